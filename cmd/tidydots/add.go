@@ -0,0 +1,108 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/AntoineGS/tidydots/internal/config"
+	"github.com/AntoineGS/tidydots/internal/presets"
+	tmpl "github.com/AntoineGS/tidydots/internal/template"
+	"github.com/spf13/cobra"
+)
+
+var (
+	addPreset      string
+	addListPresets bool
+)
+
+func newAddCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "add --preset <name>",
+		Short: "Add an application for a common tool from a preset",
+		Long: `Add the application a preset suggests for a common tool, such as neovim or
+kitty: its config targets on each OS and its package names. Presets are built
+into tidydots; .yaml files in the presets directory of the repo add more, or
+replace a built-in preset of the same name. --list-presets lists them.
+
+The application is added like 'tidydots add-from' adds one, and nothing is
+changed when its name is taken, it does not validate, or its targets collide
+with those of existing entries. What was added is printed; edit it in the
+config to fit your machines. With --dry-run it is printed but not written.`,
+		Args: cobra.NoArgs,
+		RunE: runAdd,
+	}
+
+	cmd.Flags().StringVar(&addPreset, "preset", "", "Name of the preset to add")
+	cmd.Flags().BoolVar(&addListPresets, "list-presets", false, "List the available presets instead of adding one")
+
+	return cmd
+}
+
+func runAdd(cmd *cobra.Command, _ []string) error {
+	if addPreset == "" && !addListPresets {
+		return errors.New("--preset is required; see --list-presets")
+	}
+
+	cfg, plat, configFile, err := loadConfig()
+	if err != nil {
+		return err
+	}
+
+	catalog, err := presets.Load(cfg.BackupRoot)
+	if err != nil {
+		return err
+	}
+
+	out := cmd.OutOrStdout()
+
+	if addListPresets {
+		return listPresets(out, catalog)
+	}
+
+	preset, err := presets.Find(catalog, addPreset)
+	if err != nil {
+		return fmt.Errorf("%w (available: %s)", err, strings.Join(presets.Names(catalog), ", "))
+	}
+
+	snippet := &config.AppSnippet{Application: preset.Application}
+	engine := tmpl.NewEngine(tmpl.NewContextFromPlatform(plat).WithConfig(cfg))
+
+	merged, err := config.AddSnippet(cfg, snippet, plat.EnvVars, engine)
+	if err != nil {
+		return err
+	}
+
+	data, err := snippet.Marshal()
+	if err != nil {
+		return fmt.Errorf("encoding preset: %w", err)
+	}
+
+	name := preset.Application.Name
+
+	if dryRun {
+		fmt.Fprintf(out, "Dry run: would add %s to %s:\n\n%s", name, configFile, data)
+		return nil
+	}
+
+	if err := config.SaveAtomic(merged, configFile); err != nil {
+		return fmt.Errorf("saving config: %w", err)
+	}
+
+	fmt.Fprintf(out, "Added %s to %s:\n\n%s", name, configFile, data)
+
+	return nil
+}
+
+// listPresets prints the name, description and source of each preset.
+func listPresets(w io.Writer, catalog []presets.Preset) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+
+	for _, p := range catalog {
+		fmt.Fprintf(tw, "%s\t%s\t%s\n", p.Name, p.Application.Description, p.Source)
+	}
+
+	return tw.Flush()
+}
@@ -0,0 +1,93 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/AntoineGS/tidydots/internal/config"
+	tmpl "github.com/AntoineGS/tidydots/internal/template"
+	"github.com/spf13/cobra"
+)
+
+func newAddFromCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "add-from <file | ->",
+		Short: "Add an application from a snippet written by 'tidydots show'",
+		Long: `Read an application snippet, such as one printed by 'tidydots show', from a
+file or from stdin with -, and add it to the current config. New applications
+go where newly added ones always go: default_include when set, otherwise
+tidydots.yaml.
+
+Nothing is changed when the name is already taken, the application does not
+validate, its targets collide with those of existing entries, or an attached
+backup file would overwrite an existing file. Attached files are unpacked
+into the config directory. With --dry-run the result is reported but nothing
+is written.`,
+		Args: cobra.ExactArgs(1),
+		RunE: runAddFrom,
+	}
+}
+
+func runAddFrom(cmd *cobra.Command, args []string) error {
+	cfg, plat, configFile, err := loadConfig()
+	if err != nil {
+		return err
+	}
+
+	var data []byte
+	if args[0] == "-" {
+		data, err = io.ReadAll(cmd.InOrStdin())
+	} else {
+		data, err = os.ReadFile(args[0]) //nolint:gosec // path is from the command line, intentional
+	}
+
+	if err != nil {
+		return fmt.Errorf("reading snippet: %w", err)
+	}
+
+	snippet, err := config.ParseSnippet(data)
+	if err != nil {
+		return err
+	}
+
+	engine := tmpl.NewEngine(tmpl.NewContextFromPlatform(plat).WithConfig(cfg))
+
+	merged, err := config.AddSnippet(cfg, snippet, plat.EnvVars, engine)
+	if err != nil {
+		return err
+	}
+
+	if err := snippet.CheckAttachments(cfg.BackupRoot); err != nil {
+		return err
+	}
+
+	out := cmd.OutOrStdout()
+	name := snippet.Application.Name
+
+	if dryRun {
+		fmt.Fprintf(out, "Dry run: would add %s to %s", name, configFile)
+		if n := len(snippet.Attachments); n > 0 {
+			fmt.Fprintf(out, " and unpack %d file(s) into %s", n, cfg.BackupRoot)
+		}
+
+		fmt.Fprintln(out)
+
+		return nil
+	}
+
+	if err := snippet.UnpackAttachments(cfg.BackupRoot); err != nil {
+		return err
+	}
+
+	if err := config.SaveAtomic(merged, configFile); err != nil {
+		return fmt.Errorf("saving config: %w", err)
+	}
+
+	fmt.Fprintf(out, "Added %s to %s\n", name, configFile)
+	if n := len(snippet.Attachments); n > 0 {
+		fmt.Fprintf(out, "Unpacked %d file(s) into %s\n", n, cfg.BackupRoot)
+	}
+
+	return nil
+}
@@ -1,13 +1,26 @@
 package main
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
 	"errors"
+	"io"
 	"os"
 	"path/filepath"
+	"runtime"
+	"strings"
 	"testing"
 
+	"github.com/AntoineGS/tidydots/internal/cmdexec"
 	"github.com/AntoineGS/tidydots/internal/config"
+	"github.com/AntoineGS/tidydots/internal/gitutil"
+	"github.com/AntoineGS/tidydots/internal/manager"
+	"github.com/AntoineGS/tidydots/internal/packages"
+	"github.com/AntoineGS/tidydots/internal/platform"
+	"github.com/AntoineGS/tidydots/internal/stash"
+	"github.com/AntoineGS/tidydots/internal/state"
+	"github.com/spf13/cobra"
 )
 
 // --- helpers ---
@@ -116,6 +129,131 @@ func TestLoadConfig_ValidOSOverrides(t *testing.T) {
 	}
 }
 
+func TestLoadConfig_OSFlagWinsOverEnv(t *testing.T) {
+	setupConfigDir(t)
+	t.Setenv(platform.EnvOS, "windows")
+	t.Setenv(platform.EnvHostname, "repro-host")
+
+	_, plat, _, err := loadConfig()
+	if err != nil {
+		t.Fatalf("loadConfig() unexpected error: %v", err)
+	}
+
+	if plat.OS != "windows" || plat.Hostname != "repro-host" {
+		t.Errorf("platform = %q on %q, want windows on repro-host", plat.OS, plat.Hostname)
+	}
+
+	origOS := osOverride
+	osOverride = "linux"
+	t.Cleanup(func() { osOverride = origOS })
+
+	if _, plat, _, err = loadConfig(); err != nil {
+		t.Fatalf("loadConfig() unexpected error: %v", err)
+	}
+
+	if plat.OS != "linux" {
+		t.Errorf("platform.OS = %q, want --os value linux", plat.OS)
+	}
+}
+
+func TestLoadConfig_CrossOS(t *testing.T) {
+	setupConfigDir(t)
+
+	origOS, origHome, origDryRun, origForce := osOverride, osHome, dryRun, forceCrossOS
+	t.Cleanup(func() { osOverride, osHome, dryRun, forceCrossOS = origOS, origHome, origDryRun, origForce })
+
+	hostOS, otherOS := platform.OSLinux, platform.OSWindows
+	if runtime.GOOS == "windows" {
+		hostOS, otherOS = otherOS, hostOS
+	}
+
+	osOverride, osHome = hostOS, "/srv/home"
+	if _, _, _, err := loadConfig(); err == nil || !contains(err.Error(), "--os-home") {
+		t.Errorf("loadConfig() error = %v, want --os-home refused without a cross-OS override", err)
+	}
+
+	osOverride, osHome = otherOS, `D:\Users\me`
+
+	_, plat, _, err := loadConfig()
+	if err != nil {
+		t.Fatalf("loadConfig() unexpected error: %v", err)
+	}
+
+	if got := config.ExpandPath("~/.gitconfig", plat.EnvVars); got != "D:/Users/me/.gitconfig" {
+		t.Errorf("ExpandPath(~/.gitconfig) = %q, want it under the --os-home", got)
+	}
+
+	if err := checkCrossOS(plat, "restore"); err == nil || !contains(err.Error(), "--force-cross-os") {
+		t.Errorf("checkCrossOS() = %v, want the restore refused", err)
+	}
+
+	dryRun = true
+	if err := checkCrossOS(plat, "restore"); err != nil {
+		t.Errorf("checkCrossOS() with --dry-run = %v, want nil", err)
+	}
+
+	dryRun, forceCrossOS = false, true
+	if err := checkCrossOS(plat, "restore"); err != nil {
+		t.Errorf("checkCrossOS() with --force-cross-os = %v, want nil", err)
+	}
+}
+
+func TestRunRestore_TargetOS(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("restores Windows targets from a Linux host")
+	}
+
+	dir := t.TempDir()
+	yaml := `version: 3
+applications:
+  - name: nvim
+    entries:
+      - name: config
+        backup: ./nvim
+        targets: {linux: ~/.config/nvim, windows: ~/AppData/Local/nvim}
+      - name: fonts
+        check: {windows: exit 1}
+        run: {windows: exit 1}
+`
+	if err := os.WriteFile(filepath.Join(dir, "tidydots.yaml"), []byte(yaml), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := os.MkdirAll(filepath.Join(dir, "nvim"), 0o750); err != nil {
+		t.Fatal(err)
+	}
+
+	home := t.TempDir()
+
+	origDir, origOS, origTarget, origHome := configDir, osOverride, targetOS, osHome
+	t.Cleanup(func() { configDir, osOverride, targetOS, osHome = origDir, origOS, origTarget, origHome })
+
+	configDir, targetOS = dir, "plan9"
+	if err := runRestore(nil, nil); err == nil || !contains(err.Error(), "invalid --target-os") {
+		t.Errorf("runRestore() error = %v, want the OS refused", err)
+	}
+
+	targetOS = platform.OSWindows
+	if err := runRestore(nil, nil); err == nil || !contains(err.Error(), "--os-home") {
+		t.Errorf("runRestore() error = %v, want --os-home required", err)
+	}
+
+	osOverride, osHome = platform.OSWindows, home
+	if err := runRestore(nil, nil); err == nil || !contains(err.Error(), "cannot be combined with --os") {
+		t.Errorf("runRestore() error = %v, want --os refused", err)
+	}
+
+	osOverride = ""
+	if err := runRestore(nil, nil); err != nil {
+		t.Fatalf("runRestore() error = %v, want the Windows targets restored and the setup entry skipped", err)
+	}
+
+	link, err := os.Readlink(filepath.Join(home, "AppData", "Local", "nvim"))
+	if err != nil || link != filepath.Join(dir, "nvim") {
+		t.Errorf("Windows target = %q, %v, want a symlink to the backup", link, err)
+	}
+}
+
 func TestLoadConfig_MissingYAML(t *testing.T) {
 	// configDir set to an empty dir (no tidydots.yaml)
 	dir := t.TempDir()
@@ -169,6 +307,9 @@ func (m *mockRestorer) Restore() error { return m.err }
 func (m *mockRestorer) RestoreWithContext(_ context.Context) error {
 	return m.err
 }
+func (m *mockRestorer) RestoreReport(_ context.Context) (*manager.Report, error) {
+	return &manager.Report{Operation: "restore"}, m.err
+}
 
 type mockBackuper struct {
 	err error
@@ -178,24 +319,33 @@ func (m *mockBackuper) Backup() error { return m.err }
 func (m *mockBackuper) BackupWithContext(_ context.Context) error {
 	return m.err
 }
+func (m *mockBackuper) BackupReport(_ context.Context) (*manager.Report, error) {
+	return &manager.Report{Operation: "backup"}, m.err
+}
 
 type mockLister struct {
-	err error
+	err      error
+	jsonUsed bool
 }
 
 func (m *mockLister) List() error {
 	return m.err
 }
 
+func (m *mockLister) ListJSON(_ io.Writer) error {
+	m.jsonUsed = true
+	return m.err
+}
+
 func TestRunRestoreWithManager_Success(t *testing.T) {
-	if err := runRestoreWithManager(&mockRestorer{}); err != nil {
+	if err := runRestoreWithManager(&mockRestorer{}, nil); err != nil {
 		t.Errorf("runRestoreWithManager() unexpected error: %v", err)
 	}
 }
 
 func TestRunRestoreWithManager_Error(t *testing.T) {
 	sentinel := errors.New("restore error")
-	err := runRestoreWithManager(&mockRestorer{err: sentinel})
+	err := runRestoreWithManager(&mockRestorer{err: sentinel}, nil)
 	if !errors.Is(err, sentinel) {
 		t.Errorf("runRestoreWithManager() error = %v, want %v", err, sentinel)
 	}
@@ -215,20 +365,83 @@ func TestRunBackupWithManager_Error(t *testing.T) {
 	}
 }
 
+func TestPrintRunReport(t *testing.T) {
+	report := &manager.Report{Operation: "restore", Entries: []manager.EntryResult{
+		{App: "nvim", Entry: "config", Action: manager.ActionFailed, Err: errors.New("symlink source does not exist")},
+		{App: "zsh", Entry: "rc", Action: manager.ActionRestored, Detail: "/home/u -> /repo/zsh"},
+		{App: "fonts", Entry: "local", Action: manager.ActionRestored, Detail: "/home/u/fonts -> /repo/fonts", Warning: "post_restore failed (exit 1)"},
+		{App: "sys", Entry: "hosts", Action: manager.ActionSkipped, Detail: "requires sudo"},
+		{App: "app", Entry: "data", Action: manager.ActionBackedUp, Detail: "/home/u/data -> /repo/data", Skipped: []manager.SkippedFile{
+			{Path: "/home/u/data/big.log", SkipReason: "2048 bytes exceeds max_file_size of 1024 bytes"},
+		}},
+	}}
+
+	var buf bytes.Buffer
+
+	printRunReport(&buf, report)
+
+	want := "\n[ok] zsh/rc: Restored: /home/u -> /repo/zsh\n" +
+		"[warn] fonts/local: Restored: /home/u/fonts -> /repo/fonts (warning: post_restore failed (exit 1))\n" +
+		"[ok] app/data: Backed up: /home/u/data -> /repo/data\n" +
+		"  [skip] /home/u/data/big.log: 2048 bytes exceeds max_file_size of 1024 bytes\n" +
+		"[skip] sys/hosts: requires sudo\n" +
+		"[error] nvim/config: symlink source does not exist\n" +
+		"\nSummary: 2 restored, 1 backed up, 1 skipped, 1 failed\n"
+	if got := buf.String(); got != want {
+		t.Errorf("printRunReport() =\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestPrintRestoreNotes(t *testing.T) {
+	cfg := &config.Config{Applications: []config.Application{
+		{Name: "zsh", Notes: "Run chsh -s /bin/zsh.\nLog out and back in.\n"},
+		{Name: "nvim", Notes: "Run :Lazy sync."},
+		{Name: "git"},
+	}}
+
+	report := &manager.Report{Operation: "restore", Entries: []manager.EntryResult{
+		{App: "zsh", Entry: "rc", Action: manager.ActionRestored},
+		{App: "zsh", Entry: "env", Action: manager.ActionRestored},
+		{App: "nvim", Entry: "config", Action: manager.ActionFailed, Err: errors.New("boom")},
+		{App: "git", Entry: "config", Action: manager.ActionRestored},
+	}}
+
+	var buf bytes.Buffer
+
+	printRestoreNotes(&buf, cfg, report)
+
+	// nvim failed and git has no notes.
+	want := "\nNotes for zsh:\n  Run chsh -s /bin/zsh.\n  Log out and back in.\n"
+	if got := buf.String(); got != want {
+		t.Errorf("printRestoreNotes() =\n%q\nwant:\n%q", got, want)
+	}
+}
+
 func TestRunListWithManager_Success(t *testing.T) {
-	if err := runListWithManager(&mockLister{}); err != nil {
+	if err := runListWithManager(&mockLister{}, listFormatText, io.Discard); err != nil {
 		t.Errorf("runListWithManager() unexpected error: %v", err)
 	}
 }
 
 func TestRunListWithManager_Error(t *testing.T) {
 	sentinel := errors.New("list error")
-	err := runListWithManager(&mockLister{err: sentinel})
+	err := runListWithManager(&mockLister{err: sentinel}, listFormatText, io.Discard)
 	if !errors.Is(err, sentinel) {
 		t.Errorf("runListWithManager() error = %v, want %v", err, sentinel)
 	}
 }
 
+func TestRunListWithManager_JSON(t *testing.T) {
+	m := &mockLister{}
+	if err := runListWithManager(m, listFormatJSON, io.Discard); err != nil {
+		t.Fatalf("runListWithManager() unexpected error: %v", err)
+	}
+
+	if !m.jsonUsed {
+		t.Error("--format json should call ListJSON")
+	}
+}
+
 // --- helpers ---
 
 // appConfigPathForTest returns the app config path using the exported helper.
@@ -260,3 +473,1477 @@ func removeAndRestoreFile(t *testing.T, path string) {
 		}
 	})
 }
+
+// --- runVerify ---
+
+func TestRunVerify_RequiresCheck(t *testing.T) {
+	orig := verifyIntegrity
+	verifyIntegrity = false
+	t.Cleanup(func() { verifyIntegrity = orig })
+
+	if err := runVerify(nil, nil); err == nil {
+		t.Fatal("runVerify() without a check flag expected error, got nil")
+	}
+}
+
+func TestRunVerify_IntegrityWithNoChecksums(t *testing.T) {
+	setupConfigDir(t)
+
+	orig := verifyIntegrity
+	verifyIntegrity = true
+	t.Cleanup(func() { verifyIntegrity = orig })
+
+	if err := runVerify(nil, nil); err != nil {
+		t.Fatalf("runVerify() unexpected error: %v", err)
+	}
+}
+
+func TestRunVerify_RepairImpliesLinks(t *testing.T) {
+	setupConfigDir(t)
+
+	origIntegrity, origRepair := verifyIntegrity, verifyRepair
+	verifyIntegrity, verifyRepair = false, true
+	t.Cleanup(func() { verifyIntegrity, verifyRepair = origIntegrity, origRepair })
+
+	if err := runVerify(nil, nil); err != nil {
+		t.Fatalf("runVerify() --repair unexpected error: %v", err)
+	}
+}
+
+func TestRunVerify_Strict(t *testing.T) {
+	setupConfigDir(t)
+
+	orig := verifyStrict
+	verifyStrict = true
+	t.Cleanup(func() { verifyStrict = orig })
+
+	if err := runVerify(nil, nil); err == nil || !strings.Contains(err.Error(), "tidydots pin") {
+		t.Fatalf("runVerify() --strict without a lockfile error = %v, want a hint to run pin", err)
+	}
+
+	if err := pin(io.Discard, configDir, false); err != nil {
+		t.Fatalf("pin() error = %v", err)
+	}
+
+	if err := runVerify(nil, nil); err != nil {
+		t.Errorf("runVerify() --strict right after pin() error = %v", err)
+	}
+
+	if err := pin(io.Discard, configDir, false); err == nil {
+		t.Error("pin() over an existing lockfile without --update expected error, got nil")
+	}
+
+	if err := pin(io.Discard, configDir, true); err != nil {
+		t.Errorf("pin() with --update error = %v", err)
+	}
+}
+
+// --- verify-backups ---
+
+func TestRunBackupCheck(t *testing.T) {
+	dir := t.TempDir()
+	yaml := `version: 3
+applications:
+  - name: nvim
+    entries:
+      - name: config
+        backup: ./nvim
+        targets: {linux: ~/.config/nvim, windows: ~/AppData/Local/nvim, darwin: ~/.config/nvim}
+`
+	if err := os.WriteFile(filepath.Join(dir, "tidydots.yaml"), []byte(yaml), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	orig := configDir
+	configDir = dir
+	t.Cleanup(func() { configDir = orig })
+
+	mgr, err := createManager()
+	if err != nil {
+		t.Fatalf("createManager() error = %v", err)
+	}
+	defer mgr.Close() //nolint:errcheck // best-effort cleanup
+
+	var out bytes.Buffer
+	if err := runBackupCheck(&out, mgr); !errors.Is(err, errVerifyFailed) {
+		t.Fatalf("runBackupCheck() without the backup error = %v, want errVerifyFailed", err)
+	}
+
+	if !strings.Contains(out.String(), "✗ nvim/config:") || !strings.Contains(out.String(), "1 problem(s)") {
+		t.Errorf("runBackupCheck() output = %q, want the entry reported", out.String())
+	}
+
+	if err := os.MkdirAll(filepath.Join(dir, "nvim"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, "nvim", "init.lua"), []byte("-- nvim"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	out.Reset()
+
+	if err := runBackupCheck(&out, mgr); err != nil {
+		t.Errorf("runBackupCheck() with the backup error = %v, output %q", err, out.String())
+	}
+}
+
+// --- export ---
+
+func TestRunExport_WritesOutputFile(t *testing.T) {
+	dir := t.TempDir()
+	yaml := `version: 3
+applications:
+  - name: nvim
+    entries:
+      - name: config
+        backup: ./nvim
+        targets: {linux: ~/.config/nvim}
+  - name: zsh
+`
+	if err := os.WriteFile(filepath.Join(dir, "tidydots.yaml"), []byte(yaml), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	origDir, origOut := configDir, exportOutput
+	configDir = dir
+	exportOutput = filepath.Join(t.TempDir(), "shared.yaml")
+	t.Cleanup(func() { configDir, exportOutput = origDir, origOut })
+
+	if err := runExport(nil, []string{"nvim"}); err != nil {
+		t.Fatalf("runExport() error = %v", err)
+	}
+
+	cfg, err := config.Load(exportOutput)
+	if err != nil {
+		t.Fatalf("exported file does not load: %v", err)
+	}
+
+	if len(cfg.Applications) != 1 || cfg.Applications[0].Name != "nvim" {
+		t.Errorf("exported applications = %+v, want only nvim", cfg.Applications)
+	}
+
+	if err := runExport(nil, []string{"missing"}); err == nil || !contains(err.Error(), `"missing"`) {
+		t.Errorf("runExport(missing) error = %v, want not-found error naming it", err)
+	}
+}
+
+func TestRunExportBundle_ImportsOnAnotherMachine(t *testing.T) {
+	src := t.TempDir()
+	yaml := `version: 3
+vars:
+  editor: nvim
+applications:
+  - name: nvim
+    entries:
+      - name: config
+        backup: ./nvim
+        targets: {linux: ~/.config/nvim}
+`
+	if err := os.WriteFile(filepath.Join(src, "tidydots.yaml"), []byte(yaml), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := os.MkdirAll(filepath.Join(src, "nvim"), 0o750); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := os.WriteFile(filepath.Join(src, "nvim", "init.lua"), []byte("-- mine"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	archive := filepath.Join(t.TempDir(), "dots.tar.gz")
+
+	origDir, origBundle, origConflict := configDir, exportBundle, importOnConflict
+	configDir, exportBundle = src, archive
+	t.Cleanup(func() { configDir, exportBundle, importOnConflict = origDir, origBundle, origConflict })
+
+	if err := runExport(nil, nil); err != nil {
+		t.Fatalf("runExport() --bundle error = %v", err)
+	}
+
+	dst := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dst, "tidydots.yaml"), []byte("version: 3\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	configDir, importOnConflict = dst, "error"
+
+	cmd := newImportCmd()
+
+	var out bytes.Buffer
+	cmd.SetOut(&out)
+
+	if err := runImport(cmd, []string{archive}); err != nil {
+		t.Fatalf("runImport(bundle) error = %v", err)
+	}
+
+	for _, want := range []string{"[added] nvim", "[backup] ./nvim", "[var] editor"} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("output missing %q:\n%s", want, out.String())
+		}
+	}
+
+	if data, err := os.ReadFile(filepath.Join(dst, "nvim", "init.lua")); err != nil || string(data) != "-- mine" {
+		t.Errorf("imported backup = (%q, %v), want the exported file", data, err)
+	}
+
+	cfg, err := config.Load(filepath.Join(dst, "tidydots.yaml"))
+	if err != nil {
+		t.Fatalf("config does not load after import: %v", err)
+	}
+
+	if len(cfg.Applications) != 1 || cfg.Vars["editor"] != "nvim" {
+		t.Errorf("imported config = %+v, want nvim and its vars", cfg)
+	}
+}
+
+// --- import ---
+
+func TestRunImport(t *testing.T) {
+	const current = `version: 3
+applications:
+  - name: nvim
+    description: mine
+    entries: []
+`
+
+	const shared = `version: 3
+applications:
+  - name: nvim
+    description: shared
+    entries: []
+  - name: tmux
+    entries: []
+`
+
+	tests := []struct {
+		name       string
+		onConflict string
+		wantErr    bool
+		wantOut    []string
+		wantApps   string // name:description after the import
+	}{
+		{
+			name:       "error leaves config untouched",
+			onConflict: "error",
+			wantErr:    true,
+			wantOut:    []string{"[conflict] nvim"},
+			wantApps:   "nvim:mine",
+		},
+		{
+			name:       "skip keeps existing",
+			onConflict: "skip",
+			wantOut:    []string{"[added] tmux", "[skipped] nvim", "Imported 1 application(s)"},
+			wantApps:   "nvim:mine tmux:",
+		},
+		{
+			name:       "overwrite replaces existing",
+			onConflict: "overwrite",
+			wantOut:    []string{"[added] tmux", "[overwritten] nvim", "Imported 2 application(s)"},
+			wantApps:   "nvim:shared tmux:",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			if err := os.WriteFile(filepath.Join(dir, "tidydots.yaml"), []byte(current), 0o600); err != nil {
+				t.Fatal(err)
+			}
+
+			sharedFile := filepath.Join(t.TempDir(), "shared.yaml")
+			if err := os.WriteFile(sharedFile, []byte(shared), 0o600); err != nil {
+				t.Fatal(err)
+			}
+
+			cmd := newImportCmd()
+
+			origDir, origConflict := configDir, importOnConflict
+			configDir, importOnConflict = dir, tt.onConflict
+			t.Cleanup(func() { configDir, importOnConflict = origDir, origConflict })
+
+			var out bytes.Buffer
+			cmd.SetOut(&out)
+
+			err := runImport(cmd, []string{sharedFile})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("runImport() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			for _, want := range tt.wantOut {
+				if !strings.Contains(out.String(), want) {
+					t.Errorf("output missing %q:\n%s", want, out.String())
+				}
+			}
+
+			cfg, err := config.Load(filepath.Join(dir, "tidydots.yaml"))
+			if err != nil {
+				t.Fatalf("config does not load after import: %v", err)
+			}
+
+			var got []string
+			for _, app := range cfg.Applications {
+				got = append(got, app.Name+":"+app.Description)
+			}
+
+			if strings.Join(got, " ") != tt.wantApps {
+				t.Errorf("applications = %q, want %q", strings.Join(got, " "), tt.wantApps)
+			}
+		})
+	}
+}
+
+func TestRunImport_InvalidInput(t *testing.T) {
+	setupConfigDir(t)
+
+	cmd := newImportCmd()
+
+	origConflict := importOnConflict
+	t.Cleanup(func() { importOnConflict = origConflict })
+
+	importOnConflict = "merge"
+	if err := runImport(cmd, []string{"unused.yaml"}); err == nil || !contains(err.Error(), "--on-conflict") {
+		t.Errorf("runImport() with bad strategy error = %v, want --on-conflict error", err)
+	}
+
+	importOnConflict = "error"
+
+	bad := filepath.Join(t.TempDir(), "bad.yaml")
+	if err := os.WriteFile(bad, []byte("version: 3\napplications:\n  - name: a\n  - name: a\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := runImport(cmd, []string{bad}); err == nil || !contains(err.Error(), "duplicate") {
+		t.Errorf("runImport() with invalid file error = %v, want validation error", err)
+	}
+}
+
+// --- info ---
+
+func TestRunInfo(t *testing.T) {
+	dir := t.TempDir()
+	yaml := `version: 3
+applications:
+  - name: nvim
+    entries:
+      - name: config
+        backup: ./nvim
+        targets: {linux: ~/.config/nvim, windows: ~/AppData/Local/nvim}
+  - name: old
+    enabled: false
+    entries: []
+  - name: elsewhere
+    when: '{{ eq .OS "plan9" }}'
+    entries: []
+`
+	if err := os.WriteFile(filepath.Join(dir, "tidydots.yaml"), []byte(yaml), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := os.MkdirAll(filepath.Join(dir, "nvim"), 0o750); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, "nvim", "init.lua.tmpl"), []byte("-- {{ .OS }}\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	orig := configDir
+	configDir = dir
+	t.Cleanup(func() { configDir = orig })
+
+	cmd := newInfoCmd()
+
+	var out bytes.Buffer
+	cmd.SetOut(&out)
+
+	if err := runInfo(cmd, nil); err != nil {
+		t.Fatalf("runInfo() error = %v", err)
+	}
+
+	for _, want := range []string{
+		"  Config directory  " + dir + "\n",
+		"  Applications      3\n",
+		"  On this machine   1 (1 filtered out by when, 1 of the rest disabled)\n",
+		"  Templates         1\n",
+		"Package managers\n",
+	} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("output missing %q:\n%s", want, out.String())
+		}
+	}
+}
+
+func TestWriteInfo_ManagerVersions(t *testing.T) {
+	var out bytes.Buffer
+
+	err := writeInfo(&out, infoSummary{
+		cfg:       &config.Config{},
+		plat:      &platform.Platform{OS: platform.OSLinux},
+		available: []string{"pacman", "git"},
+		versions:  map[string]platform.Version{"git": {Major: 2, Minor: 43}},
+		supported: []string{"pacman", "apt", "git"},
+	})
+	if err != nil {
+		t.Fatalf("writeInfo() error = %v", err)
+	}
+
+	if want := "  Available    pacman, git 2.43.0\n"; !strings.Contains(out.String(), want) {
+		t.Errorf("output missing %q:\n%s", want, out.String())
+	}
+}
+
+func TestRunInfo_DetectsPlatformOnce(t *testing.T) {
+	setupConfigDir(t)
+
+	calls := 0
+	orig := detectPlatform
+	detectPlatform = func() *platform.Platform {
+		calls++
+		return orig()
+	}
+	t.Cleanup(func() { detectPlatform = orig })
+
+	cmd := newInfoCmd()
+	cmd.SetOut(io.Discard)
+
+	if err := runInfo(cmd, nil); err != nil {
+		t.Fatalf("runInfo() error = %v", err)
+	}
+
+	if calls != 1 {
+		t.Errorf("platform detected %d times, want 1", calls)
+	}
+}
+
+// --- show / add-from ---
+
+func TestRunShowAndAddFrom(t *testing.T) {
+	src := t.TempDir()
+	yaml := `version: 3
+applications:
+  - name: tmux
+    entries:
+      - name: conf
+        backup: ./tmux
+        files: [.tmux.conf]
+        targets: {linux: ~/.tmux-show-test}
+`
+	if err := os.WriteFile(filepath.Join(src, "tidydots.yaml"), []byte(yaml), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := os.MkdirAll(filepath.Join(src, "tmux"), 0o750); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := os.WriteFile(filepath.Join(src, "tmux", ".tmux.conf"), []byte("set -g mouse on\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	dst := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dst, "tidydots.yaml"), []byte(minimalTidydotsYAML), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	origDir, origWithFiles := configDir, showWithFiles
+	t.Cleanup(func() { configDir, showWithFiles = origDir, origWithFiles })
+
+	show := newShowCmd()
+	configDir, showWithFiles = src, true
+
+	var snippet bytes.Buffer
+	show.SetOut(&snippet)
+
+	if err := runShow(show, []string{"tmux"}); err != nil {
+		t.Fatalf("runShow() error = %v", err)
+	}
+
+	configDir = dst
+
+	addFrom := newAddFromCmd()
+	addFrom.SetIn(bytes.NewReader(snippet.Bytes()))
+
+	var out bytes.Buffer
+	addFrom.SetOut(&out)
+
+	if err := runAddFrom(addFrom, []string{"-"}); err != nil {
+		t.Fatalf("runAddFrom() error = %v", err)
+	}
+
+	if !strings.Contains(out.String(), "Unpacked 1 file(s)") {
+		t.Errorf("output = %q, want the unpacked file reported", out.String())
+	}
+
+	cfg, err := config.Load(filepath.Join(dst, "tidydots.yaml"))
+	if err != nil {
+		t.Fatalf("config does not load after add-from: %v", err)
+	}
+
+	if len(cfg.Applications) != 1 || cfg.Applications[0].Name != "tmux" {
+		t.Errorf("applications = %+v, want tmux", cfg.Applications)
+	}
+
+	if got, err := os.ReadFile(filepath.Join(dst, "tmux", ".tmux.conf")); err != nil || string(got) != "set -g mouse on\n" {
+		t.Errorf("unpacked .tmux.conf = %q, %v", got, err)
+	}
+
+	addFrom.SetIn(bytes.NewReader(snippet.Bytes()))
+	if err := runAddFrom(addFrom, []string{"-"}); !errors.Is(err, config.ErrMergeConflict) {
+		t.Errorf("second runAddFrom() error = %v, want ErrMergeConflict", err)
+	}
+}
+
+func TestRunImportStow(t *testing.T) {
+	dir := t.TempDir()
+
+	const current = `version: 3
+applications:
+  - name: git
+    entries: []
+`
+	if err := os.WriteFile(filepath.Join(dir, "tidydots.yaml"), []byte(current), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	for path, content := range map[string]string{
+		"stow/zsh/.zshrc":                 "export EDITOR=nvim\n",
+		"stow/nvim/.config/nvim/init.lua": "require('plugins')\n",
+		"stow/git/.gitconfig":             "[user]\n",
+	} {
+		full := filepath.Join(dir, filepath.FromSlash(path))
+		if err := os.MkdirAll(filepath.Dir(full), 0o750); err != nil {
+			t.Fatal(err)
+		}
+
+		if err := os.WriteFile(full, []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	cmd := newImportStowCmd()
+
+	origDir, origDryRun := configDir, dryRun
+	configDir = dir
+	t.Cleanup(func() { configDir, dryRun = origDir, origDryRun })
+
+	var out, errOut bytes.Buffer
+	cmd.SetOut(&out)
+	cmd.SetErr(&errOut)
+
+	stowDir := filepath.Join(dir, "stow")
+
+	dryRun = true
+	if err := runImportStow(cmd, []string{stowDir}); err != nil {
+		t.Fatalf("runImportStow() dry run error = %v", err)
+	}
+
+	for _, want := range []string{"name: nvim", "backup: ./stow/nvim/.config/nvim", "linux: ~/.config/nvim", "name: zsh"} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("dry run output missing %q:\n%s", want, out.String())
+		}
+	}
+
+	if !strings.Contains(errOut.String(), "Skipping git") {
+		t.Errorf("expected existing git application to be skipped, stderr:\n%s", errOut.String())
+	}
+
+	if cfg, err := config.Load(filepath.Join(dir, "tidydots.yaml")); err != nil || len(cfg.Applications) != 1 {
+		t.Fatalf("dry run modified the config: %v", err)
+	}
+
+	dryRun = false
+	out.Reset()
+
+	if err := runImportStow(cmd, []string{stowDir}); err != nil {
+		t.Fatalf("runImportStow() error = %v", err)
+	}
+
+	cfg, err := config.Load(filepath.Join(dir, "tidydots.yaml"))
+	if err != nil {
+		t.Fatalf("config does not load after import: %v", err)
+	}
+
+	var names []string
+	for _, app := range cfg.Applications {
+		names = append(names, app.Name)
+	}
+
+	if got := strings.Join(names, " "); got != "git nvim zsh" {
+		t.Errorf("applications = %q, want git nvim zsh", got)
+	}
+}
+
+func TestRunImportStow_OSOverride(t *testing.T) {
+	dir := t.TempDir()
+
+	if err := os.WriteFile(filepath.Join(dir, "tidydots.yaml"), []byte("version: 3\napplications: []\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	rc := filepath.Join(dir, "stow", "zsh", ".zshrc")
+	if err := os.MkdirAll(filepath.Dir(rc), 0o750); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := os.WriteFile(rc, []byte("export EDITOR=nvim\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	origDir, origDryRun, origOS := configDir, dryRun, osOverride
+	configDir, dryRun, osOverride = dir, true, platform.OSWindows
+	t.Cleanup(func() { configDir, dryRun, osOverride = origDir, origDryRun, origOS })
+
+	cmd := newImportStowCmd()
+
+	var out bytes.Buffer
+	cmd.SetOut(&out)
+	cmd.SetErr(io.Discard)
+
+	if err := runImportStow(cmd, []string{filepath.Join(dir, "stow")}); err != nil {
+		t.Fatalf("runImportStow() error = %v", err)
+	}
+
+	if !strings.Contains(out.String(), "windows: \"~\"") || strings.Contains(out.String(), "linux:") {
+		t.Errorf("targets with --os windows are not set for windows:\n%s", out.String())
+	}
+}
+
+// --- import-packages ---
+
+// setupImportPackages points configDir at a repo with a git application,
+// makes pacman report git, neovim and tmux as installed, and resets the
+// import-packages flags.
+func setupImportPackages(t *testing.T) (dir string, cmd *cobra.Command, out, errOut *bytes.Buffer) {
+	t.Helper()
+	dir = t.TempDir()
+
+	const current = `version: 3
+applications:
+  - name: git
+    entries: []
+`
+	if err := os.WriteFile(filepath.Join(dir, "tidydots.yaml"), []byte(current), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	origDir, origDryRun, origList, origSelect := configDir, dryRun, listExplicitPackages, selectPackages
+	configDir = dir
+	listExplicitPackages = func(_ context.Context, _ string, mgr packages.PackageManager) ([]packages.ExplicitPackage, error) {
+		if mgr != packages.Pacman {
+			return nil, errors.New("unexpected manager")
+		}
+
+		var pkgs []packages.ExplicitPackage
+		for _, name := range []string{"git", "neovim", "tmux"} {
+			pkgs = append(pkgs, packages.ExplicitPackage{Name: name, Value: packages.ManagerValue{PackageName: name}})
+		}
+
+		return pkgs, nil
+	}
+	t.Cleanup(func() {
+		configDir, dryRun, listExplicitPackages, selectPackages = origDir, origDryRun, origList, origSelect
+	})
+
+	// Creating the command resets its flags.
+	cmd = newImportPackagesCmd()
+	importPkgsManager = "pacman"
+
+	out, errOut = &bytes.Buffer{}, &bytes.Buffer{}
+	cmd.SetOut(out)
+	cmd.SetErr(errOut)
+
+	return dir, cmd, out, errOut
+}
+
+// importedApplications returns the names of the applications of the config
+// in dir.
+func importedApplications(t *testing.T, dir string) string {
+	t.Helper()
+
+	cfg, err := config.Load(filepath.Join(dir, "tidydots.yaml"))
+	if err != nil {
+		t.Fatalf("config does not load after import: %v", err)
+	}
+
+	var names []string
+	for _, app := range cfg.Applications {
+		names = append(names, app.Name)
+	}
+
+	return strings.Join(names, " ")
+}
+
+func TestRunImportPackages_Filter(t *testing.T) {
+	dir, cmd, out, errOut := setupImportPackages(t)
+	importPkgsFilter = "^neo"
+
+	dryRun = true
+	if err := runImportPackages(cmd, nil); err != nil {
+		t.Fatalf("runImportPackages() dry run error = %v", err)
+	}
+
+	for _, want := range []string{"name: neovim", "pacman: neovim"} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("dry run output missing %q:\n%s", want, out.String())
+		}
+	}
+
+	if strings.Contains(out.String(), "tmux") {
+		t.Errorf("dry run output has tmux, which --filter excludes:\n%s", out.String())
+	}
+
+	if !strings.Contains(errOut.String(), "Skipping 1 package(s) already configured: git") {
+		t.Errorf("expected git to be skipped, stderr:\n%s", errOut.String())
+	}
+
+	if got := importedApplications(t, dir); got != "git" {
+		t.Fatalf("dry run modified the config: %q", got)
+	}
+
+	dryRun = false
+	if err := runImportPackages(cmd, nil); err != nil {
+		t.Fatalf("runImportPackages() error = %v", err)
+	}
+
+	if got := importedApplications(t, dir); got != "git neovim" {
+		t.Errorf("applications = %q, want git neovim", got)
+	}
+}
+
+func TestRunImportPackages_Pick(t *testing.T) {
+	dir, cmd, out, _ := setupImportPackages(t)
+
+	var offered []string
+	selectPackages = func(_ string, names []string) ([]string, error) {
+		offered = names
+		return []string{"tmux"}, nil
+	}
+
+	if err := runImportPackages(cmd, nil); err != nil {
+		t.Fatalf("runImportPackages() error = %v", err)
+	}
+
+	if got := strings.Join(offered, " "); got != "neovim tmux" {
+		t.Errorf("picker offered %q, want neovim tmux", got)
+	}
+
+	if got := importedApplications(t, dir); got != "git tmux" {
+		t.Errorf("applications = %q, want git tmux", got)
+	}
+
+	// Cancelling the picker changes nothing.
+	selectPackages = func(string, []string) ([]string, error) { return nil, nil }
+	out.Reset()
+
+	if err := runImportPackages(cmd, nil); err != nil {
+		t.Fatalf("runImportPackages() error = %v", err)
+	}
+
+	if !strings.Contains(out.String(), "Import cancelled") {
+		t.Errorf("output = %q, want the import cancelled", out.String())
+	}
+
+	if got := importedApplications(t, dir); got != "git tmux" {
+		t.Errorf("applications after cancel = %q, want git tmux", got)
+	}
+}
+
+func TestRunImportPackages_InvalidFlags(t *testing.T) {
+	_, cmd, _, _ := setupImportPackages(t)
+
+	importPkgsManager = "dnf"
+	if err := runImportPackages(cmd, nil); err == nil {
+		t.Error("expected an error for an unsupported manager")
+	}
+
+	importPkgsManager, importPkgsAll, importPkgsFilter = "pacman", true, "neo"
+	if err := runImportPackages(cmd, nil); err == nil {
+		t.Error("expected an error for --all with --filter")
+	}
+
+	importPkgsAll, importPkgsFilter = false, "("
+	if err := runImportPackages(cmd, nil); err == nil {
+		t.Error("expected an error for an invalid --filter")
+	}
+}
+
+// --- render ---
+
+// setupRenderConfig points configDir at a repo with one nvim folder entry
+// whose backup holds the given templates, and resets the render flags.
+func setupRenderConfig(t *testing.T, templates map[string]string) {
+	t.Helper()
+	dir := t.TempDir()
+
+	yaml := `version: 3
+applications:
+  - name: nvim
+    entries:
+      - name: config
+        backup: ./nvim
+        targets: {linux: ~/.config/nvim, windows: ~/AppData/Local/nvim}
+`
+	if err := os.WriteFile(filepath.Join(dir, "tidydots.yaml"), []byte(yaml), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	for name, content := range templates {
+		path := filepath.Join(dir, "nvim", name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o750); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	origDir, origFile, origCtx, origCheck, origOS := configDir, renderFile, renderContext, renderCheck, osOverride
+	configDir, renderFile, renderContext, renderCheck, osOverride = dir, "", nil, false, "linux"
+	t.Cleanup(func() {
+		configDir, renderFile, renderContext, renderCheck, osOverride = origDir, origFile, origCtx, origCheck, origOS
+	})
+}
+
+func TestRunRender(t *testing.T) {
+	setupRenderConfig(t, map[string]string{
+		"init.lua.tmpl": "os={{ .OS }} editor={{ .Env.EDITOR }}\n",
+	})
+
+	// Building the command binds the flags, resetting them to their defaults.
+	var out bytes.Buffer
+	cmd := newRenderCmd()
+	cmd.SetOut(&out)
+	renderContext = []string{"OS=windows", "Env.EDITOR=helix"}
+
+	if err := runRender(cmd, []string{"nvim/config"}); err != nil {
+		t.Fatalf("runRender() error = %v", err)
+	}
+
+	if got, want := out.String(), "os=windows editor=helix\n"; got != want {
+		t.Errorf("output = %q, want %q", got, want)
+	}
+}
+
+func TestRunRender_CheckReportsFailures(t *testing.T) {
+	setupRenderConfig(t, map[string]string{
+		"good.tmpl": "{{ .OS }}",
+		"bad.tmpl":  "line one\n{{ .Missing }}\n",
+	})
+
+	var out bytes.Buffer
+	cmd := newRenderCmd()
+	cmd.SetOut(&out)
+	cmd.SetErr(io.Discard)
+	renderCheck = true
+
+	err := runRender(cmd, nil)
+	if !errors.Is(err, errRenderFailed) {
+		t.Fatalf("runRender() error = %v, want errRenderFailed", err)
+	}
+
+	for _, want := range []string{"✗ nvim/config: bad.tmpl:2:4:", "✓ nvim/config: good.tmpl"} {
+		if !contains(out.String(), want) {
+			t.Errorf("output %q does not contain %q", out.String(), want)
+		}
+	}
+}
+
+func TestRunRender_Stdin(t *testing.T) {
+	setupRenderConfig(t, nil)
+
+	var out bytes.Buffer
+	cmd := newRenderCmd()
+	cmd.SetOut(&out)
+	cmd.SetIn(strings.NewReader(`{{ if eq .OS "linux" }}penguin{{ end }}`))
+
+	if err := runRender(cmd, []string{"-"}); err != nil {
+		t.Fatalf("runRender(-) error = %v", err)
+	}
+
+	if out.String() != "penguin" {
+		t.Errorf("output = %q, want penguin", out.String())
+	}
+}
+
+func TestSelectRepos(t *testing.T) {
+	repos := []packages.GitRepo{{Name: "nvim"}, {Name: "tmux"}, {Name: "zsh"}}
+
+	got, err := selectRepos(repos, []string{"zsh", "nvim"})
+	if err != nil || len(got) != 2 || got[0].Name != "zsh" || got[1].Name != "nvim" {
+		t.Errorf("selectRepos() = %+v, %v; want zsh and nvim", got, err)
+	}
+
+	if got, _ := selectRepos(repos, nil); len(got) != 3 {
+		t.Errorf("selectRepos() without names = %+v, want all repos", got)
+	}
+
+	if _, err := selectRepos(repos, []string{"emacs"}); err == nil {
+		t.Error("selectRepos() should fail for an unknown name")
+	}
+}
+
+func TestDescribeRepoStatus(t *testing.T) {
+	tests := []struct {
+		st   gitutil.Status
+		want string
+	}{
+		{gitutil.Status{Branch: "main", Upstream: "origin/main"}, "main, up to date with origin/main"},
+		{gitutil.Status{Branch: "main", Upstream: "origin/main", Ahead: 2}, "main, 2 ahead of origin/main"},
+		{gitutil.Status{Branch: "main", Upstream: "origin/main", Behind: 1, Dirty: true}, "main, 1 behind origin/main, local changes"},
+		{gitutil.Status{Branch: "dev", Upstream: "origin/dev", Ahead: 2, Behind: 1}, "dev, 2 ahead and 1 behind origin/dev"},
+		{gitutil.Status{}, "detached HEAD, no upstream"},
+	}
+
+	for _, tt := range tests {
+		if got := describeRepoStatus(tt.st); got != tt.want {
+			t.Errorf("describeRepoStatus(%+v) = %q, want %q", tt.st, got, tt.want)
+		}
+	}
+}
+
+func TestPrune(t *testing.T) {
+	tmpDir := t.TempDir()
+	target := filepath.Join(tmpDir, "target")
+	stale := filepath.Join(tmpDir, "repo", "nvim", "old.lua")
+
+	for _, dir := range []string{target, filepath.Dir(stale)} {
+		if err := os.MkdirAll(dir, 0750); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.WriteFile(stale, []byte("old"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := &config.Config{
+		Version:    3,
+		BackupRoot: filepath.Join(tmpDir, "repo"),
+		Applications: []config.Application{{
+			Name:    "nvim",
+			Entries: []config.SubEntry{{Name: "config", Backup: "./nvim", Targets: map[string]string{"linux": target}}},
+		}},
+	}
+	mgr := manager.New(cfg, &platform.Platform{OS: platform.OSLinux})
+
+	var out bytes.Buffer
+	if err := prune(&out, strings.NewReader("n\n"), mgr, false); err != nil {
+		t.Fatalf("prune() error = %v", err)
+	}
+	if !strings.Contains(out.String(), "[stale] nvim/config: "+stale) || !strings.Contains(out.String(), "Nothing removed") {
+		t.Errorf("declined prune output = %q", out.String())
+	}
+	if _, err := os.Stat(stale); err != nil {
+		t.Fatal("declined prune removed the file")
+	}
+
+	out.Reset()
+	if err := prune(&out, strings.NewReader("y\n"), mgr, false); err != nil {
+		t.Fatalf("prune() error = %v", err)
+	}
+	if !strings.Contains(out.String(), "Removed: "+stale) {
+		t.Errorf("confirmed prune output = %q", out.String())
+	}
+	if _, err := os.Stat(stale); !errors.Is(err, os.ErrNotExist) {
+		t.Error("confirmed prune kept the file")
+	}
+
+	out.Reset()
+	if err := prune(&out, strings.NewReader(""), mgr, true); err != nil {
+		t.Fatalf("prune() error = %v", err)
+	}
+	if !strings.Contains(out.String(), "Nothing to prune") {
+		t.Errorf("second prune output = %q", out.String())
+	}
+}
+
+func TestStashListAndPop(t *testing.T) {
+	store := stash.New(t.TempDir())
+	rc := filepath.Join(t.TempDir(), ".zshrc")
+	if err := os.WriteFile(rc, []byte("edited"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	var out bytes.Buffer
+	if err := stashList(&out, store); err != nil || !strings.Contains(out.String(), "No stashes") {
+		t.Errorf("stashList() on an empty store = %q, %v", out.String(), err)
+	}
+
+	st, err := store.Push([]stash.File{{App: "zsh", Entry: "rc", Path: rc}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Remove(rc); err != nil {
+		t.Fatal(err)
+	}
+
+	out.Reset()
+	if err := stashList(&out, store); err != nil || !strings.Contains(out.String(), st.ID+"  1 file(s)") || !strings.Contains(out.String(), "zsh/rc: "+rc) {
+		t.Errorf("stashList() = %q, %v", out.String(), err)
+	}
+
+	origDryRun := dryRun
+	dryRun = true
+	t.Cleanup(func() { dryRun = origDryRun })
+
+	out.Reset()
+	if err := stashPop(&out, store, ""); err != nil || !strings.Contains(out.String(), "Would restore 1 file(s) from stash "+st.ID) {
+		t.Errorf("dry-run stashPop() = %q, %v", out.String(), err)
+	}
+	if _, err := os.Stat(rc); !errors.Is(err, os.ErrNotExist) {
+		t.Error("dry-run stashPop() restored the file")
+	}
+
+	dryRun = false
+	out.Reset()
+	if err := stashPop(&out, store, st.ID); err != nil || !strings.Contains(out.String(), "Restored 1 file(s)") {
+		t.Errorf("stashPop() = %q, %v", out.String(), err)
+	}
+	if data, err := os.ReadFile(rc); err != nil || string(data) != "edited" { //nolint:gosec // test file
+		t.Errorf(".zshrc after stashPop() = %q, %v", data, err)
+	}
+}
+
+// --- exitCode ---
+
+func TestExitCode(t *testing.T) {
+	badYAML := t.TempDir()
+	if err := os.WriteFile(filepath.Join(badYAML, "tidydots.yaml"), []byte("version: [\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	loadConfigFrom := func(dir string) func() error {
+		return func() error {
+			orig := configDir
+			configDir = dir
+			defer func() { configDir = orig }()
+
+			_, _, _, err := loadConfig()
+			return err
+		}
+	}
+
+	restoreFailing := func(entryErr error) func() error {
+		return func() error {
+			return runRestoreWithManager(&mockRestorer{err: errors.Join(&manager.EntryError{App: "a", Entry: "b", Err: entryErr})}, nil)
+		}
+	}
+
+	tests := []struct {
+		name string
+		run  func() error
+		want int
+	}{
+		{"success", func() error { return runRestoreWithManager(&mockRestorer{}, nil) }, exitOK},
+		{"missing tidydots.yaml", loadConfigFrom(t.TempDir()), exitConfig},
+		{"invalid tidydots.yaml", loadConfigFrom(badYAML), exitConfig},
+		{"failed entry", restoreFailing(errors.New("boom")), exitFailure},
+		{"permission denied", restoreFailing(manager.NewPathError("create symlink", "/etc/hosts", os.ErrPermission)), exitEnvironment},
+		{"missing git", func() error {
+			return packages.NewManager(&packages.Config{}, platform.OSLinux, false, false).WithRunner(cmdexec.NewStubRunner()).CheckGit()
+		}, exitEnvironment},
+		{"invalid flag value", func() error { _, err := parseStale("soon"); return err }, exitFailure},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.run()
+			if got := exitCode(err); got != tt.want {
+				t.Errorf("exitCode(%v) = %d, want %d", err, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestExitCode_Interrupted(t *testing.T) {
+	if runtime.GOOS == platform.OSWindows {
+		t.Skip("os.Interrupt cannot be sent to a process on Windows")
+	}
+
+	// The run records the cancellation in its results and returns nil, as
+	// install does.
+	err := runWithCancellation(func(ctx context.Context) error {
+		self, err := os.FindProcess(os.Getpid())
+		if err != nil {
+			return err
+		}
+
+		if err := self.Signal(os.Interrupt); err != nil {
+			return err
+		}
+
+		<-ctx.Done()
+
+		return nil
+	})
+
+	if got := exitCode(err); got != exitInterrupted {
+		t.Errorf("exitCode(%v) = %d, want %d", err, got, exitInterrupted)
+	}
+}
+
+func TestCheckPlanReport(t *testing.T) {
+	origReport, origDryRun := planReport, dryRun
+	t.Cleanup(func() { planReport, dryRun = origReport, origDryRun })
+
+	planReport, dryRun = "plan.txt", false
+	if err := checkPlanReport(); err == nil {
+		t.Error("checkPlanReport() = nil without --dry-run, want an error")
+	}
+
+	dryRun = true
+	if err := checkPlanReport(); err != nil {
+		t.Errorf("checkPlanReport() = %v with --dry-run, want nil", err)
+	}
+}
+
+func TestWritePlan(t *testing.T) {
+	report := &manager.Report{Operation: "restore", Entries: []manager.EntryResult{
+		{App: "zsh", Entry: "rc", Action: manager.ActionRestored, Steps: []manager.Step{
+			{Op: manager.StepLink, Source: "/repo/zsh/.zshrc", Target: "/home/u/.zshrc", Conflict: manager.ConflictMerge},
+		}},
+		{App: "git", Entry: "config", Action: manager.ActionSkipped, Detail: "filtered out"},
+		{App: "nvim", Entry: "config", Action: manager.ActionFailed, Err: errors.New("boom")},
+	}}
+
+	dir := t.TempDir()
+
+	textPath := filepath.Join(dir, "plan.txt")
+	if err := writePlan(textPath, runPlan(report)); err != nil {
+		t.Fatalf("writePlan(text) error = %v", err)
+	}
+
+	text, err := os.ReadFile(textPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, want := range []string{"tidydots restore plan", "zsh/rc", "/home/u/.zshrc", "merge", "git/config", "filtered out", "nvim/config", "boom"} {
+		if !strings.Contains(string(text), want) {
+			t.Errorf("text plan missing %q:\n%s", want, text)
+		}
+	}
+
+	jsonPath := filepath.Join(dir, "plan.json")
+	if err := writePlan(jsonPath, installPlan([]packages.InstallResult{
+		{Package: "ripgrep", Method: "pacman", Success: true, Message: "Would run: sudo pacman -S --noconfirm ripgrep"},
+		{Package: "fd", Skipped: true, Message: "no installation method for this platform"},
+	})); err != nil {
+		t.Fatalf("writePlan(json) error = %v", err)
+	}
+
+	data, err := os.ReadFile(jsonPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var got plan
+	if err := json.Unmarshal(data, &got); err != nil {
+		t.Fatalf("plan.json is not JSON: %v\n%s", err, data)
+	}
+
+	if got.Operation != "install" || len(got.Packages) != 2 {
+		t.Fatalf("plan = %+v, want an install plan with 2 packages", got)
+	}
+
+	if got.Packages[0].Result != planOK || got.Packages[0].Plan != "Would run: sudo pacman -S --noconfirm ripgrep" {
+		t.Errorf("ripgrep = %+v", got.Packages[0])
+	}
+
+	if got.Packages[1].Result != planSkipped {
+		t.Errorf("fd result = %q, want %q", got.Packages[1].Result, planSkipped)
+	}
+}
+
+func TestCheckPlanFormat(t *testing.T) {
+	origFormat, origDryRun, origVerbose, origQuiet := planFormat, dryRun, verbose, quiet
+	t.Cleanup(func() { planFormat, dryRun, verbose, quiet = origFormat, origDryRun, origVerbose, origQuiet })
+
+	planFormat, dryRun, verbose, quiet = listFormatJSON, false, false, false
+	if err := checkPlanFormat(); err == nil {
+		t.Error("checkPlanFormat() = nil for json without --dry-run, want an error")
+	}
+
+	dryRun, verbose = true, true
+	if err := checkPlanFormat(); err == nil {
+		t.Error("checkPlanFormat() = nil for json with --verbose, want an error")
+	}
+
+	verbose = false
+	if err := checkPlanFormat(); err != nil || !quiet {
+		t.Errorf("checkPlanFormat() = %v, quiet = %v; want nil and quiet on", err, quiet)
+	}
+
+	planFormat = "yaml"
+	if err := checkPlanFormat(); err == nil {
+		t.Error("checkPlanFormat() = nil for yaml, want an error")
+	}
+}
+
+func TestPrintPlan(t *testing.T) {
+	report := &manager.Report{Operation: "restore", Entries: []manager.EntryResult{
+		{App: "zsh", Entry: "rc", Action: manager.ActionRestored, Steps: []manager.Step{
+			{Op: manager.StepLink, Source: "/repo/zsh/.zshrc", Target: "/home/u/.zshrc", Conflict: manager.ConflictMerge},
+			{Op: manager.StepLink, Source: "/repo/zsh/.zshenv", Target: "/home/u/.zshenv", Conflict: manager.ConflictUnchanged},
+		}},
+		{App: "zsh", Entry: "fonts", Action: manager.ActionRestored, Steps: []manager.Step{
+			{Op: manager.StepCopy, Source: "/repo/fonts", Target: "/home/u/.fonts", Conflict: manager.ConflictNone, Bytes: 3 << 10},
+			{Op: manager.StepRun, Source: "fc-cache -f", Conflict: manager.ConflictNone, Skipped: "offline mode"},
+		}},
+		{App: "docker", Entry: "enable", Action: manager.ActionSetUp, Steps: []manager.Step{
+			{Op: manager.StepRun, Source: "systemctl enable docker", Conflict: manager.ConflictNone},
+		}},
+		{App: "git", Entry: "config", Action: manager.ActionSkipped, Detail: "requires sudo"},
+	}}
+
+	var buf bytes.Buffer
+	printPlan(&buf, runPlan(report))
+
+	want := `
+Plan (dry run):
+
+zsh
+  link  rc     /repo/zsh/.zshrc -> /home/u/.zshrc  (merge)
+  copy  fonts  /repo/fonts -> /home/u/.fonts       (none, 3.0 KiB)
+  skip  fonts  fc-cache -f                         (offline mode)
+
+docker
+  run  enable  systemctl enable docker  (none)
+
+git
+  skip  config  requires sudo
+
+Plan: 1 link, 1 copy (3.0 KiB), 1 run, 1 unchanged, 1 skipped
+`
+	if got := buf.String(); got != want {
+		t.Errorf("printPlan() =\n%s\nwant\n%s", got, want)
+	}
+
+	if got := formatBytes(512); got != "512 B" {
+		t.Errorf("formatBytes(512) = %q, want 512 B", got)
+	}
+
+	if got := formatBytes(5 << 20); got != "5.0 MiB" {
+		t.Errorf("formatBytes(5 MiB) = %q, want 5.0 MiB", got)
+	}
+}
+
+func TestCheckSelect(t *testing.T) {
+	origSelect, origExact, origInteractive := selectApps, selectExact, interactive
+	t.Cleanup(func() { selectApps, selectExact, interactive = origSelect, origExact, origInteractive })
+
+	selectApps, selectExact, interactive = nil, true, false
+	if err := checkSelect(); err == nil {
+		t.Error("checkSelect() = nil for --exact without --select, want an error")
+	}
+
+	selectApps = []string{"zsh"}
+	if err := checkSelect(); err != nil {
+		t.Errorf("checkSelect() = %v, want nil", err)
+	}
+
+	interactive = true
+	if err := checkSelect(); err == nil {
+		t.Error("checkSelect() = nil with --interactive, want an error")
+	}
+}
+
+func TestApplySelect(t *testing.T) {
+	origSelect, origExact := selectApps, selectExact
+	t.Cleanup(func() { selectApps, selectExact = origSelect, origExact })
+
+	selectApps, selectExact = []string{"ZSH", "fish"}, false
+
+	cfg := &config.Config{Applications: []config.Application{{Name: "zsh"}, {Name: "zsh-plugins"}, {Name: "neovim"}}}
+	mgr := manager.New(cfg, &platform.Platform{OS: platform.OSLinux})
+
+	var out bytes.Buffer
+	applySelect(&out, mgr)
+
+	if len(cfg.Applications) != 2 || cfg.Applications[0].Name != "zsh" || cfg.Applications[1].Name != "zsh-plugins" {
+		t.Errorf("applications = %+v, want zsh and zsh-plugins", cfg.Applications)
+	}
+
+	if got := out.String(); !strings.Contains(got, `--select "fish" matches no application`) || strings.Contains(got, "ZSH") {
+		t.Errorf("warnings = %q, want one for fish only", got)
+	}
+}
+
+func TestApplyResume(t *testing.T) {
+	origResume, origInteractive := resume, interactive
+	t.Cleanup(func() { resume, interactive = origResume, origInteractive })
+
+	resume, interactive = true, true
+	if err := checkResume(); err == nil {
+		t.Error("checkResume() = nil with --interactive, want an error")
+	}
+
+	interactive = false
+
+	cfg := &config.Config{BackupRoot: t.TempDir()}
+	mgr := manager.New(cfg, &platform.Platform{OS: platform.OSLinux, Hostname: "host"})
+
+	if err := mgr.InitStateStore(); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = mgr.Close() })
+
+	var out bytes.Buffer
+	applyResume(&out, mgr, state.OpBackup)
+
+	if !mgr.Resume || !strings.Contains(out.String(), "No interrupted backup to resume") {
+		t.Errorf("Resume = %v, output %q; want resuming with nothing to resume", mgr.Resume, out.String())
+	}
+
+	resume = false
+	out.Reset()
+	applyResume(&out, mgr, state.OpBackup)
+
+	if mgr.Resume || out.Len() != 0 {
+		t.Errorf("Resume = %v, output %q; want a plain run and no output", mgr.Resume, out.String())
+	}
+}
+
+func TestQuiet(t *testing.T) {
+	origQuiet, origVerbose := quiet, verbose
+	t.Cleanup(func() { quiet, verbose = origQuiet, origVerbose })
+
+	quiet, verbose = true, true
+	if err := checkQuiet(); err == nil {
+		t.Error("checkQuiet() = nil with --verbose, want an error")
+	}
+
+	verbose = false
+	if err := checkQuiet(); err != nil {
+		t.Errorf("checkQuiet() = %v, want nil", err)
+	}
+
+	var out bytes.Buffer
+	if infoOut(&out) != io.Discard {
+		t.Error("infoOut() does not discard with --quiet")
+	}
+
+	// The error still reaches cobra, which prints it.
+	sentinel := errors.New("backup error")
+	if err := runBackupWithManager(&mockBackuper{err: sentinel}); !errors.Is(err, sentinel) {
+		t.Errorf("runBackupWithManager() error = %v, want %v", err, sentinel)
+	}
+
+	printInstallFailures(&out, []packages.InstallResult{
+		{Package: "a", Message: "Installed via pacman", Success: true},
+		{Package: "b", Message: "Skipped: offline", Success: true, Skipped: true},
+		{Package: "c", Message: "No installation method available"},
+	})
+
+	if got, want := out.String(), "[error] c: No installation method available\n"; got != want {
+		t.Errorf("printInstallFailures() = %q, want %q", got, want)
+	}
+
+	quiet = false
+	if infoOut(&out) != &out {
+		t.Error("infoOut() does not return w without --quiet")
+	}
+}
+
+// --- add ---
+
+func TestRunAdd(t *testing.T) {
+	const current = `version: 3
+applications:
+  - name: mykitty
+    entries:
+      - name: config
+        backup: ./kitty
+        targets:
+          linux: ~/.config/kitty
+`
+
+	tests := []struct {
+		name     string
+		preset   string
+		wantErr  string
+		wantOut  []string
+		wantApps string
+	}{
+		{name: "adds the preset", preset: "NeoVim", wantOut: []string{"Added neovim to", "~/.config/nvim", "Neovim.Neovim"}, wantApps: "mykitty neovim"},
+		{name: "unknown preset", preset: "emacs", wantErr: "available:", wantApps: "mykitty"},
+		{name: "colliding targets", preset: "kitty", wantErr: "collision", wantApps: "mykitty"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			if err := os.WriteFile(filepath.Join(dir, "tidydots.yaml"), []byte(current), 0o600); err != nil {
+				t.Fatal(err)
+			}
+
+			cmd := newAddCmd() // registering the flags resets them
+
+			origDir, origPreset, origList, origOS := configDir, addPreset, addListPresets, osOverride
+			configDir, addPreset, addListPresets, osOverride = dir, tt.preset, false, "linux"
+			t.Cleanup(func() { configDir, addPreset, addListPresets, osOverride = origDir, origPreset, origList, origOS })
+
+			var out bytes.Buffer
+			cmd.SetOut(&out)
+
+			err := runAdd(cmd, nil)
+			if tt.wantErr == "" && err != nil {
+				t.Fatalf("runAdd() error = %v", err)
+			}
+
+			if tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)) {
+				t.Fatalf("runAdd() error = %v, want one containing %q", err, tt.wantErr)
+			}
+
+			for _, want := range tt.wantOut {
+				if !strings.Contains(out.String(), want) {
+					t.Errorf("output missing %q:\n%s", want, out.String())
+				}
+			}
+
+			cfg, err := config.Load(filepath.Join(dir, "tidydots.yaml"))
+			if err != nil {
+				t.Fatalf("config does not load after add: %v", err)
+			}
+
+			var got []string
+			for _, app := range cfg.Applications {
+				got = append(got, app.Name)
+			}
+
+			if strings.Join(got, " ") != tt.wantApps {
+				t.Errorf("applications = %q, want %q", strings.Join(got, " "), tt.wantApps)
+			}
+		})
+	}
+}
+
+func TestRunAdd_ListPresets(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "tidydots.yaml"), []byte("version: 3\napplications: []\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	cmd := newAddCmd() // registering the flags resets them
+
+	origDir, origPreset, origList := configDir, addPreset, addListPresets
+	configDir, addPreset, addListPresets = dir, "", true
+	t.Cleanup(func() { configDir, addPreset, addListPresets = origDir, origPreset, origList })
+
+	var out bytes.Buffer
+	cmd.SetOut(&out)
+
+	if err := runAdd(cmd, nil); err != nil {
+		t.Fatalf("runAdd(--list-presets) error = %v", err)
+	}
+
+	if !strings.Contains(out.String(), "neovim") || !strings.Contains(out.String(), "built-in") {
+		t.Errorf("preset list = %q, want neovim listed as built-in", out.String())
+	}
+
+	addListPresets = false
+	if err := runAdd(cmd, nil); err == nil {
+		t.Error("runAdd() = nil without --preset, want an error")
+	}
+}
@@ -0,0 +1,37 @@
+package main
+
+import (
+	"context"
+	"errors"
+
+	"github.com/AntoineGS/tidydots/internal/config"
+	"github.com/AntoineGS/tidydots/internal/manager"
+	"github.com/AntoineGS/tidydots/internal/packages"
+)
+
+// Exit codes of the tidydots command, documented in docs/cli/reference.md.
+const (
+	exitOK          = 0
+	exitFailure     = 1   // some entries or packages failed, or another error
+	exitConfig      = 2   // tidydots.yaml or the app config is missing or invalid
+	exitEnvironment = 3   // missing privileges or a missing tool such as git
+	exitInterrupted = 130 // canceled with Ctrl+C (128 + SIGINT)
+)
+
+// exitCode maps the error a command returned to the code tidydots exits
+// with. An interruption wins over everything else, and an environment
+// problem over the entries it made fail.
+func exitCode(err error) int {
+	switch {
+	case err == nil:
+		return exitOK
+	case errors.Is(err, context.Canceled):
+		return exitInterrupted
+	case errors.Is(err, config.ErrConfigNotFound), errors.Is(err, config.ErrInvalidConfig):
+		return exitConfig
+	case errors.Is(err, manager.ErrMissingPrivileges), errors.Is(err, packages.ErrGitNotFound):
+		return exitEnvironment
+	default:
+		return exitFailure
+	}
+}
@@ -0,0 +1,117 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/AntoineGS/tidydots/internal/bundle"
+	"github.com/AntoineGS/tidydots/internal/config"
+	tmpl "github.com/AntoineGS/tidydots/internal/template"
+	"github.com/spf13/cobra"
+)
+
+var (
+	exportOutput string
+	exportBundle string
+)
+
+func newExportCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "export <application-name...>",
+		Short: "Write selected applications to a standalone tidydots.yaml",
+		Long: `Extract one or more applications, with their packages and when filters,
+into a standalone tidydots.yaml for sharing. The file is written to stdout,
+or to --output.
+
+With --bundle, the config is written together with every backup its entries
+reference, resolving ~ and paths outside the config directory, to a new
+directory, or to a tar.gz archive when the name ends in .tar.gz or .tgz.
+Backup paths in the bundle are rewritten to be relative to it, so the bundle
+is self-contained: 'tidydots import <bundle>' sets it up on another machine.
+Without application names, --bundle exports them all.`,
+		Args: cobra.ArbitraryArgs,
+		RunE: runExport,
+	}
+
+	cmd.Flags().StringVar(&exportOutput, "output", "", "Write the exported config to this file instead of stdout")
+	cmd.Flags().StringVar(&exportBundle, "bundle", "", "Write the config and its backups to this new directory or .tar.gz archive")
+
+	return cmd
+}
+
+func runExport(_ *cobra.Command, args []string) error {
+	if exportBundle != "" && exportOutput != "" {
+		return fmt.Errorf("--bundle and --output cannot be combined")
+	}
+
+	if exportBundle == "" && len(args) == 0 {
+		return fmt.Errorf("name the applications to export, or use --bundle to export them all")
+	}
+
+	cfg, plat, _, err := loadConfig()
+	if err != nil {
+		return err
+	}
+
+	if exportBundle != "" && len(args) == 0 {
+		for _, app := range cfg.Applications {
+			args = append(args, app.Name)
+		}
+	}
+
+	exported, err := config.ExportApplications(cfg, args)
+	if err != nil {
+		return err
+	}
+
+	if exportBundle != "" {
+		// The bundle's templates still render with the config's vars.
+		exported.Vars = cfg.Vars
+		exported.BackupRoot = cfg.BackupRoot
+
+		engine := tmpl.NewEngine(tmpl.NewContextFromPlatform(plat).WithConfig(cfg))
+
+		backups, err := bundle.Export(exported, func(path string) string {
+			return config.ResolveBackupPath(path, cfg.BackupRoot, plat.EnvVars, engine)
+		}, exportBundle)
+		if err != nil {
+			return err
+		}
+
+		bundled := 0
+
+		for _, b := range backups {
+			if b.Missing {
+				fmt.Fprintf(os.Stderr, "Warning: %s/%s: backup %s does not exist; back it up before exporting to include it\n",
+					b.App, b.Entry, b.From)
+
+				continue
+			}
+
+			bundled++
+		}
+
+		fmt.Fprintf(os.Stderr, "Exported %d application(s) and %d backup(s) to %s\n",
+			len(exported.Applications), bundled, exportBundle)
+
+		return nil
+	}
+
+	data, err := config.Marshal(exported)
+	if err != nil {
+		return fmt.Errorf("encoding exported config: %w", err)
+	}
+
+	if exportOutput == "" {
+		_, err := os.Stdout.Write(data)
+		return err
+	}
+
+	if err := os.WriteFile(exportOutput, data, 0600); err != nil {
+		return fmt.Errorf("writing %s: %w", exportOutput, err)
+	}
+
+	fmt.Fprintf(os.Stderr, "Exported %d application(s) to %s\n", len(exported.Applications), exportOutput)
+
+	return nil
+}
@@ -0,0 +1,177 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+
+	"github.com/AntoineGS/tidydots/internal/bundle"
+	"github.com/AntoineGS/tidydots/internal/config"
+	"github.com/spf13/cobra"
+)
+
+var importOnConflict string
+
+func newImportCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "import <file-or-bundle>",
+		Short: "Merge the applications of another tidydots.yaml into this config",
+		Long: `Read a tidydots.yaml, such as one written by 'tidydots export', validate it,
+and add its applications to the current config. New applications go where
+newly added ones always go: default_include when set, otherwise tidydots.yaml.
+
+--on-conflict decides what happens when an application name already exists:
+error (the default) aborts without changing anything, skip keeps the current
+application, and overwrite replaces it in place. With --dry-run the result is
+reported but nothing is written.
+
+A bundle written by 'tidydots export --bundle', a directory or a .tar.gz
+archive, is imported with its backups: they are copied into the config
+directory at the paths the imported entries name. A backup already there is
+a conflict unless --on-conflict=overwrite. The bundle's vars that this config
+lacks are added too.`,
+		Args: cobra.ExactArgs(1),
+		RunE: runImport,
+	}
+
+	cmd.Flags().StringVar(&importOnConflict, "on-conflict", string(config.MergeError), "How to handle existing application names: error, skip or overwrite")
+
+	return cmd
+}
+
+func runImport(cmd *cobra.Command, args []string) error {
+	strategy, err := config.ParseMergeStrategy(importOnConflict)
+	if err != nil {
+		return fmt.Errorf("--on-conflict: %w", err)
+	}
+
+	cfg, _, configFile, err := loadConfig()
+	if err != nil {
+		return err
+	}
+
+	source, bundleDir := args[0], ""
+
+	if info, err := os.Stat(source); bundle.IsArchive(source) || err == nil && info.IsDir() {
+		dir, cleanup, err := bundle.Open(source)
+		if err != nil {
+			return err
+		}
+		defer cleanup()
+
+		source, bundleDir = filepath.Join(dir, bundle.ConfigName), dir
+	}
+
+	incoming, err := config.Load(source)
+	if err != nil {
+		return fmt.Errorf("loading %s: %w", args[0], err)
+	}
+
+	merged, conflicts, mergeErr := config.MergeConfigs(cfg, incoming, strategy)
+
+	out := cmd.OutOrStdout()
+
+	conflicted := make(map[string]bool, len(conflicts))
+	for _, c := range conflicts {
+		conflicted[c.Name] = true
+	}
+
+	if mergeErr == nil {
+		for _, app := range incoming.Applications {
+			if !conflicted[app.Name] {
+				fmt.Fprintf(out, "[added] %s\n", app.Name)
+			}
+		}
+	}
+
+	for _, c := range conflicts {
+		switch strategy {
+		case config.MergeSkip:
+			fmt.Fprintf(out, "[skipped] %s: already exists\n", c.Name)
+		case config.MergeOverwrite:
+			fmt.Fprintf(out, "[overwritten] %s\n", c.Name)
+		case config.MergeError:
+			fmt.Fprintf(out, "[conflict] %s: already exists\n", c.Name)
+		}
+	}
+
+	if mergeErr != nil {
+		return fmt.Errorf("%w (use --on-conflict=skip or --on-conflict=overwrite)", mergeErr)
+	}
+
+	imported := len(incoming.Applications)
+	if strategy == config.MergeSkip {
+		imported -= len(conflicts)
+	}
+
+	if bundleDir != "" {
+		if err := installBundle(out, bundleDir, cfg, merged, incoming, conflicted, strategy); err != nil {
+			return err
+		}
+	}
+
+	if dryRun {
+		fmt.Fprintf(out, "\nDry run: would import %d application(s) into %s\n", imported, configFile)
+		return nil
+	}
+
+	if imported == 0 {
+		fmt.Fprintln(out, "\nNothing to import")
+		return nil
+	}
+
+	if err := config.SaveAtomic(merged, configFile); err != nil {
+		return fmt.Errorf("saving config: %w", err)
+	}
+
+	fmt.Fprintf(out, "\nImported %d application(s) into %s\n", imported, configFile)
+
+	return nil
+}
+
+// installBundle copies the backups of the applications imported from the
+// bundle in dir into the config directory, or checks that it can with
+// --dry-run, and adds the bundle's vars that merged lacks.
+func installBundle(out io.Writer, dir string, cfg, merged, incoming *config.Config, conflicted map[string]bool, strategy config.MergeStrategy) error {
+	var apps []config.Application
+
+	for _, app := range incoming.Applications {
+		if !conflicted[app.Name] || strategy == config.MergeOverwrite {
+			apps = append(apps, app)
+		}
+	}
+
+	backups, err := bundle.Install(dir, cfg.BackupRoot, apps, strategy == config.MergeOverwrite, dryRun)
+	if err != nil {
+		return err
+	}
+
+	for _, b := range backups {
+		if b.Missing {
+			fmt.Fprintf(out, "[missing] %s/%s: %s is not in the bundle\n", b.App, b.Entry, b.To)
+			continue
+		}
+
+		fmt.Fprintf(out, "[backup] %s\n", b.To)
+	}
+
+	merged.Vars = maps.Clone(merged.Vars)
+
+	for _, name := range slices.Sorted(maps.Keys(incoming.Vars)) {
+		if _, ok := merged.Vars[name]; ok {
+			continue
+		}
+
+		if merged.Vars == nil {
+			merged.Vars = make(map[string]string)
+		}
+
+		merged.Vars[name] = incoming.Vars[name]
+		fmt.Fprintf(out, "[var] %s\n", name)
+	}
+
+	return nil
+}
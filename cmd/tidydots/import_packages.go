@@ -0,0 +1,185 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/AntoineGS/tidydots/internal/config"
+	"github.com/AntoineGS/tidydots/internal/packages"
+	"github.com/AntoineGS/tidydots/internal/tui"
+	"github.com/spf13/cobra"
+)
+
+var (
+	importPkgsManager string
+	importPkgsFilter  string
+	importPkgsAll     bool
+)
+
+// listExplicitPackages asks a manager of this machine for the packages
+// installed explicitly with it; tests replace it.
+var listExplicitPackages = func(ctx context.Context, osType string, mgr packages.PackageManager) ([]packages.ExplicitPackage, error) {
+	return packages.NewManager(&packages.Config{}, osType, false, verbose).WithContext(ctx).ExplicitPackages(mgr)
+}
+
+// selectPackages lets the user pick which of names to import in the
+// terminal; tests replace it.
+var selectPackages = func(title string, names []string) ([]string, error) {
+	if !tui.IsTerminal() {
+		return nil, errors.New("picking packages requires a terminal; pass --all or --filter")
+	}
+
+	return tui.SelectPackages(title, names)
+}
+
+func newImportPackagesCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "import-packages --manager <manager>",
+		Short: "Generate applications from the packages installed on this machine",
+		Long: `Ask a package manager which packages were installed explicitly on this
+machine, rather than as dependencies, and add an application installing each
+of them to the config:
+
+  pacman   pacman -Qe
+  apt      apt-mark showmanual
+  brew     brew leaves --installed-on-request
+  winget   winget export
+  scoop    scoop export
+
+Each application is named after its package and only has a package.managers
+entry for the manager. Winget applications are named after the last part of
+the identifier, lowercased, e.g. git for Git.Git, unless that name is taken.
+Scoop buckets other than main and winget sources other than winget are kept.
+Packages already configured, by application name or by the manager's
+package name, are skipped.
+
+Without --all or --filter, a list of the packages opens to pick the ones to
+import. New applications go where newly added ones always go: default_include
+when set, otherwise tidydots.yaml. Run with --dry-run to print the proposed
+YAML without writing it.`,
+		Args: cobra.NoArgs,
+		RunE: runImportPackages,
+	}
+
+	cmd.Flags().StringVar(&importPkgsManager, "manager", "", "Package manager to import from: apt, brew, pacman, scoop or winget")
+	cmd.Flags().StringVar(&importPkgsFilter, "filter", "", "Import the packages whose name matches this regular expression")
+	cmd.Flags().BoolVar(&importPkgsAll, "all", false, "Import every package without asking")
+	_ = cmd.MarkFlagRequired("manager")
+
+	return cmd
+}
+
+func runImportPackages(cmd *cobra.Command, _ []string) error {
+	mgr := packages.PackageManager(importPkgsManager)
+	if !slices.Contains(packages.ExplicitManagers(), mgr) {
+		return fmt.Errorf("invalid --manager %q: must be one of %v", importPkgsManager, packages.ExplicitManagers())
+	}
+
+	if importPkgsAll && importPkgsFilter != "" {
+		return errors.New("--all cannot be combined with --filter")
+	}
+
+	var filter *regexp.Regexp
+
+	if importPkgsFilter != "" {
+		re, err := regexp.Compile(importPkgsFilter)
+		if err != nil {
+			return fmt.Errorf("invalid --filter: %w", err)
+		}
+
+		filter = re
+	}
+
+	cfg, plat, configFile, err := loadConfig()
+	if err != nil {
+		return err
+	}
+
+	explicit, err := listExplicitPackages(cmd.Context(), plat.OS, mgr)
+	if err != nil {
+		return err
+	}
+
+	out, errOut := cmd.OutOrStdout(), cmd.ErrOrStderr()
+
+	apps, skipped := packages.ImportApplications(cfg, mgr, explicit)
+	if len(skipped) > 0 {
+		fmt.Fprintf(errOut, "Skipping %d package(s) already configured: %s\n", len(skipped), strings.Join(skipped, ", "))
+	}
+
+	switch {
+	case filter != nil:
+		apps = slices.DeleteFunc(apps, func(app config.Application) bool {
+			return !filter.MatchString(importedPackage(app, mgr))
+		})
+
+	case !importPkgsAll && len(apps) > 0:
+		names := make([]string, len(apps))
+		for i, app := range apps {
+			names[i] = importedPackage(app, mgr)
+		}
+
+		picked, err := selectPackages(fmt.Sprintf("Import %s packages", mgr), names)
+		if err != nil {
+			return err
+		}
+
+		if picked == nil {
+			fmt.Fprintln(out, "Import cancelled")
+			return nil
+		}
+
+		apps = slices.DeleteFunc(apps, func(app config.Application) bool {
+			return !slices.Contains(picked, importedPackage(app, mgr))
+		})
+	}
+
+	if len(apps) == 0 {
+		fmt.Fprintln(out, "Nothing to import")
+		return nil
+	}
+
+	added := &config.Config{Version: cfg.Version, Applications: apps}
+
+	merged, _, err := config.MergeConfigs(cfg, added, config.MergeSkip)
+	if err != nil {
+		return err
+	}
+
+	if errs := config.ValidateConfig(merged); len(errs) > 0 {
+		return fmt.Errorf("generated config is invalid: %w", errors.Join(errs...))
+	}
+
+	if dryRun {
+		data, err := config.Marshal(added)
+		if err != nil {
+			return fmt.Errorf("encoding proposed config: %w", err)
+		}
+
+		_, err = out.Write(data)
+
+		return err
+	}
+
+	if err := config.SaveAtomic(merged, configFile); err != nil {
+		return fmt.Errorf("saving config: %w", err)
+	}
+
+	for _, app := range apps {
+		fmt.Fprintf(out, "[added] %s (%s %s)\n", app.Name, mgr, importedPackage(app, mgr))
+	}
+
+	fmt.Fprintf(out, "\nImported %d %s package(s) into %s\n", len(apps), mgr, configFile)
+
+	return nil
+}
+
+// importedPackage returns the package an application of
+// packages.ImportApplications installs with mgr.
+func importedPackage(app config.Application, mgr packages.PackageManager) string {
+	return app.Package.Managers[string(mgr)].PackageName
+}
@@ -0,0 +1,96 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/AntoineGS/tidydots/internal/config"
+	"github.com/spf13/cobra"
+)
+
+func newImportStowCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "import-stow <stow-dir>",
+		Short: "Generate applications from a GNU Stow directory",
+		Long: `Scan a GNU Stow directory and add one application per package to tidydots.yaml.
+Each package mirrors $HOME, so its paths are re-rooted onto ~: a directory
+such as nvim/.config/nvim becomes a folder entry for ~/.config/nvim, and
+loose files such as zsh/.zshrc become a files entry targeting ~. Shared
+directories (.config, .local/share, ...) are linked into, not replaced.
+The targets are set for this machine's OS, or the one --os names.
+
+Packages whose name is already an application are skipped. Run with
+--dry-run first to print the proposed YAML without writing it.`,
+		Args: cobra.ExactArgs(1),
+		RunE: runImportStow,
+	}
+}
+
+func runImportStow(cmd *cobra.Command, args []string) error {
+	cfg, plat, configFile, err := loadConfig()
+	if err != nil {
+		return err
+	}
+
+	apps, warnings, err := config.StowApplications(args[0], cfg.BackupRoot, plat.OS)
+	if err != nil {
+		return err
+	}
+
+	out, errOut := cmd.OutOrStdout(), cmd.ErrOrStderr()
+
+	for _, w := range warnings {
+		fmt.Fprintf(errOut, "Warning: %s\n", w)
+	}
+
+	merged, conflicts, err := config.MergeConfigs(cfg, &config.Config{Applications: apps}, config.MergeSkip)
+	if err != nil {
+		return err
+	}
+
+	skipped := make(map[string]bool, len(conflicts))
+	for _, c := range conflicts {
+		skipped[c.Name] = true
+		fmt.Fprintf(errOut, "Skipping %s: an application with this name already exists\n", c.Name)
+	}
+
+	added := &config.Config{Version: cfg.Version}
+
+	for _, app := range apps {
+		if !skipped[app.Name] {
+			added.Applications = append(added.Applications, app)
+		}
+	}
+
+	if len(added.Applications) == 0 {
+		fmt.Fprintln(out, "Nothing to import")
+		return nil
+	}
+
+	if errs := config.ValidateConfig(merged); len(errs) > 0 {
+		return fmt.Errorf("generated config is invalid: %w", errors.Join(errs...))
+	}
+
+	if dryRun {
+		data, err := config.Marshal(added)
+		if err != nil {
+			return fmt.Errorf("encoding proposed config: %w", err)
+		}
+
+		_, err = out.Write(data)
+
+		return err
+	}
+
+	if err := config.SaveAtomic(merged, configFile); err != nil {
+		return fmt.Errorf("saving config: %w", err)
+	}
+
+	for _, app := range added.Applications {
+		fmt.Fprintf(out, "[added] %s (%d entries)\n", app.Name, len(app.Entries))
+	}
+
+	fmt.Fprintf(out, "\nImported %d stow package(s) into %s\n", len(added.Applications), configFile)
+
+	return nil
+}
@@ -0,0 +1,141 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"runtime"
+	"slices"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/AntoineGS/tidydots/internal/config"
+	"github.com/AntoineGS/tidydots/internal/manager"
+	"github.com/AntoineGS/tidydots/internal/platform"
+	tmpl "github.com/AntoineGS/tidydots/internal/template"
+	"github.com/spf13/cobra"
+)
+
+func newInfoCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "info",
+		Short: "Summarize the parsed configuration and detected platform",
+		Long: `Load the configuration and detect the platform, then print what tidydots
+found: where its config lives, how many applications match this machine, the
+package managers in PATH and the templates of the matching entries. Nothing
+is restored, backed up or checked on the targets.`,
+		Args: cobra.NoArgs,
+		RunE: runInfo,
+	}
+}
+
+func runInfo(cmd *cobra.Command, _ []string) error {
+	cfg, plat, configFile, err := loadConfig()
+	if err != nil {
+		return err
+	}
+
+	mgr := manager.New(cfg, plat)
+
+	templates, err := mgr.TemplateFiles("", "", "")
+	if err != nil {
+		return err
+	}
+
+	available := platform.DetectAvailableManagers()
+
+	return writeInfo(cmd.OutOrStdout(), infoSummary{
+		cfg:        cfg,
+		plat:       plat,
+		configFile: configFile,
+		matching:   len(cfg.GetMatchingApplicationsWithLogger(tmpl.NewEngine(tmpl.NewContextFromPlatform(plat).WithConfig(cfg)), nil)),
+		enabled:    len(mgr.GetApplications()),
+		templates:  len(templates),
+		available:  available,
+		versions:   managerVersions(available),
+		supported:  platform.SupportedManagers(),
+	})
+}
+
+// managerVersions returns the version of each of managers that reports one.
+func managerVersions(managers []string) map[string]platform.Version {
+	versions := make(map[string]platform.Version, len(managers))
+
+	for _, mgr := range managers {
+		if v, err := platform.ManagerVersion(mgr); err == nil {
+			versions[mgr] = v
+		}
+	}
+
+	return versions
+}
+
+// infoSummary is what `tidydots info` reports.
+type infoSummary struct {
+	cfg        *config.Config
+	plat       *platform.Platform
+	configFile string
+	matching   int // applications whose when matches this machine
+	enabled    int // matching applications that are enabled
+	templates  int
+	available  []string
+	versions   map[string]platform.Version // of the available managers that report one
+	supported  []string
+}
+
+// writeInfo prints s as aligned key/value sections.
+func writeInfo(w io.Writer, s infoSummary) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+
+	fmt.Fprintln(tw, "Platform")
+	fmt.Fprintf(tw, "  OS\t%s\n", s.plat.OS)
+	fmt.Fprintf(tw, "  Distro\t%s\n", orNone(s.plat.Distro))
+	fmt.Fprintf(tw, "  Architecture\t%s/%s\n", runtime.GOOS, runtime.GOARCH)
+	fmt.Fprintf(tw, "  Hostname\t%s\n", orNone(s.plat.Hostname))
+	fmt.Fprintf(tw, "  User\t%s\n", orNone(s.plat.User))
+	fmt.Fprintf(tw, "  WSL\t%t\n", s.plat.IsWSL)
+	fmt.Fprintf(tw, "  Display\t%t\n", s.plat.HasDisplay)
+
+	fmt.Fprintln(tw)
+	fmt.Fprintln(tw, "Configuration")
+	fmt.Fprintf(tw, "  App config\t%s\n", config.AppConfigPath())
+	fmt.Fprintf(tw, "  Config directory\t%s\n", s.cfg.BackupRoot)
+	fmt.Fprintf(tw, "  Config file\t%s\n", s.configFile)
+	fmt.Fprintf(tw, "  Applications\t%d\n", len(s.cfg.Applications))
+	fmt.Fprintf(tw, "  On this machine\t%d (%d filtered out by when, %d of the rest disabled)\n",
+		s.enabled, len(s.cfg.Applications)-s.matching, s.matching-s.enabled)
+	fmt.Fprintf(tw, "  Templates\t%d\n", s.templates)
+
+	var unavailable []string
+
+	for _, mgr := range s.supported {
+		if !slices.Contains(s.available, mgr) {
+			unavailable = append(unavailable, mgr)
+		}
+	}
+
+	fmt.Fprintln(tw)
+	fmt.Fprintln(tw, "Package managers")
+	available := make([]string, 0, len(s.available))
+
+	for _, mgr := range s.available {
+		if v, ok := s.versions[mgr]; ok {
+			mgr += " " + v.String()
+		}
+
+		available = append(available, mgr)
+	}
+
+	fmt.Fprintf(tw, "  Available\t%s\n", orNone(strings.Join(available, ", ")))
+	fmt.Fprintf(tw, "  Unavailable\t%s\n", orNone(strings.Join(unavailable, ", ")))
+
+	return tw.Flush()
+}
+
+// orNone returns s, or "none" when s is empty.
+func orNone(s string) string {
+	if s == "" {
+		return "none"
+	}
+
+	return s
+}
@@ -0,0 +1,139 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"text/tabwriter"
+
+	"github.com/AntoineGS/tidydots/internal/config"
+	"github.com/AntoineGS/tidydots/internal/packages"
+	tmpl "github.com/AntoineGS/tidydots/internal/template"
+	"github.com/spf13/cobra"
+)
+
+// listedPackage is one package of list-packages, as printed by
+// writePackageList.
+type listedPackage struct {
+	Name        string `json:"name"`
+	Manager     string `json:"manager"` // empty when unavailable
+	State       string `json:"state"`   // packages.CheckInstalled, CheckMissing or CheckUnavailable
+	Version     string `json:"version,omitempty"`
+	Reason      string `json:"reason,omitempty"` // why an unavailable package cannot be installed
+	Description string `json:"description,omitempty"`
+	Filtered    bool   `json:"filtered"` // the package's own when is false, so install skips it
+}
+
+func runListPackages(cmd *cobra.Command, _ []string) error {
+	if listPkgsFormat != listFormatText && listPkgsFormat != listFormatJSON {
+		return fmt.Errorf("invalid --format %q: must be %q or %q", listPkgsFormat, listFormatText, listFormatJSON)
+	}
+
+	cfg, plat, _, err := loadConfig()
+	if err != nil {
+		return err
+	}
+
+	// Create template engine for when expression evaluation
+	tmplCtx := tmpl.NewContextFromPlatform(plat).WithConfig(cfg)
+	engine := tmpl.NewEngine(tmplCtx)
+
+	// Get filtered package entries
+	packageEntries := cfg.GetFilteredPackages(engine)
+	if len(packageEntries) == 0 && listPkgsFormat == listFormatText {
+		fmt.Println("No matching packages configured in tidydots.yaml")
+		return nil
+	}
+
+	// Filtered packages are listed too, so only their names are resolved.
+	pkgs := packages.FromApplications(packageEntries)
+	for i := range pkgs {
+		pkgs[i] = pkgs[i].ResolveNames(engine)
+	}
+
+	// Create package manager to determine install methods
+	pkgMgr := packages.NewManager(&packages.Config{
+		Packages:        pkgs,
+		DefaultManager:  packages.PackageManager(cfg.DefaultManager),
+		ManagerPriority: convertToPackageManagers(cfg.ManagerPriority),
+	}, plat.OS, false, verbose)
+
+	if err := pkgMgr.DetectManagers(cmd.Context()); err != nil {
+		return err
+	}
+
+	var results []packages.VersionResult
+
+	if err := runWithCancellation(func(ctx context.Context) error {
+		results = pkgMgr.WithContext(ctx).Versions(pkgMgr.Config.Packages)
+		return ctx.Err()
+	}); err != nil {
+		return err
+	}
+
+	listed := make([]listedPackage, len(results))
+	for i, r := range results {
+		pkg := pkgMgr.Config.Packages[i]
+
+		listed[i] = listedPackage{
+			Name:        pkg.Name,
+			State:       r.State,
+			Version:     r.Version,
+			Description: pkg.Description,
+			Filtered:    !config.EvaluateWhen(pkg.When, engine),
+		}
+
+		if r.State == packages.CheckUnavailable {
+			listed[i].Reason = pkgMgr.GetInstallabilityReason(pkg)
+		} else {
+			listed[i].Manager = r.Method
+		}
+	}
+
+	if listPkgsFormat == listFormatJSON {
+		return writePackageList(os.Stdout, listFormatJSON, listed)
+	}
+
+	fmt.Printf("Available package managers: %v\n\n", pkgMgr.Available)
+
+	return writePackageList(os.Stdout, listFormatText, listed)
+}
+
+// writePackageList writes the packages of list-packages to w, as a table or
+// as a JSON array.
+func writePackageList(w io.Writer, format string, listed []listedPackage) error {
+	if format == listFormatJSON {
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+
+		return enc.Encode(listed)
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "NAME\tMANAGER\tINSTALLED\tVERSION\tDESCRIPTION")
+
+	for _, p := range listed {
+		name, manager, installed, version := p.Name, p.Manager, "no", p.Version
+
+		if p.Filtered {
+			name += " (filtered)"
+		}
+
+		switch p.State {
+		case packages.CheckInstalled:
+			installed = "yes"
+		case packages.CheckUnavailable:
+			manager, installed = fmt.Sprintf("%s (%s)", packages.CheckUnavailable, p.Reason), "-"
+		}
+
+		if version == "" {
+			version = "-"
+		}
+
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\n", name, manager, installed, version, p.Description)
+	}
+
+	return tw.Flush()
+}
@@ -5,18 +5,24 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"io"
 	"log/slog"
 	"os"
 	"os/signal"
 	"path/filepath"
 	"runtime/pprof"
+	"slices"
+	"strconv"
+	"strings"
 	"syscall"
+	"time"
 
 	"github.com/AntoineGS/tidydots/internal/config"
 	"github.com/AntoineGS/tidydots/internal/manager"
 	"github.com/AntoineGS/tidydots/internal/packages"
 	"github.com/AntoineGS/tidydots/internal/platform"
 	"github.com/AntoineGS/tidydots/internal/preview"
+	"github.com/AntoineGS/tidydots/internal/state"
 	tmpl "github.com/AntoineGS/tidydots/internal/template"
 	"github.com/AntoineGS/tidydots/internal/tui"
 	"github.com/spf13/cobra"
@@ -24,19 +30,61 @@ import (
 
 var version = "dev"
 
+// Output formats of the list command.
+const (
+	listFormatText = "text"
+	listFormatJSON = "json"
+)
+
 var (
-	configDir   string // Override from --dir flag
-	osOverride  string
-	dryRun      bool
-	verbose     bool
-	interactive bool
-	noMerge     bool
-	forceDelete bool
-	forceRender bool
-	cpuProfile  string
-	logFile     *os.File
+	configDir         string // Override from --dir flag
+	osOverride        string
+	osHome            string
+	targetOS          string
+	forceCrossOS      bool
+	dryRun            bool
+	verbose           bool
+	interactive       bool
+	noMerge           bool
+	forceDelete       bool
+	forceRender       bool
+	skipVerify        bool
+	strictVerify      bool
+	symlinkCompat     string
+	restoreNotes      bool
+	listTree          bool
+	listAll           bool
+	listFormat        string
+	listPkgsFormat    string
+	backupStale       string
+	backupPrune       bool
+	assumeYes         bool
+	noSudo            bool
+	offline           bool
+	installJobs       int
+	restoreJobs       int
+	parallel          bool
+	installRetries    int
+	installRetryDelay time.Duration
+	installCheck      bool
+	cpuProfile        string
+	tuiTheme          string
+	tuiWidth          int
+	tuiHeight         int
+	logFile           *os.File
 )
 
+// maxJobs caps --jobs: past it, installs and restores mostly wait on each
+// other's disk and network use.
+const maxJobs = 16
+
+// parallelJobs is the --jobs that --parallel stands for.
+const parallelJobs = 4
+
+// detectPlatform detects the platform loadConfig returns. Tests replace it
+// to count detections.
+var detectPlatform = platform.Detect
+
 func main() {
 	rootCmd := &cobra.Command{
 		Use:     "tidydots",
@@ -54,6 +102,9 @@ Run 'tidydots init <path>' to set up the app configuration.
 Run without arguments to start the interactive TUI.`,
 		RunE: runInteractive,
 		PersistentPreRunE: func(_ *cobra.Command, _ []string) error {
+			if err := checkQuiet(); err != nil {
+				return err
+			}
 			if verbose {
 				logWriter := os.Stderr
 				// When running interactively (TUI), write logs to a file to avoid corrupting the display
@@ -94,10 +145,20 @@ Run without arguments to start the interactive TUI.`,
 
 	rootCmd.PersistentFlags().StringVarP(&configDir, "dir", "d", "", "Override configurations directory (ignores app config)")
 	rootCmd.PersistentFlags().StringVarP(&osOverride, "os", "o", "", "Override OS detection (linux or windows)")
+	rootCmd.PersistentFlags().StringVar(&osHome, "os-home", "", "Home directory paths expand to when --os is not this machine's OS")
+	rootCmd.PersistentFlags().BoolVar(&forceCrossOS, "force-cross-os", false, "Allow changes when --os is not this machine's OS")
 	rootCmd.PersistentFlags().BoolVarP(&dryRun, "dry-run", "n", false, "Show what would be done without making changes")
 	rootCmd.PersistentFlags().BoolVarP(&verbose, "verbose", "v", false, "Enable verbose output")
+	rootCmd.PersistentFlags().BoolVarP(&quiet, "quiet", "q", false, "Print only errors and results meant for scripts, nothing on success")
+	rootCmd.PersistentFlags().BoolVar(&noSudo, "no-sudo", false, "Never run sudo: drop it from package manager commands and skip entries and packages that require it")
+	rootCmd.PersistentFlags().BoolVar(&offline, "offline", false, "Skip everything that may need the network: package installs, repository clones and updates, and setup entries")
 	rootCmd.PersistentFlags().StringVar(&cpuProfile, "cpuprofile", "", "Write CPU profile to file (e.g. cpu.prof)")
 	_ = rootCmd.PersistentFlags().MarkHidden("cpuprofile")
+	rootCmd.PersistentFlags().StringVar(&tuiTheme, "theme", "", "Color theme of the TUI: dark, light, monochrome or auto (default from the app config, else auto)")
+	rootCmd.PersistentFlags().IntVar(&tuiWidth, "tui-width", 0, "Width the TUI starts at when the terminal's is unknown")
+	rootCmd.PersistentFlags().IntVar(&tuiHeight, "tui-height", 0, "Height the TUI starts at when the terminal's is unknown")
+	_ = rootCmd.PersistentFlags().MarkHidden("tui-width")
+	_ = rootCmd.PersistentFlags().MarkHidden("tui-height")
 
 	initCmd := &cobra.Command{
 		Use:   "init <path>",
@@ -113,21 +174,53 @@ The repo should contain a tidydots.yaml file with your path definitions.`,
 	restoreCmd := &cobra.Command{
 		Use:   "restore",
 		Short: "Restore configurations by creating symlinks",
-		Long:  `Restore configurations by creating symlinks from target locations to backup sources.`,
-		RunE:  runRestore,
+		Long: `Restore configurations by creating symlinks from target locations to backup sources.
+
+--jobs restores several applications at once; the entries of one application
+are still restored in order, after the applications its required_by names.
+Setup entries run one at a time, since their commands commonly call a package
+manager, and entries with sudo: true run last, one at a time, as always.
+
+A restore that is interrupted, or has failures, leaves a journal of the
+entries it restored; --resume skips those whose target is still there.`,
+		RunE: runRestore,
 	}
 	restoreCmd.Flags().BoolVarP(&interactive, "interactive", "i", false, "Run in interactive mode")
 	restoreCmd.Flags().BoolVar(&noMerge, "no-merge", false, "Disable merge mode, return error if target exists")
 	restoreCmd.Flags().BoolVar(&forceDelete, "force", false, "When combined with --no-merge, delete existing files without prompting")
 	restoreCmd.Flags().BoolVar(&forceRender, "force-render", false, "Force re-render of templates, skipping 3-way merge")
+	restoreCmd.Flags().BoolVar(&strictVerify, "strict-verify", false, "Fail entries whose backup does not match its .sha256 checksum")
+	restoreCmd.Flags().StringVar(&symlinkCompat, "symlink-compat", "", "How to link folders on Windows: symlink or junction (overrides symlink_compat)")
+	restoreCmd.Flags().StringVar(&planReport, "report", "", "With --dry-run, also write the planned actions to this file (.json for JSON, text otherwise)")
+	restoreCmd.Flags().StringVar(&planFormat, "format", listFormatText, "Output format of the --dry-run plan: text or json")
+	restoreCmd.Flags().StringVar(&targetOS, "target-os", "", "Restore the targets of another OS (linux or windows) on this machine, under --os-home")
+	restoreCmd.Flags().StringArrayVar(&selectApps, "select", nil, "Only restore applications whose name contains this (case-insensitive, repeatable)")
+	restoreCmd.Flags().BoolVar(&selectExact, "exact", false, "Match --select names against whole application names")
+	restoreCmd.Flags().BoolVar(&restoreNotes, "notes", false, "Print the notes of each restored application after the run")
+	restoreCmd.Flags().IntVarP(&restoreJobs, "jobs", "j", 0, "Number of applications to restore in parallel (default 1)")
+	restoreCmd.Flags().BoolVar(&parallel, "parallel", false, fmt.Sprintf("Restore %d applications in parallel (same as --jobs %d)", parallelJobs, parallelJobs))
+	restoreCmd.Flags().BoolVar(&resume, "resume", false, "Skip the entries an interrupted restore completed")
 
 	backupCmd := &cobra.Command{
 		Use:   "backup",
 		Short: "Backup configurations from target locations",
-		Long:  `Copy configuration files from target locations to backup directory.`,
-		RunE:  runBackup,
+		Long: `Copy configuration files from target locations to backup directory.
+--prune then removes the backed-up files of folder entries that no longer
+exist in the target, after asking for confirmation unless --yes is given.
+
+A backup that is interrupted, or has failures, leaves a journal of the
+entries it backed up; --resume skips those whose backup is still there.`,
+		RunE: runBackup,
 	}
 	backupCmd.Flags().BoolVarP(&interactive, "interactive", "i", false, "Run in interactive mode")
+	backupCmd.Flags().StringVar(&backupStale, "stale", "", "Only back up entries not backed up within this window (e.g. 30d, 2w, 12h)")
+	backupCmd.Flags().BoolVar(&backupPrune, "prune", false, "Remove backed-up files of folder entries that were deleted from the target")
+	backupCmd.Flags().BoolVarP(&assumeYes, "yes", "y", false, "Prune without asking for confirmation")
+	backupCmd.Flags().StringVar(&planReport, "report", "", "With --dry-run, also write the planned actions to this file (.json for JSON, text otherwise)")
+	backupCmd.Flags().StringVar(&planFormat, "format", listFormatText, "Output format of the --dry-run plan: text or json")
+	backupCmd.Flags().StringArrayVar(&selectApps, "select", nil, "Only back up applications whose name contains this (case-insensitive, repeatable)")
+	backupCmd.Flags().BoolVar(&selectExact, "exact", false, "Match --select names against whole application names")
+	backupCmd.Flags().BoolVar(&resume, "resume", false, "Skip the entries an interrupted backup completed")
 
 	listCmd := &cobra.Command{
 		Use:   "list",
@@ -135,23 +228,47 @@ The repo should contain a tidydots.yaml file with your path definitions.`,
 		Long:  `Display all configured paths and their targets for the current OS.`,
 		RunE:  runList,
 	}
+	listCmd.Flags().BoolVar(&listTree, "tree", false, "Show applications as a tree with each entry's type and target")
+	listCmd.Flags().BoolVar(&listAll, "all", false, "Include applications and entries disabled with enabled: false")
+	listCmd.Flags().StringVar(&listFormat, "format", listFormatText, "Output format: text or json")
 
 	installCmd := &cobra.Command{
 		Use:   "install [package-names...]",
 		Short: "Install packages using configured package managers",
 		Long: `Install packages from your configuration using the appropriate package manager.
 If no package names are provided, all matching packages will be installed.
-Packages are filtered based on their filters (os, hostname, user).`,
+Packages are filtered based on their filters (os, hostname, user).
+Packages are installed phase by phase, lowest phase first; --jobs lets
+packages of the same phase install in parallel. Git clones, installer, custom
+and URL installs run in parallel; package manager commands still run one at a
+time, since pacman, apt, dnf and the others lock their database.
+--install-retries retries
+failed installs, waiting --install-retry-delay before the first retry and
+twice as long before each next one (at most a minute).
+--check installs nothing: it reports each package as installed, missing or
+unavailable and fails when any is missing.`,
 		RunE: runInstall,
 	}
 	installCmd.Flags().BoolVarP(&interactive, "interactive", "i", false, "Run in interactive mode")
+	installCmd.Flags().BoolVar(&skipVerify, "skip-verify", false, "Skip sha256/size verification of URL downloads (emergencies only)")
+	installCmd.Flags().IntVarP(&installJobs, "jobs", "j", 0, "Number of packages of the same phase to install in parallel (default 1)")
+	installCmd.Flags().BoolVar(&parallel, "parallel", false, fmt.Sprintf("Install %d packages in parallel (same as --jobs %d)", parallelJobs, parallelJobs))
+	installCmd.Flags().IntVar(&installRetries, "install-retries", 0, "Number of times to retry a failed install")
+	installCmd.Flags().DurationVar(&installRetryDelay, "install-retry-delay", 2*time.Second, "Wait before the first retry of a failed install; doubles for each next retry")
+	installCmd.Flags().BoolVar(&installCheck, "check", false, "Report which packages are installed or missing without installing anything")
+	installCmd.Flags().StringVar(&planReport, "report", "", "With --dry-run, also write the planned commands to this file (.json for JSON, text otherwise)")
+	installCmd.MarkFlagsMutuallyExclusive("interactive", "check")
 
 	listPkgsCmd := &cobra.Command{
 		Use:   "list-packages",
 		Short: "List all configured packages",
-		Long:  `Display all configured packages and their installation methods for the current OS.`,
-		RunE:  runListPackages,
+		Long: `Display all configured packages of the current OS as a table of their
+install method, whether they are installed, the installed version and their
+description. Packages no available manager can install are listed as
+unavailable. --format json prints the same as a JSON array.`,
+		RunE: runListPackages,
 	}
+	listPkgsCmd.Flags().StringVar(&listPkgsFormat, "format", listFormatText, "Output format: text or json")
 
 	previewCmd := &cobra.Command{
 		Use:   "preview <path>",
@@ -166,10 +283,10 @@ The path can be a single .tmpl file or a directory containing .tmpl files.`,
 		RunE: runPreview,
 	}
 
-	rootCmd.AddCommand(initCmd, restoreCmd, backupCmd, listCmd, installCmd, listPkgsCmd, previewCmd)
+	rootCmd.AddCommand(initCmd, restoreCmd, backupCmd, listCmd, installCmd, listPkgsCmd, previewCmd, newVerifyCmd(), newVerifyBackupsCmd(), newExportCmd(), newImportCmd(), newImportStowCmd(), newImportPackagesCmd(), newRenderCmd(), newReposCmd(), newPackagesCmd(), newReportCmd(), newPinCmd(), newShowCmd(), newAddCmd(), newAddFromCmd(), newInfoCmd(), newStashCmd())
 
 	if err := rootCmd.Execute(); err != nil {
-		os.Exit(1)
+		os.Exit(exitCode(err))
 	}
 }
 
@@ -202,10 +319,14 @@ func runInit(_ *cobra.Command, args []string) error {
 	}
 
 	// Save app config
-	appCfg := &config.AppConfig{
-		ConfigDir: absPath,
+	// Keep preferences from an existing app config; only the path changes.
+	appCfg := &config.AppConfig{}
+	if existing, err := config.LoadAppConfig(); err == nil {
+		appCfg = existing
 	}
 
+	appCfg.ConfigDir = absPath
+
 	if err := config.SaveAppConfig(appCfg); err != nil {
 		return fmt.Errorf("saving app config: %w", err)
 	}
@@ -249,7 +370,7 @@ func loadConfig() (*config.Config, *platform.Platform, string, error) {
 
 	cfg.BackupRoot = cfgDir
 
-	plat := platform.Detect()
+	plat := detectPlatform()
 
 	if osOverride != "" {
 		if osOverride != platform.OSLinux && osOverride != platform.OSWindows {
@@ -258,92 +379,455 @@ func loadConfig() (*config.Config, *platform.Platform, string, error) {
 		plat = plat.WithOS(osOverride)
 	}
 
+	if targetOS != "" {
+		if targetOS != platform.OSLinux && targetOS != platform.OSWindows {
+			return nil, nil, "", fmt.Errorf("invalid --target-os: %s (must be 'linux' or 'windows')", targetOS)
+		}
+
+		if osOverride != "" {
+			return nil, nil, "", fmt.Errorf("--target-os cannot be combined with --os")
+		}
+
+		plat = plat.WithOS(targetOS)
+
+		if plat.CrossOS() && osHome == "" {
+			return nil, nil, "", fmt.Errorf("--target-os %s needs --os-home, the directory its home is mounted at on this machine", targetOS)
+		}
+	}
+
+	if osHome != "" {
+		if !plat.CrossOS() {
+			return nil, nil, "", fmt.Errorf("--os-home needs --os or --target-os set to another OS than this machine's")
+		}
+
+		plat = plat.WithHome(osHome)
+	}
+
 	// Paths are kept with ~ in the config for portability
 	// They will be expanded when needed for file operations
 
+	warnTargetCollisions(cfg, plat)
+
 	return cfg, plat, configFile, nil
 }
 
+// warnTargetCollisions prints a warning for every pair of entries that deploy
+// to the same path on this platform, since restoring both is undefined.
+func warnTargetCollisions(cfg *config.Config, plat *platform.Platform) {
+	engine := tmpl.NewEngine(tmpl.NewContextFromPlatform(plat).WithConfig(cfg))
+	apps := cfg.GetFilteredApplications(engine)
+
+	for _, c := range config.FindTargetCollisions(apps, plat.OS, plat.EnvVars, engine) {
+		fmt.Fprintf(os.Stderr, "Warning: %s\n", c)
+	}
+}
+
+// checkCrossOS refuses to run operation, which changes this machine, when the
+// OS is overridden to another one: paths then describe that OS, not this one.
+// Dry runs and --force-cross-os are allowed.
+func checkCrossOS(plat *platform.Platform, operation string) error {
+	if !plat.CrossOS() || dryRun || forceCrossOS {
+		return nil
+	}
+
+	return fmt.Errorf("refusing to %s for %s on another OS; preview it with --dry-run, or pass --force-cross-os", operation, plat.OS)
+}
+
 func createManager() (*manager.Manager, error) {
 	cfg, plat, _, err := loadConfig()
 	if err != nil {
 		return nil, err
 	}
 
-	fmt.Printf("Detected OS: %s\n", plat.OS)
-	fmt.Printf("Config directory: %s\n", cfg.BackupRoot)
+	fmt.Fprintf(infoOut(os.Stdout), "Detected OS: %s\n", plat.OS)
+	fmt.Fprintf(infoOut(os.Stdout), "Config directory: %s\n", cfg.BackupRoot)
+
+	return newManager(cfg, plat, infoOut(os.Stdout)), nil
+}
 
+// newManager builds a Manager from the command-line flags and opens its state
+// store, printing a warning to w if the store cannot be opened.
+func newManager(cfg *config.Config, plat *platform.Platform, w io.Writer) *manager.Manager {
 	mgr := manager.New(cfg, plat)
 	mgr.DryRun = dryRun
 	mgr.Verbose = verbose
 	mgr.NoMerge = noMerge
 	mgr.ForceDelete = forceDelete
 	mgr.ForceRender = forceRender
+	mgr.StrictVerify = strictVerify
+	mgr.NoSudo = noSudo
+	mgr.Offline = offline
+	mgr.Version = version
+
+	if appCfg, err := config.LoadAppConfig(); err == nil {
+		mgr.MaxHistory = appCfg.HistoryLimit()
+	}
 
-	// Initialize state store for template render tracking
+	// Initialize state store for template render tracking and operation history
 	if err := mgr.InitStateStore(); err != nil {
-		fmt.Printf("Warning: could not initialize template state store: %v\n", err)
+		fmt.Fprintf(w, "Warning: could not initialize state store: %v\n", err)
+	}
+
+	if quiet {
+		mgr = mgr.WithLogger(quietLogger())
 	}
 
-	return mgr, nil
+	return mgr
 }
 
 func runInteractive(_ *cobra.Command, _ []string) error {
+	sized, err := checkTUISize()
+	if err != nil {
+		return err
+	}
+
 	cfg, plat, configPath, err := loadConfig()
 	if err != nil {
 		return err
 	}
 
-	// Check if we're in a terminal
-	if !tui.IsTerminal() {
+	// Check if we're in a terminal; with a size hint the TUI can render
+	// without one, e.g. into a pipe for a snapshot.
+	if !sized && !tui.IsTerminal() {
 		return fmt.Errorf("interactive mode requires a terminal; use subcommands (restore, backup, list) for non-interactive use")
 	}
 
-	return tui.Run(cfg, plat, dryRun, configPath)
+	// The TUI can change this machine at any time, so a cross-OS session that
+	// was not forced is a dry run.
+	tuiDryRun := dryRun
+	if checkCrossOS(plat, "run the TUI") != nil {
+		fmt.Fprintf(os.Stderr, "--os %s is not this machine's OS; starting in dry-run mode (pass --force-cross-os to allow changes)\n", plat.OS)
+
+		tuiDryRun = true
+	}
+
+	return tui.Run(cfg, plat, tui.Options{
+		ConfigPath: configPath,
+		Version:    version,
+		DryRun:     tuiDryRun,
+		NoSudo:     noSudo,
+		Offline:    offline,
+		SkipVerify: skipVerify,
+		Theme:      tuiTheme,
+		Width:      tuiWidth,
+		Height:     tuiHeight,
+	})
+}
+
+// resolveJobs returns the number of jobs of a --jobs flag: jobs when set,
+// otherwise 1, or parallelJobs with --parallel. More than maxJobs is lowered
+// to it with a warning on w.
+func resolveJobs(w io.Writer, jobs int) (int, error) {
+	switch {
+	case jobs < 0:
+		return 0, fmt.Errorf("invalid --jobs %d: must not be negative", jobs)
+	case jobs == 0 && parallel:
+		return parallelJobs, nil
+	case jobs == 0:
+		return 1, nil
+	case jobs > maxJobs:
+		fmt.Fprintf(w, "Warning: --jobs %d lowered to %d\n", jobs, maxJobs)
+		return maxJobs, nil
+	}
+
+	return jobs, nil
+}
+
+// checkTUISize reports whether --tui-width and --tui-height give the TUI a
+// size, and rejects one without the other or a negative size.
+func checkTUISize() (bool, error) {
+	if tuiWidth < 0 || tuiHeight < 0 {
+		return false, errors.New("--tui-width and --tui-height must not be negative")
+	}
+
+	if (tuiWidth > 0) != (tuiHeight > 0) {
+		return false, errors.New("--tui-width and --tui-height must be set together")
+	}
+
+	return tuiWidth > 0, nil
 }
 
 func runRestore(cmd *cobra.Command, args []string) error {
+	if err := checkPlanReport(); err != nil {
+		return err
+	}
+
+	if err := checkPlanFormat(); err != nil {
+		return err
+	}
+
+	if err := checkSelect(); err != nil {
+		return err
+	}
+
+	if err := checkResume(); err != nil {
+		return err
+	}
+
 	if interactive {
+		if targetOS != "" {
+			return fmt.Errorf("--target-os cannot be combined with --interactive")
+		}
+
 		return runInteractive(cmd, args)
 	}
 
+	if err := config.ValidateSymlinkCompat(symlinkCompat); err != nil {
+		return fmt.Errorf("--symlink-compat: %w", err)
+	}
+
+	jobs, err := resolveJobs(os.Stderr, restoreJobs)
+	if err != nil {
+		return err
+	}
+
 	mgr, err := createManager()
 	if err != nil {
 		return err
 	}
 	defer mgr.Close() //nolint:errcheck // best-effort cleanup
 
+	// --target-os asks for another OS's targets on this machine, so it is no
+	// accidental cross-OS change. Its setup commands are that OS's; none run.
+	if targetOS != "" {
+		mgr.SkipSetup = mgr.Platform.CrossOS()
+	} else if err := checkCrossOS(mgr.Platform, "restore"); err != nil {
+		return err
+	}
+
+	mgr = withPlanLogger(mgr)
+	mgr.SymlinkCompat = symlinkCompat
+	mgr.Jobs = jobs
+	applySelect(os.Stderr, mgr)
+	applyResume(infoOut(os.Stderr), mgr, state.OpRestore)
+
 	if dryRun {
-		fmt.Println("=== DRY RUN MODE ===")
+		fmt.Fprintln(infoOut(os.Stdout), "=== DRY RUN MODE ===")
 	}
 
-	return runRestoreWithManager(mgr)
+	return runRestoreWithManager(mgr, mgr.Config)
 }
 
-func runRestoreWithManager(m manager.Restorer) error {
-	return runWithCancellation(m.RestoreWithContext)
+// runRestoreWithManager restores with m and prints the run report, then,
+// with --notes, the notes cfg has for the applications restored. cfg may be
+// nil.
+func runRestoreWithManager(m manager.Restorer, cfg *config.Config) error {
+	return runWithCancellation(func(ctx context.Context) error {
+		report, err := m.RestoreReport(ctx)
+		if printErr := printRunResult(report); printErr != nil {
+			return errors.Join(err, printErr)
+		}
+
+		if restoreNotes && !dryRun && cfg != nil {
+			printRestoreNotes(infoOut(os.Stdout), cfg, report)
+		}
+
+		if planReport != "" && report != nil {
+			if planErr := writePlan(planReport, runPlan(report)); planErr != nil {
+				return errors.Join(err, planErr)
+			}
+		}
+
+		return err
+	})
 }
 
 func runBackup(cmd *cobra.Command, args []string) error {
+	if err := checkPlanReport(); err != nil {
+		return err
+	}
+
+	if err := checkPlanFormat(); err != nil {
+		return err
+	}
+
+	if err := checkSelect(); err != nil {
+		return err
+	}
+
+	if err := checkResume(); err != nil {
+		return err
+	}
+
 	if interactive {
 		return runInteractive(cmd, args)
 	}
 
+	stale, err := parseStale(backupStale)
+	if err != nil {
+		return err
+	}
+
 	mgr, err := createManager()
 	if err != nil {
 		return err
 	}
 	defer mgr.Close() //nolint:errcheck // best-effort cleanup
 
+	if err := checkCrossOS(mgr.Platform, "back up"); err != nil {
+		return err
+	}
+
+	mgr = withPlanLogger(mgr)
+	mgr.Stale = stale
+	applySelect(os.Stderr, mgr)
+	applyResume(infoOut(os.Stderr), mgr, state.OpBackup)
+
 	if dryRun {
-		fmt.Println("=== DRY RUN MODE ===")
+		fmt.Fprintln(infoOut(os.Stdout), "=== DRY RUN MODE ===")
+	}
+
+	if err := runBackupWithManager(mgr); err != nil || !backupPrune {
+		return err
 	}
 
-	return runBackupWithManager(mgr)
+	return prune(os.Stdout, os.Stdin, mgr, assumeYes)
 }
 
 func runBackupWithManager(m manager.Backuper) error {
-	return runWithCancellation(m.BackupWithContext)
+	return runWithCancellation(func(ctx context.Context) error {
+		report, err := m.BackupReport(ctx)
+		if printErr := printRunResult(report); printErr != nil {
+			return errors.Join(err, printErr)
+		}
+
+		if planReport != "" && report != nil {
+			if planErr := writePlan(planReport, runPlan(report)); planErr != nil {
+				return errors.Join(err, planErr)
+			}
+		}
+
+		return err
+	})
+}
+
+// runReportOrder is the order printRunReport groups entries in: what was
+// done first, failures last so they are what is left on screen.
+var runReportOrder = []manager.EntryAction{
+	manager.ActionRestored,
+	manager.ActionBackedUp,
+	manager.ActionSetUp,
+	manager.ActionSkipped,
+	manager.ActionFailed,
+}
+
+// printRunReport prints the entries of a restore or backup run grouped by
+// outcome, failures last, followed by a line counting each outcome.
+func printRunReport(w io.Writer, report *manager.Report) {
+	if report == nil || len(report.Entries) == 0 {
+		return
+	}
+
+	fmt.Fprintln(w)
+
+	var counts []string
+
+	for _, action := range runReportOrder {
+		n := report.Count(action)
+		if n == 0 {
+			continue
+		}
+
+		counts = append(counts, fmt.Sprintf("%d %s", n, action))
+
+		for _, e := range report.Entries {
+			if e.Action != action {
+				continue
+			}
+
+			switch action {
+			case manager.ActionFailed:
+				fmt.Fprintf(w, "[error] %s: %v\n", e.Name(), e.Err)
+			case manager.ActionSkipped:
+				fmt.Fprintf(w, "[skip] %s: %s\n", e.Name(), e.Detail)
+			case manager.ActionRestored:
+				if e.Warning != "" {
+					fmt.Fprintf(w, "[warn] %s: %s\n", e.Name(), e.Message())
+					break
+				}
+
+				fmt.Fprintf(w, "[ok] %s: %s\n", e.Name(), e.Message())
+			default:
+				fmt.Fprintf(w, "[ok] %s: %s\n", e.Name(), e.Message())
+			}
+
+			for _, s := range e.Skipped {
+				fmt.Fprintf(w, "  [skip] %s: %s\n", s.Path, s.SkipReason)
+			}
+		}
+	}
+
+	fmt.Fprintf(w, "\nSummary: %s\n", strings.Join(counts, ", "))
+}
+
+// printRestoreNotes prints the notes of each application of cfg that had an
+// entry restored in report, as a reminder of what is left to do by hand.
+func printRestoreNotes(w io.Writer, cfg *config.Config, report *manager.Report) {
+	if report == nil {
+		return
+	}
+
+	var restored []string
+
+	for _, e := range report.Entries {
+		if e.Action == manager.ActionRestored && !slices.Contains(restored, e.App) {
+			restored = append(restored, e.App)
+		}
+	}
+
+	for _, name := range restored {
+		i := slices.IndexFunc(cfg.Applications, func(app config.Application) bool { return app.Name == name })
+		if i < 0 {
+			continue
+		}
+
+		app := &cfg.Applications[i]
+
+		notes, err := app.ReadNotes(cfg.BackupRoot)
+		if err != nil {
+			fmt.Fprintf(w, "\n[warn] %v\n", err)
+			continue
+		}
+
+		notes = strings.TrimSpace(notes)
+		if notes == "" {
+			continue
+		}
+
+		fmt.Fprintf(w, "\nNotes for %s:\n", name)
+
+		for line := range strings.Lines(notes) {
+			fmt.Fprintf(w, "  %s", line)
+		}
+
+		fmt.Fprintln(w)
+	}
+}
+
+// parseStale parses the --stale window. Besides time.ParseDuration units it
+// accepts whole days ("30d") and weeks ("2w"). An empty value disables the
+// filter.
+func parseStale(s string) (time.Duration, error) {
+	if s == "" {
+		return 0, nil
+	}
+
+	unit := map[byte]time.Duration{'d': 24 * time.Hour, 'w': 7 * 24 * time.Hour}[s[len(s)-1]]
+	if unit != 0 {
+		n, err := strconv.Atoi(s[:len(s)-1])
+		if err != nil || n <= 0 {
+			return 0, fmt.Errorf("invalid --stale value %q: want a positive number of days or weeks, e.g. 30d or 2w", s)
+		}
+
+		return time.Duration(n) * unit, nil
+	}
+
+	d, err := time.ParseDuration(s)
+	if err != nil || d <= 0 {
+		return 0, fmt.Errorf("invalid --stale value %q: want a positive duration, e.g. 30d, 2w or 12h", s)
+	}
+
+	return d, nil
 }
 
 // runWithCancellation runs a context-aware function with signal-based cancellation.
@@ -358,42 +842,101 @@ func runWithCancellation(fn func(ctx context.Context) error) error {
 
 	go func() {
 		<-sigChan
-		fmt.Println("\nOperation canceled by user")
+		fmt.Fprintln(infoOut(os.Stdout), "\nOperation canceled by user")
 		cancel()
 	}()
 
-	return fn(ctx)
+	err := fn(ctx)
+
+	// ctx is only canceled by a signal until this function returns. Report
+	// it even when fn recorded the cancellation in its results instead.
+	if ctxErr := ctx.Err(); ctxErr != nil && !errors.Is(err, ctxErr) {
+		return errors.Join(err, ctxErr)
+	}
+
+	return err
 }
 
 func runList(_ *cobra.Command, _ []string) error {
-	mgr, err := createManager()
-	if err != nil {
-		return err
+	if listFormat != listFormatText && listFormat != listFormatJSON {
+		return fmt.Errorf("invalid --format %q: must be %q or %q", listFormat, listFormatText, listFormatJSON)
+	}
+
+	if listTree {
+		if listFormat == listFormatJSON {
+			return fmt.Errorf("--tree cannot be combined with --format %s", listFormatJSON)
+		}
+
+		cfg, plat, _, err := loadConfig()
+		if err != nil {
+			return err
+		}
+
+		return tui.WriteTree(os.Stdout, cfg, plat, listAll)
+	}
+
+	var mgr *manager.Manager
+
+	if listFormat == listFormatJSON {
+		// Keep stdout pure JSON: no banner, warnings and logs go to stderr.
+		cfg, plat, _, err := loadConfig()
+		if err != nil {
+			return err
+		}
+
+		mgr = newManager(cfg, plat, os.Stderr).
+			WithLogger(slog.New(slog.NewTextHandler(os.Stderr, nil)))
+	} else {
+		var err error
+		if mgr, err = createManager(); err != nil {
+			return err
+		}
 	}
 	defer mgr.Close() //nolint:errcheck // best-effort cleanup
 
-	return runListWithManager(mgr)
+	mgr.ShowDisabled = listAll
+
+	return runListWithManager(mgr, listFormat, os.Stdout)
 }
 
-func runListWithManager(m manager.Lister) error {
+func runListWithManager(m manager.Lister, format string, w io.Writer) error {
+	if format == listFormatJSON {
+		return m.ListJSON(w)
+	}
+
 	return m.List()
 }
 
 func runInstall(cmd *cobra.Command, args []string) error {
+	if err := checkPlanReport(); err != nil {
+		return err
+	}
+
 	if interactive {
 		return runInteractive(cmd, args)
 	}
 
+	jobs, err := resolveJobs(os.Stderr, installJobs)
+	if err != nil {
+		return err
+	}
+
 	cfg, plat, _, err := loadConfig()
 	if err != nil {
 		return err
 	}
 
-	fmt.Printf("Detected OS: %s\n", plat.OS)
-	fmt.Printf("Config directory: %s\n", cfg.BackupRoot)
+	if !installCheck {
+		if err := checkCrossOS(plat, "install packages"); err != nil {
+			return err
+		}
+	}
+
+	fmt.Fprintf(infoOut(os.Stdout), "Detected OS: %s\n", plat.OS)
+	fmt.Fprintf(infoOut(os.Stdout), "Config directory: %s\n", cfg.BackupRoot)
 
 	// Create template engine for when expression evaluation
-	tmplCtx := tmpl.NewContextFromPlatform(plat)
+	tmplCtx := tmpl.NewContextFromPlatform(plat).WithConfig(cfg)
 	engine := tmpl.NewEngine(tmplCtx)
 
 	// Get filtered package entries
@@ -404,53 +947,68 @@ func runInstall(cmd *cobra.Command, args []string) error {
 
 	// Create package manager
 	pkgMgr := packages.NewManager(&packages.Config{
-		Packages:        packages.FromApplications(packageEntries),
+		Packages:        packages.FilterPackages(packages.FromApplications(packageEntries), engine),
 		DefaultManager:  packages.PackageManager(cfg.DefaultManager),
 		ManagerPriority: convertToPackageManagers(cfg.ManagerPriority),
 	}, plat.OS, dryRun, verbose)
+	pkgMgr.SkipVerify = skipVerify
+	pkgMgr.Jobs = jobs
+	pkgMgr.InstallRetries = installRetries
+	pkgMgr.RetryDelay = installRetryDelay
+	pkgMgr.NoSudo = noSudo
+	pkgMgr.Offline = offline
+	pkgMgr.LogOutput = infoOut(os.Stdout)
+
+	if err := pkgMgr.DetectManagers(cmd.Context()); err != nil {
+		return err
+	}
 
-	fmt.Printf("Available package managers: %v\n", pkgMgr.Available)
+	fmt.Fprintf(infoOut(os.Stdout), "Available package managers: %v\n", pkgMgr.Available)
 	if pkgMgr.Preferred != "" {
-		fmt.Printf("Preferred package manager: %s\n", pkgMgr.Preferred)
+		fmt.Fprintf(infoOut(os.Stdout), "Preferred package manager: %s\n", pkgMgr.Preferred)
+	}
+
+	if installCheck {
+		return runInstallCheck(pkgMgr, filterPackages(pkgMgr.Config.Packages, args))
 	}
 
 	if dryRun {
-		fmt.Println("=== DRY RUN MODE ===")
+		fmt.Fprintln(infoOut(os.Stdout), "=== DRY RUN MODE ===")
 	}
 
-	// Get installable packages
-	packagesToInstall := pkgMgr.GetInstallablePackages()
+	// Get installable packages, filtered by name if args provided
+	packagesToInstall := filterPackages(pkgMgr.GetInstallablePackages(), args)
 
-	// Filter by name if args provided
-	if len(args) > 0 {
-		var filtered []packages.Package
-		for _, pkg := range packagesToInstall {
-			for _, name := range args {
-				if pkg.Name == name {
-					filtered = append(filtered, pkg)
-					break
-				}
-			}
-		}
-		packagesToInstall = filtered
+	var results []packages.InstallResult
+
+	// A canceled install is reported in its result; the error only says the
+	// run was interrupted, once the results are printed.
+	runErr := runWithCancellation(func(ctx context.Context) error {
+		results = pkgMgr.WithContext(ctx).InstallAll(packagesToInstall)
+		return nil
+	})
+
+	successCount, failCount := printInstallResults(infoOut(os.Stdout), results)
+
+	if summary := formatMethodSummary(results); summary != "" {
+		fmt.Fprintf(infoOut(os.Stdout), "\nBy manager: %s\n", summary)
 	}
 
-	results := pkgMgr.InstallAll(packagesToInstall)
+	fmt.Fprintf(infoOut(os.Stdout), "\nInstallation complete: %d successful, %d failed\n", successCount, failCount)
 
-	// Print results
-	successCount := 0
-	failCount := 0
-	for _, r := range results {
-		if r.Success {
-			fmt.Printf("[ok] %s: %s\n", r.Package, r.Message)
-			successCount++
-		} else {
-			fmt.Printf("[error] %s: %s\n", r.Package, r.Message)
-			failCount++
+	if quiet {
+		printInstallFailures(os.Stderr, results)
+	}
+
+	if planReport != "" {
+		if err := writePlan(planReport, installPlan(results)); err != nil {
+			return errors.Join(runErr, err)
 		}
 	}
 
-	fmt.Printf("\nInstallation complete: %d successful, %d failed\n", successCount, failCount)
+	if runErr != nil {
+		return runErr
+	}
 
 	if failCount > 0 {
 		return fmt.Errorf("%d packages failed to install", failCount)
@@ -458,49 +1016,164 @@ func runInstall(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
-func runListPackages(_ *cobra.Command, _ []string) error {
-	cfg, plat, _, err := loadConfig()
-	if err != nil {
+// filterPackages returns the packages in pkgs named in names, or all of
+// them when names is empty.
+func filterPackages(pkgs []packages.Package, names []string) []packages.Package {
+	if len(names) == 0 {
+		return pkgs
+	}
+
+	var filtered []packages.Package
+	for _, pkg := range pkgs {
+		if slices.Contains(names, pkg.Name) {
+			filtered = append(filtered, pkg)
+		}
+	}
+
+	return filtered
+}
+
+// runInstallCheck prints whether each of pkgs is installed and fails when
+// any of them is missing. No install command is run.
+func runInstallCheck(pkgMgr *packages.Manager, pkgs []packages.Package) error {
+	var results []packages.CheckResult
+
+	if err := runWithCancellation(func(ctx context.Context) error {
+		results = pkgMgr.WithContext(ctx).CheckAll(pkgs)
+		return ctx.Err()
+	}); err != nil {
 		return err
 	}
 
-	// Create template engine for when expression evaluation
-	tmplCtx := tmpl.NewContextFromPlatform(plat)
-	engine := tmpl.NewEngine(tmplCtx)
+	installed, missing, unavailable := printCheckResults(os.Stdout, results)
 
-	// Get filtered package entries
-	packageEntries := cfg.GetFilteredPackages(engine)
-	if len(packageEntries) == 0 {
-		fmt.Println("No matching packages configured in tidydots.yaml")
-		return nil
+	fmt.Printf("\nCheck complete: %d installed, %d missing, %d unavailable\n", installed, missing, unavailable)
+
+	if missing > 0 {
+		return fmt.Errorf("%d packages missing", missing)
 	}
+	return nil
+}
 
-	// Create package manager to determine install methods
-	pkgMgr := packages.NewManager(&packages.Config{
-		Packages:        packages.FromApplications(packageEntries),
-		DefaultManager:  packages.PackageManager(cfg.DefaultManager),
-		ManagerPriority: convertToPackageManagers(cfg.ManagerPriority),
-	}, plat.OS, false, verbose)
+// printCheckResults prints one line per check result and returns how many
+// packages are installed, missing and unavailable.
+func printCheckResults(w io.Writer, results []packages.CheckResult) (installed, missing, unavailable int) {
+	for _, r := range results {
+		switch r.State {
+		case packages.CheckInstalled:
+			installed++
+			fmt.Fprintf(w, "[ok] %s: installed\n", r.Package)
+		case packages.CheckMissing:
+			missing++
+			fmt.Fprintf(w, "[missing] %s: missing (would use %s)\n", r.Package, r.Method)
+		default:
+			unavailable++
+			fmt.Fprintf(w, "[unavailable] %s: unavailable\n", r.Package)
+		}
+	}
 
-	fmt.Printf("Available package managers: %v\n\n", pkgMgr.Available)
+	return installed, missing, unavailable
+}
 
-	for _, pkg := range pkgMgr.Config.Packages {
-		method := pkgMgr.GetInstallMethod(pkg)
-		canInstall := pkgMgr.CanInstall(pkg)
+// printInstallResults prints one line per install result and returns the
+// success and failure counts. When the results span several phases, each
+// phase gets a header with its own counts.
+func printInstallResults(w io.Writer, results []packages.InstallResult) (successCount, failCount int) {
+	multiPhase := len(results) > 0 && results[0].Phase != results[len(results)-1].Phase
 
-		status := "✓"
-		if !canInstall {
-			status = "✗"
-			method = "unavailable"
+	for i := 0; i < len(results); {
+		end := i
+		for end < len(results) && results[end].Phase == results[i].Phase {
+			end++
 		}
 
-		fmt.Printf("%s %s (%s)\n", status, pkg.Name, method)
-		if pkg.Description != "" {
-			fmt.Printf("    %s\n", pkg.Description)
+		phaseOK, phaseFail := 0, 0
+		for _, r := range results[i:end] {
+			if r.Success {
+				phaseOK++
+			} else {
+				phaseFail++
+			}
 		}
+
+		if multiPhase {
+			if i > 0 {
+				fmt.Fprintln(w)
+			}
+
+			fmt.Fprintf(w, "Phase %d: %d successful, %d failed\n", results[i].Phase, phaseOK, phaseFail)
+		}
+
+		for _, r := range results[i:end] {
+			msg := r.Message
+			if r.Attempts > 1 {
+				msg = fmt.Sprintf("%s (%d attempts)", msg, r.Attempts)
+			}
+
+			if r.Skipped {
+				fmt.Fprintf(w, "[skip] %s: %s\n", r.Package, msg)
+			} else if r.Unverified {
+				fmt.Fprintf(w, "[unverified] %s: %s\n", r.Package, msg)
+			} else if r.Success {
+				fmt.Fprintf(w, "[ok] %s: %s\n", r.Package, msg)
+			} else {
+				fmt.Fprintf(w, "[error] %s: %s\n", r.Package, msg)
+			}
+		}
+
+		successCount += phaseOK
+		failCount += phaseFail
+		i = end
 	}
 
-	return nil
+	return successCount, failCount
+}
+
+// formatMethodSummary rolls install results up by the method that handled
+// them, e.g. "pacman: 12 ok / 1 fail, custom: 3 ok". Methods are listed in the
+// order they first appear in results.
+func formatMethodSummary(results []packages.InstallResult) string {
+	type counts struct{ ok, fail int }
+
+	var order []string
+	byMethod := make(map[string]*counts)
+
+	for _, r := range results {
+		method := r.Method
+		if method == "" {
+			method = packages.MethodNone
+		}
+
+		c, ok := byMethod[method]
+		if !ok {
+			c = &counts{}
+			byMethod[method] = c
+			order = append(order, method)
+		}
+
+		if r.Success {
+			c.ok++
+		} else {
+			c.fail++
+		}
+	}
+
+	parts := make([]string, 0, len(order))
+	for _, method := range order {
+		c := byMethod[method]
+
+		var stats []string
+		if c.ok > 0 {
+			stats = append(stats, fmt.Sprintf("%d ok", c.ok))
+		}
+		if c.fail > 0 {
+			stats = append(stats, fmt.Sprintf("%d fail", c.fail))
+		}
+
+		parts = append(parts, fmt.Sprintf("%s: %s", method, strings.Join(stats, " / ")))
+	}
+
+	return strings.Join(parts, ", ")
 }
 
 func convertToPackageManagers(strs []string) []packages.PackageManager {
@@ -512,12 +1185,12 @@ func convertToPackageManagers(strs []string) []packages.PackageManager {
 }
 
 func runPreview(_ *cobra.Command, args []string) error {
-	_, plat, _, err := loadConfig()
+	cfg, plat, _, err := loadConfig()
 	if err != nil {
 		return err
 	}
 
-	tmplCtx := tmpl.NewContextFromPlatform(plat)
+	tmplCtx := tmpl.NewContextFromPlatform(plat).WithConfig(cfg)
 	engine := tmpl.NewEngine(tmplCtx)
 
 	logger := slog.Default()
@@ -1,10 +1,14 @@
 package main
 
 import (
+	"bytes"
+	"encoding/json"
 	"errors"
+	"io"
 	"os"
 	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/AntoineGS/tidydots/internal/config"
 	"github.com/AntoineGS/tidydots/internal/packages"
@@ -236,3 +240,306 @@ func searchSubstring(s, substr string) bool {
 	}
 	return false
 }
+
+func TestFormatMethodSummary(t *testing.T) {
+	tests := []struct {
+		name    string
+		want    string
+		results []packages.InstallResult
+	}{
+		{
+			name: "empty",
+			want: "",
+		},
+		{
+			name: "groups in first-seen order",
+			results: []packages.InstallResult{
+				{Package: "a", Method: "pacman", Success: true},
+				{Package: "b", Method: "custom", Success: true},
+				{Package: "c", Method: "pacman", Success: false},
+				{Package: "d", Method: "pacman", Success: true},
+				{Package: "e", Method: "git", Success: true},
+			},
+			want: "pacman: 2 ok / 1 fail, custom: 1 ok, git: 1 ok",
+		},
+		{
+			name: "failures only and missing method",
+			results: []packages.InstallResult{
+				{Package: "a", Method: "", Success: false},
+				{Package: "b", Method: "url", Success: false},
+			},
+			want: "none: 1 fail, url: 1 fail",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := formatMethodSummary(tt.results); got != tt.want {
+				t.Errorf("formatMethodSummary() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseStale(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    time.Duration
+		wantErr bool
+	}{
+		{in: "", want: 0},
+		{in: "30d", want: 30 * 24 * time.Hour},
+		{in: "2w", want: 14 * 24 * time.Hour},
+		{in: "12h", want: 12 * time.Hour},
+		{in: "1h30m", want: 90 * time.Minute},
+		{in: "0d", wantErr: true},
+		{in: "-1h", wantErr: true},
+		{in: "d", wantErr: true},
+		{in: "1.5d", wantErr: true},
+		{in: "soon", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			got, err := parseStale(tt.in)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseStale(%q) error = %v, wantErr %v", tt.in, err, tt.wantErr)
+			}
+
+			if got != tt.want {
+				t.Errorf("parseStale(%q) = %v, want %v", tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPrintInstallResults(t *testing.T) {
+	tests := []struct {
+		name     string
+		want     string
+		results  []packages.InstallResult
+		wantOK   int
+		wantFail int
+	}{
+		{
+			name: "single phase has no header",
+			results: []packages.InstallResult{
+				{Package: "a", Message: "Installed via pacman", Success: true},
+				{Package: "b", Message: "No installation method available", Success: false},
+			},
+			want:     "[ok] a: Installed via pacman\n[error] b: No installation method available\n",
+			wantOK:   1,
+			wantFail: 1,
+		},
+		{
+			name: "phases are reported separately",
+			results: []packages.InstallResult{
+				{Package: "runtime", Message: "ok", Success: true},
+				{Package: "tool", Message: "failed", Phase: 1},
+				{Package: "gui", Message: "ok", Phase: 1, Success: true},
+			},
+			want: "Phase 0: 1 successful, 0 failed\n[ok] runtime: ok\n\n" +
+				"Phase 1: 1 successful, 1 failed\n[error] tool: failed\n[ok] gui: ok\n",
+			wantOK:   2,
+			wantFail: 1,
+		},
+		{
+			name: "skipped packages are tagged and not failures",
+			results: []packages.InstallResult{
+				{Package: "repo", Message: packages.MsgRequiresSudo, Success: true, Skipped: true},
+			},
+			want:   "[skip] repo: Skipped: requires sudo\n",
+			wantOK: 1,
+		},
+		{
+			name: "unverified installs are tagged and failures",
+			results: []packages.InstallResult{
+				{Package: "tool", Message: packages.MsgVerifyFailed + ": exit status 1", Unverified: true},
+			},
+			want:     "[unverified] tool: Installed but verification failed: exit status 1\n",
+			wantFail: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+
+			ok, fail := printInstallResults(&buf, tt.results)
+			if got := buf.String(); got != tt.want {
+				t.Errorf("printInstallResults() output = %q, want %q", got, tt.want)
+			}
+
+			if ok != tt.wantOK || fail != tt.wantFail {
+				t.Errorf("printInstallResults() = (%d, %d), want (%d, %d)", ok, fail, tt.wantOK, tt.wantFail)
+			}
+		})
+	}
+}
+
+func TestWritePackageList(t *testing.T) {
+	listed := []listedPackage{
+		{Name: "neovim", Manager: "pacman", State: packages.CheckInstalled, Version: "0.10.2-1", Description: "Editor"},
+		{Name: "kitty", Manager: "pacman", State: packages.CheckMissing, Filtered: true},
+		{Name: "powertoys", State: packages.CheckUnavailable, Reason: "no manager for linux"},
+	}
+
+	var buf bytes.Buffer
+	if err := writePackageList(&buf, listFormatText, listed); err != nil {
+		t.Fatal(err)
+	}
+
+	want := "NAME              MANAGER                             INSTALLED  VERSION   DESCRIPTION\n" +
+		"neovim            pacman                              yes        0.10.2-1  Editor\n" +
+		"kitty (filtered)  pacman                              no         -         \n" +
+		"powertoys         unavailable (no manager for linux)  -          -         \n"
+	if got := buf.String(); got != want {
+		t.Errorf("writePackageList(text) =\n%s\nwant\n%s", got, want)
+	}
+
+	buf.Reset()
+	if err := writePackageList(&buf, listFormatJSON, listed); err != nil {
+		t.Fatal(err)
+	}
+
+	var got []listedPackage
+	if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+		t.Fatalf("writePackageList(json) is not JSON: %v\n%s", err, buf.String())
+	}
+
+	if len(got) != 3 || got[0] != listed[0] || got[2] != listed[2] {
+		t.Errorf("writePackageList(json) = %+v", got)
+	}
+}
+
+func TestWriteUpdates(t *testing.T) {
+	results := []packages.UpdateResult{
+		{
+			VersionResult: packages.VersionResult{
+				CheckResult: packages.CheckResult{Package: "neovim", State: packages.CheckInstalled},
+				Version:     "0.10.2-1",
+				Manager:     packages.Pacman,
+			},
+			Available: "0.10.3-1",
+			Status:    packages.UpdateOutdated,
+		},
+		{
+			VersionResult: packages.VersionResult{CheckResult: packages.CheckResult{Package: "starship", State: packages.CheckInstalled}},
+			Status:        packages.UpdateUnknown,
+		},
+	}
+
+	var buf bytes.Buffer
+	if err := writeUpdates(&buf, listFormatText, results); err != nil {
+		t.Fatal(err)
+	}
+
+	want := "NAME      INSTALLED  AVAILABLE  STATUS\n" +
+		"neovim    0.10.2-1   0.10.3-1   outdated\n" +
+		"starship  -          -          unknown\n"
+	if got := buf.String(); got != want {
+		t.Errorf("writeUpdates(text) =\n%s\nwant\n%s", got, want)
+	}
+
+	if got := countOutdated(results); got != 1 {
+		t.Errorf("countOutdated() = %d, want 1", got)
+	}
+
+	buf.Reset()
+	if err := writeUpdates(&buf, listFormatJSON, results); err != nil {
+		t.Fatal(err)
+	}
+
+	var got []checkedUpdate
+	if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+		t.Fatalf("writeUpdates(json) is not JSON: %v\n%s", err, buf.String())
+	}
+
+	wantJSON := checkedUpdate{Name: "neovim", Manager: "pacman", Installed: "0.10.2-1", Available: "0.10.3-1", Status: packages.UpdateOutdated}
+	if len(got) != 2 || got[0] != wantJSON {
+		t.Errorf("writeUpdates(json) = %+v", got)
+	}
+}
+
+func TestPrintCheckResults(t *testing.T) {
+	var buf bytes.Buffer
+
+	installed, missing, unavailable := printCheckResults(&buf, []packages.CheckResult{
+		{Package: "neovim", State: packages.CheckInstalled, Method: "pacman"},
+		{Package: "ripgrep", State: packages.CheckMissing, Method: "pacman"},
+		{Package: "winonly", State: packages.CheckUnavailable, Method: packages.MethodNone},
+	})
+
+	want := "[ok] neovim: installed\n" +
+		"[missing] ripgrep: missing (would use pacman)\n" +
+		"[unavailable] winonly: unavailable\n"
+	if got := buf.String(); got != want {
+		t.Errorf("printCheckResults() output = %q, want %q", got, want)
+	}
+
+	if installed != 1 || missing != 1 || unavailable != 1 {
+		t.Errorf("printCheckResults() = (%d, %d, %d), want (1, 1, 1)", installed, missing, unavailable)
+	}
+}
+
+func TestCheckTUISize(t *testing.T) {
+	origWidth, origHeight := tuiWidth, tuiHeight
+	t.Cleanup(func() { tuiWidth, tuiHeight = origWidth, origHeight })
+
+	tests := []struct {
+		width, height int
+		wantSized     bool
+		wantErr       bool
+	}{
+		{width: 0, height: 0},
+		{width: 120, height: 40, wantSized: true},
+		{width: 120, height: 0, wantErr: true},
+		{width: 0, height: 40, wantErr: true},
+		{width: -1, height: 40, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		tuiWidth, tuiHeight = tt.width, tt.height
+
+		sized, err := checkTUISize()
+		if (err != nil) != tt.wantErr {
+			t.Errorf("checkTUISize() with %dx%d error = %v, wantErr %v", tt.width, tt.height, err, tt.wantErr)
+		}
+
+		if sized != tt.wantSized {
+			t.Errorf("checkTUISize() with %dx%d = %v, want %v", tt.width, tt.height, sized, tt.wantSized)
+		}
+	}
+}
+
+func TestResolveJobs(t *testing.T) {
+	origParallel := parallel
+	t.Cleanup(func() { parallel = origParallel })
+
+	tests := []struct {
+		jobs     int
+		parallel bool
+		want     int
+		wantErr  bool
+	}{
+		{jobs: 0, want: 1},
+		{jobs: 3, want: 3},
+		{jobs: 0, parallel: true, want: parallelJobs},
+		{jobs: 2, parallel: true, want: 2},
+		{jobs: 100, want: maxJobs},
+		{jobs: -1, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		parallel = tt.parallel
+
+		got, err := resolveJobs(io.Discard, tt.jobs)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("resolveJobs(%d) with parallel %v error = %v, wantErr %v", tt.jobs, tt.parallel, err, tt.wantErr)
+		}
+
+		if err == nil && got != tt.want {
+			t.Errorf("resolveJobs(%d) with parallel %v = %d, want %d", tt.jobs, tt.parallel, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,152 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"text/tabwriter"
+
+	"github.com/AntoineGS/tidydots/internal/packages"
+	tmpl "github.com/AntoineGS/tidydots/internal/template"
+	"github.com/spf13/cobra"
+)
+
+var checkUpdatesFormat string
+
+func newPackagesCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "packages",
+		Short: "Inspect the packages installed on this machine",
+	}
+
+	checkUpdatesCmd := &cobra.Command{
+		Use:   "check-updates [package-names...]",
+		Short: "Show installed packages their package manager has a newer version of",
+		Long: `Compare the installed version of each package that matches this machine
+with the version its package manager offers, and list them with their status.
+Only packages installed through a package manager that reports versions
+(pacman, yay, paru, apt, dnf, brew, winget and choco) are compared; nothing
+is upgraded. The command exits non-zero when any package is outdated.
+
+Versions come from the managers' local package databases, so refresh them
+first (e.g. 'pacman -Sy', 'apt update' or 'brew update') for current results.`,
+		RunE: runCheckUpdates,
+	}
+	checkUpdatesCmd.Flags().StringVar(&checkUpdatesFormat, "format", listFormatText, "Output format: text or json")
+
+	cmd.AddCommand(checkUpdatesCmd)
+
+	return cmd
+}
+
+func runCheckUpdates(cmd *cobra.Command, args []string) error {
+	if checkUpdatesFormat != listFormatText && checkUpdatesFormat != listFormatJSON {
+		return fmt.Errorf("invalid --format %q: must be %q or %q", checkUpdatesFormat, listFormatText, listFormatJSON)
+	}
+
+	cfg, plat, _, err := loadConfig()
+	if err != nil {
+		return err
+	}
+
+	engine := tmpl.NewEngine(tmpl.NewContextFromPlatform(plat).WithConfig(cfg))
+
+	pkgMgr := packages.NewManager(&packages.Config{
+		Packages:        packages.FilterPackages(packages.FromApplications(cfg.GetFilteredPackages(engine)), engine),
+		DefaultManager:  packages.PackageManager(cfg.DefaultManager),
+		ManagerPriority: convertToPackageManagers(cfg.ManagerPriority),
+	}, plat.OS, false, verbose)
+
+	if err := pkgMgr.DetectManagers(cmd.Context()); err != nil {
+		return err
+	}
+
+	var results []packages.UpdateResult
+
+	if err := runWithCancellation(func(ctx context.Context) error {
+		results = pkgMgr.WithContext(ctx).CheckUpdates(filterPackages(pkgMgr.Config.Packages, args))
+		return ctx.Err()
+	}); err != nil {
+		return err
+	}
+
+	if err := writeUpdates(os.Stdout, checkUpdatesFormat, results); err != nil {
+		return err
+	}
+
+	if outdated := countOutdated(results); outdated > 0 {
+		return fmt.Errorf("%d package(s) outdated", outdated)
+	}
+
+	return nil
+}
+
+// checkedUpdate is one package of check-updates, as printed by writeUpdates.
+type checkedUpdate struct {
+	Name      string `json:"name"`
+	Manager   string `json:"manager,omitempty"` // empty when the installed version is unknown
+	Installed string `json:"installed,omitempty"`
+	Available string `json:"available,omitempty"`
+	Status    string `json:"status"` // packages.UpdateCurrent, UpdateOutdated or UpdateUnknown
+}
+
+// writeUpdates writes the results of check-updates to w, as a table or as a
+// JSON array.
+func writeUpdates(w io.Writer, format string, results []packages.UpdateResult) error {
+	checked := make([]checkedUpdate, len(results))
+	for i, r := range results {
+		checked[i] = checkedUpdate{
+			Name:      r.Package,
+			Manager:   string(r.Manager),
+			Installed: r.Version,
+			Available: r.Available,
+			Status:    r.Status,
+		}
+	}
+
+	if format == listFormatJSON {
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+
+		return enc.Encode(checked)
+	}
+
+	if len(checked) == 0 {
+		fmt.Fprintln(infoOut(w), "No installed packages to check")
+		return nil
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "NAME\tINSTALLED\tAVAILABLE\tSTATUS")
+
+	for _, c := range checked {
+		installed, available := c.Installed, c.Available
+
+		if installed == "" {
+			installed = "-"
+		}
+
+		if available == "" {
+			available = "-"
+		}
+
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n", c.Name, installed, available, c.Status)
+	}
+
+	return tw.Flush()
+}
+
+// countOutdated returns how many of results are packages.UpdateOutdated.
+func countOutdated(results []packages.UpdateResult) int {
+	outdated := 0
+
+	for _, r := range results {
+		if r.Status == packages.UpdateOutdated {
+			outdated++
+		}
+	}
+
+	return outdated
+}
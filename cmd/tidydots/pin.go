@@ -0,0 +1,73 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"github.com/AntoineGS/tidydots/internal/lockfile"
+	"github.com/spf13/cobra"
+)
+
+var pinUpdate bool
+
+func newPinCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "pin",
+		Short: "Record the checksum of every backed-up file in tidydots.lock",
+		Long: `Hash every backed-up file of every application in the configuration and
+write the checksums to tidydots.lock in the dotfiles repository. Commit the
+lockfile alongside your dotfiles; 'tidydots verify --strict' reports every
+file that was modified, removed or added since it was pinned.
+
+An existing lockfile is only replaced with --update.`,
+		Args: cobra.NoArgs,
+		RunE: runPin,
+	}
+
+	cmd.Flags().BoolVar(&pinUpdate, "update", false, "Regenerate an existing tidydots.lock")
+
+	return cmd
+}
+
+func runPin(_ *cobra.Command, _ []string) error {
+	cfgDir, err := getConfigDir()
+	if err != nil {
+		return err
+	}
+
+	return pin(os.Stdout, cfgDir, pinUpdate)
+}
+
+// pin writes the lockfile of the repository in backupRoot, refusing to
+// replace an existing one unless update is set.
+func pin(w io.Writer, backupRoot string, update bool) error {
+	path := filepath.Join(backupRoot, lockfile.FileName)
+
+	if _, err := os.Stat(path); err == nil && !update {
+		return fmt.Errorf("%s already exists; pass --update to regenerate it", path)
+	} else if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+
+	lock, err := lockfile.Pin(backupRoot)
+	if err != nil {
+		return err
+	}
+
+	if dryRun {
+		fmt.Fprintf(w, "Would pin %d file(s) in %s\n", len(lock.Files), path)
+		return nil
+	}
+
+	if err := lock.Save(path); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(w, "Pinned %d file(s) in %s\n", len(lock.Files), path)
+
+	return nil
+}
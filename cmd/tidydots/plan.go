@@ -0,0 +1,404 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/AntoineGS/tidydots/internal/manager"
+	"github.com/AntoineGS/tidydots/internal/packages"
+)
+
+// planReport is the file --report writes the plan of a dry run to.
+var planReport string
+
+// planFormat is the format restore and backup print the plan of a dry run
+// in: text, or json for tooling.
+var planFormat string
+
+// Results of the entries and packages of a plan.
+const (
+	planOK      = "ok"
+	planSkipped = "skipped"
+	planFailed  = "failed"
+)
+
+// plan is what a dry run would do, as written by --report. It is built from
+// the results of the dry run itself, so it cannot disagree with the run.
+type plan struct {
+	Operation string        `json:"operation"`
+	Entries   []planEntry   `json:"entries,omitempty"`
+	Packages  []planPackage `json:"packages,omitempty"`
+}
+
+// planEntry is one restore or backup entry of a plan.
+type planEntry struct {
+	App    string         `json:"app"`
+	Entry  string         `json:"entry"`
+	Result string         `json:"result"`
+	Detail string         `json:"detail,omitempty"` // why the entry is skipped, or its error
+	Steps  []manager.Step `json:"steps,omitempty"`
+	// Skipped are the files a backup would leave out, e.g. for max_file_size.
+	Skipped []manager.SkippedFile `json:"skipped_files,omitempty"`
+}
+
+// planPackage is one package of an install plan. Plan holds the commands
+// that would run, or why the package is skipped or fails.
+type planPackage struct {
+	Package string `json:"package"`
+	Method  string `json:"method,omitempty"`
+	Phase   int    `json:"phase"`
+	Result  string `json:"result"`
+	Plan    string `json:"plan"`
+}
+
+// checkPlanReport rejects --report outside a dry run: the report is the plan
+// of changes not made yet.
+func checkPlanReport() error {
+	if planReport != "" && !dryRun {
+		return errors.New("--report needs --dry-run")
+	}
+
+	return nil
+}
+
+// checkPlanFormat validates --format, which only applies to dry runs. JSON
+// leaves stdout to the plan, as --quiet does, so it turns --quiet on.
+func checkPlanFormat() error {
+	switch {
+	case planFormat == "" || planFormat == listFormatText:
+		return nil
+	case planFormat != listFormatJSON:
+		return fmt.Errorf("invalid --format %q: must be %q or %q", planFormat, listFormatText, listFormatJSON)
+	case !dryRun:
+		return fmt.Errorf("--format %s needs --dry-run", listFormatJSON)
+	case verbose:
+		return fmt.Errorf("--format %s cannot be combined with --verbose", listFormatJSON)
+	case interactive:
+		return fmt.Errorf("--format %s cannot be combined with --interactive", listFormatJSON)
+	}
+
+	quiet = true
+
+	return nil
+}
+
+// withPlanLogger returns mgr logging only warnings and errors during a dry
+// run, so that the plan printed at the end is not lost among a log line per
+// decision. --verbose and --quiet keep their own loggers.
+func withPlanLogger(mgr *manager.Manager) *manager.Manager {
+	if !dryRun || verbose || quiet {
+		return mgr
+	}
+
+	return mgr.WithLogger(slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelWarn})))
+}
+
+// printRunResult prints the outcome of a restore or backup run: the plan of
+// a dry run, in planFormat, or the run report.
+func printRunResult(report *manager.Report) error {
+	switch {
+	case !dryRun:
+		printRunReport(infoOut(os.Stdout), report)
+	case report == nil:
+	case planFormat == listFormatJSON:
+		return writePlanJSON(os.Stdout, runPlan(report))
+	default:
+		printPlan(infoOut(os.Stdout), runPlan(report))
+	}
+
+	return nil
+}
+
+// runPlan returns the plan of a restore or backup dry run.
+func runPlan(report *manager.Report) plan {
+	p := plan{Operation: report.Operation}
+
+	for _, e := range report.Entries {
+		entry := planEntry{App: e.App, Entry: e.Entry, Result: planOK, Steps: e.Steps, Skipped: e.Skipped}
+
+		switch e.Action {
+		case manager.ActionSkipped:
+			entry.Result, entry.Detail = planSkipped, e.Detail
+		case manager.ActionFailed:
+			entry.Result, entry.Detail = planFailed, e.Err.Error()
+		}
+
+		p.Entries = append(p.Entries, entry)
+	}
+
+	return p
+}
+
+// installPlan returns the plan of an install dry run.
+func installPlan(results []packages.InstallResult) plan {
+	p := plan{Operation: "install"}
+
+	for _, r := range results {
+		pkg := planPackage{Package: r.Package, Method: r.Method, Phase: r.Phase, Result: planOK, Plan: r.Message}
+
+		switch {
+		case r.Skipped:
+			pkg.Result = planSkipped
+		case !r.Success:
+			pkg.Result = planFailed
+		}
+
+		p.Packages = append(p.Packages, pkg)
+	}
+
+	return p
+}
+
+// writePlan writes p to path: as JSON when path ends in .json, as text
+// tables otherwise.
+func writePlan(path string, p plan) error {
+	var b strings.Builder
+
+	if strings.EqualFold(filepath.Ext(path), ".json") {
+		if err := writePlanJSON(&b, p); err != nil {
+			return err
+		}
+	} else {
+		writePlanText(&b, p)
+	}
+
+	if err := os.WriteFile(path, []byte(b.String()), 0o600); err != nil {
+		return fmt.Errorf("writing %s: %w", path, err)
+	}
+
+	fmt.Fprintf(infoOut(os.Stderr), "Wrote plan to %s\n", path)
+
+	return nil
+}
+
+// writePlanJSON writes p to w as indented JSON.
+func writePlanJSON(w io.Writer, p plan) error {
+	data, err := json.MarshalIndent(p, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	_, err = fmt.Fprintf(w, "%s\n", data)
+
+	return err
+}
+
+// printPlan prints the plan of a restore or backup dry run grouped by
+// application, a line per step, followed by a line counting each operation.
+// Unchanged steps are only counted.
+func printPlan(w io.Writer, p plan) {
+	fmt.Fprintf(w, "\nPlan (dry run):\n")
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	app := ""
+
+	for _, e := range p.Entries {
+		if e.App != app {
+			app = e.App
+			fmt.Fprintf(tw, "\n%s\n", app)
+		}
+
+		printPlanEntry(tw, e)
+	}
+
+	_ = tw.Flush()
+
+	fmt.Fprintf(w, "\n%s\n", planSummary(p))
+}
+
+// printPlanEntry prints the steps of one entry of a plan, or why it has none.
+func printPlanEntry(w io.Writer, e planEntry) {
+	changed := 0
+
+	for _, s := range e.Steps {
+		if s.Conflict == manager.ConflictUnchanged {
+			continue
+		}
+
+		if s.Skipped != "" {
+			fmt.Fprintf(w, "  skip\t%s\t%s\t(%s)\n", e.Entry, s.Source, s.Skipped)
+			continue
+		}
+
+		changed++
+
+		action := s.Source
+		if s.Target != "" {
+			action += " -> " + s.Target
+		}
+
+		note := s.Conflict
+		if s.Bytes > 0 {
+			note += ", " + formatBytes(s.Bytes)
+		}
+
+		fmt.Fprintf(w, "  %s\t%s\t%s\t(%s)\n", s.Op, e.Entry, action, note)
+	}
+
+	for _, s := range e.Skipped {
+		fmt.Fprintf(w, "  skip\t%s\t%s\t(%s)\n", e.Entry, s.Path, s.SkipReason)
+	}
+
+	switch {
+	case e.Result == planSkipped:
+		fmt.Fprintf(w, "  skip\t%s\t%s\n", e.Entry, e.Detail)
+	case e.Result == planFailed:
+		fmt.Fprintf(w, "  fail\t%s\t%s\n", e.Entry, e.Detail)
+	case changed == 0:
+		fmt.Fprintf(w, "  -\t%s\tnothing to do\n", e.Entry)
+	}
+}
+
+// planOps is the order planSummary counts operations in.
+var planOps = []string{manager.StepLink, manager.StepCopy, manager.StepRender, manager.StepBackup, manager.StepRun}
+
+// planSummary counts the steps of p by operation, with the bytes copy and
+// backup steps write, then the unchanged steps and the skipped and failed
+// entries, e.g. "Plan: 3 link, 1 copy (2.0 KiB), 4 unchanged".
+func planSummary(p plan) string {
+	counts := make(map[string]int)
+	bytes := make(map[string]int64)
+	unchanged, skipped, failed := 0, 0, 0
+
+	for _, e := range p.Entries {
+		switch e.Result {
+		case planSkipped:
+			skipped++
+		case planFailed:
+			failed++
+		}
+
+		for _, s := range e.Steps {
+			if s.Conflict == manager.ConflictUnchanged {
+				unchanged++
+				continue
+			}
+
+			if s.Skipped != "" {
+				continue
+			}
+
+			counts[s.Op]++
+			bytes[s.Op] += s.Bytes
+		}
+	}
+
+	var parts []string
+
+	for _, op := range planOps {
+		if counts[op] == 0 {
+			continue
+		}
+
+		part := fmt.Sprintf("%d %s", counts[op], op)
+		if bytes[op] > 0 {
+			part += fmt.Sprintf(" (%s)", formatBytes(bytes[op]))
+		}
+
+		parts = append(parts, part)
+	}
+
+	for _, n := range []struct {
+		count int
+		label string
+	}{{unchanged, "unchanged"}, {skipped, "skipped"}, {failed, "failed"}} {
+		if n.count > 0 {
+			parts = append(parts, fmt.Sprintf("%d %s", n.count, n.label))
+		}
+	}
+
+	if len(parts) == 0 {
+		return "Plan: nothing to do"
+	}
+
+	return "Plan: " + strings.Join(parts, ", ")
+}
+
+// formatBytes formats n bytes with a binary unit, e.g. 512 B or 1.5 KiB.
+func formatBytes(n int64) string {
+	const unit = 1024
+
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+
+	div, exp := int64(unit), 0
+	for m := n / unit; m >= unit; m /= unit {
+		div *= unit
+		exp++
+	}
+
+	return fmt.Sprintf("%.1f %ciB", float64(n)/float64(div), "KMGTPE"[exp])
+}
+
+// writePlanText writes p as a table with a line per step or package.
+func writePlanText(w io.Writer, p plan) {
+	fmt.Fprintf(w, "tidydots %s plan (dry run)\n\n", p.Operation)
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+
+	if p.Operation == "install" {
+		fmt.Fprintln(tw, "PACKAGE\tMETHOD\tPHASE\tRESULT\tPLAN")
+
+		for _, pkg := range p.Packages {
+			method := pkg.Method
+			if method == "" {
+				method = "-"
+			}
+
+			fmt.Fprintf(tw, "%s\t%s\t%d\t%s\t%s\n", pkg.Package, method, pkg.Phase, pkg.Result, pkg.Plan)
+		}
+
+		_ = tw.Flush()
+
+		return
+	}
+
+	fmt.Fprintln(tw, "ENTRY\tRESULT\tOP\tSOURCE\tTARGET\tCONFLICT")
+
+	for _, e := range p.Entries {
+		name := e.App + "/" + e.Entry
+
+		if len(e.Steps) == 0 {
+			detail := e.Detail
+			if e.Result == planOK {
+				detail = "nothing to do"
+			}
+
+			fmt.Fprintf(tw, "%s\t%s\t-\t-\t-\t%s\n", name, e.Result, detail)
+
+			continue
+		}
+
+		for _, s := range e.Steps {
+			target := s.Target
+			if target == "" {
+				target = "-"
+			}
+
+			if s.Skipped != "" {
+				fmt.Fprintf(tw, "%s\t%s\tskip\t%s\t-\t%s\n", name, e.Result, s.Source, s.Skipped)
+				continue
+			}
+
+			fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%s\n", name, e.Result, s.Op, s.Source, target, s.Conflict)
+		}
+
+		for _, s := range e.Skipped {
+			fmt.Fprintf(tw, "%s\t%s\tskip\t%s\t-\t%s\n", name, e.Result, s.Path, s.SkipReason)
+		}
+
+		if e.Result == planFailed {
+			fmt.Fprintf(tw, "%s\t%s\t-\t-\t-\t%s\n", name, e.Result, e.Detail)
+		}
+	}
+
+	_ = tw.Flush()
+}
@@ -0,0 +1,59 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/AntoineGS/tidydots/internal/manager"
+)
+
+// prune removes the backed-up files of folder entries that were deleted from
+// their targets. It lists them and asks on in before removing anything,
+// unless yes is set; a dry run only lists them.
+func prune(w io.Writer, in io.Reader, mgr *manager.Manager, yes bool) error {
+	stale, err := mgr.FindStaleBackups()
+	if err != nil {
+		return fmt.Errorf("finding stale backups: %w", err)
+	}
+
+	fmt.Fprintln(w)
+
+	if len(stale) == 0 {
+		fmt.Fprintln(w, "Nothing to prune")
+		return nil
+	}
+
+	for _, s := range stale {
+		fmt.Fprintf(w, "[stale] %s/%s: %s\n", s.App, s.Entry, s.Path)
+	}
+
+	if dryRun {
+		fmt.Fprintf(w, "\nWould remove %d file(s)\n", len(stale))
+		return nil
+	}
+
+	if !yes && !confirm(w, in, fmt.Sprintf("\nRemove %d file(s) from the backup?", len(stale))) {
+		fmt.Fprintln(w, "Nothing removed")
+		return nil
+	}
+
+	removed, err := mgr.PruneBackups(stale)
+	for _, path := range removed {
+		fmt.Fprintf(w, "Removed: %s\n", path)
+	}
+
+	return err
+}
+
+// confirm asks prompt on w and reports whether the answer read from in is
+// yes. Anything else, including no answer at all, is no.
+func confirm(w io.Writer, in io.Reader, prompt string) bool {
+	fmt.Fprintf(w, "%s [y/N] ", prompt)
+
+	answer, _ := bufio.NewReader(in).ReadString('\n') //nolint:errcheck // a failed read is a no
+	answer = strings.ToLower(strings.TrimSpace(answer))
+
+	return answer == "y" || answer == "yes"
+}
@@ -0,0 +1,51 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+
+	"github.com/AntoineGS/tidydots/internal/packages"
+)
+
+// quiet makes commands print only errors and the output a script acts on,
+// such as --format json: a run that succeeds prints nothing.
+var quiet bool
+
+// checkQuiet rejects --quiet with --verbose, which asks for the opposite.
+func checkQuiet() error {
+	if quiet && verbose {
+		return errors.New("--quiet cannot be combined with --verbose")
+	}
+
+	return nil
+}
+
+// infoOut returns where a command prints informational output meant for w:
+// w itself, or nowhere with --quiet.
+func infoOut(w io.Writer) io.Writer {
+	if quiet {
+		return io.Discard
+	}
+
+	return w
+}
+
+// quietLogger returns the logger of a Manager under --quiet: errors only, on
+// stderr, so that stdout is left to results.
+func quietLogger() *slog.Logger {
+	return slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelError}))
+}
+
+// printInstallFailures prints one line per failed install result, which
+// --quiet keeps of the results printInstallResults prints: the error a
+// command returns only counts them.
+func printInstallFailures(w io.Writer, results []packages.InstallResult) {
+	for _, r := range results {
+		if !r.Success {
+			fmt.Fprintf(w, "[error] %s: %s\n", r.Package, r.Message)
+		}
+	}
+}
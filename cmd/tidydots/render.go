@@ -0,0 +1,155 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+
+	"github.com/AntoineGS/tidydots/internal/manager"
+	tmpl "github.com/AntoineGS/tidydots/internal/template"
+	"github.com/spf13/cobra"
+)
+
+var (
+	renderFile    string
+	renderContext []string
+	renderCheck   bool
+)
+
+// errRenderFailed is returned when at least one template fails to parse or
+// execute, after every selected template has been reported.
+var errRenderFailed = errors.New("template rendering failed")
+
+func newRenderCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "render [app[/entry] | -]",
+		Short: "Render templates to stdout without restoring",
+		Long: `Render the .tmpl files of folder entries with the same engine and context
+restore uses, and print the output. With no argument every template is
+rendered; app or app/entry narrows the selection and --file picks one file
+within the entry's backup. Pass - to render a template read from stdin.
+
+--context key=value overrides a context value (OS, Distro, Hostname, User,
+HasDisplay, IsWSL, Env.NAME or Vars.NAME) and may be repeated. --check prints
+one line per template instead of its output, with the line and column of any
+error.`,
+		Args: cobra.MaximumNArgs(1),
+		RunE: runRender,
+	}
+
+	cmd.Flags().StringVar(&renderFile, "file", "", "Render only this file, relative to the entry's backup directory")
+	cmd.Flags().StringArrayVar(&renderContext, "context", nil, "Override a template context value (key=value, repeatable)")
+	cmd.Flags().BoolVar(&renderCheck, "check", false, "Only report parse and execution errors")
+
+	return cmd
+}
+
+func runRender(cmd *cobra.Command, args []string) error {
+	cfg, plat, _, err := loadConfig()
+	if err != nil {
+		return err
+	}
+
+	tmplCtx := tmpl.NewContextFromPlatform(plat).WithConfig(cfg)
+	for _, kv := range renderContext {
+		key, value, ok := strings.Cut(kv, "=")
+		if !ok {
+			return fmt.Errorf("--context %q: expected key=value", kv)
+		}
+
+		if err := tmplCtx.Set(key, value); err != nil {
+			return fmt.Errorf("--context: %w", err)
+		}
+	}
+
+	// Keep stdout for the rendered output.
+	mgr := manager.New(cfg, plat).
+		WithLogger(slog.New(slog.NewTextHandler(os.Stderr, nil))).
+		WithTemplateContext(tmplCtx)
+
+	out, errOut := cmd.OutOrStdout(), cmd.ErrOrStderr()
+
+	if len(args) == 1 && args[0] == "-" {
+		content, err := io.ReadAll(cmd.InOrStdin())
+		if err != nil {
+			return fmt.Errorf("reading stdin: %w", err)
+		}
+
+		rendered, err := mgr.RenderTemplate("stdin", content)
+		if !reportRender(out, errOut, "", "stdin", rendered, err, false) {
+			return errRenderFailed
+		}
+
+		return nil
+	}
+
+	var app, entry string
+	if len(args) == 1 {
+		app, entry, _ = strings.Cut(args[0], "/")
+	}
+
+	files, err := mgr.TemplateFiles(app, entry, renderFile)
+	if err != nil {
+		return err
+	}
+
+	if len(files) == 0 {
+		return fmt.Errorf("no templates found")
+	}
+
+	failed := 0
+
+	for _, tf := range files {
+		rendered, err := mgr.RenderTemplateFile(tf)
+		if !reportRender(out, errOut, tf.App+"/"+tf.Entry, tf.RelPath, rendered, err, len(files) > 1) {
+			failed++
+		}
+	}
+
+	if failed > 0 {
+		fmt.Fprintf(errOut, "%d of %d template(s) failed\n", failed, len(files))
+		return errRenderFailed
+	}
+
+	return nil
+}
+
+// reportRender prints one template's result and reports whether it rendered.
+// entry is "app/entry", or empty for stdin; name is the template's path in
+// the entry's backup, which render errors already start with. With --check
+// only a status line is printed; otherwise the output goes to out, preceded
+// by a header when several templates are printed together. Render errors go
+// to errOut.
+func reportRender(out, errOut io.Writer, entry, name string, rendered []byte, err error, header bool) bool {
+	prefix := ""
+	if entry != "" {
+		prefix = entry + ": "
+	}
+
+	if renderCheck {
+		if err != nil {
+			fmt.Fprintf(out, "✗ %s%v\n", prefix, err)
+			return false
+		}
+
+		fmt.Fprintf(out, "✓ %s%s\n", prefix, name)
+
+		return true
+	}
+
+	if err != nil {
+		fmt.Fprintf(errOut, "Error: %s%v\n", prefix, err)
+		return false
+	}
+
+	if header {
+		fmt.Fprintf(out, "==> %s%s <==\n", prefix, name)
+	}
+
+	_, _ = out.Write(rendered)
+
+	return true
+}
@@ -0,0 +1,63 @@
+package main
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+
+	"github.com/AntoineGS/tidydots/internal/report"
+	"github.com/spf13/cobra"
+)
+
+var (
+	reportOutput   string
+	reportNoRedact bool
+)
+
+func newReportCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "report",
+		Short: "Print a Markdown summary of the configuration and this machine",
+		Long: `Print a Markdown report of the platform, the configuration, the available
+package managers and the state of every entry on this machine, for pasting
+into a bug report. The hostname and user name are replaced by placeholders
+unless --no-redact is given. The report is written to stdout, or to --output.`,
+		Args: cobra.NoArgs,
+		RunE: runReport,
+	}
+
+	cmd.Flags().StringVar(&reportOutput, "output", "", "Write the report to this file instead of stdout")
+	cmd.Flags().BoolVar(&reportNoRedact, "no-redact", false, "Keep the hostname and user name in the report")
+
+	return cmd
+}
+
+func runReport(_ *cobra.Command, _ []string) error {
+	cfg, plat, _, err := loadConfig()
+	if err != nil {
+		return err
+	}
+
+	// Keep stdout pure Markdown: warnings and logs go to stderr.
+	mgr := newManager(cfg, plat, os.Stderr).
+		WithLogger(slog.New(slog.NewTextHandler(os.Stderr, nil)))
+	defer mgr.Close() //nolint:errcheck // best-effort cleanup
+
+	out := report.Generate(cfg, mgr, plat)
+	if !reportNoRedact {
+		out = report.Redact(out, plat)
+	}
+
+	if reportOutput == "" {
+		_, err := fmt.Fprint(os.Stdout, out)
+		return err
+	}
+
+	if err := os.WriteFile(reportOutput, []byte(out), 0600); err != nil {
+		return fmt.Errorf("writing %s: %w", reportOutput, err)
+	}
+
+	fmt.Fprintf(infoOut(os.Stderr), "Wrote report to %s\n", reportOutput)
+
+	return nil
+}
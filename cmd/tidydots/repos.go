@@ -0,0 +1,250 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/AntoineGS/tidydots/internal/gitutil"
+	"github.com/AntoineGS/tidydots/internal/packages"
+	tmpl "github.com/AntoineGS/tidydots/internal/template"
+	"github.com/spf13/cobra"
+)
+
+var reposStash bool
+
+func newReposCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "repos",
+		Short: "Inspect, clone and update the repositories of git packages",
+		Long: `Work with the repositories of git packages (managers: git) on this machine,
+without installing anything else. Each subcommand takes optional package
+names; without them it covers every git package that matches this machine.`,
+	}
+
+	statusCmd := &cobra.Command{
+		Use:   "status [package-names...]",
+		Short: "Show each repository's clone, branch and distance from its upstream",
+		Long: `Show whether each repository is cloned, its current branch, and how many
+commits it is ahead of and behind its upstream. The counts are as of the last
+fetch; 'tidydots repos update' fetches.`,
+		RunE: runReposStatus,
+	}
+
+	updateCmd := &cobra.Command{
+		Use:   "update [package-names...]",
+		Short: "Fetch and fast-forward cloned repositories",
+		Long: `Fetch each cloned repository and fast-forward its branch to the upstream.
+A repository with uncommitted changes is refused unless --stash is given,
+which stashes the changes around the update and reapplies them. A branch
+that has diverged from its upstream is never merged.`,
+		RunE: runReposUpdate,
+	}
+	updateCmd.Flags().BoolVar(&reposStash, "stash", false, "Stash local changes around the update instead of refusing")
+
+	cloneCmd := &cobra.Command{
+		Use:   "clone [package-names...]",
+		Short: "Clone the repositories that are not cloned yet",
+		Long: `Clone each repository that has no clone at its target yet. Existing clones
+are left alone; use 'tidydots repos update' to update them.`,
+		RunE: runReposClone,
+	}
+
+	cmd.AddCommand(statusCmd, updateCmd, cloneCmd)
+
+	return cmd
+}
+
+// loadRepos returns a package manager for this machine and the repositories
+// of the git packages that match it, narrowed to names when any are given.
+// operation names what will be done with them when it changes the machine,
+// which checkCrossOS may refuse; it is empty for read-only commands.
+func loadRepos(names []string, operation string) (*packages.Manager, []packages.GitRepo, error) {
+	cfg, plat, _, err := loadConfig()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if operation != "" {
+		if err := checkCrossOS(plat, operation); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	engine := tmpl.NewEngine(tmpl.NewContextFromPlatform(plat).WithConfig(cfg))
+	pkgs := packages.FilterPackages(packages.FromApplications(cfg.GetFilteredPackages(engine)), engine)
+	repos := packages.GitRepos(pkgs, plat.OS)
+
+	repos, err = selectRepos(repos, names)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	pkgMgr := packages.NewManager(&packages.Config{}, plat.OS, dryRun, verbose)
+	pkgMgr.NoSudo = noSudo
+	pkgMgr.Offline = offline
+	pkgMgr.LogOutput = infoOut(os.Stdout)
+
+	return pkgMgr, repos, nil
+}
+
+// selectRepos returns the repos named in names, in that order, or all of
+// them when names is empty.
+func selectRepos(repos []packages.GitRepo, names []string) ([]packages.GitRepo, error) {
+	if len(names) == 0 {
+		return repos, nil
+	}
+
+	byName := make(map[string]packages.GitRepo, len(repos))
+	for _, repo := range repos {
+		byName[repo.Name] = repo
+	}
+
+	selected := make([]packages.GitRepo, 0, len(names))
+
+	for _, name := range names {
+		repo, ok := byName[name]
+		if !ok {
+			return nil, fmt.Errorf("no git package named %q for this machine", name)
+		}
+
+		selected = append(selected, repo)
+	}
+
+	return selected, nil
+}
+
+func runReposStatus(_ *cobra.Command, args []string) error {
+	pkgMgr, repos, err := loadRepos(args, "")
+	if err != nil {
+		return err
+	}
+
+	if len(repos) > 0 {
+		if err := pkgMgr.CheckGit(); err != nil {
+			return err
+		}
+	}
+
+	return runWithCancellation(func(ctx context.Context) error {
+		printRepoStatus(os.Stdout, pkgMgr.WithContext(ctx), repos)
+		return nil
+	})
+}
+
+// printRepoStatus prints one line per repository describing its clone.
+func printRepoStatus(w io.Writer, pkgMgr *packages.Manager, repos []packages.GitRepo) {
+	if len(repos) == 0 {
+		fmt.Fprintln(w, "No git packages configured for this machine")
+		return
+	}
+
+	for _, repo := range repos {
+		if !gitutil.IsCloned(repo.Path) {
+			fmt.Fprintf(w, "[missing] %s: not cloned at %s\n", repo.Name, repo.Path)
+			continue
+		}
+
+		st, err := pkgMgr.RepoStatus(repo)
+		if err != nil {
+			fmt.Fprintf(w, "[error] %s: %v\n", repo.Name, err)
+			continue
+		}
+
+		fmt.Fprintf(w, "[ok] %s: %s\n", repo.Name, describeRepoStatus(st))
+	}
+}
+
+// describeRepoStatus renders st as e.g. "main, 2 ahead and 1 behind
+// origin/main, local changes".
+func describeRepoStatus(st gitutil.Status) string {
+	parts := []string{"detached HEAD"}
+	if st.Branch != "" {
+		parts[0] = st.Branch
+	}
+
+	switch {
+	case st.Upstream == "":
+		parts = append(parts, "no upstream")
+	case st.Ahead == 0 && st.Behind == 0:
+		parts = append(parts, "up to date with "+st.Upstream)
+	case st.Behind == 0:
+		parts = append(parts, fmt.Sprintf("%d ahead of %s", st.Ahead, st.Upstream))
+	case st.Ahead == 0:
+		parts = append(parts, fmt.Sprintf("%d behind %s", st.Behind, st.Upstream))
+	default:
+		parts = append(parts, fmt.Sprintf("%d ahead and %d behind %s", st.Ahead, st.Behind, st.Upstream))
+	}
+
+	if st.Dirty {
+		parts = append(parts, "local changes")
+	}
+
+	return strings.Join(parts, ", ")
+}
+
+func runReposUpdate(_ *cobra.Command, args []string) error {
+	return runRepoAction(args, "update repositories", "updated", func(pkgMgr *packages.Manager, repo packages.GitRepo) packages.InstallResult {
+		return pkgMgr.UpdateRepo(repo, reposStash)
+	})
+}
+
+func runReposClone(_ *cobra.Command, args []string) error {
+	return runRepoAction(args, "clone repositories", "cloned", (*packages.Manager).CloneRepo)
+}
+
+// runRepoAction runs action, described by operation, on every selected
+// repository, prints the results like install does and fails when any
+// repository failed.
+func runRepoAction(names []string, operation, done string, action func(*packages.Manager, packages.GitRepo) packages.InstallResult) error {
+	pkgMgr, repos, err := loadRepos(names, operation)
+	if err != nil {
+		return err
+	}
+
+	if len(repos) == 0 {
+		fmt.Fprintln(infoOut(os.Stdout), "No git packages configured for this machine")
+		return nil
+	}
+
+	if dryRun {
+		fmt.Fprintln(infoOut(os.Stdout), "=== DRY RUN MODE ===")
+	} else if err := pkgMgr.CheckGit(); err != nil {
+		return err
+	}
+
+	results := make([]packages.InstallResult, 0, len(repos))
+
+	runErr := runWithCancellation(func(ctx context.Context) error {
+		pkgMgr = pkgMgr.WithContext(ctx)
+		for _, repo := range repos {
+			if ctx.Err() != nil {
+				break
+			}
+
+			results = append(results, action(pkgMgr, repo))
+		}
+
+		return nil
+	})
+
+	successCount, failCount := printInstallResults(infoOut(os.Stdout), results)
+
+	fmt.Fprintf(infoOut(os.Stdout), "\nRepositories %s: %d successful, %d failed\n", done, successCount, failCount)
+
+	if quiet {
+		printInstallFailures(os.Stderr, results)
+	}
+
+	if runErr != nil {
+		return runErr
+	}
+
+	if failCount > 0 {
+		return fmt.Errorf("%d repositories failed", failCount)
+	}
+
+	return nil
+}
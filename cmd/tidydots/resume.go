@@ -0,0 +1,45 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/AntoineGS/tidydots/internal/manager"
+)
+
+// resume makes restore and backup skip the entries an interrupted run of the
+// same command completed.
+var resume bool
+
+// checkResume rejects --resume in interactive mode, where the restore
+// confirmation offers to resume instead.
+func checkResume() error {
+	if interactive && resume {
+		return errors.New("--resume cannot be combined with --interactive")
+	}
+
+	return nil
+}
+
+// applyResume sets mgr to resume the interrupted run of op with --resume, and
+// tells w what is resumed. Without --resume, it points out an interrupted run
+// that could have been, since the run about to start begins anew.
+func applyResume(w io.Writer, mgr *manager.Manager, op string) {
+	mgr.Resume = resume
+
+	done, err := mgr.InterruptedEntries(op)
+	if err != nil {
+		fmt.Fprintf(w, "Warning: %v\n", err)
+		return
+	}
+
+	switch {
+	case resume && len(done) == 0:
+		fmt.Fprintf(w, "No interrupted %s to resume; running every entry\n", op)
+	case resume:
+		fmt.Fprintf(w, "Resuming the interrupted %s: %d entry(ies) already done are skipped if still in place\n", op, len(done))
+	case len(done) > 0:
+		fmt.Fprintf(w, "Note: the last %s did not finish (%d entry(ies) done); pass --resume to skip them. Starting over.\n", op, len(done))
+	}
+}
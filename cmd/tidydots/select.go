@@ -0,0 +1,40 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/AntoineGS/tidydots/internal/manager"
+)
+
+// selectApps are the --select names restore and backup are scoped to, and
+// selectExact makes them match whole application names.
+var (
+	selectApps  []string
+	selectExact bool
+)
+
+// checkSelect rejects --exact without --select, and --select in interactive
+// mode, where the TUI lists every application.
+func checkSelect() error {
+	if selectExact && len(selectApps) == 0 {
+		return errors.New("--exact needs --select")
+	}
+
+	if interactive && len(selectApps) > 0 {
+		return errors.New("--select cannot be combined with --interactive")
+	}
+
+	return nil
+}
+
+// applySelect scopes mgr to the --select applications, warning on w about
+// the names that match none.
+func applySelect(w io.Writer, mgr *manager.Manager) {
+	mgr.ExactSelect = selectExact
+
+	for _, name := range mgr.SetApplicationFilter(selectApps) {
+		fmt.Fprintf(w, "Warning: --select %q matches no application\n", name)
+	}
+}
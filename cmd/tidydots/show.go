@@ -0,0 +1,61 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/AntoineGS/tidydots/internal/config"
+	"github.com/spf13/cobra"
+)
+
+var showWithFiles bool
+
+func newShowCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "show <application-name>",
+		Short: "Print one application as a snippet to share",
+		Long: `Print the YAML of a single application, with the defaults its entries
+inherit, so it can be pasted into another config with 'tidydots add-from'.
+
+--with-files bundles the backup files of its config entries as base64
+attachments. Entries whose backup is outside the config directory, or not
+backed up yet, are left out and reported on stderr.`,
+		Args: cobra.ExactArgs(1),
+		RunE: runShow,
+	}
+
+	cmd.Flags().BoolVar(&showWithFiles, "with-files", false, "Bundle the application's backup files in the snippet")
+
+	return cmd
+}
+
+func runShow(cmd *cobra.Command, args []string) error {
+	cfg, _, _, err := loadConfig()
+	if err != nil {
+		return err
+	}
+
+	snippet, err := config.SnippetFor(cfg, args[0])
+	if err != nil {
+		return err
+	}
+
+	if showWithFiles {
+		notes, err := snippet.Attach(cfg.BackupRoot)
+		if err != nil {
+			return err
+		}
+
+		for _, note := range notes {
+			fmt.Fprintf(cmd.ErrOrStderr(), "Not attached: %s\n", note)
+		}
+	}
+
+	data, err := snippet.Marshal()
+	if err != nil {
+		return fmt.Errorf("encoding snippet: %w", err)
+	}
+
+	_, err = cmd.OutOrStdout().Write(data)
+
+	return err
+}
@@ -0,0 +1,211 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/AntoineGS/tidydots/internal/stash"
+	"github.com/spf13/cobra"
+)
+
+func newStashCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "stash",
+		Short: "Save targets edited in place before a restore replaces them",
+		Long: `Copy every target that was edited in place into a new stash in
+~/.local/share/tidydots/stash: a file or directory that took the place of an
+entry's symlink. The targets themselves are left alone; run 'tidydots restore' afterwards.
+
+Each stash is a directory named after the time it was made, with a
+manifest.yaml recording where each file came from. 'tidydots stash pop' puts
+the files back.`,
+		Args: cobra.NoArgs,
+		RunE: runStash,
+	}
+
+	listCmd := &cobra.Command{
+		Use:   "list",
+		Short: "List stashes, newest first",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			store, err := stashStore()
+			if err != nil {
+				return err
+			}
+
+			return stashList(cmd.OutOrStdout(), store)
+		},
+	}
+
+	popCmd := &cobra.Command{
+		Use:   "pop [id]",
+		Short: "Put the files of a stash back where they were and drop it",
+		Long: `Put the files of the newest stash, or of the stash with the given ID, back
+where they were, then drop the stash. The symlink a restore deployed at a
+file's place is replaced. Any other file or directory there stops the pop
+before anything is moved.`,
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			store, err := stashStore()
+			if err != nil {
+				return err
+			}
+
+			return stashPop(cmd.OutOrStdout(), store, firstArg(args))
+		},
+	}
+
+	dropCmd := &cobra.Command{
+		Use:   "drop [id]",
+		Short: "Delete a stash without restoring it",
+		Long:  `Delete the newest stash, or the stash with the given ID.`,
+		Args:  cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			store, err := stashStore()
+			if err != nil {
+				return err
+			}
+
+			if dryRun {
+				st, err := store.Get(firstArg(args))
+				if err != nil {
+					return err
+				}
+
+				fmt.Fprintf(cmd.OutOrStdout(), "Would drop stash %s\n", st.ID)
+
+				return nil
+			}
+
+			st, err := store.Drop(firstArg(args))
+			if err != nil {
+				return err
+			}
+
+			fmt.Fprintf(cmd.OutOrStdout(), "Dropped stash %s\n", st.ID)
+
+			return nil
+		},
+	}
+
+	cmd.AddCommand(listCmd, popCmd, dropCmd)
+
+	return cmd
+}
+
+// stashStore returns the store in the default stash directory.
+func stashStore() (*stash.Store, error) {
+	dir, err := stash.DefaultDir()
+	if err != nil {
+		return nil, err
+	}
+
+	return stash.New(dir), nil
+}
+
+// firstArg returns args[0], or "" when there is none.
+func firstArg(args []string) string {
+	if len(args) == 0 {
+		return ""
+	}
+
+	return args[0]
+}
+
+func runStash(cmd *cobra.Command, _ []string) error {
+	cfg, plat, _, err := loadConfig()
+	if err != nil {
+		return err
+	}
+
+	mgr := newManager(cfg, plat, os.Stderr)
+	defer mgr.Close() //nolint:errcheck // best-effort cleanup
+
+	modified := mgr.ModifiedTargets()
+	w := cmd.OutOrStdout()
+
+	if len(modified) == 0 {
+		fmt.Fprintln(w, "No targets were edited in place; nothing to stash")
+		return nil
+	}
+
+	files := make([]stash.File, 0, len(modified))
+	for _, t := range modified {
+		files = append(files, stash.File{App: t.App, Entry: t.Entry, Path: t.Path})
+	}
+
+	if dryRun {
+		fmt.Fprintf(w, "Would stash %d target(s):\n", len(files))
+		printStashFiles(w, files)
+
+		return nil
+	}
+
+	store, err := stashStore()
+	if err != nil {
+		return err
+	}
+
+	st, err := store.Push(files)
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(w, "Stashed %d target(s) as %s:\n", len(st.Files), st.ID)
+	printStashFiles(w, st.Files)
+
+	return nil
+}
+
+// stashList prints every stash of store with its files.
+func stashList(w io.Writer, store *stash.Store) error {
+	stashes, err := store.List()
+	if err != nil {
+		return err
+	}
+
+	if len(stashes) == 0 {
+		fmt.Fprintln(w, "No stashes")
+		return nil
+	}
+
+	for _, st := range stashes {
+		fmt.Fprintf(w, "%s  %d file(s)\n", st.ID, len(st.Files))
+		printStashFiles(w, st.Files)
+	}
+
+	return nil
+}
+
+// stashPop pops the stash with id, or the newest one, from store.
+func stashPop(w io.Writer, store *stash.Store, id string) error {
+	if dryRun {
+		st, err := store.Get(id)
+		if err != nil {
+			return err
+		}
+
+		fmt.Fprintf(w, "Would restore %d file(s) from stash %s:\n", len(st.Files), st.ID)
+		printStashFiles(w, st.Files)
+
+		return nil
+	}
+
+	st, err := store.Pop(id)
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(w, "Restored %d file(s) from stash %s and dropped it:\n", len(st.Files), st.ID)
+	printStashFiles(w, st.Files)
+
+	return nil
+}
+
+// printStashFiles prints one line per file: its entry and original path.
+func printStashFiles(w io.Writer, files []stash.File) {
+	for _, f := range files {
+		fmt.Fprintf(w, "    %s/%s: %s\n", f.App, f.Entry, f.Path)
+	}
+}
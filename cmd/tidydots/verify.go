@@ -0,0 +1,230 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"github.com/AntoineGS/tidydots/internal/lockfile"
+	"github.com/AntoineGS/tidydots/internal/manager"
+	"github.com/spf13/cobra"
+)
+
+var (
+	verifyIntegrity bool
+	verifyLinks     bool
+	verifyRepair    bool
+	verifyStrict    bool
+)
+
+// errVerifyFailed is returned when a verify check finds problems, so the
+// command exits non-zero after printing its report.
+var errVerifyFailed = errors.New("verification failed")
+
+func newVerifyCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "verify",
+		Short: "Check the health of backed-up configurations",
+		Long: `Run consistency checks against the backups in your dotfiles repository.
+
+--integrity compares every backed-up file against the .sha256 checksum
+written next to it by 'tidydots backup' for entries with verify: true.
+
+--links confirms that every symlinked entry still points to its backup path
+and reports links that are missing, point elsewhere or were replaced by a
+regular file. --repair (which implies --links) re-creates them, moving a
+file or directory that took a link's place aside with a timestamp suffix.
+
+--strict compares every backed-up file against tidydots.lock, written by
+'tidydots pin', and reports files that were modified, removed or added since.
+
+Every check first warns about glob patterns in files lists that match no file
+in their backup directory, and fails on targets restore refuses: system
+directories and the home directory as folder targets without
+allow_dangerous, and targets that resolve into the repository.`,
+		Args: cobra.NoArgs,
+		RunE: runVerify,
+	}
+
+	cmd.Flags().BoolVar(&verifyIntegrity, "integrity", false, "Check backed-up files against their .sha256 checksums")
+	cmd.Flags().BoolVar(&verifyLinks, "links", false, "Check that symlinked entries still point to their backup paths")
+	cmd.Flags().BoolVar(&verifyRepair, "repair", false, "Re-create drifted links (implies --links)")
+	cmd.Flags().BoolVar(&verifyStrict, "strict", false, "Check backed-up files against tidydots.lock")
+
+	return cmd
+}
+
+func runVerify(_ *cobra.Command, _ []string) error {
+	checkLinks := verifyLinks || verifyRepair
+
+	if !verifyIntegrity && !checkLinks && !verifyStrict {
+		return fmt.Errorf("no check selected; pass --integrity, --links or --strict")
+	}
+
+	mgr, err := createManager()
+	if err != nil {
+		return err
+	}
+	defer mgr.Close() //nolint:errcheck // best-effort cleanup
+
+	warnUnmatchedPatterns(os.Stderr, mgr)
+
+	unsafeErr := reportUnsafeTargets(os.Stdout, mgr)
+
+	if verifyRepair {
+		if err := checkCrossOS(mgr.Platform, "repair links"); err != nil {
+			return err
+		}
+	}
+
+	// A missing lockfile is a usage error, reported before any check runs.
+	var lock *lockfile.Lockfile
+	if verifyStrict {
+		if lock, err = loadLockfile(mgr.Config.BackupRoot); err != nil {
+			return err
+		}
+	}
+
+	if dryRun && verifyRepair {
+		fmt.Println("=== DRY RUN MODE ===")
+	}
+
+	errs := []error{unsafeErr}
+
+	if verifyIntegrity {
+		errs = append(errs, runIntegrityCheck(mgr))
+	}
+
+	if checkLinks {
+		if verifyIntegrity {
+			fmt.Println()
+		}
+
+		errs = append(errs, runLinkCheck(os.Stdout, mgr, verifyRepair))
+	}
+
+	if verifyStrict {
+		if verifyIntegrity || checkLinks {
+			fmt.Println()
+		}
+
+		errs = append(errs, runLockCheck(os.Stdout, mgr.Config.BackupRoot, lock))
+	}
+
+	if errors.Join(errs...) != nil {
+		return errVerifyFailed
+	}
+
+	return nil
+}
+
+// warnUnmatchedPatterns prints a warning for every glob pattern in a files
+// entry that matches no file in its backup, which restore and backup skip.
+func warnUnmatchedPatterns(w io.Writer, mgr *manager.Manager) {
+	for _, u := range mgr.UnmatchedPatterns() {
+		fmt.Fprintf(w, "Warning: %s\n", u)
+	}
+}
+
+// reportUnsafeTargets prints every config entry whose target restore
+// refuses and returns errVerifyFailed if there is any.
+func reportUnsafeTargets(w io.Writer, mgr *manager.Manager) error {
+	unsafe := mgr.UnsafeTargets()
+
+	for _, u := range unsafe {
+		fmt.Fprintf(w, "✗ %s\n", u)
+	}
+
+	if len(unsafe) > 0 {
+		fmt.Fprintln(w)
+		return errVerifyFailed
+	}
+
+	return nil
+}
+
+// runIntegrityCheck prints the result of checking every checksum sidecar and
+// returns errVerifyFailed if any backup file does not match.
+func runIntegrityCheck(mgr *manager.Manager) error {
+	checked, failures := mgr.VerifyIntegrity()
+
+	for _, failure := range failures {
+		fmt.Printf("✗ %v\n", failure)
+	}
+
+	fmt.Printf("\nIntegrity check: %d file(s) checked, %d failed\n", checked, len(failures))
+
+	if len(failures) > 0 {
+		return errVerifyFailed
+	}
+
+	return nil
+}
+
+// runLinkCheck prints the entries whose links drifted, repairing them when
+// repair is set, and returns errVerifyFailed if any entry is still drifted or
+// failed.
+func runLinkCheck(w io.Writer, mgr *manager.Manager, repair bool) error {
+	results := mgr.VerifyLinks(repair)
+
+	drifted, failed := 0, 0
+
+	for _, r := range results {
+		if len(r.Drifted) > 0 {
+			drifted++
+		}
+
+		if !r.OK() {
+			failed++
+			fmt.Fprintf(w, "✗ %s/%s: %s\n", r.App, r.Entry, r.Message())
+		} else if r.Repaired {
+			fmt.Fprintf(w, "✓ %s/%s: %s\n", r.App, r.Entry, r.Message())
+		}
+	}
+
+	if repair {
+		fmt.Fprintf(w, "\nLink check: %d entry(s) checked, %d drifted, %d repaired\n", len(results), drifted, drifted-failed)
+	} else {
+		fmt.Fprintf(w, "\nLink check: %d entry(s) checked, %d drifted\n", len(results), drifted)
+	}
+
+	if failed > 0 {
+		return errVerifyFailed
+	}
+
+	return nil
+}
+
+// loadLockfile reads the lockfile in backupRoot, pointing at 'tidydots pin'
+// when there is none.
+func loadLockfile(backupRoot string) (*lockfile.Lockfile, error) {
+	path := filepath.Join(backupRoot, lockfile.FileName)
+
+	lock, err := lockfile.Load(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, fmt.Errorf("%s not found; run 'tidydots pin' first", path)
+	}
+
+	return lock, err
+}
+
+// runLockCheck prints every backed-up file in backupRoot that deviates from
+// lock and returns errVerifyFailed if any does.
+func runLockCheck(w io.Writer, backupRoot string, lock *lockfile.Lockfile) error {
+	deviations := lockfile.Verify(backupRoot, lock)
+
+	for _, d := range deviations {
+		fmt.Fprintf(w, "✗ %s\n", d)
+	}
+
+	fmt.Fprintf(w, "\nLock check: %d file(s) pinned, %d deviation(s)\n", len(lock.Files), len(deviations))
+
+	if len(deviations) > 0 {
+		return errVerifyFailed
+	}
+
+	return nil
+}
@@ -0,0 +1,56 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/AntoineGS/tidydots/internal/manager"
+	"github.com/spf13/cobra"
+)
+
+func newVerifyBackupsCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "verify-backups",
+		Short: "Check that every entry's backup is in the repository",
+		Long: `Check that the backup of every config entry of this platform exists in
+your dotfiles repository, so restore has something to deploy.
+
+A folder entry's backup must be a readable, non-empty directory. A files
+entry's backup must be a readable directory holding every file it lists;
+glob patterns that match no file are reported as warnings. This catches an
+entry added to tidydots.yaml whose files were never moved into the repository.`,
+		Args: cobra.NoArgs,
+		RunE: runVerifyBackups,
+	}
+}
+
+func runVerifyBackups(_ *cobra.Command, _ []string) error {
+	mgr, err := createManager()
+	if err != nil {
+		return err
+	}
+	defer mgr.Close() //nolint:errcheck // best-effort cleanup
+
+	warnUnmatchedPatterns(os.Stderr, mgr)
+
+	return runBackupCheck(os.Stdout, mgr)
+}
+
+// runBackupCheck prints every entry whose backup is missing, empty or
+// incomplete and returns errVerifyFailed if there is any.
+func runBackupCheck(w io.Writer, mgr *manager.Manager) error {
+	checked, problems := mgr.VerifyBackups()
+
+	for _, p := range problems {
+		fmt.Fprintf(w, "✗ %s\n", p)
+	}
+
+	fmt.Fprintf(w, "\nBackup check: %d entry(s) checked, %d problem(s)\n", checked, len(problems))
+
+	if len(problems) > 0 {
+		return errVerifyFailed
+	}
+
+	return nil
+}
@@ -0,0 +1,459 @@
+// Package bundle packs a config and the backups it references into one
+// self-contained directory or tar.gz archive, and unpacks such a bundle into
+// another config directory. It is what `tidydots export --bundle` and
+// `tidydots import` use to share a setup or move it to a new machine when not
+// every backup lives in the dotfiles repo.
+//
+// In a bundle, tidydots.yaml sits at the top and every backup path is
+// relative to it: a backup that was under the config directory keeps its
+// place, and one from elsewhere is put under external/<app>/<entry>.
+package bundle
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/AntoineGS/tidydots/internal/config"
+	"github.com/AntoineGS/tidydots/internal/objects"
+)
+
+// ConfigName is the name of the config in a bundle.
+const ConfigName = "tidydots.yaml"
+
+// ExternalDir holds the backups that were outside the config directory.
+const ExternalDir = "external"
+
+// Backup is a backup source of an exported or imported entry.
+type Backup struct {
+	App   string
+	Entry string
+	From  string // where the backup is read from
+	To    string // the entry's backup path in the bundle, e.g. ./nvim
+	// Missing is set when From does not exist: the entry is bundled, but
+	// restoring it needs a backup first.
+	Missing bool
+}
+
+// IsArchive reports whether path names a tar.gz archive, by its extension.
+func IsArchive(path string) bool {
+	return strings.HasSuffix(path, ".tar.gz") || strings.HasSuffix(path, ".tgz")
+}
+
+// Export writes cfg and the backups of its config entries to dest, a
+// directory or, when IsArchive, a tar.gz archive, neither of which may
+// exist yet. resolve turns a backup path as written in cfg into the path it
+// is read from, and cfg.BackupRoot is the config directory. cfg is not
+// changed: the bundle gets a copy with its backup paths rewritten.
+func Export(cfg *config.Config, resolve func(string) string, dest string) ([]Backup, error) {
+	if _, err := os.Lstat(dest); err == nil {
+		return nil, fmt.Errorf("%s already exists", dest)
+	}
+
+	backups, err := export(cfg, resolve, dest)
+	if err != nil {
+		// dest did not exist, so all there is was written by this export.
+		_ = os.RemoveAll(dest)
+		return nil, err
+	}
+
+	return backups, nil
+}
+
+// export implements Export once dest is known not to exist.
+func export(cfg *config.Config, resolve func(string) string, dest string) ([]Backup, error) {
+	dir := dest
+	if IsArchive(dest) {
+		tmp, err := os.MkdirTemp("", "tidydots-bundle-")
+		if err != nil {
+			return nil, err
+		}
+		defer os.RemoveAll(tmp) //nolint:errcheck // best-effort cleanup
+
+		dir = tmp
+	}
+
+	out := *cfg
+	out.Applications = make([]config.Application, len(cfg.Applications))
+
+	var backups []Backup
+
+	copied := make(map[string]bool)
+
+	for i, app := range cfg.Applications {
+		app.Entries = append([]config.SubEntry(nil), app.Entries...)
+
+		for j, entry := range app.Entries {
+			if !entry.IsConfig() {
+				continue
+			}
+
+			b := Backup{App: app.Name, Entry: entry.Name, From: resolve(entry.Backup)}
+			rel := bundlePath(cfg.BackupRoot, b)
+			b.To = "./" + filepath.ToSlash(rel)
+			app.Entries[j].Backup = b.To
+
+			switch _, err := os.Lstat(b.From); {
+			case errors.Is(err, fs.ErrNotExist):
+				b.Missing = true
+			case err != nil:
+				return nil, err
+			case !copied[rel]:
+				copied[rel] = true
+
+				if err := copyTree(b.From, filepath.Join(dir, rel), false); err != nil {
+					return nil, fmt.Errorf("copying backup of %s/%s: %w", app.Name, entry.Name, err)
+				}
+			}
+
+			backups = append(backups, b)
+		}
+
+		out.Applications[i] = app
+	}
+
+	// Dedupe pointers need the objects they name.
+	store := filepath.Join(cfg.BackupRoot, filepath.FromSlash(objects.Dir))
+	if _, err := os.Stat(store); err == nil {
+		if err := copyTree(store, filepath.Join(dir, filepath.FromSlash(objects.Dir)), false); err != nil {
+			return nil, fmt.Errorf("copying %s: %w", objects.Dir, err)
+		}
+	}
+
+	data, err := config.Marshal(&out)
+	if err != nil {
+		return nil, fmt.Errorf("encoding config: %w", err)
+	}
+
+	if err := os.MkdirAll(dir, 0o750); err != nil {
+		return nil, err
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, ConfigName), data, 0o600); err != nil {
+		return nil, err
+	}
+
+	if dir != dest {
+		if err := writeArchive(dir, dest); err != nil {
+			return nil, fmt.Errorf("writing %s: %w", dest, err)
+		}
+	}
+
+	return backups, nil
+}
+
+// bundlePath returns where the backup b goes in the bundle, relative to it:
+// its path relative to root when it is under root, otherwise a place under
+// ExternalDir named after its entry.
+func bundlePath(root string, b Backup) string {
+	if rel, err := filepath.Rel(root, b.From); err == nil && rel != "." && filepath.IsLocal(rel) &&
+		!strings.HasPrefix(filepath.ToSlash(rel), ExternalDir+"/") {
+		return rel
+	}
+
+	name := func(s string) string {
+		return strings.NewReplacer("/", "_", `\`, "_").Replace(s)
+	}
+
+	return filepath.Join(ExternalDir, name(b.App), name(b.Entry))
+}
+
+// Open returns the directory of the bundle at path: path itself when it is a
+// directory, or a temporary one the archive is extracted to. cleanup removes
+// what Open created and must be called once the bundle is no longer needed.
+func Open(path string) (dir string, cleanup func(), err error) {
+	if !IsArchive(path) {
+		return path, func() {}, nil
+	}
+
+	tmp, err := os.MkdirTemp("", "tidydots-bundle-")
+	if err != nil {
+		return "", nil, err
+	}
+
+	cleanup = func() { _ = os.RemoveAll(tmp) }
+
+	if err := extractArchive(path, tmp); err != nil {
+		cleanup()
+		return "", nil, fmt.Errorf("extracting %s: %w", path, err)
+	}
+
+	return tmp, cleanup, nil
+}
+
+// Install copies the backups of apps, the applications imported from the
+// bundle in dir, into root, the config directory, along with the dedupe
+// objects root lacks. Nothing is copied when any backup is already in root,
+// unless overwrite is set, in which case it is replaced. With dryRun, only
+// the checks are made.
+func Install(dir, root string, apps []config.Application, overwrite, dryRun bool) ([]Backup, error) {
+	var backups []Backup
+
+	seen := make(map[string]bool)
+
+	for _, app := range apps {
+		for _, entry := range app.Entries {
+			if !entry.IsConfig() {
+				continue
+			}
+
+			rel := filepath.Clean(filepath.FromSlash(entry.Backup))
+			if !filepath.IsLocal(rel) || rel == "." {
+				return nil, fmt.Errorf("%s/%s: backup %q is not inside the bundle", app.Name, entry.Name, entry.Backup)
+			}
+
+			if seen[rel] {
+				continue
+			}
+
+			seen[rel] = true
+
+			b := Backup{App: app.Name, Entry: entry.Name, From: filepath.Join(dir, rel), To: entry.Backup}
+			if _, err := os.Lstat(b.From); err != nil {
+				b.Missing = true
+			}
+
+			if _, err := os.Lstat(filepath.Join(root, rel)); err == nil && !b.Missing && !overwrite {
+				return nil, fmt.Errorf("%s/%s: backup %s already exists in %s (use --on-conflict=overwrite to replace it)",
+					app.Name, entry.Name, entry.Backup, root)
+			}
+
+			backups = append(backups, b)
+		}
+	}
+
+	if dryRun {
+		return backups, nil
+	}
+
+	for _, b := range backups {
+		if b.Missing {
+			continue
+		}
+
+		dst := filepath.Join(root, filepath.FromSlash(b.To))
+		if err := os.RemoveAll(dst); err != nil {
+			return nil, err
+		}
+
+		if err := copyTree(b.From, dst, false); err != nil {
+			return nil, fmt.Errorf("copying backup of %s/%s: %w", b.App, b.Entry, err)
+		}
+	}
+
+	store := filepath.Join(dir, filepath.FromSlash(objects.Dir))
+	if _, err := os.Stat(store); err == nil {
+		if err := copyTree(store, filepath.Join(root, filepath.FromSlash(objects.Dir)), true); err != nil {
+			return nil, fmt.Errorf("copying %s: %w", objects.Dir, err)
+		}
+	}
+
+	return backups, nil
+}
+
+// copyTree copies the file or directory at src to dst, keeping file modes
+// and recreating symlinks. With keep, files already at dst are left alone.
+func copyTree(src, dst string, keep bool) error {
+	return filepath.WalkDir(src, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		rel, err := filepath.Rel(src, path)
+		if err != nil {
+			return err
+		}
+
+		target := filepath.Join(dst, rel)
+
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+
+		if d.IsDir() {
+			return os.MkdirAll(target, info.Mode().Perm()|0o700)
+		}
+
+		if keep {
+			if _, err := os.Lstat(target); err == nil {
+				return nil
+			}
+		}
+
+		if err := os.MkdirAll(filepath.Dir(target), 0o750); err != nil {
+			return err
+		}
+
+		if d.Type()&fs.ModeSymlink != 0 {
+			link, err := os.Readlink(path)
+			if err != nil {
+				return err
+			}
+
+			return os.Symlink(link, target)
+		}
+
+		data, err := os.ReadFile(path) //nolint:gosec // path is walked from a backup the config names
+		if err != nil {
+			return err
+		}
+
+		return os.WriteFile(target, data, info.Mode().Perm())
+	})
+}
+
+// writeArchive writes the tree at dir to a tar.gz archive at dest.
+func writeArchive(dir, dest string) error {
+	f, err := os.OpenFile(dest, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o600) //nolint:gosec // dest is given by the user
+	if err != nil {
+		return err
+	}
+
+	gz := gzip.NewWriter(f)
+	tw := tar.NewWriter(gz)
+
+	walkErr := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		rel, err := filepath.Rel(dir, path)
+		if err != nil || rel == "." {
+			return err
+		}
+
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+
+		var link string
+		if d.Type()&fs.ModeSymlink != 0 {
+			if link, err = os.Readlink(path); err != nil {
+				return err
+			}
+		}
+
+		hdr, err := tar.FileInfoHeader(info, link)
+		if err != nil {
+			return err
+		}
+
+		hdr.Name = filepath.ToSlash(rel)
+		if d.IsDir() {
+			hdr.Name += "/"
+		}
+
+		if err := tw.WriteHeader(hdr); err != nil {
+			return err
+		}
+
+		if !d.Type().IsRegular() {
+			return nil
+		}
+
+		src, err := os.Open(path) //nolint:gosec // path is walked from the staging directory
+		if err != nil {
+			return err
+		}
+		defer src.Close() //nolint:errcheck // read-only
+
+		_, err = io.Copy(tw, src)
+
+		return err
+	})
+
+	return errors.Join(walkErr, tw.Close(), gz.Close(), f.Close())
+}
+
+// extractArchive extracts the tar.gz archive at path into dir. Entries that
+// would land outside dir are refused, and symlinks are created last so no
+// file is written through one.
+func extractArchive(path, dir string) error {
+	f, err := os.Open(path) //nolint:gosec // path is given by the user
+	if err != nil {
+		return err
+	}
+	defer f.Close() //nolint:errcheck // read-only
+
+	gz, err := gzip.NewReader(f)
+	if err != nil {
+		return err
+	}
+
+	tr := tar.NewReader(gz)
+
+	var links []*tar.Header
+
+	for {
+		hdr, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+
+		if err != nil {
+			return err
+		}
+
+		name := filepath.FromSlash(strings.TrimSuffix(hdr.Name, "/"))
+		if !filepath.IsLocal(name) {
+			return fmt.Errorf("%s: path outside the bundle", hdr.Name)
+		}
+
+		target := filepath.Join(dir, name)
+
+		switch hdr.Typeflag {
+		case tar.TypeDir:
+			if err := os.MkdirAll(target, hdr.FileInfo().Mode().Perm()|0o700); err != nil {
+				return err
+			}
+		case tar.TypeReg:
+			if err := extractFile(tr, target, hdr.FileInfo().Mode().Perm()); err != nil {
+				return err
+			}
+		case tar.TypeSymlink:
+			links = append(links, hdr)
+		default:
+			return fmt.Errorf("%s: unsupported entry type %q", hdr.Name, hdr.Typeflag)
+		}
+	}
+
+	for _, hdr := range links {
+		target := filepath.Join(dir, filepath.FromSlash(hdr.Name))
+		if err := os.MkdirAll(filepath.Dir(target), 0o750); err != nil {
+			return err
+		}
+
+		if err := os.Symlink(hdr.Linkname, target); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// extractFile writes the current file of tr to target with perm.
+func extractFile(tr io.Reader, target string, perm fs.FileMode) error {
+	if err := os.MkdirAll(filepath.Dir(target), 0o750); err != nil {
+		return err
+	}
+
+	out, err := os.OpenFile(target, os.O_WRONLY|os.O_CREATE|os.O_EXCL, perm) //nolint:gosec // target is checked to be inside dir
+	if err != nil {
+		return err
+	}
+
+	if _, err := io.Copy(out, tr); err != nil { //nolint:gosec // a bundle is a user's own backups
+		_ = out.Close()
+		return err
+	}
+
+	return out.Close()
+}
@@ -0,0 +1,226 @@
+package bundle
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/AntoineGS/tidydots/internal/config"
+)
+
+// writeFile writes content to path, creating its directory.
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func readFile(t *testing.T, path string) string {
+	t.Helper()
+
+	data, err := os.ReadFile(path) //nolint:gosec // test file
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return string(data)
+}
+
+// newConfig returns a config in a temporary directory with three entries:
+// nvim backed up in the config directory, ssh backed up outside it, and zsh
+// whose backup does not exist. It also returns the directory outside.
+func newConfig(t *testing.T) (*config.Config, string) {
+	t.Helper()
+
+	root := t.TempDir()
+	elsewhere := t.TempDir()
+
+	writeFile(t, filepath.Join(root, "nvim", "init.lua"), "vim.o.number = true")
+	writeFile(t, filepath.Join(elsewhere, "ssh", "config"), "Host *")
+
+	if err := os.Symlink("init.lua", filepath.Join(root, "nvim", "link.lua")); err != nil {
+		t.Fatal(err)
+	}
+
+	entry := func(name, backup string) config.SubEntry {
+		return config.SubEntry{Name: name, Backup: backup, Targets: map[string]string{"linux": "~/." + name}}
+	}
+
+	return &config.Config{
+		Version:    3,
+		BackupRoot: root,
+		Applications: []config.Application{
+			{Name: "nvim", Entries: []config.SubEntry{entry("config", "./nvim")}},
+			{Name: "ssh", Entries: []config.SubEntry{entry("config", filepath.Join(elsewhere, "ssh"))}},
+			{Name: "zsh", Entries: []config.SubEntry{entry("config", "./zsh")}},
+		},
+	}, elsewhere
+}
+
+// resolver resolves backup paths against cfg's BackupRoot.
+func resolver(cfg *config.Config) func(string) string {
+	return func(path string) string {
+		return config.ResolveBackupPath(path, cfg.BackupRoot, nil, nil)
+	}
+}
+
+func TestExport(t *testing.T) {
+	for _, name := range []string{"bundle", "bundle.tar.gz"} {
+		t.Run(name, func(t *testing.T) {
+			cfg, _ := newConfig(t)
+			dest := filepath.Join(t.TempDir(), name)
+
+			backups, err := Export(cfg, resolver(cfg), dest)
+			if err != nil {
+				t.Fatalf("Export() error = %v", err)
+			}
+
+			var got []string
+			for _, b := range backups {
+				got = append(got, b.App+":"+b.To)
+				if b.Missing != (b.App == "zsh") {
+					t.Errorf("%s Missing = %v", b.App, b.Missing)
+				}
+			}
+
+			if want := "nvim:./nvim ssh:./external/ssh/config zsh:./zsh"; strings.Join(got, " ") != want {
+				t.Errorf("backups = %s, want %s", strings.Join(got, " "), want)
+			}
+
+			if cfg.Applications[1].Entries[0].Backup == "./external/ssh/config" {
+				t.Error("Export() rewrote the caller's config")
+			}
+
+			dir, cleanup, err := Open(dest)
+			if err != nil {
+				t.Fatalf("Open() error = %v", err)
+			}
+			defer cleanup()
+
+			bundled, err := config.Load(filepath.Join(dir, ConfigName))
+			if err != nil {
+				t.Fatalf("bundled config does not load: %v", err)
+			}
+
+			if got := bundled.Applications[1].Entries[0].Backup; got != "./external/ssh/config" {
+				t.Errorf("bundled ssh backup = %q, want ./external/ssh/config", got)
+			}
+
+			if got := readFile(t, filepath.Join(dir, "external", "ssh", "config", "config")); got != "Host *" {
+				t.Errorf("bundled ssh config = %q", got)
+			}
+
+			if link, err := os.Readlink(filepath.Join(dir, "nvim", "link.lua")); err != nil || link != "init.lua" {
+				t.Errorf("bundled symlink = (%q, %v), want init.lua", link, err)
+			}
+		})
+	}
+}
+
+func TestExport_DestinationExists(t *testing.T) {
+	cfg, _ := newConfig(t)
+	dest := t.TempDir()
+
+	if _, err := Export(cfg, resolver(cfg), dest); err == nil || !strings.Contains(err.Error(), "already exists") {
+		t.Errorf("Export() over an existing directory error = %v, want already exists", err)
+	}
+}
+
+func TestInstall(t *testing.T) {
+	cfg, _ := newConfig(t)
+	dest := filepath.Join(t.TempDir(), "bundle")
+
+	if _, err := Export(cfg, resolver(cfg), dest); err != nil {
+		t.Fatalf("Export() error = %v", err)
+	}
+
+	bundled, err := config.Load(filepath.Join(dest, ConfigName))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	root := t.TempDir()
+
+	if _, err := Install(dest, root, bundled.Applications, false, true); err != nil {
+		t.Fatalf("Install() dry run error = %v", err)
+	}
+
+	if _, err := os.Stat(filepath.Join(root, "nvim")); err == nil {
+		t.Fatal("Install() dry run copied a backup")
+	}
+
+	if _, err := Install(dest, root, bundled.Applications, false, false); err != nil {
+		t.Fatalf("Install() error = %v", err)
+	}
+
+	if got := readFile(t, filepath.Join(root, "external", "ssh", "config", "config")); got != "Host *" {
+		t.Errorf("installed ssh config = %q", got)
+	}
+
+	writeFile(t, filepath.Join(root, "nvim", "init.lua"), "edited")
+
+	if _, err := Install(dest, root, bundled.Applications, false, false); err == nil || !strings.Contains(err.Error(), "already exists") {
+		t.Errorf("Install() over an existing backup error = %v, want already exists", err)
+	}
+
+	if got := readFile(t, filepath.Join(root, "nvim", "init.lua")); got != "edited" {
+		t.Errorf("failed Install() changed the existing backup to %q", got)
+	}
+
+	if _, err := Install(dest, root, bundled.Applications, true, false); err != nil {
+		t.Fatalf("Install() with overwrite error = %v", err)
+	}
+
+	if got := readFile(t, filepath.Join(root, "nvim", "init.lua")); got != "vim.o.number = true" {
+		t.Errorf("overwritten backup = %q", got)
+	}
+}
+
+func TestInstall_BackupOutsideBundle(t *testing.T) {
+	apps := []config.Application{{Name: "evil", Entries: []config.SubEntry{
+		{Name: "config", Backup: "../outside", Targets: map[string]string{"linux": "~/.evil"}},
+	}}}
+
+	if _, err := Install(t.TempDir(), t.TempDir(), apps, true, false); err == nil {
+		t.Error("Install() with a backup outside the bundle succeeded, want an error")
+	}
+}
+
+func TestOpen_RefusesPathOutsideBundle(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "evil.tar.gz")
+
+	f, err := os.Create(path) //nolint:gosec // test file
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	gz := gzip.NewWriter(f)
+	tw := tar.NewWriter(gz)
+
+	if err := tw.WriteHeader(&tar.Header{Name: "../escape", Mode: 0o600, Size: 1, Typeflag: tar.TypeReg}); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := tw.Write([]byte("x")); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, c := range []interface{ Close() error }{tw, gz, f} {
+		if err := c.Close(); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if _, _, err := Open(path); err == nil || !strings.Contains(err.Error(), "outside the bundle") {
+		t.Errorf("Open() error = %v, want path outside the bundle", err)
+	}
+}
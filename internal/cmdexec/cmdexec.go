@@ -1,6 +1,14 @@
 package cmdexec
 
-import "context"
+import (
+	"context"
+	"errors"
+)
+
+// ErrElevationDeclined is wrapped by the error of a sudo command that did not
+// run because authentication failed or was declined, e.g. a wrong password or
+// no terminal to ask for one.
+var ErrElevationDeclined = errors.New("elevation declined")
 
 // Result holds the output of a command execution.
 type Result struct {
@@ -16,6 +24,9 @@ type RunOptions struct {
 	Dir string
 	// Sudo runs the command with elevated privileges.
 	Sudo bool
+	// Env holds KEY=VALUE variables added to the environment the command
+	// inherits, overriding variables of the same name.
+	Env []string
 }
 
 // Runner abstracts command execution.
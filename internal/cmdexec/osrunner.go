@@ -4,7 +4,10 @@ import (
 	"bytes"
 	"context"
 	"errors"
+	"fmt"
+	"os"
 	"os/exec"
+	"strings"
 )
 
 // OsRunner is the real implementation of Runner using os/exec.
@@ -15,7 +18,13 @@ type OsRunner struct{}
 // whatever output was captured and the exit code from ProcessState.
 func (r OsRunner) RunIn(ctx context.Context, opts RunOptions, name string, args ...string) (Result, error) {
 	if opts.Sudo {
-		sudoArgs := make([]string, 0, 1+len(args))
+		sudoArgs := make([]string, 0, 2+len(opts.Env)+len(args))
+		if len(opts.Env) > 0 {
+			// sudo resets the environment, so pass the variables through env.
+			sudoArgs = append(sudoArgs, "env")
+			sudoArgs = append(sudoArgs, opts.Env...)
+		}
+
 		sudoArgs = append(sudoArgs, name)
 		sudoArgs = append(sudoArgs, args...)
 		name, args = "sudo", sudoArgs
@@ -25,6 +34,10 @@ func (r OsRunner) RunIn(ctx context.Context, opts RunOptions, name string, args
 
 	cmd := exec.CommandContext(ctx, name, args...)
 	cmd.Dir = opts.Dir
+
+	if len(opts.Env) > 0 {
+		cmd.Env = append(os.Environ(), opts.Env...)
+	}
 	cmd.Stdout = &stdoutBuf
 	cmd.Stderr = &stderrBuf
 
@@ -45,11 +58,40 @@ func (r OsRunner) RunIn(ctx context.Context, opts RunOptions, name string, args
 			// Not an exit error (e.g. command not found); propagate as-is.
 			return result, runErr
 		}
+
+		if opts.Sudo && elevationDeclined(result.Stderr) {
+			runErr = fmt.Errorf("%w: %w", ErrElevationDeclined, runErr)
+		}
 	}
 
 	return result, runErr
 }
 
+// sudoAuthFailures are the messages sudo prints when it refuses to run a
+// command because the user could not, or would not, authenticate.
+var sudoAuthFailures = []string{
+	"a password is required",
+	"no password was provided",
+	"incorrect password attempt",
+	"a terminal is required",
+	"no tty present",
+	"not in the sudoers file",
+}
+
+// elevationDeclined reports whether stderr, the output of a failed sudo
+// command, is sudo refusing to authenticate rather than the command failing.
+func elevationDeclined(stderr []byte) bool {
+	msg := strings.ToLower(string(stderr))
+
+	for _, failure := range sudoAuthFailures {
+		if strings.Contains(msg, failure) {
+			return true
+		}
+	}
+
+	return false
+}
+
 // Run executes a command and captures its stdout and stderr.
 func (r OsRunner) Run(ctx context.Context, name string, args ...string) (Result, error) {
 	return r.RunIn(ctx, RunOptions{}, name, args...)
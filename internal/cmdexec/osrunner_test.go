@@ -2,6 +2,8 @@ package cmdexec_test
 
 import (
 	"context"
+	"errors"
+	"os"
 	"path/filepath"
 	"runtime"
 	"strings"
@@ -55,3 +57,59 @@ func TestOsRunner_RunIn_NonZeroExitReportsExitCode(t *testing.T) {
 		t.Errorf("ExitCode = %d, want 3", res.ExitCode)
 	}
 }
+
+func TestOsRunner_RunIn_AddsEnv(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("sh is not available on Windows")
+	}
+
+	t.Setenv("TIDYDOTS_TEST_KEPT", "kept")
+
+	opts := cmdexec.RunOptions{Env: []string{"TIDYDOTS_TEST_ADDED=added"}}
+
+	res, err := cmdexec.OsRunner{}.RunIn(context.Background(), opts, "sh", "-c", `echo "$TIDYDOTS_TEST_ADDED $TIDYDOTS_TEST_KEPT"`)
+	if err != nil {
+		t.Fatalf("RunIn returned error: %v", err)
+	}
+
+	if got := strings.TrimSpace(string(res.Stdout)); got != "added kept" {
+		t.Errorf("output = %q, want the added variable next to the inherited environment", got)
+	}
+}
+
+func TestOsRunner_RunIn_SudoAuthFailureIsElevationDeclined(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("sudo is not available on Windows")
+	}
+
+	// A fake sudo that fails with the message in FAKE_SUDO_STDERR.
+	bin := t.TempDir()
+	script := "#!/bin/sh\necho \"$FAKE_SUDO_STDERR\" >&2\nexit 1\n"
+	if err := os.WriteFile(filepath.Join(bin, "sudo"), []byte(script), 0o755); err != nil { //nolint:gosec // the fake sudo must be executable
+		t.Fatal(err)
+	}
+
+	t.Setenv("PATH", bin+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+	tests := []struct {
+		stderr   string
+		declined bool
+	}{
+		{stderr: "sudo: a password is required", declined: true},
+		{stderr: "Sorry, try again.\nsudo: 3 incorrect password attempts", declined: true},
+		{stderr: "cp: cannot stat '/src': No such file or directory", declined: false},
+	}
+
+	for _, tt := range tests {
+		t.Setenv("FAKE_SUDO_STDERR", tt.stderr)
+
+		_, err := cmdexec.OsRunner{}.RunIn(context.Background(), cmdexec.RunOptions{Sudo: true}, "cp", "/src", "/dst")
+		if err == nil {
+			t.Fatalf("stderr %q: expected an error", tt.stderr)
+		}
+
+		if got := errors.Is(err, cmdexec.ErrElevationDeclined); got != tt.declined {
+			t.Errorf("stderr %q: errors.Is(err, ErrElevationDeclined) = %v, want %v", tt.stderr, got, tt.declined)
+		}
+	}
+}
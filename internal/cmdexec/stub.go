@@ -3,6 +3,7 @@ package cmdexec
 import (
 	"context"
 	"os/exec"
+	"sync"
 )
 
 // Call records a single invocation of Run, RunWithSudo, or RunIn.
@@ -10,21 +11,25 @@ type Call struct {
 	Name string
 	Args []string
 	Dir  string
+	Env  []string
 	Sudo bool
 }
 
 // StubRunner is a test fake that records calls and returns pre-configured results.
+// Run, RunWithSudo and RunIn may be called concurrently; read Calls only once
+// they have returned.
 type StubRunner struct {
 	// Calls is the ordered list of all recorded invocations.
 	Calls   []Call
-	results map[string][]Result
+	results map[string][]stubResult
 	paths   map[string]string
+	mu      sync.Mutex
 }
 
 // NewStubRunner creates an empty StubRunner.
 func NewStubRunner() *StubRunner {
 	return &StubRunner{
-		results: make(map[string][]Result),
+		results: make(map[string][]stubResult),
 		paths:   make(map[string]string),
 	}
 }
@@ -32,7 +37,19 @@ func NewStubRunner() *StubRunner {
 // AddResult queues a Result to be returned the next time Run or RunWithSudo is
 // called with the given command name. Results are consumed in FIFO order.
 func (s *StubRunner) AddResult(name string, r Result) {
-	s.results[name] = append(s.results[name], r)
+	s.results[name] = append(s.results[name], stubResult{result: r})
+}
+
+// AddError queues err, with a zero Result, to be returned the next time a
+// command with the given name is run, in FIFO order with AddResult.
+func (s *StubRunner) AddError(name string, err error) {
+	s.results[name] = append(s.results[name], stubResult{err: err})
+}
+
+// stubResult is a queued outcome of a StubRunner command.
+type stubResult struct {
+	err    error
+	result Result
 }
 
 // AddPath registers a path to be returned by LookPath for the given name.
@@ -40,26 +57,22 @@ func (s *StubRunner) AddPath(name, path string) {
 	s.paths[name] = path
 }
 
-// Run records the call and returns the next queued Result for name.
+// Run records the call and returns the next queued Result and error for name.
 // If no result is queued, a zero Result is returned with no error.
 func (s *StubRunner) Run(_ context.Context, name string, args ...string) (Result, error) {
-	s.Calls = append(s.Calls, Call{Name: name, Args: args, Sudo: false})
-
-	return s.popResult(name), nil
+	return s.record(Call{Name: name, Args: args, Sudo: false})
 }
 
-// RunWithSudo records the call with Sudo=true and returns the next queued Result.
+// RunWithSudo records the call with Sudo=true and returns the next queued
+// Result and error.
 func (s *StubRunner) RunWithSudo(_ context.Context, name string, args ...string) (Result, error) {
-	s.Calls = append(s.Calls, Call{Name: name, Args: args, Sudo: true})
-
-	return s.popResult(name), nil
+	return s.record(Call{Name: name, Args: args, Sudo: true})
 }
 
-// RunIn records the call with its options and returns the next queued Result.
+// RunIn records the call with its options and returns the next queued Result
+// and error.
 func (s *StubRunner) RunIn(_ context.Context, opts RunOptions, name string, args ...string) (Result, error) {
-	s.Calls = append(s.Calls, Call{Name: name, Args: args, Dir: opts.Dir, Sudo: opts.Sudo})
-
-	return s.popResult(name), nil
+	return s.record(Call{Name: name, Args: args, Dir: opts.Dir, Env: opts.Env, Sudo: opts.Sudo})
 }
 
 // LookPath returns the registered path for name, or exec.ErrNotFound if none.
@@ -71,16 +84,28 @@ func (s *StubRunner) LookPath(name string) (string, error) {
 	return "", exec.ErrNotFound
 }
 
-// popResult removes and returns the first queued Result for name.
-// Returns a zero Result if the queue is empty.
-func (s *StubRunner) popResult(name string) Result {
+// record appends call to Calls and returns the next queued Result and error
+// for its command name.
+func (s *StubRunner) record(call Call) (Result, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.Calls = append(s.Calls, call)
+
+	return s.popResult(call.Name)
+}
+
+// popResult removes and returns the first queued Result and error for name.
+// Returns a zero Result and no error if the queue is empty. The caller holds
+// s.mu.
+func (s *StubRunner) popResult(name string) (Result, error) {
 	queue := s.results[name]
 	if len(queue) == 0 {
-		return Result{}
+		return Result{}, nil
 	}
 
 	r := queue[0]
 	s.results[name] = queue[1:]
 
-	return r
+	return r.result, r.err
 }
@@ -150,3 +150,17 @@ func TestStubRunner_RunIn_RecordsDirAndSudo(t *testing.T) {
 		t.Error("Call.Sudo = false, want true")
 	}
 }
+
+func TestStubRunner_AddError_ReturnedInOrder(t *testing.T) {
+	s := cmdexec.NewStubRunner()
+	s.AddError("ln", cmdexec.ErrElevationDeclined)
+	s.AddResult("ln", cmdexec.Result{ExitCode: 0})
+
+	if _, err := s.RunWithSudo(context.Background(), "ln", "-s", "a", "b"); !errors.Is(err, cmdexec.ErrElevationDeclined) {
+		t.Errorf("first call error = %v, want ErrElevationDeclined", err)
+	}
+
+	if _, err := s.RunWithSudo(context.Background(), "ln", "-s", "a", "b"); err != nil {
+		t.Errorf("second call error = %v, want nil", err)
+	}
+}
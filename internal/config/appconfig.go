@@ -11,10 +11,78 @@ import (
 )
 
 // AppConfig is the minimal configuration stored in ~/.config/tidydots/
-// It only contains the path to the configurations repository
+// It contains the path to the configurations repository and machine-local
+// preferences that do not belong in the shared repository config.
 type AppConfig struct {
 	// ConfigDir is the path to the configurations repository
 	ConfigDir string `yaml:"config_dir"`
+
+	// GloballyUniqueSubEntryNames makes the TUI reject a sub-entry name that
+	// is already used by any application, not just the one being edited.
+	GloballyUniqueSubEntryNames bool `yaml:"globally_unique_sub_entry_names,omitempty"`
+
+	// MaxHistoryEntries is how many template renders the state store keeps
+	// per template; older ones are pruned. Zero means
+	// DefaultMaxHistoryEntries; see HistoryLimit.
+	MaxHistoryEntries int `yaml:"max_history_entries,omitempty"`
+
+	// KeyBindings remaps TUI actions, by action name such as
+	// "list.sort_by_name", to keys. The TUI validates the names and
+	// checks the keys for conflicts when it starts.
+	KeyBindings map[string]KeyList `yaml:"keybindings,omitempty"`
+
+	// Theme is the TUI's color theme: dark, light, monochrome, or auto
+	// (the default) for dark or light to follow the terminal's background.
+	// The TUI validates it when it starts.
+	Theme string `yaml:"theme,omitempty"`
+}
+
+// KeyList is the keys of a remapped TUI action, written in YAML as a single
+// key or a list of keys.
+type KeyList []string
+
+// UnmarshalYAML accepts a single key as well as a list of keys.
+func (k *KeyList) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind == yaml.ScalarNode {
+		*k = KeyList{node.Value}
+		return nil
+	}
+
+	var keys []string
+	if err := node.Decode(&keys); err != nil {
+		return err
+	}
+
+	*k = keys
+
+	return nil
+}
+
+// KeyBindingOverrides returns KeyBindings as plain key lists by action name.
+func (a *AppConfig) KeyBindingOverrides() map[string][]string {
+	if a == nil || len(a.KeyBindings) == 0 {
+		return nil
+	}
+
+	overrides := make(map[string][]string, len(a.KeyBindings))
+	for name, keys := range a.KeyBindings {
+		overrides[name] = keys
+	}
+
+	return overrides
+}
+
+// DefaultMaxHistoryEntries is the number of renders kept per template when
+// max_history_entries is not set.
+const DefaultMaxHistoryEntries = 500
+
+// HistoryLimit returns the number of renders to keep per template.
+func (a *AppConfig) HistoryLimit() int {
+	if a == nil || a.MaxHistoryEntries <= 0 {
+		return DefaultMaxHistoryEntries
+	}
+
+	return a.MaxHistoryEntries
 }
 
 const (
@@ -23,8 +91,19 @@ const (
 	repoConfigFile = "tidydots.yaml"
 )
 
-// LoadAppConfig loads the app configuration from ~/.config/tidydots/config.yaml
+// LoadAppConfig loads the app configuration from ~/.config/tidydots/config.yaml.
+// Like Load, a missing file is reported with an error matching
+// ErrConfigNotFound, any other failure with one matching ErrInvalidConfig.
 func LoadAppConfig() (*AppConfig, error) {
+	cfg, err := loadAppConfig()
+	if err != nil {
+		return nil, withKind(ErrInvalidConfig, err)
+	}
+
+	return cfg, nil
+}
+
+func loadAppConfig() (*AppConfig, error) {
 	home, err := os.UserHomeDir()
 	if err != nil {
 		return nil, fmt.Errorf("getting home directory: %w", err)
@@ -35,7 +114,7 @@ func LoadAppConfig() (*AppConfig, error) {
 	data, err := os.ReadFile(configPath) //nolint:gosec // path is from user home dir, intentional
 	if err != nil {
 		if errors.Is(err, os.ErrNotExist) {
-			return nil, fmt.Errorf("app config not found at %s - run 'tidydots init' or create it manually", configPath)
+			return nil, withKind(ErrConfigNotFound, fmt.Errorf("app config not found at %s - run 'tidydots init' or create it manually", configPath))
 		}
 
 		return nil, fmt.Errorf("reading app config: %w", err)
@@ -50,6 +129,10 @@ func LoadAppConfig() (*AppConfig, error) {
 		return nil, fmt.Errorf("config_dir not set in %s", configPath)
 	}
 
+	if cfg.MaxHistoryEntries < 0 {
+		return nil, fmt.Errorf("max_history_entries must not be negative in %s", configPath)
+	}
+
 	// Expand ~ in config_dir
 	cfg.ConfigDir = ExpandPath(cfg.ConfigDir, nil)
 
@@ -81,7 +164,7 @@ func SaveAppConfig(cfg *AppConfig) error {
 	}
 
 	// Add a header comment
-	content := fmt.Sprintf("# tidydots app configuration\n# This file only stores the path to your configurations repository\n\n%s", string(data))
+	content := fmt.Sprintf("# tidydots app configuration\n# This file stores the path to your configurations repository and local preferences\n\n%s", string(data))
 
 	// Use 0600 permissions to restrict access to owner only
 	if err := os.WriteFile(configPath, []byte(content), 0600); err != nil {
@@ -145,6 +145,10 @@ func TestLoadAppConfigNotFound(t *testing.T) {
 	if !strings.Contains(err.Error(), "app config not found") {
 		t.Errorf("Error should mention 'app config not found', got: %v", err)
 	}
+
+	if !errors.Is(err, ErrConfigNotFound) {
+		t.Errorf("LoadAppConfig() error = %v, want ErrConfigNotFound", err)
+	}
 }
 
 func TestLoadAppConfigInvalidYAML(t *testing.T) {
@@ -164,8 +168,8 @@ func TestLoadAppConfigInvalidYAML(t *testing.T) {
 	}
 
 	_, err := LoadAppConfig()
-	if err == nil {
-		t.Error("LoadAppConfig() should error for invalid YAML")
+	if !errors.Is(err, ErrInvalidConfig) {
+		t.Errorf("LoadAppConfig() error = %v, want ErrInvalidConfig for invalid YAML", err)
 	}
 }
 
@@ -250,3 +254,83 @@ func TestAppConfigPath(t *testing.T) {
 		t.Errorf("AppConfigPath() = %q, want %q", path, expected)
 	}
 }
+
+func TestLoadAppConfigMaxHistoryEntries(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	setTestHome(t, tmpDir)
+
+	configDir := filepath.Join(tmpDir, appConfigDir)
+	if err := os.MkdirAll(configDir, 0750); err != nil {
+		t.Fatal(err)
+	}
+
+	configPath := filepath.Join(configDir, appConfigFile)
+
+	tests := []struct {
+		name    string
+		extra   string
+		want    int
+		wantErr bool
+	}{
+		{name: "default", want: DefaultMaxHistoryEntries},
+		{name: "set", extra: "max_history_entries: 50\n", want: 50},
+		{name: "negative", extra: "max_history_entries: -1\n", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			content := "config_dir: " + tmpDir + "\n" + tt.extra
+			if err := os.WriteFile(configPath, []byte(content), 0600); err != nil {
+				t.Fatal(err)
+			}
+
+			cfg, err := LoadAppConfig()
+			if tt.wantErr {
+				if err == nil {
+					t.Error("LoadAppConfig() should error for a negative max_history_entries")
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("LoadAppConfig() error = %v", err)
+			}
+
+			if got := cfg.HistoryLimit(); got != tt.want {
+				t.Errorf("HistoryLimit() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLoadAppConfigKeyBindings(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	setTestHome(t, tmpDir)
+
+	configDir := filepath.Join(tmpDir, appConfigDir)
+	if err := os.MkdirAll(configDir, 0750); err != nil {
+		t.Fatal(err)
+	}
+
+	content := "config_dir: " + tmpDir + "\nkeybindings:\n  list.search: ctrl+f\n  list.edit: [e, enter]\n"
+	if err := os.WriteFile(filepath.Join(configDir, appConfigFile), []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, err := LoadAppConfig()
+	if err != nil {
+		t.Fatalf("LoadAppConfig() error = %v", err)
+	}
+
+	got := cfg.KeyBindingOverrides()
+	if strings.Join(got["list.search"], ",") != "ctrl+f" {
+		t.Errorf("list.search = %v, want [ctrl+f]", got["list.search"])
+	}
+
+	if strings.Join(got["list.edit"], ",") != "e,enter" {
+		t.Errorf("list.edit = %v, want [e enter]", got["list.edit"])
+	}
+}
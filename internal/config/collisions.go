@@ -0,0 +1,117 @@
+package config
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+)
+
+// TargetCollision describes two config entries that deploy to the same
+// location on the current OS. Restoring both gives undefined results: the
+// entry processed last silently wins.
+type TargetCollision struct {
+	Path       string // resolved path claimed by both entries
+	App        string
+	Entry      string
+	OtherApp   string
+	OtherEntry string
+}
+
+func (c TargetCollision) String() string {
+	return fmt.Sprintf("%s/%s and %s/%s both target %s", c.App, c.Entry, c.OtherApp, c.OtherEntry, c.Path)
+}
+
+// Involves reports whether the collision names the given entry on either side.
+func (c TargetCollision) Involves(app, entry string) bool {
+	return (c.App == app && c.Entry == entry) || (c.OtherApp == app && c.OtherEntry == entry)
+}
+
+// targetClaim is a path a config entry deploys to. A folder entry claims its
+// whole target directory; a files entry claims each listed file.
+type targetClaim struct {
+	path   string
+	app    string
+	entry  string
+	folder bool
+}
+
+// overlaps reports whether two claims deploy to the same place: the same
+// path, or a path inside a folder the other entry manages.
+func (c targetClaim) overlaps(other targetClaim) bool {
+	return c.path == other.path ||
+		(c.folder && IsWithin(other.path, c.path)) ||
+		(other.folder && IsWithin(c.path, other.path))
+}
+
+// IsWithin reports whether path lies strictly inside dir. Both are expected
+// to be cleaned; a trailing separator on dir, as on a root, is allowed.
+func IsWithin(path, dir string) bool {
+	return strings.HasPrefix(path, strings.TrimSuffix(dir, string(filepath.Separator))+string(filepath.Separator))
+}
+
+// FindTargetCollisions returns every pair of config entries in apps whose
+// resolved targets for osType overlap. Targets are resolved the same way as
+// for file operations, so `~/.config/nvim` and `$HOME/.config/nvim` collide.
+// Each pair of entries is reported once, in application order.
+func FindTargetCollisions(apps []Application, osType string, envVars map[string]string, renderer PathRenderer) []TargetCollision {
+	var claims []targetClaim
+
+	for _, app := range apps {
+		for _, entry := range app.Entries {
+			if !entry.IsConfig() {
+				continue
+			}
+
+			target := entry.GetTarget(osType)
+			if target == "" {
+				continue
+			}
+
+			base := filepath.Clean(ExpandPathWithTemplate(target, envVars, renderer))
+
+			if entry.IsFolder() {
+				claims = append(claims, targetClaim{path: base, app: app.Name, entry: entry.Name, folder: true})
+				continue
+			}
+
+			for _, file := range entry.Files {
+				claims = append(claims, targetClaim{path: filepath.Join(base, file), app: app.Name, entry: entry.Name})
+			}
+		}
+	}
+
+	var collisions []TargetCollision
+
+	reported := make(map[[4]string]bool)
+
+	for i, a := range claims {
+		for _, b := range claims[i+1:] {
+			if (a.app == b.app && a.entry == b.entry) || !a.overlaps(b) {
+				continue
+			}
+
+			key := [4]string{a.app, a.entry, b.app, b.entry}
+			if reported[key] {
+				continue
+			}
+
+			reported[key] = true
+
+			// Report the enclosing path when one claim lies inside the other.
+			path := a.path
+			if len(b.path) < len(path) {
+				path = b.path
+			}
+
+			collisions = append(collisions, TargetCollision{
+				Path:       path,
+				App:        a.app,
+				Entry:      a.entry,
+				OtherApp:   b.app,
+				OtherEntry: b.entry,
+			})
+		}
+	}
+
+	return collisions
+}
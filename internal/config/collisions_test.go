@@ -0,0 +1,127 @@
+package config
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestFindTargetCollisions(t *testing.T) {
+	t.Parallel()
+
+	home := t.TempDir()
+	envVars := map[string]string{"XDG": filepath.Join(home, ".config")}
+
+	folder := func(name, target string) SubEntry {
+		return SubEntry{Name: name, Backup: "./" + name, Targets: map[string]string{"linux": target}}
+	}
+	files := func(name, target string, f ...string) SubEntry {
+		e := folder(name, target)
+		e.Files = f
+		return e
+	}
+
+	tests := []struct {
+		name string
+		apps []Application
+		want []TargetCollision
+	}{
+		{
+			name: "same folder through different spellings",
+			apps: []Application{
+				{Name: "nvim", Entries: []SubEntry{folder("config", filepath.Join(home, ".config", "nvim"))}},
+				{Name: "neovim", Entries: []SubEntry{folder("cfg", "$XDG/nvim/")}},
+			},
+			want: []TargetCollision{{
+				Path: filepath.Join(home, ".config", "nvim"),
+				App:  "nvim", Entry: "config", OtherApp: "neovim", OtherEntry: "cfg",
+			}},
+		},
+		{
+			name: "different files in the same directory do not collide",
+			apps: []Application{
+				{Name: "bash", Entries: []SubEntry{files("rc", home, ".bashrc")}},
+				{Name: "profile", Entries: []SubEntry{files("rc", home, ".profile")}},
+			},
+		},
+		{
+			name: "file inside a managed folder collides once per entry pair",
+			apps: []Application{
+				{Name: "nvim", Entries: []SubEntry{folder("config", filepath.Join(home, "nvim"))}},
+				{Name: "extra", Entries: []SubEntry{files("init", filepath.Join(home, "nvim"), "init.lua", "lazy.lua")}},
+			},
+			want: []TargetCollision{{
+				Path: filepath.Join(home, "nvim"),
+				App:  "nvim", Entry: "config", OtherApp: "extra", OtherEntry: "init",
+			}},
+		},
+		{
+			name: "other OS targets and setup entries are ignored",
+			apps: []Application{
+				{Name: "a", Entries: []SubEntry{{Name: "w", Backup: "./w", Targets: map[string]string{"windows": home}}}},
+				{Name: "b", Entries: []SubEntry{{Name: "w", Backup: "./w", Targets: map[string]string{"windows": home}}}},
+				{Name: "c", Entries: []SubEntry{{Name: "s", Run: map[string]string{"linux": "true"}, Check: map[string]string{"linux": "true"}}}},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			got := FindTargetCollisions(tt.apps, "linux", envVars, nil)
+			if len(got) != len(tt.want) {
+				t.Fatalf("FindTargetCollisions() = %v, want %v", got, tt.want)
+			}
+
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Errorf("collision[%d] = %+v, want %+v", i, got[i], tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestTargetCollision_Involves(t *testing.T) {
+	t.Parallel()
+
+	c := TargetCollision{App: "a", Entry: "x", OtherApp: "b", OtherEntry: "y"}
+
+	if !c.Involves("a", "x") || !c.Involves("b", "y") {
+		t.Error("Involves() = false for an entry named in the collision")
+	}
+
+	if c.Involves("a", "y") {
+		t.Error("Involves() = true for an entry not named in the collision")
+	}
+}
+
+func TestIsWithin(t *testing.T) {
+	t.Parallel()
+
+	root := string(filepath.Separator)
+	dir := filepath.Join(root, "home", "me")
+
+	tests := []struct {
+		name      string
+		path, dir string
+		want      bool
+	}{
+		{name: "file inside", path: filepath.Join(dir, "a"), dir: dir, want: true},
+		{name: "nested inside", path: filepath.Join(dir, "a", "b"), dir: dir, want: true},
+		{name: "dir itself", path: dir, dir: dir, want: false},
+		{name: "sibling sharing a prefix", path: dir + "2", dir: dir, want: false},
+		{name: "trailing separator on dir", path: filepath.Join(dir, "a"), dir: dir + string(filepath.Separator), want: true},
+		{name: "inside the root", path: dir, dir: root, want: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			if got := IsWithin(tt.path, tt.dir); got != tt.want {
+				t.Errorf("IsWithin(%q, %q) = %v, want %v", tt.path, tt.dir, got, tt.want)
+			}
+		})
+	}
+}
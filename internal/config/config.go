@@ -7,6 +7,7 @@ import (
 	"log/slog"
 	"os"
 	"path/filepath"
+	"regexp"
 	"strings"
 
 	"gopkg.in/yaml.v3"
@@ -14,24 +15,96 @@ import (
 
 // Config is the main configuration structure
 type Config struct {
-	Version         int           `yaml:"version"`
-	BackupRoot      string        `yaml:"-"`
-	DefaultManager  string        `yaml:"default_manager,omitempty"`
-	ManagerPriority []string      `yaml:"manager_priority,omitempty"`
-	Applications    []Application `yaml:"applications,omitempty"`
+	Version         int               `yaml:"version"`
+	BackupRoot      string            `yaml:"-"`
+	Include         []string          `yaml:"include,omitempty"`         // globs relative to the repo, e.g. apps/*.yaml
+	DefaultInclude  string            `yaml:"default_include,omitempty"` // file that receives newly added applications
+	PackagesFile    string            `yaml:"packages_file,omitempty"`   // file with more packages, e.g. one shared by several repos
+	DefaultManager  string            `yaml:"default_manager,omitempty"`
+	ManagerPriority []string          `yaml:"manager_priority,omitempty"`
+	DirtyCheck      *bool             `yaml:"dirty_check,omitempty"` // nil means enabled; see DirtyCheckEnabled
+	Notifications   *Notifications    `yaml:"notifications,omitempty"`
+	Dedupe          *Dedupe           `yaml:"dedupe,omitempty"`         // large-file handling of entries with dedupe: true
+	SymlinkCompat   string            `yaml:"symlink_compat,omitempty"` // how restore links folders on Windows: symlink (default) or junction
+	Defaults        *Defaults         `yaml:"defaults,omitempty"`       // sub-entry fields entries inherit; see Defaults
+	Vars            map[string]string `yaml:"vars,omitempty"`           // user values templates see as .Vars
+	WhenPresets     map[string]string `yaml:"when_presets,omitempty"`   // when expressions applications share by name; see PresetWhens
+	Applications    []Application     `yaml:"applications,omitempty"`
+
+	// DefaultMaxFileSize is the max_file_size of entries that set none, in
+	// bytes. Zero backs up files of any size.
+	DefaultMaxFileSize int64 `yaml:"default_max_file_size,omitempty"`
+
+	// includedFiles are the absolute paths of the files pulled in via Include,
+	// in load order. Save writes each of them back.
+	includedFiles []string
+	// packagesFile is the absolute path of the file named by PackagesFile,
+	// whose packages Save writes back to it.
+	packagesFile string
+	// settingsSource is the included file that declared DefaultManager and
+	// ManagerPriority, or empty when they live in the main file.
+	settingsSource string
 }
 
-// URLInstallSpec defines URL-based installation
+// Values of Config.SymlinkCompat.
+const (
+	SymlinkCompatSymlink  = "symlink"
+	SymlinkCompatJunction = "junction"
+)
+
+// DirtyCheckEnabled reports whether linked entries should be checked for
+// uncommitted git changes in the backup repo. The check is on unless
+// `dirty_check: false` is set.
+func (c *Config) DirtyCheckEnabled() bool {
+	return c.DirtyCheck == nil || *c.DirtyCheck
+}
+
+// MaxFileSize returns the size in bytes above which backup skips a file of
+// entry: its own max_file_size, or DefaultMaxFileSize. Zero means no limit.
+// Sudo entries have none, as their files are copied without being read.
+func (c *Config) MaxFileSize(entry SubEntry) int64 {
+	switch {
+	case entry.Sudo:
+		return 0
+	case entry.MaxFileSize > 0:
+		return entry.MaxFileSize
+	default:
+		return c.DefaultMaxFileSize
+	}
+}
+
+// URLInstallSpec defines URL-based installation. When SHA256 or Size is set,
+// the downloaded file is verified before Command runs.
 type URLInstallSpec struct {
 	URL     string `yaml:"url"`
-	Command string `yaml:"command"` // Use {file} as placeholder for downloaded file
+	Command string `yaml:"command"`          // Use {file} as placeholder for downloaded file
+	SHA256  string `yaml:"sha256,omitempty"` // Expected hex-encoded SHA-256 of the download
+	Size    int64  `yaml:"size,omitempty"`   // Expected size of the download in bytes
 }
 
 // Load reads and parses the configuration file from the given path.
 // It supports both v2 and v3 configuration formats, returning an error
 // if the version is unsupported or if the file cannot be read or parsed.
+// Files listed under `include` are merged in, and so are the packages of
+// `packages_file`; see loadIncludes and loadPackagesFile.
+//
+// A missing file is reported with an error matching ErrConfigNotFound, any
+// other failure with one matching ErrInvalidConfig.
 func Load(path string) (*Config, error) {
+	cfg, err := load(path)
+	if err != nil {
+		return nil, withKind(ErrInvalidConfig, err)
+	}
+
+	return cfg, nil
+}
+
+func load(path string) (*Config, error) {
 	data, err := os.ReadFile(path) //nolint:gosec // path is from user config, intentional
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, withKind(ErrConfigNotFound, fmt.Errorf("reading config file: %w", err))
+	}
+
 	if err != nil {
 		return nil, fmt.Errorf("reading config file: %w", err)
 	}
@@ -49,6 +122,16 @@ func Load(path string) (*Config, error) {
 		return nil, fmt.Errorf("unsupported config version %d (expected 3)", cfg.Version)
 	}
 
+	if err := loadIncludes(&cfg, path); err != nil {
+		return nil, err
+	}
+
+	if err := loadPackagesFile(&cfg, path); err != nil {
+		return nil, err
+	}
+
+	cfg.applyDefaults()
+
 	if validationErrs := ValidateConfig(&cfg); len(validationErrs) > 0 {
 		return nil, fmt.Errorf("validating config: %w", errors.Join(validationErrs...))
 	}
@@ -74,7 +157,8 @@ func (c *Config) ExpandPaths(envVars map[string]string) {
 	}
 }
 
-// GetFilteredApplications returns applications filtered by when expressions.
+// GetFilteredApplications returns the enabled applications whose when
+// expression matches, each holding only its enabled sub-entries.
 // Silent on evaluation errors — prefer GetFilteredApplicationsWithLogger.
 func (c *Config) GetFilteredApplications(renderer PathRenderer) []Application {
 	return c.GetFilteredApplicationsWithLogger(renderer, nil)
@@ -85,8 +169,25 @@ func (c *Config) GetFilteredApplications(renderer PathRenderer) []Application {
 func (c *Config) GetFilteredApplicationsWithLogger(renderer PathRenderer, logger *slog.Logger) []Application {
 	result := make([]Application, 0, len(c.Applications))
 
+	for _, app := range c.GetMatchingApplicationsWithLogger(renderer, logger) {
+		if app.IsEnabled() {
+			app.Entries = app.EnabledEntries()
+			result = append(result, app)
+		}
+	}
+
+	return result
+}
+
+// GetMatchingApplicationsWithLogger returns applications filtered by when
+// expressions only (see MatchesWhen), disabled applications and sub-entries included. It is for
+// views that show disabled items; anything that acts on the config should use
+// GetFilteredApplicationsWithLogger.
+func (c *Config) GetMatchingApplicationsWithLogger(renderer PathRenderer, logger *slog.Logger) []Application {
+	result := make([]Application, 0, len(c.Applications))
+
 	for _, app := range c.Applications {
-		if EvaluateWhenWithLogger(app.When, renderer, logger) {
+		if c.MatchesWhen(&app, renderer, logger) {
 			result = append(result, app)
 		}
 	}
@@ -94,7 +195,8 @@ func (c *Config) GetFilteredApplicationsWithLogger(renderer PathRenderer, logger
 	return result
 }
 
-// GetAllSubEntries returns all sub-entries from all applications filtered by when expressions
+// GetAllSubEntries returns the enabled sub-entries of the applications
+// GetFilteredApplications returns
 func (c *Config) GetAllSubEntries(renderer PathRenderer) []SubEntry {
 	apps := c.GetFilteredApplications(renderer)
 
@@ -137,7 +239,8 @@ func (c *Config) GetAllConfigSubEntries(renderer PathRenderer) []SubEntry {
 	return result
 }
 
-// GetFilteredPackages returns applications with packages, filtered by when expressions
+// GetFilteredPackages returns the applications with packages that
+// GetFilteredApplications returns
 func (c *Config) GetFilteredPackages(renderer PathRenderer) []Application {
 	apps := c.GetFilteredApplications(renderer)
 	result := make([]Application, 0, len(apps))
@@ -176,16 +279,57 @@ func ExpandPathWithTemplate(path string, envVars map[string]string, renderer Pat
 	return ExpandPath(rendered, envVars)
 }
 
+// HomeVar is the envVars key holding the home directory of a platform whose
+// OS was overridden to one tidydots is not running on. It mirrors
+// platform.EnvHome.
+const HomeVar = "TIDYDOTS_HOME"
+
+// percentVar matches a Windows-style %NAME% variable reference.
+var percentVar = regexp.MustCompile(`%([A-Za-z_][A-Za-z0-9_]*)%`)
+
+// RenderPathTemplate renders the template actions of path with renderer and
+// returns the result, or path unchanged when it has none. Unlike
+// ExpandPathWithTemplate, which falls back to the unrendered path, it returns
+// the error rendering fails with. A path that uses a value the context lacks,
+// such as an undefined .Vars key, renders as "<no value>" and is an error too.
+func RenderPathTemplate(path string, renderer PathRenderer) (string, error) {
+	if renderer == nil || !strings.Contains(path, "{{") {
+		return path, nil
+	}
+
+	rendered, err := renderer.RenderString("path", path)
+	if err != nil {
+		return "", err
+	}
+
+	if strings.Contains(rendered, "<no value>") {
+		return "", errors.New("a template value it uses is not defined")
+	}
+
+	return rendered, nil
+}
+
 // ExpandPath expands ~ and environment variables in a single path.
 // This should be used when a path is needed for file operations.
 // The path is kept unexpanded in the config to maintain portability.
+// Variables may be written $NAME, ${NAME} or %NAME%.
+//
+// When envVars holds HomeVar, they describe another OS, and the path is
+// expanded for that OS alone: ~ is that home, only envVars are consulted,
+// unknown variables are left as written, and separators become forward
+// slashes.
 func ExpandPath(path string, envVars map[string]string) string {
 	if path == "" {
 		return path
 	}
 
-	// Expand ~ to home directory
-	if strings.HasPrefix(path, "~/") {
+	if home, ok := envVars[HomeVar]; ok {
+		return expandCrossOSPath(path, home, envVars)
+	}
+
+	// Expand ~ to home directory. The OS separator is accepted as well so that
+	// `~\dotfiles` works on Windows.
+	if strings.HasPrefix(path, "~/") || strings.HasPrefix(path, "~"+string(filepath.Separator)) {
 		home, err := os.UserHomeDir()
 		if err == nil {
 			path = filepath.Join(home, path[2:])
@@ -202,25 +346,178 @@ func ExpandPath(path string, envVars map[string]string) string {
 		path = strings.ReplaceAll(path, "$"+key, value)
 	}
 
+	path = expandPercentVars(path, func(key string) (string, bool) {
+		if value, ok := envVars[key]; ok {
+			return value, true
+		}
+
+		return os.LookupEnv(key)
+	})
+
 	// Also expand standard environment variables
 	path = os.ExpandEnv(path)
 
 	return path
 }
 
-// Save writes the config to the specified file path
+// expandCrossOSPath expands path for the OS whose home and environment are
+// home and envVars; see ExpandPath.
+func expandCrossOSPath(path, home string, envVars map[string]string) string {
+	path = strings.ReplaceAll(path, `\`, "/")
+
+	if path == "~" || strings.HasPrefix(path, "~/") {
+		path = home + path[1:]
+	}
+
+	path = expandPercentVars(path, func(key string) (string, bool) {
+		value, ok := envVars[key]
+		return value, ok
+	})
+
+	path = os.Expand(path, func(key string) string {
+		if value, ok := envVars[key]; ok {
+			return value
+		}
+
+		return "${" + key + "}"
+	})
+
+	return strings.ReplaceAll(path, `\`, "/")
+}
+
+// expandPercentVars replaces every %NAME% reference that lookup knows, in any
+// case, as Windows does, and leaves the others as written.
+func expandPercentVars(path string, lookup func(string) (string, bool)) string {
+	if !strings.Contains(path, "%") {
+		return path
+	}
+
+	return percentVar.ReplaceAllStringFunc(path, func(ref string) string {
+		name := ref[1 : len(ref)-1]
+
+		if value, ok := lookup(name); ok {
+			return value
+		}
+
+		if value, ok := lookup(strings.ToUpper(name)); ok {
+			return value
+		}
+
+		return ref
+	})
+}
+
+// ResolveBackupPath expands a backup path and anchors it for file operations.
+// Absolute paths, including `~`-prefixed ones that expand to the home
+// directory, are used as-is; anything else is relative to backupRoot, which is
+// expanded the same way.
+func ResolveBackupPath(backup, backupRoot string, envVars map[string]string, renderer PathRenderer) string {
+	expanded := ExpandPathWithTemplate(backup, envVars, renderer)
+	if filepath.IsAbs(expanded) {
+		return expanded
+	}
+
+	return filepath.Join(ExpandPathWithTemplate(backupRoot, envVars, renderer), expanded)
+}
+
+// Save writes the config to the specified file path. Applications that were
+// loaded from an included file are written back to that file; new ones go to
+// DefaultInclude when set, otherwise to path. Packages loaded from the packages
+// file are written back to it.
 func Save(cfg *Config, path string) error {
-	data, err := marshalYAML(cfg)
+	return save(cfg, path, os.WriteFile)
+}
+
+// SaveAtomic is like Save, but writes each file to a temporary file in the
+// same directory and renames it into place, so an interrupted write never
+// leaves a truncated config behind. Files are replaced one at a time.
+func SaveAtomic(cfg *Config, path string) error {
+	return save(cfg, path, writeFileAtomic)
+}
+
+// save implements Save and SaveAtomic, writing each file with writeFile.
+func save(cfg *Config, path string, writeFile func(string, []byte, os.FileMode) error) error {
+	// Refuse to write a config that Load would reject.
+	if errs := duplicateNameErrors(cfg.Applications); len(errs) > 0 {
+		return errors.Join(errs...)
+	}
+
+	defs, err := packageDefs(cfg)
+	if err != nil {
+		return err
+	}
+
+	mainApps, byFile := splitBySource(cfg, path)
+
+	mainCfg := *cfg
+	mainCfg.Applications = mainApps
+
+	if cfg.settingsSource != "" {
+		mainCfg.DefaultManager = ""
+		mainCfg.ManagerPriority = nil
+	}
+
+	data, err := marshalYAML(&mainCfg)
 	if err != nil {
 		return fmt.Errorf("marshaling config: %w", err)
 	}
 
 	// Use 0600 permissions to restrict access to owner only,
 	// as config may contain sensitive path information
-	if err := os.WriteFile(path, data, 0600); err != nil {
+	if err := writeFile(path, data, 0600); err != nil {
 		return fmt.Errorf("writing config file: %w", err)
 	}
 
+	if err := writeIncludes(cfg, byFile, writeFile); err != nil {
+		return err
+	}
+
+	return writePackagesFile(cfg, defs, writeFile)
+}
+
+// writeFileAtomic writes data to a temporary file next to path and renames it
+// over path. A symlinked path is resolved first so the link itself survives.
+func writeFileAtomic(path string, data []byte, perm os.FileMode) error {
+	if resolved, err := filepath.EvalSymlinks(path); err == nil {
+		path = resolved
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".tmp-*")
+	if err != nil {
+		return err
+	}
+
+	tmpPath := tmp.Name()
+
+	if _, err := tmp.Write(data); err != nil {
+		_ = tmp.Close()
+		_ = os.Remove(tmpPath)
+
+		return err
+	}
+
+	if err := tmp.Sync(); err != nil {
+		_ = tmp.Close()
+		_ = os.Remove(tmpPath)
+
+		return err
+	}
+
+	if err := tmp.Close(); err != nil {
+		_ = os.Remove(tmpPath)
+		return err
+	}
+
+	if err := os.Chmod(tmpPath, perm); err != nil {
+		_ = os.Remove(tmpPath)
+		return err
+	}
+
+	if err := os.Rename(tmpPath, path); err != nil {
+		_ = os.Remove(tmpPath)
+		return err
+	}
+
 	return nil
 }
 
@@ -7,6 +7,7 @@ import (
 	"log/slog"
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
 	"testing"
 
@@ -112,8 +113,12 @@ func TestLoadNonExistent(t *testing.T) {
 	t.Parallel()
 
 	_, err := Load("/nonexistent/config.yaml")
-	if err == nil {
-		t.Error("Load() expected error for non-existent file")
+	if !errors.Is(err, ErrConfigNotFound) || errors.Is(err, ErrInvalidConfig) {
+		t.Errorf("Load() error = %v, want ErrConfigNotFound only", err)
+	}
+
+	if !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("Load() error = %v, want it to keep wrapping os.ErrNotExist", err)
 	}
 }
 
@@ -127,8 +132,12 @@ func TestLoadInvalidYAML(t *testing.T) {
 	}
 
 	_, err := Load(configPath)
-	if err == nil {
-		t.Error("Load() expected error for invalid YAML")
+	if !errors.Is(err, ErrInvalidConfig) {
+		t.Errorf("Load() error = %v, want ErrInvalidConfig for invalid YAML", err)
+	}
+
+	if !strings.HasPrefix(err.Error(), "parsing config file: ") {
+		t.Errorf("Load() error = %q, want the parse error's message", err)
 	}
 }
 
@@ -146,8 +155,8 @@ applications: []
 	}
 
 	_, err := Load(configPath)
-	if err == nil {
-		t.Error("Load() expected error for unsupported version 2")
+	if !errors.Is(err, ErrInvalidConfig) {
+		t.Errorf("Load() error = %v, want ErrInvalidConfig for unsupported version 2", err)
 	}
 }
 
@@ -317,6 +326,7 @@ applications:
     description: "Editor"
     when: '{{ eq .OS "linux" }}'
     package:
+      phase: 1
       managers:
         pacman: neovim
         apt: neovim
@@ -357,6 +367,47 @@ applications:
 	if cfg.Applications[0].Package.Managers["pacman"].PackageName != "neovim" {
 		t.Errorf("Package.Managers[pacman] = %q, want %q", cfg.Applications[0].Package.Managers["pacman"].PackageName, "neovim")
 	}
+
+	if cfg.Applications[0].Package.Phase != 1 {
+		t.Errorf("Package.Phase = %d, want 1", cfg.Applications[0].Package.Phase)
+	}
+}
+
+func TestLoadPackageAfter(t *testing.T) {
+	t.Parallel()
+	tmpDir := t.TempDir()
+	configPath := filepath.Join(tmpDir, "config.yaml")
+
+	configContent := `
+version: 3
+applications:
+  - name: neovim
+    package:
+      managers:
+        pacman: neovim
+  - name: neovim-plugins
+    package:
+      phase: 1
+      after: [neovim]
+      custom:
+        linux: nvim --headless "+Lazy! sync" +qa
+`
+	if err := os.WriteFile(configPath, []byte(configContent), 0600); err != nil {
+		t.Fatalf("Failed to write test config: %v", err)
+	}
+
+	cfg, err := Load(configPath)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+
+	if len(cfg.Applications) != 2 || cfg.Applications[1].Package == nil {
+		t.Fatalf("Applications = %+v, want neovim-plugins with a package", cfg.Applications)
+	}
+
+	if after := cfg.Applications[1].Package.After; len(after) != 1 || after[0] != "neovim" {
+		t.Errorf("Package.After = %q, want [neovim]", after)
+	}
 }
 
 func TestExpandPathOnlyTilde(t *testing.T) {
@@ -378,6 +429,59 @@ func TestExpandPathEmpty(t *testing.T) {
 	}
 }
 
+func TestExpandPath_CrossOS(t *testing.T) {
+	t.Parallel()
+
+	windows := map[string]string{
+		HomeVar:       "C:/Users/alice",
+		"USERPROFILE": "C:/Users/alice",
+		"APPDATA":     "C:/Users/alice/AppData/Roaming",
+	}
+	linux := map[string]string{
+		HomeVar:           "/home/alice",
+		"HOME":            "/home/alice",
+		"XDG_CONFIG_HOME": "/home/alice/.config",
+	}
+
+	tests := []struct {
+		envVars map[string]string
+		path    string
+		want    string
+	}{
+		{windows, "~", "C:/Users/alice"},
+		{windows, "~/.gitconfig", "C:/Users/alice/.gitconfig"},
+		{windows, `~\AppData\Local/nvim`, "C:/Users/alice/AppData/Local/nvim"},
+		{windows, `%APPDATA%\Code\User`, "C:/Users/alice/AppData/Roaming/Code/User"},
+		{windows, "%AppData%/Code", "C:/Users/alice/AppData/Roaming/Code"},
+		{windows, "$USERPROFILE/.ssh", "C:/Users/alice/.ssh"},
+		{windows, "%PROGRAMDATA%/tool", "%PROGRAMDATA%/tool"},
+		{linux, "~/.config/nvim", "/home/alice/.config/nvim"},
+		{linux, "$XDG_CONFIG_HOME/nvim", "/home/alice/.config/nvim"},
+		{linux, "${HOME}/bin", "/home/alice/bin"},
+		{linux, "$UNSET/x", "${UNSET}/x"},
+	}
+
+	for _, tt := range tests {
+		if got := ExpandPath(tt.path, tt.envVars); got != tt.want {
+			t.Errorf("ExpandPath(%q) = %q, want %q", tt.path, got, tt.want)
+		}
+	}
+}
+
+func TestExpandPath_PercentVars(t *testing.T) {
+	t.Parallel()
+
+	envVars := map[string]string{"PWSH_PROFILE_PATH": "/profiles"}
+
+	if got := ExpandPath("%PWSH_PROFILE_PATH%/x", envVars); got != "/profiles/x" {
+		t.Errorf("ExpandPath(%%PWSH_PROFILE_PATH%%/x) = %q, want /profiles/x", got)
+	}
+
+	if got := ExpandPath("100%/%TIDYDOTS_NOT_SET%", envVars); got != "100%/%TIDYDOTS_NOT_SET%" {
+		t.Errorf("ExpandPath() = %q, want unknown %%VAR%% references kept", got)
+	}
+}
+
 func TestLoadWithURLInstall(t *testing.T) {
 	t.Parallel()
 	tmpDir := t.TempDir()
@@ -671,6 +775,114 @@ func TestGetFilteredApplications(t *testing.T) {
 	_ = linuxRenderer // suppress unused warning
 }
 
+func TestGetFilteredApplications_Enabled(t *testing.T) {
+	t.Parallel()
+
+	disabled := false
+	renderer := &mockRenderer{values: map[string]string{"{{ match }}": "true", "{{ nomatch }}": "false"}}
+
+	entry := func(name string, enabled *bool) SubEntry {
+		return SubEntry{Name: name, Backup: "./" + name, Targets: map[string]string{"linux": "~/." + name}, Enabled: enabled}
+	}
+
+	cfg := &Config{
+		Version: 3,
+		Applications: []Application{
+			{Name: "plain", Entries: []SubEntry{entry("a", nil)}},
+			{Name: "matched", When: "{{ match }}", Entries: []SubEntry{entry("a", nil), entry("b", &disabled)}},
+			{Name: "matched-disabled", When: "{{ match }}", Enabled: &disabled, Entries: []SubEntry{entry("a", nil)}},
+			{Name: "unmatched", When: "{{ nomatch }}", Entries: []SubEntry{entry("a", nil)}},
+			{Name: "unmatched-disabled", When: "{{ nomatch }}", Enabled: &disabled, Entries: []SubEntry{entry("a", nil)}},
+			{
+				Name:    "game",
+				Enabled: &disabled,
+				Package: &EntryPackage{Managers: map[string]ManagerValue{"pacman": {PackageName: "steam"}}},
+			},
+		},
+	}
+
+	names := func(apps []Application) []string {
+		var out []string
+		for _, app := range apps {
+			var entries []string
+			for _, e := range app.Entries {
+				entries = append(entries, e.Name)
+			}
+			out = append(out, app.Name+"["+strings.Join(entries, ",")+"]")
+		}
+		return out
+	}
+
+	t.Run("both when and enabled must pass", func(t *testing.T) {
+		t.Parallel()
+
+		got := names(cfg.GetFilteredApplications(renderer))
+		want := []string{"plain[a]", "matched[a]"}
+
+		if !slices.Equal(got, want) {
+			t.Errorf("GetFilteredApplications() = %v, want %v", got, want)
+		}
+	})
+
+	t.Run("matching keeps disabled items", func(t *testing.T) {
+		t.Parallel()
+
+		got := names(cfg.GetMatchingApplicationsWithLogger(renderer, nil))
+		want := []string{"plain[a]", "matched[a,b]", "matched-disabled[a]", "game[]"}
+
+		if !slices.Equal(got, want) {
+			t.Errorf("GetMatchingApplicationsWithLogger() = %v, want %v", got, want)
+		}
+	})
+
+	t.Run("disabled packages are not installed", func(t *testing.T) {
+		t.Parallel()
+
+		if got := cfg.GetFilteredPackages(renderer); len(got) != 0 {
+			t.Errorf("GetFilteredPackages() = %v, want none", names(got))
+		}
+	})
+
+	t.Run("config is not modified", func(t *testing.T) {
+		t.Parallel()
+
+		if n := len(cfg.Applications[1].Entries); n != 2 {
+			t.Errorf("matched has %d entries after filtering, want 2", n)
+		}
+	})
+}
+
+func TestSetEnabled(t *testing.T) {
+	t.Parallel()
+
+	var app Application
+	if !app.IsEnabled() {
+		t.Fatal("an application without enabled should be enabled")
+	}
+
+	app.SetEnabled(false)
+	out, err := yaml.Marshal(&app)
+	if err != nil {
+		t.Fatalf("Marshal error: %v", err)
+	}
+	if !strings.Contains(string(out), "enabled: false") {
+		t.Errorf("disabled application should marshal enabled: false, got:\n%s", out)
+	}
+
+	app.SetEnabled(true)
+	if app.Enabled != nil {
+		t.Errorf("SetEnabled(true) should clear the flag, got %v", *app.Enabled)
+	}
+
+	var sub SubEntry
+	if err := yaml.Unmarshal([]byte("name: x\nenabled: false\n"), &sub); err != nil {
+		t.Fatalf("Unmarshal error: %v", err)
+	}
+	if sub.IsEnabled() {
+		t.Error("sub-entry with enabled: false should be disabled")
+	}
+}
+
 // testGetFilteredApps is a test helper that filters apps using a per-app match map
 func testGetFilteredApps(t *testing.T, cfg *Config, matches map[string]bool) []Application {
 	t.Helper()
@@ -984,6 +1196,55 @@ func TestExpandPathWithTemplate_NilRenderer(t *testing.T) {
 	}
 }
 
+// failingRenderer implements PathRenderer with a renderer that always fails.
+type failingRenderer struct{}
+
+func (failingRenderer) RenderString(_, _ string) (string, error) {
+	return "", errors.New("template: path:1: unexpected EOF")
+}
+
+func TestRenderPathTemplate(t *testing.T) {
+	t.Parallel()
+
+	renderer := &mockRenderer{values: map[string]string{
+		"{{ .Vars.app }}":     "nvim",
+		"{{ .Vars.missing }}": "<no value>",
+	}}
+
+	tests := []struct {
+		name     string
+		path     string
+		renderer PathRenderer
+		want     string
+		wantErr  string
+	}{
+		{name: "no template", path: "~/.config/nvim", renderer: renderer, want: "~/.config/nvim"},
+		{name: "nil renderer", path: "~/{{ .Vars.app }}", want: "~/{{ .Vars.app }}"},
+		{name: "var", path: "~/.config/{{ .Vars.app }}", renderer: renderer, want: "~/.config/nvim"},
+		{name: "undefined var", path: "~/{{ .Vars.missing }}", renderer: renderer, wantErr: "not defined"},
+		{name: "render error", path: "~/{{ .Vars.app", renderer: failingRenderer{}, wantErr: "unexpected EOF"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := RenderPathTemplate(tt.path, tt.renderer)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("RenderPathTemplate() error = %v, want %q", err, tt.wantErr)
+				}
+
+				return
+			}
+
+			if err != nil || got != tt.want {
+				t.Errorf("RenderPathTemplate() = %q, %v, want %q", got, err, tt.want)
+			}
+		})
+	}
+}
+
 func TestLoadWithInstallerPackage(t *testing.T) {
 	t.Parallel()
 	tmpDir := t.TempDir()
@@ -1003,6 +1264,12 @@ applications:
             linux: "curl -fsSL https://example.com/install.sh | sh"
             windows: "iwr https://example.com/install.ps1 | iex"
           binary: "mytool"
+          verify:
+            linux: "mytool --version"
+      custom:
+        linux: "make install"
+      verify:
+        linux: "make check"
 `
 	if err := os.WriteFile(configPath, []byte(configContent), 0600); err != nil {
 		t.Fatalf("Failed to write test config: %v", err)
@@ -1045,6 +1312,14 @@ applications:
 		t.Errorf("Installer.Binary = %q, want %q", installerPkg.Binary, "mytool")
 	}
 
+	if installerPkg.Verify["linux"] != "mytool --version" {
+		t.Errorf("Installer.Verify[linux] = %q", installerPkg.Verify["linux"])
+	}
+
+	if got := cfg.Applications[0].Package.Verify["linux"]; got != "make check" {
+		t.Errorf("Package.Verify[linux] = %q, want %q", got, "make check")
+	}
+
 	// GetManagerString should return false for installer
 	_, ok = cfg.Applications[0].Package.GetManagerString("installer")
 	if ok {
@@ -1320,6 +1595,214 @@ func TestManagerValueMarshalYAML(t *testing.T) {
 		}
 	})
 
+	t.Run("emerge USE override marshals as object and round-trips", func(t *testing.T) {
+		t.Parallel()
+		ep := EntryPackage{
+			Managers: map[string]ManagerValue{
+				"emerge": {PackageName: "app-editors/neovim", Emerge: &EmergeOptions{UseFlagsOverride: "lua -X"}},
+			},
+		}
+
+		out, err := yaml.Marshal(&ep)
+		if err != nil {
+			t.Fatalf("Marshal error: %v", err)
+		}
+
+		if !strings.Contains(string(out), "use: lua -X") {
+			t.Errorf("Object form should contain 'use: lua -X', got:\n%s", out)
+		}
+
+		var ep2 EntryPackage
+		if err := yaml.Unmarshal(out, &ep2); err != nil {
+			t.Fatalf("Unmarshal error: %v", err)
+		}
+
+		val := ep2.Managers["emerge"]
+		if val.PackageName != "app-editors/neovim" {
+			t.Errorf("Round-trip PackageName = %q, want %q", val.PackageName, "app-editors/neovim")
+		}
+		if val.Emerge == nil || val.Emerge.UseFlagsOverride != "lua -X" {
+			t.Errorf("Round-trip Emerge = %+v, want USE override %q", val.Emerge, "lua -X")
+		}
+	})
+
+	t.Run("apt repo marshals as object and round-trips", func(t *testing.T) {
+		t.Parallel()
+		ep := EntryPackage{
+			Managers: map[string]ManagerValue{
+				"apt": {PackageName: "neovim", Apt: &AptOptions{Repo: "ppa:neovim-ppa/stable"}},
+			},
+		}
+
+		out, err := yaml.Marshal(&ep)
+		if err != nil {
+			t.Fatalf("Marshal error: %v", err)
+		}
+
+		if !strings.Contains(string(out), "repo: ppa:neovim-ppa/stable") {
+			t.Errorf("Object form should contain 'repo: ppa:neovim-ppa/stable', got:\n%s", out)
+		}
+
+		var ep2 EntryPackage
+		if err := yaml.Unmarshal(out, &ep2); err != nil {
+			t.Fatalf("Unmarshal error: %v", err)
+		}
+
+		val := ep2.Managers["apt"]
+		if val.PackageName != "neovim" {
+			t.Errorf("Round-trip PackageName = %q, want %q", val.PackageName, "neovim")
+		}
+		if val.Apt == nil || val.Apt.Repo != "ppa:neovim-ppa/stable" {
+			t.Errorf("Round-trip Apt = %+v, want repo %q", val.Apt, "ppa:neovim-ppa/stable")
+		}
+	})
+
+	t.Run("repo on a manager other than apt is an error", func(t *testing.T) {
+		t.Parallel()
+		var ep EntryPackage
+		err := yaml.Unmarshal([]byte("managers:\n  pacman:\n    name: neovim\n    repo: ppa:neovim-ppa/stable\n"), &ep)
+		if err == nil {
+			t.Error("expected an error for repo on pacman")
+		}
+	})
+
+	t.Run("mas app name round-trips with a numeric ID", func(t *testing.T) {
+		t.Parallel()
+		var ep EntryPackage
+		if err := yaml.Unmarshal([]byte("managers:\n  mas:\n    name: \"497799835\"\n    app: Xcode\n"), &ep); err != nil {
+			t.Fatalf("Unmarshal error: %v", err)
+		}
+
+		out, err := yaml.Marshal(&ep)
+		if err != nil {
+			t.Fatalf("Marshal error: %v", err)
+		}
+
+		var ep2 EntryPackage
+		if err := yaml.Unmarshal(out, &ep2); err != nil {
+			t.Fatalf("Unmarshal error: %v", err)
+		}
+
+		val := ep2.Managers["mas"]
+		if val.PackageName != "497799835" || val.Mas == nil || val.Mas.AppName != "Xcode" {
+			t.Errorf("Round-trip mas = %+v (Mas %+v), want ID 497799835 and app Xcode", val, val.Mas)
+		}
+	})
+
+	t.Run("unquoted mas ID is read as a string", func(t *testing.T) {
+		t.Parallel()
+		var ep EntryPackage
+		if err := yaml.Unmarshal([]byte("managers:\n  mas: 497799835\n"), &ep); err != nil {
+			t.Fatalf("Unmarshal error: %v", err)
+		}
+
+		if got := ep.Managers["mas"].PackageName; got != "497799835" {
+			t.Errorf("mas PackageName = %q, want %q", got, "497799835")
+		}
+	})
+
+	t.Run("app on a manager other than mas is an error", func(t *testing.T) {
+		t.Parallel()
+		var ep EntryPackage
+		if err := yaml.Unmarshal([]byte("managers:\n  brew:\n    name: xcode\n    app: Xcode\n"), &ep); err == nil {
+			t.Error("expected an error for app on brew")
+		}
+	})
+
+	t.Run("scoop bucket and winget source round-trip", func(t *testing.T) {
+		t.Parallel()
+		var ep EntryPackage
+		data := "managers:\n  scoop:\n    name: vlc\n    bucket: extras\n" +
+			"  winget:\n    name: Contoso.Tool\n    source: contoso\n    source_url: https://winget.contoso.com/api\n"
+		if err := yaml.Unmarshal([]byte(data), &ep); err != nil {
+			t.Fatalf("Unmarshal error: %v", err)
+		}
+
+		out, err := yaml.Marshal(&ep)
+		if err != nil {
+			t.Fatalf("Marshal error: %v", err)
+		}
+
+		var ep2 EntryPackage
+		if err := yaml.Unmarshal(out, &ep2); err != nil {
+			t.Fatalf("Unmarshal error: %v", err)
+		}
+
+		if scoop := ep2.Managers["scoop"]; scoop.Scoop == nil || scoop.Scoop.Bucket != "extras" {
+			t.Errorf("Round-trip scoop = %+v, want bucket extras", scoop)
+		}
+
+		winget := ep2.Managers["winget"]
+		if winget.Winget == nil || winget.Winget.Source != "contoso" || winget.Winget.SourceURL != "https://winget.contoso.com/api" {
+			t.Errorf("Round-trip winget = %+v, want source contoso and its URL", winget)
+		}
+	})
+
+	t.Run("bucket on a manager other than scoop is an error", func(t *testing.T) {
+		t.Parallel()
+		var ep EntryPackage
+		if err := yaml.Unmarshal([]byte("managers:\n  winget:\n    name: vlc\n    bucket: extras\n"), &ep); err == nil {
+			t.Error("expected an error for bucket on winget")
+		}
+	})
+
+	t.Run("package name overrides round-trip", func(t *testing.T) {
+		t.Parallel()
+		var ep EntryPackage
+		data := "managers:\n  pacman:\n    name: neovim\n    overrides:\n" +
+			"      - when: '{{ eq .Hostname \"desktop\" }}'\n        package_name: neovim-git\n"
+		if err := yaml.Unmarshal([]byte(data), &ep); err != nil {
+			t.Fatalf("Unmarshal error: %v", err)
+		}
+
+		out, err := yaml.Marshal(&ep)
+		if err != nil {
+			t.Fatalf("Marshal error: %v", err)
+		}
+
+		var ep2 EntryPackage
+		if err := yaml.Unmarshal(out, &ep2); err != nil {
+			t.Fatalf("Unmarshal error: %v", err)
+		}
+
+		want := []PackageNameOverride{{When: `{{ eq .Hostname "desktop" }}`, PackageName: "neovim-git"}}
+		if pacman := ep2.Managers["pacman"]; pacman.PackageName != "neovim" || !slices.Equal(pacman.Overrides, want) {
+			t.Errorf("Round-trip pacman = %+v, want neovim with override %+v", pacman, want)
+		}
+	})
+
+	t.Run("override without a when is an error", func(t *testing.T) {
+		t.Parallel()
+		var ep EntryPackage
+		if err := yaml.Unmarshal([]byte("managers:\n  pacman:\n    name: neovim\n    overrides:\n      - package_name: neovim-git\n"), &ep); err == nil {
+			t.Error("expected an error for an override without when")
+		}
+	})
+
+	t.Run("portage is read as emerge", func(t *testing.T) {
+		t.Parallel()
+		var ep EntryPackage
+		if err := yaml.Unmarshal([]byte("managers:\n  portage: app-editors/neovim\n"), &ep); err != nil {
+			t.Fatalf("Unmarshal error: %v", err)
+		}
+
+		if _, ok := ep.Managers["portage"]; ok {
+			t.Error("portage should be stored as emerge")
+		}
+		if got := ep.Managers["emerge"].PackageName; got != "app-editors/neovim" {
+			t.Errorf("emerge PackageName = %q, want %q", got, "app-editors/neovim")
+		}
+	})
+
+	t.Run("portage and emerge together is an error", func(t *testing.T) {
+		t.Parallel()
+		var ep EntryPackage
+		err := yaml.Unmarshal([]byte("managers:\n  portage: neovim\n  emerge: neovim\n"), &ep)
+		if err == nil {
+			t.Error("expected an error when both portage and emerge are set")
+		}
+	})
+
 	t.Run("native manager with empty deps collapses to plain string", func(t *testing.T) {
 		t.Parallel()
 		ep := EntryPackage{
@@ -1419,3 +1902,38 @@ func TestGetFilteredApplicationsWithLogger_SurfacesWhenErrors(t *testing.T) {
 		t.Errorf("expected warning about when expression; log = %q", buf.String())
 	}
 }
+
+func TestResolveBackupPath(t *testing.T) {
+	t.Parallel()
+
+	home, err := os.UserHomeDir()
+	if err != nil {
+		t.Fatalf("cannot get home dir: %v", err)
+	}
+
+	backupRoot := filepath.Join(t.TempDir(), "dotfiles")
+	absBackup := filepath.Join(t.TempDir(), "elsewhere", "nvim")
+
+	tests := []struct {
+		name   string
+		backup string
+		root   string
+		want   string
+	}{
+		{"absolute", absBackup, backupRoot, absBackup},
+		{"repo-relative with dot", "./nvim", backupRoot, filepath.Join(backupRoot, "nvim")},
+		{"repo-relative bare", "nvim", backupRoot, filepath.Join(backupRoot, "nvim")},
+		{"tilde-prefixed ignores backup root", "~/external-dotfiles/nvim", backupRoot, filepath.Join(home, "external-dotfiles", "nvim")},
+		{"tilde backup root", "./nvim", "~/dotfiles", filepath.Join(home, "dotfiles", "nvim")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			if got := ResolveBackupPath(tt.backup, tt.root, nil, nil); got != tt.want {
+				t.Errorf("ResolveBackupPath(%q, %q) = %q, want %q", tt.backup, tt.root, got, tt.want)
+			}
+		})
+	}
+}
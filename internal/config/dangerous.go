@@ -0,0 +1,74 @@
+package config
+
+import (
+	"path"
+	"strings"
+)
+
+// dangerousTargets are the system directories a target may only be with
+// allow_dangerous, in the form normalizeTarget gives them.
+var dangerousTargets = []string{
+	"/",
+	"/bin",
+	"/boot",
+	"/dev",
+	"/etc",
+	"/lib",
+	"/proc",
+	"/sbin",
+	"/sys",
+	"/usr",
+	"/var",
+	"c:/",
+	"c:/program files",
+	"c:/program files (x86)",
+	"c:/windows",
+}
+
+// DangerousTarget returns why restoring to target, an expanded target path,
+// could damage the system: it is one of the system directories of
+// dangerousTargets or the home directory home itself. It returns "" for any
+// other target. ".." segments are resolved first; symlinks are not.
+func DangerousTarget(target, home string) string {
+	target = normalizeTarget(target)
+
+	if home != "" && target == normalizeTarget(home) {
+		return "the home directory"
+	}
+
+	for _, dir := range dangerousTargets {
+		if target == dir {
+			return "a system directory"
+		}
+	}
+
+	return ""
+}
+
+// normalizeTarget cleans p with forward slashes, so Windows targets compare
+// on any OS, and lowercases paths with a drive letter, which Windows
+// compares ignoring case.
+func normalizeTarget(p string) string {
+	p = path.Clean(strings.ReplaceAll(p, `\`, "/"))
+
+	if len(p) >= 2 && p[1] == ':' {
+		p = strings.ToLower(p)
+		if len(p) == 2 {
+			p += "/"
+		}
+	}
+
+	return p
+}
+
+// DangerousTarget returns DangerousTarget of target unless the entry allows
+// dangerous targets or is not a folder entry: a files entry, like a folder
+// entry with link_mode: files, links files into its target but leaves the
+// directory itself in place.
+func (s *SubEntry) DangerousTarget(target, home string) string {
+	if s.AllowDangerous || !s.IsFolder() || s.LinksFiles() {
+		return ""
+	}
+
+	return DangerousTarget(target, home)
+}
@@ -0,0 +1,86 @@
+package config
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// DefaultDedupeThreshold is the size from which files of entries with
+// `dedupe: true` are compared by content, when no threshold is configured.
+const DefaultDedupeThreshold = 1 << 20
+
+// Dedupe configures content-based handling of large files in entries that
+// set `dedupe: true`. Files of at least Threshold bytes are hashed on
+// backup, and one whose backup already has the same content is not copied.
+// With Objects, the files of copy entries are stored once under
+// .tidydots/objects in the repo and the entry's backup holds a small pointer
+// to them instead.
+type Dedupe struct {
+	Threshold string `yaml:"threshold,omitempty"` // e.g. 512KiB or 4MiB; default DefaultDedupeThreshold
+	Objects   bool   `yaml:"objects,omitempty"`
+}
+
+// DedupeThreshold returns the size in bytes from which deduped files are
+// hashed: the configured threshold, or DefaultDedupeThreshold.
+func (c *Config) DedupeThreshold() int64 {
+	if c.Dedupe != nil && c.Dedupe.Threshold != "" {
+		if n, err := ParseSize(c.Dedupe.Threshold); err == nil {
+			return n
+		}
+	}
+
+	return DefaultDedupeThreshold
+}
+
+// DedupeObjects reports whether large files of deduped copy entries are kept
+// in the shared object store.
+func (c *Config) DedupeObjects() bool {
+	return c.Dedupe != nil && c.Dedupe.Objects
+}
+
+// sizeUnits are the suffixes ParseSize accepts. K, M and G are binary
+// multiples, with or without a trailing B or iB.
+var sizeUnits = []struct {
+	suffix     string
+	multiplier int64
+}{
+	{"KiB", 1 << 10}, {"MiB", 1 << 20}, {"GiB", 1 << 30},
+	{"KB", 1 << 10}, {"MB", 1 << 20}, {"GB", 1 << 30},
+	{"K", 1 << 10}, {"M", 1 << 20}, {"G", 1 << 30},
+	{"B", 1},
+}
+
+// ParseSize parses a size such as 1048576, 512KiB or 4M into bytes.
+func ParseSize(s string) (int64, error) {
+	s = strings.TrimSpace(s)
+	multiplier := int64(1)
+
+	for _, u := range sizeUnits {
+		if num, ok := strings.CutSuffix(s, u.suffix); ok {
+			s, multiplier = strings.TrimSpace(num), u.multiplier
+			break
+		}
+	}
+
+	n, err := strconv.ParseInt(s, 10, 64)
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("invalid size %q", s)
+	}
+
+	return n * multiplier, nil
+}
+
+// validateDedupe validates the dedupe block.
+func validateDedupe(d *Dedupe) []error {
+	if d == nil || d.Threshold == "" {
+		return nil
+	}
+
+	if _, err := ParseSize(d.Threshold); err != nil {
+		return []error{NewFieldError("dedupe", "threshold", d.Threshold,
+			fmt.Errorf("must be a size in bytes, optionally with a unit, e.g. 512KiB or 4MiB"))}
+	}
+
+	return nil
+}
@@ -0,0 +1,263 @@
+package config
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Defaults sets sub-entry fields that entries leave unset, to save repeating
+// them. A `defaults:` block at the top of the config applies to every entry;
+// one on an application applies to its entries and overrides the top-level
+// block field by field. A field an entry sets itself always wins.
+type Defaults struct {
+	Sudo   *bool  `yaml:"sudo,omitempty"`
+	Verify *bool  `yaml:"verify,omitempty"`
+	Method string `yaml:"method,omitempty"` // applies to entries that list files
+	// BackupPattern is the backup path of entries that declare none, e.g.
+	// "./{{ .App }}/{{ .Entry }}". Setup entries never get one.
+	BackupPattern string `yaml:"backup_pattern,omitempty"`
+}
+
+// Sub-entry fields that defaults can set; see SubEntry.Inherits.
+const (
+	FieldSudo   = "sudo"
+	FieldVerify = "verify"
+	FieldMethod = "method"
+	FieldBackup = "backup"
+)
+
+// entryFields is a set of sub-entry fields, one bit per Field constant.
+type entryFields uint8
+
+const (
+	fieldSudo entryFields = 1 << iota
+	fieldVerify
+	fieldMethod
+	fieldBackup
+)
+
+// entryField returns the bit of a Field constant, or 0 for any other name.
+func entryField(name string) entryFields {
+	switch name {
+	case FieldSudo:
+		return fieldSudo
+	case FieldVerify:
+		return fieldVerify
+	case FieldMethod:
+		return fieldMethod
+	case FieldBackup:
+		return fieldBackup
+	}
+
+	return 0
+}
+
+// backupPatternVar matches the {{ .App }} and {{ .Entry }} actions of a
+// backup pattern.
+var backupPatternVar = regexp.MustCompile(`\{\{-?\s*\.(App|Entry)\s*-?\}\}`)
+
+// BackupFor returns the backup path BackupPattern gives the entry named entry
+// of application app, or "" when there is no pattern. Template actions other
+// than {{ .App }} and {{ .Entry }} are kept, to be rendered with the path.
+func (d Defaults) BackupFor(app, entry string) string {
+	return backupPatternVar.ReplaceAllStringFunc(d.BackupPattern, func(action string) string {
+		if backupPatternVar.FindStringSubmatch(action)[1] == "App" {
+			return app
+		}
+
+		return entry
+	})
+}
+
+// merged returns d with every field override sets replacing d's.
+func (d Defaults) merged(override *Defaults) Defaults {
+	if override == nil {
+		return d
+	}
+
+	if override.Sudo != nil {
+		d.Sudo = override.Sudo
+	}
+
+	if override.Verify != nil {
+		d.Verify = override.Verify
+	}
+
+	if override.Method != "" {
+		d.Method = override.Method
+	}
+
+	if override.BackupPattern != "" {
+		d.BackupPattern = override.BackupPattern
+	}
+
+	return d
+}
+
+// EntryDefaults returns the defaults the entries of app inherit: app's own
+// defaults over the config's.
+func (c *Config) EntryDefaults(app *Application) Defaults {
+	var d Defaults
+	if c.Defaults != nil {
+		d = *c.Defaults
+	}
+
+	return d.merged(app.Defaults)
+}
+
+// applyDefaults fills in the fields every entry leaves unset from the
+// defaults of its application and of the config, and marks them inherited.
+func (c *Config) applyDefaults() {
+	for i := range c.Applications {
+		app := &c.Applications[i]
+		d := c.EntryDefaults(app)
+
+		for j := range app.Entries {
+			app.Entries[j].inherit(app.Name, d)
+		}
+	}
+}
+
+// inherit sets the fields of s that were not set explicitly and that d has a
+// value for, and marks them inherited.
+func (s *SubEntry) inherit(appName string, d Defaults) {
+	if d.Sudo != nil && !s.Explicit(FieldSudo) {
+		s.Sudo = *d.Sudo
+		s.SetInherited(FieldSudo, true)
+	}
+
+	if d.Verify != nil && !s.Explicit(FieldVerify) {
+		s.Verify = *d.Verify
+		s.SetInherited(FieldVerify, true)
+	}
+
+	if d.Method != "" && len(s.Files) > 0 && !s.Explicit(FieldMethod) {
+		s.Method = d.Method
+		s.SetInherited(FieldMethod, true)
+	}
+
+	if d.BackupPattern != "" && !s.IsSetup() && !s.Explicit(FieldBackup) {
+		s.Backup = d.BackupFor(appName, s.Name)
+		s.SetInherited(FieldBackup, true)
+	}
+}
+
+// Inherits reports whether field, one of the Field constants, holds a value
+// taken from defaults rather than set on the entry. Inherited values are not
+// written back when the config is saved.
+func (s *SubEntry) Inherits(field string) bool {
+	return s.inherited&entryField(field) != 0
+}
+
+// Explicit reports whether field, one of the Field constants, was set on the
+// entry itself, in the config file or with SetInherited(field, false).
+func (s *SubEntry) Explicit(field string) bool {
+	return s.explicit&entryField(field) != 0
+}
+
+// SetInherited marks field, one of the Field constants, as inherited from
+// defaults, or as set on the entry, which saves it even when it holds the
+// zero value, e.g. `sudo: false` overriding a default of true.
+func (s *SubEntry) SetInherited(field string, inherited bool) {
+	bit := entryField(field)
+
+	if inherited {
+		s.inherited |= bit
+		s.explicit &^= bit
+	} else {
+		s.inherited &^= bit
+		s.explicit |= bit
+	}
+}
+
+// subEntryYAML is SubEntry without its YAML methods.
+type subEntryYAML SubEntry
+
+// UnmarshalYAML decodes a sub-entry and records which of the fields that
+// defaults can set it declares.
+func (s *SubEntry) UnmarshalYAML(node *yaml.Node) error {
+	var plain subEntryYAML
+	if err := node.Decode(&plain); err != nil {
+		return err
+	}
+
+	*s = SubEntry(plain)
+
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		s.explicit |= entryField(node.Content[i].Value)
+	}
+
+	return nil
+}
+
+// MarshalYAML leaves out inherited fields and writes explicit false values
+// of sudo and verify, which would otherwise be omitted.
+func (s SubEntry) MarshalYAML() (any, error) {
+	plain := subEntryYAML(s)
+
+	var falseKeys []string
+
+	for _, f := range []struct {
+		name  string
+		value *bool
+	}{{FieldSudo, &plain.Sudo}, {FieldVerify, &plain.Verify}} {
+		switch {
+		case s.Inherits(f.name):
+			*f.value = false
+		case s.Explicit(f.name) && !*f.value:
+			// Encode as true so omitempty keeps the key, then flip it below.
+			*f.value = true
+			falseKeys = append(falseKeys, f.name)
+		}
+	}
+
+	if s.Inherits(FieldMethod) {
+		plain.Method = ""
+	}
+
+	if s.Inherits(FieldBackup) {
+		plain.Backup = ""
+	}
+
+	var node yaml.Node
+	if err := node.Encode(plain); err != nil {
+		return nil, err
+	}
+
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if slices.Contains(falseKeys, node.Content[i].Value) {
+			node.Content[i+1].Value = "false"
+		}
+	}
+
+	return &node, nil
+}
+
+// validateDefaults validates a defaults block; where names it in errors.
+func validateDefaults(where string, d *Defaults) []error {
+	if d == nil {
+		return nil
+	}
+
+	var errs []error
+
+	switch d.Method {
+	case "", MethodSymlink, MethodCopy:
+	default:
+		errs = append(errs, NewFieldError(where, "defaults.method", d.Method,
+			fmt.Errorf("must be %q or %q", MethodSymlink, MethodCopy)))
+	}
+
+	// Entry backups are validated once the pattern is expanded, unless other
+	// template actions remain; check the pattern itself the same way.
+	if backup := d.BackupFor("app", "entry"); backup != "" && !isTemplatePath(backup) {
+		if err := ValidatePath(backup); err != nil {
+			errs = append(errs, NewFieldError(where, "defaults.backup_pattern", d.BackupPattern, err))
+		}
+	}
+
+	return errs
+}
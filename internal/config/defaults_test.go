@@ -0,0 +1,225 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLoad_DefaultsResolutionOrder(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name       string
+		configDefs string
+		appDefs    string
+		entry      string
+		wantSudo   bool
+		wantVerify bool
+		wantMethod string
+		wantBackup string
+		inherited  []string
+	}{
+		{
+			name:       "built-in",
+			entry:      "backup: ./x",
+			wantBackup: "./x",
+		},
+		{
+			name:       "config defaults",
+			configDefs: "{sudo: true, verify: true, method: copy, backup_pattern: './{{ .App }}/{{ .Entry }}'}",
+			wantSudo:   true,
+			wantVerify: true,
+			wantMethod: MethodCopy,
+			wantBackup: "./app/entry",
+			inherited:  []string{FieldSudo, FieldVerify, FieldMethod, FieldBackup},
+		},
+		{
+			name:       "application defaults over config defaults",
+			configDefs: "{sudo: true, backup_pattern: './shared'}",
+			appDefs:    "{sudo: false, backup_pattern: './{{ .Entry }}-{{.App}}'}",
+			wantBackup: "./entry-app",
+			inherited:  []string{FieldSudo, FieldBackup},
+		},
+		{
+			name:       "application defaults alone",
+			appDefs:    "{verify: true}",
+			entry:      "backup: ./x",
+			wantVerify: true,
+			wantBackup: "./x",
+			inherited:  []string{FieldVerify},
+		},
+		{
+			name:       "entry over defaults",
+			configDefs: "{sudo: true, verify: true, method: copy, backup_pattern: './{{ .App }}'}",
+			appDefs:    "{verify: true}",
+			entry:      "sudo: false\nverify: false\nmethod: symlink\nbackup: ./mine",
+			wantMethod: MethodSymlink,
+			wantBackup: "./mine",
+		},
+		{
+			name:       "other template actions are kept",
+			configDefs: "{backup_pattern: './{{ .App }}/{{ .Hostname }}'}",
+			wantBackup: "./app/{{ .Hostname }}",
+			inherited:  []string{FieldBackup},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			var b strings.Builder
+			b.WriteString("version: 3\n")
+
+			if tt.configDefs != "" {
+				b.WriteString("defaults: " + tt.configDefs + "\n")
+			}
+
+			b.WriteString("applications:\n  - name: app\n")
+
+			if tt.appDefs != "" {
+				b.WriteString("    defaults: " + tt.appDefs + "\n")
+			}
+
+			b.WriteString("    entries:\n      - name: entry\n        targets: {linux: ~/x}\n        files: [a]\n")
+
+			if tt.entry != "" {
+				b.WriteString("        " + strings.ReplaceAll(tt.entry, "\n", "\n        ") + "\n")
+			}
+
+			cfg, err := Load(writeTestFile(t, t.TempDir(), "tidydots.yaml", b.String()))
+			if err != nil {
+				t.Fatalf("Load() error = %v", err)
+			}
+
+			got := cfg.Applications[0].Entries[0]
+
+			if got.Sudo != tt.wantSudo || got.Verify != tt.wantVerify || got.Method != tt.wantMethod || got.Backup != tt.wantBackup {
+				t.Errorf("entry = {sudo: %v, verify: %v, method: %q, backup: %q}, want {sudo: %v, verify: %v, method: %q, backup: %q}",
+					got.Sudo, got.Verify, got.Method, got.Backup, tt.wantSudo, tt.wantVerify, tt.wantMethod, tt.wantBackup)
+			}
+
+			for _, field := range []string{FieldSudo, FieldVerify, FieldMethod, FieldBackup} {
+				want := strings.Contains(strings.Join(tt.inherited, " "), field)
+				if got.Inherits(field) != want {
+					t.Errorf("Inherits(%q) = %v, want %v", field, got.Inherits(field), want)
+				}
+			}
+		})
+	}
+}
+
+func TestLoad_DefaultsSkipEntriesTheyDoNotApplyTo(t *testing.T) {
+	t.Parallel()
+
+	path := writeTestFile(t, t.TempDir(), "tidydots.yaml", `version: 3
+defaults:
+  method: copy
+  backup_pattern: ./{{ .App }}/{{ .Entry }}
+applications:
+  - name: app
+    entries:
+      - name: folder
+        targets: {linux: ~/x}
+      - name: setup
+        check: {linux: "true"}
+        run: {linux: "true"}
+`)
+
+	cfg, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+
+	folder, setup := cfg.Applications[0].Entries[0], cfg.Applications[0].Entries[1]
+
+	if folder.Method != "" {
+		t.Errorf("folder entry Method = %q, want the copy default skipped: copy is files-only", folder.Method)
+	}
+
+	if folder.Backup != "./app/folder" {
+		t.Errorf("folder entry Backup = %q, want ./app/folder", folder.Backup)
+	}
+
+	if setup.Backup != "" {
+		t.Errorf("setup entry Backup = %q, want none: setup entries cannot declare a backup", setup.Backup)
+	}
+}
+
+func TestSave_WritesOnlyExplicitEntryFields(t *testing.T) {
+	t.Parallel()
+	dir := t.TempDir()
+
+	path := writeTestFile(t, dir, "tidydots.yaml", `version: 3
+defaults:
+  sudo: true
+  backup_pattern: ./{{ .App }}/{{ .Entry }}
+applications:
+  - name: app
+    entries:
+      - name: inherits
+        targets: {linux: ~/a}
+      - name: overrides
+        sudo: false
+        backup: ./elsewhere
+        targets: {linux: ~/b}
+`)
+
+	cfg, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+
+	if err := Save(cfg, path); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	data, err := os.ReadFile(filepath.Clean(path))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	saved := string(data)
+
+	if strings.Count(saved, "sudo:") != 2 {
+		t.Errorf("saved config should hold the default and the override of sudo only:\n%s", saved)
+	}
+
+	if !strings.Contains(saved, "sudo: false") || !strings.Contains(saved, "backup: ./elsewhere") {
+		t.Errorf("saved config lost the overrides:\n%s", saved)
+	}
+
+	if strings.Contains(saved, "./app/inherits") {
+		t.Errorf("saved config wrote the inherited backup:\n%s", saved)
+	}
+
+	reloaded, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load() of the saved config error = %v", err)
+	}
+
+	inherits, overrides := reloaded.Applications[0].Entries[0], reloaded.Applications[0].Entries[1]
+
+	if !inherits.Sudo || inherits.Backup != "./app/inherits" || overrides.Sudo || overrides.Backup != "./elsewhere" {
+		t.Errorf("reloaded entries = %+v, %+v, want the same values as before saving", inherits, overrides)
+	}
+}
+
+func TestValidateConfig_Defaults(t *testing.T) {
+	t.Parallel()
+
+	cfg := &Config{
+		Version:  3,
+		Defaults: &Defaults{Method: "hardlink"},
+		Applications: []Application{
+			{Name: "app", Defaults: &Defaults{BackupPattern: "../{{ .App }}"}},
+		},
+	}
+
+	errs := ValidateConfig(cfg)
+	if len(errs) != 2 {
+		t.Fatalf("ValidateConfig() = %v, want an error for the method and one for the backup pattern", errs)
+	}
+}
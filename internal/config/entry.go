@@ -2,6 +2,9 @@ package config
 
 import (
 	"fmt"
+	"os"
+	"strconv"
+	"strings"
 
 	"gopkg.in/yaml.v3"
 )
@@ -14,19 +17,117 @@ const (
 	MethodCopy = "copy"
 )
 
+// Link modes of a folder SubEntry.
+const (
+	// LinkModeDir symlinks the target directory itself to the backup (default).
+	LinkModeDir = "dir"
+	// LinkModeFiles keeps the target a real directory and symlinks each file
+	// of the backup into it, leaving the files other programs add alone.
+	LinkModeFiles = "files"
+)
+
+// Encodings of the templates of a config SubEntry.
+const (
+	// EncodingUTF8 is the default: templates are rendered as they are.
+	EncodingUTF8 = "utf-8"
+	// EncodingLatin1 is ISO 8859-1.
+	EncodingLatin1 = "latin1"
+	// EncodingWindows1252 is the Windows superset of Latin-1.
+	EncodingWindows1252 = "windows-1252"
+)
+
 // managerGit is the managers-map key whose value is a GitPackage object rather
 // than a plain package name.
 const managerGit = "git"
 
+// managerApt is the Debian/Ubuntu package manager, which also accepts a
+// repository to add before installing.
+const managerApt = "apt"
+
+// managerMas installs Mac App Store apps, and also accepts the app's name.
+const managerMas = "mas"
+
+// managerScoop and managerWinget are Windows package managers that also accept
+// the bucket or source a package is installed from.
+const (
+	managerScoop  = "scoop"
+	managerWinget = "winget"
+)
+
+// managerEmerge is the Gentoo package manager, which also accepts USE flags.
+// managerPortage is an alias for it: a portage entry is loaded as emerge.
+const (
+	managerEmerge  = "emerge"
+	managerPortage = "portage"
+)
+
 // ManagerValue represents a typed value for a package manager entry.
 // It holds either a package name string (for traditional managers like pacman, apt),
 // a GitPackage configuration (for git repositories), or an InstallerPackage
-// configuration (for shell command-based installation).
+// configuration (for shell command-based installation). Emerge carries the
+// emerge-specific options of a Gentoo package, Apt the apt-specific options
+// of a Debian/Ubuntu package, Mas the display name of a Mac App Store app,
+// whose PackageName is its numeric App Store ID, and Scoop and Winget the
+// bucket or source a Windows package is installed from. Overrides replace
+// PackageName where their when is true; see Resolve.
 type ManagerValue struct {
 	PackageName string
 	Git         *GitPackage
 	Installer   *InstallerPackage
+	Emerge      *EmergeOptions
+	Apt         *AptOptions
+	Mas         *MasOptions
+	Scoop       *ScoopOptions
+	Winget      *WingetOptions
 	Deps        []string
+	Overrides   []PackageNameOverride
+}
+
+// PackageNameOverride is a package name used instead of a manager's
+// package name where When renders true, e.g. neovim-git on one host.
+type PackageNameOverride struct {
+	When        string `yaml:"when"`
+	PackageName string `yaml:"package_name"`
+}
+
+// EmergeOptions holds emerge-specific install settings, given as the `use`
+// key of an emerge manager entry.
+type EmergeOptions struct {
+	// UseFlagsOverride is set as USE for the install command, e.g. "lua -X".
+	UseFlagsOverride string
+}
+
+// AptOptions holds apt-specific install settings, given as the `repo` key of
+// an apt manager entry.
+type AptOptions struct {
+	// Repo is added with add-apt-repository before installing, unless it is
+	// already configured, e.g. "ppa:neovim-ppa/stable".
+	Repo string
+}
+
+// MasOptions holds mas-specific settings, given as the `app` key of a mas
+// manager entry.
+type MasOptions struct {
+	// AppName is the app's name, shown instead of its App Store ID, e.g. "Xcode".
+	AppName string
+}
+
+// ScoopOptions holds scoop-specific settings, given as the `bucket` key of a
+// scoop manager entry.
+type ScoopOptions struct {
+	// Bucket is added with scoop bucket add before installing, unless it is
+	// already known, e.g. "extras".
+	Bucket string
+}
+
+// WingetOptions holds winget-specific settings, given as the `source` and
+// `source_url` keys of a winget manager entry.
+type WingetOptions struct {
+	// Source is the source the package is installed from, e.g. "msstore".
+	Source string
+	// SourceURL is the source's URL. When set, the source is added with
+	// winget source add before installing, unless it is already configured.
+	SourceURL string
 }
 
 // IsGit returns true if this manager value represents a git package configuration.
@@ -35,8 +136,26 @@ func (v ManagerValue) IsGit() bool { return v.Git != nil }
 // IsInstaller returns true if this manager value represents an installer package configuration.
 func (v ManagerValue) IsInstaller() bool { return v.Installer != nil }
 
+// Resolve returns v with the package name of its first override whose when
+// renders true against renderer, or v's own package name when none does,
+// and no overrides left.
+func (v ManagerValue) Resolve(renderer PathRenderer) ManagerValue {
+	for _, o := range v.Overrides {
+		if EvaluateWhen(o.When, renderer) {
+			v.PackageName = o.PackageName
+			break
+		}
+	}
+
+	v.Overrides = nil
+
+	return v
+}
+
 // MarshalYAML writes non-git/non-installer manager values as plain strings
-// when no deps exist, or as an object with name/deps when deps are present.
+// when no deps exist, or as an object with name/deps (and use, for emerge,
+// repo, for apt, app, for mas, bucket, for scoop, or source and source_url,
+// for winget, and overrides) otherwise.
 func (v ManagerValue) MarshalYAML() (any, error) {
 	if v.IsGit() {
 		return v.Git, nil
@@ -46,42 +165,92 @@ func (v ManagerValue) MarshalYAML() (any, error) {
 		return v.Installer, nil
 	}
 
+	hasUse := v.Emerge != nil && v.Emerge.UseFlagsOverride != ""
+	hasRepo := v.Apt != nil && v.Apt.Repo != ""
+	hasApp := v.Mas != nil && v.Mas.AppName != ""
+	hasBucket := v.Scoop != nil && v.Scoop.Bucket != ""
+	hasSource := v.Winget != nil && (v.Winget.Source != "" || v.Winget.SourceURL != "")
+
 	// Collapse to plain string when no deps
-	if len(v.Deps) == 0 {
+	if len(v.Deps) == 0 && len(v.Overrides) == 0 && !hasUse && !hasRepo && !hasApp && !hasBucket && !hasSource {
 		return v.PackageName, nil
 	}
 
-	// Object form with name and deps
+	// Object form with name, deps, use and repo
 	result := map[string]any{}
 	if v.PackageName != "" {
 		result["name"] = v.PackageName
 	}
-	result["deps"] = v.Deps
+	if len(v.Deps) > 0 {
+		result["deps"] = v.Deps
+	}
+	if hasUse {
+		result["use"] = v.Emerge.UseFlagsOverride
+	}
+	if hasRepo {
+		result["repo"] = v.Apt.Repo
+	}
+	if hasApp {
+		result["app"] = v.Mas.AppName
+	}
+	if hasBucket {
+		result["bucket"] = v.Scoop.Bucket
+	}
+	if hasSource {
+		if v.Winget.Source != "" {
+			result["source"] = v.Winget.Source
+		}
+		if v.Winget.SourceURL != "" {
+			result["source_url"] = v.Winget.SourceURL
+		}
+	}
+	if len(v.Overrides) > 0 {
+		result["overrides"] = v.Overrides
+	}
 
 	return result, nil
 }
 
-// EntryPackage contains package installation configuration
+// EntryPackage contains package installation configuration.
+// Phase orders installs coarsely: every package of a lower phase is installed
+// before any package of a higher one. Packages without a phase are in phase 0.
+// After orders installs within a phase: the package is installed after the
+// named applications' packages of the same phase, whether or not they succeed.
+// Verify is run after a successful custom command; the install fails if it
+// does. Prefer names the manager to install with whenever it is available,
+// ahead of manager_priority, e.g. pacman over an AUR helper.
 type EntryPackage struct {
 	Managers map[string]ManagerValue   `yaml:"managers,omitempty"` // manager -> package name or GitPackage
 	Custom   map[string]string         `yaml:"custom,omitempty"`   // os -> command
+	Verify   map[string]string         `yaml:"verify,omitempty"`   // os -> command checking the custom install
 	URL      map[string]URLInstallSpec `yaml:"url,omitempty"`      // os -> url install
+	Phase    int                       `yaml:"phase,omitempty"`
+	After    []string                  `yaml:"after,omitempty"`  // application names
+	Prefer   string                    `yaml:"prefer,omitempty"` // manager used first when available
+	When     string                    `yaml:"when,omitempty"`   // installs the package only where this renders true
 }
 
-// GitPackage represents a git repository package configuration
+// GitPackage represents a git repository package configuration. Depth makes
+// a shallow clone of that many commits, and Sparse restricts the checkout to
+// files matching its patterns.
 type GitPackage struct {
 	URL     string            `yaml:"url"`
 	Branch  string            `yaml:"branch,omitempty"`
 	Targets map[string]string `yaml:"targets"`
+	Sparse  []string          `yaml:"sparse,omitempty"`
+	Depth   int               `yaml:"depth,omitempty"`
 	Sudo    bool              `yaml:"sudo,omitempty"`
 }
 
 // InstallerPackage represents a shell command-based package installation configuration.
 // Command is an OS-specific map of shell commands to run for installation.
 // Binary is an optional name used to check if the software is already installed via PATH lookup.
+// Verify is an optional OS-specific map of shell commands run after a successful install;
+// the install fails if the command does.
 type InstallerPackage struct {
 	Command map[string]string `yaml:"command"`
 	Binary  string            `yaml:"binary,omitempty"`
+	Verify  map[string]string `yaml:"verify,omitempty"`
 }
 
 // unmarshalGitManager converts a raw any value into a ManagerValue with a GitPackage.
@@ -125,7 +294,9 @@ func unmarshalInstallerManager(value any) (ManagerValue, error) {
 }
 
 // unmarshalNativeManager converts a raw any value into a ManagerValue for a standard
-// package manager. It supports both plain string format and object format with name/deps.
+// package manager. It supports both plain string format and object format with
+// name/deps, plus use for emerge, repo for apt, app for mas, bucket for scoop,
+// source/source_url for winget and overrides for any manager.
 func unmarshalNativeManager(key string, value any) (ManagerValue, error) {
 	// Try string first (backward compat)
 	str, ok := value.(string)
@@ -133,6 +304,11 @@ func unmarshalNativeManager(key string, value any) (ManagerValue, error) {
 		return ManagerValue{PackageName: str}, nil
 	}
 
+	// App Store IDs are numeric, so YAML reads an unquoted one as an int.
+	if id, ok := value.(int); ok && key == managerMas {
+		return ManagerValue{PackageName: strconv.Itoa(id)}, nil
+	}
+
 	// Try object with name/deps
 	objMap, ok := value.(map[string]any)
 	if !ok {
@@ -143,6 +319,8 @@ func unmarshalNativeManager(key string, value any) (ManagerValue, error) {
 	if name, ok := objMap["name"]; ok {
 		if nameStr, ok := name.(string); ok {
 			mv.PackageName = nameStr
+		} else if id, ok := name.(int); ok && key == managerMas {
+			mv.PackageName = strconv.Itoa(id)
 		}
 	}
 
@@ -159,9 +337,107 @@ func unmarshalNativeManager(key string, value any) (ManagerValue, error) {
 		}
 	}
 
+	if use, ok := objMap["use"]; ok {
+		useStr, ok := use.(string)
+		if !ok || key != managerEmerge {
+			return ManagerValue{}, fmt.Errorf("manager %s: use must be a string on emerge", key)
+		}
+
+		mv.Emerge = &EmergeOptions{UseFlagsOverride: useStr}
+	}
+
+	if repo, ok := objMap["repo"]; ok {
+		repoStr, ok := repo.(string)
+		if !ok || key != managerApt {
+			return ManagerValue{}, fmt.Errorf("manager %s: repo must be a string on apt", key)
+		}
+
+		mv.Apt = &AptOptions{Repo: repoStr}
+	}
+
+	if app, ok := objMap["app"]; ok {
+		appStr, ok := app.(string)
+		if !ok || key != managerMas {
+			return ManagerValue{}, fmt.Errorf("manager %s: app must be a string on mas", key)
+		}
+
+		mv.Mas = &MasOptions{AppName: appStr}
+	}
+
+	if bucket, ok := objMap["bucket"]; ok {
+		bucketStr, ok := bucket.(string)
+		if !ok || key != managerScoop {
+			return ManagerValue{}, fmt.Errorf("manager %s: bucket must be a string on scoop", key)
+		}
+
+		mv.Scoop = &ScoopOptions{Bucket: bucketStr}
+	}
+
+	for _, field := range []string{"source", "source_url"} {
+		value, ok := objMap[field]
+		if !ok {
+			continue
+		}
+
+		str, ok := value.(string)
+		if !ok || key != managerWinget {
+			return ManagerValue{}, fmt.Errorf("manager %s: %s must be a string on winget", key, field)
+		}
+
+		if mv.Winget == nil {
+			mv.Winget = &WingetOptions{}
+		}
+
+		if field == "source" {
+			mv.Winget.Source = str
+		} else {
+			mv.Winget.SourceURL = str
+		}
+	}
+
+	if mv.Winget != nil && mv.Winget.SourceURL != "" && mv.Winget.Source == "" {
+		return ManagerValue{}, fmt.Errorf("manager %s: source_url needs a source name", key)
+	}
+
+	if overrides, ok := objMap["overrides"]; ok {
+		var err error
+		if mv.Overrides, err = unmarshalOverrides(key, overrides); err != nil {
+			return ManagerValue{}, err
+		}
+	}
+
 	return mv, nil
 }
 
+// unmarshalOverrides converts the overrides list of a manager into
+// PackageNameOverrides, each with a when and a package_name.
+func unmarshalOverrides(key string, value any) ([]PackageNameOverride, error) {
+	list, ok := value.([]any)
+	if !ok {
+		return nil, fmt.Errorf("manager %s: overrides must be a list, got %T", key, value)
+	}
+
+	overrides := make([]PackageNameOverride, 0, len(list))
+
+	for i, item := range list {
+		obj, ok := item.(map[string]any)
+		if !ok {
+			return nil, fmt.Errorf("manager %s: overrides[%d] must be an object with when and package_name", key, i)
+		}
+
+		when, _ := obj["when"].(string)
+		name, _ := obj["package_name"].(string)
+
+		if strings.TrimSpace(when) == "" || name == "" {
+			return nil, fmt.Errorf("manager %s: overrides[%d] needs a when and a package_name", key, i)
+		}
+
+		overrides = append(overrides, PackageNameOverride{When: when, PackageName: name})
+	}
+
+	return overrides, nil
+}
+
 // UnmarshalYAML implements custom YAML unmarshaling for EntryPackage
 // to properly handle git manager objects while keeping other managers as strings
 func (ep *EntryPackage) UnmarshalYAML(node *yaml.Node) error {
@@ -169,7 +445,12 @@ func (ep *EntryPackage) UnmarshalYAML(node *yaml.Node) error {
 	type rawPackage struct {
 		Managers map[string]any            `yaml:"managers,omitempty"`
 		Custom   map[string]string         `yaml:"custom,omitempty"`
+		Verify   map[string]string         `yaml:"verify,omitempty"`
 		URL      map[string]URLInstallSpec `yaml:"url,omitempty"`
+		Phase    int                       `yaml:"phase,omitempty"`
+		After    []string                  `yaml:"after,omitempty"`
+		Prefer   string                    `yaml:"prefer,omitempty"`
+		When     string                    `yaml:"when,omitempty"`
 	}
 
 	var raw rawPackage
@@ -179,6 +460,15 @@ func (ep *EntryPackage) UnmarshalYAML(node *yaml.Node) error {
 
 	// Process managers to convert git entries to GitPackage
 	if raw.Managers != nil {
+		if _, ok := raw.Managers[managerPortage]; ok {
+			if _, ok := raw.Managers[managerEmerge]; ok {
+				return fmt.Errorf("managers: %s is an alias for %s, set only one", managerPortage, managerEmerge)
+			}
+
+			raw.Managers[managerEmerge] = raw.Managers[managerPortage]
+			delete(raw.Managers, managerPortage)
+		}
+
 		ep.Managers = make(map[string]ManagerValue, len(raw.Managers))
 		for key, value := range raw.Managers {
 			var (
@@ -204,7 +494,16 @@ func (ep *EntryPackage) UnmarshalYAML(node *yaml.Node) error {
 	}
 
 	ep.Custom = raw.Custom
+	ep.Verify = raw.Verify
 	ep.URL = raw.URL
+	ep.Phase = raw.Phase
+	ep.After = raw.After
+	ep.Prefer = raw.Prefer
+	ep.When = raw.When
+
+	if ep.Prefer == managerPortage {
+		ep.Prefer = managerEmerge
+	}
 
 	return nil
 }
@@ -255,11 +554,24 @@ func (ep *EntryPackage) GetInstallerPackage() (*InstallerPackage, bool) {
 // An application has a name, optional description, when condition, and contains multiple sub-entries.
 // It can also have an associated package for installation.
 type Application struct {
-	Package     *EntryPackage `yaml:"package,omitempty"`
-	Name        string        `yaml:"name"`
-	Description string        `yaml:"description,omitempty"`
-	When        string        `yaml:"when,omitempty"`
-	Entries     []SubEntry    `yaml:"entries"`
+	Package     *EntryPackage     `yaml:"package,omitempty"`
+	Name        string            `yaml:"name"`
+	Description string            `yaml:"description,omitempty"`
+	Notes       string            `yaml:"notes,omitempty"`      // reminders shown with the application; see ReadNotes
+	NotesFile   string            `yaml:"notes_file,omitempty"` // repo file holding the notes instead
+	When        string            `yaml:"when,omitempty"`
+	WhenRef     []string          `yaml:"when_ref,omitempty"`    // when_presets that must match too; see Config.MatchesWhen
+	Enabled     *bool             `yaml:"enabled,omitempty"`     // nil means enabled; see IsEnabled
+	Priority    int               `yaml:"priority,omitempty"`    // restore and backup process higher priorities first
+	RequiredBy  []string          `yaml:"required_by,omitempty"` // applications restore runs before this one, whatever their priority
+	Defaults    *Defaults         `yaml:"defaults,omitempty"`    // over the config's defaults for this application's entries
+	EnvVars     map[string]string `yaml:"env_vars,omitempty"`    // .Env of its templates and environment of its setup commands
+	Entries     []SubEntry        `yaml:"entries"`
+
+	// Source is the absolute path of the file this application was loaded
+	// from: the main tidydots.yaml or one of its includes. Empty for an
+	// application that has not been saved yet.
+	Source string `yaml:"-"`
 }
 
 // SubEntry represents an individual configuration entry within an application.
@@ -269,11 +581,51 @@ type SubEntry struct {
 	Targets map[string]string `yaml:"targets,omitempty"`
 	Check   map[string]string `yaml:"check,omitempty"` // os -> command; exit 0 means already set up
 	Run     map[string]string `yaml:"run,omitempty"`   // os -> command; runs only when check fails
-	Name    string            `yaml:"name"`
-	Method  string            `yaml:"method,omitempty"` // "" | "symlink" (default) | "copy"
-	Backup  string            `yaml:"backup,omitempty"`
-	Files   []string          `yaml:"files,omitempty"`
-	Sudo    bool              `yaml:"sudo,omitempty"`
+	// PostRestore maps an OS to a command run after the config entry is
+	// restored, e.g. fc-cache after fonts. A failing command leaves the entry
+	// restored with a warning; see manager.EntryResult.Warning.
+	PostRestore map[string]string `yaml:"post_restore,omitempty"`
+	// CaseRename maps a file of a files entry, by its name in the target, to
+	// the name it is backed up under, for files whose names differ only by
+	// case and would clobber each other on a case-insensitive filesystem.
+	CaseRename map[string]string `yaml:"case_rename,omitempty"`
+	Name       string            `yaml:"name"`
+	Method     string            `yaml:"method,omitempty"`    // "" | "symlink" (default) | "copy"
+	LinkMode   string            `yaml:"link_mode,omitempty"` // "" | "dir" (default) | "files"; folder entries only
+	Backup     string            `yaml:"backup,omitempty"`
+	Files      []string          `yaml:"files,omitempty"`
+	Sudo       bool              `yaml:"sudo,omitempty"`
+	Verify     bool              `yaml:"verify,omitempty"`  // write .sha256 sidecars on backup, check them on restore
+	Dedupe     bool              `yaml:"dedupe,omitempty"`  // compare large files by content on backup; see Config.Dedupe
+	Enabled    *bool             `yaml:"enabled,omitempty"` // nil means enabled; see IsEnabled
+	// MaxFileSize is the size in bytes above which backup skips a file of
+	// the entry. Zero means Config.DefaultMaxFileSize; see Config.MaxFileSize.
+	MaxFileSize int64 `yaml:"max_file_size,omitempty"`
+	// Encoding is the character encoding of the entry's templates, which
+	// are decoded before rendering and written back in it. Empty means
+	// EncodingUTF8. Files other than templates are never transcoded.
+	Encoding string `yaml:"encoding,omitempty"`
+	// AllowDangerous lets a folder entry target a system directory or the
+	// home directory, which restore would replace with a symlink; see
+	// DangerousTarget.
+	AllowDangerous bool `yaml:"allow_dangerous,omitempty"`
+
+	// explicit and inherited record which of the fields defaults can set
+	// were declared on the entry and which were filled in from defaults.
+	explicit  entryFields
+	inherited entryFields
+}
+
+// IsEnabled reports whether the sub-entry takes part in restore, backup and
+// state checks. Entries are enabled unless `enabled: false` is set.
+func (s *SubEntry) IsEnabled() bool {
+	return s.Enabled == nil || *s.Enabled
+}
+
+// SetEnabled sets the enabled flag. Enabling clears it rather than writing
+// `enabled: true`, keeping the default out of the saved config.
+func (s *SubEntry) SetEnabled(enabled bool) {
+	s.Enabled = enabledFlag(enabled)
 }
 
 // IsConfig returns true if this is a config type sub-entry
@@ -299,11 +651,45 @@ func (s *SubEntry) GetRun(osType string) string {
 	return s.Run[osType]
 }
 
+// GetPostRestore returns the command to run after restoring the entry on the
+// specified OS, or "" if the entry declares none for it.
+func (s *SubEntry) GetPostRestore(osType string) string {
+	return s.PostRestore[osType]
+}
+
 // IsFolder returns true if this config sub-entry manages an entire folder (no specific files)
 func (s *SubEntry) IsFolder() bool {
 	return s.IsConfig() && len(s.Files) == 0
 }
 
+// LinksFiles reports whether this folder sub-entry is restored as a farm of
+// file symlinks in a real target directory; see LinkModeFiles.
+func (s *SubEntry) LinksFiles() bool {
+	return s.IsFolder() && s.LinkMode == LinkModeFiles
+}
+
+// BackupName returns the name file, a file of the entry relative to its
+// target, has in the backup: its case_rename, or file itself.
+func (s *SubEntry) BackupName(file string) string {
+	if renamed, ok := s.CaseRename[file]; ok {
+		return renamed
+	}
+
+	return file
+}
+
+// TargetName returns the name backupFile, a file of the entry relative to its
+// backup, has in the target, undoing its case_rename.
+func (s *SubEntry) TargetName(backupFile string) string {
+	for file, renamed := range s.CaseRename {
+		if renamed == backupFile {
+			return file
+		}
+	}
+
+	return backupFile
+}
+
 // GetTarget returns the target path for the specified OS
 func (s *SubEntry) GetTarget(osType string) string {
 	if target, ok := s.Targets[osType]; ok {
@@ -328,3 +714,55 @@ func (s *SubEntry) IsCopy() bool { return s.EffectiveMethod() == MethodCopy }
 func (a *Application) HasPackage() bool {
 	return a.Package != nil
 }
+
+// IsEnabled reports whether the application takes part in restore, backup,
+// install and state checks. Unlike When, which is evaluated against the
+// machine, this is a manual switch: applications are enabled unless
+// `enabled: false` is set.
+func (a *Application) IsEnabled() bool {
+	return a.Enabled == nil || *a.Enabled
+}
+
+// SetEnabled sets the enabled flag. Enabling clears it rather than writing
+// `enabled: true`, keeping the default out of the saved config.
+func (a *Application) SetEnabled(enabled bool) {
+	a.Enabled = enabledFlag(enabled)
+}
+
+// ExpandedEnvVars returns EnvVars with $VAR and ${VAR} references in their
+// values expanded from the process environment, or nil when there are none.
+func (a *Application) ExpandedEnvVars() map[string]string {
+	if len(a.EnvVars) == 0 {
+		return nil
+	}
+
+	env := make(map[string]string, len(a.EnvVars))
+	for k, v := range a.EnvVars {
+		env[k] = os.ExpandEnv(v)
+	}
+
+	return env
+}
+
+// EnabledEntries returns the sub-entries that are enabled, in order.
+func (a *Application) EnabledEntries() []SubEntry {
+	entries := make([]SubEntry, 0, len(a.Entries))
+
+	for _, e := range a.Entries {
+		if e.IsEnabled() {
+			entries = append(entries, e)
+		}
+	}
+
+	return entries
+}
+
+// enabledFlag returns the Enabled value for enabled: nil for the default,
+// a pointer to false otherwise.
+func enabledFlag(enabled bool) *bool {
+	if enabled {
+		return nil
+	}
+
+	return &enabled
+}
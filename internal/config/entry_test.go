@@ -87,3 +87,72 @@ entries:
 		t.Errorf("GetRun(windows) = %q, want \"\"", got)
 	}
 }
+
+// TestSubEntry_KindsAreExclusive enumerates every valid sub-entry kind and
+// checks that exactly one of the kind predicates holds. Git repositories are
+// packages (package.managers.git), not sub-entries, so the kinds are config
+// (folder or files) and setup.
+func TestSubEntry_KindsAreExclusive(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name       string
+		entry      SubEntry
+		wantConfig bool
+		wantSetup  bool
+		wantFolder bool
+	}{
+		{
+			name:       "config folder",
+			entry:      SubEntry{Name: "nvim", Backup: "./nvim", Targets: map[string]string{"linux": "~/.config/nvim"}},
+			wantConfig: true,
+			wantFolder: true,
+		},
+		{
+			name:       "config files",
+			entry:      SubEntry{Name: "bash", Backup: "./bash", Files: []string{".bashrc"}, Targets: map[string]string{"linux": "~"}},
+			wantConfig: true,
+		},
+		{
+			name:       "config copy",
+			entry:      SubEntry{Name: "ssh", Backup: "./ssh", Method: MethodCopy, Files: []string{"config"}, Targets: map[string]string{"linux": "~/.ssh"}},
+			wantConfig: true,
+		},
+		{
+			name: "setup",
+			entry: SubEntry{
+				Name:  "shell",
+				Check: map[string]string{"linux": "true"},
+				Run:   map[string]string{"linux": "chsh -s /bin/zsh"},
+			},
+			wantSetup: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			if got := tt.entry.IsConfig(); got != tt.wantConfig {
+				t.Errorf("IsConfig() = %v, want %v", got, tt.wantConfig)
+			}
+
+			if got := tt.entry.IsSetup(); got != tt.wantSetup {
+				t.Errorf("IsSetup() = %v, want %v", got, tt.wantSetup)
+			}
+
+			if got := tt.entry.IsFolder(); got != tt.wantFolder {
+				t.Errorf("IsFolder() = %v, want %v", got, tt.wantFolder)
+			}
+
+			if tt.entry.IsConfig() == tt.entry.IsSetup() {
+				t.Errorf("exactly one of IsConfig()/IsSetup() must be true, got IsConfig=%v IsSetup=%v",
+					tt.entry.IsConfig(), tt.entry.IsSetup())
+			}
+
+			if errs := validateEntryPaths("app", tt.entry); len(errs) > 0 {
+				t.Errorf("entry kind is not valid config: %v", errs)
+			}
+		})
+	}
+}
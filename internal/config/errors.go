@@ -9,8 +9,31 @@ import (
 var (
 	ErrUnsupportedVersion = errors.New("unsupported config version")
 	ErrInvalidConfig      = errors.New("invalid configuration")
+	ErrMergeConflict      = errors.New("applications already exist")
+	ErrConfigNotFound     = errors.New("configuration not found")
 )
 
+// kindError tags err with a sentinel such as ErrInvalidConfig, so errors.Is
+// matches both, while keeping err's message.
+type kindError struct {
+	kind error
+	err  error
+}
+
+func (e *kindError) Error() string { return e.err.Error() }
+
+func (e *kindError) Unwrap() []error { return []error{e.err, e.kind} }
+
+// withKind tags err with kind unless it already matches ErrConfigNotFound or
+// ErrInvalidConfig.
+func withKind(kind, err error) error {
+	if errors.Is(err, ErrConfigNotFound) || errors.Is(err, ErrInvalidConfig) {
+		return err
+	}
+
+	return &kindError{kind: kind, err: err}
+}
+
 // FieldError represents a validation error for a specific field
 type FieldError struct {
 	Err   error
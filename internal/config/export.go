@@ -0,0 +1,62 @@
+package config
+
+import (
+	"fmt"
+)
+
+// ExportApplications returns a standalone config holding only the named
+// applications, in config order, together with the package manager settings
+// they install through. Each application carries its own package definition
+// and `when` filter, and the when_presets it references come along, so the
+// result is a complete tidydots.yaml on its own.
+// Names that match no application are reported together in one error.
+func ExportApplications(cfg *Config, names []string) (*Config, error) {
+	wanted := make(map[string]bool, len(names))
+	for _, name := range names {
+		wanted[name] = true
+	}
+
+	out := &Config{
+		Version:         cfg.Version,
+		DefaultManager:  cfg.DefaultManager,
+		ManagerPriority: cfg.ManagerPriority,
+	}
+
+	found := make(map[string]bool, len(names))
+
+	for _, app := range cfg.Applications {
+		if wanted[app.Name] && !found[app.Name] {
+			found[app.Name] = true
+			out.Applications = append(out.Applications, app)
+
+			for _, preset := range cfg.PresetWhens(&app) {
+				if out.WhenPresets == nil {
+					out.WhenPresets = make(map[string]string)
+				}
+
+				out.WhenPresets[preset.Name] = preset.When
+			}
+		}
+	}
+
+	var missing []string
+
+	for _, name := range names {
+		if !found[name] {
+			missing = append(missing, name)
+			found[name] = true // report each missing name once
+		}
+	}
+
+	if len(missing) > 0 {
+		return nil, fmt.Errorf("applications not found: %s", quoteJoin(missing))
+	}
+
+	return out, nil
+}
+
+// Marshal encodes cfg as tidydots.yaml content. Unlike Save it writes a single
+// document: applications loaded from included files are encoded inline.
+func Marshal(cfg *Config) ([]byte, error) {
+	return marshalYAML(cfg)
+}
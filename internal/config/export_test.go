@@ -0,0 +1,131 @@
+package config
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestExportApplications(t *testing.T) {
+	t.Parallel()
+
+	cfg := &Config{
+		Version:         3,
+		DefaultManager:  "yay",
+		ManagerPriority: []string{"paru", "yay"},
+		Applications: []Application{
+			{Name: "nvim", When: `{{ eq .OS "linux" }}`, Package: &EntryPackage{Managers: map[string]ManagerValue{"pacman": {PackageName: "neovim"}}}},
+			{Name: "zsh"},
+			{Name: "git"},
+		},
+	}
+
+	tests := []struct {
+		name      string
+		names     []string
+		wantApps  []string
+		wantErrIn []string
+	}{
+		{name: "keeps config order", names: []string{"git", "nvim"}, wantApps: []string{"nvim", "git"}},
+		{name: "repeated name exported once", names: []string{"zsh", "zsh"}, wantApps: []string{"zsh"}},
+		{name: "lists every missing name", names: []string{"nvim", "tmux", "kitty"}, wantErrIn: []string{`"tmux"`, `"kitty"`}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := ExportApplications(cfg, tt.names)
+			if len(tt.wantErrIn) > 0 {
+				if err == nil {
+					t.Fatal("ExportApplications() expected error, got nil")
+				}
+
+				for _, want := range tt.wantErrIn {
+					if !strings.Contains(err.Error(), want) {
+						t.Errorf("error %q does not mention %s", err.Error(), want)
+					}
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("ExportApplications() error = %v", err)
+			}
+
+			var names []string
+			for _, app := range got.Applications {
+				names = append(names, app.Name)
+			}
+
+			if strings.Join(names, ",") != strings.Join(tt.wantApps, ",") {
+				t.Errorf("exported %v, want %v", names, tt.wantApps)
+			}
+
+			if got.DefaultManager != "yay" || len(got.ManagerPriority) != 2 {
+				t.Errorf("package manager settings not exported: %q %v", got.DefaultManager, got.ManagerPriority)
+			}
+		})
+	}
+}
+
+func TestExportApplications_RoundTrip(t *testing.T) {
+	t.Parallel()
+	dir := t.TempDir()
+
+	mainPath := writeTestFile(t, dir, "tidydots.yaml", `version: 3
+include: [apps/*.yaml]
+applications:
+  - name: zsh
+    entries:
+      - name: rc
+        backup: ./zsh
+        files: [.zshrc]
+        targets: {linux: "~"}
+`)
+	writeTestFile(t, dir, "apps/nvim.yaml", `applications:
+  - name: nvim
+    when: '{{ eq .OS "linux" }}'
+    entries:
+      - name: config
+        backup: ./nvim
+        targets: {linux: ~/.config/nvim}
+    package:
+      managers:
+        pacman: neovim
+`)
+
+	cfg, err := Load(mainPath)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+
+	exported, err := ExportApplications(cfg, []string{"nvim"})
+	if err != nil {
+		t.Fatalf("ExportApplications() error = %v", err)
+	}
+
+	data, err := Marshal(exported)
+	if err != nil {
+		t.Fatalf("Marshal() error = %v", err)
+	}
+
+	if strings.Contains(string(data), "include") {
+		t.Errorf("export should be standalone, got:\n%s", data)
+	}
+
+	reloaded, err := Load(writeTestFile(t, t.TempDir(), filepath.Join("out", "tidydots.yaml"), string(data)))
+	if err != nil {
+		t.Fatalf("Load(exported) error = %v\n%s", err, data)
+	}
+
+	if len(reloaded.Applications) != 1 {
+		t.Fatalf("reloaded %d applications, want 1", len(reloaded.Applications))
+	}
+
+	app := reloaded.Applications[0]
+	if app.Name != "nvim" || app.When == "" || !app.HasPackage() || len(app.Entries) != 1 {
+		t.Errorf("reloaded application lost data: %+v", app)
+	}
+}
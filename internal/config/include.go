@@ -0,0 +1,261 @@
+package config
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// includeFile is the on-disk shape of a file pulled in via Config.Include.
+// It contributes applications and, optionally, the package manager settings
+// that would otherwise live in the main file.
+type includeFile struct {
+	DefaultManager  string        `yaml:"default_manager,omitempty"`
+	ManagerPriority []string      `yaml:"manager_priority,omitempty"`
+	Applications    []Application `yaml:"applications"`
+}
+
+// hasPackageSettings reports whether the file declares package manager settings.
+func (f *includeFile) hasPackageSettings() bool {
+	return f.DefaultManager != "" || len(f.ManagerPriority) > 0
+}
+
+// resolveIncludePath resolves an include path or pattern relative to the
+// directory holding the main config file.
+func resolveIncludePath(baseDir, path string) string {
+	path = ExpandPath(path, nil)
+	if filepath.IsAbs(path) {
+		return filepath.Clean(path)
+	}
+
+	return filepath.Join(baseDir, path)
+}
+
+// hasGlobMeta reports whether a pattern contains glob metacharacters.
+func hasGlobMeta(pattern string) bool {
+	return strings.ContainsAny(pattern, "*?[")
+}
+
+// expandIncludes resolves the include patterns of a config loaded from
+// mainPath into a sorted, de-duplicated list of absolute file paths. A literal
+// path that does not exist is an error; a glob matching nothing is not, so an
+// `apps/*.yaml` pattern may point at a directory that is still empty.
+func expandIncludes(mainPath string, patterns []string) ([]string, error) {
+	baseDir := filepath.Dir(mainPath)
+	mainAbs := filepath.Clean(mainPath)
+	seen := make(map[string]bool)
+
+	var files []string
+
+	for _, pattern := range patterns {
+		resolved := resolveIncludePath(baseDir, pattern)
+
+		matches, err := filepath.Glob(resolved)
+		if err != nil {
+			return nil, fmt.Errorf("%w: invalid include pattern %q: %w", ErrInvalidConfig, pattern, err)
+		}
+
+		if len(matches) == 0 {
+			if !hasGlobMeta(pattern) {
+				return nil, fmt.Errorf("%w: included file %q does not exist", ErrInvalidConfig, pattern)
+			}
+
+			slog.Debug("include pattern matched no files", slog.String("pattern", pattern))
+
+			continue
+		}
+
+		for _, match := range matches {
+			match = filepath.Clean(match)
+			if match == mainAbs || seen[match] {
+				continue
+			}
+
+			seen[match] = true
+			files = append(files, match)
+		}
+	}
+
+	slices.Sort(files)
+
+	return files, nil
+}
+
+// displayPath returns path relative to baseDir for use in error messages,
+// falling back to the path itself when it lies elsewhere.
+func displayPath(baseDir, path string) string {
+	if rel, err := filepath.Rel(baseDir, path); err == nil && !strings.HasPrefix(rel, "..") {
+		return rel
+	}
+
+	return path
+}
+
+// loadIncludes reads every file named by cfg.Include and merges its
+// applications into cfg. Application names must be unique across all files,
+// and at most one file may declare package manager settings; both conflicts
+// are reported with the names of the two files involved.
+func loadIncludes(cfg *Config, mainPath string) error {
+	mainPath = filepath.Clean(mainPath)
+	baseDir := filepath.Dir(mainPath)
+
+	if cfg.DefaultInclude != "" && !matchesAnyInclude(baseDir, cfg.Include, cfg.DefaultInclude) {
+		return fmt.Errorf("%w: default_include %q is not matched by any include pattern",
+			ErrInvalidConfig, cfg.DefaultInclude)
+	}
+
+	origins := make(map[string]string, len(cfg.Applications))
+	for i := range cfg.Applications {
+		cfg.Applications[i].Source = mainPath
+		origins[cfg.Applications[i].Name] = mainPath
+	}
+
+	settingsFile := ""
+	if cfg.DefaultManager != "" || len(cfg.ManagerPriority) > 0 {
+		settingsFile = mainPath
+	}
+
+	files, err := expandIncludes(mainPath, cfg.Include)
+	if err != nil {
+		return err
+	}
+
+	for _, file := range files {
+		data, err := os.ReadFile(file) //nolint:gosec // path is from user config, intentional
+		if err != nil {
+			return fmt.Errorf("reading included file %s: %w", displayPath(baseDir, file), err)
+		}
+
+		var inc includeFile
+		if err := yaml.Unmarshal(data, &inc); err != nil {
+			return fmt.Errorf("parsing included file %s: %w", displayPath(baseDir, file), err)
+		}
+
+		if inc.hasPackageSettings() {
+			if settingsFile != "" {
+				return fmt.Errorf("%w: package manager settings declared in both %s and %s",
+					ErrInvalidConfig, displayPath(baseDir, settingsFile), displayPath(baseDir, file))
+			}
+
+			settingsFile = file
+			cfg.DefaultManager = inc.DefaultManager
+			cfg.ManagerPriority = inc.ManagerPriority
+			cfg.settingsSource = file
+		}
+
+		for _, app := range inc.Applications {
+			if prev, ok := origins[app.Name]; ok && app.Name != "" {
+				return fmt.Errorf("%w: duplicate application name %q in %s and %s",
+					ErrInvalidConfig, app.Name, displayPath(baseDir, prev), displayPath(baseDir, file))
+			}
+
+			origins[app.Name] = file
+			app.Source = file
+			cfg.Applications = append(cfg.Applications, app)
+		}
+
+		cfg.includedFiles = append(cfg.includedFiles, file)
+	}
+
+	return nil
+}
+
+// matchesAnyInclude reports whether path, resolved against baseDir, is
+// matched by one of the include patterns.
+func matchesAnyInclude(baseDir string, patterns []string, path string) bool {
+	target := resolveIncludePath(baseDir, path)
+
+	for _, pattern := range patterns {
+		if ok, err := filepath.Match(resolveIncludePath(baseDir, pattern), target); err == nil && ok {
+			return true
+		}
+	}
+
+	return false
+}
+
+// splitBySource groups cfg's applications by the file they belong to. It
+// returns the applications that belong in the main file and, separately, the
+// applications of every included file (keyed by absolute path, including files
+// that are now empty). Applications without a recorded source (newly added
+// ones) are assigned to DefaultInclude when set, otherwise to the main file.
+// Packages of the packages file are left out; see packageDefs.
+func splitBySource(cfg *Config, mainPath string) ([]Application, map[string][]Application) {
+	mainPath = filepath.Clean(mainPath)
+
+	byFile := make(map[string][]Application, len(cfg.includedFiles)+1)
+	for _, file := range cfg.includedFiles {
+		byFile[file] = []Application{}
+	}
+
+	defaultFile := mainPath
+	if cfg.DefaultInclude != "" {
+		defaultFile = resolveIncludePath(filepath.Dir(mainPath), cfg.DefaultInclude)
+	}
+
+	var mainApps []Application
+
+	for i := range cfg.Applications {
+		app := &cfg.Applications[i]
+		if cfg.packagesFile != "" && app.Source == cfg.packagesFile {
+			continue
+		}
+
+		if app.Source == "" {
+			app.Source = defaultFile
+		}
+
+		_, included := byFile[app.Source]
+		if included || (app.Source == defaultFile && defaultFile != mainPath) {
+			byFile[app.Source] = append(byFile[app.Source], *app)
+		} else {
+			mainApps = append(mainApps, *app)
+		}
+	}
+
+	return mainApps, byFile
+}
+
+// writeIncludes writes the applications of each included file back to disk
+// with writeFile, along with the package manager settings for the file that
+// declared them.
+func writeIncludes(cfg *Config, byFile map[string][]Application, writeFile func(string, []byte, os.FileMode) error) error {
+	files := make([]string, 0, len(byFile))
+	for file := range byFile {
+		files = append(files, file)
+	}
+
+	slices.Sort(files)
+
+	for _, file := range files {
+		inc := includeFile{Applications: byFile[file]}
+		if file == cfg.settingsSource {
+			inc.DefaultManager = cfg.DefaultManager
+			inc.ManagerPriority = cfg.ManagerPriority
+		}
+
+		data, err := marshalYAML(inc)
+		if err != nil {
+			return fmt.Errorf("marshaling included file %s: %w", file, err)
+		}
+
+		if err := os.MkdirAll(filepath.Dir(file), 0750); err != nil {
+			return fmt.Errorf("creating directory for included file %s: %w", file, err)
+		}
+
+		if err := writeFile(file, data, 0600); err != nil {
+			return fmt.Errorf("writing included file %s: %w", file, err)
+		}
+
+		if !slices.Contains(cfg.includedFiles, file) {
+			cfg.includedFiles = append(cfg.includedFiles, file)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,304 @@
+package config
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// writeTestFile writes content to dir/name, creating parent directories.
+func writeTestFile(t *testing.T, dir, name, content string) string {
+	t.Helper()
+
+	path := filepath.Join(dir, name)
+	if err := os.MkdirAll(filepath.Dir(path), 0750); err != nil {
+		t.Fatalf("creating directory for %s: %v", name, err)
+	}
+
+	if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatalf("writing %s: %v", name, err)
+	}
+
+	return path
+}
+
+func appNames(apps []Application) []string {
+	names := make([]string, 0, len(apps))
+	for _, app := range apps {
+		names = append(names, app.Name)
+	}
+
+	return names
+}
+
+func TestLoadIncludes_MergesInSortedOrder(t *testing.T) {
+	t.Parallel()
+	dir := t.TempDir()
+
+	mainPath := writeTestFile(t, dir, "tidydots.yaml", `version: 3
+include:
+  - apps/*.yaml
+applications:
+  - name: main-app
+    entries: []
+`)
+	writeTestFile(t, dir, "apps/zsh.yaml", `applications:
+  - name: zsh
+    entries: []
+`)
+	writeTestFile(t, dir, "apps/bash.yaml", `manager_priority: [paru, pacman]
+applications:
+  - name: bash
+    entries: []
+  - name: bash-extra
+    entries: []
+`)
+
+	cfg, err := Load(mainPath)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+
+	got := strings.Join(appNames(cfg.Applications), ",")
+	if want := "main-app,bash,bash-extra,zsh"; got != want {
+		t.Errorf("application order = %s, want %s", got, want)
+	}
+
+	if len(cfg.ManagerPriority) != 2 || cfg.ManagerPriority[0] != "paru" {
+		t.Errorf("ManagerPriority = %v, want [paru pacman]", cfg.ManagerPriority)
+	}
+
+	if src := cfg.Applications[1].Source; src != filepath.Join(dir, "apps", "bash.yaml") {
+		t.Errorf("bash Source = %q, want apps/bash.yaml", src)
+	}
+
+	if src := cfg.Applications[0].Source; src != mainPath {
+		t.Errorf("main-app Source = %q, want %q", src, mainPath)
+	}
+}
+
+func TestLoadIncludes_DuplicateNameNamesBothFiles(t *testing.T) {
+	t.Parallel()
+	dir := t.TempDir()
+
+	mainPath := writeTestFile(t, dir, "tidydots.yaml", `version: 3
+include: [apps/*.yaml]
+`)
+	writeTestFile(t, dir, "apps/a.yaml", "applications:\n  - name: nvim\n    entries: []\n")
+	writeTestFile(t, dir, "apps/b.yaml", "applications:\n  - name: nvim\n    entries: []\n")
+
+	_, err := Load(mainPath)
+	if err == nil {
+		t.Fatal("Load() expected duplicate name error, got nil")
+	}
+
+	if !errors.Is(err, ErrInvalidConfig) {
+		t.Errorf("error = %v, want ErrInvalidConfig", err)
+	}
+
+	for _, want := range []string{`"nvim"`, filepath.Join("apps", "a.yaml"), filepath.Join("apps", "b.yaml")} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("error %q does not mention %s", err.Error(), want)
+		}
+	}
+}
+
+func TestLoadIncludes_ConflictWithMainFile(t *testing.T) {
+	t.Parallel()
+	dir := t.TempDir()
+
+	mainPath := writeTestFile(t, dir, "tidydots.yaml", `version: 3
+include: [extra.yaml]
+applications:
+  - name: git
+    entries: []
+`)
+	writeTestFile(t, dir, "extra.yaml", "applications:\n  - name: git\n    entries: []\n")
+
+	_, err := Load(mainPath)
+	if err == nil || !strings.Contains(err.Error(), "tidydots.yaml and extra.yaml") {
+		t.Fatalf("Load() error = %v, want conflict naming tidydots.yaml and extra.yaml", err)
+	}
+}
+
+func TestLoadIncludes_MissingFiles(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		include string
+		wantErr bool
+	}{
+		{name: "glob matching nothing is allowed", include: "apps/*.yaml", wantErr: false},
+		{name: "missing literal file is an error", include: "apps/missing.yaml", wantErr: true},
+		{name: "malformed pattern is an error", include: "apps/[.yaml", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			dir := t.TempDir()
+			mainPath := writeTestFile(t, dir, "tidydots.yaml", "version: 3\ninclude: ['"+tt.include+"']\n")
+
+			_, err := Load(mainPath)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Load() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestLoadIncludes_PackageSettingsConflict(t *testing.T) {
+	t.Parallel()
+	dir := t.TempDir()
+
+	mainPath := writeTestFile(t, dir, "tidydots.yaml", `version: 3
+default_manager: yay
+include: [pkgs.yaml]
+`)
+	writeTestFile(t, dir, "pkgs.yaml", "default_manager: paru\napplications: []\n")
+
+	if _, err := Load(mainPath); err == nil {
+		t.Fatal("Load() expected package settings conflict, got nil")
+	}
+}
+
+func TestLoadIncludes_DefaultIncludeMustBeMatched(t *testing.T) {
+	t.Parallel()
+	dir := t.TempDir()
+
+	mainPath := writeTestFile(t, dir, "tidydots.yaml", `version: 3
+include: [apps/*.yaml]
+default_include: other/new.yaml
+`)
+
+	if _, err := Load(mainPath); err == nil {
+		t.Fatal("Load() expected error for unmatched default_include, got nil")
+	}
+}
+
+func TestSave_WritesApplicationsBackToSourceFiles(t *testing.T) {
+	t.Parallel()
+	dir := t.TempDir()
+
+	mainPath := writeTestFile(t, dir, "tidydots.yaml", `version: 3
+include: [apps/*.yaml]
+applications:
+  - name: main-app
+    entries: []
+`)
+	incPath := writeTestFile(t, dir, "apps/shell.yaml", "applications:\n  - name: zsh\n    entries: []\n")
+
+	cfg, err := Load(mainPath)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+
+	// Edit an included application and add a new one.
+	cfg.Applications[1].Description = "edited"
+	cfg.Applications = append(cfg.Applications, Application{Name: "new-app", Entries: []SubEntry{}})
+
+	if err := Save(cfg, mainPath); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	mainData, err := os.ReadFile(mainPath) //nolint:gosec // test path
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	incData, err := os.ReadFile(incPath) //nolint:gosec // test path
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if strings.Contains(string(mainData), "zsh") {
+		t.Errorf("main file contains included application:\n%s", mainData)
+	}
+
+	if !strings.Contains(string(mainData), "new-app") {
+		t.Errorf("main file missing new application without default_include:\n%s", mainData)
+	}
+
+	if !strings.Contains(string(incData), "edited") {
+		t.Errorf("included file missing edit:\n%s", incData)
+	}
+
+	reloaded, err := Load(mainPath)
+	if err != nil {
+		t.Fatalf("Load() after Save() error = %v", err)
+	}
+
+	if got := strings.Join(appNames(reloaded.Applications), ","); got != "main-app,new-app,zsh" {
+		t.Errorf("reloaded applications = %s, want main-app,new-app,zsh", got)
+	}
+}
+
+func TestSave_NewApplicationsGoToDefaultInclude(t *testing.T) {
+	t.Parallel()
+	dir := t.TempDir()
+
+	mainPath := writeTestFile(t, dir, "tidydots.yaml", `version: 3
+include: [apps/*.yaml]
+default_include: apps/new.yaml
+`)
+
+	cfg, err := Load(mainPath)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+
+	cfg.Applications = append(cfg.Applications, Application{Name: "added", Entries: []SubEntry{}})
+
+	if err := Save(cfg, mainPath); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	data, err := os.ReadFile(filepath.Join(dir, "apps", "new.yaml")) //nolint:gosec // test path
+	if err != nil {
+		t.Fatalf("default include file not written: %v", err)
+	}
+
+	if !strings.Contains(string(data), "added") {
+		t.Errorf("default include file missing new application:\n%s", data)
+	}
+
+	reloaded, err := Load(mainPath)
+	if err != nil {
+		t.Fatalf("Load() after Save() error = %v", err)
+	}
+
+	if len(reloaded.Applications) != 1 || reloaded.Applications[0].Source != filepath.Join(dir, "apps", "new.yaml") {
+		t.Errorf("reloaded applications = %+v, want one app from apps/new.yaml", reloaded.Applications)
+	}
+}
+
+func TestSave_PackageSettingsStayInIncludedFile(t *testing.T) {
+	t.Parallel()
+	dir := t.TempDir()
+
+	mainPath := writeTestFile(t, dir, "tidydots.yaml", "version: 3\ninclude: [pkgs.yaml]\n")
+	incPath := writeTestFile(t, dir, "pkgs.yaml", "default_manager: paru\napplications: []\n")
+
+	cfg, err := Load(mainPath)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+
+	if err := Save(cfg, mainPath); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	mainData, _ := os.ReadFile(mainPath) //nolint:gosec // test path
+	incData, _ := os.ReadFile(incPath)   //nolint:gosec // test path
+
+	if strings.Contains(string(mainData), "default_manager") {
+		t.Errorf("main file gained default_manager:\n%s", mainData)
+	}
+
+	if !strings.Contains(string(incData), "default_manager: paru") {
+		t.Errorf("included file lost default_manager:\n%s", incData)
+	}
+}
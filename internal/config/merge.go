@@ -0,0 +1,96 @@
+package config
+
+import (
+	"fmt"
+	"slices"
+)
+
+// MergeStrategy decides what MergeConfigs does with an incoming application
+// whose name is already taken in the base config.
+type MergeStrategy string
+
+// Supported merge strategies.
+const (
+	// MergeError rejects the whole merge if any name conflicts.
+	MergeError MergeStrategy = "error"
+	// MergeSkip keeps the base application and drops the incoming one.
+	MergeSkip MergeStrategy = "skip"
+	// MergeOverwrite replaces the base application with the incoming one.
+	MergeOverwrite MergeStrategy = "overwrite"
+)
+
+// ParseMergeStrategy converts a strategy name, as given on the command line,
+// into a MergeStrategy.
+func ParseMergeStrategy(s string) (MergeStrategy, error) {
+	switch strategy := MergeStrategy(s); strategy {
+	case MergeError, MergeSkip, MergeOverwrite:
+		return strategy, nil
+	default:
+		return "", fmt.Errorf("unknown merge strategy %q (expected error, skip or overwrite)", s)
+	}
+}
+
+// MergeConflict is an incoming application whose name is already taken in the
+// base config.
+type MergeConflict struct {
+	Existing Application
+	Incoming Application
+	Name     string
+}
+
+// MergeConfigs returns a copy of base with the applications of incoming added
+// after its own, in incoming's order. Conflicting names are resolved by
+// strategy and always returned; with MergeError any conflict fails the merge
+// with ErrMergeConflict. An overwritten application keeps its position and the
+// file it was loaded from, so Save writes it back in place. Only applications
+// are merged: base keeps its version and package manager settings.
+func MergeConfigs(base, incoming *Config, strategy MergeStrategy) (*Config, []MergeConflict, error) {
+	if _, err := ParseMergeStrategy(string(strategy)); err != nil {
+		return nil, nil, err
+	}
+
+	merged := *base
+	merged.Applications = slices.Clone(base.Applications)
+
+	index := make(map[string]int, len(merged.Applications))
+	for i, app := range merged.Applications {
+		index[app.Name] = i
+	}
+
+	var conflicts []MergeConflict
+
+	for _, app := range incoming.Applications {
+		// The incoming application now belongs to base's files.
+		app.Source = ""
+
+		i, exists := index[app.Name]
+		if !exists {
+			index[app.Name] = len(merged.Applications)
+			merged.Applications = append(merged.Applications, app)
+
+			continue
+		}
+
+		conflicts = append(conflicts, MergeConflict{
+			Name:     app.Name,
+			Existing: merged.Applications[i],
+			Incoming: app,
+		})
+
+		if strategy == MergeOverwrite {
+			app.Source = merged.Applications[i].Source
+			merged.Applications[i] = app
+		}
+	}
+
+	if strategy == MergeError && len(conflicts) > 0 {
+		names := make([]string, len(conflicts))
+		for i, c := range conflicts {
+			names[i] = c.Name
+		}
+
+		return nil, conflicts, fmt.Errorf("%w: %s", ErrMergeConflict, quoteJoin(names))
+	}
+
+	return &merged, conflicts, nil
+}
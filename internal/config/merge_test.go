@@ -0,0 +1,162 @@
+package config
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestMergeConfigs(t *testing.T) {
+	t.Parallel()
+
+	base := &Config{
+		Version:        3,
+		DefaultManager: "yay",
+		Applications: []Application{
+			{Name: "nvim", Description: "base", Source: "/repo/apps/editors.yaml"},
+			{Name: "zsh", Description: "base"},
+		},
+	}
+
+	incoming := &Config{
+		Version:        3,
+		DefaultManager: "apt",
+		Applications: []Application{
+			{Name: "tmux", Description: "incoming", Source: "/shared/tmux.yaml"},
+			{Name: "nvim", Description: "incoming"},
+		},
+	}
+
+	tests := []struct {
+		strategy     MergeStrategy
+		wantApps     string // name:description, in order
+		wantNvimFrom string // Source of the merged nvim
+		wantErr      bool
+	}{
+		{strategy: MergeError, wantErr: true},
+		{strategy: MergeSkip, wantApps: "nvim:base zsh:base tmux:incoming", wantNvimFrom: "/repo/apps/editors.yaml"},
+		{strategy: MergeOverwrite, wantApps: "nvim:incoming zsh:base tmux:incoming", wantNvimFrom: "/repo/apps/editors.yaml"},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.strategy), func(t *testing.T) {
+			t.Parallel()
+
+			merged, conflicts, err := MergeConfigs(base, incoming, tt.strategy)
+
+			if len(conflicts) != 1 || conflicts[0].Name != "nvim" ||
+				conflicts[0].Existing.Description != "base" || conflicts[0].Incoming.Description != "incoming" {
+				t.Errorf("conflicts = %+v, want one for nvim", conflicts)
+			}
+
+			if tt.wantErr {
+				if !errors.Is(err, ErrMergeConflict) || !strings.Contains(err.Error(), `"nvim"`) {
+					t.Errorf("MergeConfigs() error = %v, want ErrMergeConflict naming nvim", err)
+				}
+
+				if merged != nil {
+					t.Error("expected no merged config on error")
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("MergeConfigs() error = %v", err)
+			}
+
+			var got []string
+			for _, app := range merged.Applications {
+				got = append(got, app.Name+":"+app.Description)
+
+				switch app.Name {
+				case "nvim":
+					if app.Source != tt.wantNvimFrom {
+						t.Errorf("nvim Source = %q, want %q", app.Source, tt.wantNvimFrom)
+					}
+				case "tmux":
+					if app.Source != "" {
+						t.Errorf("imported tmux Source = %q, want empty", app.Source)
+					}
+				}
+			}
+
+			if strings.Join(got, " ") != tt.wantApps {
+				t.Errorf("merged applications = %q, want %q", strings.Join(got, " "), tt.wantApps)
+			}
+
+			if merged.DefaultManager != "yay" {
+				t.Errorf("DefaultManager = %q, want base's yay", merged.DefaultManager)
+			}
+
+			if len(base.Applications) != 2 || base.Applications[0].Description != "base" {
+				t.Errorf("base was modified: %+v", base.Applications)
+			}
+		})
+	}
+}
+
+func TestMergeConfigs_UnknownStrategy(t *testing.T) {
+	t.Parallel()
+
+	if _, _, err := MergeConfigs(&Config{}, &Config{}, "replace"); err == nil {
+		t.Error("expected error for unknown strategy")
+	}
+
+	if _, err := ParseMergeStrategy("skip"); err != nil {
+		t.Errorf("ParseMergeStrategy(skip) error = %v", err)
+	}
+}
+
+func TestSaveAtomic(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	repoFile := filepath.Join(dir, "repo", "tidydots.yaml")
+	link := filepath.Join(dir, "tidydots.yaml")
+
+	if err := os.MkdirAll(filepath.Dir(repoFile), 0o750); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := os.WriteFile(repoFile, []byte("version: 3\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := os.Symlink(repoFile, link); err != nil {
+		t.Skipf("symlinks not supported: %v", err)
+	}
+
+	cfg := &Config{Version: 3, Applications: []Application{{Name: "nvim"}}}
+	if err := SaveAtomic(cfg, link); err != nil {
+		t.Fatalf("SaveAtomic() error = %v", err)
+	}
+
+	if fi, err := os.Lstat(link); err != nil || fi.Mode()&os.ModeSymlink == 0 {
+		t.Errorf("config symlink was replaced: %v", err)
+	}
+
+	loaded, err := Load(repoFile)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+
+	if len(loaded.Applications) != 1 || loaded.Applications[0].Name != "nvim" {
+		t.Errorf("saved applications = %+v, want nvim", loaded.Applications)
+	}
+
+	entries, err := os.ReadDir(filepath.Dir(repoFile))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(entries) != 1 {
+		t.Errorf("temporary files left behind: %v", entries)
+	}
+
+	if err := SaveAtomic(&Config{Applications: []Application{{Name: "a"}, {Name: "a"}}}, link); err == nil {
+		t.Error("SaveAtomic() should refuse duplicate names")
+	}
+}
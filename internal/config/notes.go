@@ -0,0 +1,51 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// ReadNotes returns the application's notes: Notes, or the contents of
+// NotesFile, relative to backupRoot. It returns "" when the application has
+// neither.
+func (a *Application) ReadNotes(backupRoot string) (string, error) {
+	if a.NotesFile == "" {
+		return a.Notes, nil
+	}
+
+	path := filepath.Join(ExpandPath(backupRoot, nil), filepath.FromSlash(a.NotesFile))
+
+	data, err := os.ReadFile(path) //nolint:gosec // path is inside the repo, checked by validateNotes
+	if err != nil {
+		return "", fmt.Errorf("reading notes of %s: %w", a.Name, err)
+	}
+
+	return string(data), nil
+}
+
+// validateNotes reports an application with both notes and notes_file, and
+// a notes_file that is not a path inside the repository.
+func validateNotes(app Application) []error {
+	if app.NotesFile == "" {
+		return nil
+	}
+
+	var errs []error
+
+	if app.Notes != "" {
+		errs = append(errs, NewFieldError(app.Name, "notes_file", app.NotesFile,
+			errors.New("cannot be combined with notes")))
+	}
+
+	clean := filepath.ToSlash(filepath.Clean(filepath.FromSlash(app.NotesFile)))
+	if filepath.IsAbs(app.NotesFile) || strings.HasPrefix(clean, "/") || strings.HasPrefix(app.NotesFile, "~") ||
+		clean == ".." || strings.HasPrefix(clean, "../") {
+		errs = append(errs, NewFieldError(app.Name, "notes_file", app.NotesFile,
+			errors.New("must be a path inside the repository")))
+	}
+
+	return errs
+}
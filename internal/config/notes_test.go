@@ -0,0 +1,111 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestReadNotes(t *testing.T) {
+	t.Parallel()
+
+	root := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(root, "notes"), 0o750); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := os.WriteFile(filepath.Join(root, "notes", "office.md"), []byte("# Office\nLicense key in the vault.\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	inline := Application{Name: "kitty", Notes: "Pick the theme by hand."}
+	if got, err := inline.ReadNotes(root); err != nil || got != "Pick the theme by hand." {
+		t.Errorf("ReadNotes(notes) = %q, %v", got, err)
+	}
+
+	file := Application{Name: "office", NotesFile: "notes/office.md"}
+	if got, err := file.ReadNotes(root); err != nil || got != "# Office\nLicense key in the vault.\n" {
+		t.Errorf("ReadNotes(notes_file) = %q, %v", got, err)
+	}
+
+	missing := Application{Name: "office", NotesFile: "notes/missing.md"}
+	if _, err := missing.ReadNotes(root); err == nil {
+		t.Error("ReadNotes() of a missing notes_file error = nil, want an error")
+	}
+
+	var none Application
+	if got, err := none.ReadNotes(root); err != nil || got != "" {
+		t.Errorf("ReadNotes() without notes = %q, %v, want empty", got, err)
+	}
+}
+
+func TestValidateConfig_Notes(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		app     Application
+		wantErr string
+	}{
+		{name: "notes", app: Application{Name: "a", Notes: "line one\nline two"}},
+		{name: "notes file", app: Application{Name: "a", NotesFile: "notes/a.md"}},
+		{name: "both", app: Application{Name: "a", Notes: "x", NotesFile: "notes/a.md"}, wantErr: "cannot be combined with notes"},
+		{name: "outside the repo", app: Application{Name: "a", NotesFile: "../a.md"}, wantErr: "inside the repository"},
+		{name: "absolute", app: Application{Name: "a", NotesFile: "/etc/a.md"}, wantErr: "inside the repository"},
+		{name: "home", app: Application{Name: "a", NotesFile: "~/a.md"}, wantErr: "inside the repository"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			errs := ValidateConfig(&Config{Version: 3, Applications: []Application{tt.app}})
+
+			if tt.wantErr == "" {
+				if len(errs) > 0 {
+					t.Errorf("ValidateConfig() = %v, want no errors", errs)
+				}
+
+				return
+			}
+
+			if len(errs) != 1 || !strings.Contains(errs[0].Error(), tt.wantErr) {
+				t.Errorf("ValidateConfig() = %v, want one error containing %q", errs, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestSave_NotesBlockStyle(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "tidydots.yaml")
+	cfg := &Config{Version: 3, Applications: []Application{{
+		Name:    "office",
+		Notes:   "Activate with the key from the vault.\nThen disable telemetry.\n",
+		Entries: []SubEntry{},
+	}}}
+
+	if err := Save(cfg, path); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !strings.Contains(string(data), "notes: |\n      Activate with the key from the vault.\n      Then disable telemetry.\n") {
+		t.Errorf("notes are not written as a literal block:\n%s", data)
+	}
+
+	loaded, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+
+	if got := loaded.Applications[0].Notes; got != cfg.Applications[0].Notes {
+		t.Errorf("round-trip notes = %q, want %q", got, cfg.Applications[0].Notes)
+	}
+}
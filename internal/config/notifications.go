@@ -0,0 +1,74 @@
+package config
+
+import (
+	"fmt"
+	"net/url"
+	"time"
+)
+
+// Values of Notifications.On: when a run is reported.
+const (
+	// NotifyOnFailure reports only runs with at least one failure (default).
+	NotifyOnFailure = "failure"
+	// NotifyAlways reports every run.
+	NotifyAlways = "always"
+)
+
+// DefaultNotifyTimeout bounds each notification delivery when no timeout is
+// configured, so a slow hook never holds up the run for long.
+const DefaultNotifyTimeout = 5 * time.Second
+
+// Notifications configures the report sent when an unattended backup or
+// restore finishes. Command and Webhook may both be set; each receives the
+// same summary of the run.
+type Notifications struct {
+	On      string `yaml:"on,omitempty"`      // failure (default) or always
+	Command string `yaml:"command,omitempty"` // shell command, rendered as a template with the run summary
+	Webhook string `yaml:"webhook,omitempty"` // URL the run summary is POSTed to as JSON
+	Timeout string `yaml:"timeout,omitempty"` // per delivery, e.g. 3s; default DefaultNotifyTimeout
+}
+
+// ShouldNotify reports whether a run is reported, given whether it failed.
+func (n *Notifications) ShouldNotify(failed bool) bool {
+	return failed || n.On == NotifyAlways
+}
+
+// TimeoutDuration returns the delivery timeout, DefaultNotifyTimeout when
+// none (or an invalid one) is set.
+func (n *Notifications) TimeoutDuration() time.Duration {
+	if d, err := time.ParseDuration(n.Timeout); err == nil && d > 0 {
+		return d
+	}
+
+	return DefaultNotifyTimeout
+}
+
+// validateNotifications checks the notifications section of the config.
+func validateNotifications(n *Notifications) []error {
+	if n == nil {
+		return nil
+	}
+
+	var errs []error
+
+	if n.On != "" && n.On != NotifyOnFailure && n.On != NotifyAlways {
+		errs = append(errs, NewFieldError("notifications", "on", n.On,
+			fmt.Errorf("must be %q or %q", NotifyOnFailure, NotifyAlways)))
+	}
+
+	if n.Webhook != "" {
+		if u, err := url.Parse(n.Webhook); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			errs = append(errs, NewFieldError("notifications", "webhook", n.Webhook,
+				fmt.Errorf("must be an http or https URL")))
+		}
+	}
+
+	if n.Timeout != "" {
+		if d, err := time.ParseDuration(n.Timeout); err != nil || d <= 0 {
+			errs = append(errs, NewFieldError("notifications", "timeout", n.Timeout,
+				fmt.Errorf("must be a positive duration, e.g. 5s")))
+		}
+	}
+
+	return errs
+}
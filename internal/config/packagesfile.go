@@ -0,0 +1,161 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"gopkg.in/yaml.v3"
+)
+
+// packagesFile is the on-disk shape of the file named by Config.PackagesFile:
+// a list of packages, meant to be shared by several configuration repos.
+type packagesFile struct {
+	Packages []packageDef `yaml:"packages"`
+}
+
+// packageDef is one package of a packages file: its name, description and
+// when condition next to the keys of an application's package. It is loaded
+// as an application with no entries, named after the package.
+type packageDef struct {
+	Name        string       `yaml:"name"`
+	Description string       `yaml:"description,omitempty"`
+	When        string       `yaml:"when,omitempty"`
+	Package     EntryPackage `yaml:"-"`
+}
+
+// packageDefYAML is packageDef without its YAML methods.
+type packageDefYAML packageDef
+
+// UnmarshalYAML decodes the package's name, description and when condition,
+// then the rest of its keys as an EntryPackage.
+func (d *packageDef) UnmarshalYAML(node *yaml.Node) error {
+	var head packageDefYAML
+	if err := node.Decode(&head); err != nil {
+		return err
+	}
+
+	*d = packageDef(head)
+
+	if err := node.Decode(&d.Package); err != nil {
+		return err
+	}
+
+	// A package of the file is an application, so its when is the
+	// application's; it was decoded into head above.
+	d.Package.When = ""
+
+	return nil
+}
+
+// MarshalYAML writes the package's name, description and when condition
+// followed by the keys of its EntryPackage, in one mapping.
+func (d packageDef) MarshalYAML() (any, error) {
+	var node, pkg yaml.Node
+	if err := node.Encode(packageDefYAML(d)); err != nil {
+		return nil, err
+	}
+
+	if err := pkg.Encode(d.Package); err != nil {
+		return nil, err
+	}
+
+	node.Content = append(node.Content, pkg.Content...)
+
+	return &node, nil
+}
+
+// loadPackagesFile reads the file named by cfg.PackagesFile, resolved like an
+// include, and appends one application per package to cfg. A package named
+// like an application already loaded, or like another package of the file,
+// is an error naming both files.
+func loadPackagesFile(cfg *Config, mainPath string) error {
+	if cfg.PackagesFile == "" {
+		return nil
+	}
+
+	baseDir := filepath.Dir(filepath.Clean(mainPath))
+	file := resolveIncludePath(baseDir, cfg.PackagesFile)
+
+	data, err := os.ReadFile(file) //nolint:gosec // path is from user config, intentional
+	if err != nil {
+		return fmt.Errorf("reading packages file %s: %w", displayPath(baseDir, file), err)
+	}
+
+	var pf packagesFile
+	if err := yaml.Unmarshal(data, &pf); err != nil {
+		return fmt.Errorf("parsing packages file %s: %w", displayPath(baseDir, file), err)
+	}
+
+	origins := make(map[string]string, len(cfg.Applications)+len(pf.Packages))
+	for _, app := range cfg.Applications {
+		origins[app.Name] = app.Source
+	}
+
+	for _, def := range pf.Packages {
+		if prev, ok := origins[def.Name]; ok && def.Name != "" {
+			return fmt.Errorf("%w: duplicate package name %q in %s and %s",
+				ErrInvalidConfig, def.Name, displayPath(baseDir, prev), displayPath(baseDir, file))
+		}
+
+		origins[def.Name] = file
+		pkg := def.Package
+
+		cfg.Applications = append(cfg.Applications, Application{
+			Name:        def.Name,
+			Description: def.Description,
+			When:        def.When,
+			Package:     &pkg,
+			Source:      file,
+		})
+	}
+
+	cfg.packagesFile = file
+
+	return nil
+}
+
+// packageDefs returns the applications of cfg loaded from the packages file
+// as the file's packages. It fails for one that was given entries, which a
+// packages file cannot hold.
+func packageDefs(cfg *Config) ([]packageDef, error) {
+	defs := []packageDef{}
+
+	for _, app := range cfg.Applications {
+		if cfg.packagesFile == "" || app.Source != cfg.packagesFile {
+			continue
+		}
+
+		if len(app.Entries) > 0 {
+			return nil, fmt.Errorf("%w: application %q comes from packages file %s, which holds packages only",
+				ErrInvalidConfig, app.Name, cfg.packagesFile)
+		}
+
+		def := packageDef{Name: app.Name, Description: app.Description, When: app.When}
+		if app.Package != nil {
+			def.Package = *app.Package
+		}
+
+		defs = append(defs, def)
+	}
+
+	return defs, nil
+}
+
+// writePackagesFile writes the packages of the packages file back to it.
+func writePackagesFile(cfg *Config, defs []packageDef, writeFile func(string, []byte, os.FileMode) error) error {
+	if cfg.packagesFile == "" {
+		return nil
+	}
+
+	data, err := marshalYAML(packagesFile{Packages: defs})
+	if err != nil {
+		return fmt.Errorf("marshaling packages file %s: %w", cfg.packagesFile, err)
+	}
+
+	if err := writeFile(cfg.packagesFile, data, 0600); err != nil {
+		return fmt.Errorf("writing packages file %s: %w", cfg.packagesFile, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,162 @@
+package config
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLoadPackagesFile_MergesPackages(t *testing.T) {
+	t.Parallel()
+	dir := t.TempDir()
+
+	mainPath := writeTestFile(t, dir, "tidydots.yaml", `version: 3
+packages_file: ../shared/packages.yaml
+applications:
+  - name: nvim
+    package:
+      managers: {pacman: neovim}
+      when: '{{ .HasDisplay }}'
+    entries: []
+  - name: zsh
+    entries: []
+`)
+	writeTestFile(t, filepath.Dir(dir), "shared/packages.yaml", `packages:
+  - name: ripgrep
+    managers: {pacman: ripgrep, apt: ripgrep}
+  - name: fd
+    description: find alternative
+    managers: {pacman: fd}
+`)
+
+	cfg, err := Load(mainPath)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+
+	if got := strings.Join(appNames(cfg.Applications), " "); got != "nvim zsh ripgrep fd" {
+		t.Errorf("applications = %s, want nvim zsh ripgrep fd", got)
+	}
+
+	fd := cfg.Applications[3]
+	if fd.Description != "find alternative" || fd.Package == nil || fd.Package.Managers["pacman"].PackageName != "fd" {
+		t.Errorf("fd = %+v, want its description and package", fd)
+	}
+
+	if got := cfg.Applications[0].Package.When; got != "{{ .HasDisplay }}" {
+		t.Errorf("nvim package when = %q, want {{ .HasDisplay }}", got)
+	}
+
+	if got := strings.Join(appNames(cfg.GetFilteredPackages(nil)), " "); got != "nvim ripgrep fd" {
+		t.Errorf("GetFilteredPackages() = %s, want nvim ripgrep fd", got)
+	}
+}
+
+func TestLoadPackagesFile_DuplicateNames(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		apps     string
+		packages string
+		files    []string
+	}{
+		{
+			name:     "application",
+			apps:     "applications:\n  - name: git\n    entries: []\n",
+			packages: "packages:\n  - name: git\n    managers: {apt: git}\n",
+			files:    []string{"tidydots.yaml", "packages.yaml"},
+		},
+		{
+			name:     "package",
+			packages: "packages:\n  - name: git\n    managers: {apt: git}\n  - name: git\n    managers: {pacman: git}\n",
+			files:    []string{"packages.yaml and packages.yaml"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			dir := t.TempDir()
+
+			mainPath := writeTestFile(t, dir, "tidydots.yaml", "version: 3\npackages_file: packages.yaml\n"+tt.apps)
+			writeTestFile(t, dir, "packages.yaml", tt.packages)
+
+			_, err := Load(mainPath)
+			if !errors.Is(err, ErrInvalidConfig) {
+				t.Fatalf("Load() error = %v, want ErrInvalidConfig", err)
+			}
+
+			for _, want := range append([]string{`duplicate package name "git"`}, tt.files...) {
+				if !strings.Contains(err.Error(), want) {
+					t.Errorf("error %q does not mention %s", err.Error(), want)
+				}
+			}
+		})
+	}
+}
+
+func TestLoadPackagesFile_Missing(t *testing.T) {
+	t.Parallel()
+	dir := t.TempDir()
+
+	mainPath := writeTestFile(t, dir, "tidydots.yaml", "version: 3\npackages_file: packages.yaml\n")
+
+	if _, err := Load(mainPath); err == nil || !strings.Contains(err.Error(), "reading packages file packages.yaml") {
+		t.Errorf("Load() error = %v, want the packages file named", err)
+	}
+}
+
+func TestSave_WritesPackagesBackToPackagesFile(t *testing.T) {
+	t.Parallel()
+	dir := t.TempDir()
+
+	mainPath := writeTestFile(t, dir, "tidydots.yaml", `version: 3
+packages_file: packages.yaml
+applications:
+  - name: zsh
+    entries: []
+`)
+	packagesPath := writeTestFile(t, dir, "packages.yaml", "packages:\n  - name: ripgrep\n    when: '{{ .HasDisplay }}'\n    managers: {apt: ripgrep}\n")
+
+	cfg, err := Load(mainPath)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+
+	cfg.Applications[1].Package.Managers["pacman"] = ManagerValue{PackageName: "ripgrep"}
+
+	if err := Save(cfg, mainPath); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	mainData, err := os.ReadFile(filepath.Clean(mainPath))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if strings.Contains(string(mainData), "ripgrep") {
+		t.Errorf("main file holds the packages file's package:\n%s", mainData)
+	}
+
+	reloaded, err := Load(mainPath)
+	if err != nil {
+		t.Fatalf("Load() of the saved config error = %v", err)
+	}
+
+	if got := reloaded.Applications[1].Package.Managers["pacman"].PackageName; got != "ripgrep" {
+		t.Errorf("reloaded pacman package = %q, want the edit saved to %s", got, packagesPath)
+	}
+
+	if rg := reloaded.Applications[1]; rg.When != "{{ .HasDisplay }}" || rg.Package.When != "" {
+		t.Errorf("reloaded when = %q, package when = %q, want the when on the application only", rg.When, rg.Package.When)
+	}
+
+	cfg.Applications[1].Entries = []SubEntry{{Name: "rc", Backup: "./rc", Targets: map[string]string{"linux": "~/.rc"}}}
+
+	if err := Save(cfg, mainPath); !errors.Is(err, ErrInvalidConfig) {
+		t.Errorf("Save() error = %v, want ErrInvalidConfig for entries on a packages file package", err)
+	}
+}
@@ -38,6 +38,11 @@ func TestExpandPath_WindowsTilde(t *testing.T) {
 			path: "~/AppData/Local/nvim",
 			want: filepath.Join(home, "AppData/Local/nvim"),
 		},
+		{
+			name: "tilde with backslash",
+			path: `~\external-dotfiles\nvim`,
+			want: filepath.Join(home, "external-dotfiles", "nvim"),
+		},
 	}
 
 	for _, tt := range tests {
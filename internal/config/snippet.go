@@ -0,0 +1,339 @@
+package config
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// AppSnippet is a single application on its own, as `tidydots show` prints
+// it and `tidydots add-from` reads it: the keys of the application, followed
+// by an optional attachments list carrying the files of its backups.
+type AppSnippet struct {
+	Application Application
+	Attachments []Attachment
+}
+
+// Attachment is a backup file bundled in an AppSnippet.
+type Attachment struct {
+	Path       string `yaml:"path"` // slash-separated, relative to the backup root
+	Data       string `yaml:"data"` // base64-encoded content
+	Executable bool   `yaml:"executable,omitempty"`
+}
+
+// snippetAttachments holds the keys an AppSnippet adds to its application's.
+type snippetAttachments struct {
+	Attachments []Attachment `yaml:"attachments,omitempty"`
+}
+
+// UnmarshalYAML decodes the application, then its attachments.
+func (s *AppSnippet) UnmarshalYAML(node *yaml.Node) error {
+	if err := node.Decode(&s.Application); err != nil {
+		return err
+	}
+
+	var extra snippetAttachments
+	if err := node.Decode(&extra); err != nil {
+		return err
+	}
+
+	s.Attachments = extra.Attachments
+
+	return nil
+}
+
+// MarshalYAML writes the keys of the application followed by its
+// attachments, in one mapping.
+func (s AppSnippet) MarshalYAML() (any, error) {
+	var node, extra yaml.Node
+	if err := node.Encode(s.Application); err != nil {
+		return nil, err
+	}
+
+	if err := extra.Encode(snippetAttachments{Attachments: s.Attachments}); err != nil {
+		return nil, err
+	}
+
+	node.Content = append(node.Content, extra.Content...)
+
+	return &node, nil
+}
+
+// SnippetFor returns the application of cfg named name as a snippet. The
+// defaults its entries inherit from the config are set on the application,
+// and the contents of its notes_file become its notes, so the snippet means
+// the same in another config.
+func SnippetFor(cfg *Config, name string) (*AppSnippet, error) {
+	i := slices.IndexFunc(cfg.Applications, func(app Application) bool { return app.Name == name })
+	if i < 0 {
+		return nil, fmt.Errorf("application %q not found", name)
+	}
+
+	app := cfg.Applications[i]
+	app.Source = ""
+
+	notes, err := app.ReadNotes(cfg.BackupRoot)
+	if err != nil {
+		return nil, err
+	}
+
+	app.Notes, app.NotesFile = notes, ""
+
+	if d := cfg.EntryDefaults(&app); d != (Defaults{}) {
+		app.Defaults = &d
+	}
+
+	return &AppSnippet{Application: app}, nil
+}
+
+// ParseSnippet decodes an application snippet. It only checks the snippet is
+// an application; AddSnippet validates it against the config it joins.
+func ParseSnippet(data []byte) (*AppSnippet, error) {
+	var s AppSnippet
+	if err := yaml.Unmarshal(data, &s); err != nil {
+		return nil, fmt.Errorf("parsing snippet: %w", err)
+	}
+
+	if s.Application.Name == "" {
+		return nil, fmt.Errorf("%w: snippet has no application name", ErrInvalidConfig)
+	}
+
+	return &s, nil
+}
+
+// Marshal encodes the snippet as YAML.
+func (s *AppSnippet) Marshal() ([]byte, error) {
+	return marshalYAML(s)
+}
+
+// Attach bundles the backup files of the snippet's config entries, read
+// from under backupRoot. A folder entry bundles every file of its backup
+// directory, a files entry the files it lists. Entries whose backup is not
+// a plain path inside backupRoot, and backups that do not exist yet, are
+// left out; each is described in the returned notes.
+func (s *AppSnippet) Attach(backupRoot string) ([]string, error) {
+	var notes []string
+
+	for _, entry := range s.Application.Entries {
+		if !entry.IsConfig() {
+			continue
+		}
+
+		label := s.Application.Name + "/" + entry.Name
+
+		backup := ExpandPath(entry.Backup, nil)
+		if isTemplatePath(entry.Backup) || filepath.IsAbs(backup) || !filepath.IsLocal(backup) {
+			notes = append(notes, fmt.Sprintf("%s: backup %s is not inside the config directory", label, entry.Backup))
+			continue
+		}
+
+		dir := filepath.Join(backupRoot, backup)
+
+		var files []string
+
+		if entry.IsFolder() {
+			err := filepath.WalkDir(dir, func(p string, d fs.DirEntry, err error) error {
+				if err != nil {
+					return err
+				}
+
+				if d.Type().IsRegular() {
+					files = append(files, p)
+				}
+
+				return nil
+			})
+			if errors.Is(err, fs.ErrNotExist) {
+				notes = append(notes, fmt.Sprintf("%s: backup %s does not exist", label, entry.Backup))
+				continue
+			}
+
+			if err != nil {
+				return nil, fmt.Errorf("reading backup of %s: %w", label, err)
+			}
+		} else {
+			for _, file := range entry.Files {
+				files = append(files, filepath.Join(dir, file))
+			}
+		}
+
+		for _, file := range files {
+			att, err := newAttachment(backupRoot, file)
+			if errors.Is(err, fs.ErrNotExist) {
+				notes = append(notes, fmt.Sprintf("%s: %s does not exist", label, file))
+				continue
+			}
+
+			if err != nil {
+				return nil, fmt.Errorf("attaching %s: %w", file, err)
+			}
+
+			s.Attachments = append(s.Attachments, att)
+		}
+	}
+
+	return notes, nil
+}
+
+// newAttachment reads file, which lies under backupRoot, into an Attachment.
+func newAttachment(backupRoot, file string) (Attachment, error) {
+	info, err := os.Stat(file)
+	if err != nil {
+		return Attachment{}, err
+	}
+
+	data, err := os.ReadFile(file) //nolint:gosec // backup file of a configured entry
+	if err != nil {
+		return Attachment{}, err
+	}
+
+	rel, err := filepath.Rel(backupRoot, file)
+	if err != nil {
+		return Attachment{}, err
+	}
+
+	return Attachment{
+		Path:       filepath.ToSlash(rel),
+		Data:       base64.StdEncoding.EncodeToString(data),
+		Executable: info.Mode()&0o111 != 0,
+	}, nil
+}
+
+// unpackedFile is an attachment decoded and placed under a backup root.
+type unpackedFile struct {
+	path       string
+	data       []byte
+	executable bool
+}
+
+// CheckAttachments reports whether every attachment can be unpacked under
+// backupRoot: its path stays inside backupRoot, its data is valid base64,
+// and no file exists at its place yet.
+func (s *AppSnippet) CheckAttachments(backupRoot string) error {
+	_, err := s.unpackedFiles(backupRoot)
+	return err
+}
+
+// UnpackAttachments writes the attachments under backupRoot. Every
+// attachment is checked first, as CheckAttachments does, so a snippet that
+// fails the check writes nothing.
+func (s *AppSnippet) UnpackAttachments(backupRoot string) error {
+	files, err := s.unpackedFiles(backupRoot)
+	if err != nil {
+		return err
+	}
+
+	for _, f := range files {
+		if err := os.MkdirAll(filepath.Dir(f.path), 0o750); err != nil {
+			return fmt.Errorf("creating directory for %s: %w", f.path, err)
+		}
+
+		perm := os.FileMode(0o600)
+		if f.executable {
+			perm = 0o700
+		}
+
+		if err := os.WriteFile(f.path, f.data, perm); err != nil {
+			return fmt.Errorf("writing %s: %w", f.path, err)
+		}
+	}
+
+	return nil
+}
+
+// unpackedFiles decodes the attachments and resolves their paths under
+// backupRoot, failing on the first one that cannot be unpacked.
+func (s *AppSnippet) unpackedFiles(backupRoot string) ([]unpackedFile, error) {
+	files := make([]unpackedFile, 0, len(s.Attachments))
+	seen := make(map[string]bool, len(s.Attachments))
+
+	for _, att := range s.Attachments {
+		rel := filepath.FromSlash(path.Clean(att.Path))
+		if att.Path == "" || !filepath.IsLocal(rel) || strings.Contains(att.Path, `\`) {
+			return nil, fmt.Errorf("%w: attachment path %q is not inside the config directory", ErrInvalidConfig, att.Path)
+		}
+
+		if seen[rel] {
+			return nil, fmt.Errorf("%w: attachment %s appears twice", ErrInvalidConfig, att.Path)
+		}
+
+		seen[rel] = true
+
+		data, err := base64.StdEncoding.DecodeString(att.Data)
+		if err != nil {
+			return nil, fmt.Errorf("%w: attachment %s: %w", ErrInvalidConfig, att.Path, err)
+		}
+
+		dest := filepath.Join(backupRoot, rel)
+		if _, err := os.Lstat(dest); err == nil {
+			return nil, fmt.Errorf("attachment %s: %s already exists", att.Path, dest)
+		} else if !errors.Is(err, fs.ErrNotExist) {
+			return nil, fmt.Errorf("attachment %s: %w", att.Path, err)
+		}
+
+		files = append(files, unpackedFile{path: dest, data: data, executable: att.Executable})
+	}
+
+	return files, nil
+}
+
+// AddSnippet returns a copy of cfg with the snippet's application added after
+// its own, as MergeConfigs adds it. It fails with ErrMergeConflict when the
+// name is taken, and with ErrInvalidConfig when the result does not validate
+// or the application's targets collide with those of existing entries on any
+// OS the application targets. Targets are resolved with envVars and renderer,
+// as FindTargetCollisions does.
+func AddSnippet(cfg *Config, s *AppSnippet, envVars map[string]string, renderer PathRenderer) (*Config, error) {
+	merged, _, err := MergeConfigs(cfg, &Config{Applications: []Application{s.Application}}, MergeError)
+	if err != nil {
+		return nil, err
+	}
+
+	app := &merged.Applications[len(merged.Applications)-1]
+	app.Entries = slices.Clone(app.Entries)
+
+	d := merged.EntryDefaults(app)
+	for i := range app.Entries {
+		app.Entries[i].inherit(app.Name, d)
+	}
+
+	if errs := ValidateConfig(merged); len(errs) > 0 {
+		return nil, fmt.Errorf("validating snippet: %w", errors.Join(errs...))
+	}
+
+	var osTypes []string
+
+	for _, entry := range app.Entries {
+		for osType := range entry.Targets {
+			if !slices.Contains(osTypes, osType) {
+				osTypes = append(osTypes, osType)
+			}
+		}
+	}
+
+	slices.Sort(osTypes)
+
+	var collisions []string
+
+	for _, osType := range osTypes {
+		for _, c := range FindTargetCollisions(merged.Applications, osType, envVars, renderer) {
+			if c.App == app.Name || c.OtherApp == app.Name {
+				collisions = append(collisions, c.String())
+			}
+		}
+	}
+
+	if len(collisions) > 0 {
+		return nil, fmt.Errorf("%w: target collisions: %s", ErrInvalidConfig, strings.Join(collisions, "; "))
+	}
+
+	return merged, nil
+}
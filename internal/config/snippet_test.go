@@ -0,0 +1,246 @@
+package config
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestSnippet_RoundTrip(t *testing.T) {
+	t.Parallel()
+	src := t.TempDir()
+
+	srcPath := writeTestFile(t, src, "tidydots.yaml", `version: 3
+defaults:
+  method: copy
+applications:
+  - name: nvim
+    package:
+      managers: {pacman: neovim}
+    entries:
+      - name: config
+        backup: ./nvim
+        targets: {linux: ~/.config/nvim-snippet-test}
+      - name: rc
+        backup: ./rc
+        files: [.nvimrc]
+        targets: {linux: ~/snippet-test}
+`)
+	writeTestFile(t, src, "nvim/init.lua", "vim.o.number = true\n")
+	writeTestFile(t, src, "nvim/lua/plugins.lua", "return {}\n")
+	writeTestFile(t, src, "rc/.nvimrc", "set nu\n")
+
+	cfg, err := Load(srcPath)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+
+	snippet, err := SnippetFor(cfg, "nvim")
+	if err != nil {
+		t.Fatalf("SnippetFor() error = %v", err)
+	}
+
+	notes, err := snippet.Attach(src)
+	if err != nil || len(notes) != 0 {
+		t.Fatalf("Attach() = %v, %v, want no notes", notes, err)
+	}
+
+	data, err := snippet.Marshal()
+	if err != nil {
+		t.Fatalf("Marshal() error = %v", err)
+	}
+
+	if !strings.HasPrefix(string(data), "package:") || !strings.Contains(string(data), "method: copy") {
+		t.Errorf("snippet is not the application with its defaults:\n%s", data)
+	}
+
+	parsed, err := ParseSnippet(data)
+	if err != nil {
+		t.Fatalf("ParseSnippet() error = %v", err)
+	}
+
+	dst := t.TempDir()
+
+	dstPath := writeTestFile(t, dst, "tidydots.yaml", "version: 3\napplications:\n  - name: zsh\n    entries: []\n")
+
+	dstCfg, err := Load(dstPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	merged, err := AddSnippet(dstCfg, parsed, nil, nil)
+	if err != nil {
+		t.Fatalf("AddSnippet() error = %v", err)
+	}
+
+	if err := parsed.UnpackAttachments(dst); err != nil {
+		t.Fatalf("UnpackAttachments() error = %v", err)
+	}
+
+	if err := Save(merged, dstPath); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	reloaded, err := Load(dstPath)
+	if err != nil {
+		t.Fatalf("Load() of the saved config error = %v", err)
+	}
+
+	if got := strings.Join(appNames(reloaded.Applications), " "); got != "zsh nvim" {
+		t.Fatalf("applications = %s, want zsh nvim", got)
+	}
+
+	if rc := reloaded.Applications[1].Entries[1]; rc.Method != MethodCopy || reloaded.Applications[1].Package == nil {
+		t.Errorf("added nvim lost its package or inherited method: %+v", reloaded.Applications[1])
+	}
+
+	for name, want := range map[string]string{
+		"nvim/init.lua":        "vim.o.number = true\n",
+		"nvim/lua/plugins.lua": "return {}\n",
+		"rc/.nvimrc":           "set nu\n",
+	} {
+		got, err := os.ReadFile(filepath.Join(dst, name))
+		if err != nil || string(got) != want {
+			t.Errorf("unpacked %s = %q, %v, want %q", name, got, err, want)
+		}
+	}
+}
+
+func TestSnippetFor_InlinesNotesFile(t *testing.T) {
+	t.Parallel()
+	src := t.TempDir()
+
+	srcPath := writeTestFile(t, src, "tidydots.yaml", `version: 3
+applications:
+  - name: office
+    notes_file: notes/office.md
+    entries: []
+`)
+	writeTestFile(t, src, "notes/office.md", "License key in the vault.\n")
+
+	cfg, err := Load(srcPath)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+
+	cfg.BackupRoot = src
+
+	snippet, err := SnippetFor(cfg, "office")
+	if err != nil {
+		t.Fatalf("SnippetFor() error = %v", err)
+	}
+
+	if app := snippet.Application; app.Notes != "License key in the vault.\n" || app.NotesFile != "" {
+		t.Errorf("snippet notes = %q, notes_file = %q, want the file's contents inline", app.Notes, app.NotesFile)
+	}
+}
+
+func TestAttach_NotesSkippedBackups(t *testing.T) {
+	t.Parallel()
+
+	snippet := &AppSnippet{Application: Application{
+		Name: "app",
+		Entries: []SubEntry{
+			{Name: "abs", Backup: "/etc/app", Targets: map[string]string{"linux": "~/.app"}},
+			{Name: "missing", Backup: "./missing", Targets: map[string]string{"linux": "~/.missing"}},
+		},
+	}}
+
+	notes, err := snippet.Attach(t.TempDir())
+	if err != nil {
+		t.Fatalf("Attach() error = %v", err)
+	}
+
+	if len(notes) != 2 || !strings.Contains(notes[0], "not inside the config directory") || !strings.Contains(notes[1], "does not exist") {
+		t.Errorf("notes = %q, want the absolute and missing backups", notes)
+	}
+
+	if len(snippet.Attachments) != 0 {
+		t.Errorf("Attachments = %v, want none", snippet.Attachments)
+	}
+}
+
+func TestAddSnippet_Rejects(t *testing.T) {
+	t.Parallel()
+
+	existing := &Config{Version: 3, Applications: []Application{{
+		Name: "nvim",
+		Entries: []SubEntry{
+			{Name: "config", Backup: "./nvim", Targets: map[string]string{"linux": "/home/u/.config/nvim"}},
+		},
+	}}}
+
+	tests := []struct {
+		name    string
+		app     Application
+		wantErr error
+		want    string
+	}{
+		{
+			name:    "name taken",
+			app:     Application{Name: "nvim"},
+			wantErr: ErrMergeConflict,
+			want:    `"nvim"`,
+		},
+		{
+			name: "target collision",
+			app: Application{Name: "lazyvim", Entries: []SubEntry{
+				{Name: "lua", Backup: "./lazyvim", Files: []string{"init.lua"}, Targets: map[string]string{"linux": "/home/u/.config/nvim/lua"}},
+			}},
+			wantErr: ErrInvalidConfig,
+			want:    "nvim/config and lazyvim/lua",
+		},
+		{
+			name: "invalid",
+			app:  Application{Name: "tmux", Priority: -1},
+			want: "validating snippet",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := AddSnippet(existing, &AppSnippet{Application: tt.app}, nil, nil)
+			if err == nil || (tt.wantErr != nil && !errors.Is(err, tt.wantErr)) || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("AddSnippet() error = %v, want %v mentioning %s", err, tt.wantErr, tt.want)
+			}
+		})
+	}
+}
+
+func TestUnpackAttachments_Rejects(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		att  Attachment
+		want string
+	}{
+		{name: "escapes", att: Attachment{Path: "../evil", Data: ""}, want: "not inside the config directory"},
+		{name: "absolute", att: Attachment{Path: "/etc/evil", Data: ""}, want: "not inside the config directory"},
+		{name: "bad data", att: Attachment{Path: "app/file", Data: "not base64!"}, want: "illegal base64"},
+		{name: "exists", att: Attachment{Path: "existing", Data: ""}, want: "already exists"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			dir := t.TempDir()
+			writeTestFile(t, dir, "existing", "keep")
+
+			snippet := &AppSnippet{Attachments: []Attachment{{Path: "app/ok", Data: "b2sK"}, tt.att}}
+
+			err := snippet.UnpackAttachments(dir)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Fatalf("UnpackAttachments() error = %v, want %q", err, tt.want)
+			}
+
+			if _, err := os.Stat(filepath.Join(dir, "app", "ok")); !os.IsNotExist(err) {
+				t.Errorf("a valid attachment was written before the check failed")
+			}
+		})
+	}
+}
@@ -0,0 +1,221 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// sharedHomeDirs are directories under $HOME that many programs write into.
+// Stow links inside them instead of replacing them, so StowApplications
+// creates entries for their children rather than for the directories.
+var sharedHomeDirs = map[string]bool{
+	".config":      true,
+	".local":       true,
+	".local/bin":   true,
+	".local/share": true,
+	".local/state": true,
+	".cache":       true,
+}
+
+// stowIgnored are the names stow skips by default. A package's
+// .stow-local-ignore is not interpreted; the file itself is skipped.
+var stowIgnored = map[string]bool{
+	".git":               true,
+	".gitignore":         true,
+	".gitmodules":        true,
+	".hg":                true,
+	".svn":               true,
+	"CVS":                true,
+	".stow-local-ignore": true,
+}
+
+// stowIgnoredTopLevel reports whether name, at the top of a package, is one
+// of the documentation files stow skips there.
+func stowIgnoredTopLevel(name string) bool {
+	for _, prefix := range []string{"README", "LICENSE", "COPYING"} {
+		if strings.HasPrefix(name, prefix) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// stowEntry is one sub-entry inferred from a stow package: a directory that
+// is linked whole, or a set of files linked into a directory. rel is the path
+// inside the package, which is also the path under $HOME.
+type stowEntry struct {
+	rel   string
+	files []string // nil for a folder entry
+}
+
+// StowApplications turns a GNU Stow directory into applications, one per
+// package directory, in name order. Each package mirrors $HOME, so its paths
+// are re-rooted onto ~ as targets for osType:
+//
+//   - a top-level directory becomes a folder entry, e.g. zsh/.zsh -> ~/.zsh;
+//   - top-level files become one files entry targeting ~;
+//   - shared directories such as .config and .local/share are descended
+//     into, so nvim/.config/nvim becomes a folder entry for ~/.config/nvim.
+//
+// Backups are written relative to backupRoot when stowDir lies inside it, and
+// as absolute paths otherwise. Packages that cannot be imported as-is, such as
+// ones using stow's dot- prefix, are left out and described in the returned
+// warnings.
+func StowApplications(stowDir, backupRoot, osType string) ([]Application, []string, error) {
+	stowDir, err := filepath.Abs(stowDir)
+	if err != nil {
+		return nil, nil, fmt.Errorf("resolving stow directory: %w", err)
+	}
+
+	packages, err := os.ReadDir(stowDir)
+	if err != nil {
+		return nil, nil, fmt.Errorf("reading stow directory: %w", err)
+	}
+
+	var (
+		apps     []Application
+		warnings []string
+	)
+
+	for _, pkg := range packages {
+		if !pkg.IsDir() || strings.HasPrefix(pkg.Name(), ".") {
+			continue
+		}
+
+		pkgDir := filepath.Join(stowDir, pkg.Name())
+
+		entries, dotted, err := scanStowDir(pkgDir, "")
+		if err != nil {
+			return nil, nil, fmt.Errorf("reading stow package %s: %w", pkg.Name(), err)
+		}
+
+		switch {
+		case len(dotted) > 0:
+			warnings = append(warnings, fmt.Sprintf(
+				"%s: skipped, %s use stow's dot- prefix, which tidydots cannot rename when linking",
+				pkg.Name(), quoteJoin(dotted)))
+
+			continue
+		case len(entries) == 0:
+			warnings = append(warnings, fmt.Sprintf("%s: skipped, the package has no files", pkg.Name()))
+			continue
+		}
+
+		app := Application{Name: pkg.Name()}
+		used := make(map[string]bool, len(entries))
+
+		for _, e := range entries {
+			target := "~"
+			if e.rel != "" {
+				target = "~/" + e.rel
+			}
+
+			app.Entries = append(app.Entries, SubEntry{
+				Name:    uniqueName(stowEntryName(e), used),
+				Backup:  stowBackupPath(filepath.Join(pkgDir, filepath.FromSlash(e.rel)), backupRoot),
+				Targets: map[string]string{osType: target},
+				Files:   e.files,
+			})
+		}
+
+		apps = append(apps, app)
+	}
+
+	return apps, warnings, nil
+}
+
+// scanStowDir lists the entries for the package directory pkgDir at rel, a
+// slash-separated path under the package ("" for its top). It also returns
+// the paths that use stow's dot- prefix.
+func scanStowDir(pkgDir, rel string) ([]stowEntry, []string, error) {
+	children, err := os.ReadDir(filepath.Join(pkgDir, filepath.FromSlash(rel)))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var (
+		entries []stowEntry
+		dotted  []string
+		files   []string
+	)
+
+	for _, child := range children {
+		name := child.Name()
+		if stowIgnored[name] || (rel == "" && stowIgnoredTopLevel(name)) {
+			continue
+		}
+
+		childRel := path.Join(rel, name)
+
+		if strings.HasPrefix(name, "dot-") {
+			dotted = append(dotted, childRel)
+			continue
+		}
+
+		if !child.IsDir() {
+			files = append(files, name)
+			continue
+		}
+
+		if !sharedHomeDirs[childRel] {
+			entries = append(entries, stowEntry{rel: childRel})
+			continue
+		}
+
+		nested, nestedDotted, err := scanStowDir(pkgDir, childRel)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		entries = append(entries, nested...)
+		dotted = append(dotted, nestedDotted...)
+	}
+
+	if len(files) > 0 {
+		entries = append([]stowEntry{{rel: rel, files: files}}, entries...)
+	}
+
+	return entries, dotted, nil
+}
+
+// stowEntryName derives an entry name from the linked path: "nvim" for
+// .config/nvim, "bin" for files in .local/bin, and "home" for files in ~.
+func stowEntryName(e stowEntry) string {
+	if e.rel == "" {
+		return "home"
+	}
+
+	return strings.TrimPrefix(path.Base(e.rel), ".")
+}
+
+// uniqueName returns name, or name with a numeric suffix if it is already in
+// used, and records the result.
+func uniqueName(name string, used map[string]bool) string {
+	unique := name
+	for i := 2; used[unique]; i++ {
+		unique = name + "-" + strconv.Itoa(i)
+	}
+
+	used[unique] = true
+
+	return unique
+}
+
+// stowBackupPath expresses dir relative to backupRoot ("./zsh") when it lies
+// inside it, so the config stays portable, and as an absolute path otherwise.
+func stowBackupPath(dir, backupRoot string) string {
+	if backupRoot != "" {
+		if root, err := filepath.Abs(ExpandPath(backupRoot, nil)); err == nil {
+			if rel, err := filepath.Rel(root, dir); err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+				return "./" + filepath.ToSlash(rel)
+			}
+		}
+	}
+
+	return filepath.ToSlash(dir)
+}
@@ -0,0 +1,135 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// describeEntries renders an application's entries one per line as
+// "name backup -> target [files]" for compact comparisons.
+func describeEntries(app Application) []string {
+	lines := make([]string, 0, len(app.Entries))
+
+	for _, e := range app.Entries {
+		line := e.Name + " " + e.Backup + " -> " + e.Targets["linux"]
+		if len(e.Files) > 0 {
+			line += " [" + strings.Join(e.Files, " ") + "]"
+		}
+
+		lines = append(lines, line)
+	}
+
+	return lines
+}
+
+func TestStowApplications(t *testing.T) {
+	t.Parallel()
+
+	apps, warnings, err := StowApplications(filepath.Join("testdata", "stow"), "testdata", "linux")
+	if err != nil {
+		t.Fatalf("StowApplications() error = %v", err)
+	}
+
+	want := map[string][]string{
+		"desktop": {
+			"home ./stow/desktop -> ~ [.tmux.conf]",
+			"config ./stow/desktop/.config -> ~/.config [starship.toml]",
+			"alacritty ./stow/desktop/.config/alacritty -> ~/.config/alacritty",
+			"fonts ./stow/desktop/.local/share/fonts -> ~/.local/share/fonts",
+		},
+		"git": {
+			"home ./stow/git -> ~ [.gitconfig .gitignore_global]",
+		},
+		"nvim": {
+			"nvim ./stow/nvim/.config/nvim -> ~/.config/nvim",
+		},
+		"scripts": {
+			"bin ./stow/scripts/.local/bin -> ~/.local/bin [bye hello]",
+		},
+		"zsh": {
+			"home ./stow/zsh -> ~ [.zprofile .zshrc]",
+			"zsh ./stow/zsh/.zsh -> ~/.zsh",
+		},
+	}
+
+	var names []string
+	for _, app := range apps {
+		names = append(names, app.Name)
+	}
+
+	if got := strings.Join(names, " "); got != "desktop git nvim scripts zsh" {
+		t.Fatalf("applications = %q, want desktop git nvim scripts zsh", got)
+	}
+
+	for _, app := range apps {
+		got := describeEntries(app)
+		if strings.Join(got, "\n") != strings.Join(want[app.Name], "\n") {
+			t.Errorf("%s entries:\n%s\nwant:\n%s", app.Name, strings.Join(got, "\n"), strings.Join(want[app.Name], "\n"))
+		}
+	}
+
+	if len(warnings) != 1 || !strings.Contains(warnings[0], "bash") || !strings.Contains(warnings[0], `"dot-bashrc"`) {
+		t.Errorf("warnings = %q, want one about bash's dot- prefix", warnings)
+	}
+
+	if errs := ValidateConfig(&Config{Version: 3, Applications: apps}); len(errs) > 0 {
+		t.Errorf("generated config does not validate: %v", errs)
+	}
+}
+
+func TestStowApplications_OutsideBackupRoot(t *testing.T) {
+	t.Parallel()
+
+	stowDir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(stowDir, "vim", ".vim"), 0o750); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := os.WriteFile(filepath.Join(stowDir, "vim", ".vimrc"), []byte("set nu\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := os.Mkdir(filepath.Join(stowDir, "empty"), 0o750); err != nil {
+		t.Fatal(err)
+	}
+
+	apps, warnings, err := StowApplications(stowDir, t.TempDir(), "linux")
+	if err != nil {
+		t.Fatalf("StowApplications() error = %v", err)
+	}
+
+	if len(apps) != 1 {
+		t.Fatalf("expected only vim, got %+v", apps)
+	}
+
+	abs := filepath.ToSlash(filepath.Join(stowDir, "vim"))
+	want := []string{
+		"home " + abs + " -> ~ [.vimrc]",
+		"vim " + abs + "/.vim -> ~/.vim",
+	}
+
+	if got := describeEntries(apps[0]); strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("entries = %q, want %q", got, want)
+	}
+
+	if len(warnings) != 1 || !strings.Contains(warnings[0], "empty") {
+		t.Errorf("warnings = %q, want one about the empty package", warnings)
+	}
+
+	if _, _, err := StowApplications(filepath.Join(stowDir, "missing"), "", "linux"); err == nil {
+		t.Error("expected error for a missing stow directory")
+	}
+}
+
+func TestUniqueName(t *testing.T) {
+	t.Parallel()
+
+	used := map[string]bool{}
+	got := []string{uniqueName("zsh", used), uniqueName("zsh", used), uniqueName("zsh", used)}
+
+	if strings.Join(got, " ") != "zsh zsh-2 zsh-3" {
+		t.Errorf("uniqueName() = %q, want zsh zsh-2 zsh-3", got)
+	}
+}
@@ -0,0 +1 @@
+require("plugins")
@@ -1,10 +1,17 @@
 package config
 
 import (
+	"encoding/hex"
 	"fmt"
+	"regexp"
+	"slices"
+	"strconv"
 	"strings"
 )
 
+// envVarName matches the names env_vars may declare.
+var envVarName = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
 // ValidatePath checks a path for potential security issues.
 // It returns an error if the path contains null bytes or suspicious patterns.
 func ValidatePath(path string) error {
@@ -45,6 +52,13 @@ func validateSetupEntry(appName string, entry SubEntry) []error {
 				fmt.Errorf("check requires a matching run command")))
 		}
 
+		for os, cmd := range entry.PostRestore {
+			if strings.TrimSpace(cmd) == "" {
+				errs = append(errs, NewFieldError(entryPath, fmt.Sprintf("post_restore[%s]", os), cmd,
+					fmt.Errorf("command cannot be empty")))
+			}
+		}
+
 		return errs
 	}
 
@@ -59,6 +73,11 @@ func validateSetupEntry(appName string, entry SubEntry) []error {
 			fmt.Errorf("a setup entry (one with run) cannot declare targets")))
 	}
 
+	if len(entry.PostRestore) > 0 {
+		errs = append(errs, NewFieldError(entryPath, "post_restore", "",
+			fmt.Errorf("a setup entry (one with run) cannot declare post_restore; put the command in run")))
+	}
+
 	// Every OS with a run command needs a check command, and vice versa.
 	for os, cmd := range entry.Run {
 		if strings.TrimSpace(cmd) == "" {
@@ -117,6 +136,15 @@ func validateEntryPaths(appName string, entry SubEntry) []error {
 				fmt.Sprintf("targets[%s]", os), target, err,
 			))
 		}
+
+		// Targets are checked again once expanded, in restore.
+		if reason := entry.DangerousTarget(target, "~"); reason != "" {
+			errs = append(errs, NewFieldError(
+				fmt.Sprintf("%s/%s", appName, entry.Name),
+				fmt.Sprintf("targets[%s]", os), target,
+				fmt.Errorf("target is %s; set allow_dangerous: true to restore there", reason),
+			))
+		}
 	}
 
 	// Validate deployment method.
@@ -130,6 +158,36 @@ func validateEntryPaths(appName string, entry SubEntry) []error {
 		))
 	}
 
+	switch entry.LinkMode {
+	case "", LinkModeDir, LinkModeFiles:
+	default:
+		errs = append(errs, NewFieldError(
+			fmt.Sprintf("%s/%s", appName, entry.Name),
+			"link_mode", entry.LinkMode,
+			fmt.Errorf("must be %q or %q", LinkModeDir, LinkModeFiles),
+		))
+	}
+
+	// A files entry already links file by file; a sudo farm would need
+	// its intermediate directories created as root.
+	if entry.LinkMode == LinkModeFiles && (len(entry.Files) > 0 || entry.Sudo) {
+		errs = append(errs, NewFieldError(
+			fmt.Sprintf("%s/%s", appName, entry.Name),
+			"link_mode", entry.LinkMode,
+			fmt.Errorf("link_mode files applies to folder entries without sudo"),
+		))
+	}
+
+	switch entry.Encoding {
+	case "", EncodingUTF8, EncodingLatin1, EncodingWindows1252:
+	default:
+		errs = append(errs, NewFieldError(
+			fmt.Sprintf("%s/%s", appName, entry.Name),
+			"encoding", entry.Encoding,
+			fmt.Errorf("must be %q, %q or %q", EncodingUTF8, EncodingLatin1, EncodingWindows1252),
+		))
+	}
+
 	// Copy mode requires an explicit, non-empty files list (v1: files-only).
 	if entry.Method == MethodCopy && len(entry.Files) == 0 {
 		errs = append(errs, NewFieldError(
@@ -148,11 +206,89 @@ func validateEntryPaths(appName string, entry SubEntry) []error {
 		))
 	}
 
+	// Dedupe reads the files of a backup and their targets without sudo.
+	if entry.Dedupe && entry.Backup == "" {
+		errs = append(errs, NewFieldError(
+			fmt.Sprintf("%s/%s", appName, entry.Name),
+			"dedupe", "",
+			fmt.Errorf("dedupe requires a backup path"),
+		))
+	} else if entry.Dedupe && entry.Sudo {
+		errs = append(errs, NewFieldError(
+			fmt.Sprintf("%s/%s", appName, entry.Name),
+			"dedupe", "",
+			fmt.Errorf("dedupe is not supported on sudo entries"),
+		))
+	}
+
+	if entry.MaxFileSize < 0 {
+		errs = append(errs, NewFieldError(
+			fmt.Sprintf("%s/%s", appName, entry.Name),
+			"max_file_size", strconv.FormatInt(entry.MaxFileSize, 10),
+			fmt.Errorf("must not be negative"),
+		))
+	} else if entry.MaxFileSize > 0 && entry.Sudo {
+		errs = append(errs, NewFieldError(
+			fmt.Sprintf("%s/%s", appName, entry.Name),
+			"max_file_size", strconv.FormatInt(entry.MaxFileSize, 10),
+			fmt.Errorf("max_file_size is not supported on sudo entries"),
+		))
+	}
+
+	errs = append(errs, validateCaseRename(appName, entry)...)
 	errs = append(errs, validateSetupEntry(appName, entry)...)
 
 	return errs
 }
 
+// validateCaseRename checks the case_rename map of a sub-entry: it renames
+// files of a files list, one exact name to another, and no two files to the
+// same backup name.
+func validateCaseRename(appName string, entry SubEntry) []error {
+	if len(entry.CaseRename) == 0 {
+		return nil
+	}
+
+	field := fmt.Sprintf("%s/%s", appName, entry.Name)
+
+	if len(entry.Files) == 0 {
+		return []error{NewFieldError(field, "case_rename", "",
+			fmt.Errorf("case_rename requires a files list"))}
+	}
+
+	var errs []error
+
+	files := make([]string, 0, len(entry.CaseRename))
+	for file := range entry.CaseRename {
+		files = append(files, file)
+	}
+
+	slices.Sort(files)
+
+	seen := make(map[string]string, len(files))
+
+	for _, file := range files {
+		renamed := entry.CaseRename[file]
+		key := fmt.Sprintf("case_rename[%s]", file)
+
+		switch {
+		case file == "" || renamed == "":
+			errs = append(errs, NewFieldError(field, key, renamed, fmt.Errorf("names must not be empty")))
+		case strings.ContainsAny(file, "*?") || strings.ContainsAny(renamed, "*?"):
+			errs = append(errs, NewFieldError(field, key, renamed, fmt.Errorf("names must be exact, not glob patterns")))
+		case renamed == file:
+			errs = append(errs, NewFieldError(field, key, renamed, fmt.Errorf("renames the file to itself")))
+		case seen[renamed] != "":
+			errs = append(errs, NewFieldError(field, key, renamed,
+				fmt.Errorf("%s is already renamed to the same name", seen[renamed])))
+		default:
+			seen[renamed] = file
+		}
+	}
+
+	return errs
+}
+
 // validateGitPackagePaths validates target paths on a git package configuration.
 func validateGitPackagePaths(appName string, gitPkg *GitPackage) []error {
 	var errs []error
@@ -173,6 +309,30 @@ func validateGitPackagePaths(appName string, gitPkg *GitPackage) []error {
 	return errs
 }
 
+// validateURLInstalls validates the optional verification fields on URL
+// installs: a sha256 must be 64 hex characters and a size must be positive.
+func validateURLInstalls(appName string, urls map[string]URLInstallSpec) []error {
+	var errs []error
+
+	for os, spec := range urls {
+		if spec.SHA256 != "" {
+			if b, err := hex.DecodeString(spec.SHA256); err != nil || len(b) != 32 {
+				errs = append(errs, NewFieldError(appName,
+					fmt.Sprintf("package.url[%s].sha256", os), spec.SHA256,
+					fmt.Errorf("must be a 64-character hex SHA-256 digest")))
+			}
+		}
+
+		if spec.Size < 0 {
+			errs = append(errs, NewFieldError(appName,
+				fmt.Sprintf("package.url[%s].size", os), fmt.Sprint(spec.Size),
+				fmt.Errorf("must be a positive number of bytes")))
+		}
+	}
+
+	return errs
+}
+
 // ValidateConfig validates the entire config including all applications
 func ValidateConfig(cfg *Config) []error {
 	var errs []error
@@ -182,9 +342,31 @@ func ValidateConfig(cfg *Config) []error {
 		errs = append(errs, fmt.Errorf("%w: %d (expected 3)", ErrUnsupportedVersion, cfg.Version))
 	}
 
-	// Validate applications
-	appNames := make(map[string]bool)
+	errs = append(errs, duplicateNameErrors(cfg.Applications)...)
+	errs = append(errs, validateNotifications(cfg.Notifications)...)
+	errs = append(errs, validateDedupe(cfg.Dedupe)...)
 
+	if cfg.DefaultMaxFileSize < 0 {
+		errs = append(errs, NewFieldError("config", "default_max_file_size",
+			strconv.FormatInt(cfg.DefaultMaxFileSize, 10), fmt.Errorf("must not be negative")))
+	}
+	errs = append(errs, validateAfter(cfg.Applications)...)
+	errs = append(errs, validateRequiredBy(cfg.Applications)...)
+	errs = append(errs, validateWhenRefs(cfg.WhenPresets, cfg.Applications)...)
+	errs = append(errs, validateDefaults("config", cfg.Defaults)...)
+
+	for name := range cfg.Vars {
+		if !envVarName.MatchString(name) {
+			errs = append(errs, NewFieldError("config", "vars", name,
+				fmt.Errorf("must be a name of letters, digits and underscores")))
+		}
+	}
+
+	if err := ValidateSymlinkCompat(cfg.SymlinkCompat); err != nil {
+		errs = append(errs, NewFieldError("config", "symlink_compat", cfg.SymlinkCompat, err))
+	}
+
+	// Validate applications
 	for _, app := range cfg.Applications {
 		if app.Name == "" {
 			errs = append(errs, fmt.Errorf("%w: application has empty name", ErrInvalidConfig))
@@ -192,14 +374,22 @@ func ValidateConfig(cfg *Config) []error {
 			continue
 		}
 
-		if appNames[app.Name] {
-			errs = append(errs, fmt.Errorf("%w: duplicate application name %q", ErrInvalidConfig, app.Name))
+		if app.Priority < 0 {
+			errs = append(errs, NewFieldError(app.Name, "priority", strconv.Itoa(app.Priority),
+				fmt.Errorf("must not be negative")))
 		}
 
-		appNames[app.Name] = true
+		errs = append(errs, validateDefaults(app.Name, app.Defaults)...)
+		errs = append(errs, validateNotes(app)...)
+
+		for name := range app.EnvVars {
+			if !envVarName.MatchString(name) {
+				errs = append(errs, NewFieldError(app.Name, "env_vars", name,
+					fmt.Errorf("must be a variable name of letters, digits and underscores")))
+			}
+		}
 
 		// Validate sub-entries
-		subNames := make(map[string]bool)
 		for _, entry := range app.Entries {
 			if entry.Name == "" {
 				errs = append(errs, fmt.Errorf("%w: application %q has entry with empty name", ErrInvalidConfig, app.Name))
@@ -207,12 +397,6 @@ func ValidateConfig(cfg *Config) []error {
 				continue
 			}
 
-			if subNames[entry.Name] {
-				errs = append(errs, fmt.Errorf("%w: application %q has duplicate entry name %q", ErrInvalidConfig, app.Name, entry.Name))
-			}
-
-			subNames[entry.Name] = true
-
 			// Validate paths on each entry
 			errs = append(errs, validateEntryPaths(app.Name, entry)...)
 		}
@@ -222,8 +406,148 @@ func ValidateConfig(cfg *Config) []error {
 			if gitPkg, ok := app.Package.GetGitPackage(); ok {
 				errs = append(errs, validateGitPackagePaths(app.Name, gitPkg)...)
 			}
+
+			errs = append(errs, validateURLInstalls(app.Name, app.Package.URL)...)
+
+			if prefer := app.Package.Prefer; prefer != "" {
+				if _, ok := app.Package.Managers[prefer]; !ok {
+					errs = append(errs, NewFieldError(app.Name, "package.prefer", prefer,
+						fmt.Errorf("must be one of the package's managers")))
+				}
+			}
 		}
 	}
 
 	return errs
 }
+
+// ValidateSymlinkCompat checks a symlink_compat value, which may be empty.
+func ValidateSymlinkCompat(mode string) error {
+	switch mode {
+	case "", SymlinkCompatSymlink, SymlinkCompatJunction:
+		return nil
+	}
+
+	return fmt.Errorf("must be %q or %q", SymlinkCompatSymlink, SymlinkCompatJunction)
+}
+
+// validateAfter reports package after lists that name the application itself
+// or an application that does not exist. A name that exists but is left out
+// on this machine is fine: after only orders packages installed together.
+func validateAfter(apps []Application) []error {
+	names := make(map[string]bool, len(apps))
+	for _, app := range apps {
+		names[app.Name] = true
+	}
+
+	var errs []error
+
+	for _, app := range apps {
+		if app.Package == nil {
+			continue
+		}
+
+		for _, name := range app.Package.After {
+			switch {
+			case name == app.Name:
+				errs = append(errs, NewFieldError(app.Name, "package.after", name,
+					fmt.Errorf("a package cannot be installed after itself")))
+			case !names[name]:
+				errs = append(errs, NewFieldError(app.Name, "package.after", name,
+					fmt.Errorf("no application with this name")))
+			}
+		}
+	}
+
+	return errs
+}
+
+// validateRequiredBy reports required_by lists that name the application
+// itself or an application that does not exist. Cycles are reported by
+// restore, which orders the applications it runs.
+func validateRequiredBy(apps []Application) []error {
+	names := make(map[string]bool, len(apps))
+	for _, app := range apps {
+		names[app.Name] = true
+	}
+
+	var errs []error
+
+	for _, app := range apps {
+		for _, name := range app.RequiredBy {
+			switch {
+			case name == app.Name:
+				errs = append(errs, NewFieldError(app.Name, "required_by", name,
+					fmt.Errorf("an application cannot be restored after itself")))
+			case !names[name]:
+				errs = append(errs, NewFieldError(app.Name, "required_by", name,
+					fmt.Errorf("no application with this name")))
+			}
+		}
+	}
+
+	return errs
+}
+
+// duplicateNameErrors reports duplicate names in apps: one error listing every
+// application name used more than once, and one per application listing its
+// duplicate entry names. Empty names are reported separately by ValidateConfig.
+func duplicateNameErrors(apps []Application) []error {
+	var errs []error
+
+	appNames := make([]string, 0, len(apps))
+	for _, app := range apps {
+		appNames = append(appNames, app.Name)
+	}
+
+	if dups := duplicates(appNames); len(dups) > 0 {
+		errs = append(errs, fmt.Errorf("%w: duplicate application names: %s", ErrInvalidConfig, quoteJoin(dups)))
+	}
+
+	for _, app := range apps {
+		if app.Name == "" {
+			continue
+		}
+
+		entryNames := make([]string, 0, len(app.Entries))
+		for _, entry := range app.Entries {
+			entryNames = append(entryNames, entry.Name)
+		}
+
+		if dups := duplicates(entryNames); len(dups) > 0 {
+			errs = append(errs, fmt.Errorf("%w: application %q has duplicate entry names: %s", ErrInvalidConfig, app.Name, quoteJoin(dups)))
+		}
+	}
+
+	return errs
+}
+
+// duplicates returns the non-empty names that appear more than once, in order
+// of first appearance.
+func duplicates(names []string) []string {
+	counts := make(map[string]int, len(names))
+	for _, name := range names {
+		counts[name]++
+	}
+
+	var dups []string
+
+	for _, name := range names {
+		if name != "" && counts[name] > 1 {
+			dups = append(dups, name)
+			counts[name] = 0
+		}
+	}
+
+	return dups
+}
+
+// quoteJoin formats names as a comma-separated list of quoted strings.
+func quoteJoin(names []string) string {
+	quoted := make([]string, len(names))
+	for i, name := range names {
+		quoted[i] = strconv.Quote(name)
+	}
+
+	return strings.Join(quoted, ", ")
+}
@@ -2,6 +2,8 @@ package config
 
 import (
 	"errors"
+	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 )
@@ -506,6 +508,81 @@ func TestValidateConfig_RejectsBadMethod(t *testing.T) {
 	}
 }
 
+func TestValidateConfig_LinkMode(t *testing.T) {
+	t.Parallel()
+
+	for _, tt := range []struct {
+		name    string
+		entry   SubEntry
+		wantErr bool
+	}{
+		{name: "dir", entry: SubEntry{LinkMode: LinkModeDir}},
+		{name: "files", entry: SubEntry{LinkMode: LinkModeFiles}},
+		{name: "unknown", entry: SubEntry{LinkMode: "hardlinks"}, wantErr: true},
+		{name: "files entry", entry: SubEntry{LinkMode: LinkModeFiles, Files: []string{"a"}}, wantErr: true},
+		{name: "sudo", entry: SubEntry{LinkMode: LinkModeFiles, Sudo: true}, wantErr: true},
+	} {
+		entry := tt.entry
+		entry.Name, entry.Backup = "e", "./b"
+		entry.Targets = map[string]string{"linux": "~/.local/share/applications"}
+
+		cfg := &Config{Version: 3, Applications: []Application{{Name: "app", Entries: []SubEntry{entry}}}}
+		if errs := ValidateConfig(cfg); (len(errs) > 0) != tt.wantErr {
+			t.Errorf("%s: errors = %v, wantErr %v", tt.name, errs, tt.wantErr)
+		}
+	}
+}
+
+func TestValidateConfig_RejectsBadEncoding(t *testing.T) {
+	t.Parallel()
+
+	for _, tt := range []struct {
+		encoding string
+		wantErr  bool
+	}{
+		{encoding: EncodingUTF8},
+		{encoding: EncodingLatin1},
+		{encoding: EncodingWindows1252},
+		{encoding: "Latin1", wantErr: true},
+		{encoding: "shift-jis", wantErr: true},
+	} {
+		cfg := &Config{Version: 3, Applications: []Application{{
+			Name: "app",
+			Entries: []SubEntry{{
+				Name: "e", Backup: "./b", Encoding: tt.encoding,
+				Targets: map[string]string{"linux": "~/.config/app"},
+			}},
+		}}}
+		if errs := ValidateConfig(cfg); (len(errs) > 0) != tt.wantErr {
+			t.Errorf("encoding %q: errors = %v, wantErr %v", tt.encoding, errs, tt.wantErr)
+		}
+	}
+}
+
+func TestValidateConfig_PackagePrefer(t *testing.T) {
+	t.Parallel()
+
+	for _, tt := range []struct {
+		prefer  string
+		wantErr bool
+	}{
+		{prefer: "pacman"},
+		{prefer: "apt", wantErr: true},
+	} {
+		cfg := &Config{Version: 3, Applications: []Application{{
+			Name: "vim",
+			Package: &EntryPackage{
+				Managers: map[string]ManagerValue{"pacman": {PackageName: "vim"}, "yay": {PackageName: "vim"}},
+				Prefer:   tt.prefer,
+			},
+		}}}
+
+		if errs := ValidateConfig(cfg); (len(errs) > 0) != tt.wantErr {
+			t.Errorf("prefer %s: ValidateConfig() = %v, want error %v", tt.prefer, errs, tt.wantErr)
+		}
+	}
+}
+
 func TestValidateConfig_RejectsCopyWithoutFiles(t *testing.T) {
 	t.Parallel()
 	cfg := &Config{Version: 3, Applications: []Application{{
@@ -548,6 +625,76 @@ func TestValidateConfig_AcceptsCopyWithFiles(t *testing.T) {
 	}
 }
 
+func TestValidateConfig_CaseRename(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		files   []string
+		rename  map[string]string
+		wantErr bool
+	}{
+		{name: "renames a listed file", files: []string{"Config.toml", "config.toml"}, rename: map[string]string{"config.toml": "config.lower.toml"}},
+		{name: "folder entry", rename: map[string]string{"config.toml": "config.lower.toml"}, wantErr: true},
+		{name: "empty name", files: []string{"a"}, rename: map[string]string{"a": ""}, wantErr: true},
+		{name: "pattern", files: []string{"*.toml"}, rename: map[string]string{"*.toml": "x"}, wantErr: true},
+		{name: "same name", files: []string{"a"}, rename: map[string]string{"a": "a"}, wantErr: true},
+		{name: "two files to one name", files: []string{"a", "b"}, rename: map[string]string{"a": "c", "b": "c"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			cfg := &Config{Version: 3, Applications: []Application{{
+				Name: "app",
+				Entries: []SubEntry{{
+					Name: "e", Backup: "./b", Files: tt.files, CaseRename: tt.rename,
+					Targets: map[string]string{"linux": "~/.config/app"},
+				}},
+			}}}
+
+			if errs := ValidateConfig(cfg); (len(errs) > 0) != tt.wantErr {
+				t.Errorf("ValidateConfig() = %v, want error %v", errs, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidateConfig_MaxFileSize(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name        string
+		entry       SubEntry
+		defaultSize int64
+		wantErr     bool
+	}{
+		{name: "set", entry: SubEntry{MaxFileSize: 1024}},
+		{name: "negative", entry: SubEntry{MaxFileSize: -1}, wantErr: true},
+		{name: "sudo", entry: SubEntry{MaxFileSize: 1024, Sudo: true}, wantErr: true},
+		{name: "negative default", defaultSize: -1, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			entry := tt.entry
+			entry.Name, entry.Backup = "e", "./b"
+			entry.Targets = map[string]string{"linux": "~/.config/app"}
+
+			cfg := &Config{Version: 3, DefaultMaxFileSize: tt.defaultSize, Applications: []Application{{
+				Name: "app", Entries: []SubEntry{entry},
+			}}}
+
+			if errs := ValidateConfig(cfg); (len(errs) > 0) != tt.wantErr {
+				t.Errorf("ValidateConfig() = %v, want error %v", errs, tt.wantErr)
+			}
+		})
+	}
+}
+
 func TestValidateSetupEntry(t *testing.T) {
 	tests := []struct {
 		name  string
@@ -656,6 +803,38 @@ func TestValidateSetupEntry(t *testing.T) {
 			wantFields:      []string{"check"},
 			wantMsgContains: []string{"requires a matching run command"},
 		},
+		{
+			name: "setup entry with post_restore",
+			entry: SubEntry{
+				Name:        "enable-service",
+				Check:       map[string]string{"linux": "check"},
+				Run:         map[string]string{"linux": "run"},
+				PostRestore: map[string]string{"linux": "fc-cache -f"},
+			},
+			wantFields:      []string{"post_restore"},
+			wantMsgContains: []string{"cannot declare post_restore"},
+		},
+		{
+			name: "empty post_restore command",
+			entry: SubEntry{
+				Name:        "fonts",
+				Targets:     map[string]string{"linux": "~/.local/share/fonts"},
+				Backup:      "./fonts",
+				PostRestore: map[string]string{"linux": " "},
+			},
+			wantFields:      []string{"post_restore[linux]"},
+			wantMsgContains: []string{"command cannot be empty"},
+		},
+		{
+			name: "config entry with post_restore",
+			entry: SubEntry{
+				Name:        "fonts",
+				Targets:     map[string]string{"linux": "~/.local/share/fonts"},
+				Backup:      "./fonts",
+				PostRestore: map[string]string{"linux": "fc-cache -f"},
+			},
+			wantFields: nil,
+		},
 		{
 			name: "plain config entry is unaffected",
 			entry: SubEntry{
@@ -709,3 +888,286 @@ func TestValidateSetupEntry(t *testing.T) {
 		})
 	}
 }
+
+func TestValidateConfig_URLInstallVerification(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		spec    URLInstallSpec
+		wantErr bool
+	}{
+		{name: "no verification", spec: URLInstallSpec{URL: "https://x"}},
+		{name: "valid sha256", spec: URLInstallSpec{URL: "https://x", SHA256: strings.Repeat("ab", 32), Size: 10}},
+		{name: "short sha256", spec: URLInstallSpec{URL: "https://x", SHA256: "abc"}, wantErr: true},
+		{name: "non-hex sha256", spec: URLInstallSpec{URL: "https://x", SHA256: strings.Repeat("zz", 32)}, wantErr: true},
+		{name: "negative size", spec: URLInstallSpec{URL: "https://x", Size: -1}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			cfg := &Config{
+				Version: 3,
+				Applications: []Application{{
+					Name:    "tool",
+					Package: &EntryPackage{URL: map[string]URLInstallSpec{"linux": tt.spec}},
+				}},
+			}
+
+			if errs := ValidateConfig(cfg); (len(errs) > 0) != tt.wantErr {
+				t.Errorf("ValidateConfig() errors = %v, wantErr %v", errs, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestLoad_ListsAllDuplicateNames(t *testing.T) {
+	t.Parallel()
+	dir := t.TempDir()
+
+	path := writeTestFile(t, dir, "tidydots.yaml", `version: 3
+applications:
+  - name: nvim
+  - name: zsh
+  - name: nvim
+  - name: zsh
+  - name: nvim
+  - name: git
+    entries:
+      - name: config
+        backup: ./git
+        targets: {linux: ~/.config/git}
+      - name: config
+        backup: ./git2
+        targets: {linux: ~/.config/git2}
+`)
+
+	_, err := Load(path)
+	if err == nil {
+		t.Fatal("Load() expected duplicate name error, got nil")
+	}
+
+	for _, want := range []string{
+		`duplicate application names: "nvim", "zsh"`,
+		`application "git" has duplicate entry names: "config"`,
+	} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("error %q does not contain %q", err.Error(), want)
+		}
+	}
+}
+
+func TestSave_RejectsDuplicateNames(t *testing.T) {
+	t.Parallel()
+	path := filepath.Join(t.TempDir(), "tidydots.yaml")
+
+	cfg := &Config{
+		Version:      3,
+		Applications: []Application{{Name: "nvim"}, {Name: "nvim"}},
+	}
+
+	err := Save(cfg, path)
+	if !errors.Is(err, ErrInvalidConfig) {
+		t.Fatalf("Save() error = %v, want ErrInvalidConfig", err)
+	}
+
+	if _, statErr := os.Stat(path); !errors.Is(statErr, os.ErrNotExist) {
+		t.Error("Save() wrote a config with duplicate names")
+	}
+}
+
+func TestValidateConfig_Notifications(t *testing.T) {
+	cfg := &Config{
+		Version:       3,
+		Notifications: &Notifications{On: "sometimes", Webhook: "ftp://example.com", Timeout: "soon"},
+	}
+
+	errs := ValidateConfig(cfg)
+	if len(errs) != 3 {
+		t.Errorf("ValidateConfig() = %v, want errors for on, webhook and timeout", errs)
+	}
+}
+
+func TestValidateConfig_Dedupe(t *testing.T) {
+	entry := func(sub SubEntry) *Config {
+		sub.Name = "e"
+		sub.Dedupe = true
+		return &Config{Version: 3, Applications: []Application{{Name: "app", Entries: []SubEntry{sub}}}}
+	}
+
+	tests := []struct {
+		name    string
+		cfg     *Config
+		wantErr bool
+	}{
+		{"files entry", entry(SubEntry{Backup: "./b", Files: []string{"f"}, Targets: map[string]string{"linux": "~"}}), false},
+		{"sudo entry", entry(SubEntry{Backup: "./b", Sudo: true, Targets: map[string]string{"linux": "/etc"}}), true},
+		{"setup entry", entry(SubEntry{Check: map[string]string{"linux": "true"}, Run: map[string]string{"linux": "true"}}), true},
+		{"valid threshold", &Config{Version: 3, Dedupe: &Dedupe{Threshold: "4MiB"}}, false},
+		{"invalid threshold", &Config{Version: 3, Dedupe: &Dedupe{Threshold: "big"}}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if errs := ValidateConfig(tt.cfg); (len(errs) > 0) != tt.wantErr {
+				t.Errorf("ValidateConfig() = %v, wantErr %v", errs, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestParseSize(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    int64
+		wantErr bool
+	}{
+		{"1048576", 1 << 20, false},
+		{"512KiB", 512 << 10, false},
+		{"4 MB", 4 << 20, false},
+		{"2G", 2 << 30, false},
+		{"10B", 10, false},
+		{"-1", 0, true},
+		{"MiB", 0, true},
+		{"lots", 0, true},
+	}
+
+	for _, tt := range tests {
+		got, err := ParseSize(tt.in)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("ParseSize(%q) = %d, %v; want %d, wantErr %v", tt.in, got, err, tt.want, tt.wantErr)
+		}
+	}
+
+	if got := (&Config{}).DedupeThreshold(); got != DefaultDedupeThreshold {
+		t.Errorf("default DedupeThreshold() = %d, want %d", got, DefaultDedupeThreshold)
+	}
+}
+
+func TestValidateConfig_After(t *testing.T) {
+	cfg := &Config{
+		Version: 3,
+		Applications: []Application{
+			{Name: "rust", Package: &EntryPackage{Managers: map[string]ManagerValue{"pacman": {PackageName: "rustup"}}}},
+			{Name: "cargo-tools", Package: &EntryPackage{After: []string{"rust", "cargo-tools", "nodejs"}}},
+		},
+	}
+
+	errs := ValidateConfig(cfg)
+	if len(errs) != 2 {
+		t.Fatalf("ValidateConfig() = %v, want errors for the self reference and the unknown name", errs)
+	}
+
+	for i, want := range []string{"after itself", "no application"} {
+		if !strings.Contains(errs[i].Error(), want) {
+			t.Errorf("error %d = %v, want it to mention %q", i, errs[i], want)
+		}
+	}
+}
+
+func TestValidateConfig_RequiredBy(t *testing.T) {
+	cfg := &Config{
+		Version: 3,
+		Applications: []Application{
+			{Name: "fonts"},
+			{Name: "kitty", RequiredBy: []string{"fonts", "kitty", "icons"}},
+		},
+	}
+
+	errs := ValidateConfig(cfg)
+	if len(errs) != 2 {
+		t.Fatalf("ValidateConfig() = %v, want errors for the self reference and the unknown name", errs)
+	}
+
+	for i, want := range []string{"after itself", "no application"} {
+		if !strings.Contains(errs[i].Error(), want) || !strings.Contains(errs[i].Error(), "required_by") {
+			t.Errorf("error %d = %v, want a required_by error mentioning %q", i, errs[i], want)
+		}
+	}
+}
+
+func TestValidateConfig_SymlinkCompat(t *testing.T) {
+	for _, mode := range []string{"", SymlinkCompatSymlink, SymlinkCompatJunction} {
+		if errs := ValidateConfig(&Config{Version: 3, SymlinkCompat: mode}); len(errs) != 0 {
+			t.Errorf("ValidateConfig(symlink_compat: %q) = %v, want no errors", mode, errs)
+		}
+	}
+
+	errs := ValidateConfig(&Config{Version: 3, SymlinkCompat: "hardlink"})
+	if len(errs) != 1 || !strings.Contains(errs[0].Error(), "symlink_compat") {
+		t.Errorf("ValidateConfig(symlink_compat: hardlink) = %v, want one symlink_compat error", errs)
+	}
+}
+
+func TestValidateConfig_Priority(t *testing.T) {
+	apps := []Application{{Name: "pacman", Priority: 10}, {Name: "zsh"}}
+	if errs := ValidateConfig(&Config{Version: 3, Applications: apps}); len(errs) != 0 {
+		t.Errorf("ValidateConfig() = %v, want no errors", errs)
+	}
+
+	errs := ValidateConfig(&Config{Version: 3, Applications: []Application{{Name: "zsh", Priority: -1}}})
+	if len(errs) != 1 || !strings.Contains(errs[0].Error(), "priority") {
+		t.Errorf("ValidateConfig(priority: -1) = %v, want one priority error", errs)
+	}
+}
+
+func TestValidateConfig_Vars(t *testing.T) {
+	if errs := ValidateConfig(&Config{Version: 3, Vars: map[string]string{"app": "nvim", "THEME_2": "dark"}}); len(errs) != 0 {
+		t.Errorf("ValidateConfig() = %v, want no errors", errs)
+	}
+
+	errs := ValidateConfig(&Config{Version: 3, Vars: map[string]string{"my-app": "nvim"}})
+	if len(errs) != 1 || !strings.Contains(errs[0].Error(), "vars") {
+		t.Errorf("ValidateConfig(vars: my-app) = %v, want one vars error", errs)
+	}
+}
+
+func TestValidateConfig_DangerousTargets(t *testing.T) {
+	validate := func(entry SubEntry) []error {
+		entry.Name, entry.Backup = "e", "./e"
+		return ValidateConfig(&Config{Version: 3, Applications: []Application{{Name: "app", Entries: []SubEntry{entry}}}})
+	}
+
+	for _, target := range []string{"/", "/etc/", "/usr", "~", `C:\Windows`, "c:/windows"} {
+		errs := validate(SubEntry{Targets: map[string]string{"linux": target}})
+		if len(errs) != 1 || !strings.Contains(errs[0].Error(), "app/e") || !strings.Contains(errs[0].Error(), "allow_dangerous") {
+			t.Errorf("folder target %q: errs = %v, want one allow_dangerous error naming app/e", target, errs)
+		}
+
+		if errs := validate(SubEntry{Targets: map[string]string{"linux": target}, AllowDangerous: true}); len(errs) != 0 {
+			t.Errorf("folder target %q with allow_dangerous: errs = %v, want none", target, errs)
+		}
+	}
+
+	// A files entry links files into its target, which is common for ~ and /etc.
+	for _, target := range []string{"~", "/etc"} {
+		if errs := validate(SubEntry{Files: []string{"f"}, Targets: map[string]string{"linux": target}}); len(errs) != 0 {
+			t.Errorf("files target %q: errs = %v, want none", target, errs)
+		}
+	}
+
+	if errs := validate(SubEntry{Targets: map[string]string{"linux": "/etc/nginx"}}); len(errs) != 0 {
+		t.Errorf("folder target /etc/nginx: errs = %v, want none", errs)
+	}
+}
+
+func TestDangerousTarget(t *testing.T) {
+	tests := map[string]string{
+		"/":                    "a system directory",
+		"/usr/local/..":        "a system directory",
+		"/home/user/../../etc": "a system directory",
+		"/home/user/":          "the home directory",
+		`C:\`:                  "a system directory",
+		`c:\Program Files`:     "a system directory",
+		"/etc/nginx":           "",
+		"/home/user/.config":   "",
+	}
+
+	for target, want := range tests {
+		if got := DangerousTarget(target, "/home/user"); got != want {
+			t.Errorf("DangerousTarget(%q) = %q, want %q", target, got, want)
+		}
+	}
+}
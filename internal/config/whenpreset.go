@@ -0,0 +1,67 @@
+package config
+
+import (
+	"fmt"
+	"log/slog"
+)
+
+// NamedWhen is one of the config's when_presets: a when expression that
+// applications share by naming it in when_ref.
+type NamedWhen struct {
+	Name string
+	When string
+}
+
+// PresetWhens returns the when_presets app names in its when_ref, in that
+// order. Names with no preset are skipped; ValidateConfig reports them.
+func (c *Config) PresetWhens(app *Application) []NamedWhen {
+	presets := make([]NamedWhen, 0, len(app.WhenRef))
+
+	for _, name := range app.WhenRef {
+		if when, ok := c.WhenPresets[name]; ok {
+			presets = append(presets, NamedWhen{Name: name, When: when})
+		}
+	}
+
+	return presets
+}
+
+// MatchesWhen reports whether app applies to this machine: the when
+// expression of every preset in its when_ref and its own when all match. See
+// EvaluateWhenWithLogger for how each is evaluated and logged.
+func (c *Config) MatchesWhen(app *Application, renderer PathRenderer, logger *slog.Logger) bool {
+	for _, preset := range c.PresetWhens(app) {
+		if !EvaluateWhenWithLogger(preset.When, renderer, logger) {
+			return false
+		}
+	}
+
+	return EvaluateWhenWithLogger(app.When, renderer, logger)
+}
+
+// validateWhenRefs reports the when_ref names of apps that name no preset of
+// presets, and the names an application references twice.
+func validateWhenRefs(presets map[string]string, apps []Application) []error {
+	var errs []error
+
+	for _, app := range apps {
+		seen := make(map[string]bool, len(app.WhenRef))
+
+		for _, name := range app.WhenRef {
+			_, known := presets[name]
+
+			switch {
+			case seen[name]:
+				errs = append(errs, NewFieldError(app.Name, "when_ref", name,
+					fmt.Errorf("referenced twice")))
+			case !known:
+				errs = append(errs, NewFieldError(app.Name, "when_ref", name,
+					fmt.Errorf("no when preset with this name")))
+			}
+
+			seen[name] = true
+		}
+	}
+
+	return errs
+}
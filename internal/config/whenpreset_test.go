@@ -0,0 +1,126 @@
+package config
+
+import (
+	"strings"
+	"testing"
+)
+
+// exprRenderer renders a when expression to its value in results; an
+// expression it does not know renders to "false".
+type exprRenderer map[string]string
+
+func (r exprRenderer) RenderString(_, tmpl string) (string, error) {
+	if v, ok := r[tmpl]; ok {
+		return v, nil
+	}
+
+	return "false", nil
+}
+
+func TestLoad_WhenPresets(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	path := writeTestFile(t, dir, "tidydots.yaml", `version: 3
+when_presets:
+  linux: "{{ linux }}"
+  desktop: "{{ desktop }}"
+applications:
+  - name: kitty
+    when_ref: [linux, desktop]
+  - name: sway
+    when_ref: [linux]
+    when: "{{ wayland }}"
+  - name: zsh
+    when_ref: [linux]
+    when: "{{ zsh }}"
+  - name: git
+`)
+
+	cfg, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+
+	presets := cfg.PresetWhens(&cfg.Applications[0])
+	if len(presets) != 2 || presets[0] != (NamedWhen{Name: "linux", When: "{{ linux }}"}) || presets[1].Name != "desktop" {
+		t.Errorf("PresetWhens(kitty) = %+v, want linux then desktop", presets)
+	}
+
+	renderer := exprRenderer{"{{ linux }}": "true", "{{ zsh }}": "true"}
+
+	var names []string
+	for _, app := range cfg.GetMatchingApplicationsWithLogger(renderer, nil) {
+		names = append(names, app.Name)
+	}
+
+	if got := strings.Join(names, ","); got != "zsh,git" {
+		t.Errorf("matching applications = %s, want zsh,git", got)
+	}
+}
+
+func TestValidateConfig_WhenRef(t *testing.T) {
+	t.Parallel()
+
+	cfg := &Config{
+		Version:     3,
+		WhenPresets: map[string]string{"linux": `{{ eq .OS "linux" }}`},
+		Applications: []Application{
+			{Name: "nvim", WhenRef: []string{"linux"}},
+			{Name: "kitty", WhenRef: []string{"desktop"}},
+			{Name: "zsh", WhenRef: []string{"linux", "linux"}},
+		},
+	}
+
+	errs := ValidateConfig(cfg)
+	if len(errs) != 2 {
+		t.Fatalf("ValidateConfig() = %v, want 2 errors", errs)
+	}
+
+	if !strings.Contains(errs[0].Error(), "kitty") || !strings.Contains(errs[0].Error(), "no when preset") {
+		t.Errorf("errs[0] = %v, want kitty's unknown preset", errs[0])
+	}
+
+	if !strings.Contains(errs[1].Error(), "zsh") || !strings.Contains(errs[1].Error(), "referenced twice") {
+		t.Errorf("errs[1] = %v, want zsh's repeated preset", errs[1])
+	}
+}
+
+func TestExportApplications_WhenPresets(t *testing.T) {
+	t.Parallel()
+
+	cfg := &Config{
+		Version: 3,
+		WhenPresets: map[string]string{
+			"linux":   `{{ eq .OS "linux" }}`,
+			"desktop": `{{ not .IsHeadless }}`,
+		},
+		Applications: []Application{
+			{Name: "nvim", WhenRef: []string{"linux"}},
+			{Name: "kitty", WhenRef: []string{"desktop"}},
+			{Name: "git"},
+		},
+	}
+
+	got, err := ExportApplications(cfg, []string{"nvim", "git"})
+	if err != nil {
+		t.Fatalf("ExportApplications() error = %v", err)
+	}
+
+	if len(got.WhenPresets) != 1 || got.WhenPresets["linux"] != cfg.WhenPresets["linux"] {
+		t.Errorf("exported when_presets = %v, want only linux", got.WhenPresets)
+	}
+
+	if errs := ValidateConfig(got); len(errs) != 0 {
+		t.Errorf("exported config does not validate: %v", errs)
+	}
+
+	got, err = ExportApplications(cfg, []string{"git"})
+	if err != nil {
+		t.Fatalf("ExportApplications() error = %v", err)
+	}
+
+	if got.WhenPresets != nil {
+		t.Errorf("exported when_presets = %v, want none", got.WhenPresets)
+	}
+}
@@ -326,6 +326,7 @@ func (m *MemFS) Rename(oldpath, newpath string) error {
 
 	if data, ok := m.files[oldpath]; ok {
 		perm := m.perms[oldpath]
+		delete(m.symlinks, newpath)
 		m.files[newpath] = data
 		m.perms[newpath] = perm
 		delete(m.files, oldpath)
@@ -333,6 +334,8 @@ func (m *MemFS) Rename(oldpath, newpath string) error {
 		return nil
 	}
 	if target, ok := m.symlinks[oldpath]; ok {
+		delete(m.files, newpath)
+		delete(m.perms, newpath)
 		m.symlinks[newpath] = target
 		delete(m.symlinks, oldpath)
 		return nil
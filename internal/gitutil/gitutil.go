@@ -0,0 +1,250 @@
+// Package gitutil runs the git commands tidydots uses to clone, inspect and
+// update repositories: the git packages installed by `tidydots install` and
+// managed by `tidydots repos`.
+package gitutil
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/AntoineGS/tidydots/internal/cmdexec"
+)
+
+const cmdGit = "git"
+
+// ErrLocalChanges is returned by Update when the work tree has uncommitted
+// changes and stashing them was not asked for.
+var ErrLocalChanges = errors.New("repository has local changes")
+
+// ErrNoUpstream is returned by Update when the checked-out branch does not
+// track an upstream branch.
+var ErrNoUpstream = errors.New("branch has no upstream")
+
+// Status describes a clone's checked-out branch relative to its upstream.
+// Ahead and Behind count commits as of the last fetch.
+type Status struct {
+	Branch   string // empty when HEAD is detached
+	Upstream string // empty when the branch tracks no upstream
+	Ahead    int
+	Behind   int
+	Dirty    bool // uncommitted changes, untracked files included
+}
+
+// IsCloned reports whether path is a git work tree, i.e. has a .git entry
+// (a directory, or a file for worktrees and submodules).
+func IsCloned(path string) bool {
+	_, err := os.Stat(filepath.Join(path, ".git"))
+	return err == nil
+}
+
+// CloneOptions are the optional settings of a clone.
+type CloneOptions struct {
+	// Branch is checked out instead of the remote's default branch.
+	Branch string
+	// Depth makes a shallow clone of that many commits; 0 clones the whole
+	// history.
+	Depth int
+	// Sparse lists sparse-checkout patterns; when empty every file is
+	// checked out.
+	Sparse []string
+}
+
+// CloneArgs returns the git arguments that clone url into path with opts. A
+// sparse clone only checks out the top-level files; SparseCheckoutArgs then
+// selects the rest.
+func CloneArgs(url, path string, opts CloneOptions) []string {
+	args := []string{"clone"}
+	if opts.Branch != "" {
+		args = append(args, "-b", opts.Branch)
+	}
+
+	if opts.Depth > 0 {
+		args = append(args, "--depth", strconv.Itoa(opts.Depth))
+	}
+
+	if len(opts.Sparse) > 0 {
+		args = append(args, "--sparse")
+	}
+
+	return append(args, url, path)
+}
+
+// SparseCheckoutArgs returns the git arguments that restrict the work tree
+// at path to files matching patterns, in gitignore syntax.
+func SparseCheckoutArgs(path string, patterns []string) []string {
+	return append([]string{"-C", path, "sparse-checkout", "set", "--no-cone"}, patterns...)
+}
+
+// Clone clones url into path with opts, through sudo when sudo is set.
+func Clone(ctx context.Context, r cmdexec.Runner, url, path string, opts CloneOptions, sudo bool) error {
+	if _, err := run(ctx, r, sudo, CloneArgs(url, path, opts)...); err != nil {
+		return err
+	}
+
+	if len(opts.Sparse) == 0 {
+		return nil
+	}
+
+	if _, err := run(ctx, r, sudo, SparseCheckoutArgs(path, opts.Sparse)...); err != nil {
+		return fmt.Errorf("setting sparse checkout: %w", err)
+	}
+
+	return nil
+}
+
+// PullArgs returns the git commands that update the repository at path,
+// cloned with opts. A shallow clone fetches only the last opts.Depth commits
+// and resets to them, discarding local changes, since a merge would need the
+// history it does not have.
+func PullArgs(path string, opts CloneOptions) [][]string {
+	if opts.Depth <= 0 {
+		return [][]string{{"-C", path, "pull"}}
+	}
+
+	upstream := "origin/HEAD"
+	if opts.Branch != "" {
+		upstream = "origin/" + opts.Branch
+	}
+
+	return [][]string{
+		{"-C", path, "fetch", "--depth", strconv.Itoa(opts.Depth)},
+		{"-C", path, "reset", "--hard", upstream},
+	}
+}
+
+// Pull updates the repository at path, cloned with opts, through sudo when
+// sudo is set. See PullArgs.
+func Pull(ctx context.Context, r cmdexec.Runner, path string, opts CloneOptions, sudo bool) error {
+	for _, args := range PullArgs(path, opts) {
+		if _, err := run(ctx, r, sudo, args...); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// ReadStatus returns the status of the clone at path. It does not fetch.
+func ReadStatus(ctx context.Context, r cmdexec.Runner, path string) (Status, error) {
+	var st Status
+
+	if res, err := run(ctx, r, false, "-C", path, "symbolic-ref", "--quiet", "--short", "HEAD"); err == nil {
+		st.Branch = strings.TrimSpace(string(res.Stdout))
+	}
+
+	if res, err := run(ctx, r, false, "-C", path, "rev-parse", "--abbrev-ref", "--symbolic-full-name", "@{upstream}"); err == nil {
+		st.Upstream = strings.TrimSpace(string(res.Stdout))
+	}
+
+	if st.Upstream != "" {
+		res, err := run(ctx, r, false, "-C", path, "rev-list", "--left-right", "--count", "HEAD...@{upstream}")
+		if err != nil {
+			return st, fmt.Errorf("comparing with %s: %w", st.Upstream, err)
+		}
+
+		if st.Ahead, st.Behind, err = parseLeftRight(res.Stdout); err != nil {
+			return st, fmt.Errorf("comparing with %s: %w", st.Upstream, err)
+		}
+	}
+
+	res, err := run(ctx, r, false, "-C", path, "status", "--porcelain")
+	if err != nil {
+		return st, fmt.Errorf("reading work tree status: %w", err)
+	}
+
+	st.Dirty = len(strings.TrimSpace(string(res.Stdout))) > 0
+
+	return st, nil
+}
+
+// Update fetches the clone at path and fast-forwards its branch to the
+// upstream. A clone with local changes is refused with ErrLocalChanges unless
+// stash is set, in which case the changes are stashed around the update and
+// reapplied. A branch that has diverged from its upstream is left untouched.
+func Update(ctx context.Context, r cmdexec.Runner, path string, stash, sudo bool) error {
+	st, err := ReadStatus(ctx, r, path)
+	if err != nil {
+		return err
+	}
+
+	if st.Upstream == "" {
+		return ErrNoUpstream
+	}
+
+	if st.Dirty && !stash {
+		return ErrLocalChanges
+	}
+
+	if _, err := run(ctx, r, sudo, "-C", path, "fetch", "--quiet"); err != nil {
+		return fmt.Errorf("fetching: %w", err)
+	}
+
+	if st.Dirty {
+		if _, err := run(ctx, r, sudo, "-C", path, "stash", "push", "--include-untracked", "--quiet", "-m", "tidydots repos update"); err != nil {
+			return fmt.Errorf("stashing local changes: %w", err)
+		}
+	}
+
+	_, mergeErr := run(ctx, r, sudo, "-C", path, "merge", "--ff-only", "--quiet", "@{upstream}")
+
+	if st.Dirty {
+		if _, err := run(ctx, r, sudo, "-C", path, "stash", "pop", "--quiet"); err != nil {
+			return errors.Join(mergeErr, fmt.Errorf("reapplying stashed changes, they are kept in git stash: %w", err))
+		}
+	}
+
+	if mergeErr != nil {
+		return fmt.Errorf("fast-forwarding to %s: %w", st.Upstream, mergeErr)
+	}
+
+	return nil
+}
+
+// run runs git with args, through sudo when sudo is set. A failure carries
+// git's error output.
+func run(ctx context.Context, r cmdexec.Runner, sudo bool, args ...string) (cmdexec.Result, error) {
+	var (
+		res cmdexec.Result
+		err error
+	)
+
+	if sudo {
+		res, err = r.RunWithSudo(ctx, cmdGit, args...) //nolint:gosec // args built by this package
+	} else {
+		res, err = r.Run(ctx, cmdGit, args...) //nolint:gosec // args built by this package
+	}
+
+	if err != nil {
+		if msg := strings.TrimSpace(string(res.Stderr)); msg != "" {
+			return res, fmt.Errorf("%w: %s", err, msg)
+		}
+
+		return res, err
+	}
+
+	return res, nil
+}
+
+// parseLeftRight parses the "<left>\t<right>" output of
+// `git rev-list --left-right --count`.
+func parseLeftRight(out []byte) (left, right int, err error) {
+	fields := strings.Fields(string(out))
+	if len(fields) != 2 {
+		return 0, 0, fmt.Errorf("unexpected rev-list output %q", out)
+	}
+
+	if left, err = strconv.Atoi(fields[0]); err != nil {
+		return 0, 0, err
+	}
+
+	if right, err = strconv.Atoi(fields[1]); err != nil {
+		return 0, 0, err
+	}
+
+	return left, right, nil
+}
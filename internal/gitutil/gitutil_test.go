@@ -0,0 +1,304 @@
+package gitutil
+
+import (
+	"context"
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/AntoineGS/tidydots/internal/cmdexec"
+	"github.com/AntoineGS/tidydots/internal/platform"
+)
+
+// git runs a git command for test setup and returns its trimmed output.
+func git(t *testing.T, args ...string) string {
+	t.Helper()
+
+	cmd := exec.CommandContext(context.Background(), "git", args...) //nolint:gosec // test command
+	cmd.Env = append(os.Environ(),
+		"GIT_AUTHOR_NAME=Test User", "GIT_AUTHOR_EMAIL=test@example.com",
+		"GIT_COMMITTER_NAME=Test User", "GIT_COMMITTER_EMAIL=test@example.com")
+
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		t.Fatalf("git %v: %v\n%s", args, err, out)
+	}
+
+	return strings.TrimSpace(string(out))
+}
+
+// commit writes name in the work tree at dir and commits it.
+func commit(t *testing.T, dir, name, content string) {
+	t.Helper()
+
+	if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	git(t, "-C", dir, "add", name)
+	git(t, "-C", dir, "commit", "-q", "-m", "update "+name)
+}
+
+// newUpstream creates a bare repository with one commit on main and returns
+// its path along with a second clone used to push new upstream commits.
+func newUpstream(t *testing.T) (bare, pusher string) {
+	t.Helper()
+
+	if !platform.IsCommandAvailable("git") {
+		t.Skip("git not available for testing")
+	}
+
+	tmpDir := t.TempDir()
+	bare = filepath.Join(tmpDir, "upstream.git")
+	pusher = filepath.Join(tmpDir, "pusher")
+
+	git(t, "init", "-q", "--bare", "--initial-branch=main", bare)
+	git(t, "clone", "-q", bare, pusher)
+	git(t, "-C", pusher, "checkout", "-q", "-b", "main")
+	commit(t, pusher, "README", "first\n")
+	git(t, "-C", pusher, "push", "-q", "origin", "main")
+
+	return bare, pusher
+}
+
+func cloneUpstream(t *testing.T, bare string) string {
+	t.Helper()
+
+	dest := filepath.Join(t.TempDir(), "clone")
+	if err := Clone(context.Background(), cmdexec.OsRunner{}, bare, dest, CloneOptions{Branch: "main"}, false); err != nil {
+		t.Fatalf("Clone() error = %v", err)
+	}
+
+	return dest
+}
+
+func TestClone(t *testing.T) {
+	bare, _ := newUpstream(t)
+	dest := cloneUpstream(t, bare)
+
+	if !IsCloned(dest) {
+		t.Errorf("IsCloned(%q) = false after Clone", dest)
+	}
+
+	if IsCloned(filepath.Dir(dest)) {
+		t.Error("IsCloned() = true for a directory without .git")
+	}
+}
+
+func TestReadStatus(t *testing.T) {
+	bare, pusher := newUpstream(t)
+	dest := cloneUpstream(t, bare)
+	runner := cmdexec.OsRunner{}
+
+	st, err := ReadStatus(context.Background(), runner, dest)
+	if err != nil {
+		t.Fatalf("ReadStatus() error = %v", err)
+	}
+
+	want := Status{Branch: "main", Upstream: "origin/main"}
+	if st != want {
+		t.Errorf("ReadStatus() = %+v, want %+v", st, want)
+	}
+
+	commit(t, pusher, "remote.txt", "remote\n")
+	git(t, "-C", pusher, "push", "-q")
+	commit(t, dest, "local1.txt", "1\n")
+	commit(t, dest, "local2.txt", "2\n")
+	git(t, "-C", dest, "fetch", "-q")
+
+	if err := os.WriteFile(filepath.Join(dest, "README"), []byte("edited\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	st, err = ReadStatus(context.Background(), runner, dest)
+	if err != nil {
+		t.Fatalf("ReadStatus() error = %v", err)
+	}
+
+	want = Status{Branch: "main", Upstream: "origin/main", Ahead: 2, Behind: 1, Dirty: true}
+	if st != want {
+		t.Errorf("ReadStatus() = %+v, want %+v", st, want)
+	}
+}
+
+func TestUpdate_FastForwards(t *testing.T) {
+	bare, pusher := newUpstream(t)
+	dest := cloneUpstream(t, bare)
+
+	commit(t, pusher, "remote.txt", "remote\n")
+	git(t, "-C", pusher, "push", "-q")
+
+	if err := Update(context.Background(), cmdexec.OsRunner{}, dest, false, false); err != nil {
+		t.Fatalf("Update() error = %v", err)
+	}
+
+	if got, want := git(t, "-C", dest, "rev-parse", "HEAD"), git(t, "-C", pusher, "rev-parse", "HEAD"); got != want {
+		t.Errorf("HEAD = %s, want upstream %s", got, want)
+	}
+}
+
+func TestUpdate_RefusesLocalChanges(t *testing.T) {
+	bare, pusher := newUpstream(t)
+	dest := cloneUpstream(t, bare)
+	before := git(t, "-C", dest, "rev-parse", "HEAD")
+
+	commit(t, pusher, "remote.txt", "remote\n")
+	git(t, "-C", pusher, "push", "-q")
+
+	if err := os.WriteFile(filepath.Join(dest, "README"), []byte("edited\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	err := Update(context.Background(), cmdexec.OsRunner{}, dest, false, false)
+	if !errors.Is(err, ErrLocalChanges) {
+		t.Fatalf("Update() error = %v, want ErrLocalChanges", err)
+	}
+
+	if got := git(t, "-C", dest, "rev-parse", "HEAD"); got != before {
+		t.Errorf("HEAD moved to %s although the update was refused", got)
+	}
+}
+
+func TestUpdate_StashKeepsLocalChanges(t *testing.T) {
+	bare, pusher := newUpstream(t)
+	dest := cloneUpstream(t, bare)
+
+	commit(t, pusher, "remote.txt", "remote\n")
+	git(t, "-C", pusher, "push", "-q")
+
+	if err := os.WriteFile(filepath.Join(dest, "README"), []byte("edited\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := Update(context.Background(), cmdexec.OsRunner{}, dest, true, false); err != nil {
+		t.Fatalf("Update() error = %v", err)
+	}
+
+	if got, want := git(t, "-C", dest, "rev-parse", "HEAD"), git(t, "-C", pusher, "rev-parse", "HEAD"); got != want {
+		t.Errorf("HEAD = %s, want upstream %s", got, want)
+	}
+
+	data, err := os.ReadFile(filepath.Join(dest, "README")) //nolint:gosec // test file path is controlled
+	if err != nil || string(data) != "edited\n" {
+		t.Errorf("README = %q, %v; want the local edit back", data, err)
+	}
+
+	if stashes := git(t, "-C", dest, "stash", "list"); stashes != "" {
+		t.Errorf("stash should be empty after the update, got %q", stashes)
+	}
+}
+
+func TestUpdate_DivergedIsLeftAlone(t *testing.T) {
+	bare, pusher := newUpstream(t)
+	dest := cloneUpstream(t, bare)
+
+	commit(t, pusher, "remote.txt", "remote\n")
+	git(t, "-C", pusher, "push", "-q")
+	commit(t, dest, "local.txt", "local\n")
+	before := git(t, "-C", dest, "rev-parse", "HEAD")
+
+	if err := Update(context.Background(), cmdexec.OsRunner{}, dest, false, false); err == nil {
+		t.Fatal("Update() should fail when the branch cannot be fast-forwarded")
+	}
+
+	if got := git(t, "-C", dest, "rev-parse", "HEAD"); got != before {
+		t.Errorf("HEAD moved to %s on a diverged branch", got)
+	}
+}
+
+func TestUpdate_NoUpstream(t *testing.T) {
+	if !platform.IsCommandAvailable("git") {
+		t.Skip("git not available for testing")
+	}
+
+	dir := t.TempDir()
+	git(t, "init", "-q", dir)
+	commit(t, dir, "README", "first\n")
+
+	if err := Update(context.Background(), cmdexec.OsRunner{}, dir, false, false); !errors.Is(err, ErrNoUpstream) {
+		t.Errorf("Update() error = %v, want ErrNoUpstream", err)
+	}
+}
+
+func TestCloneArgs(t *testing.T) {
+	if got := strings.Join(CloneArgs("https://example.com/r.git", "/tmp/r", CloneOptions{}), " "); got != "clone https://example.com/r.git /tmp/r" {
+		t.Errorf("CloneArgs() = %q", got)
+	}
+
+	if got := strings.Join(CloneArgs("https://example.com/r.git", "/tmp/r", CloneOptions{Branch: "dev"}), " "); got != "clone -b dev https://example.com/r.git /tmp/r" {
+		t.Errorf("CloneArgs() with branch = %q", got)
+	}
+
+	opts := CloneOptions{Branch: "dev", Depth: 1, Sparse: []string{"lua/"}}
+	if got := strings.Join(CloneArgs("https://example.com/r.git", "/tmp/r", opts), " "); got != "clone -b dev --depth 1 --sparse https://example.com/r.git /tmp/r" {
+		t.Errorf("CloneArgs() shallow and sparse = %q", got)
+	}
+}
+
+func TestPullArgs(t *testing.T) {
+	tests := []struct {
+		name string
+		opts CloneOptions
+		want string
+	}{
+		{"full history", CloneOptions{}, "-C /tmp/r pull"},
+		{"shallow", CloneOptions{Depth: 1, Branch: "dev"}, "-C /tmp/r fetch --depth 1 && -C /tmp/r reset --hard origin/dev"},
+		{"shallow default branch", CloneOptions{Depth: 3}, "-C /tmp/r fetch --depth 3 && -C /tmp/r reset --hard origin/HEAD"},
+	}
+
+	for _, tt := range tests {
+		var lines []string
+		for _, args := range PullArgs("/tmp/r", tt.opts) {
+			lines = append(lines, strings.Join(args, " "))
+		}
+
+		if got := strings.Join(lines, " && "); got != tt.want {
+			t.Errorf("%s: PullArgs() = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestClone_ShallowSparse(t *testing.T) {
+	bare, pusher := newUpstream(t)
+
+	if err := os.MkdirAll(filepath.Join(pusher, "lua"), 0750); err != nil {
+		t.Fatal(err)
+	}
+	commit(t, pusher, filepath.Join("lua", "init.lua"), "return {}\n")
+	commit(t, pusher, "big.bin", "data\n")
+	git(t, "-C", pusher, "push", "-q", "origin", "main")
+
+	// --depth is ignored for plain local paths, so clone through file://.
+	dest := filepath.Join(t.TempDir(), "clone")
+	opts := CloneOptions{Branch: "main", Depth: 1, Sparse: []string{"/lua/"}}
+	if err := Clone(context.Background(), cmdexec.OsRunner{}, "file://"+filepath.ToSlash(bare), dest, opts, false); err != nil {
+		t.Fatalf("Clone() error = %v", err)
+	}
+
+	if got := git(t, "-C", dest, "rev-list", "--count", "HEAD"); got != "1" {
+		t.Errorf("shallow clone has %s commits, want 1", got)
+	}
+
+	if _, err := os.Stat(filepath.Join(dest, "lua", "init.lua")); err != nil {
+		t.Errorf("sparse pattern file not checked out: %v", err)
+	}
+
+	if _, err := os.Stat(filepath.Join(dest, "big.bin")); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("file outside the sparse patterns was checked out: %v", err)
+	}
+
+	commit(t, pusher, filepath.Join("lua", "init.lua"), "return { updated = true }\n")
+	git(t, "-C", pusher, "push", "-q", "origin", "main")
+
+	if err := Pull(context.Background(), cmdexec.OsRunner{}, dest, opts, false); err != nil {
+		t.Fatalf("Pull() error = %v", err)
+	}
+
+	content, err := os.ReadFile(filepath.Join(dest, "lua", "init.lua")) //nolint:gosec // test file
+	if err != nil || !strings.Contains(string(content), "updated") {
+		t.Errorf("shallow pull did not update the clone: %q, %v", content, err)
+	}
+}
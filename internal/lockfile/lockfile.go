@@ -0,0 +1,262 @@
+// Package lockfile pins the content of every backed-up file of a dotfiles
+// repository in tidydots.lock, the way a package manager's lockfile pins
+// dependency versions. The lockfile is committed with the dotfiles, and
+// `tidydots verify --strict` reports every file that drifted since it was
+// written by `tidydots pin`.
+package lockfile
+
+import (
+	"cmp"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/AntoineGS/tidydots/internal/config"
+	"github.com/AntoineGS/tidydots/internal/manager"
+	tmpl "github.com/AntoineGS/tidydots/internal/template"
+	"gopkg.in/yaml.v3"
+)
+
+// FileName is the name of the lockfile in the backup root.
+const FileName = "tidydots.lock"
+
+// Version is the lockfile format written by Pin.
+const Version = 1
+
+// header precedes the YAML written by Save.
+const header = "# Generated by `tidydots pin`. Commit this file; `tidydots verify --strict`\n" +
+	"# reports backed-up files whose content no longer matches it.\n"
+
+// Lockfile maps every backed-up file to the SHA-256 of its content. Keys are
+// application/entry/file, with file relative to the entry's backup path and
+// slash-separated on every OS.
+type Lockfile struct {
+	Version int               `yaml:"version"`
+	Files   map[string]string `yaml:"files"`
+}
+
+// DeviationKind describes how a backed-up file differs from the lockfile.
+type DeviationKind int
+
+// Deviation kinds reported by Verify.
+const (
+	// Modified is a pinned file whose content changed
+	Modified DeviationKind = iota
+	// Missing is a pinned file that no longer exists
+	Missing
+	// Added is a backed-up file that is not pinned
+	Added
+	// Unreadable is a file, or the configuration, that could not be read
+	Unreadable
+)
+
+// String returns the human-readable name of a DeviationKind.
+func (k DeviationKind) String() string {
+	switch k {
+	case Modified:
+		return "modified"
+	case Missing:
+		return "missing"
+	case Added:
+		return "not pinned"
+	case Unreadable:
+		return "unreadable"
+	}
+
+	return "unknown"
+}
+
+// Deviation is one backed-up file that does not match the lockfile.
+type Deviation struct {
+	Err      error // set for Unreadable
+	Key      string
+	Expected string // sha256 in the lockfile; empty for Added
+	Actual   string // sha256 on disk; empty for Missing
+	Kind     DeviationKind
+}
+
+func (d Deviation) String() string {
+	switch d.Kind {
+	case Modified:
+		return fmt.Sprintf("%s: modified: expected %s, got %s", d.Key, d.Expected, d.Actual)
+	case Unreadable:
+		return fmt.Sprintf("%s: %v", d.Key, d.Err)
+	default:
+		return fmt.Sprintf("%s: %s", d.Key, d.Kind)
+	}
+}
+
+// Pin hashes every backed-up file of the configuration in backupRoot. Every
+// application and config entry is included whichever machine it applies to,
+// so the lockfile is the same on all of them. Checksum sidecars and template
+// artifacts, which tidydots regenerates, are left out, as are listed files
+// that do not exist.
+func Pin(backupRoot string) (*Lockfile, error) {
+	cfg, err := config.Load(filepath.Join(backupRoot, "tidydots.yaml"))
+	if err != nil {
+		return nil, fmt.Errorf("loading config: %w", err)
+	}
+
+	lock := &Lockfile{Version: Version, Files: make(map[string]string)}
+
+	for _, app := range cfg.Applications {
+		for _, entry := range app.Entries {
+			if !entry.IsConfig() {
+				continue
+			}
+
+			prefix := app.Name + "/" + entry.Name + "/"
+			backupPath := config.ResolveBackupPath(entry.Backup, backupRoot, nil, nil)
+
+			if err := pinEntry(lock.Files, prefix, entry, backupPath); err != nil {
+				return nil, fmt.Errorf("pinning %s/%s: %w", app.Name, entry.Name, err)
+			}
+		}
+	}
+
+	return lock, nil
+}
+
+// pinEntry adds the files of one config entry to files, keyed under prefix.
+func pinEntry(files map[string]string, prefix string, entry config.SubEntry, backupPath string) error {
+	if !entry.IsFolder() {
+		for _, file := range entry.Files {
+			sum, err := hashFile(filepath.Join(backupPath, file))
+			if errors.Is(err, fs.ErrNotExist) {
+				continue
+			}
+
+			if err != nil {
+				return err
+			}
+
+			files[prefix+filepath.ToSlash(file)] = sum
+		}
+
+		return nil
+	}
+
+	if _, err := os.Stat(backupPath); errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+
+	return filepath.WalkDir(backupPath, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if !d.Type().IsRegular() || !isPinned(path) {
+			return nil
+		}
+
+		rel, err := filepath.Rel(backupPath, path)
+		if err != nil {
+			return err
+		}
+
+		sum, err := hashFile(path)
+		if err != nil {
+			return err
+		}
+
+		files[prefix+filepath.ToSlash(rel)] = sum
+
+		return nil
+	})
+}
+
+// isPinned reports whether a file in a folder backup is pinned.
+func isPinned(path string) bool {
+	return !manager.IsChecksumFile(path) && !tmpl.IsRenderedFile(path) && !tmpl.IsConflictFile(path)
+}
+
+// hashFile returns the hex-encoded SHA-256 of the file at path.
+func hashFile(path string) (string, error) {
+	data, err := os.ReadFile(path) //nolint:gosec // path is a backup file from the user's config
+	if err != nil {
+		return "", err
+	}
+
+	sum := sha256.Sum256(data)
+
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// Verify compares the backed-up files in backupRoot with lock and returns
+// the deviations, sorted by key. A configuration that cannot be read is
+// reported as a single Unreadable deviation.
+func Verify(backupRoot string, lock *Lockfile) []Deviation {
+	current, err := Pin(backupRoot)
+	if err != nil {
+		return []Deviation{{Key: FileName, Kind: Unreadable, Err: err}}
+	}
+
+	var deviations []Deviation
+
+	for key, expected := range lock.Files {
+		actual, ok := current.Files[key]
+
+		switch {
+		case !ok:
+			deviations = append(deviations, Deviation{Key: key, Kind: Missing, Expected: expected})
+		case !strings.EqualFold(actual, expected):
+			deviations = append(deviations, Deviation{Key: key, Kind: Modified, Expected: expected, Actual: actual})
+		}
+	}
+
+	for key, actual := range current.Files {
+		if _, ok := lock.Files[key]; !ok {
+			deviations = append(deviations, Deviation{Key: key, Kind: Added, Actual: actual})
+		}
+	}
+
+	slices.SortFunc(deviations, func(a, b Deviation) int {
+		return cmp.Compare(a.Key, b.Key)
+	})
+
+	return deviations
+}
+
+// Load reads the lockfile at path.
+func Load(path string) (*Lockfile, error) {
+	data, err := os.ReadFile(path) //nolint:gosec // path is the lockfile in the user's backup root
+	if err != nil {
+		return nil, err
+	}
+
+	var lock Lockfile
+	if err := yaml.Unmarshal(data, &lock); err != nil {
+		return nil, fmt.Errorf("parsing %s: %w", path, err)
+	}
+
+	if lock.Version > Version {
+		return nil, fmt.Errorf("%s has version %d; this tidydots reads up to version %d", path, lock.Version, Version)
+	}
+
+	if lock.Files == nil {
+		lock.Files = make(map[string]string)
+	}
+
+	return &lock, nil
+}
+
+// Save writes lock to path. Keys are written sorted, so a regenerated
+// lockfile only differs from the committed one where files changed.
+func (l *Lockfile) Save(path string) error {
+	data, err := yaml.Marshal(l)
+	if err != nil {
+		return fmt.Errorf("marshaling lockfile: %w", err)
+	}
+
+	if err := os.WriteFile(path, append([]byte(header), data...), 0600); err != nil {
+		return fmt.Errorf("writing %s: %w", path, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,154 @@
+package lockfile
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+const testConfig = `version: 3
+applications:
+  - name: neovim
+    entries:
+      - name: config
+        backup: ./nvim
+        targets: {linux: ~/.config/nvim}
+  - name: zsh
+    entries:
+      - name: rc
+        backup: ./zsh
+        files: [.zshrc, .zprofile]
+        targets: {windows: '~\zsh'}
+      - name: plugins
+        run: {linux: "true"}
+        check: {linux: "true"}
+`
+
+// writeFile writes content to root/rel, creating its directory.
+func writeFile(t *testing.T, root, rel, content string) {
+	t.Helper()
+
+	path := filepath.Join(root, rel)
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func newRepo(t *testing.T) string {
+	t.Helper()
+
+	root := t.TempDir()
+	writeFile(t, root, "tidydots.yaml", testConfig)
+	writeFile(t, root, "nvim/init.lua", "vim.o.number = true\n")
+	writeFile(t, root, "nvim/lua/plugins.lua", "return {}\n")
+	writeFile(t, root, "nvim/init.lua.sha256", "ignored\n")
+	writeFile(t, root, "zsh/.zshrc", "export EDITOR=nvim\n")
+
+	return root
+}
+
+func keys(lock *Lockfile) []string {
+	var k []string
+	for key := range lock.Files {
+		k = append(k, key)
+	}
+
+	slices.Sort(k)
+
+	return k
+}
+
+func TestPin(t *testing.T) {
+	lock, err := Pin(newRepo(t))
+	if err != nil {
+		t.Fatalf("Pin() error = %v", err)
+	}
+
+	// Entries are pinned whichever OS they target; the missing .zprofile and
+	// the checksum sidecar are not.
+	want := []string{"neovim/config/init.lua", "neovim/config/lua/plugins.lua", "zsh/rc/.zshrc"}
+	if got := keys(lock); !slices.Equal(got, want) {
+		t.Errorf("Pin() keys = %v, want %v", got, want)
+	}
+
+	if got := lock.Files["zsh/rc/.zshrc"]; len(got) != 64 {
+		t.Errorf("sha256 of .zshrc = %q, want 64 hex digits", got)
+	}
+}
+
+func TestVerify(t *testing.T) {
+	root := newRepo(t)
+
+	lock, err := Pin(root)
+	if err != nil {
+		t.Fatalf("Pin() error = %v", err)
+	}
+
+	if got := Verify(root, lock); len(got) != 0 {
+		t.Fatalf("Verify() right after Pin() = %v, want no deviations", got)
+	}
+
+	writeFile(t, root, "nvim/init.lua", "vim.o.number = false\n")
+	writeFile(t, root, "zsh/.zprofile", "path+=~/bin\n")
+
+	if err := os.Remove(filepath.Join(root, "nvim/lua/plugins.lua")); err != nil {
+		t.Fatal(err)
+	}
+
+	got := Verify(root, lock)
+
+	want := []struct {
+		key  string
+		kind DeviationKind
+	}{
+		{"neovim/config/init.lua", Modified},
+		{"neovim/config/lua/plugins.lua", Missing},
+		{"zsh/rc/.zprofile", Added},
+	}
+
+	if len(got) != len(want) {
+		t.Fatalf("Verify() = %v, want %d deviations", got, len(want))
+	}
+
+	for i, w := range want {
+		if got[i].Key != w.key || got[i].Kind != w.kind {
+			t.Errorf("deviation %d = %s (%s), want %s (%s)", i, got[i].Key, got[i].Kind, w.key, w.kind)
+		}
+	}
+}
+
+func TestVerify_UnreadableConfig(t *testing.T) {
+	got := Verify(t.TempDir(), &Lockfile{Version: Version})
+	if len(got) != 1 || got[0].Kind != Unreadable {
+		t.Errorf("Verify() without a config = %v, want one Unreadable deviation", got)
+	}
+}
+
+func TestSaveLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), FileName)
+	lock := &Lockfile{Version: Version, Files: map[string]string{"a/b/c": "00ff"}}
+
+	if err := lock.Save(path); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	loaded, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+
+	if loaded.Version != Version || loaded.Files["a/b/c"] != "00ff" || len(loaded.Files) != 1 {
+		t.Errorf("Load() = %+v, want %+v", loaded, lock)
+	}
+
+	writeFile(t, filepath.Dir(path), FileName, "version: 99\nfiles: {}\n")
+
+	if _, err := Load(path); err == nil {
+		t.Error("Load() of a newer lockfile version expected error, got nil")
+	}
+}
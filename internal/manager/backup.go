@@ -2,34 +2,75 @@ package manager
 
 import (
 	"context"
-	"errors"
 	"fmt"
 	"log/slog"
 	"path/filepath"
 
 	"github.com/AntoineGS/tidydots/internal/config"
+	"github.com/AntoineGS/tidydots/internal/state"
 	tmpl "github.com/AntoineGS/tidydots/internal/template"
 )
 
 // BackupWithContext backs up configurations with context support
 func (m *Manager) BackupWithContext(ctx context.Context) error {
-	m = m.WithContext(ctx)
-	return m.Backup()
+	_, err := m.BackupReport(ctx)
+	return err
 }
 
-// Backup copies configuration files from their target locations to the backup directory.
-//
-//nolint:dupl // similar structure to Restore, but semantically different operations
+// Backup copies configuration files from their target locations to the backup
+// directory, then sends the configured notifications (see notify).
 func (m *Manager) Backup() error {
+	_, err := m.backupReport()
+	return err
+}
+
+// BackupReport backs up like BackupWithContext and also returns the outcome
+// of every entry. As with RestoreReport, every selected entry is attempted
+// and the error joins an *EntryError per failure.
+func (m *Manager) BackupReport(ctx context.Context) (*Report, error) {
+	return m.WithContext(ctx).backupReport()
+}
+
+func (m *Manager) backupReport() (*Report, error) {
+	report := &Report{Operation: state.OpBackup}
+	run := m.BeginJournal(state.OpBackup)
+	err := joinReportErr(run.backup(report), report)
+	run.EndJournal(err == nil)
+
+	summary := m.newRunSummary(state.OpBackup)
+	report.summarize(&summary)
+	m.notify(summary, err)
+
+	return report, err
+}
+
+// backup implements Backup, recording the outcome of every entry in report.
+// It only returns an error when the run was canceled or the backup history
+// could not be read; entry failures are in the report.
+//
+//nolint:dupl // similar structure to restore, but semantically different operations
+func (m *Manager) backup(report *Report) error {
 	// Check context before starting
 	if err := m.checkContext(); err != nil {
 		return err
 	}
 
 	m.logger.Info("backing up configurations", slog.String("os", m.Platform.OS)) //nolint:dupl // similar structure to restoreV3, but semantically different
-	apps := m.GetApplications()
+	apps := m.applicationsByPriority()
+
+	var history map[HistoryKey]EntryHistory
+	if m.Stale > 0 {
+		if m.stateStore == nil {
+			m.logger.Warn("no state store: backing up every entry regardless of --stale")
+		} else {
+			var err error
+			if history, err = m.History(); err != nil {
+				return fmt.Errorf("reading backup history: %w", err)
+			}
+		}
+	}
 
-	var errs []error
+	now := m.now()
 
 	for _, app := range apps {
 		// Check context before each application
@@ -63,25 +104,81 @@ func (m *Manager) Backup() error {
 				continue
 			}
 
+			result := EntryResult{App: app.Name, Entry: subEntry.Name}
+
+			if m.SkipsSudo(subEntry) {
+				m.logger.Warn("skipped: requires sudo",
+					slog.String("app", app.Name),
+					slog.String("entry", subEntry.Name))
+
+				result.Action, result.Detail = ActionSkipped, skipReasonSudo
+				report.add(result)
+
+				continue
+			}
+
+			if history != nil && backedUpWithin(history[HistoryKey{App: app.Name, Entry: subEntry.Name}], now, m.Stale) {
+				m.logger.Debug("skipping entry",
+					slog.String("app", app.Name),
+					slog.String("entry", subEntry.Name),
+					slog.String("reason", "backed up within stale window"))
+
+				result.Action, result.Detail = ActionSkipped, skipReasonStale
+				report.add(result)
+
+				continue
+			}
+
+			if m.resumed(app.Name, subEntry.Name, m.resolvePath(subEntry.Backup)) {
+				result.Action, result.Detail = ActionSkipped, skipReasonResumed
+				report.add(result)
+
+				continue
+			}
+
 			// Expand ~ and env vars in target path for file operations
 			expandedTarget := m.expandTarget(target)
 
-			if err := m.backupSubEntry(app.Name, subEntry, expandedTarget); err != nil {
+			em := m.withSteps()
+			err := em.backupSubEntry(app.Name, subEntry, expandedTarget)
+			result.Steps = em.recordedSteps()
+			result.Skipped = em.skippedFiles()
+
+			if err != nil {
 				m.logger.Error("backup failed",
 					slog.String("app", app.Name),
 					slog.String("entry", subEntry.Name),
 					slog.String("error", err.Error()))
-				errs = append(errs, err)
+
+				result.Action, result.Err = ActionFailed, err
+				report.add(result)
+
+				continue
 			}
+
+			m.RecordOperation(state.OpBackup, app.Name, subEntry.Name)
+			m.journalEntry(app.Name, subEntry.Name)
+
+			result.Action = ActionBackedUp
+			result.Detail = fmt.Sprintf("%s -> %s", expandedTarget, m.resolvePath(subEntry.Backup))
+			report.add(result)
 		}
 	}
 
-	return errors.Join(errs...)
+	return nil
 }
 
 func (m *Manager) backupSubEntry(appName string, subEntry config.SubEntry, target string) error {
+	if err := m.checkPathTemplates(subEntry); err != nil {
+		return err
+	}
+
 	backupPath := m.resolvePath(subEntry.Backup)
 
+	if subEntry.LinksFiles() {
+		return m.backupLinkFarm(appName, subEntry, backupPath, target)
+	}
+
 	if subEntry.IsFolder() {
 		return m.backupFolderSubEntry(appName, subEntry, backupPath, target)
 	}
@@ -102,10 +199,18 @@ func (m *Manager) backupFolderSubEntry(_ string, subEntry config.SubEntry, backu
 		return nil
 	}
 
+	if err := m.checkCaseCollision(subEntry, target); err != nil {
+		return NewPathError("backup", target, err)
+	}
+
 	m.logger.Info("backing up folder",
 		slog.String("from", target),
 		slog.String("to", backup))
 
+	m.step(StepBackup, target, backup, m.backupConflict(backup))
+
+	oversized := m.oversizedFiles(target, m.Config.MaxFileSize(subEntry))
+
 	if !m.DryRun {
 		if err := m.fs.MkdirAll(filepath.Dir(backup), DirPerms); err != nil {
 			return NewPathError("backup", backup, fmt.Errorf("creating parent directory: %w", err))
@@ -116,10 +221,13 @@ func (m *Manager) backupFolderSubEntry(_ string, subEntry config.SubEntry, backu
 			if _, err := m.runner.RunWithSudo(m.ctx, "cp", "-rT", target, backup); err != nil {
 				return err
 			}
-			return nil
+		} else if err := m.copyTree(target, backup, m.folderCopier(subEntry, oversized)); err != nil {
+			return err
 		}
 
-		return m.copyDir(target, backup)
+		if subEntry.Verify {
+			return m.writeFolderChecksums(backup)
+		}
 	}
 
 	return nil
@@ -132,24 +240,32 @@ func (m *Manager) backupFilesSubEntry(_ string, subEntry config.SubEntry, backup
 		return nil
 	}
 
+	// Patterns are matched in the target, so new files are backed up too.
+	files, err := m.expandFiles(subEntry.Files, target)
+	if err != nil {
+		return NewPathError("backup", target, err)
+	}
+
+	if first, second := caseCollision(subEntry, files); first != "" {
+		return NewPathError("backup", target, caseCollisionError(first, second, false))
+	}
+
 	if !m.DryRun {
 		if err := m.fs.MkdirAll(backup, DirPerms); err != nil {
 			return NewPathError("backup", backup, fmt.Errorf("creating backup directory: %w", err))
 		}
 	}
 
-	for _, file := range subEntry.Files {
+	for _, file := range files {
 		srcFile := filepath.Join(target, file)
-		dstFile := filepath.Join(backup, file)
+		dstFile := filepath.Join(backup, subEntry.BackupName(file))
 
 		if !m.pathExists(srcFile) {
 			m.logger.Debug("source file does not exist", slog.String("path", srcFile))
 			continue
 		}
 
-		// Skip symlinks
-		if m.isSymlink(srcFile) {
-			m.logger.Debug("skipping symlink", slog.String("path", srcFile))
+		if m.skipSymlink(srcFile, dstFile) {
 			continue
 		}
 
@@ -159,10 +275,27 @@ func (m *Manager) backupFilesSubEntry(_ string, subEntry config.SubEntry, backup
 			continue
 		}
 
+		if m.oversized(srcFile, m.Config.MaxFileSize(subEntry)) {
+			continue
+		}
+
+		if subEntry.Dedupe {
+			done, err := m.backupBlob(subEntry, srcFile, dstFile)
+			if err != nil {
+				return err
+			}
+
+			if done {
+				continue
+			}
+		}
+
 		m.logger.Info("backing up file",
 			slog.String("from", srcFile),
 			slog.String("to", dstFile))
 
+		m.step(StepBackup, srcFile, dstFile, m.backupConflict(dstFile))
+
 		if !m.DryRun {
 			if subEntry.Sudo {
 				if _, err := m.runner.RunWithSudo(m.ctx, "cp", srcFile, dstFile); err != nil {
@@ -173,8 +306,78 @@ func (m *Manager) backupFilesSubEntry(_ string, subEntry config.SubEntry, backup
 					return NewPathError("backup", srcFile, fmt.Errorf("copying file: %w", err))
 				}
 			}
+
+			if subEntry.Verify {
+				if err := m.writeChecksum(dstFile); err != nil {
+					return err
+				}
+			}
 		}
 	}
 
 	return nil
 }
+
+// folderCopier returns how backing up a folder entry copies each file:
+// deduped entries skip unchanged large files, and the files in oversized and
+// the symlinks skipSymlink leaves out are not copied at all.
+func (m *Manager) folderCopier(subEntry config.SubEntry, oversized map[string]bool) func(src, dst string) error {
+	copyFile := m.copyFile
+	if subEntry.Dedupe {
+		copyFile = m.copyChanged
+	}
+
+	return func(src, dst string) error {
+		if oversized[src] || m.skipSymlink(src, dst) {
+			return nil
+		}
+
+		return copyFile(src, dst)
+	}
+}
+
+// skipSymlink reports whether backing up src to dst leaves src out because
+// it is a symlink: always when it links to dst itself, which restore made,
+// and for any symlink when SkipSymlinks is set.
+func (m *Manager) skipSymlink(src, dst string) bool {
+	if !m.isSymlink(src) {
+		return false
+	}
+
+	if !m.SkipSymlinks && !m.linksTo(src, dst) {
+		return false
+	}
+
+	m.logger.Debug("skipping symlink", slog.String("path", src))
+
+	return true
+}
+
+// linksTo reports whether the symlink at path points to target.
+func (m *Manager) linksTo(path, target string) bool {
+	link, err := m.fs.Readlink(path)
+	if err != nil {
+		return false
+	}
+
+	if !filepath.IsAbs(link) {
+		link = filepath.Join(filepath.Dir(path), link)
+	}
+
+	return filepath.Clean(link) == filepath.Clean(target)
+}
+
+// backupConflict returns how backing up onto backup treats what is there:
+// a backup folder is merged into, an existing file overwritten.
+func (m *Manager) backupConflict(backup string) string {
+	info, err := m.fs.Stat(backup)
+
+	switch {
+	case err != nil:
+		return ConflictNone
+	case info.IsDir():
+		return ConflictMerge
+	default:
+		return ConflictReplace
+	}
+}
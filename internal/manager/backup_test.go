@@ -212,6 +212,139 @@ func TestBackupFilesSkipsSymlinks(t *testing.T) {
 	}
 }
 
+func TestBackupFilesCopiesSymlinksWithoutSkipSymlinks(t *testing.T) {
+	t.Parallel()
+	skipIfNoSymlink(t)
+	tmpDir := t.TempDir()
+
+	srcDir := filepath.Join(tmpDir, "source")
+	backupDir := filepath.Join(tmpDir, "backup")
+	realFile := filepath.Join(tmpDir, "elsewhere.txt")
+
+	if err := os.MkdirAll(srcDir, 0750); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(realFile, []byte("real content"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink(realFile, filepath.Join(srcDir, "symlink.txt")); err != nil {
+		t.Fatal(err)
+	}
+
+	mgr := New(&config.Config{BackupRoot: tmpDir}, &platform.Platform{OS: platform.OSLinux})
+	mgr.SkipSymlinks = false
+
+	subEntry := config.SubEntry{Name: "test", Files: []string{"symlink.txt"}, Backup: "./backup"}
+
+	if err := mgr.backupFilesSubEntry("test", subEntry, backupDir, srcDir); err != nil {
+		t.Fatalf("backupFilesSubEntry() error = %v", err)
+	}
+
+	backupFile := filepath.Join(backupDir, "symlink.txt")
+	if mgr.isSymlink(backupFile) {
+		t.Error("backup of a symlink is a symlink, want its content")
+	}
+
+	//nolint:gosec // test file
+	if content, err := os.ReadFile(backupFile); err != nil || string(content) != "real content" {
+		t.Errorf("backup of symlink = (%q, %v), want the content it points to", content, err)
+	}
+}
+
+func TestBackupFilesSkipsLinkToOwnBackupWithoutSkipSymlinks(t *testing.T) {
+	t.Parallel()
+	skipIfNoSymlink(t)
+	tmpDir := t.TempDir()
+
+	srcDir := filepath.Join(tmpDir, "source")
+	backupDir := filepath.Join(tmpDir, "backup")
+	backupFile := filepath.Join(backupDir, "config.txt")
+
+	for _, dir := range []string{srcDir, backupDir} {
+		if err := os.MkdirAll(dir, 0750); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.WriteFile(backupFile, []byte("backed up"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	// What restore leaves in the target.
+	if err := os.Symlink(backupFile, filepath.Join(srcDir, "config.txt")); err != nil {
+		t.Fatal(err)
+	}
+
+	mgr := New(&config.Config{BackupRoot: tmpDir}, &platform.Platform{OS: platform.OSLinux})
+	mgr.SkipSymlinks = false
+
+	subEntry := config.SubEntry{Name: "test", Files: []string{"config.txt"}, Backup: "./backup", Sudo: true}
+
+	// A sudo entry would run "cp", which fails copying a file onto itself.
+	if err := mgr.backupFilesSubEntry("test", subEntry, backupDir, srcDir); err != nil {
+		t.Fatalf("backupFilesSubEntry() error = %v", err)
+	}
+}
+
+func TestBackupFolderSymlinkedFiles(t *testing.T) {
+	t.Parallel()
+	skipIfNoSymlink(t)
+
+	tests := []struct {
+		name         string
+		skipSymlinks bool
+		wantCopied   bool
+	}{
+		{"skipped by default", true, false},
+		{"copied without SkipSymlinks", false, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			tmpDir := t.TempDir()
+
+			srcDir := filepath.Join(tmpDir, "source")
+			backupDir := filepath.Join(tmpDir, "backup")
+			realFile := filepath.Join(tmpDir, "elsewhere.txt")
+
+			if err := os.MkdirAll(srcDir, 0750); err != nil {
+				t.Fatal(err)
+			}
+			if err := os.WriteFile(filepath.Join(srcDir, "real.txt"), []byte("real"), 0600); err != nil {
+				t.Fatal(err)
+			}
+			if err := os.WriteFile(realFile, []byte("linked"), 0600); err != nil {
+				t.Fatal(err)
+			}
+			if err := os.Symlink(realFile, filepath.Join(srcDir, "link.txt")); err != nil {
+				t.Fatal(err)
+			}
+
+			mgr := New(&config.Config{BackupRoot: tmpDir}, &platform.Platform{OS: platform.OSLinux})
+			mgr.SkipSymlinks = tt.skipSymlinks
+
+			subEntry := config.SubEntry{Name: "test", Backup: "./backup"}
+
+			if err := mgr.backupFolderSubEntry("test", subEntry, backupDir, srcDir); err != nil {
+				t.Fatalf("backupFolderSubEntry() error = %v", err)
+			}
+
+			if !testPathExists(filepath.Join(backupDir, "real.txt")) {
+				t.Error("regular file was not backed up")
+			}
+
+			//nolint:gosec // test file
+			content, err := os.ReadFile(filepath.Join(backupDir, "link.txt"))
+			if copied := err == nil; copied != tt.wantCopied {
+				t.Fatalf("symlinked file copied = %v, want %v", copied, tt.wantCopied)
+			}
+
+			if tt.wantCopied && string(content) != "linked" {
+				t.Errorf("copied symlinked file = %q, want the content it points to", content)
+			}
+		})
+	}
+}
+
 func TestBackupDryRun(t *testing.T) {
 	t.Parallel()
 	tmpDir := t.TempDir()
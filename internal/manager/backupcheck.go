@@ -0,0 +1,101 @@
+package manager
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"path/filepath"
+
+	"github.com/AntoineGS/tidydots/internal/config"
+)
+
+// BackupProblem is a config entry whose backup restore would have nothing to
+// deploy from; see VerifyBackups.
+type BackupProblem struct {
+	Err   error
+	App   string
+	Entry string
+	Path  string // the backup directory, or the missing file in it
+}
+
+// String describes the problem for a report.
+func (p BackupProblem) String() string {
+	return fmt.Sprintf("%s/%s: %s: %v", p.App, p.Entry, p.Path, p.Err)
+}
+
+// VerifyBackups checks the backup of every config entry of the current
+// platform and returns how many entries it checked and their problems. A
+// folder entry's backup must be a readable directory that is not empty; a
+// files entry's backup must be a readable directory holding every file it
+// lists. Glob patterns are left to UnmatchedPatterns, which reports those
+// that match nothing. Missing backups fail with ErrBackupNotFound and empty
+// ones with ErrBackupEmpty.
+func (m *Manager) VerifyBackups() (int, []BackupProblem) {
+	checked := 0
+
+	var problems []BackupProblem
+
+	for _, app := range m.applicationsByPriority() {
+		for _, subEntry := range app.Entries {
+			if !subEntry.IsConfig() || subEntry.GetTarget(m.Platform.OS) == "" {
+				continue
+			}
+
+			checked++
+
+			for _, p := range m.checkBackup(subEntry) {
+				p.App, p.Entry = app.Name, subEntry.Name
+				problems = append(problems, p)
+			}
+		}
+	}
+
+	return checked, problems
+}
+
+// checkBackup returns the problems of one entry's backup, without App and
+// Entry set.
+func (m *Manager) checkBackup(subEntry config.SubEntry) []BackupProblem {
+	backupPath := m.resolvePath(subEntry.Backup)
+
+	info, err := m.fs.Stat(backupPath)
+	switch {
+	case errors.Is(err, fs.ErrNotExist):
+		return []BackupProblem{{Path: backupPath, Err: ErrBackupNotFound}}
+	case err != nil:
+		return []BackupProblem{{Path: backupPath, Err: err}}
+	case !info.IsDir():
+		return []BackupProblem{{Path: backupPath, Err: errors.New("not a directory")}}
+	}
+
+	entries, err := m.fs.ReadDir(backupPath)
+	if err != nil {
+		return []BackupProblem{{Path: backupPath, Err: fmt.Errorf("not readable: %w", err)}}
+	}
+
+	if subEntry.IsFolder() {
+		if len(entries) == 0 {
+			return []BackupProblem{{Path: backupPath, Err: ErrBackupEmpty}}
+		}
+
+		return nil
+	}
+
+	var problems []BackupProblem
+
+	for _, file := range subEntry.Files {
+		if IsGlobPattern(file) {
+			continue
+		}
+
+		path := filepath.Join(backupPath, subEntry.BackupName(file))
+
+		if _, err := m.fs.ReadFile(path); errors.Is(err, fs.ErrNotExist) {
+			problems = append(problems, BackupProblem{Path: path, Err: ErrBackupNotFound})
+		} else if err != nil {
+			problems = append(problems, BackupProblem{Path: path, Err: fmt.Errorf("not readable: %w", err)})
+		}
+	}
+
+	return problems
+}
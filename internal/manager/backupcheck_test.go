@@ -0,0 +1,73 @@
+package manager
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/AntoineGS/tidydots/internal/config"
+)
+
+func TestVerifyBackups(t *testing.T) {
+	root := t.TempDir()
+	writeGlobFiles(t, root, "nvim/init.lua", "zsh/.zshrc", "git/config", "tmux.conf")
+
+	if err := os.Mkdir(filepath.Join(root, "empty"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	linux := map[string]string{"linux": "~/.config/x"}
+
+	mgr := newGlobManager(root)
+	mgr.Config.Applications = []config.Application{
+		{
+			Name: "nvim",
+			Entries: []config.SubEntry{
+				{Name: "config", Backup: "./nvim", Targets: linux},
+				{Name: "forgotten", Backup: "./nvim-extra", Targets: linux},
+				{Name: "empty", Backup: "./empty", Targets: linux},
+				{Name: "file", Backup: "./tmux.conf", Targets: linux},
+			},
+		},
+		{
+			Name: "zsh",
+			Entries: []config.SubEntry{
+				{Name: "rc", Backup: "./zsh", Files: []string{".zshrc", ".zprofile", "*.zsh"}, Targets: linux},
+				{Name: "missing", Backup: "./zsh-missing", Files: []string{".zshenv"}, Targets: linux},
+				{Name: "windows-only", Backup: "./zsh-windows", Targets: map[string]string{"windows": "~/zsh"}},
+			},
+		},
+	}
+
+	checked, problems := mgr.VerifyBackups()
+	if checked != 6 {
+		t.Errorf("VerifyBackups() checked %d entries, want 6", checked)
+	}
+
+	want := []struct {
+		app, entry, path string
+		err              error
+	}{
+		{"nvim", "forgotten", filepath.Join(root, "nvim-extra"), ErrBackupNotFound},
+		{"nvim", "empty", filepath.Join(root, "empty"), ErrBackupEmpty},
+		{"nvim", "file", filepath.Join(root, "tmux.conf"), nil},
+		{"zsh", "rc", filepath.Join(root, "zsh", ".zprofile"), ErrBackupNotFound},
+		{"zsh", "missing", filepath.Join(root, "zsh-missing"), ErrBackupNotFound},
+	}
+
+	if len(problems) != len(want) {
+		t.Fatalf("VerifyBackups() = %v, want %d problems", problems, len(want))
+	}
+
+	for i, w := range want {
+		p := problems[i]
+		if p.App != w.app || p.Entry != w.entry || p.Path != w.path {
+			t.Errorf("problem %d = %s, want %s/%s at %s", i, p, w.app, w.entry, w.path)
+		}
+
+		if w.err != nil && !errors.Is(p.Err, w.err) {
+			t.Errorf("problem %d error = %v, want %v", i, p.Err, w.err)
+		}
+	}
+}
@@ -0,0 +1,120 @@
+package manager
+
+import (
+	"fmt"
+	"io/fs"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/AntoineGS/tidydots/internal/config"
+)
+
+// caseCollision returns the first two of files, names relative to the target
+// of subEntry, whose backup names differ only by case, or "", "" when there
+// are none. Such files clobber each other in a backup checked out on a
+// case-insensitive filesystem. Names are compared, not looked up, so the
+// result does not depend on the filesystem it runs on.
+func caseCollision(subEntry config.SubEntry, files []string) (string, string) {
+	seen := make(map[string]string, len(files))
+
+	for _, file := range files {
+		name := filepath.ToSlash(subEntry.BackupName(file))
+		folded := strings.ToLower(name)
+
+		if other, ok := seen[folded]; ok && filepath.ToSlash(subEntry.BackupName(other)) != name {
+			return other, file
+		}
+
+		seen[folded] = file
+	}
+
+	return "", ""
+}
+
+// folderCaseCollision returns the first two paths under dir, relative to it,
+// whose names differ only by case within the same directory, or "", "".
+func (m *Manager) folderCaseCollision(dir string) (string, string) {
+	seen := make(map[string]string)
+
+	var first, second string
+
+	_ = m.fs.WalkDir(dir, func(path string, _ fs.DirEntry, err error) error { //nolint:errcheck // unreadable parts cannot collide
+		if err != nil || path == dir {
+			return nil
+		}
+
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return nil
+		}
+
+		folded := strings.ToLower(filepath.ToSlash(rel))
+		if other, ok := seen[folded]; ok {
+			first, second = other, rel
+			return fs.SkipAll
+		}
+
+		seen[folded] = rel
+
+		return nil
+	})
+
+	return first, second
+}
+
+// checkCaseCollision returns an error naming the two files of subEntry,
+// deployed at target, whose backup names differ only by case. Files are
+// expanded in the target, as backup does.
+func (m *Manager) checkCaseCollision(subEntry config.SubEntry, target string) error {
+	var first, second string
+
+	if subEntry.IsFolder() {
+		first, second = m.folderCaseCollision(target)
+	} else {
+		files, err := m.expandFiles(subEntry.Files, target)
+		if err != nil {
+			return err
+		}
+
+		first, second = caseCollision(subEntry, files)
+	}
+
+	return caseCollisionError(first, second, subEntry.IsFolder())
+}
+
+// caseCollisionError returns the error for files first and second colliding
+// by case, or nil when first is "". A folder entry cannot rename its files.
+func caseCollisionError(first, second string, folder bool) error {
+	switch {
+	case first == "":
+		return nil
+	case folder:
+		return fmt.Errorf("%w: %s and %s; list the files of the entry to rename one of them with case_rename",
+			ErrCaseCollision, first, second)
+	default:
+		return fmt.Errorf("%w: %s and %s; map one of them to another backup name with case_rename",
+			ErrCaseCollision, first, second)
+	}
+}
+
+// entryFiles returns the files of the files entry subEntry, as names relative
+// to its target, with patterns expanded in its backup directory backupPath.
+// A match renamed by case_rename is listed under its target name.
+func (m *Manager) entryFiles(subEntry config.SubEntry, backupPath string) ([]string, error) {
+	files, err := m.expandFiles(subEntry.Files, backupPath)
+	if err != nil || len(subEntry.CaseRename) == 0 {
+		return files, err
+	}
+
+	names := make([]string, 0, len(files))
+
+	for _, file := range files {
+		name := subEntry.TargetName(file)
+		if !slices.Contains(names, name) {
+			names = append(names, name)
+		}
+	}
+
+	return names, nil
+}
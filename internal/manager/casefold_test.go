@@ -0,0 +1,166 @@
+package manager
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/AntoineGS/tidydots/internal/config"
+)
+
+func TestCaseCollision(t *testing.T) {
+	tests := []struct {
+		name       string
+		files      []string
+		rename     map[string]string
+		wantFirst  string
+		wantSecond string
+	}{
+		{
+			name:  "distinct names",
+			files: []string{"config.toml", "themes.toml"},
+		},
+		{
+			name:       "names differing only by case",
+			files:      []string{"Config.toml", "themes.toml", "config.toml"},
+			wantFirst:  "Config.toml",
+			wantSecond: "config.toml",
+		},
+		{
+			name:       "directories differing only by case",
+			files:      []string{filepath.Join("Themes", "dark.toml"), filepath.Join("themes", "dark.toml")},
+			wantFirst:  filepath.Join("Themes", "dark.toml"),
+			wantSecond: filepath.Join("themes", "dark.toml"),
+		},
+		{
+			name:   "case_rename separates them",
+			files:  []string{"Config.toml", "config.toml"},
+			rename: map[string]string{"config.toml": "config.lower.toml"},
+		},
+		{
+			name:       "case_rename onto another name's case",
+			files:      []string{"Config.toml", "settings.toml"},
+			rename:     map[string]string{"settings.toml": "CONFIG.toml"},
+			wantFirst:  "Config.toml",
+			wantSecond: "settings.toml",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			subEntry := config.SubEntry{Name: "config", Files: tt.files, CaseRename: tt.rename}
+
+			first, second := caseCollision(subEntry, tt.files)
+			if first != tt.wantFirst || second != tt.wantSecond {
+				t.Errorf("caseCollision() = %q, %q, want %q, %q", first, second, tt.wantFirst, tt.wantSecond)
+			}
+		})
+	}
+}
+
+func TestBackupFiles_CaseCollision(t *testing.T) {
+	backup := t.TempDir()
+	target := t.TempDir()
+
+	// Only one of the names needs to exist: the names are compared, so the
+	// test does not depend on the filesystem being case-sensitive.
+	writeGlobFiles(t, target, "Config.toml")
+
+	mgr := newGlobManager(backup)
+	subEntry := config.SubEntry{Name: "config", Backup: backup, Files: []string{"Config.toml", "config.toml"}}
+
+	err := mgr.backupSubEntry("app", subEntry, target)
+	if !errors.Is(err, ErrCaseCollision) {
+		t.Fatalf("backupSubEntry() error = %v, want ErrCaseCollision", err)
+	}
+
+	if _, err := os.Stat(filepath.Join(backup, "Config.toml")); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("Config.toml was backed up although the entry collides")
+	}
+
+	subEntry.CaseRename = map[string]string{"config.toml": "config.lower.toml"}
+	if err := mgr.backupSubEntry("app", subEntry, target); err != nil {
+		t.Fatalf("backupSubEntry() with case_rename error = %v", err)
+	}
+
+	if _, err := os.Stat(filepath.Join(backup, "Config.toml")); err != nil {
+		t.Errorf("Config.toml was not backed up: %v", err)
+	}
+}
+
+func TestBackupAndRestoreFiles_CaseRename(t *testing.T) {
+	skipIfNoSymlink(t)
+
+	backup := t.TempDir()
+	target := t.TempDir()
+	writeGlobFiles(t, target, "settings.toml")
+
+	mgr := newGlobManager(backup)
+	subEntry := config.SubEntry{
+		Name:       "config",
+		Backup:     backup,
+		Files:      []string{"settings.toml"},
+		CaseRename: map[string]string{"settings.toml": "settings.lower.toml"},
+	}
+
+	if err := mgr.backupSubEntry("app", subEntry, target); err != nil {
+		t.Fatalf("backupSubEntry() error = %v", err)
+	}
+
+	renamed := filepath.Join(backup, "settings.lower.toml")
+	if _, err := os.Stat(renamed); err != nil {
+		t.Fatalf("backup did not write the case_rename name: %v", err)
+	}
+
+	restored := t.TempDir()
+	if err := mgr.RestoreFiles(subEntry, backup, restored); err != nil {
+		t.Fatalf("RestoreFiles() error = %v", err)
+	}
+
+	if !mgr.symlinkPointsTo(filepath.Join(restored, "settings.toml"), renamed) {
+		t.Errorf("settings.toml is not linked to %s", renamed)
+	}
+
+	// A pattern matching the renamed backup file deploys it under its target name.
+	subEntry.Files = []string{"*.toml"}
+	restored = t.TempDir()
+
+	if err := mgr.RestoreFiles(subEntry, backup, restored); err != nil {
+		t.Fatalf("RestoreFiles() with a pattern error = %v", err)
+	}
+
+	if !mgr.symlinkPointsTo(filepath.Join(restored, "settings.toml"), renamed) {
+		t.Errorf("pattern match settings.toml is not linked to %s", renamed)
+	}
+}
+
+func TestFolderCaseCollision(t *testing.T) {
+	// MemFS keeps names as given, whatever the case-sensitivity of the
+	// filesystem the tests run on.
+	mgr, mem := newMemManager(t)
+
+	for _, file := range []string{"/target/init.lua", "/target/lua/plugins.lua", "/target/Lua/keys.lua"} {
+		if err := mem.MkdirAll(filepath.Dir(file), 0o755); err != nil {
+			t.Fatal(err)
+		}
+
+		if err := mem.WriteFile(file, nil, 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	first, second := mgr.folderCaseCollision("/target")
+	if first != "Lua" || second != "lua" {
+		t.Errorf("folderCaseCollision() = %q, %q, want Lua, lua", first, second)
+	}
+
+	subEntry := config.SubEntry{Name: "config", Backup: "/backup/nvim"}
+	if err := mgr.backupSubEntry("nvim", subEntry, "/target"); !errors.Is(err, ErrCaseCollision) {
+		t.Errorf("backupSubEntry() of a colliding folder error = %v, want ErrCaseCollision", err)
+	}
+
+	if first, second := mgr.folderCaseCollision("/target/lua"); first != "" {
+		t.Errorf("folderCaseCollision(lua) = %q, %q, want none", first, second)
+	}
+}
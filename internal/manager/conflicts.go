@@ -0,0 +1,144 @@
+package manager
+
+import (
+	"path/filepath"
+
+	"github.com/AntoineGS/tidydots/internal/config"
+	tmpl "github.com/AntoineGS/tidydots/internal/template"
+)
+
+// TargetConflict is a file or folder at a target that restoring its entry
+// would have to merge into the backup or replace: it is not the entry's link,
+// and the backup has a different counterpart.
+type TargetConflict struct {
+	Target string
+	// Backup is where the backup's content is read from: the backup file or
+	// folder, or the object of a deduplicated copy.
+	Backup string
+	IsDir  bool
+}
+
+// Resolution is how RestoreEntryResolved treats the conflicts of an entry.
+type Resolution int
+
+// Resolutions of an entry's conflicts.
+const (
+	// ResolveDefault restores as configured: conflicting targets are merged
+	// into the backup, or replaced with NoMerge and ForceDelete.
+	ResolveDefault Resolution = iota
+	// ResolveKeepTarget backs the targets up over the backup, then deploys
+	// them, so the target's content is kept.
+	ResolveKeepTarget
+	// ResolveUseBackup replaces the targets with the backup.
+	ResolveUseBackup
+	// ResolveSkip leaves the entry alone.
+	ResolveSkip
+)
+
+// String returns the label of a Resolution.
+func (r Resolution) String() string {
+	switch r {
+	case ResolveKeepTarget:
+		return "keep mine"
+	case ResolveUseBackup:
+		return "use backup"
+	case ResolveSkip:
+		return "skip"
+	default:
+		return "merge"
+	}
+}
+
+// TargetConflicts lists the conflicts restoring subEntry to target, an
+// expanded target path, would run into. Missing targets and symlinks are not
+// conflicts, and neither are targets without a backup, which a restore adopts.
+// Folders with templates are skipped: their targets are real directories by
+// design. So is the target of a link_mode: files entry, whose files are
+// checked one by one instead.
+func (m *Manager) TargetConflicts(subEntry config.SubEntry, target string) []TargetConflict {
+	if !subEntry.IsConfig() {
+		return nil
+	}
+
+	backupPath := m.resolvePath(subEntry.Backup)
+
+	if subEntry.LinksFiles() {
+		files, _ := LinkFarmFiles(m.fs, backupPath) //nolint:errcheck // a missing backup is adopted, not a conflict
+
+		var conflicts []TargetConflict
+
+		for _, file := range files {
+			srcFile := filepath.Join(backupPath, file)
+			dstFile := filepath.Join(target, file)
+
+			if m.checkLink(dstFile, srcFile).State == LinkReplaced {
+				conflicts = append(conflicts, TargetConflict{Target: dstFile, Backup: srcFile})
+			}
+		}
+
+		return conflicts
+	}
+
+	if subEntry.IsFolder() {
+		if !m.pathExists(backupPath) || m.hasTemplateFiles(backupPath) ||
+			m.checkLink(target, backupPath).State != LinkReplaced {
+			return nil
+		}
+
+		return []TargetConflict{{Target: target, Backup: backupPath, IsDir: true}}
+	}
+
+	files, _ := m.entryFiles(subEntry, backupPath) //nolint:errcheck // a malformed pattern matches nothing; restore reports it
+
+	var conflicts []TargetConflict
+
+	for _, file := range files {
+		srcFile := filepath.Join(backupPath, subEntry.BackupName(file))
+		dstFile := filepath.Join(target, file)
+
+		if tmpl.IsTemplateFile(file) || !m.pathExists(srcFile) || m.checkLink(dstFile, srcFile).State != LinkReplaced {
+			continue
+		}
+
+		if subEntry.IsCopy() {
+			src, err := m.objectSource(srcFile)
+			if err != nil {
+				continue
+			}
+
+			if equal, err := m.filesEqual(src, dstFile, subEntry.Sudo); err == nil && equal {
+				continue
+			}
+
+			srcFile = src
+		}
+
+		conflicts = append(conflicts, TargetConflict{Target: dstFile, Backup: srcFile})
+	}
+
+	return conflicts
+}
+
+// RestoreEntryResolved restores one config entry like RestoreEntry, handling
+// its conflicting targets as res says.
+func (m *Manager) RestoreEntryResolved(appName string, subEntry config.SubEntry, target string, res Resolution) EntryResult {
+	switch res {
+	case ResolveSkip:
+		return EntryResult{App: appName, Entry: subEntry.Name, Action: ActionSkipped, Detail: skipReasonKept}
+	case ResolveKeepTarget:
+		if !m.SkipsSudo(subEntry) {
+			if err := m.forApp(appName).backupSubEntry(appName, subEntry, target); err != nil {
+				return EntryResult{App: appName, Entry: subEntry.Name, Action: ActionFailed, Err: err}
+			}
+		}
+
+		fallthrough
+	case ResolveUseBackup:
+		rm := *m
+		rm.NoMerge, rm.ForceDelete = true, true
+
+		return rm.RestoreEntry(appName, subEntry, target)
+	default:
+		return m.RestoreEntry(appName, subEntry, target)
+	}
+}
@@ -0,0 +1,111 @@
+package manager
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestTargetConflicts(t *testing.T) {
+	mgr, root, home := newLinkManager(t)
+
+	writeTestFile(t, filepath.Join(home, "nvim", "init.lua"), "mine")
+	writeTestFile(t, filepath.Join(home, ".zshrc"), "mine")
+	writeTestFile(t, filepath.Join(home, "copy", ".zshrc"), "zsh/.zshrc") // in sync with the backup
+
+	apps := mgr.GetApplications()
+	nvim, rc, copied := apps[0].Entries[0], apps[1].Entries[0], apps[1].Entries[1]
+
+	got := mgr.TargetConflicts(nvim, filepath.Join(home, "nvim"))
+	if len(got) != 1 || got[0] != (TargetConflict{Target: filepath.Join(home, "nvim"), Backup: filepath.Join(root, "nvim"), IsDir: true}) {
+		t.Errorf("TargetConflicts(nvim) = %+v", got)
+	}
+
+	got = mgr.TargetConflicts(rc, home)
+	if len(got) != 1 || got[0] != (TargetConflict{Target: filepath.Join(home, ".zshrc"), Backup: filepath.Join(root, "zsh", ".zshrc")}) {
+		t.Errorf("TargetConflicts(rc) = %+v, want only .zshrc", got)
+	}
+
+	if got := mgr.TargetConflicts(copied, filepath.Join(home, "copy")); len(got) != 0 {
+		t.Errorf("TargetConflicts(copied) = %+v for a copy in sync, want none", got)
+	}
+
+	writeTestFile(t, filepath.Join(home, "copy", ".zshrc"), "edited")
+
+	if got := mgr.TargetConflicts(copied, filepath.Join(home, "copy")); len(got) != 1 {
+		t.Errorf("TargetConflicts(copied) = %+v for an edited copy, want one", got)
+	}
+
+	// A deployed link is not a conflict.
+	if err := os.Remove(filepath.Join(home, ".zshrc")); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := os.Symlink(filepath.Join(root, "zsh", ".zshrc"), filepath.Join(home, ".zshrc")); err != nil {
+		t.Fatal(err)
+	}
+
+	if got := mgr.TargetConflicts(rc, home); len(got) != 0 {
+		t.Errorf("TargetConflicts(rc) = %+v once linked, want none", got)
+	}
+}
+
+func TestRestoreEntryResolved(t *testing.T) {
+	tests := []struct {
+		res        Resolution
+		wantAction EntryAction
+		wantBackup string // content of zsh/.zshrc in the backup afterwards
+		wantLinked bool
+	}{
+		{ResolveKeepTarget, ActionRestored, "mine", true},
+		{ResolveUseBackup, ActionRestored, "zsh/.zshrc", true},
+		{ResolveSkip, ActionSkipped, "zsh/.zshrc", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.res.String(), func(t *testing.T) {
+			mgr, root, home := newLinkManager(t)
+			writeTestFile(t, filepath.Join(home, ".zshrc"), "mine")
+
+			rc := mgr.GetApplications()[1].Entries[0]
+
+			result := mgr.RestoreEntryResolved("zsh", rc, home, tt.res)
+			if result.Action != tt.wantAction {
+				t.Fatalf("Action = %s (%v), want %s", result.Action, result.Err, tt.wantAction)
+			}
+
+			if got := readTestFile(t, filepath.Join(root, "zsh", ".zshrc")); got != tt.wantBackup {
+				t.Errorf("backup .zshrc = %q, want %q", got, tt.wantBackup)
+			}
+
+			if linked := mgr.isSymlink(filepath.Join(home, ".zshrc")); linked != tt.wantLinked {
+				t.Errorf(".zshrc linked = %v, want %v", linked, tt.wantLinked)
+			}
+
+			// No renamed conflict copy is left behind, as a merge would.
+			matches, _ := filepath.Glob(filepath.Join(root, "zsh", ".zshrc_target_*")) //nolint:errcheck // pattern is valid
+			if len(matches) != 0 {
+				t.Errorf("conflict copies %v left in the backup", matches)
+			}
+		})
+	}
+}
+
+func TestRestoreEntryResolved_UseBackupFolder(t *testing.T) {
+	mgr, root, home := newLinkManager(t)
+	writeTestFile(t, filepath.Join(home, "nvim", "init.lua"), "mine")
+
+	nvim := mgr.GetApplications()[0].Entries[0]
+
+	if result := mgr.RestoreEntryResolved("neovim", nvim, filepath.Join(home, "nvim"), ResolveUseBackup); !result.OK() {
+		t.Fatalf("RestoreEntryResolved() = %+v", result)
+	}
+
+	if got := readTestFile(t, filepath.Join(home, "nvim", "init.lua")); got != "nvim/init.lua" {
+		t.Errorf("init.lua through the link = %q, want the backup's", got)
+	}
+
+	if got := readTestFile(t, filepath.Join(root, "nvim", "init.lua")); got != "nvim/init.lua" {
+		t.Errorf("backup init.lua = %q, want it unchanged", got)
+	}
+}
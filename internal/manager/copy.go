@@ -4,24 +4,50 @@ import (
 	"bytes"
 	"fmt"
 	"log/slog"
+	"path/filepath"
 	"runtime"
 
 	"github.com/AntoineGS/tidydots/internal/config"
 	"github.com/AntoineGS/tidydots/internal/platform"
 )
 
-// copyFileTo copies src to dst. When useSudo is true (and not Windows) it shells
-// out to `cp`; otherwise it copies via the filesystem abstraction (copyFile),
-// which preserves the source file's permission bits. The sudo `cp` path is
-// subject to the process umask and does not bit-for-bit preserve unusual modes.
+// copyFileTo copies src to dst. The copy is written at a temp name next to dst
+// and renamed into place, so dst is never left half written. When useSudo is
+// true (and not Windows) it shells out to `cp` and `mv`; otherwise it copies via
+// the filesystem abstraction, which preserves the source file's permission bits.
+// The sudo `cp` path is subject to the process umask and does not bit-for-bit
+// preserve unusual modes.
 func (m *Manager) copyFileTo(src, dst string, useSudo bool) error {
 	if useSudo && runtime.GOOS != platform.OSWindows {
-		if _, err := m.runner.RunWithSudo(m.ctx, "cp", src, dst); err != nil {
+		tmpPath := atomicTempPath(dst)
+
+		if _, err := m.runner.RunWithSudo(m.ctx, "cp", src, tmpPath); err != nil {
+			return err
+		}
+
+		if _, err := m.runner.RunWithSudo(m.ctx, "mv", "-f", tmpPath, dst); err != nil {
+			_, _ = m.runner.RunWithSudo(m.ctx, "rm", "-f", tmpPath) //nolint:errcheck // best-effort cleanup
 			return err
 		}
+
 		return nil
 	}
-	return m.copyFile(src, dst)
+
+	data, err := m.fs.ReadFile(src)
+	if err != nil {
+		return fmt.Errorf("opening source: %w", err)
+	}
+
+	srcInfo, err := m.fs.Stat(src)
+	if err != nil {
+		return fmt.Errorf("stating source: %w", err)
+	}
+
+	if err := m.fs.MkdirAll(filepath.Dir(dst), DirPerms); err != nil {
+		return fmt.Errorf("creating destination directory: %w", err)
+	}
+
+	return m.writeFileAtomic(dst, data, srcInfo.Mode().Perm())
 }
 
 // removePath removes a single file or symlink at path. When useSudo is true (and
@@ -71,7 +97,8 @@ func (m *Manager) filesEqual(src, dst string, useSudo bool) (bool, error) {
 // dstFile, used for entries with method: copy. It replaces any pre-existing
 // symlink at dstFile (migration from a prior symlink-mode deployment) and is
 // idempotent: when dstFile already exists as a real file whose contents match
-// srcFile, it performs no write. All actions respect DryRun.
+// srcFile, it performs no write. When srcFile is an object pointer, the
+// object's content is deployed. All actions respect DryRun.
 func (m *Manager) restoreFileCopy(subEntry config.SubEntry, srcFile, dstFile string) error {
 	if !m.pathExists(srcFile) {
 		if m.DryRun {
@@ -82,9 +109,18 @@ func (m *Manager) restoreFileCopy(subEntry config.SubEntry, srcFile, dstFile str
 			"source file does not exist; copy mode does not adopt an existing target — run `tidydots backup` first to pull it into your repo"))
 	}
 
+	srcFile, err := m.objectSource(srcFile)
+	if err != nil {
+		return NewPathError("restore", dstFile, err)
+	}
+
+	conflict := ConflictNone
+
 	switch {
 	case m.isSymlink(dstFile):
 		m.logger.Info("removing existing symlink", slog.String("path", dstFile))
+		conflict = ConflictRelink
+
 		if !m.DryRun {
 			if err := m.removePath(dstFile, subEntry.Sudo); err != nil {
 				return NewPathError("restore", dstFile, fmt.Errorf("removing existing symlink: %w", err))
@@ -97,14 +133,20 @@ func (m *Manager) restoreFileCopy(subEntry config.SubEntry, srcFile, dstFile str
 		}
 		if equal {
 			m.logger.Debug("copy already in sync", slog.String("path", dstFile))
+			m.step(StepCopy, srcFile, dstFile, ConflictUnchanged)
+
 			return nil
 		}
+
+		conflict = ConflictReplace
 	}
 
 	m.logger.Info("copying file",
 		slog.String("target", dstFile),
 		slog.String("source", srcFile))
 
+	m.step(StepCopy, srcFile, dstFile, conflict)
+
 	if m.DryRun {
 		return nil
 	}
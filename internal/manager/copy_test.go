@@ -2,6 +2,7 @@ package manager
 
 import (
 	"io/fs"
+	"strings"
 	"testing"
 
 	"github.com/AntoineGS/tidydots/internal/cmdexec"
@@ -64,7 +65,7 @@ func TestFilesEqual_Sudo_UsesCmpExitCode(t *testing.T) {
 	}
 }
 
-func TestCopyFileTo_Sudo_RecordsCp(t *testing.T) {
+func TestCopyFileTo_Sudo_RecordsCpThenMv(t *testing.T) {
 	t.Parallel()
 	skipIfNoSudo(t)
 	mgr, mem, stub := newSudoManager(t)
@@ -73,9 +74,29 @@ func TestCopyFileTo_Sudo_RecordsCp(t *testing.T) {
 	if err := mgr.copyFileTo("/src", "/dst", true); err != nil {
 		t.Fatalf("copyFileTo sudo: %v", err)
 	}
-	if len(stub.Calls) != 1 || stub.Calls[0].Name != "cp" ||
-		stub.Calls[0].Args[0] != "/src" || stub.Calls[0].Args[1] != "/dst" {
-		t.Errorf("expected sudo `cp /src /dst`, got %+v", stub.Calls)
+	// The copy is made at a temp name and renamed over /dst.
+	if len(stub.Calls) != 2 ||
+		stub.Calls[0].Name != "cp" || strings.Join(stub.Calls[0].Args, " ") != "/src /.dst.tidydots-tmp" ||
+		stub.Calls[1].Name != "mv" || strings.Join(stub.Calls[1].Args, " ") != "-f /.dst.tidydots-tmp /dst" {
+		t.Errorf("expected sudo `cp /src /.dst.tidydots-tmp` then `mv -f /.dst.tidydots-tmp /dst`, got %+v", stub.Calls)
+	}
+}
+
+func TestCopyFileTo_NoSudo_ReplacesAtomically(t *testing.T) {
+	t.Parallel()
+	mgr, mem := newMemManager(t)
+	_ = mem.WriteFile("/src", []byte("new"), 0644)
+	_ = mem.WriteFile("/dst", []byte("old"), 0644)
+	_ = mem.WriteFile("/.dst.tidydots-tmp", []byte("left by a crash"), 0644)
+
+	if err := mgr.copyFileTo("/src", "/dst", false); err != nil {
+		t.Fatalf("copyFileTo: %v", err)
+	}
+	if got, _ := mem.ReadFile("/dst"); string(got) != "new" {
+		t.Errorf("dst = %q, want \"new\"", got)
+	}
+	if _, err := mem.Lstat("/.dst.tidydots-tmp"); err == nil {
+		t.Error("temp file left behind after copyFileTo")
 	}
 }
 
@@ -231,10 +252,11 @@ func TestRestoreFileCopy_Sudo_MigratesSymlinkThenCopies(t *testing.T) {
 	if err := mgr.restoreFileCopy(copyEntry(true), "/backup/f", "/etc/f"); err != nil {
 		t.Fatalf("restoreFileCopy sudo: %v", err)
 	}
-	// Expect a sudo `rm -f` (remove symlink) followed by a sudo `cp`.
-	if len(stub.Calls) != 2 ||
-		stub.Calls[0].Name != "rm" || stub.Calls[1].Name != "cp" {
-		t.Errorf("expected sudo rm then cp, got %+v", stub.Calls)
+	// Expect a sudo `rm -f` (remove symlink) followed by a sudo `cp` to a temp
+	// name and its `mv` into place.
+	if len(stub.Calls) != 3 ||
+		stub.Calls[0].Name != "rm" || stub.Calls[1].Name != "cp" || stub.Calls[2].Name != "mv" {
+		t.Errorf("expected sudo rm, cp then mv, got %+v", stub.Calls)
 	}
 }
 
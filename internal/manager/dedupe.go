@@ -0,0 +1,186 @@
+package manager
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"log/slog"
+
+	"github.com/AntoineGS/tidydots/internal/config"
+	"github.com/AntoineGS/tidydots/internal/objects"
+)
+
+// backupBlob backs up srcFile to dstFile for an entry with dedupe: true. It
+// reports false, and does nothing, when srcFile is smaller than the dedupe
+// threshold or its content changed, so the caller copies it as usual. A
+// large copy-entry file is written to the object store instead when dedupe
+// objects are enabled.
+func (m *Manager) backupBlob(subEntry config.SubEntry, srcFile, dstFile string) (bool, error) {
+	info, err := m.fs.Stat(srcFile)
+	if err != nil || info.Size() < m.Config.DedupeThreshold() {
+		return false, nil
+	}
+
+	if m.Config.DedupeObjects() && subEntry.IsCopy() {
+		return true, m.backupObject(subEntry, srcFile, dstFile)
+	}
+
+	same, err := m.sameContent(srcFile, dstFile)
+	if err != nil {
+		return true, NewPathError("backup", srcFile, fmt.Errorf("comparing files: %w", err))
+	}
+
+	if !same {
+		return false, nil
+	}
+
+	m.logger.Debug("backup already up to date", slog.String("path", dstFile))
+	m.step(StepBackup, srcFile, dstFile, ConflictUnchanged)
+
+	if subEntry.Verify && !m.DryRun && !m.pathExists(dstFile+ChecksumSuffix) {
+		return true, m.writeChecksum(dstFile)
+	}
+
+	return true, nil
+}
+
+// backupObject stores srcFile in the object store and points dstFile at it.
+// An object already in the store is not written again, and a pointer already
+// naming it is left alone.
+func (m *Manager) backupObject(subEntry config.SubEntry, srcFile, dstFile string) error {
+	data, err := m.fs.ReadFile(srcFile)
+	if err != nil {
+		return NewPathError("backup", srcFile, fmt.Errorf("reading file: %w", err))
+	}
+
+	ptr := objects.NewPointer(data)
+	object := objects.Path(m.Config.BackupRoot, ptr.Hash)
+
+	if existing, ok := m.readPointer(dstFile); ok && existing == ptr && m.pathExists(object) {
+		m.logger.Debug("backup already up to date", slog.String("path", dstFile), slog.String("object", ptr.Hash))
+		m.step(StepBackup, srcFile, dstFile, ConflictUnchanged)
+
+		return nil
+	}
+
+	m.logger.Info("backing up file to object store",
+		slog.String("from", srcFile),
+		slog.String("to", dstFile),
+		slog.String("object", ptr.Hash))
+
+	m.step(StepBackup, srcFile, dstFile, m.backupConflict(dstFile))
+
+	if m.DryRun {
+		return nil
+	}
+
+	if !m.pathExists(object) {
+		if err := m.copyFile(srcFile, object); err != nil {
+			return NewPathError("backup", object, fmt.Errorf("storing object: %w", err))
+		}
+	}
+
+	if err := m.fs.WriteFile(dstFile, []byte(ptr.String()), FilePerms); err != nil {
+		return NewPathError("backup", dstFile, fmt.Errorf("writing pointer: %w", err))
+	}
+
+	if subEntry.Verify {
+		return m.writeChecksum(dstFile)
+	}
+
+	return nil
+}
+
+// copyChanged copies src to dst, unless src is at least the dedupe threshold
+// and dst already has the same content. It backs up the folders of entries
+// with dedupe: true.
+func (m *Manager) copyChanged(src, dst string) error {
+	if info, err := m.fs.Stat(src); err == nil && info.Size() >= m.Config.DedupeThreshold() {
+		same, err := m.sameContent(src, dst)
+		if err != nil {
+			return err
+		}
+
+		if same {
+			m.logger.Debug("backup already up to date", slog.String("path", dst))
+			return nil
+		}
+	}
+
+	return m.copyFile(src, dst)
+}
+
+// sameContent reports whether the regular file dst exists and has the content
+// of src. Sizes are compared before anything is hashed.
+func (m *Manager) sameContent(src, dst string) (bool, error) {
+	dstInfo, err := m.fs.Lstat(dst)
+	if err != nil || !dstInfo.Mode().IsRegular() {
+		return false, nil
+	}
+
+	srcInfo, err := m.fs.Stat(src)
+	if err != nil {
+		return false, err
+	}
+
+	if srcInfo.Size() != dstInfo.Size() {
+		return false, nil
+	}
+
+	srcSum, err := m.hashFile(src)
+	if err != nil {
+		return false, err
+	}
+
+	dstSum, err := m.hashFile(dst)
+	if err != nil {
+		return false, err
+	}
+
+	return srcSum == dstSum, nil
+}
+
+// readPointer reads the object pointer at path. It reports false when path is
+// not a regular file holding a pointer.
+func (m *Manager) readPointer(path string) (objects.Pointer, bool) {
+	info, err := m.fs.Lstat(path)
+	if err != nil || !info.Mode().IsRegular() || info.Size() > objects.MaxPointerSize {
+		return objects.Pointer{}, false
+	}
+
+	data, err := m.fs.ReadFile(path)
+	if err != nil {
+		return objects.Pointer{}, false
+	}
+
+	return objects.Parse(data)
+}
+
+// objectSource returns the file to deploy for the backup file srcFile: the
+// object it points to, or srcFile itself when it is not a pointer. An object
+// that is missing, or whose content no longer matches its hash, is an error
+// wrapping ErrObjectMissing.
+func (m *Manager) objectSource(srcFile string) (string, error) {
+	ptr, ok := m.readPointer(srcFile)
+	if !ok {
+		return srcFile, nil
+	}
+
+	object := objects.Path(m.Config.BackupRoot, ptr.Hash)
+
+	data, err := m.fs.ReadFile(object)
+	if errors.Is(err, fs.ErrNotExist) {
+		return "", fmt.Errorf("%w: %s is not in %s; commit it, or back the file up again on a machine that has it",
+			ErrObjectMissing, ptr.Hash, objects.Dir)
+	}
+
+	if err != nil {
+		return "", fmt.Errorf("reading object %s: %w", ptr.Hash, err)
+	}
+
+	if !ptr.Matches(data) {
+		return "", fmt.Errorf("object %s does not match its hash", ptr.Hash)
+	}
+
+	return object, nil
+}
@@ -0,0 +1,251 @@
+package manager
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/AntoineGS/tidydots/internal/config"
+	"github.com/AntoineGS/tidydots/internal/objects"
+	"github.com/AntoineGS/tidydots/internal/platform"
+)
+
+// blob is larger than the 8-byte threshold newDedupeManager sets.
+const blob = "firmware image v1"
+
+// newDedupeManager returns a Linux manager with one entry, "fonts", that
+// dedupes font.bin between home and the "fonts" folder of the backup root,
+// deployed with method.
+func newDedupeManager(t *testing.T, method string, useObjects bool) (mgr *Manager, root, home string) {
+	t.Helper()
+
+	root = t.TempDir()
+	home = t.TempDir()
+
+	cfg := &config.Config{
+		Version:    3,
+		BackupRoot: root,
+		Dedupe:     &config.Dedupe{Threshold: "8B", Objects: useObjects},
+		Applications: []config.Application{
+			{Name: "kbd", Entries: []config.SubEntry{
+				{Name: "fonts", Backup: "./fonts", Method: method, Dedupe: true, Files: []string{"font.bin", "small"}, Targets: map[string]string{"linux": home}},
+			}},
+		},
+	}
+
+	mgr = New(cfg, &platform.Platform{OS: platform.OSLinux, EnvVars: map[string]string{}})
+
+	return mgr, root, home
+}
+
+func writeTestFile(t *testing.T, path, content string) {
+	t.Helper()
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func readTestFile(t *testing.T, path string) string {
+	t.Helper()
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return string(data)
+}
+
+func TestBackup_DedupeSkipsUnchangedLargeFiles(t *testing.T) {
+	mgr, root, home := newDedupeManager(t, "", false)
+
+	writeTestFile(t, filepath.Join(home, "font.bin"), blob)
+	writeTestFile(t, filepath.Join(root, "fonts", "font.bin"), blob)
+
+	old := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	if err := os.Chtimes(filepath.Join(root, "fonts", "font.bin"), old, old); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := mgr.Backup(); err != nil {
+		t.Fatalf("Backup() error = %v", err)
+	}
+
+	info, err := os.Stat(filepath.Join(root, "fonts", "font.bin"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !info.ModTime().Equal(old) {
+		t.Error("unchanged font.bin was copied again")
+	}
+
+	writeTestFile(t, filepath.Join(home, "font.bin"), "firmware image v2")
+
+	if err := mgr.Backup(); err != nil {
+		t.Fatalf("Backup() error = %v", err)
+	}
+
+	if got := readTestFile(t, filepath.Join(root, "fonts", "font.bin")); got != "firmware image v2" {
+		t.Errorf("changed font.bin backed up as %q", got)
+	}
+}
+
+func TestBackup_DedupeFolderSkipsUnchangedLargeFiles(t *testing.T) {
+	root := t.TempDir()
+	home := t.TempDir()
+
+	writeTestFile(t, filepath.Join(home, "fonts", "a.ttf"), blob)
+	writeTestFile(t, filepath.Join(home, "fonts", "b.ttf"), blob+" bold")
+	writeTestFile(t, filepath.Join(root, "fonts", "a.ttf"), blob)
+
+	old := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	if err := os.Chtimes(filepath.Join(root, "fonts", "a.ttf"), old, old); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := &config.Config{
+		Version:    3,
+		BackupRoot: root,
+		Dedupe:     &config.Dedupe{Threshold: "8"},
+		Applications: []config.Application{
+			{Name: "fonts", Entries: []config.SubEntry{
+				{Name: "ttf", Backup: "./fonts", Dedupe: true, Targets: map[string]string{"linux": filepath.Join(home, "fonts")}},
+			}},
+		},
+	}
+
+	if err := New(cfg, &platform.Platform{OS: platform.OSLinux, EnvVars: map[string]string{}}).Backup(); err != nil {
+		t.Fatalf("Backup() error = %v", err)
+	}
+
+	info, err := os.Stat(filepath.Join(root, "fonts", "a.ttf"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !info.ModTime().Equal(old) {
+		t.Error("unchanged a.ttf was copied again")
+	}
+
+	if got := readTestFile(t, filepath.Join(root, "fonts", "b.ttf")); got != blob+" bold" {
+		t.Errorf("new b.ttf backed up as %q", got)
+	}
+}
+
+func TestBackup_DedupeObjects(t *testing.T) {
+	mgr, root, home := newDedupeManager(t, config.MethodCopy, true)
+
+	writeTestFile(t, filepath.Join(home, "font.bin"), blob)
+	writeTestFile(t, filepath.Join(home, "small"), "tiny")
+
+	if err := mgr.Backup(); err != nil {
+		t.Fatalf("Backup() error = %v", err)
+	}
+
+	ptr, ok := objects.Parse([]byte(readTestFile(t, filepath.Join(root, "fonts", "font.bin"))))
+	if !ok {
+		t.Fatal("font.bin in the backup is not a pointer")
+	}
+
+	if got := readTestFile(t, objects.Path(root, ptr.Hash)); got != blob {
+		t.Errorf("object = %q, want %q", got, blob)
+	}
+
+	// Files below the threshold are backed up as they are.
+	if got := readTestFile(t, filepath.Join(root, "fonts", "small")); got != "tiny" {
+		t.Errorf("small = %q, want %q", got, "tiny")
+	}
+
+	// Backing up the same content again changes nothing.
+	report, err := mgr.BackupReport(context.Background())
+	if err != nil {
+		t.Fatalf("BackupReport() error = %v", err)
+	}
+
+	for _, s := range report.Entries[0].Steps {
+		if s.Target == filepath.Join(root, "fonts", "font.bin") && s.Conflict != ConflictUnchanged {
+			t.Errorf("second backup of font.bin = %+v, want it unchanged", s)
+		}
+	}
+
+	entries, err := os.ReadDir(filepath.Join(root, filepath.FromSlash(objects.Dir)))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(entries) != 1 {
+		t.Errorf("object store holds %d objects, want 1", len(entries))
+	}
+}
+
+func TestRestore_DedupeObjectsOnNewMachine(t *testing.T) {
+	mgr, root, home := newDedupeManager(t, config.MethodCopy, true)
+
+	// The repo was cloned from a machine that backed up; this one never did.
+	ptr := objects.NewPointer([]byte(blob))
+	writeTestFile(t, objects.Path(root, ptr.Hash), blob)
+	writeTestFile(t, filepath.Join(root, "fonts", "font.bin"), ptr.String())
+	writeTestFile(t, filepath.Join(root, "fonts", "small"), "tiny")
+
+	if err := mgr.Restore(); err != nil {
+		t.Fatalf("Restore() error = %v", err)
+	}
+
+	if got := readTestFile(t, filepath.Join(home, "font.bin")); got != blob {
+		t.Errorf("restored font.bin = %q, want %q", got, blob)
+	}
+
+	if got := readTestFile(t, filepath.Join(home, "small")); got != "tiny" {
+		t.Errorf("restored small = %q, want %q", got, "tiny")
+	}
+
+	// A second restore finds the target in sync with the object.
+	report, err := mgr.RestoreReport(context.Background())
+	if err != nil {
+		t.Fatalf("RestoreReport() error = %v", err)
+	}
+
+	for _, s := range report.Entries[0].Steps {
+		if s.Conflict != ConflictUnchanged {
+			t.Errorf("second restore step = %+v, want it unchanged", s)
+		}
+	}
+}
+
+func TestRestore_DedupeObjectMissing(t *testing.T) {
+	mgr, root, home := newDedupeManager(t, config.MethodCopy, true)
+
+	writeTestFile(t, filepath.Join(root, "fonts", "font.bin"), objects.NewPointer([]byte(blob)).String())
+
+	err := mgr.Restore()
+	if !errors.Is(err, ErrObjectMissing) {
+		t.Fatalf("Restore() error = %v, want ErrObjectMissing", err)
+	}
+
+	if mgr.pathExists(filepath.Join(home, "font.bin")) {
+		t.Error("font.bin was deployed without its object")
+	}
+}
+
+func TestRestore_DedupePointerNeedsCopyMethod(t *testing.T) {
+	mgr, root, home := newDedupeManager(t, "", true)
+
+	writeTestFile(t, filepath.Join(root, "fonts", "font.bin"), objects.NewPointer([]byte(blob)).String())
+
+	if err := mgr.Restore(); err == nil {
+		t.Fatal("Restore() = nil, want an error for a pointer in a symlink entry")
+	}
+
+	if mgr.pathExists(filepath.Join(home, "font.bin")) {
+		t.Error("a link to the pointer was created")
+	}
+}
@@ -0,0 +1,142 @@
+package manager
+
+import (
+	"bytes"
+	"fmt"
+	"log/slog"
+	"path/filepath"
+	"strings"
+
+	"github.com/AntoineGS/tidydots/internal/cmdexec"
+	"github.com/AntoineGS/tidydots/internal/config"
+)
+
+// gitRepoRoot returns the closest directory at or above dir that contains a
+// .git entry (a directory, or a file for worktrees and submodules), or "" if
+// dir is not inside a git repository.
+func (m *Manager) gitRepoRoot(dir string) string {
+	for {
+		if _, err := m.fs.Lstat(filepath.Join(dir, ".git")); err == nil {
+			return dir
+		}
+
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return ""
+		}
+
+		dir = parent
+	}
+}
+
+// DirtyBackupFiles returns the absolute paths of files in the backup repo that
+// have uncommitted changes, untracked files included. Edits made through a
+// symlink land directly in the repo, so this is how they are noticed.
+//
+// It runs a single `git status` for the whole repository; use EntryIsDirty to
+// map the result onto entries. A nil map with no error means the check does
+// not apply: it is disabled with `dirty_check: false`, git is not installed,
+// or the backup root is not inside a git repository.
+func (m *Manager) DirtyBackupFiles() (map[string]bool, error) {
+	if !m.Config.DirtyCheckEnabled() {
+		return nil, nil
+	}
+
+	if _, err := m.runner.LookPath("git"); err != nil {
+		return nil, nil
+	}
+
+	backupRoot := config.ExpandPath(m.Config.BackupRoot, m.Platform.EnvVars)
+
+	root := m.gitRepoRoot(backupRoot)
+	if root == "" {
+		return nil, nil
+	}
+
+	res, err := m.runner.RunIn(m.ctx, cmdexec.RunOptions{Dir: root},
+		"git", "status", "--porcelain", "-z", "--untracked-files=all")
+	if err != nil {
+		return nil, fmt.Errorf("git status in %s: %w: %s", root, err, strings.TrimSpace(string(res.Stderr)))
+	}
+
+	dirty := parsePorcelainZ(root, res.Stdout)
+
+	m.logger.Debug("checked backup repo for uncommitted changes",
+		slog.String("repo", root),
+		slog.Int("dirty_files", len(dirty)))
+
+	return dirty, nil
+}
+
+// parsePorcelainZ parses `git status --porcelain -z` output into a set of
+// absolute paths under root. Each record is "XY <path>"; renames and copies
+// are followed by an extra record holding the original path, which is skipped.
+func parsePorcelainZ(root string, out []byte) map[string]bool {
+	dirty := make(map[string]bool)
+
+	records := bytes.Split(out, []byte{0})
+	for i := 0; i < len(records); i++ {
+		rec := string(records[i])
+		if len(rec) < 4 {
+			continue
+		}
+
+		dirty[filepath.Join(root, filepath.FromSlash(rec[3:]))] = true
+
+		if rec[0] == 'R' || rec[0] == 'C' {
+			i++
+		}
+	}
+
+	return dirty
+}
+
+// EntryIsDirty reports whether any backup file of a config sub-entry appears
+// in dirty, as returned by DirtyBackupFiles. Folder entries match any file
+// under backupPath; files entries match only their listed files.
+func EntryIsDirty(subEntry config.SubEntry, backupPath string, dirty map[string]bool) bool {
+	if len(dirty) == 0 {
+		return false
+	}
+
+	prefix := backupPath + string(filepath.Separator)
+
+	if !subEntry.IsFolder() {
+		for _, file := range subEntry.Files {
+			if dirty[filepath.Join(backupPath, subEntry.BackupName(file))] {
+				return true
+			}
+
+			if IsGlobPattern(file) && anyMatches(dirty, prefix, file) {
+				return true
+			}
+		}
+
+		return false
+	}
+
+	for path := range dirty {
+		if strings.HasPrefix(path, prefix) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// anyMatches reports whether any path in dirty, relative to prefix, matches
+// pattern.
+func anyMatches(dirty map[string]bool, prefix, pattern string) bool {
+	for path := range dirty {
+		rel, ok := strings.CutPrefix(path, prefix)
+		if !ok {
+			continue
+		}
+
+		if matched, _ := filepath.Match(pattern, rel); matched {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,127 @@
+package manager
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/AntoineGS/tidydots/internal/cmdexec"
+	"github.com/AntoineGS/tidydots/internal/config"
+	"github.com/AntoineGS/tidydots/internal/platform"
+)
+
+func TestParsePorcelainZ(t *testing.T) {
+	t.Parallel()
+	root := filepath.Join("/", "repo")
+
+	out := " M nvim/init.lua\x00?? zsh/.zshrc\x00R  git/config\x00git/old-config\x00A  dir with space/f\x00"
+	got := parsePorcelainZ(root, []byte(out))
+
+	want := []string{
+		filepath.Join(root, "nvim", "init.lua"),
+		filepath.Join(root, "zsh", ".zshrc"),
+		filepath.Join(root, "git", "config"),
+		filepath.Join(root, "dir with space", "f"),
+	}
+
+	if len(got) != len(want) {
+		t.Fatalf("parsePorcelainZ() = %v, want %v", got, want)
+	}
+
+	for _, path := range want {
+		if !got[path] {
+			t.Errorf("parsePorcelainZ() missing %s", path)
+		}
+	}
+}
+
+func TestEntryIsDirty(t *testing.T) {
+	t.Parallel()
+	backup := filepath.Join("/", "repo", "zsh")
+	dirty := map[string]bool{filepath.Join(backup, ".zshrc"): true}
+
+	tests := []struct {
+		name  string
+		entry config.SubEntry
+		dirty map[string]bool
+		want  bool
+	}{
+		{"listed file modified", config.SubEntry{Backup: "./zsh", Files: []string{".zshrc", ".zshenv"}}, dirty, true},
+		{"other file modified", config.SubEntry{Backup: "./zsh", Files: []string{".zshenv"}}, dirty, false},
+		{"folder contains modified file", config.SubEntry{Backup: "./zsh"}, dirty, true},
+		{"nothing dirty", config.SubEntry{Backup: "./zsh", Files: []string{".zshrc"}}, nil, false},
+		{"sibling folder with shared prefix", config.SubEntry{Backup: "./zsh"}, map[string]bool{backup + "-old/.zshrc": true}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			if got := EntryIsDirty(tt.entry, backup, tt.dirty); got != tt.want {
+				t.Errorf("EntryIsDirty() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDirtyBackupFiles(t *testing.T) {
+	t.Parallel()
+
+	disabled := false
+
+	tests := []struct {
+		name      string
+		gitRepo   bool
+		dirtyFlag *bool
+		wantCalls int
+		wantFiles int
+	}{
+		{name: "runs git status once from the repo root", gitRepo: true, wantCalls: 1, wantFiles: 1},
+		{name: "skipped outside a git repo", gitRepo: false, wantCalls: 0},
+		{name: "skipped when disabled in config", gitRepo: true, dirtyFlag: &disabled, wantCalls: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			repo := t.TempDir()
+
+			if tt.gitRepo {
+				if err := os.Mkdir(filepath.Join(repo, ".git"), 0750); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			// The backup root may be a subdirectory of the repository.
+			backupRoot := filepath.Join(repo, "dotfiles")
+
+			stub := cmdexec.NewStubRunner()
+			stub.AddPath("git", "/usr/bin/git")
+			stub.AddResult("git", cmdexec.Result{Stdout: []byte(" M dotfiles/zsh/.zshrc\x00")})
+
+			cfg := &config.Config{Version: 3, BackupRoot: backupRoot, DirtyCheck: tt.dirtyFlag}
+			mgr := New(cfg, &platform.Platform{OS: platform.OSLinux}).WithRunner(stub)
+
+			files, err := mgr.DirtyBackupFiles()
+			if err != nil {
+				t.Fatalf("DirtyBackupFiles() error = %v", err)
+			}
+
+			if len(stub.Calls) != tt.wantCalls {
+				t.Fatalf("git invoked %d times, want %d", len(stub.Calls), tt.wantCalls)
+			}
+
+			if tt.wantCalls > 0 && stub.Calls[0].Dir != repo {
+				t.Errorf("git status ran in %q, want repo root %q", stub.Calls[0].Dir, repo)
+			}
+
+			if len(files) != tt.wantFiles {
+				t.Fatalf("DirtyBackupFiles() = %v, want %d files", files, tt.wantFiles)
+			}
+
+			if tt.wantFiles > 0 && !files[filepath.Join(backupRoot, "zsh", ".zshrc")] {
+				t.Errorf("DirtyBackupFiles() = %v, missing zsh/.zshrc", files)
+			}
+		})
+	}
+}
@@ -8,7 +8,20 @@ import (
 // Sentinel errors for common manager operations
 var (
 	ErrBackupNotFound = errors.New("backup not found")
+	ErrBackupEmpty    = errors.New("backup is empty")
 	ErrTargetExists   = errors.New("target already exists")
+	ErrInvalidGlob    = errors.New("invalid glob pattern")
+	ErrObjectMissing  = errors.New("object missing from the object store")
+	ErrCaseCollision  = errors.New("backup names differ only by case")
+
+	// ErrCircularDependency is returned by restore when the required_by
+	// lists of the applications form a cycle, which the error lists.
+	ErrCircularDependency = errors.New("circular dependency between applications")
+
+	// ErrMissingPrivileges is matched, through errors.Is, by the error of a
+	// run in which an entry failed because this user may not change its
+	// files.
+	ErrMissingPrivileges = errors.New("missing privileges")
 )
 
 // PathError records an error and the operation and path that caused it.
@@ -0,0 +1,153 @@
+package manager
+
+import (
+	"fmt"
+	"log/slog"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	tmpl "github.com/AntoineGS/tidydots/internal/template"
+)
+
+// IsGlobPattern reports whether a name listed in a files entry is a pattern,
+// i.e. contains * or ?, rather than the exact name of one file.
+func IsGlobPattern(name string) bool {
+	return strings.ContainsAny(name, "*?")
+}
+
+// expandFiles returns files with every glob pattern replaced by the files it
+// matches under dir, sorted, as paths relative to dir. Names that are not
+// patterns are kept as they are, whether they exist or not. Matched
+// directories, integrity sidecars and template render artifacts are left
+// out, and so is a file already listed. A pattern that is malformed fails
+// with ErrInvalidGlob.
+func (m *Manager) expandFiles(files []string, dir string) ([]string, error) {
+	if !slices.ContainsFunc(files, IsGlobPattern) {
+		return files, nil
+	}
+
+	expanded := make([]string, 0, len(files))
+
+	for _, file := range files {
+		if !IsGlobPattern(file) {
+			if !slices.Contains(expanded, file) {
+				expanded = append(expanded, file)
+			}
+
+			continue
+		}
+
+		matches, err := m.glob(dir, file)
+		if err != nil {
+			return nil, err
+		}
+
+		if len(matches) == 0 {
+			m.logger.Debug("pattern matches no file", slog.String("pattern", file), slog.String("dir", dir))
+		}
+
+		for _, match := range matches {
+			if !slices.Contains(expanded, match) {
+				expanded = append(expanded, match)
+			}
+		}
+	}
+
+	return expanded, nil
+}
+
+// glob returns the files under dir that match pattern, one path element per
+// pattern element, like filepath.Glob but through the Manager's filesystem.
+func (m *Manager) glob(dir, pattern string) ([]string, error) {
+	elems := strings.Split(filepath.ToSlash(pattern), "/")
+
+	for _, elem := range elems {
+		if _, err := filepath.Match(elem, ""); err != nil {
+			return nil, fmt.Errorf("%w %q: %w", ErrInvalidGlob, pattern, err)
+		}
+	}
+
+	matches := []string{""}
+
+	for i, elem := range elems {
+		var next []string
+
+		for _, prefix := range matches {
+			entries, err := m.fs.ReadDir(filepath.Join(dir, prefix))
+			if err != nil {
+				continue
+			}
+
+			for _, e := range entries {
+				if ok, _ := filepath.Match(elem, e.Name()); !ok {
+					continue
+				}
+
+				// Only the last element may name a file.
+				if e.IsDir() != (i < len(elems)-1) {
+					continue
+				}
+
+				next = append(next, filepath.Join(prefix, e.Name()))
+			}
+		}
+
+		matches = next
+	}
+
+	matches = slices.DeleteFunc(matches, func(match string) bool {
+		return IsChecksumFile(match) || tmpl.IsRenderedFile(match) || tmpl.IsConflictFile(match)
+	})
+	slices.Sort(matches)
+
+	return matches, nil
+}
+
+// MatchFiles returns the files that pattern, a name listed in a files entry,
+// matches in the backup directory backup, as paths relative to it. backup is
+// resolved like an entry's backup path.
+func (m *Manager) MatchFiles(backup, pattern string) ([]string, error) {
+	return m.glob(m.resolvePath(backup), pattern)
+}
+
+// UnmatchedPattern is a glob pattern of a files entry that matches no file in
+// the entry's backup directory.
+type UnmatchedPattern struct {
+	App     string
+	Entry   string
+	Pattern string
+}
+
+// String describes the pattern for a warning.
+func (u UnmatchedPattern) String() string {
+	return fmt.Sprintf("%s/%s: pattern %q matches no file in the backup", u.App, u.Entry, u.Pattern)
+}
+
+// UnmatchedPatterns lists the glob patterns of the current platform's config
+// entries that match no file in their backup directory, which restore and
+// backup skip without a word. Malformed patterns are left out; restore and
+// backup fail them.
+func (m *Manager) UnmatchedPatterns() []UnmatchedPattern {
+	var unmatched []UnmatchedPattern
+
+	for _, app := range m.applicationsByPriority() {
+		for _, subEntry := range app.Entries {
+			if !subEntry.IsConfig() || subEntry.IsFolder() || subEntry.GetTarget(m.Platform.OS) == "" {
+				continue
+			}
+
+			for _, file := range subEntry.Files {
+				if !IsGlobPattern(file) {
+					continue
+				}
+
+				if matches, err := m.MatchFiles(subEntry.Backup, file); err == nil && len(matches) == 0 {
+					unmatched = append(unmatched, UnmatchedPattern{App: app.Name, Entry: subEntry.Name, Pattern: file})
+				}
+			}
+		}
+	}
+
+	return unmatched
+}
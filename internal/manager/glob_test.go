@@ -0,0 +1,188 @@
+package manager
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/AntoineGS/tidydots/internal/config"
+	"github.com/AntoineGS/tidydots/internal/platform"
+)
+
+// writeGlobFiles creates each of paths, relative to dir, with its path as
+// content.
+func writeGlobFiles(t *testing.T, dir string, paths ...string) {
+	t.Helper()
+
+	for _, path := range paths {
+		full := filepath.Join(dir, path)
+		if err := os.MkdirAll(filepath.Dir(full), 0o755); err != nil {
+			t.Fatal(err)
+		}
+
+		if err := os.WriteFile(full, []byte(path), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func newGlobManager(backupRoot string) *Manager {
+	cfg := &config.Config{Version: 3, BackupRoot: backupRoot}
+	return New(cfg, &platform.Platform{OS: platform.OSLinux, EnvVars: map[string]string{}})
+}
+
+func TestExpandFiles(t *testing.T) {
+	dir := t.TempDir()
+	writeGlobFiles(t, dir,
+		"a.conf", "b.conf", "b.conf"+ChecksumSuffix, "x.txt", "x.tmpl.rendered",
+		"themes/dark.toml", "themes/light.toml", "themes/extra/blue.toml")
+
+	if err := os.Mkdir(filepath.Join(dir, "dir.conf"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	mgr := newGlobManager(dir)
+
+	tests := []struct {
+		name  string
+		files []string
+		want  []string
+	}{
+		{
+			name:  "exact names are kept as they are",
+			files: []string{"x.txt", "missing.txt"},
+			want:  []string{"x.txt", "missing.txt"},
+		},
+		{
+			name:  "star skips directories, sidecars and render artifacts",
+			files: []string{"*.conf", "*.rendered"},
+			want:  []string{"a.conf", "b.conf"},
+		},
+		{
+			name:  "pattern in a subdirectory",
+			files: []string{"themes/*"},
+			want:  []string{filepath.Join("themes", "dark.toml"), filepath.Join("themes", "light.toml")},
+		},
+		{
+			name:  "question mark and duplicates",
+			files: []string{"a.conf", "?.conf", "x.txt"},
+			want:  []string{"a.conf", "b.conf", "x.txt"},
+		},
+		{
+			name:  "pattern matching nothing",
+			files: []string{"*.lua"},
+			want:  []string{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := mgr.expandFiles(tt.files, dir)
+			if err != nil {
+				t.Fatalf("expandFiles() error = %v", err)
+			}
+
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("expandFiles(%v) = %v, want %v", tt.files, got, tt.want)
+			}
+		})
+	}
+
+	if _, err := mgr.expandFiles([]string{"[*.conf"}, dir); !errors.Is(err, ErrInvalidGlob) {
+		t.Errorf("expandFiles() of a malformed pattern error = %v, want ErrInvalidGlob", err)
+	}
+}
+
+func TestRestoreFiles_Glob(t *testing.T) {
+	skipIfNoSymlink(t)
+
+	backup := t.TempDir()
+	target := t.TempDir()
+	writeGlobFiles(t, backup, "a.conf", "b.conf", "themes/dark.toml", "notes.md")
+
+	mgr := newGlobManager(backup)
+	subEntry := config.SubEntry{Name: "conf", Files: []string{"*.conf", "themes/*"}}
+
+	if err := mgr.RestoreFiles(subEntry, backup, target); err != nil {
+		t.Fatalf("RestoreFiles() error = %v", err)
+	}
+
+	for _, file := range []string{"a.conf", "b.conf", filepath.Join("themes", "dark.toml")} {
+		if !mgr.symlinkPointsTo(filepath.Join(target, file), filepath.Join(backup, file)) {
+			t.Errorf("%s is not linked to its backup", file)
+		}
+	}
+
+	if _, err := os.Lstat(filepath.Join(target, "notes.md")); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("notes.md was deployed although no pattern matches it")
+	}
+
+	subEntry.Files = []string{"[*.conf"}
+	if err := mgr.RestoreFiles(subEntry, backup, target); !errors.Is(err, ErrInvalidGlob) {
+		t.Errorf("RestoreFiles() with a malformed pattern error = %v, want ErrInvalidGlob", err)
+	}
+}
+
+func TestBackupFiles_GlobMatchesNewTargetFiles(t *testing.T) {
+	backup := t.TempDir()
+	target := t.TempDir()
+	writeGlobFiles(t, backup, "a.conf")
+	writeGlobFiles(t, target, "a.conf", "new.conf", "other.txt")
+
+	mgr := newGlobManager(backup)
+	subEntry := config.SubEntry{Name: "conf", Files: []string{"*.conf"}}
+
+	if err := mgr.backupFilesSubEntry("app", subEntry, backup, target); err != nil {
+		t.Fatalf("backupFilesSubEntry() error = %v", err)
+	}
+
+	if data, err := os.ReadFile(filepath.Join(backup, "new.conf")); err != nil || string(data) != "new.conf" {
+		t.Errorf("new.conf in backup = %q, %v; want it copied from the target", data, err)
+	}
+
+	if _, err := os.Stat(filepath.Join(backup, "other.txt")); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("other.txt was backed up although the pattern does not match it")
+	}
+}
+
+func TestUnmatchedPatterns(t *testing.T) {
+	root := t.TempDir()
+	writeGlobFiles(t, root, "kitty/kitty.conf", "kitty/themes/dark.conf")
+
+	mgr := newGlobManager(root)
+	mgr.Config.Applications = []config.Application{{
+		Name: "kitty",
+		Entries: []config.SubEntry{
+			{
+				Name:    "config",
+				Backup:  "./kitty",
+				Files:   []string{"kitty.conf", "*.conf", "themes/*.conf", "*.lua", "[*.conf"},
+				Targets: map[string]string{"linux": "~/.config/kitty"},
+			},
+			{
+				Name:    "windows-only",
+				Backup:  "./kitty",
+				Files:   []string{"*.ini"},
+				Targets: map[string]string{"windows": "~/AppData/kitty"},
+			},
+		},
+	}}
+
+	got := mgr.UnmatchedPatterns()
+	want := []UnmatchedPattern{{App: "kitty", Entry: "config", Pattern: "*.lua"}}
+
+	if !slices.Equal(got, want) {
+		t.Errorf("UnmatchedPatterns() = %v, want %v", got, want)
+	}
+
+	matches, err := mgr.MatchFiles("./kitty", "themes/*")
+	if err != nil {
+		t.Fatalf("MatchFiles() error = %v", err)
+	}
+
+	if want := []string{filepath.Join("themes", "dark.conf")}; !slices.Equal(matches, want) {
+		t.Errorf("MatchFiles() = %v, want %v", matches, want)
+	}
+}
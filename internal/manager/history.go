@@ -0,0 +1,95 @@
+package manager
+
+import (
+	"log/slog"
+	"time"
+
+	"github.com/AntoineGS/tidydots/internal/state"
+)
+
+// HistoryKey identifies an entry in the operation history.
+type HistoryKey struct {
+	App   string
+	Entry string
+}
+
+// EntryHistory holds the last backup and restore of an entry on this machine.
+// A nil field means the operation has never been recorded.
+type EntryHistory struct {
+	Backup  *state.OperationRecord
+	Restore *state.OperationRecord
+}
+
+// WithClock returns a new Manager that reads the current time from now.
+// Used primarily for testing operation timestamps and the stale filter.
+func (m *Manager) WithClock(now func() time.Time) *Manager {
+	m2 := *m
+	m2.now = now
+
+	return &m2
+}
+
+// RecordOperation stores the time and tidydots version of a successful
+// operation on an entry. It does nothing in dry-run mode or without a state
+// store, and a failure to record is logged rather than returned: the
+// operation itself has already succeeded.
+func (m *Manager) RecordOperation(op, appName, entryName string) {
+	if m.DryRun || m.stateStore == nil {
+		return
+	}
+
+	rec := state.OperationRecord{
+		AppName:      appName,
+		EntryName:    entryName,
+		Operation:    op,
+		RanAt:        m.now(),
+		Version:      m.Version,
+		PlatformOS:   m.Platform.OS,
+		PlatformHost: m.Platform.Hostname,
+	}
+
+	if err := m.stateStore.RecordOperation(m.ctx, rec); err != nil {
+		m.logger.Warn("could not record operation",
+			slog.String("app", appName),
+			slog.String("entry", entryName),
+			slog.String("operation", op),
+			slog.String("error", err.Error()))
+	}
+}
+
+// History returns the recorded operations of every entry on this machine.
+// It returns an empty map without a state store.
+func (m *Manager) History() (map[HistoryKey]EntryHistory, error) {
+	history := make(map[HistoryKey]EntryHistory)
+	if m.stateStore == nil {
+		return history, nil
+	}
+
+	records, err := m.stateStore.GetOperations(m.ctx, m.Platform.OS, m.Platform.Hostname)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range records {
+		rec := &records[i]
+		key := HistoryKey{App: rec.AppName, Entry: rec.EntryName}
+		h := history[key]
+
+		switch rec.Operation {
+		case state.OpBackup:
+			h.Backup = rec
+		case state.OpRestore:
+			h.Restore = rec
+		}
+
+		history[key] = h
+	}
+
+	return history, nil
+}
+
+// backedUpWithin reports whether the entry's last recorded backup is more
+// recent than window.
+func backedUpWithin(h EntryHistory, now time.Time, window time.Duration) bool {
+	return h.Backup != nil && now.Sub(h.Backup.RanAt) < window
+}
@@ -0,0 +1,247 @@
+package manager
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/AntoineGS/tidydots/internal/config"
+	"github.com/AntoineGS/tidydots/internal/platform"
+	"github.com/AntoineGS/tidydots/internal/state"
+)
+
+// newHistoryManager returns a Manager with an open state store and two file
+// entries, "shell/zshrc" and "shell/bashrc", whose targets exist on disk.
+func newHistoryManager(t *testing.T, now *time.Time) *Manager {
+	t.Helper()
+
+	tmpDir := t.TempDir()
+	targetDir := filepath.Join(t.TempDir(), "home") // outside the repository, as restore requires
+
+	if err := os.MkdirAll(targetDir, 0750); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, name := range []string{".zshrc", ".bashrc"} {
+		if err := os.WriteFile(filepath.Join(targetDir, name), []byte(name), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	cfg := &config.Config{
+		Version:    3,
+		BackupRoot: tmpDir,
+		Applications: []config.Application{
+			{
+				Name: "shell",
+				Entries: []config.SubEntry{
+					{Name: "zshrc", Backup: "./zsh", Files: []string{".zshrc"}, Targets: map[string]string{"linux": targetDir}},
+					{Name: "bashrc", Backup: "./bash", Files: []string{".bashrc"}, Targets: map[string]string{"linux": targetDir}},
+				},
+			},
+		},
+	}
+	plat := &platform.Platform{OS: platform.OSLinux, Hostname: "host", EnvVars: map[string]string{}}
+
+	m := New(cfg, plat).WithClock(func() time.Time { return *now })
+	m.Version = "1.2.3"
+
+	if err := m.InitStateStore(); err != nil {
+		t.Fatalf("InitStateStore() error = %v", err)
+	}
+	t.Cleanup(func() { _ = m.Close() })
+
+	return m
+}
+
+func TestBackup_RecordsOperation(t *testing.T) {
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	m := newHistoryManager(t, &now)
+
+	if err := m.Backup(); err != nil {
+		t.Fatalf("Backup() error = %v", err)
+	}
+
+	history, err := m.History()
+	if err != nil {
+		t.Fatalf("History() error = %v", err)
+	}
+
+	h := history[HistoryKey{App: "shell", Entry: "zshrc"}]
+	if h.Backup == nil {
+		t.Fatal("zshrc backup was not recorded")
+	}
+
+	if !h.Backup.RanAt.Equal(now) || h.Backup.Version != "1.2.3" {
+		t.Errorf("backup record = %+v, want RanAt %v and version 1.2.3", h.Backup, now)
+	}
+
+	if h.Restore != nil {
+		t.Errorf("restore record = %+v, want none", h.Restore)
+	}
+}
+
+func TestBackup_DryRunRecordsNothing(t *testing.T) {
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	m := newHistoryManager(t, &now)
+	m.DryRun = true
+
+	if err := m.Backup(); err != nil {
+		t.Fatalf("Backup() error = %v", err)
+	}
+
+	history, err := m.History()
+	if err != nil {
+		t.Fatalf("History() error = %v", err)
+	}
+
+	if len(history) != 0 {
+		t.Errorf("dry run recorded %d entries, want none", len(history))
+	}
+}
+
+func TestRestore_RecordsOperation(t *testing.T) {
+	for _, dryRun := range []bool{false, true} {
+		t.Run(fmt.Sprintf("dryRun=%v", dryRun), func(t *testing.T) {
+			now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+			m := newHistoryManager(t, &now)
+
+			if err := m.Backup(); err != nil {
+				t.Fatalf("Backup() error = %v", err)
+			}
+
+			now = now.Add(time.Hour)
+			m.DryRun = dryRun
+
+			if err := m.Restore(); err != nil {
+				t.Fatalf("Restore() error = %v", err)
+			}
+
+			history, err := m.History()
+			if err != nil {
+				t.Fatalf("History() error = %v", err)
+			}
+
+			h := history[HistoryKey{App: "shell", Entry: "zshrc"}]
+
+			switch {
+			case dryRun && h.Restore != nil:
+				t.Errorf("dry run recorded restore %+v, want none", h.Restore)
+			case !dryRun && (h.Restore == nil || !h.Restore.RanAt.Equal(now)):
+				t.Errorf("restore record = %+v, want RanAt %v", h.Restore, now)
+			}
+		})
+	}
+}
+
+func TestBackup_Stale(t *testing.T) {
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	m := newHistoryManager(t, &now)
+
+	// Only zshrc is backed up on day one.
+	m.RecordOperation(state.OpBackup, "shell", "zshrc")
+
+	// Ten days later, with a 30-day window, zshrc is fresh and bashrc has
+	// never been backed up.
+	now = now.Add(10 * 24 * time.Hour)
+	m.Stale = 30 * 24 * time.Hour
+
+	if err := m.Backup(); err != nil {
+		t.Fatalf("Backup() error = %v", err)
+	}
+
+	backupRoot := m.Config.BackupRoot
+	if testPathExists(filepath.Join(backupRoot, "zsh", ".zshrc")) {
+		t.Error("zshrc was backed up within the stale window")
+	}
+
+	if !testPathExists(filepath.Join(backupRoot, "bash", ".bashrc")) {
+		t.Error("bashrc, never backed up, should have been backed up")
+	}
+
+	// Forty days after the first backup zshrc is stale again.
+	now = now.Add(30 * 24 * time.Hour)
+
+	if err := m.Backup(); err != nil {
+		t.Fatalf("Backup() error = %v", err)
+	}
+
+	if !testPathExists(filepath.Join(backupRoot, "zsh", ".zshrc")) {
+		t.Error("zshrc should be backed up once its last backup is outside the window")
+	}
+}
+
+func TestList_VerboseShowsHistory(t *testing.T) {
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.Local)
+	m := newHistoryManager(t, &now)
+
+	m.RecordOperation(state.OpRestore, "shell", "zshrc")
+
+	list := func(verbose bool) string {
+		m.Verbose = verbose
+
+		old := os.Stdout
+		r, w, _ := os.Pipe()
+		os.Stdout = w
+
+		_ = m.List()
+
+		_ = w.Close()
+		os.Stdout = old
+
+		var buf bytes.Buffer
+		_, _ = buf.ReadFrom(r)
+
+		return buf.String()
+	}
+
+	if output := list(false); strings.Contains(output, "last restore") {
+		t.Errorf("List() without verbose shows history:\n%s", output)
+	}
+
+	output := list(true)
+	for _, want := range []string{"last restore: 2026-03-01 12:00 (1.2.3)", "last backup: never"} {
+		if !strings.Contains(output, want) {
+			t.Errorf("List() verbose output missing %q:\n%s", want, output)
+		}
+	}
+}
+
+func TestListJSON_IncludesHistory(t *testing.T) {
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	m := newHistoryManager(t, &now)
+
+	m.RecordOperation(state.OpRestore, "shell", "zshrc")
+
+	var buf bytes.Buffer
+	if err := m.ListJSON(&buf); err != nil {
+		t.Fatalf("ListJSON() error = %v", err)
+	}
+
+	var apps []ListedApplication
+	if err := json.Unmarshal(buf.Bytes(), &apps); err != nil {
+		t.Fatalf("ListJSON() output is not valid JSON: %v\n%s", err, buf.String())
+	}
+
+	if len(apps) != 1 || len(apps[0].Entries) != 2 {
+		t.Fatalf("ListJSON() = %+v, want one app with two entries", apps)
+	}
+
+	zshrc := apps[0].Entries[0]
+	if zshrc.LastRestore == nil || !zshrc.LastRestore.At.Equal(now) || zshrc.LastRestore.Version != "1.2.3" {
+		t.Errorf("zshrc last_restore = %+v, want %v at version 1.2.3", zshrc.LastRestore, now)
+	}
+
+	if zshrc.LastBackup != nil {
+		t.Errorf("zshrc last_backup = %+v, want null", zshrc.LastBackup)
+	}
+
+	if apps[0].Entries[1].LastRestore != nil {
+		t.Errorf("bashrc last_restore = %+v, want null", apps[0].Entries[1].LastRestore)
+	}
+}
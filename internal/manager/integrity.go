@@ -0,0 +1,305 @@
+package manager
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"path/filepath"
+	"strings"
+
+	"github.com/AntoineGS/tidydots/internal/config"
+	tmpl "github.com/AntoineGS/tidydots/internal/template"
+)
+
+// ChecksumSuffix is appended to a backup file's name to form the path of its
+// integrity sidecar. The sidecar uses the sha256sum output format, so
+// `sha256sum -c` can check it from the same directory.
+const ChecksumSuffix = ".sha256"
+
+// IsChecksumFile reports whether path is an integrity sidecar.
+func IsChecksumFile(path string) bool {
+	return strings.HasSuffix(path, ChecksumSuffix)
+}
+
+// IntegrityError reports a backup file whose content no longer matches the
+// hash recorded in its sidecar.
+type IntegrityError struct {
+	Path     string
+	Expected string
+	Actual   string // empty when the backup file is missing
+}
+
+func (e *IntegrityError) Error() string {
+	if e.Actual == "" {
+		return fmt.Sprintf("%s: file is missing, expected sha256 %s", e.Path, e.Expected)
+	}
+
+	return fmt.Sprintf("%s: sha256 mismatch: expected %s, got %s", e.Path, e.Expected, e.Actual)
+}
+
+// hashFile returns the hex-encoded SHA-256 of the file at path.
+func (m *Manager) hashFile(path string) (string, error) {
+	data, err := m.fs.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+
+	sum := sha256.Sum256(data)
+
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// writeChecksum hashes the backup file at path and stores the result in its
+// sidecar.
+func (m *Manager) writeChecksum(path string) error {
+	sum, err := m.hashFile(path)
+	if err != nil {
+		return NewPathError("backup", path, fmt.Errorf("hashing file: %w", err))
+	}
+
+	line := fmt.Sprintf("%s  %s\n", sum, filepath.Base(path))
+	if err := m.fs.WriteFile(path+ChecksumSuffix, []byte(line), FilePerms); err != nil {
+		return NewPathError("backup", path, fmt.Errorf("writing checksum: %w", err))
+	}
+
+	m.logger.Debug("wrote checksum", slog.String("path", path), slog.String("sha256", sum))
+
+	return nil
+}
+
+// folderManifest returns the path of the checksum manifest of the folder
+// backup dir. The manifest sits beside the folder rather than in it, since a
+// folder entry's target is a symlink to the folder and the configured program
+// would find sidecars in its own directory. It uses the sha256sum format with
+// paths relative to dir, so `sha256sum -c` can check it from dir.
+func folderManifest(dir string) string {
+	return filepath.Clean(dir) + ChecksumSuffix
+}
+
+// writeFolderChecksums hashes every file under dir into its manifest (see
+// folderManifest). Checksum files and template artifacts (which are
+// regenerated on restore) are skipped.
+func (m *Manager) writeFolderChecksums(dir string) error {
+	var manifest strings.Builder
+
+	err := m.fs.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if d.IsDir() || !d.Type().IsRegular() || !isHashable(path) {
+			return nil
+		}
+
+		sum, err := m.hashFile(path)
+		if err != nil {
+			return NewPathError("backup", path, fmt.Errorf("hashing file: %w", err))
+		}
+
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+
+		fmt.Fprintf(&manifest, "%s  %s\n", sum, filepath.ToSlash(rel))
+
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	path := folderManifest(dir)
+	if err := m.fs.WriteFile(path, []byte(manifest.String()), FilePerms); err != nil {
+		return NewPathError("backup", path, fmt.Errorf("writing checksum manifest: %w", err))
+	}
+
+	m.logger.Debug("wrote checksum manifest", slog.String("path", path))
+
+	return nil
+}
+
+// isHashable reports whether a backup file gets an integrity checksum.
+func isHashable(path string) bool {
+	return !IsChecksumFile(path) && !tmpl.IsRenderedFile(path) && !tmpl.IsConflictFile(path)
+}
+
+// checksum is the hash recorded for one backup file, by its sidecar or by
+// its folder's manifest. err is set when the record could not be read.
+type checksum struct {
+	err      error
+	path     string
+	expected string
+}
+
+// readSidecar returns the checksum a sidecar records for the file it guards.
+func (m *Manager) readSidecar(sidecar string) checksum {
+	c := checksum{path: strings.TrimSuffix(sidecar, ChecksumSuffix)}
+
+	data, err := m.fs.ReadFile(sidecar)
+	if err != nil {
+		c.err = NewPathError("verify", sidecar, fmt.Errorf("reading checksum: %w", err))
+		return c
+	}
+
+	fields := strings.Fields(string(data))
+	if len(fields) == 0 {
+		c.err = NewPathError("verify", sidecar, errors.New("checksum file is empty"))
+		return c
+	}
+
+	c.expected = strings.ToLower(fields[0])
+
+	return c
+}
+
+// readManifest returns the checksums the manifest of the folder backup dir
+// records.
+func (m *Manager) readManifest(dir string) []checksum {
+	path := folderManifest(dir)
+
+	data, err := m.fs.ReadFile(path)
+	if err != nil {
+		return []checksum{{path: path, err: NewPathError("verify", path, fmt.Errorf("reading checksum manifest: %w", err))}}
+	}
+
+	var sums []checksum
+
+	for line := range strings.Lines(string(data)) {
+		line = strings.TrimRight(line, "\r\n")
+		if line == "" {
+			continue
+		}
+
+		sum, name, ok := strings.Cut(line, "  ")
+		if !ok || name == "" {
+			sums = append(sums, checksum{path: path, err: NewPathError("verify", path, fmt.Errorf("malformed line %q", line))})
+			continue
+		}
+
+		sums = append(sums, checksum{path: filepath.Join(dir, filepath.FromSlash(name)), expected: strings.ToLower(sum)})
+	}
+
+	return sums
+}
+
+// checkChecksum compares a backup file against the hash recorded for it. A
+// mismatch or missing file is reported as an *IntegrityError.
+func (m *Manager) checkChecksum(c checksum) error {
+	if c.err != nil {
+		return c.err
+	}
+
+	if !m.pathExists(c.path) {
+		return &IntegrityError{Path: c.path, Expected: c.expected}
+	}
+
+	actual, err := m.hashFile(c.path)
+	if err != nil {
+		return NewPathError("verify", c.path, fmt.Errorf("hashing file: %w", err))
+	}
+
+	if actual != c.expected {
+		return &IntegrityError{Path: c.path, Expected: c.expected, Actual: actual}
+	}
+
+	return nil
+}
+
+// checksumsFor returns the checksums that guard a config sub-entry's backup:
+// the manifest of a folder entry, or the sidecar of each listed file for
+// file entries.
+func (m *Manager) checksumsFor(subEntry config.SubEntry, backupPath string) ([]checksum, error) {
+	if !m.pathExists(backupPath) {
+		return nil, nil
+	}
+
+	var sums []checksum
+
+	if !subEntry.IsFolder() {
+		files, err := m.expandFiles(subEntry.Files, backupPath)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, file := range files {
+			sidecar := filepath.Join(backupPath, file) + ChecksumSuffix
+			if m.pathExists(sidecar) {
+				sums = append(sums, m.readSidecar(sidecar))
+			}
+		}
+
+		return sums, nil
+	}
+
+	if !m.pathExists(folderManifest(backupPath)) {
+		return nil, nil
+	}
+
+	return m.readManifest(backupPath), nil
+}
+
+// checkEntryIntegrity verifies the checksums of a sub-entry before it is
+// restored. Failures are logged as warnings; with StrictVerify they are also
+// returned so the entry is not deployed.
+func (m *Manager) checkEntryIntegrity(subEntry config.SubEntry, backupPath string) error {
+	sums, err := m.checksumsFor(subEntry, backupPath)
+	if err != nil {
+		return NewPathError("verify", backupPath, fmt.Errorf("listing checksums: %w", err))
+	}
+
+	var errs []error
+
+	for _, sum := range sums {
+		if err := m.checkChecksum(sum); err != nil {
+			m.logger.Warn("backup failed integrity check",
+				slog.String("entry", subEntry.Name),
+				slog.String("error", err.Error()))
+			errs = append(errs, err)
+		}
+	}
+
+	if m.StrictVerify && len(errs) > 0 {
+		return NewPathError("verify", backupPath, errors.Join(errs...))
+	}
+
+	return nil
+}
+
+// VerifyIntegrity checks every checksum sidecar and folder manifest belonging
+// to the config entries of the current platform. It returns the number of
+// files checked and one error per file that failed.
+func (m *Manager) VerifyIntegrity() (int, []error) {
+	checked := 0
+
+	var failures []error
+
+	for _, app := range m.GetApplications() {
+		for _, subEntry := range app.Entries {
+			if !subEntry.IsConfig() {
+				continue
+			}
+
+			backupPath := m.resolvePath(subEntry.Backup)
+
+			sums, err := m.checksumsFor(subEntry, backupPath)
+			if err != nil {
+				failures = append(failures, NewPathError("verify", backupPath, fmt.Errorf("listing checksums: %w", err)))
+				continue
+			}
+
+			for _, sum := range sums {
+				checked++
+
+				if err := m.checkChecksum(sum); err != nil {
+					failures = append(failures, err)
+				}
+			}
+		}
+	}
+
+	return checked, failures
+}
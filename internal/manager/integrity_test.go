@@ -0,0 +1,223 @@
+package manager
+
+import (
+	"errors"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/AntoineGS/tidydots/internal/config"
+	"github.com/AntoineGS/tidydots/internal/platform"
+)
+
+// newIntegrityManager backs up a verified files entry holding .bashrc and
+// returns a Manager over it together with the home and backup directories.
+func newIntegrityManager(t *testing.T) (mgr *Manager, homeDir, backupDir string) {
+	t.Helper()
+	tmpDir := t.TempDir()
+
+	homeDir = filepath.Join(tmpDir, "home")
+	if err := os.MkdirAll(homeDir, 0750); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(homeDir, ".bashrc"), []byte("bash config"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	backupRoot := filepath.Join(tmpDir, "backup")
+	cfg := &config.Config{
+		Version:    3,
+		BackupRoot: backupRoot,
+		Applications: []config.Application{{
+			Name: "bash",
+			Entries: []config.SubEntry{{
+				Name:    "config",
+				Files:   []string{".bashrc"},
+				Backup:  "./bash",
+				Verify:  true,
+				Targets: map[string]string{platform.OSLinux: homeDir},
+			}},
+		}},
+	}
+
+	mgr = New(cfg, &platform.Platform{OS: platform.OSLinux}).
+		WithLogger(slog.New(slog.NewTextHandler(io.Discard, nil)))
+
+	if err := mgr.Backup(); err != nil {
+		t.Fatalf("Backup() error = %v", err)
+	}
+
+	return mgr, homeDir, filepath.Join(backupRoot, "bash")
+}
+
+func TestBackup_VerifyWritesChecksum(t *testing.T) {
+	t.Parallel()
+	mgr, _, backupDir := newIntegrityManager(t)
+
+	data, err := os.ReadFile(filepath.Join(backupDir, ".bashrc"+ChecksumSuffix)) //nolint:gosec // test file
+	if err != nil {
+		t.Fatalf("checksum sidecar not written: %v", err)
+	}
+
+	// sha256("bash config")
+	want := "854905a637c4ceffce05e770057770a345f70da131ba6d1a2125a9063ab43da8  .bashrc\n"
+	if string(data) != want {
+		t.Errorf("sidecar = %q, want %q", data, want)
+	}
+
+	checked, failures := mgr.VerifyIntegrity()
+	if checked != 1 || len(failures) != 0 {
+		t.Errorf("VerifyIntegrity() = %d, %v; want 1 checked, no failures", checked, failures)
+	}
+}
+
+func TestRestore_DetectsCorruptedBackup(t *testing.T) {
+	skipIfNoSymlink(t)
+	t.Parallel()
+
+	tests := []struct {
+		name        string
+		strict      bool
+		wantErr     bool
+		wantSymlink bool
+	}{
+		{name: "warns and restores by default", strict: false, wantErr: false, wantSymlink: true},
+		{name: "fails with strict verify", strict: true, wantErr: true, wantSymlink: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			mgr, homeDir, backupDir := newIntegrityManager(t)
+
+			// Simulate silent corruption of the backed-up file.
+			if err := os.WriteFile(filepath.Join(backupDir, ".bashrc"), []byte("bash c0nfig"), 0600); err != nil {
+				t.Fatal(err)
+			}
+			if err := os.Remove(filepath.Join(homeDir, ".bashrc")); err != nil {
+				t.Fatal(err)
+			}
+
+			mgr.StrictVerify = tt.strict
+			err := mgr.Restore()
+
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Restore() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			var integrityErr *IntegrityError
+			if tt.wantErr && !errors.As(err, &integrityErr) {
+				t.Errorf("Restore() error = %v, want *IntegrityError", err)
+			}
+
+			if got := testIsSymlink(filepath.Join(homeDir, ".bashrc")); got != tt.wantSymlink {
+				t.Errorf("symlink created = %v, want %v", got, tt.wantSymlink)
+			}
+
+			checked, failures := mgr.VerifyIntegrity()
+			if checked != 1 || len(failures) != 1 {
+				t.Errorf("VerifyIntegrity() = %d, %v; want 1 checked, 1 failure", checked, failures)
+			}
+		})
+	}
+}
+
+func TestVerifyIntegrity_MissingBackupFile(t *testing.T) {
+	t.Parallel()
+	mgr, _, backupDir := newIntegrityManager(t)
+
+	if err := os.Remove(filepath.Join(backupDir, ".bashrc")); err != nil {
+		t.Fatal(err)
+	}
+
+	_, failures := mgr.VerifyIntegrity()
+
+	var integrityErr *IntegrityError
+	if len(failures) != 1 || !errors.As(failures[0], &integrityErr) || integrityErr.Actual != "" {
+		t.Errorf("VerifyIntegrity() failures = %v, want one missing-file IntegrityError", failures)
+	}
+}
+
+func TestBackup_VerifyFolderWritesManifest(t *testing.T) {
+	t.Parallel()
+	tmpDir := t.TempDir()
+
+	nvimDir := filepath.Join(tmpDir, "home", ".config", "nvim")
+	if err := os.MkdirAll(filepath.Join(nvimDir, "lua"), 0750); err != nil {
+		t.Fatal(err)
+	}
+	for name, content := range map[string]string{
+		"init.lua":             "require('x')",
+		"lua/x.lua":            "return {}",
+		"x.toml.tmpl.rendered": "rendered",
+	} {
+		if err := os.WriteFile(filepath.Join(nvimDir, name), []byte(content), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	backupRoot := filepath.Join(tmpDir, "backup")
+	cfg := &config.Config{
+		Version:    3,
+		BackupRoot: backupRoot,
+		Applications: []config.Application{{
+			Name: "nvim",
+			Entries: []config.SubEntry{{
+				Name:    "config",
+				Backup:  "./nvim",
+				Verify:  true,
+				Targets: map[string]string{platform.OSLinux: nvimDir},
+			}},
+		}},
+	}
+
+	mgr := New(cfg, &platform.Platform{OS: platform.OSLinux}).
+		WithLogger(slog.New(slog.NewTextHandler(io.Discard, nil)))
+
+	// Backing up twice must not hash the manifest written the first time.
+	for range 2 {
+		if err := mgr.Backup(); err != nil {
+			t.Fatalf("Backup() error = %v", err)
+		}
+	}
+
+	backupDir := filepath.Join(backupRoot, "nvim")
+
+	manifest, err := os.ReadFile(backupDir + ChecksumSuffix)
+	if err != nil {
+		t.Fatalf("reading the manifest beside the backup: %v", err)
+	}
+
+	var names []string
+	for line := range strings.Lines(string(manifest)) {
+		_, name, _ := strings.Cut(strings.TrimSpace(line), "  ")
+		names = append(names, name)
+	}
+
+	if got := strings.Join(names, ","); got != "init.lua,lua/x.lua" {
+		t.Errorf("manifest lists %q, want init.lua and lua/x.lua", got)
+	}
+
+	if testPathExists(filepath.Join(backupDir, "init.lua"+ChecksumSuffix)) {
+		t.Error("a sidecar was written inside the folder backup")
+	}
+
+	checked, failures := mgr.VerifyIntegrity()
+	if checked != 2 || len(failures) != 0 {
+		t.Errorf("VerifyIntegrity() = %d, %v; want 2 checked, no failures", checked, failures)
+	}
+
+	if err := os.WriteFile(filepath.Join(backupDir, "lua", "x.lua"), []byte("corrupted"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	_, failures = mgr.VerifyIntegrity()
+
+	var integrityErr *IntegrityError
+	if len(failures) != 1 || !errors.As(failures[0], &integrityErr) || integrityErr.Path != filepath.Join(backupDir, "lua", "x.lua") {
+		t.Errorf("VerifyIntegrity() failures = %v, want lua/x.lua mismatching", failures)
+	}
+}
@@ -2,18 +2,21 @@ package manager
 
 import (
 	"context"
+	"io"
 )
 
 // Restorer defines the interface for restore operations
 type Restorer interface {
 	Restore() error
 	RestoreWithContext(ctx context.Context) error
+	RestoreReport(ctx context.Context) (*Report, error)
 }
 
 // Backuper defines the interface for backup operations
 type Backuper interface {
 	Backup() error
 	BackupWithContext(ctx context.Context) error
+	BackupReport(ctx context.Context) (*Report, error)
 }
 
 // Adopter defines the interface for adopt operations
@@ -25,6 +28,7 @@ type Adopter interface {
 // Lister defines the interface for listing operations
 type Lister interface {
 	List() error
+	ListJSON(w io.Writer) error
 }
 
 // DotfileManager combines all manager operations
@@ -0,0 +1,145 @@
+package manager
+
+import (
+	"fmt"
+	"log/slog"
+
+	"github.com/AntoineGS/tidydots/internal/state"
+)
+
+// skipReasonResumed is the Detail of an entry a resumed run skips.
+const skipReasonResumed = "completed by the interrupted run"
+
+// runJournal is the journal of the restore or backup run a Manager is in:
+// the entries it completed are added to the state store as they complete, so
+// that a run interrupted by cancellation or a crash can be resumed.
+type runJournal struct {
+	done map[HistoryKey]bool // entries the resumed run completed
+	op   string
+}
+
+// InterruptedEntries returns the entries the last run of op on this machine
+// completed, when that run did not finish without failures: it was
+// interrupted, or some entries failed. It returns nil without a state store.
+func (m *Manager) InterruptedEntries(op string) ([]HistoryKey, error) {
+	if m.stateStore == nil {
+		return nil, nil
+	}
+
+	records, err := m.stateStore.GetJournal(m.ctx, op, m.Platform.OS, m.Platform.Hostname)
+	if err != nil {
+		return nil, fmt.Errorf("reading %s journal: %w", op, err)
+	}
+
+	keys := make([]HistoryKey, len(records))
+	for i, rec := range records {
+		keys[i] = HistoryKey{App: rec.AppName, Entry: rec.EntryName}
+	}
+
+	return keys, nil
+}
+
+// BeginJournal returns a Manager that journals the entries it restores or
+// backs up in a run of op. With Resume, the run skips the entries the
+// interrupted run of op completed, as long as their target (restore) or
+// backup (backup) is still there; otherwise it starts a new journal. Call
+// EndJournal on the returned Manager when the run is over. Dry runs read the
+// journal but never write it.
+func (m *Manager) BeginJournal(op string) *Manager {
+	m2 := *m
+	m2.journal = &runJournal{op: op}
+
+	if m.stateStore == nil {
+		return &m2
+	}
+
+	if m.Resume {
+		keys, err := m.InterruptedEntries(op)
+		if err != nil {
+			m.logger.Warn("could not read journal, not resuming", slog.String("error", err.Error()))
+		}
+
+		m2.journal.done = make(map[HistoryKey]bool, len(keys))
+		for _, key := range keys {
+			m2.journal.done[key] = true
+		}
+
+		return &m2
+	}
+
+	m.clearJournal(op)
+
+	return &m2
+}
+
+// EndJournal ends the run BeginJournal began. The journal is cleared when the
+// run completed without failures; otherwise it is kept for a later run to
+// resume.
+func (m *Manager) EndJournal(completed bool) {
+	if m.journal == nil || !completed {
+		return
+	}
+
+	m.clearJournal(m.journal.op)
+}
+
+// clearJournal deletes the journal of op, logging a failure.
+func (m *Manager) clearJournal(op string) {
+	if m.DryRun || m.stateStore == nil {
+		return
+	}
+
+	if err := m.stateStore.ClearJournal(m.ctx, op, m.Platform.OS, m.Platform.Hostname); err != nil {
+		m.logger.Warn("could not clear journal", slog.String("operation", op), slog.String("error", err.Error()))
+	}
+}
+
+// journalEntry records that the run completed an entry. Outside a run, in
+// dry-run mode or without a state store it does nothing, and a failure is
+// logged: the entry itself has already succeeded.
+func (m *Manager) journalEntry(appName, entryName string) {
+	if m.journal == nil || m.DryRun || m.stateStore == nil {
+		return
+	}
+
+	rec := state.OperationRecord{
+		AppName:      appName,
+		EntryName:    entryName,
+		Operation:    m.journal.op,
+		RanAt:        m.now(),
+		PlatformOS:   m.Platform.OS,
+		PlatformHost: m.Platform.Hostname,
+	}
+
+	if err := m.stateStore.RecordJournal(m.ctx, rec); err != nil {
+		m.logger.Warn("could not journal entry",
+			slog.String("app", appName),
+			slog.String("entry", entryName),
+			slog.String("error", err.Error()))
+	}
+}
+
+// resumed reports whether the run skips an entry because the run it resumes
+// completed it, checking first that path, the entry's target or backup, still
+// exists rather than trusting the journal blindly. The skipped entry stays in
+// the journal.
+func (m *Manager) resumed(appName, entryName, path string) bool {
+	if m.journal == nil || !m.journal.done[HistoryKey{App: appName, Entry: entryName}] {
+		return false
+	}
+
+	if !m.pathExists(path) {
+		m.logger.Info("resuming: redoing entry whose result is gone",
+			slog.String("app", appName),
+			slog.String("entry", entryName),
+			slog.String("path", path))
+
+		return false
+	}
+
+	m.logger.Info("skipped: completed by the interrupted run",
+		slog.String("app", appName),
+		slog.String("entry", entryName))
+
+	return true
+}
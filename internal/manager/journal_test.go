@@ -0,0 +1,214 @@
+package manager
+
+import (
+	"context"
+	"io/fs"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/AntoineGS/tidydots/internal/config"
+	"github.com/AntoineGS/tidydots/internal/fsys"
+	"github.com/AntoineGS/tidydots/internal/platform"
+	"github.com/AntoineGS/tidydots/internal/state"
+)
+
+// cancelFS cancels a run the first time it looks at a path under trigger.
+type cancelFS struct {
+	fsys.OsFS
+	trigger string
+	cancel  context.CancelFunc
+}
+
+func (c *cancelFS) Lstat(name string) (fs.FileInfo, error) {
+	if strings.HasPrefix(name, c.trigger) {
+		c.cancel()
+	}
+
+	return c.OsFS.Lstat(name)
+}
+
+// newJournalManager returns a Manager with an open state store and one
+// application, "shell", whose entries "a" to "d" each hold one file. The
+// targets exist in the returned home directory.
+func newJournalManager(t *testing.T) (*Manager, string) {
+	t.Helper()
+
+	home := filepath.Join(t.TempDir(), "home")
+
+	var entries []config.SubEntry
+
+	for _, name := range []string{"a", "b", "c", "d"} {
+		target := filepath.Join(home, name)
+		if err := os.MkdirAll(target, 0o750); err != nil {
+			t.Fatal(err)
+		}
+
+		if err := os.WriteFile(filepath.Join(target, "rc"), []byte(name), 0o600); err != nil {
+			t.Fatal(err)
+		}
+
+		entries = append(entries, config.SubEntry{
+			Name: name, Backup: "./" + name, Files: []string{"rc"},
+			Targets: map[string]string{platform.OSLinux: target},
+		})
+	}
+
+	cfg := &config.Config{
+		Version:      3,
+		BackupRoot:   t.TempDir(),
+		Applications: []config.Application{{Name: "shell", Entries: entries}},
+	}
+	plat := &platform.Platform{OS: platform.OSLinux, Hostname: "host", EnvVars: map[string]string{}}
+
+	m := New(cfg, plat).WithClock(func() time.Time { return time.Date(2026, 10, 17, 9, 0, 0, 0, time.UTC) })
+	if err := m.InitStateStore(); err != nil {
+		t.Fatalf("InitStateStore() error = %v", err)
+	}
+	t.Cleanup(func() { _ = m.Close() })
+
+	return m, home
+}
+
+// journaled returns the entries of the journal of op as app/entry names.
+func journaled(t *testing.T, m *Manager, op string) []string {
+	t.Helper()
+
+	keys, err := m.InterruptedEntries(op)
+	if err != nil {
+		t.Fatalf("InterruptedEntries() error = %v", err)
+	}
+
+	names := make([]string, len(keys))
+	for i, k := range keys {
+		names[i] = k.App + "/" + k.Entry
+	}
+
+	slices.Sort(names)
+
+	return names
+}
+
+// actions returns the action of every entry of report by entry name.
+func actions(report *Report) map[string]EntryAction {
+	got := make(map[string]EntryAction, len(report.Entries))
+	for _, e := range report.Entries {
+		got[e.Entry] = e.Action
+	}
+
+	return got
+}
+
+func TestBackup_ResumeAfterCancel(t *testing.T) {
+	m, home := newJournalManager(t)
+
+	// Cancel while backing up c: a and b are done, c is cut short.
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	interrupted := m.WithFS(&cancelFS{trigger: filepath.Join(home, "c"), cancel: cancel})
+	if _, err := interrupted.BackupReport(ctx); err == nil {
+		t.Fatal("BackupReport() error = nil, want the cancellation")
+	}
+
+	if got, want := journaled(t, m, state.OpBackup), []string{"shell/a", "shell/b"}; !slices.Equal(got, want) {
+		t.Fatalf("journal after cancel = %v, want %v", got, want)
+	}
+
+	// Without --resume, a run starts over; with it, a and b are skipped.
+	m.Resume = true
+
+	report, err := m.BackupReport(context.Background())
+	if err != nil {
+		t.Fatalf("resumed BackupReport() error = %v", err)
+	}
+
+	want := map[string]EntryAction{"a": ActionSkipped, "b": ActionSkipped, "c": ActionBackedUp, "d": ActionBackedUp}
+	if got := actions(report); !maps.Equal(got, want) {
+		t.Errorf("resumed run = %v, want %v", got, want)
+	}
+
+	if got := journaled(t, m, state.OpBackup); len(got) != 0 {
+		t.Errorf("journal after a full run = %v, want it cleared", got)
+	}
+}
+
+func TestRestore_ResumeRechecksCompletedEntries(t *testing.T) {
+	skipIfNoSymlink(t)
+
+	m, home := newJournalManager(t)
+	if err := m.Backup(); err != nil {
+		t.Fatalf("Backup() error = %v", err)
+	}
+
+	for _, name := range []string{"a", "b", "c", "d"} {
+		if err := os.RemoveAll(filepath.Join(home, name)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	interrupted := m.WithFS(&cancelFS{trigger: filepath.Join(home, "c"), cancel: cancel})
+	if _, err := interrupted.RestoreReport(ctx); err == nil {
+		t.Fatal("RestoreReport() error = nil, want the cancellation")
+	}
+
+	if got, want := journaled(t, m, state.OpRestore), []string{"shell/a", "shell/b"}; !slices.Equal(got, want) {
+		t.Fatalf("journal after cancel = %v, want %v", got, want)
+	}
+
+	// b's restored target is gone since: the journal is not trusted for it.
+	if err := os.RemoveAll(filepath.Join(home, "b")); err != nil {
+		t.Fatal(err)
+	}
+
+	m.Resume = true
+
+	report, err := m.RestoreReport(context.Background())
+	if err != nil {
+		t.Fatalf("resumed RestoreReport() error = %v", err)
+	}
+
+	want := map[string]EntryAction{"a": ActionSkipped, "b": ActionRestored, "c": ActionRestored, "d": ActionRestored}
+	if got := actions(report); !maps.Equal(got, want) {
+		t.Errorf("resumed run = %v, want %v", got, want)
+	}
+
+	if !testPathExists(filepath.Join(home, "b", "rc")) {
+		t.Error("b was not restored again")
+	}
+}
+
+func TestRun_FailureKeepsJournal(t *testing.T) {
+	m, _ := newJournalManager(t)
+
+	// A file is where d's backup folder goes, so the run fails on it.
+	if err := os.WriteFile(filepath.Join(m.Config.BackupRoot, "d"), []byte("d"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := m.Backup(); err == nil {
+		t.Fatal("Backup() error = nil, want d to fail")
+	}
+
+	if got, want := journaled(t, m, state.OpBackup), []string{"shell/a", "shell/b", "shell/c"}; !slices.Equal(got, want) {
+		t.Errorf("journal after a failed run = %v, want %v", got, want)
+	}
+
+	// A run without Resume starts a new journal.
+	m.Config.Applications[0].Entries = m.Config.Applications[0].Entries[:1]
+
+	if err := m.Backup(); err != nil {
+		t.Fatalf("Backup() error = %v", err)
+	}
+
+	if got := journaled(t, m, state.OpBackup); len(got) != 0 {
+		t.Errorf("journal after a new full run = %v, want it cleared", got)
+	}
+}
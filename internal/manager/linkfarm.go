@@ -0,0 +1,198 @@
+package manager
+
+import (
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"path/filepath"
+	"slices"
+
+	"github.com/AntoineGS/tidydots/internal/config"
+	"github.com/AntoineGS/tidydots/internal/fsys"
+	tmpl "github.com/AntoineGS/tidydots/internal/template"
+)
+
+// LinkFarmFiles returns the files a link_mode: files entry links from
+// backupPath, as paths relative to it in lexical order: every file and
+// symlink of the backup except integrity sidecars and template sources and
+// artifacts. A template is linked through the link restore renders beside
+// it, so it counts once rendered.
+func LinkFarmFiles(f fsys.FS, backupPath string) ([]string, error) {
+	var files []string
+
+	err := f.WalkDir(backupPath, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		name := d.Name()
+		if d.IsDir() || IsChecksumFile(name) ||
+			tmpl.IsTemplateFile(name) || tmpl.IsRenderedFile(name) || tmpl.IsConflictFile(name) {
+			return nil
+		}
+
+		rel, err := filepath.Rel(backupPath, path)
+		if err != nil {
+			return err
+		}
+
+		files = append(files, rel)
+
+		return nil
+	})
+
+	return files, err
+}
+
+// RestoreLinkFarm restores a link_mode: files entry: target stays a real
+// directory, and every file of source (see LinkFarmFiles) is linked into it
+// the way RestoreFiles links the files of a files entry, creating the
+// directories between. Other files in target are left alone. Links a
+// previous restore made to files since removed from source are deleted, and
+// the linked files are recorded in the state store for backup to sync.
+func (m *Manager) RestoreLinkFarm(appName string, subEntry config.SubEntry, source, target string) error {
+	if !m.pathExists(source) {
+		if m.DryRun {
+			m.logger.Info("source folder does not exist (dry-run, skipping)", slog.String("path", source))
+			return nil
+		}
+
+		return NewPathError("restore", source, fmt.Errorf("source folder does not exist"))
+	}
+
+	if m.hasTemplateFiles(source) {
+		m2, err := m.withTemplateEncoding(subEntry.Encoding)
+		if err != nil {
+			return NewPathError("restore", source, err)
+		}
+
+		if err := m2.renderTemplatesInBackup(source); err != nil {
+			return err
+		}
+	}
+
+	// An entry switched from link_mode: dir is still a directory link, in
+	// which the file links would be written into the backup itself.
+	if m.isSymlink(target) {
+		m.logger.Info("removing directory symlink", slog.String("path", target))
+
+		if m.DryRun {
+			m.step(StepLink, source, target, ConflictRelink)
+			return nil
+		}
+
+		if err := m.fs.Remove(target); err != nil {
+			return NewPathError("restore", target, fmt.Errorf("removing directory symlink: %w", err))
+		}
+	}
+
+	files, err := LinkFarmFiles(m.fs, source)
+	if err != nil {
+		return NewPathError("restore", source, fmt.Errorf("listing files: %w", err))
+	}
+
+	if err := m.pruneLinkFarm(appName, subEntry.Name, source, target, files); err != nil {
+		return err
+	}
+
+	farm := subEntry
+	farm.Files = files
+	farm.CaseRename = nil
+
+	if err := m.RestoreFiles(farm, source, target); err != nil {
+		return err
+	}
+
+	m.recordLinkedFiles(appName, subEntry.Name, files)
+
+	return nil
+}
+
+// pruneLinkFarm deletes the links of the entry's last recorded restore whose
+// backup file is no longer among files. Only links still pointing to their
+// backup file are deleted; anything else at their path is left alone.
+func (m *Manager) pruneLinkFarm(appName, entryName, source, target string, files []string) error {
+	for _, file := range m.linkedFiles(appName, entryName) {
+		if slices.Contains(files, file) {
+			continue
+		}
+
+		link := filepath.Join(target, file)
+		if !m.symlinkPointsTo(link, filepath.Join(source, file)) {
+			continue
+		}
+
+		m.logger.Info("removing stale link", slog.String("path", link))
+
+		if m.DryRun {
+			continue
+		}
+
+		if err := m.fs.Remove(link); err != nil {
+			return NewPathError("restore", link, fmt.Errorf("removing stale link: %w", err))
+		}
+	}
+
+	return nil
+}
+
+// backupLinkFarm backs up a link_mode: files entry: only the files its last
+// restore linked, which backupFilesSubEntry copies back when a program
+// replaced their link with a real file. Without a state store, the files of
+// the backup are used instead.
+func (m *Manager) backupLinkFarm(appName string, subEntry config.SubEntry, backup, target string) error {
+	files := m.linkedFiles(appName, subEntry.Name)
+
+	if m.stateStore == nil && m.pathExists(backup) {
+		var err error
+		if files, err = LinkFarmFiles(m.fs, backup); err != nil {
+			return NewPathError("backup", backup, fmt.Errorf("listing files: %w", err))
+		}
+	}
+
+	if len(files) == 0 {
+		m.logger.Debug("no linked files to back up", slog.String("path", target))
+		return nil
+	}
+
+	farm := subEntry
+	farm.Files = files
+	farm.CaseRename = nil
+
+	return m.backupFilesSubEntry(appName, farm, backup, target)
+}
+
+// linkedFiles returns the files recorded for a link_mode: files entry by its
+// last restore on this machine, or nil without a state store. A failure to
+// read them is logged, as if none were recorded.
+func (m *Manager) linkedFiles(appName, entryName string) []string {
+	if m.stateStore == nil {
+		return nil
+	}
+
+	files, err := m.stateStore.GetLinkedFiles(m.ctx, appName, entryName, m.Platform.OS, m.Platform.Hostname)
+	if err != nil {
+		m.logger.Warn("could not read linked files",
+			slog.String("app", appName),
+			slog.String("entry", entryName),
+			slog.String("error", err.Error()))
+	}
+
+	return files
+}
+
+// recordLinkedFiles records files as the links of a link_mode: files entry.
+// It does nothing in dry-run mode or without a state store, and a failure is
+// logged: the links themselves are in place.
+func (m *Manager) recordLinkedFiles(appName, entryName string, files []string) {
+	if m.DryRun || m.stateStore == nil {
+		return
+	}
+
+	if err := m.stateStore.SetLinkedFiles(m.ctx, appName, entryName, m.Platform.OS, m.Platform.Hostname, files); err != nil {
+		m.logger.Warn("could not record linked files",
+			slog.String("app", appName),
+			slog.String("entry", entryName),
+			slog.String("error", err.Error()))
+	}
+}
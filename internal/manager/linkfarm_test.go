@@ -0,0 +1,265 @@
+package manager
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/AntoineGS/tidydots/internal/config"
+	"github.com/AntoineGS/tidydots/internal/platform"
+	"github.com/AntoineGS/tidydots/internal/state"
+)
+
+// newLinkFarm returns a manager with a state store and a link_mode: files
+// entry whose backup holds a.desktop and sub/b.desktop, targeting a real
+// directory that already holds a file of another program, other.desktop.
+func newLinkFarm(t *testing.T) (mgr *Manager, entry config.SubEntry, backup, target string) {
+	t.Helper()
+	skipIfNoSymlink(t)
+
+	root := t.TempDir()
+	backup = filepath.Join(root, "apps")
+	target = filepath.Join(t.TempDir(), "applications")
+
+	writeFarmFile(t, filepath.Join(backup, "a.desktop"), "a")
+	writeFarmFile(t, filepath.Join(backup, "sub", "b.desktop"), "b")
+	writeFarmFile(t, filepath.Join(target, "other.desktop"), "other")
+
+	entry = config.SubEntry{
+		Name:     "apps",
+		Backup:   "./apps",
+		LinkMode: config.LinkModeFiles,
+		Targets:  map[string]string{"linux": target},
+	}
+
+	cfg := &config.Config{
+		Version:      3,
+		BackupRoot:   root,
+		Applications: []config.Application{{Name: "desktop", Entries: []config.SubEntry{entry}}},
+	}
+
+	mgr = New(cfg, &platform.Platform{OS: platform.OSLinux, Hostname: "host", EnvVars: map[string]string{}})
+
+	store, err := state.Open(context.Background(), filepath.Join(root, ".tidydots.db"))
+	if err != nil {
+		t.Fatalf("opening state store: %v", err)
+	}
+
+	mgr.stateStore = store
+	t.Cleanup(func() { _ = store.Close() }) //nolint:errcheck // cleanup is best-effort
+
+	return mgr, entry, backup, target
+}
+
+func writeFarmFile(t *testing.T, path, content string) {
+	t.Helper()
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o750); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// assertFarmLink fails unless path is a symlink to want.
+func assertFarmLink(t *testing.T, path, want string) {
+	t.Helper()
+
+	got, err := os.Readlink(path)
+	if err != nil {
+		t.Fatalf("%s is not a symlink: %v", path, err)
+	}
+
+	if got != want {
+		t.Errorf("%s links to %s, want %s", path, got, want)
+	}
+}
+
+func TestRestoreLinkFarm(t *testing.T) {
+	mgr, entry, backup, target := newLinkFarm(t)
+
+	if result := mgr.RestoreEntry("desktop", entry, target); result.Err != nil {
+		t.Fatalf("RestoreEntry() error = %v", result.Err)
+	}
+
+	if testIsSymlink(target) {
+		t.Fatal("target directory was replaced by a symlink")
+	}
+
+	assertFarmLink(t, filepath.Join(target, "a.desktop"), filepath.Join(backup, "a.desktop"))
+	assertFarmLink(t, filepath.Join(target, "sub", "b.desktop"), filepath.Join(backup, "sub", "b.desktop"))
+
+	if content, err := os.ReadFile(filepath.Join(target, "other.desktop")); err != nil || string(content) != "other" {
+		t.Errorf("unmanaged file = %q, %v; want it untouched", content, err)
+	}
+
+	if _, err := os.Stat(filepath.Join(backup, "other.desktop")); !os.IsNotExist(err) {
+		t.Errorf("unmanaged file was adopted into the backup: %v", err)
+	}
+
+	files := mgr.linkedFiles("desktop", "apps")
+	if strings.Join(files, ",") != "a.desktop,"+filepath.Join("sub", "b.desktop") {
+		t.Errorf("recorded links = %v, want a.desktop and sub/b.desktop", files)
+	}
+}
+
+func TestRestoreLinkFarm_FilesAddedAndRemoved(t *testing.T) {
+	mgr, entry, backup, target := newLinkFarm(t)
+
+	if result := mgr.RestoreEntry("desktop", entry, target); result.Err != nil {
+		t.Fatalf("RestoreEntry() error = %v", result.Err)
+	}
+
+	// A file added to the backup since the restore is not linked yet.
+	writeFarmFile(t, filepath.Join(backup, "c.desktop"), "c")
+
+	result := mgr.VerifyEntryLinks("desktop", entry, target, false)
+	if len(result.Drifted) != 1 || result.Drifted[0].Path != filepath.Join(target, "c.desktop") || result.Drifted[0].State != LinkMissing {
+		t.Fatalf("VerifyEntryLinks() drift = %v, want only c.desktop missing", result.Drifted)
+	}
+
+	// A file removed from the backup leaves a stale link behind.
+	if err := os.Remove(filepath.Join(backup, "a.desktop")); err != nil {
+		t.Fatal(err)
+	}
+
+	if result := mgr.RestoreEntry("desktop", entry, target); result.Err != nil {
+		t.Fatalf("second RestoreEntry() error = %v", result.Err)
+	}
+
+	assertFarmLink(t, filepath.Join(target, "c.desktop"), filepath.Join(backup, "c.desktop"))
+
+	if _, err := os.Lstat(filepath.Join(target, "a.desktop")); !os.IsNotExist(err) {
+		t.Errorf("stale link to a removed backup file was kept: %v", err)
+	}
+
+	if files := mgr.linkedFiles("desktop", "apps"); len(files) != 2 || files[0] != "c.desktop" {
+		t.Errorf("recorded links = %v, want c.desktop and sub/b.desktop", files)
+	}
+
+	if result := mgr.VerifyEntryLinks("desktop", entry, target, false); len(result.Drifted) != 0 {
+		t.Errorf("VerifyEntryLinks() drift after restore = %v, want none", result.Drifted)
+	}
+}
+
+func TestRestoreLinkFarm_ReplacesDirectoryLink(t *testing.T) {
+	mgr, entry, backup, target := newLinkFarm(t)
+
+	// The entry was restored in link_mode: dir before.
+	if err := os.RemoveAll(target); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := os.Symlink(backup, target); err != nil {
+		t.Fatal(err)
+	}
+
+	if result := mgr.RestoreEntry("desktop", entry, target); result.Err != nil {
+		t.Fatalf("RestoreEntry() error = %v", result.Err)
+	}
+
+	if testIsSymlink(target) {
+		t.Fatal("target is still a directory link")
+	}
+
+	assertFarmLink(t, filepath.Join(target, "a.desktop"), filepath.Join(backup, "a.desktop"))
+
+	if testIsSymlink(filepath.Join(backup, "a.desktop")) {
+		t.Error("a link was written into the backup")
+	}
+}
+
+func TestBackupLinkFarm_SyncsTrackedFilesOnly(t *testing.T) {
+	mgr, entry, backup, target := newLinkFarm(t)
+
+	if result := mgr.RestoreEntry("desktop", entry, target); result.Err != nil {
+		t.Fatalf("RestoreEntry() error = %v", result.Err)
+	}
+
+	// A program replaced one link with a real file and added another file.
+	link := filepath.Join(target, "a.desktop")
+	if err := os.Remove(link); err != nil {
+		t.Fatal(err)
+	}
+
+	writeFarmFile(t, link, "edited")
+	writeFarmFile(t, filepath.Join(target, "new.desktop"), "new")
+
+	if err := mgr.backupSubEntry("desktop", entry, target); err != nil {
+		t.Fatalf("backupSubEntry() error = %v", err)
+	}
+
+	if content, _ := os.ReadFile(filepath.Join(backup, "a.desktop")); string(content) != "edited" { //nolint:gosec
+		t.Errorf("tracked file in backup = %q, want the edited copy", content)
+	}
+
+	for _, name := range []string{"other.desktop", "new.desktop"} {
+		if _, err := os.Stat(filepath.Join(backup, name)); !os.IsNotExist(err) {
+			t.Errorf("untracked %s was backed up: %v", name, err)
+		}
+	}
+}
+
+func TestFindStaleBackups_LinkFarm(t *testing.T) {
+	mgr, entry, backup, target := newLinkFarm(t)
+
+	if result := mgr.RestoreEntry("desktop", entry, target); result.Err != nil {
+		t.Fatalf("RestoreEntry() error = %v", result.Err)
+	}
+
+	// A file added to the backup since the restore has no link yet, and the
+	// link of sub/b.desktop was deleted from the target.
+	writeFarmFile(t, filepath.Join(backup, "c.desktop"), "c")
+
+	if err := os.Remove(filepath.Join(target, "sub", "b.desktop")); err != nil {
+		t.Fatal(err)
+	}
+
+	stale, err := mgr.FindStaleBackups()
+	if err != nil {
+		t.Fatalf("FindStaleBackups() error = %v", err)
+	}
+
+	if len(stale) != 1 || stale[0].Path != filepath.Join(backup, "sub", "b.desktop") {
+		t.Errorf("FindStaleBackups() = %+v, want only sub/b.desktop", stale)
+	}
+}
+
+func TestLinkFarm_ModifiedAndConflicts(t *testing.T) {
+	mgr, entry, backup, target := newLinkFarm(t)
+
+	if result := mgr.RestoreEntry("desktop", entry, target); result.Err != nil {
+		t.Fatalf("RestoreEntry() error = %v", result.Err)
+	}
+
+	// The real target directory and its unmanaged files are neither modified
+	// nor conflicts.
+	if modified := mgr.ModifiedTargets(); len(modified) != 0 {
+		t.Errorf("ModifiedTargets() = %+v, want none after restore", modified)
+	}
+
+	if conflicts := mgr.TargetConflicts(entry, target); len(conflicts) != 0 {
+		t.Errorf("TargetConflicts() = %+v, want none after restore", conflicts)
+	}
+
+	// A program replaced the link of a.desktop with a real file.
+	replaced := filepath.Join(target, "a.desktop")
+	if err := os.Remove(replaced); err != nil {
+		t.Fatal(err)
+	}
+
+	writeFarmFile(t, replaced, "edited")
+
+	if modified := mgr.ModifiedTargets(); len(modified) != 1 || modified[0].Path != replaced {
+		t.Errorf("ModifiedTargets() = %+v, want only a.desktop", modified)
+	}
+
+	conflicts := mgr.TargetConflicts(entry, target)
+	if len(conflicts) != 1 || conflicts[0].Target != replaced || conflicts[0].Backup != filepath.Join(backup, "a.desktop") || conflicts[0].IsDir {
+		t.Errorf("TargetConflicts() = %+v, want only the file a.desktop", conflicts)
+	}
+}
@@ -0,0 +1,281 @@
+package manager
+
+import (
+	"fmt"
+	"log/slog"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	"github.com/AntoineGS/tidydots/internal/config"
+	"github.com/AntoineGS/tidydots/internal/platform"
+)
+
+// QuarantineSuffix precedes the timestamp appended to whatever VerifyLinks
+// moves out of the way before relinking, e.g. init.lua.tidydots-20260204-153000.
+const QuarantineSuffix = ".tidydots-"
+
+// LinkState describes a deployed link compared to the backup path it should
+// point to.
+type LinkState int
+
+// Link states reported by VerifyLinks.
+const (
+	// LinkOK is a symlink to the expected backup path
+	LinkOK LinkState = iota
+	// LinkMissing means nothing is at the link's path
+	LinkMissing
+	// LinkElsewhere is a symlink to some other path
+	LinkElsewhere
+	// LinkReplaced is a regular file or directory where the symlink should be
+	LinkReplaced
+)
+
+// String returns the human-readable name of a LinkState.
+func (s LinkState) String() string {
+	switch s {
+	case LinkOK:
+		return "ok"
+	case LinkMissing:
+		return "missing"
+	case LinkElsewhere:
+		return "points elsewhere"
+	case LinkReplaced:
+		return "replaced by a regular file"
+	}
+
+	return "unknown"
+}
+
+// LinkCheck is one link of an entry that is not LinkOK.
+type LinkCheck struct {
+	Path     string // where the link should be
+	Expected string // backup path it should point to
+	Actual   string // where it points; set for LinkElsewhere
+	State    LinkState
+}
+
+func (c LinkCheck) String() string {
+	switch c.State {
+	case LinkElsewhere:
+		return fmt.Sprintf("%s points to %s, expected %s", c.Path, c.Actual, c.Expected)
+	case LinkReplaced:
+		return fmt.Sprintf("%s was replaced by a regular file or directory", c.Path)
+	default:
+		return fmt.Sprintf("%s is %s", c.Path, c.State)
+	}
+}
+
+// LinkResult is the outcome of verifying the links of one entry.
+type LinkResult struct {
+	App   string
+	Entry string
+	// Drifted lists the links that were not LinkOK when checked.
+	Drifted []LinkCheck
+	// Repaired is set when every drifted link was re-established.
+	Repaired bool
+	// Quarantined lists where replaced files and directories were moved.
+	Quarantined []string
+	// Err is set when checking or repairing failed.
+	Err error
+}
+
+// OK reports whether the entry's links are as expected, or were repaired.
+func (r LinkResult) OK() bool {
+	return r.Err == nil && (len(r.Drifted) == 0 || r.Repaired)
+}
+
+// Message describes the result in one line, the way the TUI's restore
+// results do.
+func (r LinkResult) Message() string {
+	drift := make([]string, 0, len(r.Drifted))
+	for _, c := range r.Drifted {
+		drift = append(drift, c.String())
+	}
+
+	switch {
+	case r.Err != nil:
+		return fmt.Sprintf("Failed: %v", r.Err)
+	case len(r.Drifted) == 0:
+		return "Links intact"
+	case !r.Repaired:
+		return "Drifted: " + strings.Join(drift, "; ")
+	case len(r.Quarantined) > 0:
+		return fmt.Sprintf("Repaired: %s (moved aside to %s)", strings.Join(drift, "; "), strings.Join(r.Quarantined, ", "))
+	default:
+		return "Repaired: " + strings.Join(drift, "; ")
+	}
+}
+
+// VerifyLinks checks every symlinked config entry of the current platform and
+// returns one result per entry. With repair, drifted links are re-established
+// the way restore creates them, after moving a file or directory that took a
+// link's place aside (see QuarantineSuffix). Copy-method entries are not
+// links and are skipped, as are sudo entries when NoSudo is set.
+func (m *Manager) VerifyLinks(repair bool) []LinkResult {
+	var results []LinkResult
+
+	for _, app := range m.applicationsByPriority() {
+		for _, subEntry := range app.Entries {
+			if !subEntry.IsConfig() || subEntry.IsCopy() || m.SkipsSudo(subEntry) {
+				continue
+			}
+
+			target := subEntry.GetTarget(m.Platform.OS)
+			if target == "" {
+				continue
+			}
+
+			results = append(results, m.VerifyEntryLinks(app.Name, subEntry, m.expandTarget(target), repair))
+		}
+	}
+
+	return results
+}
+
+// VerifyEntryLinks checks the links of one config entry deployed at target,
+// an expanded target path, and repairs them when repair is set. See
+// VerifyLinks.
+func (m *Manager) VerifyEntryLinks(appName string, subEntry config.SubEntry, target string, repair bool) LinkResult {
+	result := LinkResult{App: appName, Entry: subEntry.Name}
+	backupPath := m.resolvePath(subEntry.Backup)
+
+	switch {
+	case subEntry.LinksFiles():
+		files, err := LinkFarmFiles(m.fs, backupPath)
+		if err != nil {
+			result.Err = err
+			return result
+		}
+
+		for _, file := range files {
+			if c := m.checkLink(filepath.Join(target, file), filepath.Join(backupPath, file)); c.State != LinkOK {
+				result.Drifted = append(result.Drifted, c)
+			}
+		}
+	case subEntry.IsFolder():
+		if c := m.checkLink(target, backupPath); c.State != LinkOK {
+			result.Drifted = append(result.Drifted, c)
+		}
+	default:
+		files, err := m.entryFiles(subEntry, backupPath)
+		if err != nil {
+			result.Err = err
+			return result
+		}
+
+		for _, file := range files {
+			if c := m.checkLink(filepath.Join(target, file), filepath.Join(backupPath, subEntry.BackupName(file))); c.State != LinkOK {
+				result.Drifted = append(result.Drifted, c)
+			}
+		}
+	}
+
+	if !repair || len(result.Drifted) == 0 {
+		return result
+	}
+
+	for _, c := range result.Drifted {
+		moved, err := m.repairLink(c, subEntry.IsFolder() && !subEntry.LinksFiles(), subEntry.Sudo)
+		if moved != "" && !m.DryRun {
+			result.Quarantined = append(result.Quarantined, moved)
+		}
+
+		if err != nil {
+			result.Err = err
+			return result
+		}
+	}
+
+	// A dry run only logs the repairs, so the links are still drifted.
+	result.Repaired = !m.DryRun
+
+	return result
+}
+
+// checkLink compares the link at path with the backup path it should point to.
+func (m *Manager) checkLink(path, expected string) LinkCheck {
+	c := LinkCheck{Path: path, Expected: expected}
+
+	switch {
+	case m.symlinkPointsTo(path, expected):
+		c.State = LinkOK
+	case m.isSymlink(path):
+		c.State = LinkElsewhere
+		c.Actual, _ = m.fs.Readlink(path) //nolint:errcheck // an unreadable link still points elsewhere
+	case m.pathExists(path):
+		c.State = LinkReplaced
+	default:
+		c.State = LinkMissing
+	}
+
+	return c
+}
+
+// repairLink re-establishes the link c describes and returns where whatever
+// was at its path was moved, if anything. A wrong symlink is removed, as
+// restore does; a file or directory is moved aside so nothing is lost.
+func (m *Manager) repairLink(c LinkCheck, folder, useSudo bool) (string, error) {
+	if err := m.checkLinkSource(c.Expected); err != nil {
+		return "", err
+	}
+
+	var moved string
+
+	switch c.State {
+	case LinkElsewhere:
+		m.logger.Info("removing incorrect symlink", slog.String("path", c.Path))
+
+		if !m.DryRun {
+			if err := m.fs.Remove(c.Path); err != nil {
+				return "", NewPathError("repair", c.Path, fmt.Errorf("removing incorrect symlink: %w", err))
+			}
+		}
+	case LinkReplaced:
+		moved = c.Path + QuarantineSuffix + m.now().Format("20060102-150405")
+		m.logger.Info("moving aside", slog.String("from", c.Path), slog.String("to", moved))
+
+		if !m.DryRun {
+			if err := m.quarantine(c.Path, moved, useSudo); err != nil {
+				return "", NewPathError("repair", c.Path, fmt.Errorf("moving aside: %w", err))
+			}
+		}
+	case LinkMissing:
+		if parent := filepath.Dir(c.Path); !m.pathExists(parent) && !m.DryRun {
+			if err := m.fs.MkdirAll(parent, DirPerms); err != nil {
+				return "", NewPathError("repair", parent, fmt.Errorf("creating parent: %w", err))
+			}
+		}
+	case LinkOK:
+		return "", nil
+	}
+
+	m.logger.Info("creating symlink", slog.String("target", c.Path), slog.String("source", c.Expected))
+
+	if m.DryRun {
+		return moved, nil
+	}
+
+	var err error
+	if folder {
+		err = m.createFolderLink(c.Expected, c.Path, useSudo)
+	} else {
+		err = m.createSymlink(c.Expected, c.Path, useSudo)
+	}
+
+	if err != nil {
+		return moved, NewPathError("repair", c.Path, fmt.Errorf("creating symlink: %w", err))
+	}
+
+	return moved, nil
+}
+
+// quarantine renames path to dest, with sudo when the entry requires it.
+func (m *Manager) quarantine(path, dest string, useSudo bool) error {
+	if useSudo && runtime.GOOS != platform.OSWindows {
+		_, err := m.runner.RunWithSudo(m.ctx, "mv", path, dest)
+		return err
+	}
+
+	return m.fs.Rename(path, dest)
+}
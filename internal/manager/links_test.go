@@ -0,0 +1,169 @@
+package manager
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/AntoineGS/tidydots/internal/config"
+	"github.com/AntoineGS/tidydots/internal/platform"
+)
+
+// newLinkManager returns a Linux manager whose backup root holds an "nvim"
+// folder and a "zsh" folder with .zshrc and .zshenv, and the home directory
+// its entries are deployed under. Nothing is linked yet.
+func newLinkManager(t *testing.T) (mgr *Manager, root, home string) {
+	t.Helper()
+
+	if runtime.GOOS == platform.OSWindows {
+		t.Skip("symlinks need Developer Mode on Windows")
+	}
+
+	root = t.TempDir()
+	home = t.TempDir()
+
+	for _, path := range []string{"nvim/init.lua", "zsh/.zshrc", "zsh/.zshenv"} {
+		full := filepath.Join(root, path)
+		if err := os.MkdirAll(filepath.Dir(full), 0o755); err != nil {
+			t.Fatal(err)
+		}
+
+		if err := os.WriteFile(full, []byte(path), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	cfg := &config.Config{
+		Version:    3,
+		BackupRoot: root,
+		Applications: []config.Application{
+			{Name: "neovim", Entries: []config.SubEntry{
+				{Name: "config", Backup: "./nvim", Targets: map[string]string{"linux": filepath.Join(home, "nvim")}},
+			}},
+			{Name: "zsh", Entries: []config.SubEntry{
+				{Name: "rc", Backup: "./zsh", Files: []string{".zshrc", ".zshenv"}, Targets: map[string]string{"linux": home}},
+				{Name: "copied", Backup: "./zsh", Method: config.MethodCopy, Files: []string{".zshrc"}, Targets: map[string]string{"linux": filepath.Join(home, "copy")}},
+			}},
+		},
+	}
+
+	mgr = New(cfg, &platform.Platform{OS: platform.OSLinux, EnvVars: map[string]string{}}).
+		WithClock(func() time.Time { return time.Date(2026, 2, 4, 15, 30, 0, 0, time.UTC) })
+
+	return mgr, root, home
+}
+
+func resultsByEntry(results []LinkResult) map[string]LinkResult {
+	byEntry := make(map[string]LinkResult, len(results))
+	for _, r := range results {
+		byEntry[r.App+"/"+r.Entry] = r
+	}
+
+	return byEntry
+}
+
+func TestVerifyLinks_ReportsDrift(t *testing.T) {
+	mgr, root, home := newLinkManager(t)
+
+	if err := os.Symlink(filepath.Join(root, "nvim"), filepath.Join(home, "nvim")); err != nil {
+		t.Fatal(err)
+	}
+
+	// .zshrc was replaced by a regular file, .zshenv points elsewhere.
+	if err := os.WriteFile(filepath.Join(home, ".zshrc"), []byte("installer"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := os.Symlink("/elsewhere", filepath.Join(home, ".zshenv")); err != nil {
+		t.Fatal(err)
+	}
+
+	results := resultsByEntry(mgr.VerifyLinks(false))
+
+	if len(results) != 2 {
+		t.Fatalf("VerifyLinks() checked %d entries, want 2 (copy entries are not links): %v", len(results), results)
+	}
+
+	if r := results["neovim/config"]; !r.OK() || len(r.Drifted) != 0 {
+		t.Errorf("neovim/config = %+v, want intact", r)
+	}
+
+	rc := results["zsh/rc"]
+	if rc.OK() || len(rc.Drifted) != 2 {
+		t.Fatalf("zsh/rc = %+v, want two drifted links", rc)
+	}
+
+	if rc.Drifted[0].State != LinkReplaced || rc.Drifted[1].State != LinkElsewhere || rc.Drifted[1].Actual != "/elsewhere" {
+		t.Errorf("zsh/rc drift = %+v, want replaced .zshrc and .zshenv pointing to /elsewhere", rc.Drifted)
+	}
+
+	if data, err := os.ReadFile(filepath.Join(home, ".zshrc")); err != nil || string(data) != "installer" { //nolint:gosec // test path
+		t.Errorf("VerifyLinks(false) changed .zshrc: %q, %v", data, err)
+	}
+}
+
+func TestVerifyLinks_Repair(t *testing.T) {
+	mgr, root, home := newLinkManager(t)
+
+	// The nvim folder was replaced by a real directory; the zsh links are missing.
+	if err := os.MkdirAll(filepath.Join(home, "nvim"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := os.WriteFile(filepath.Join(home, "nvim", "init.lua"), []byte("local"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	results := resultsByEntry(mgr.VerifyLinks(true))
+
+	for name, r := range results {
+		if !r.OK() {
+			t.Errorf("%s = %+v, want repaired", name, r)
+		}
+	}
+
+	quarantined := filepath.Join(home, "nvim") + QuarantineSuffix + "20260204-153000"
+	if got := results["neovim/config"].Quarantined; len(got) != 1 || got[0] != quarantined {
+		t.Errorf("neovim/config quarantined %v, want [%s]", got, quarantined)
+	}
+
+	if data, err := os.ReadFile(filepath.Join(quarantined, "init.lua")); err != nil || string(data) != "local" { //nolint:gosec // test path
+		t.Errorf("quarantined init.lua = %q, %v; want the local copy kept", data, err)
+	}
+
+	for link, want := range map[string]string{
+		filepath.Join(home, "nvim"):    filepath.Join(root, "nvim"),
+		filepath.Join(home, ".zshrc"):  filepath.Join(root, "zsh", ".zshrc"),
+		filepath.Join(home, ".zshenv"): filepath.Join(root, "zsh", ".zshenv"),
+	} {
+		if got, err := os.Readlink(link); err != nil || got != want {
+			t.Errorf("Readlink(%s) = %q, %v; want %q", link, got, err, want)
+		}
+	}
+
+	if !strings.HasPrefix(results["neovim/config"].Message(), "Repaired: ") {
+		t.Errorf("Message() = %q, want a Repaired message", results["neovim/config"].Message())
+	}
+
+	if again := mgr.VerifyLinks(false); len(again) != 2 || len(again[0].Drifted)+len(again[1].Drifted) != 0 {
+		t.Errorf("links still drifted after repair: %+v", again)
+	}
+}
+
+func TestVerifyLinks_RepairDryRun(t *testing.T) {
+	mgr, _, home := newLinkManager(t)
+	mgr.DryRun = true
+
+	for _, r := range mgr.VerifyLinks(true) {
+		if r.OK() || r.Repaired {
+			t.Errorf("%s/%s = %+v, want still drifted in a dry run", r.App, r.Entry, r)
+		}
+	}
+
+	if entries, err := os.ReadDir(home); err != nil || len(entries) != 0 {
+		t.Errorf("dry-run repair changed the home directory: %v, %v", entries, err)
+	}
+}
@@ -1,18 +1,76 @@
 package manager
 
 import (
+	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
+	"log/slog"
 	"strings"
+	"time"
+
+	"github.com/AntoineGS/tidydots/internal/config"
+	"github.com/AntoineGS/tidydots/internal/state"
 )
 
+// ListedApplication is an application in the JSON output of list.
+type ListedApplication struct {
+	Name        string        `json:"name"`
+	Description string        `json:"description,omitempty"`
+	Disabled    bool          `json:"disabled"`
+	Entries     []ListedEntry `json:"entries"`
+}
+
+// ListedEntry is a config entry in the JSON output of list.
+type ListedEntry struct {
+	LastBackup  *ListedOperation `json:"last_backup"`
+	LastRestore *ListedOperation `json:"last_restore"`
+	Name        string           `json:"name"`
+	Backup      string           `json:"backup"`
+	Target      string           `json:"target"`
+	Files       []string         `json:"files,omitempty"`
+	Folder      bool             `json:"folder"`
+	Disabled    bool             `json:"disabled"`
+	Dirty       bool             `json:"dirty"`
+	// CaseCollision is set when two files of the entry would have backup
+	// names differing only by case; see ErrCaseCollision.
+	CaseCollision bool `json:"case_collision"`
+}
+
+// ListedOperation is the last recorded backup or restore of an entry on this
+// machine. It is null in the JSON output when the operation never ran.
+type ListedOperation struct {
+	At      time.Time `json:"at"`
+	Version string    `json:"version"`
+}
+
 // List displays all managed configuration entries with their current status.
+// Disabled applications and entries are left out unless ShowDisabled is set.
+// In verbose mode each entry also shows its last backup and restore on this
+// machine.
 func (m *Manager) List() error {
 	fmt.Printf("Configuration paths for OS: %s\n\n", m.Platform.OS)
 
-	apps := m.GetApplications()
+	apps := m.listedApplications()
+
+	dirty, err := m.DirtyBackupFiles()
+	if err != nil {
+		m.logger.Warn("could not check backup repo for uncommitted changes", slog.String("error", err.Error()))
+	}
+
+	var history map[HistoryKey]EntryHistory
+	if m.Verbose {
+		if history, err = m.History(); err != nil {
+			m.logger.Warn("could not read operation history", slog.String("error", err.Error()))
+		}
+	}
 
 	for _, app := range apps {
-		fmt.Printf("Application: %s\n", app.Name)
+		if app.IsEnabled() {
+			fmt.Printf("Application: %s\n", app.Name)
+		} else {
+			fmt.Printf("Application: %s [disabled]\n", app.Name)
+		}
 
 		if app.Description != "" {
 			fmt.Printf("  %s\n", app.Description)
@@ -28,18 +86,45 @@ func (m *Manager) List() error {
 				continue
 			}
 
-			fmt.Printf("├─ %s [config]\n", entry.Name)
+			backupPath := m.resolvePath(entry.Backup)
+
+			tags := "[config]"
+			if !entry.IsEnabled() {
+				tags += " [disabled]"
+			} else if EntryIsDirty(entry, backupPath, dirty) {
+				tags += " [dirty]"
+			}
+
+			collision := m.caseCollisionRisk(entry, target)
+			if collision != nil {
+				tags += " [case collision]"
+			}
+
+			fmt.Printf("├─ %s %s\n", entry.Name, tags)
 
 			var files string
-			if entry.IsFolder() {
+			switch {
+			case entry.LinksFiles():
+				files = "[folder, linked file by file]"
+			case entry.IsFolder():
 				files = "[folder]"
-			} else {
+			default:
 				files = strings.Join(entry.Files, ", ")
 			}
 
 			fmt.Printf("     files: %s\n", files)
-			fmt.Printf("     backup: %s\n", m.resolvePath(entry.Backup))
+			fmt.Printf("     backup: %s\n", backupPath)
 			fmt.Printf("     target: %s\n", target)
+
+			if collision != nil {
+				fmt.Printf("     warning: %v\n", collision)
+			}
+
+			if m.Verbose {
+				h := history[HistoryKey{App: app.Name, Entry: entry.Name}]
+				fmt.Printf("     last backup: %s\n", formatListedOperation(h.Backup))
+				fmt.Printf("     last restore: %s\n", formatListedOperation(h.Restore))
+			}
 		}
 
 		if app.HasPackage() {
@@ -51,3 +136,110 @@ func (m *Manager) List() error {
 
 	return nil
 }
+
+// ListJSON writes the entries List would display as a JSON array, with the
+// last backup and restore of each entry on this machine.
+func (m *Manager) ListJSON(w io.Writer) error {
+	dirty, err := m.DirtyBackupFiles()
+	if err != nil {
+		m.logger.Warn("could not check backup repo for uncommitted changes", slog.String("error", err.Error()))
+	}
+
+	history, err := m.History()
+	if err != nil {
+		m.logger.Warn("could not read operation history", slog.String("error", err.Error()))
+	}
+
+	listed := []ListedApplication{}
+
+	for _, app := range m.listedApplications() {
+		la := ListedApplication{
+			Name:        app.Name,
+			Description: app.Description,
+			Disabled:    !app.IsEnabled(),
+			Entries:     []ListedEntry{},
+		}
+
+		for _, entry := range app.Entries {
+			if !entry.IsConfig() {
+				continue
+			}
+
+			target := entry.GetTarget(m.Platform.OS)
+			if target == "" {
+				continue
+			}
+
+			backupPath := m.resolvePath(entry.Backup)
+			h := history[HistoryKey{App: app.Name, Entry: entry.Name}]
+
+			la.Entries = append(la.Entries, ListedEntry{
+				Name:        entry.Name,
+				Backup:      backupPath,
+				Target:      target,
+				Files:       entry.Files,
+				Folder:      entry.IsFolder(),
+				Disabled:    !entry.IsEnabled(),
+				Dirty:       entry.IsEnabled() && EntryIsDirty(entry, backupPath, dirty),
+				LastBackup:  listedOperation(h.Backup),
+				LastRestore: listedOperation(h.Restore),
+
+				CaseCollision: m.caseCollisionRisk(entry, target) != nil,
+			})
+		}
+
+		listed = append(listed, la)
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+
+	return enc.Encode(listed)
+}
+
+// caseCollisionRisk returns the error backup would fail entry with because
+// two of its files, deployed at target, collide by case, or nil.
+func (m *Manager) caseCollisionRisk(entry config.SubEntry, target string) error {
+	err := m.checkCaseCollision(entry, m.expandTarget(target))
+	if !errors.Is(err, ErrCaseCollision) {
+		return nil
+	}
+
+	return err
+}
+
+// listedApplications returns the applications List displays: those matching
+// this machine, without disabled ones unless ShowDisabled is set.
+func (m *Manager) listedApplications() []config.Application {
+	if m.ShowDisabled {
+		return m.Config.GetMatchingApplicationsWithLogger(m.templateEngine, m.logger)
+	}
+
+	return m.GetApplications()
+}
+
+// listTimeLayout is how List shows the time of an operation, in local time.
+const listTimeLayout = "2006-01-02 15:04"
+
+// formatListedOperation describes a recorded operation for List: its time and
+// the tidydots version that ran it, or "never".
+func formatListedOperation(rec *state.OperationRecord) string {
+	if rec == nil {
+		return "never"
+	}
+
+	s := rec.RanAt.Local().Format(listTimeLayout)
+	if rec.Version != "" {
+		s += " (" + rec.Version + ")"
+	}
+
+	return s
+}
+
+func listedOperation(rec *state.OperationRecord) *ListedOperation {
+	if rec == nil {
+		return nil
+	}
+
+	return &ListedOperation{At: rec.RanAt, Version: rec.Version}
+}
@@ -224,3 +224,61 @@ func TestList_SkipsGitEntries(t *testing.T) {
 		t.Error("Did not expect git-entry in output")
 	}
 }
+
+func TestList_Disabled(t *testing.T) {
+	disabled := false
+	cfg := &config.Config{
+		Version:    3,
+		BackupRoot: t.TempDir(),
+		Applications: []config.Application{
+			{
+				Name: "shell",
+				Entries: []config.SubEntry{
+					{Name: "zshrc", Backup: "./zsh", Targets: map[string]string{"linux": "/home/user/.zsh"}},
+					{Name: "old-bashrc", Backup: "./bash", Targets: map[string]string{"linux": "/home/user/.bash"}, Enabled: &disabled},
+				},
+			},
+			{
+				Name:    "game",
+				Enabled: &disabled,
+				Entries: []config.SubEntry{
+					{Name: "saves", Backup: "./game", Targets: map[string]string{"linux": "/home/user/.game"}},
+				},
+			},
+		},
+	}
+
+	list := func(showDisabled bool) string {
+		mgr := New(cfg, &platform.Platform{OS: platform.OSLinux})
+		mgr.ShowDisabled = showDisabled
+
+		old := os.Stdout
+		r, w, _ := os.Pipe()
+		os.Stdout = w
+
+		_ = mgr.List()
+
+		_ = w.Close()
+		os.Stdout = old
+
+		var buf bytes.Buffer
+		_, _ = buf.ReadFrom(r)
+
+		return buf.String()
+	}
+
+	output := list(false)
+	if !strings.Contains(output, "zshrc") {
+		t.Error("Expected zshrc in output")
+	}
+	if strings.Contains(output, "old-bashrc") || strings.Contains(output, "game") {
+		t.Errorf("Did not expect disabled items in output:\n%s", output)
+	}
+
+	output = list(true)
+	for _, want := range []string{"Application: game [disabled]", "├─ old-bashrc [config] [disabled]", "├─ zshrc [config]\n"} {
+		if !strings.Contains(output, want) {
+			t.Errorf("Expected %q in output:\n%s", want, output)
+		}
+	}
+}
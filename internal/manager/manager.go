@@ -2,15 +2,20 @@ package manager
 
 import (
 	"bytes"
+	"cmp"
 	"context"
 	"crypto/sha256"
 	"errors"
 	"fmt"
 	"io/fs"
 	"log/slog"
+	"maps"
 	"os"
 	"path/filepath"
 	"runtime"
+	"slices"
+	"sync"
+	"time"
 
 	"github.com/AntoineGS/tidydots/internal/cmdexec"
 	"github.com/AntoineGS/tidydots/internal/config"
@@ -42,11 +47,34 @@ type Manager struct {
 	stateStore     *state.Store
 	fs             fsys.FS
 	runner         cmdexec.Runner
+	now            func() time.Time
+	appEnv         []string       // KEY=VALUE env_vars of the application forApp scoped to
+	steps          *[]Step        // steps of the entry being run; see withSteps
+	skipped        *[]SkippedFile // files the entry being backed up left out
+	journal        *runJournal    // journal of the run in progress; see BeginJournal
+	setupMu        *sync.Mutex    // serializes commands of concurrent restores; see restoreConcurrently
+	Version        string         // tidydots version recorded with each operation
+	Stale          time.Duration  // back up only entries not backed up within this window
+	MaxHistory     int            // template renders kept per template; zero keeps all
+	SymlinkCompat  string         // overrides Config.SymlinkCompat when set
+	Jobs           int            // applications restore works on at once; below 2, one at a time
 	DryRun         bool
 	Verbose        bool
 	NoMerge        bool
 	ForceDelete    bool
 	ForceRender    bool
+	StrictVerify   bool // fail restore of an entry whose backup fails its integrity check
+	ShowDisabled   bool // include disabled applications and entries in List
+	NoSudo         bool // skip entries marked sudo: true instead of running sudo
+	Offline        bool // skip setup entries, whose commands may need the network
+	SkipSetup      bool // skip setup entries, whose commands are for another OS (restore --target-os)
+	ExactSelect    bool // SetApplicationFilter matches whole application names only
+	Resume         bool // skip entries an interrupted run of the same operation completed
+
+	// SkipSymlinks leaves symlinked files in targets out of backups; they
+	// are usually links restore made to the backup. Without it, backup copies
+	// the content they point to. New sets it.
+	SkipSymlinks bool
 }
 
 // New creates a new Manager instance with the given configuration and platform information.
@@ -59,7 +87,7 @@ func New(cfg *config.Config, plat *platform.Platform) *Manager {
 	handler := slog.NewTextHandler(os.Stdout, opts)
 
 	// Create template engine
-	tmplCtx := tmpl.NewContextFromPlatform(plat)
+	tmplCtx := tmpl.NewContextFromPlatform(plat).WithConfig(cfg)
 	engine := tmpl.NewEngine(tmplCtx)
 
 	return &Manager{
@@ -68,11 +96,43 @@ func New(cfg *config.Config, plat *platform.Platform) *Manager {
 		ctx:            context.Background(), // Default context
 		logger:         slog.New(handler),
 		templateEngine: engine,
-		fs:             fsys.OsFS{},
+		fs:             platform.WithLongPaths(fsys.OsFS{}),
 		runner:         cmdexec.OsRunner{},
+		now:            time.Now,
+		MaxHistory:     config.DefaultMaxHistoryEntries,
+		SkipSymlinks:   true,
 	}
 }
 
+// forApp returns a Manager scoped to the application named appName: its
+// templates see the application's env_vars in .Env, over the platform's, and
+// its setup commands run with them. It returns m when the application
+// declares none.
+func (m *Manager) forApp(appName string) *Manager {
+	var env map[string]string
+
+	for i := range m.Config.Applications {
+		if m.Config.Applications[i].Name == appName {
+			env = m.Config.Applications[i].ExpandedEnvVars()
+			break
+		}
+	}
+
+	if len(env) == 0 {
+		return m
+	}
+
+	m2 := *m
+	m2.templateEngine = m.templateEngine.WithEnv(env)
+	m2.appEnv = make([]string, 0, len(env))
+
+	for _, k := range slices.Sorted(maps.Keys(env)) {
+		m2.appEnv = append(m2.appEnv, k+"="+env[k])
+	}
+
+	return &m2
+}
+
 // WithFS returns a new Manager with the given filesystem implementation.
 // Used primarily for testing with an in-memory filesystem.
 func (m *Manager) WithFS(f fsys.FS) *Manager {
@@ -89,7 +149,8 @@ func (m *Manager) WithRunner(r cmdexec.Runner) *Manager {
 	return &m2
 }
 
-// InitStateStore initializes the SQLite state store for template render history.
+// InitStateStore initializes the SQLite state store for template render and
+// operation history.
 // The database is placed in the backup root directory.
 func (m *Manager) InitStateStore() error {
 	backupRoot := config.ExpandPath(m.Config.BackupRoot, m.Platform.EnvVars)
@@ -156,26 +217,51 @@ func (m *Manager) checkContext() error {
 	}
 }
 
+// SkipsSudo reports whether subEntry must be skipped because it is marked
+// sudo: true and NoSudo is set.
+func (m *Manager) SkipsSudo(subEntry config.SubEntry) bool {
+	return m.NoSudo && subEntry.Sudo
+}
+
 // GetApplications returns all filtered applications from the configuration.
 func (m *Manager) GetApplications() []config.Application {
 	return m.Config.GetFilteredApplicationsWithLogger(m.templateEngine, m.logger)
 }
 
+// applicationsByPriority returns GetApplications in the order restore and
+// backup process them: highest priority first, config order among equal
+// priorities.
+func (m *Manager) applicationsByPriority() []config.Application {
+	apps := m.GetApplications()
+	slices.SortStableFunc(apps, func(a, b config.Application) int {
+		return cmp.Compare(b.Priority, a.Priority)
+	})
+
+	return apps
+}
+
 // resolvePath expands templates, ~ and environment variables in paths and resolves
 // relative paths against BackupRoot. This ensures paths work correctly even when
 // stored with ~ in config.
 func (m *Manager) resolvePath(path string) string {
-	// Expand templates, ~ and env vars in the path
-	expandedPath := config.ExpandPathWithTemplate(path, m.Platform.EnvVars, m.templateEngine)
+	return config.ResolveBackupPath(path, m.Config.BackupRoot, m.Platform.EnvVars, m.templateEngine)
+}
 
-	// If it's already absolute after expansion, return it
-	if filepath.IsAbs(expandedPath) {
-		return expandedPath
+// checkPathTemplates renders the templated backup and target paths of
+// subEntry on this OS, returning an error naming the path that fails to
+// render. Restore and backup call it first, since resolvePath and
+// expandTarget fall back to the unrendered path.
+func (m *Manager) checkPathTemplates(subEntry config.SubEntry) error {
+	for _, p := range []struct{ field, path string }{
+		{"backup", subEntry.Backup},
+		{"target", subEntry.GetTarget(m.Platform.OS)},
+	} {
+		if _, err := config.RenderPathTemplate(p.path, m.templateEngine); err != nil {
+			return fmt.Errorf("rendering %s path %q: %w", p.field, p.path, err)
+		}
 	}
 
-	// Otherwise, resolve relative to BackupRoot (also expand BackupRoot)
-	expandedBackupRoot := config.ExpandPathWithTemplate(m.Config.BackupRoot, m.Platform.EnvVars, m.templateEngine)
-	return filepath.Join(expandedBackupRoot, expandedPath)
+	return nil
 }
 
 // expandTarget expands templates, ~ and environment variables in a target path.
@@ -316,6 +402,12 @@ func (m *Manager) copyFile(src, dst string) error {
 // copyDir recursively copies a directory tree from src to dst using the
 // Manager's filesystem abstraction.
 func (m *Manager) copyDir(src, dst string) error {
+	return m.copyTree(src, dst, m.copyFile)
+}
+
+// copyTree recursively copies a directory tree from src to dst, copying each
+// file with copyFile.
+func (m *Manager) copyTree(src, dst string, copyFile func(src, dst string) error) error {
 	srcInfo, err := m.fs.Stat(src)
 	if err != nil {
 		return err
@@ -335,11 +427,11 @@ func (m *Manager) copyDir(src, dst string) error {
 		dstPath := filepath.Join(dst, entry.Name())
 
 		if entry.IsDir() {
-			if err := m.copyDir(srcPath, dstPath); err != nil {
+			if err := m.copyTree(srcPath, dstPath, copyFile); err != nil {
 				return err
 			}
 		} else {
-			if err := m.copyFile(srcPath, dstPath); err != nil {
+			if err := copyFile(srcPath, dstPath); err != nil {
 				return err
 			}
 		}
@@ -298,13 +298,20 @@ func TestResolvePath(t *testing.T) {
 	plat := &platform.Platform{OS: platform.OSLinux}
 	mgr := New(cfg, plat)
 
+	home, err := os.UserHomeDir()
+	if err != nil {
+		t.Fatalf("cannot get home dir: %v", err)
+	}
+
 	tests := []struct {
 		name string
 		path string
 		want string
 	}{
 		{"relative path", "./configs", filepath.Join(backupRoot, "configs")},
+		{"bare relative path", "configs", filepath.Join(backupRoot, "configs")},
 		{"absolute path", absPath, absPath},
+		{"tilde path outside repo", "~/external-dotfiles/nvim", filepath.Join(home, "external-dotfiles", "nvim")},
 	}
 
 	for _, tt := range tests {
@@ -0,0 +1,74 @@
+package manager
+
+import (
+	"fmt"
+	"io/fs"
+	"log/slog"
+)
+
+// SkippedFile is a file a backup left out of an entry, with the reason.
+type SkippedFile struct {
+	Path       string `json:"path"`
+	SkipReason string `json:"skip_reason"`
+}
+
+// skippedFiles returns the files skipped so far; see withSteps.
+func (m *Manager) skippedFiles() []SkippedFile {
+	if m.skipped == nil {
+		return nil
+	}
+
+	return *m.skipped
+}
+
+// oversized reports whether the file at path is larger than limit bytes, in
+// which case it warns and records the file as skipped. A limit of zero
+// allows any size; a file that cannot be stat'ed is left to the copy.
+func (m *Manager) oversized(path string, limit int64) bool {
+	if limit <= 0 {
+		return false
+	}
+
+	info, err := m.fs.Stat(path)
+	if err != nil || info.Size() <= limit {
+		return false
+	}
+
+	m.logger.Warn("skipping file larger than max_file_size",
+		slog.String("path", path),
+		slog.Int64("size", info.Size()),
+		slog.Int64("max_file_size", limit))
+
+	if m.skipped != nil {
+		*m.skipped = append(*m.skipped, SkippedFile{
+			Path:       path,
+			SkipReason: fmt.Sprintf("%d bytes exceeds max_file_size of %d bytes", info.Size(), limit),
+		})
+	}
+
+	return true
+}
+
+// oversizedFiles returns the files under dir larger than limit bytes, each
+// recorded as skipped, or nil when there is no limit.
+func (m *Manager) oversizedFiles(dir string, limit int64) map[string]bool {
+	if limit <= 0 {
+		return nil
+	}
+
+	skip := make(map[string]bool)
+
+	_ = m.fs.WalkDir(dir, func(path string, d fs.DirEntry, err error) error { //nolint:errcheck // unreadable parts fail the copy instead
+		if err != nil || d.IsDir() {
+			return nil
+		}
+
+		if m.oversized(path, limit) {
+			skip[path] = true
+		}
+
+		return nil
+	})
+
+	return skip
+}
@@ -0,0 +1,131 @@
+package manager
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/AntoineGS/tidydots/internal/config"
+	"github.com/AntoineGS/tidydots/internal/platform"
+)
+
+// newMaxSizeManager returns a manager backing up the files entry "app/cfg"
+// from a target holding small (10 bytes), at (16 bytes) and large (17 bytes).
+func newMaxSizeManager(t *testing.T, entry config.SubEntry) (*Manager, string, string) {
+	t.Helper()
+
+	tmpDir := t.TempDir()
+	target := filepath.Join(tmpDir, "home")
+	backupRoot := filepath.Join(tmpDir, "backup")
+
+	if err := os.MkdirAll(filepath.Join(target, "sub"), 0o750); err != nil {
+		t.Fatal(err)
+	}
+
+	for name, size := range map[string]int{"small": 10, "at": 16, "sub/large": 17} {
+		if err := os.WriteFile(filepath.Join(target, name), []byte(strings.Repeat("x", size)), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	entry.Name = "cfg"
+	entry.Backup = "./app"
+	entry.Targets = map[string]string{"linux": target}
+
+	cfg := &config.Config{
+		Version:      3,
+		BackupRoot:   backupRoot,
+		Applications: []config.Application{{Name: "app", Entries: []config.SubEntry{entry}}},
+	}
+
+	return New(cfg, &platform.Platform{OS: platform.OSLinux}), target, filepath.Join(backupRoot, "app")
+}
+
+func TestBackup_MaxFileSize(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name  string
+		entry config.SubEntry
+	}{
+		{name: "files", entry: config.SubEntry{Files: []string{"small", "at", "sub/large"}, MaxFileSize: 16}},
+		{name: "folder", entry: config.SubEntry{MaxFileSize: 16}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			mgr, target, backup := newMaxSizeManager(t, tt.entry)
+
+			report, err := mgr.BackupReport(context.Background())
+			if err != nil {
+				t.Fatalf("BackupReport() error = %v", err)
+			}
+
+			for _, name := range []string{"small", "at"} {
+				if !testPathExists(filepath.Join(backup, name)) {
+					t.Errorf("%s (not above max_file_size) was not backed up", name)
+				}
+			}
+
+			if testPathExists(filepath.Join(backup, "sub", "large")) {
+				t.Error("sub/large (1 byte above max_file_size) was backed up")
+			}
+
+			res := report.Entries[0]
+			if res.Action != ActionBackedUp {
+				t.Fatalf("Action = %q, want %q", res.Action, ActionBackedUp)
+			}
+
+			if len(res.Skipped) != 1 || res.Skipped[0].Path != filepath.Join(target, "sub", "large") {
+				t.Fatalf("Skipped = %+v, want only sub/large", res.Skipped)
+			}
+
+			if want := "17 bytes exceeds max_file_size of 16 bytes"; res.Skipped[0].SkipReason != want {
+				t.Errorf("SkipReason = %q, want %q", res.Skipped[0].SkipReason, want)
+			}
+		})
+	}
+}
+
+func TestBackup_MaxFileSizeDryRun(t *testing.T) {
+	t.Parallel()
+
+	mgr, _, backup := newMaxSizeManager(t, config.SubEntry{MaxFileSize: 16})
+	mgr.DryRun = true
+
+	report, err := mgr.BackupReport(context.Background())
+	if err != nil {
+		t.Fatalf("BackupReport() error = %v", err)
+	}
+
+	if len(report.Entries[0].Skipped) != 1 {
+		t.Errorf("Skipped = %+v, want the file a real run would skip", report.Entries[0].Skipped)
+	}
+
+	if testPathExists(backup) {
+		t.Error("dry run created the backup")
+	}
+}
+
+func TestBackup_DefaultMaxFileSize(t *testing.T) {
+	t.Parallel()
+
+	mgr, _, backup := newMaxSizeManager(t, config.SubEntry{Files: []string{"small", "at"}})
+	mgr.Config.DefaultMaxFileSize = 15
+
+	if err := mgr.Backup(); err != nil {
+		t.Fatalf("Backup() error = %v", err)
+	}
+
+	if !testPathExists(filepath.Join(backup, "small")) {
+		t.Error("small was not backed up")
+	}
+
+	if testPathExists(filepath.Join(backup, "at")) {
+		t.Error("at (above default_max_file_size) was backed up")
+	}
+}
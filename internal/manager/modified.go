@@ -0,0 +1,90 @@
+package manager
+
+import (
+	"path/filepath"
+
+	"github.com/AntoineGS/tidydots/internal/config"
+)
+
+// ModifiedTarget is a deployed target that no longer matches its backup, one
+// a restore would replace.
+type ModifiedTarget struct {
+	App   string
+	Entry string
+	// Path is the file, or the directory of a folder entry, to save.
+	Path string
+}
+
+// ModifiedTargets lists the targets of the current platform that were
+// changed in place: a regular file or directory where a symlinked entry
+// should have its link. Missing targets and links pointing elsewhere are not
+// edits and are left out, as are copy and sudo entries, whose targets are
+// regular files by design.
+func (m *Manager) ModifiedTargets() []ModifiedTarget {
+	var modified []ModifiedTarget
+
+	for _, app := range m.applicationsByPriority() {
+		for _, subEntry := range app.Entries {
+			if !subEntry.IsConfig() || subEntry.IsCopy() || subEntry.Sudo {
+				continue
+			}
+
+			target := subEntry.GetTarget(m.Platform.OS)
+			if target == "" {
+				continue
+			}
+
+			for _, path := range m.modifiedInEntry(subEntry, m.expandTarget(target)) {
+				modified = append(modified, ModifiedTarget{App: app.Name, Entry: subEntry.Name, Path: path})
+			}
+		}
+	}
+
+	return modified
+}
+
+// modifiedInEntry returns the modified paths of one config entry deployed at
+// target.
+func (m *Manager) modifiedInEntry(subEntry config.SubEntry, target string) []string {
+	backupPath := m.resolvePath(subEntry.Backup)
+
+	// The target of a link_mode: files entry is a real directory by design;
+	// only its links can have been replaced.
+	if subEntry.LinksFiles() {
+		files, _ := LinkFarmFiles(m.fs, backupPath) //nolint:errcheck // a missing backup has nothing deployed
+
+		var paths []string
+
+		for _, file := range files {
+			targetFile := filepath.Join(target, file)
+
+			if m.checkLink(targetFile, filepath.Join(backupPath, file)).State == LinkReplaced {
+				paths = append(paths, targetFile)
+			}
+		}
+
+		return paths
+	}
+
+	if subEntry.IsFolder() {
+		if m.checkLink(target, backupPath).State == LinkReplaced {
+			return []string{target}
+		}
+
+		return nil
+	}
+
+	files, _ := m.entryFiles(subEntry, backupPath) //nolint:errcheck // a malformed pattern matches nothing; restore reports it
+
+	var paths []string
+
+	for _, file := range files {
+		targetFile := filepath.Join(target, file)
+
+		if m.checkLink(targetFile, filepath.Join(backupPath, subEntry.BackupName(file))).State == LinkReplaced {
+			paths = append(paths, targetFile)
+		}
+	}
+
+	return paths
+}
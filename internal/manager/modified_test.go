@@ -0,0 +1,57 @@
+package manager
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestModifiedTargets(t *testing.T) {
+	mgr, root, home := newLinkManager(t)
+
+	// The nvim folder was replaced by a directory, .zshrc is still linked,
+	// .zshenv points elsewhere and the copied .zshrc differs from its backup.
+	if err := os.MkdirAll(filepath.Join(home, "nvim"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := os.Symlink(filepath.Join(root, "zsh", ".zshrc"), filepath.Join(home, ".zshrc")); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := os.Symlink("/elsewhere", filepath.Join(home, ".zshenv")); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := os.MkdirAll(filepath.Join(home, "copy"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := os.WriteFile(filepath.Join(home, "copy", ".zshrc"), []byte("edited"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	got := mgr.ModifiedTargets()
+	want := []ModifiedTarget{{App: "neovim", Entry: "config", Path: filepath.Join(home, "nvim")}}
+
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ModifiedTargets() = %+v, want %+v", got, want)
+	}
+
+	// Replacing the .zshrc link with an edited file adds it.
+	if err := os.Remove(filepath.Join(home, ".zshrc")); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := os.WriteFile(filepath.Join(home, ".zshrc"), []byte("edited"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	got = mgr.ModifiedTargets()
+	want = append(want, ModifiedTarget{App: "zsh", Entry: "rc", Path: filepath.Join(home, ".zshrc")})
+
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ModifiedTargets() = %+v, want %+v", got, want)
+	}
+}
@@ -0,0 +1,178 @@
+package manager
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"text/template"
+	"time"
+
+	"github.com/AntoineGS/tidydots/internal/cmdexec"
+)
+
+// RunSummary is what a notification reports about one backup or restore run.
+// It is the data the notification command template is rendered with, and the
+// JSON body POSTed to the webhook.
+type RunSummary struct {
+	Operation string    `json:"operation"`
+	Succeeded int       `json:"succeeded"`
+	Failed    int       `json:"failed"`
+	Error     string    `json:"error,omitempty"`
+	Host      string    `json:"host"`
+	OS        string    `json:"os"`
+	Version   string    `json:"version,omitempty"`
+	Time      time.Time `json:"time"`
+}
+
+// Status returns "success" or "failure", for use in the command template.
+func (s RunSummary) Status() string {
+	if s.Failed > 0 || s.Error != "" {
+		return "failure"
+	}
+
+	return "success"
+}
+
+// newRunSummary starts the summary of an operation run on this machine.
+func (m *Manager) newRunSummary(op string) RunSummary {
+	return RunSummary{
+		Operation: op,
+		Host:      m.Platform.Hostname,
+		OS:        m.Platform.OS,
+		Version:   m.Version,
+	}
+}
+
+// notify sends the configured notifications for a finished run whose outcome
+// is err. It does nothing in dry-run mode, without a notifications section,
+// or when the section's severity filter leaves the run out. The webhook is
+// not sent in offline mode, but the command, which is local, still runs. The
+// command and webhook are delivered in parallel, each bounded by the configured timeout,
+// and a delivery failure is logged rather than returned: the run itself is
+// over.
+func (m *Manager) notify(summary RunSummary, err error) {
+	n := m.Config.Notifications
+	if n == nil || m.DryRun {
+		return
+	}
+
+	if err != nil {
+		summary.Error = err.Error()
+	}
+
+	if !n.ShouldNotify(summary.Status() == "failure") {
+		return
+	}
+
+	summary.Time = m.now()
+
+	// A canceled run is still reported, so only the timeout bounds delivery.
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(m.ctx), n.TimeoutDuration())
+	defer cancel()
+
+	var wg sync.WaitGroup
+
+	if n.Command != "" {
+		wg.Go(func() {
+			if err := m.runNotifyCommand(ctx, n.Command, summary); err != nil {
+				m.logger.Warn("notification command failed",
+					slog.String("operation", summary.Operation),
+					slog.String("error", err.Error()))
+			}
+		})
+	}
+
+	if n.Webhook != "" && m.Offline {
+		m.logger.Info("skipping notification webhook (offline mode)",
+			slog.String("operation", summary.Operation))
+	} else if n.Webhook != "" {
+		wg.Go(func() {
+			if err := postWebhook(ctx, n.Webhook, summary); err != nil {
+				m.logger.Warn("notification webhook failed",
+					slog.String("operation", summary.Operation),
+					slog.String("url", n.Webhook),
+					slog.String("error", err.Error()))
+			}
+		})
+	}
+
+	wg.Wait()
+}
+
+// env returns the summary as TIDYDOTS_* environment variables, which a
+// notification command can use without its values, such as an error message
+// naming a path, being parsed by the shell.
+func (s RunSummary) env() []string {
+	return []string{
+		"TIDYDOTS_OPERATION=" + s.Operation,
+		"TIDYDOTS_STATUS=" + s.Status(),
+		"TIDYDOTS_SUCCEEDED=" + strconv.Itoa(s.Succeeded),
+		"TIDYDOTS_FAILED=" + strconv.Itoa(s.Failed),
+		"TIDYDOTS_ERROR=" + s.Error,
+		"TIDYDOTS_HOST=" + s.Host,
+		"TIDYDOTS_OS=" + s.OS,
+		"TIDYDOTS_VERSION=" + s.Version,
+		"TIDYDOTS_TIME=" + s.Time.Format(time.RFC3339),
+	}
+}
+
+// runNotifyCommand renders command as a template with summary and runs it
+// through the platform shell, with the summary in its environment too; see
+// RunSummary.env. Templated values are pasted into the command as they are.
+func (m *Manager) runNotifyCommand(ctx context.Context, command string, summary RunSummary) error {
+	t, err := template.New("notification").Option("missingkey=error").Parse(command)
+	if err != nil {
+		return fmt.Errorf("parsing command template: %w", err)
+	}
+
+	var buf strings.Builder
+	if err := t.Execute(&buf, summary); err != nil {
+		return fmt.Errorf("rendering command template: %w", err)
+	}
+
+	name, args := shellCommand(m.Platform.OS, buf.String())
+
+	res, err := m.runner.RunIn(ctx, cmdexec.RunOptions{Env: summary.env()}, name, args...) //nolint:gosec // command from trusted config
+	if err != nil {
+		return err
+	}
+
+	if res.ExitCode != 0 {
+		return fmt.Errorf("exit status %d", res.ExitCode)
+	}
+
+	return nil
+}
+
+// postWebhook POSTs summary as JSON to url.
+func postWebhook(ctx context.Context, url string, summary RunSummary) error {
+	body, err := json.Marshal(summary)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close() //nolint:errcheck // body is not read
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("unexpected status %s", resp.Status)
+	}
+
+	return nil
+}
@@ -0,0 +1,189 @@
+package manager
+
+import (
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/AntoineGS/tidydots/internal/cmdexec"
+	"github.com/AntoineGS/tidydots/internal/config"
+	"github.com/AntoineGS/tidydots/internal/state"
+)
+
+// webhookRecorder is an httptest server that decodes each POSTed summary.
+func webhookRecorder(t *testing.T) (*httptest.Server, chan RunSummary) {
+	t.Helper()
+
+	got := make(chan RunSummary, 4)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var s RunSummary
+		if err := json.NewDecoder(r.Body).Decode(&s); err != nil {
+			t.Errorf("webhook body is not a summary: %v", err)
+		}
+		got <- s
+	}))
+	t.Cleanup(srv.Close)
+
+	return srv, got
+}
+
+func TestBackup_NotifiesWebhook(t *testing.T) {
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	m := newHistoryManager(t, &now)
+	srv, got := webhookRecorder(t)
+	m.Config.Notifications = &config.Notifications{On: config.NotifyAlways, Webhook: srv.URL}
+
+	if err := m.Backup(); err != nil {
+		t.Fatalf("Backup() error = %v", err)
+	}
+
+	select {
+	case s := <-got:
+		if s.Operation != state.OpBackup || s.Succeeded != 2 || s.Failed != 0 || s.Host != "host" || !s.Time.Equal(now) {
+			t.Errorf("summary = %+v, want backup with 2 succeeded on host at %v", s, now)
+		}
+	default:
+		t.Fatal("webhook was not called")
+	}
+}
+
+func TestNotify_FailureOnlyByDefault(t *testing.T) {
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	m := newHistoryManager(t, &now)
+	srv, got := webhookRecorder(t)
+	m.Config.Notifications = &config.Notifications{Webhook: srv.URL}
+
+	if err := m.Backup(); err != nil {
+		t.Fatalf("Backup() error = %v", err)
+	}
+
+	if len(got) != 0 {
+		t.Error("a successful run was reported with the default failure filter")
+	}
+
+	m.notify(RunSummary{Operation: state.OpRestore, Succeeded: 1, Failed: 1}, errors.New("boom"))
+
+	if s := <-got; s.Failed != 1 || s.Error != "boom" || s.Status() != "failure" {
+		t.Errorf("summary = %+v, want one failure with error boom", s)
+	}
+}
+
+func TestNotify_DryRunSendsNothing(t *testing.T) {
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	m := newHistoryManager(t, &now)
+	srv, got := webhookRecorder(t)
+	m.Config.Notifications = &config.Notifications{On: config.NotifyAlways, Webhook: srv.URL}
+	m.DryRun = true
+
+	if err := m.Backup(); err != nil {
+		t.Fatalf("Backup() error = %v", err)
+	}
+
+	if len(got) != 0 {
+		t.Error("a dry run was reported")
+	}
+}
+
+func TestNotify_RunsTemplatedCommand(t *testing.T) {
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	m := newHistoryManager(t, &now)
+	stub := cmdexec.NewStubRunner()
+	m = m.WithRunner(stub)
+	m.Config.Notifications = &config.Notifications{
+		On:      config.NotifyAlways,
+		Command: `notify-send "tidydots {{ .Operation }}: {{ .Succeeded }} ok, {{ .Failed }} failed ({{ .Status }})"`,
+	}
+
+	m.notify(RunSummary{Operation: state.OpBackup, Succeeded: 3, Failed: 1}, nil)
+
+	if len(stub.Calls) != 1 {
+		t.Fatalf("expected one command, got %+v", stub.Calls)
+	}
+
+	want := `notify-send "tidydots backup: 3 ok, 1 failed (failure)"`
+	if call := stub.Calls[0]; call.Name != "sh" || call.Args[1] != want {
+		t.Errorf("command = %s %v, want sh -c %q", call.Name, call.Args, want)
+	}
+}
+
+func TestNotify_SlowWebhookTimesOut(t *testing.T) {
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	m := newHistoryManager(t, &now)
+
+	release := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(http.ResponseWriter, *http.Request) { <-release }))
+	t.Cleanup(srv.Close)
+	t.Cleanup(func() { close(release) })
+
+	var logs strings.Builder
+	m = m.WithLogger(slog.New(slog.NewTextHandler(&logs, nil)))
+	m.Config.Notifications = &config.Notifications{On: config.NotifyAlways, Webhook: srv.URL, Timeout: "50ms"}
+
+	start := time.Now()
+	m.notify(RunSummary{Operation: state.OpBackup}, nil)
+
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Errorf("notify blocked for %v, want about the 50ms timeout", elapsed)
+	}
+
+	if !strings.Contains(logs.String(), "notification webhook failed") {
+		t.Errorf("expected a warning about the webhook, got logs:\n%s", logs.String())
+	}
+}
+
+func TestNotify_CommandGetsSummaryInEnvironment(t *testing.T) {
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	m := newHistoryManager(t, &now)
+	stub := cmdexec.NewStubRunner()
+	m = m.WithRunner(stub)
+	m.Config.Notifications = &config.Notifications{
+		On:      config.NotifyAlways,
+		Command: `notify-send "tidydots $TIDYDOTS_OPERATION" "$TIDYDOTS_ERROR"`,
+	}
+
+	// Quotes and a command substitution in a path must reach the command as
+	// data, never as part of the shell command line.
+	failure := `restore /home/u/it's "$(rm -rf ~)": permission denied`
+	m.notify(RunSummary{Operation: state.OpRestore, Failed: 1}, errors.New(failure))
+
+	if len(stub.Calls) != 1 {
+		t.Fatalf("expected one command, got %+v", stub.Calls)
+	}
+
+	call := stub.Calls[0]
+	if want := `notify-send "tidydots $TIDYDOTS_OPERATION" "$TIDYDOTS_ERROR"`; call.Args[1] != want {
+		t.Errorf("command = %q, want it unchanged %q", call.Args[1], want)
+	}
+
+	for _, want := range []string{"TIDYDOTS_OPERATION=restore", "TIDYDOTS_STATUS=failure", "TIDYDOTS_FAILED=1", "TIDYDOTS_ERROR=" + failure} {
+		if !slices.Contains(call.Env, want) {
+			t.Errorf("environment %q is missing %q", call.Env, want)
+		}
+	}
+}
+
+func TestNotify_OfflineSkipsWebhook(t *testing.T) {
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	m := newHistoryManager(t, &now)
+	srv, got := webhookRecorder(t)
+	stub := cmdexec.NewStubRunner()
+	m = m.WithRunner(stub)
+	m.Config.Notifications = &config.Notifications{On: config.NotifyAlways, Webhook: srv.URL, Command: "notify-send tidydots"}
+	m.Offline = true
+
+	m.notify(RunSummary{Operation: state.OpBackup, Succeeded: 1}, nil)
+
+	if len(got) != 0 {
+		t.Error("the webhook was sent in offline mode")
+	}
+
+	if len(stub.Calls) != 1 {
+		t.Errorf("expected the local command to run offline, got %+v", stub.Calls)
+	}
+}
@@ -0,0 +1,159 @@
+package manager
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"regexp"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/AntoineGS/tidydots/internal/cmdexec"
+	"github.com/AntoineGS/tidydots/internal/config"
+	"github.com/AntoineGS/tidydots/internal/platform"
+)
+
+// priorityApps returns applications listed in the reverse of the order their
+// priorities put them in, with a tie to check config order is kept.
+func priorityApps() []config.Application {
+	setup := func(name string) []config.SubEntry {
+		return []config.SubEntry{{
+			Name:  "setup",
+			Check: map[string]string{"linux": "check " + name},
+			Run:   map[string]string{"linux": "run " + name},
+		}}
+	}
+
+	return []config.Application{
+		{Name: "low", Entries: setup("low")},
+		{Name: "tie-first", Priority: 5, Entries: setup("tie-first")},
+		{Name: "tie-second", Priority: 5, Entries: setup("tie-second")},
+		{Name: "high", Priority: 10, Entries: setup("high")},
+	}
+}
+
+var wantPriorityOrder = []string{"high", "tie-first", "tie-second", "low"}
+
+func TestRestoreWithContext_HigherPriorityFirst(t *testing.T) {
+	stub := cmdexec.NewStubRunner()
+
+	cfg := &config.Config{Version: 3, BackupRoot: "/repo", Applications: priorityApps()}
+	plat := &platform.Platform{OS: platform.OSLinux, EnvVars: map[string]string{}}
+
+	if err := New(cfg, plat).WithRunner(stub).RestoreWithContext(context.Background()); err != nil {
+		t.Fatalf("RestoreWithContext() error = %v", err)
+	}
+
+	var got []string
+	for _, c := range shellCalls(stub) {
+		got = append(got, strings.TrimPrefix(c.Args[len(c.Args)-1], "check "))
+	}
+
+	if !slices.Equal(got, wantPriorityOrder) {
+		t.Errorf("setup checks ran for %v, want %v", got, wantPriorityOrder)
+	}
+}
+
+// eventLog collects the lines of a logger and of a runner in the order they
+// are written from concurrent restores.
+type eventLog struct {
+	mu    sync.Mutex
+	lines []string
+}
+
+func (l *eventLog) Write(p []byte) (int, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.lines = append(l.lines, strings.TrimSpace(string(p)))
+
+	return len(p), nil
+}
+
+// slowChecks is a runner whose setup checks take a while and log when they
+// are done, so an application started too early shows up before them.
+type slowChecks struct {
+	*cmdexec.StubRunner
+	log *eventLog
+}
+
+func (r slowChecks) RunIn(ctx context.Context, opts cmdexec.RunOptions, name string, args ...string) (cmdexec.Result, error) {
+	res, err := r.StubRunner.RunIn(ctx, opts, name, args...)
+
+	if check, ok := strings.CutPrefix(args[len(args)-1], "check "); ok {
+		time.Sleep(20 * time.Millisecond)
+		_, _ = r.log.Write([]byte("done " + check))
+	}
+
+	return res, err
+}
+
+func TestRestoreWithContext_JobsWaitForHigherPriority(t *testing.T) {
+	events := &eventLog{}
+	apps := priorityApps()
+
+	cfg := &config.Config{Version: 3, BackupRoot: "/repo", Applications: apps}
+	plat := &platform.Platform{OS: platform.OSLinux, EnvVars: map[string]string{}}
+	mgr := New(cfg, plat).
+		WithRunner(slowChecks{StubRunner: cmdexec.NewStubRunner(), log: events}).
+		WithLogger(slog.New(slog.NewTextHandler(events, nil)))
+	mgr.Jobs = len(apps)
+
+	if err := mgr.RestoreWithContext(context.Background()); err != nil {
+		t.Fatalf("RestoreWithContext() error = %v", err)
+	}
+
+	priority := make(map[string]int, len(apps))
+	for _, app := range apps {
+		priority[app.Name] = app.Priority
+	}
+
+	started := regexp.MustCompile(`msg="restoring application" app=(\S+)`)
+	done := map[string]bool{}
+
+	for _, line := range events.lines {
+		if name, ok := strings.CutPrefix(line, "done "); ok {
+			done[name] = true
+			continue
+		}
+
+		m := started.FindStringSubmatch(line)
+		if m == nil {
+			continue
+		}
+
+		for _, app := range apps {
+			if app.Priority > priority[m[1]] && !done[app.Name] {
+				t.Errorf("%s started before %s, of a higher priority, was done", m[1], app.Name)
+			}
+		}
+	}
+
+	if len(done) != len(apps) {
+		t.Errorf("checks done for %v, want every application", done)
+	}
+}
+
+func TestBackupWithContext_HigherPriorityFirst(t *testing.T) {
+	var logs bytes.Buffer
+
+	cfg := &config.Config{Version: 3, BackupRoot: t.TempDir(), Applications: priorityApps()}
+	plat := &platform.Platform{OS: platform.OSLinux, EnvVars: map[string]string{}}
+	mgr := New(cfg, plat).WithLogger(slog.New(slog.NewTextHandler(&logs, nil)))
+
+	if err := mgr.BackupWithContext(context.Background()); err != nil {
+		t.Fatalf("BackupWithContext() error = %v", err)
+	}
+
+	var got []string
+	for _, m := range regexp.MustCompile(`msg="backing up application" app=(\S+)`).FindAllStringSubmatch(logs.String(), -1) {
+		got = append(got, m[1])
+	}
+
+	if !slices.Equal(got, wantPriorityOrder) {
+		t.Errorf("applications backed up in order %v, want %v", got, wantPriorityOrder)
+	}
+}
@@ -0,0 +1,244 @@
+package manager
+
+import (
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"path/filepath"
+	"strings"
+
+	"github.com/AntoineGS/tidydots/internal/config"
+	tmpl "github.com/AntoineGS/tidydots/internal/template"
+)
+
+// StaleBackup is a file in the backup of a folder entry whose counterpart no
+// longer exists in the entry's target.
+type StaleBackup struct {
+	App   string
+	Entry string
+	// Path is the backup file to remove.
+	Path string
+	// root is the entry's backup path; PruneBackups never removes anything
+	// outside of it.
+	root string
+	sudo bool
+}
+
+// FindStaleBackups lists the backed-up files of every folder entry of the
+// current platform that no longer exist in the entry's target, the files a
+// backup would leave behind after they were deleted from the target. Entries
+// whose target is missing or is the symlink restore created are skipped, as
+// are sudo entries when NoSudo is set. Checksum files, template sources and
+// template artifacts have no counterpart in the target and are kept.
+// The target of a link_mode: files entry holds links to only some of its
+// backup, so only the files restore linked there and that are gone count.
+func (m *Manager) FindStaleBackups() ([]StaleBackup, error) {
+	var stale []StaleBackup
+
+	for _, app := range m.applicationsByPriority() {
+		for _, subEntry := range app.Entries {
+			if !subEntry.IsConfig() || !subEntry.IsFolder() || m.SkipsSudo(subEntry) {
+				continue
+			}
+
+			target := subEntry.GetTarget(m.Platform.OS)
+			if target == "" {
+				continue
+			}
+
+			found, err := m.findStaleInEntry(app.Name, subEntry, m.expandTarget(target))
+			if err != nil {
+				return nil, fmt.Errorf("%s/%s: %w", app.Name, subEntry.Name, err)
+			}
+
+			stale = append(stale, found...)
+		}
+	}
+
+	return stale, nil
+}
+
+// findStaleInEntry walks the backup of one folder entry deployed at target.
+func (m *Manager) findStaleInEntry(appName string, subEntry config.SubEntry, target string) ([]StaleBackup, error) {
+	backupPath := m.resolvePath(subEntry.Backup)
+
+	if !m.pathExists(target) || m.isSymlink(target) || !m.pathExists(backupPath) {
+		return nil, nil
+	}
+
+	if subEntry.LinksFiles() {
+		return m.findStaleLinkedFiles(appName, subEntry, backupPath, target), nil
+	}
+
+	// A backup path that holds the whole backup root would prune other
+	// entries and the configuration itself.
+	if rel, err := filepath.Rel(backupPath, m.resolvePath(".")); err == nil && !strings.HasPrefix(rel, "..") {
+		m.logger.Warn("not pruning an entry backed up to the backup root",
+			slog.String("app", appName),
+			slog.String("entry", subEntry.Name))
+
+		return nil, nil
+	}
+
+	var stale []StaleBackup
+
+	err := m.fs.WalkDir(backupPath, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if path == backupPath {
+			return nil
+		}
+
+		name := d.Name()
+		if IsChecksumFile(name) || tmpl.IsTemplateFile(name) || tmpl.IsRenderedFile(name) || tmpl.IsConflictFile(name) {
+			return nil
+		}
+
+		rel, err := filepath.Rel(backupPath, path)
+		if err != nil {
+			return err
+		}
+
+		if m.pathExists(filepath.Join(target, rel)) {
+			return nil
+		}
+
+		if d.IsDir() {
+			// Every file below a directory deleted from the target is stale.
+			err := m.fs.WalkDir(path, func(p string, d fs.DirEntry, err error) error {
+				if err != nil || d.IsDir() {
+					return err
+				}
+
+				stale = append(stale, StaleBackup{App: appName, Entry: subEntry.Name, Path: p, root: backupPath, sudo: subEntry.Sudo})
+
+				return nil
+			})
+			if err != nil {
+				return err
+			}
+
+			return fs.SkipDir
+		}
+
+		stale = append(stale, StaleBackup{App: appName, Entry: subEntry.Name, Path: path, root: backupPath, sudo: subEntry.Sudo})
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return stale, nil
+}
+
+// findStaleLinkedFiles lists the backup files of a link_mode: files entry
+// that restore linked into target and that were deleted from it since. A
+// file added to the backup after the last restore has no link yet and is
+// not stale.
+func (m *Manager) findStaleLinkedFiles(appName string, subEntry config.SubEntry, backupPath, target string) []StaleBackup {
+	var stale []StaleBackup
+
+	for _, rel := range m.linkedFiles(appName, subEntry.Name) {
+		path := filepath.Join(backupPath, rel)
+		if m.pathExists(filepath.Join(target, rel)) || !m.pathExists(path) {
+			continue
+		}
+
+		stale = append(stale, StaleBackup{App: appName, Entry: subEntry.Name, Path: path, root: backupPath, sudo: subEntry.Sudo})
+	}
+
+	return stale
+}
+
+// PruneBackups removes the stale backup files FindStaleBackups listed, along
+// with their checksums and the directories left empty, and returns
+// the files it removed. A path outside its entry's backup is never removed.
+// In dry-run mode nothing is removed and every file is returned.
+func (m *Manager) PruneBackups(stale []StaleBackup) ([]string, error) {
+	var removed []string
+
+	for _, s := range stale {
+		if err := m.checkContext(); err != nil {
+			return removed, err
+		}
+
+		rel, err := filepath.Rel(s.root, s.Path)
+		if s.root == "" || err != nil || rel == "." || strings.HasPrefix(rel, "..") || filepath.IsAbs(rel) {
+			return removed, NewPathError("prune", s.Path, fmt.Errorf("outside the backup of %s/%s", s.App, s.Entry))
+		}
+
+		m.logger.Info("pruning stale backup", slog.String("path", s.Path))
+
+		if m.DryRun {
+			removed = append(removed, s.Path)
+			continue
+		}
+
+		if err := m.removePath(s.Path, s.sudo); err != nil {
+			return removed, NewPathError("prune", s.Path, err)
+		}
+
+		removed = append(removed, s.Path)
+
+		if err := m.dropFromManifest(s.root, s.Path); err != nil {
+			return removed, err
+		}
+
+		m.removeEmptyParents(filepath.Dir(s.Path), s.root)
+	}
+
+	return removed, nil
+}
+
+// dropFromManifest removes the line of path, a file removed from the folder
+// backup dir, from the folder's checksum manifest, if it has one.
+func (m *Manager) dropFromManifest(dir, path string) error {
+	manifest := folderManifest(dir)
+	if !m.pathExists(manifest) {
+		return nil
+	}
+
+	rel, err := filepath.Rel(dir, path)
+	if err != nil {
+		return err
+	}
+
+	data, err := m.fs.ReadFile(manifest)
+	if err != nil {
+		return NewPathError("prune", manifest, err)
+	}
+
+	var kept strings.Builder
+
+	for line := range strings.Lines(string(data)) {
+		if _, name, _ := strings.Cut(strings.TrimRight(line, "\r\n"), "  "); name != filepath.ToSlash(rel) {
+			kept.WriteString(line)
+		}
+	}
+
+	if err := m.fs.WriteFile(manifest, []byte(kept.String()), FilePerms); err != nil {
+		return NewPathError("prune", manifest, err)
+	}
+
+	return nil
+}
+
+// removeEmptyParents removes dir and its parents while they are empty,
+// stopping at root, which is kept.
+func (m *Manager) removeEmptyParents(dir, root string) {
+	for dir != root && strings.HasPrefix(dir, root+string(filepath.Separator)) {
+		entries, err := m.fs.ReadDir(dir)
+		if err != nil || len(entries) > 0 {
+			return
+		}
+
+		if err := m.fs.Remove(dir); err != nil {
+			return
+		}
+
+		dir = filepath.Dir(dir)
+	}
+}
@@ -0,0 +1,186 @@
+package manager
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/AntoineGS/tidydots/internal/config"
+	"github.com/AntoineGS/tidydots/internal/platform"
+)
+
+// setupPruneEntry creates a folder entry whose backup holds a file and a
+// subdirectory the target no longer has.
+func setupPruneEntry(t *testing.T) (mgr *Manager, target, backup string) {
+	t.Helper()
+	tmpDir := t.TempDir()
+
+	target = filepath.Join(tmpDir, "target")
+	backup = filepath.Join(tmpDir, "repo", "nvim")
+
+	for path, content := range map[string]string{
+		filepath.Join(target, "init.lua"):                 "kept",
+		filepath.Join(backup, "init.lua"):                 "kept",
+		filepath.Join(backup, "old.lua"):                  "stale",
+		filepath.Join(backup, "lua", "plugin.lua"):        "stale",
+		filepath.Join(backup, "lua", "sub", "x.lua"):      "stale",
+		filepath.Join(backup, "colors.lua.tmpl"):          "template",
+		filepath.Join(backup, "colors.lua.tmpl.rendered"): "rendered",
+	} {
+		if err := os.MkdirAll(filepath.Dir(path), 0750); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	cfg := &config.Config{
+		Version:    3,
+		BackupRoot: filepath.Join(tmpDir, "repo"),
+		Applications: []config.Application{{
+			Name:    "nvim",
+			Entries: []config.SubEntry{{Name: "config", Backup: "./nvim", Targets: map[string]string{"linux": target}}},
+		}},
+	}
+
+	return New(cfg, &platform.Platform{OS: platform.OSLinux}), target, backup
+}
+
+func TestFindStaleBackups(t *testing.T) {
+	t.Parallel()
+	mgr, _, backup := setupPruneEntry(t)
+
+	stale, err := mgr.FindStaleBackups()
+	if err != nil {
+		t.Fatalf("FindStaleBackups() error = %v", err)
+	}
+
+	var got []string
+	for _, s := range stale {
+		if s.App != "nvim" || s.Entry != "config" {
+			t.Errorf("stale entry = %s/%s, want nvim/config", s.App, s.Entry)
+		}
+		got = append(got, s.Path)
+	}
+
+	want := []string{
+		filepath.Join(backup, "lua", "plugin.lua"),
+		filepath.Join(backup, "lua", "sub", "x.lua"),
+		filepath.Join(backup, "old.lua"),
+	}
+	slices.Sort(got)
+	if !slices.Equal(got, want) {
+		t.Errorf("FindStaleBackups() = %v, want %v", got, want)
+	}
+}
+
+func TestPruneBackups(t *testing.T) {
+	t.Parallel()
+	mgr, _, backup := setupPruneEntry(t)
+
+	stale, err := mgr.FindStaleBackups()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	removed, err := mgr.PruneBackups(stale)
+	if err != nil {
+		t.Fatalf("PruneBackups() error = %v", err)
+	}
+
+	if len(removed) != 3 {
+		t.Errorf("PruneBackups() removed %v, want 3 files", removed)
+	}
+
+	for _, gone := range []string{"old.lua", "lua"} {
+		if testPathExists(filepath.Join(backup, gone)) {
+			t.Errorf("%s should have been pruned", gone)
+		}
+	}
+
+	for _, kept := range []string{"init.lua", "colors.lua.tmpl", "colors.lua.tmpl.rendered"} {
+		if !testPathExists(filepath.Join(backup, kept)) {
+			t.Errorf("%s should have been kept", kept)
+		}
+	}
+}
+
+func TestPruneBackups_UpdatesManifest(t *testing.T) {
+	t.Parallel()
+	mgr, _, backup := setupPruneEntry(t)
+
+	manifest := "aaaa  init.lua\nbbbb  old.lua\ncccc  lua/plugin.lua\n"
+	if err := os.WriteFile(backup+ChecksumSuffix, []byte(manifest), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	stale, err := mgr.FindStaleBackups()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := mgr.PruneBackups(stale); err != nil {
+		t.Fatalf("PruneBackups() error = %v", err)
+	}
+
+	got, err := os.ReadFile(backup + ChecksumSuffix)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if string(got) != "aaaa  init.lua\n" {
+		t.Errorf("manifest after prune = %q, want only init.lua", got)
+	}
+}
+
+func TestPruneBackups_DryRun(t *testing.T) {
+	t.Parallel()
+	mgr, _, backup := setupPruneEntry(t)
+	mgr.DryRun = true
+
+	stale, err := mgr.FindStaleBackups()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if removed, err := mgr.PruneBackups(stale); err != nil || len(removed) != len(stale) {
+		t.Errorf("PruneBackups() = %v, %v; want every stale file listed", removed, err)
+	}
+
+	if !testPathExists(filepath.Join(backup, "old.lua")) {
+		t.Error("dry run should not remove anything")
+	}
+}
+
+func TestPruneBackups_RefusesOutsideEntry(t *testing.T) {
+	t.Parallel()
+	mgr, target, backup := setupPruneEntry(t)
+
+	outside := filepath.Join(target, "init.lua")
+	stale := []StaleBackup{{App: "nvim", Entry: "config", Path: outside, root: backup}}
+
+	if _, err := mgr.PruneBackups(stale); err == nil {
+		t.Error("PruneBackups() error = nil, want a refusal for a path outside the entry's backup")
+	}
+
+	if !testPathExists(outside) {
+		t.Error("a file outside the entry's backup was removed")
+	}
+}
+
+func TestFindStaleBackups_SkipsBackupRoot(t *testing.T) {
+	t.Parallel()
+	mgr, _, _ := setupPruneEntry(t)
+	mgr.Config.Applications[0].Entries[0].Backup = "."
+
+	stale, err := mgr.FindStaleBackups()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(stale) != 0 {
+		t.Errorf("FindStaleBackups() = %v, want nothing for an entry backed up to the root", stale)
+	}
+}
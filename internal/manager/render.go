@@ -0,0 +1,126 @@
+package manager
+
+import (
+	"fmt"
+	"io/fs"
+	"path/filepath"
+
+	tmpl "github.com/AntoineGS/tidydots/internal/template"
+)
+
+// TemplateFile is a .tmpl file inside the backup of a folder config entry,
+// which is where restore looks for templates.
+type TemplateFile struct {
+	App      string
+	Entry    string
+	Path     string // absolute path to the .tmpl file
+	RelPath  string // path within the entry's backup; also the template name
+	Encoding string // the entry's encoding; see config.SubEntry.Encoding
+}
+
+// WithTemplateContext returns a new Manager whose templates, `when`
+// expressions and templated paths are rendered with ctx instead of the
+// context detected from the platform.
+func (m *Manager) WithTemplateContext(ctx *tmpl.Context) *Manager {
+	m2 := *m
+	m2.templateEngine = tmpl.NewEngine(ctx)
+	return &m2
+}
+
+// TemplateFiles returns the templates of the current platform's folder
+// entries, in config order. An empty app, entry or file matches everything;
+// file is a path relative to the entry's backup. It is an error for a
+// non-empty app or entry to match nothing.
+func (m *Manager) TemplateFiles(app, entry, file string) ([]TemplateFile, error) {
+	var (
+		files      []TemplateFile
+		foundApp   bool
+		foundEntry bool
+	)
+
+	for _, a := range m.GetApplications() {
+		if app != "" && a.Name != app {
+			continue
+		}
+
+		foundApp = true
+
+		for _, sub := range a.Entries {
+			if entry != "" && sub.Name != entry {
+				continue
+			}
+
+			foundEntry = true
+
+			if !sub.IsFolder() {
+				continue
+			}
+
+			backupPath := m.resolvePath(sub.Backup)
+			if !m.pathExists(backupPath) {
+				continue
+			}
+
+			err := m.fs.WalkDir(backupPath, func(path string, d fs.DirEntry, err error) error {
+				if err != nil {
+					return err
+				}
+
+				if d.IsDir() || !tmpl.IsTemplateFile(d.Name()) {
+					return nil
+				}
+
+				relPath, err := filepath.Rel(backupPath, path)
+				if err != nil {
+					return err
+				}
+
+				if file != "" && filepath.Clean(file) != relPath {
+					return nil
+				}
+
+				files = append(files, TemplateFile{
+					App: a.Name, Entry: sub.Name, Path: path, RelPath: relPath, Encoding: sub.Encoding,
+				})
+
+				return nil
+			})
+			if err != nil {
+				return nil, NewPathError("render", backupPath, fmt.Errorf("listing templates: %w", err))
+			}
+		}
+	}
+
+	switch {
+	case app != "" && !foundApp:
+		return nil, fmt.Errorf("application %q not found for this platform", app)
+	case entry != "" && !foundEntry:
+		return nil, fmt.Errorf("entry %q not found in application %q", entry, app)
+	}
+
+	return files, nil
+}
+
+// RenderTemplateFile renders a template with the same engine and template
+// name restore uses, so the output matches the .tmpl.rendered file restore
+// would write (before any merge with local edits). Failures are returned as
+// *template.RenderError.
+func (m *Manager) RenderTemplateFile(tf TemplateFile) ([]byte, error) {
+	content, err := m.fs.ReadFile(tf.Path)
+	if err != nil {
+		return nil, NewPathError("render", tf.Path, fmt.Errorf("reading template: %w", err))
+	}
+
+	engine, err := m.forApp(tf.App).templateEngine.WithEncoding(tf.Encoding)
+	if err != nil {
+		return nil, NewPathError("render", tf.Path, err)
+	}
+
+	return engine.RenderFile(tf.RelPath, content)
+}
+
+// RenderTemplate renders template content that is not part of a config entry,
+// such as a template read from stdin, with the manager's engine.
+func (m *Manager) RenderTemplate(name string, content []byte) ([]byte, error) {
+	return m.templateEngine.RenderFile(name, content)
+}
@@ -0,0 +1,138 @@
+package manager
+
+import (
+	"errors"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/AntoineGS/tidydots/internal/config"
+	"github.com/AntoineGS/tidydots/internal/platform"
+	tmpl "github.com/AntoineGS/tidydots/internal/template"
+)
+
+// newRenderManager returns a Manager over a backup holding two nvim templates,
+// a broken zsh template and a plain file.
+func newRenderManager(t *testing.T) *Manager {
+	t.Helper()
+	backupRoot := t.TempDir()
+
+	for name, content := range map[string]string{
+		"nvim/init.lua.tmpl":     "os={{ .OS }}\n",
+		"nvim/lua/opts.lua.tmpl": "host={{ .Hostname }}\n",
+		"nvim/plain.lua":         "plain\n",
+		"zsh/zshrc.tmpl":         "a\n{{ .Nope }}\n",
+	} {
+		path := filepath.Join(backupRoot, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0750); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	cfg := &config.Config{
+		Version:    3,
+		BackupRoot: backupRoot,
+		Applications: []config.Application{
+			{Name: "nvim", Entries: []config.SubEntry{{Name: "config", Backup: "./nvim", Targets: map[string]string{platform.OSLinux: "~/.config/nvim"}}}},
+			{Name: "zsh", Entries: []config.SubEntry{{Name: "rc", Backup: "./zsh", Targets: map[string]string{platform.OSLinux: "~/.config/zsh"}}}},
+		},
+	}
+
+	return New(cfg, &platform.Platform{OS: platform.OSLinux, Hostname: "desktop"}).
+		WithLogger(slog.New(slog.NewTextHandler(io.Discard, nil)))
+}
+
+func TestTemplateFiles(t *testing.T) {
+	t.Parallel()
+	mgr := newRenderManager(t)
+
+	tests := []struct {
+		name    string
+		app     string
+		entry   string
+		file    string
+		want    []string
+		wantErr bool
+	}{
+		{name: "all", want: []string{"init.lua.tmpl", filepath.Join("lua", "opts.lua.tmpl"), "zshrc.tmpl"}},
+		{name: "one entry", app: "nvim", entry: "config", want: []string{"init.lua.tmpl", filepath.Join("lua", "opts.lua.tmpl")}},
+		{name: "one file", app: "nvim", file: "lua/opts.lua.tmpl", want: []string{filepath.Join("lua", "opts.lua.tmpl")}},
+		{name: "unknown app", app: "tmux", wantErr: true},
+		{name: "unknown entry", app: "nvim", entry: "nope", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			files, err := mgr.TemplateFiles(tt.app, tt.entry, tt.file)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("TemplateFiles() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			var got []string
+			for _, f := range files {
+				got = append(got, f.RelPath)
+			}
+
+			if len(got) != len(tt.want) {
+				t.Fatalf("TemplateFiles() = %v, want %v", got, tt.want)
+			}
+
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Errorf("TemplateFiles()[%d] = %q, want %q", i, got[i], tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestRenderTemplateFile(t *testing.T) {
+	t.Parallel()
+	mgr := newRenderManager(t)
+
+	files, err := mgr.TemplateFiles("", "", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := mgr.RenderTemplateFile(files[0])
+	if err != nil || string(got) != "os=linux\n" {
+		t.Errorf("RenderTemplateFile(init.lua.tmpl) = %q, %v; want os=linux", got, err)
+	}
+
+	ctx := tmpl.NewContextFromPlatform(mgr.Platform)
+	if err := ctx.Set("OS", "windows"); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err = mgr.WithTemplateContext(ctx).RenderTemplateFile(files[0])
+	if err != nil || string(got) != "os=windows\n" {
+		t.Errorf("with overridden context = %q, %v; want os=windows", got, err)
+	}
+
+	var rerr *tmpl.RenderError
+	if _, err := mgr.RenderTemplateFile(files[2]); !errors.As(err, &rerr) || rerr.Line != 2 {
+		t.Errorf("RenderTemplateFile(zshrc.tmpl) error = %v, want RenderError on line 2", err)
+	}
+}
+
+func TestNew_TemplatesSeeConfigDir(t *testing.T) {
+	t.Parallel()
+	mgr := newRenderManager(t)
+
+	want := filepath.Join(mgr.Config.BackupRoot, "themes", "current")
+	if got := mgr.expandTarget("{{ .ConfigDir }}/themes/current"); filepath.Clean(got) != want {
+		t.Errorf("expandTarget({{ .ConfigDir }}/themes/current) = %q, want %q", got, want)
+	}
+
+	if got := mgr.expandTarget("{{ .BackupRoot }}"); got != mgr.Config.BackupRoot {
+		t.Errorf("expandTarget({{ .BackupRoot }}) = %q, want %q", got, mgr.Config.BackupRoot)
+	}
+}
@@ -0,0 +1,156 @@
+package manager
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+)
+
+// EntryAction is what a restore or backup run did with one entry.
+type EntryAction string
+
+// Entry actions recorded in a Report.
+const (
+	ActionRestored EntryAction = "restored"
+	ActionBackedUp EntryAction = "backed up"
+	ActionSetUp    EntryAction = "set up"
+	ActionSkipped  EntryAction = "skipped"
+	ActionFailed   EntryAction = "failed"
+)
+
+// Reasons recorded in the Detail of a skipped entry.
+const (
+	skipReasonSudo    = "requires sudo"
+	skipReasonStale   = "backed up within the stale window"
+	skipReasonOffline = "offline mode"
+	skipReasonSetup   = "setup commands are for another OS"
+	skipReasonKept    = "kept the existing target"
+
+	skipReasonElevation = "elevation declined"
+)
+
+// EntryResult is the outcome of one entry of a restore or backup run.
+// Detail says what was done, e.g. "~/.zshrc -> /repo/zsh", or why the entry
+// was skipped. Err is set when Action is ActionFailed. Steps lists the
+// changes decided on, up to the failure if any. Skipped lists the files a
+// backup left out of an entry it otherwise backed up. Warning is set when
+// the entry was restored but its post_restore command failed.
+type EntryResult struct {
+	Err     error
+	App     string
+	Entry   string
+	Action  EntryAction
+	Detail  string
+	Warning string
+	Steps   []Step
+	Skipped []SkippedFile
+}
+
+// Name returns the entry as application/entry.
+func (r EntryResult) Name() string {
+	return r.App + "/" + r.Entry
+}
+
+// OK reports whether the entry did not fail. A skipped entry is OK.
+func (r EntryResult) OK() bool {
+	return r.Action != ActionFailed
+}
+
+// Message describes the result in one line, e.g. "Restored: ~/.zshrc ->
+// /repo/zsh" or "Failed: ...".
+func (r EntryResult) Message() string {
+	switch r.Action {
+	case ActionFailed:
+		return fmt.Sprintf("Failed: %v", r.Err)
+	case ActionSkipped:
+		return "Skipped: " + r.Detail
+	case ActionRestored:
+		if r.Warning != "" {
+			return fmt.Sprintf("Restored: %s (warning: %s)", r.Detail, r.Warning)
+		}
+
+		return "Restored: " + r.Detail
+	case ActionBackedUp:
+		return "Backed up: " + r.Detail
+	case ActionSetUp:
+		return "Set up"
+	}
+
+	return string(r.Action)
+}
+
+// EntryError is one failed entry in the error returned by a restore or backup
+// run, which joins one EntryError per failure. Use errors.As to find them.
+// The message is the underlying error's, which already names the path.
+type EntryError struct {
+	Err   error
+	App   string
+	Entry string
+}
+
+func (e *EntryError) Error() string { return e.Err.Error() }
+
+func (e *EntryError) Unwrap() error { return e.Err }
+
+// Is reports whether target is ErrMissingPrivileges and the entry failed
+// with a permission error.
+func (e *EntryError) Is(target error) bool {
+	return target == ErrMissingPrivileges && errors.Is(e.Err, fs.ErrPermission)
+}
+
+// Report collects the outcome of every entry a restore or backup run
+// attempted, in the order they ran. Entries that do not apply to this
+// machine (no target for the OS, not a config entry) are not listed.
+type Report struct {
+	Operation string
+	Entries   []EntryResult
+}
+
+// add records the outcome of one entry.
+func (r *Report) add(res EntryResult) {
+	r.Entries = append(r.Entries, res)
+}
+
+// Count returns how many entries ended with action.
+func (r *Report) Count(action EntryAction) int {
+	n := 0
+
+	for _, e := range r.Entries {
+		if e.Action == action {
+			n++
+		}
+	}
+
+	return n
+}
+
+// Err joins an *EntryError for every failed entry, or returns nil when none
+// failed. Skipped entries are not failures.
+func (r *Report) Err() error {
+	var errs []error
+
+	for _, e := range r.Entries {
+		if e.Action == ActionFailed {
+			errs = append(errs, &EntryError{App: e.App, Entry: e.Entry, Err: e.Err})
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// summarize fills in the succeeded and failed counts of a notification
+// summary. Skipped entries count as neither.
+func (r *Report) summarize(s *RunSummary) {
+	s.Failed = r.Count(ActionFailed)
+	s.Succeeded = len(r.Entries) - s.Failed - r.Count(ActionSkipped)
+}
+
+// joinReportErr returns the error of a run that stopped with err, or
+// completed when err is nil, and recorded its entries in report.
+func joinReportErr(err error, report *Report) error {
+	if err == nil {
+		return report.Err()
+	}
+
+	return errors.Join(err, report.Err())
+}
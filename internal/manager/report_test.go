@@ -0,0 +1,140 @@
+package manager
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/AntoineGS/tidydots/internal/config"
+	"github.com/AntoineGS/tidydots/internal/platform"
+)
+
+func TestRestoreReport_ContinuesPastFailures(t *testing.T) {
+	t.Parallel()
+	skipIfNoSymlink(t)
+	tmpDir := t.TempDir()
+
+	backupRoot := filepath.Join(tmpDir, "backup")
+	if err := os.MkdirAll(filepath.Join(backupRoot, "ok"), 0750); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := &config.Config{
+		Version:    3,
+		BackupRoot: backupRoot,
+		Applications: []config.Application{
+			{
+				Name: "app",
+				Entries: []config.SubEntry{
+					{Name: "missing", Backup: "./missing", Targets: map[string]string{"linux": filepath.Join(tmpDir, "missing")}},
+					{Name: "ok", Backup: "./ok", Targets: map[string]string{"linux": filepath.Join(tmpDir, "ok")}},
+					{Name: "root", Sudo: true, Backup: "./ok", Targets: map[string]string{"linux": filepath.Join(tmpDir, "root")}},
+					{Name: "win", Backup: "./ok", Targets: map[string]string{"windows": `C:\ok`}},
+				},
+			},
+		},
+	}
+
+	mgr := New(cfg, &platform.Platform{OS: platform.OSLinux})
+	mgr.NoSudo = true
+
+	report, err := mgr.RestoreReport(context.Background())
+	if err == nil {
+		t.Fatal("RestoreReport() error = nil, want the failure of app/missing")
+	}
+
+	var entryErr *EntryError
+	if !errors.As(err, &entryErr) || entryErr.App != "app" || entryErr.Entry != "missing" {
+		t.Errorf("errors.As(*EntryError) = %+v, want app/missing", entryErr)
+	}
+
+	var actions []EntryAction
+	for _, e := range report.Entries {
+		actions = append(actions, e.Action)
+	}
+
+	want := []EntryAction{ActionFailed, ActionRestored, ActionSkipped}
+	if len(actions) != len(want) {
+		t.Fatalf("report actions = %v, want %v", actions, want)
+	}
+
+	for i := range want {
+		if actions[i] != want[i] {
+			t.Errorf("report actions = %v, want %v", actions, want)
+			break
+		}
+	}
+
+	if !testPathExists(filepath.Join(tmpDir, "ok")) {
+		t.Error("entry after the failed one should still be restored")
+	}
+}
+
+func TestBackupReport_SkipsAreNotFailures(t *testing.T) {
+	t.Parallel()
+	tmpDir := t.TempDir()
+
+	cfg := &config.Config{
+		Version:    3,
+		BackupRoot: tmpDir,
+		Applications: []config.Application{
+			{
+				Name: "app",
+				Entries: []config.SubEntry{
+					{Name: "root", Sudo: true, Backup: "./root", Targets: map[string]string{"linux": "/etc/root"}},
+				},
+			},
+		},
+	}
+
+	mgr := New(cfg, &platform.Platform{OS: platform.OSLinux})
+	mgr.NoSudo = true
+
+	report, err := mgr.BackupReport(context.Background())
+	if err != nil {
+		t.Fatalf("BackupReport() error = %v, want nil when entries are only skipped", err)
+	}
+
+	if got := report.Count(ActionSkipped); got != 1 {
+		t.Errorf("Count(ActionSkipped) = %d, want 1", got)
+	}
+}
+
+func TestReportErr(t *testing.T) {
+	sentinel := errors.New("boom")
+	report := &Report{Entries: []EntryResult{
+		{App: "a", Entry: "one", Action: ActionFailed, Err: sentinel},
+		{App: "a", Entry: "two", Action: ActionSkipped, Detail: skipReasonSudo},
+		{App: "b", Entry: "three", Action: ActionFailed, Err: errors.New("bang")},
+	}}
+
+	err := report.Err()
+	if !errors.Is(err, sentinel) {
+		t.Errorf("Err() = %v, want it to wrap the entry's error", err)
+	}
+
+	unwrapped, ok := err.(interface{ Unwrap() []error })
+	if !ok || len(unwrapped.Unwrap()) != 2 {
+		t.Errorf("Err() should join one error per failed entry, got %v", err)
+	}
+
+	if err := (&Report{}).Err(); err != nil {
+		t.Errorf("Err() of an empty report = %v, want nil", err)
+	}
+
+	if errors.Is(err, ErrMissingPrivileges) {
+		t.Errorf("Err() = %v, want no ErrMissingPrivileges without a permission error", err)
+	}
+
+	report.Entries = append(report.Entries, EntryResult{
+		App: "c", Entry: "etc", Action: ActionFailed,
+		Err: NewPathError("create symlink", "/etc/hosts", fs.ErrPermission),
+	})
+
+	if err := report.Err(); !errors.Is(err, ErrMissingPrivileges) {
+		t.Errorf("Err() = %v, want ErrMissingPrivileges for a permission error", err)
+	}
+}
@@ -0,0 +1,98 @@
+package manager
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/AntoineGS/tidydots/internal/config"
+)
+
+// orderByRequiredBy returns apps reordered so each application comes after
+// the applications its RequiredBy names, by Kahn's algorithm: of the
+// applications whose required ones are all placed, the first in apps goes
+// next, so the given order decides wherever RequiredBy does not. Names of
+// applications not in apps, such as ones filtered out on this machine, are
+// ignored. Applications requiring each other in a cycle return
+// ErrCircularDependency, listing the cycle.
+func orderByRequiredBy(apps []config.Application) ([]config.Application, error) {
+	index := make(map[string]int, len(apps))
+	for i, app := range apps {
+		index[app.Name] = i
+	}
+
+	required := make([][]int, len(apps))
+	followers := make([][]int, len(apps))
+	pending := make([]int, len(apps))
+
+	for i, app := range apps {
+		for _, name := range app.RequiredBy {
+			if j, ok := index[name]; ok && j != i {
+				required[i] = append(required[i], j)
+				followers[j] = append(followers[j], i)
+				pending[i]++
+			}
+		}
+	}
+
+	placed := make([]bool, len(apps))
+	ordered := make([]config.Application, 0, len(apps))
+
+	for len(ordered) < len(apps) {
+		next := -1
+
+		for i := range apps {
+			if !placed[i] && pending[i] == 0 {
+				next = i
+				break
+			}
+		}
+
+		if next == -1 {
+			return nil, fmt.Errorf("%w: %s", ErrCircularDependency, requiredCycle(apps, required, placed))
+		}
+
+		placed[next] = true
+		ordered = append(ordered, apps[next])
+
+		for _, f := range followers[next] {
+			pending[f]--
+		}
+	}
+
+	return ordered, nil
+}
+
+// requiredCycle returns a cycle among the applications not yet placed, each
+// of which requires an unplaced one, as their names in restore order: "a ->
+// b -> a" when a is required by b and b by a.
+func requiredCycle(apps []config.Application, required [][]int, placed []bool) string {
+	cur := slices.Index(placed, false)
+	seen := make(map[int]int)
+
+	var path []int
+
+	for {
+		if start, ok := seen[cur]; ok {
+			path = append(path[start:], cur)
+			break
+		}
+
+		seen[cur] = len(path)
+		path = append(path, cur)
+
+		for _, j := range required[cur] {
+			if !placed[j] {
+				cur = j
+				break
+			}
+		}
+	}
+
+	names := make([]string, len(path))
+	for i, app := range path {
+		names[len(path)-1-i] = apps[app].Name
+	}
+
+	return strings.Join(names, " -> ")
+}
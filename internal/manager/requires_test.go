@@ -0,0 +1,128 @@
+package manager
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/AntoineGS/tidydots/internal/config"
+)
+
+func appNames(apps []config.Application) string {
+	names := make([]string, len(apps))
+	for i, app := range apps {
+		names[i] = app.Name
+	}
+
+	return strings.Join(names, " ")
+}
+
+func TestOrderByRequiredBy(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		apps []config.Application
+		want string
+	}{
+		{
+			name: "no requirements keep their order",
+			apps: []config.Application{{Name: "a"}, {Name: "b"}, {Name: "c"}},
+			want: "a b c",
+		},
+		{
+			name: "required applications first",
+			apps: []config.Application{
+				{Name: "kitty", RequiredBy: []string{"fonts"}},
+				{Name: "zsh"},
+				{Name: "fonts"},
+			},
+			want: "zsh fonts kitty",
+		},
+		{
+			name: "chain and diamond",
+			apps: []config.Application{
+				{Name: "app", RequiredBy: []string{"left", "right"}},
+				{Name: "left", RequiredBy: []string{"base"}},
+				{Name: "right", RequiredBy: []string{"base"}},
+				{Name: "base"},
+			},
+			want: "base left right app",
+		},
+		{
+			name: "applications not restored are ignored",
+			apps: []config.Application{{Name: "kitty", RequiredBy: []string{"filtered-out"}}, {Name: "zsh"}},
+			want: "kitty zsh",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := orderByRequiredBy(tt.apps)
+			if err != nil {
+				t.Fatalf("orderByRequiredBy() error = %v", err)
+			}
+
+			if appNames(got) != tt.want {
+				t.Errorf("orderByRequiredBy() = %s, want %s", appNames(got), tt.want)
+			}
+		})
+	}
+}
+
+func TestOrderByRequiredBy_Cycle(t *testing.T) {
+	t.Parallel()
+
+	apps := []config.Application{
+		{Name: "zsh"},
+		{Name: "kitty", RequiredBy: []string{"fonts"}},
+		{Name: "fonts", RequiredBy: []string{"theme"}},
+		{Name: "theme", RequiredBy: []string{"kitty"}},
+	}
+
+	_, err := orderByRequiredBy(apps)
+	if !errors.Is(err, ErrCircularDependency) {
+		t.Fatalf("orderByRequiredBy() error = %v, want ErrCircularDependency", err)
+	}
+
+	if !strings.Contains(err.Error(), "kitty -> theme -> fonts -> kitty") {
+		t.Errorf("error = %q, want the cycle listed in restore order", err)
+	}
+}
+
+func TestRestore_OrdersByRequiredBy(t *testing.T) {
+	t.Parallel()
+
+	mgr, _ := newMemManager(t)
+	mgr.SkipSetup = true // setup entries are then reported in order without running
+
+	setup := []config.SubEntry{{Name: "setup", Run: map[string]string{"linux": "true"}, Check: map[string]string{"linux": "true"}}}
+	mgr.Config.Applications = []config.Application{
+		{Name: "kitty", Priority: 10, RequiredBy: []string{"fonts"}, Entries: setup},
+		{Name: "zsh", Priority: 5, Entries: setup},
+		{Name: "fonts", Entries: setup},
+	}
+
+	report, err := mgr.RestoreReport(context.Background())
+	if err != nil {
+		t.Fatalf("RestoreReport() error = %v", err)
+	}
+
+	var got []string
+	for _, e := range report.Entries {
+		got = append(got, e.App)
+	}
+
+	if want := "zsh fonts kitty"; strings.Join(got, " ") != want {
+		t.Errorf("restore order = %s, want %s", strings.Join(got, " "), want)
+	}
+
+	mgr.Config.Applications[2].RequiredBy = []string{"kitty"}
+
+	if err := mgr.RestoreWithContext(context.Background()); !errors.Is(err, ErrCircularDependency) {
+		t.Errorf("RestoreWithContext() with a cycle error = %v, want ErrCircularDependency", err)
+	}
+}
@@ -11,97 +11,312 @@ import (
 	"runtime"
 	"strings"
 
+	"github.com/AntoineGS/tidydots/internal/cmdexec"
 	"github.com/AntoineGS/tidydots/internal/config"
 	"github.com/AntoineGS/tidydots/internal/platform"
+	"github.com/AntoineGS/tidydots/internal/state"
 )
 
 // RestoreWithContext restores configurations with context support
 func (m *Manager) RestoreWithContext(ctx context.Context) error {
-	m = m.WithContext(ctx)
-	return m.Restore()
+	_, err := m.RestoreReport(ctx)
+	return err
 }
 
-// Restore creates symlinks from target locations to backup sources for all managed configuration files.
-//
-//nolint:dupl // similar structure to Backup, but semantically different operations
+// Restore creates symlinks from target locations to backup sources for all
+// managed configuration files, then sends the configured notifications (see
+// notify).
 func (m *Manager) Restore() error {
+	_, err := m.restoreReport()
+	return err
+}
+
+// RestoreReport restores like RestoreWithContext and also returns the outcome
+// of every entry. A failed entry does not stop the run: every selected entry
+// is attempted, and the error joins an *EntryError per failure. Only
+// cancellation ends the run early, in which case the report holds the
+// entries attempted so far.
+func (m *Manager) RestoreReport(ctx context.Context) (*Report, error) {
+	return m.WithContext(ctx).restoreReport()
+}
+
+func (m *Manager) restoreReport() (*Report, error) {
+	report := &Report{Operation: state.OpRestore}
+	run := m.BeginJournal(state.OpRestore)
+	err := joinReportErr(run.restore(report), report)
+	run.EndJournal(err == nil)
+
+	summary := m.newRunSummary(state.OpRestore)
+	report.summarize(&summary)
+	m.notify(summary, err)
+
+	return report, err
+}
+
+// restore implements Restore, recording the outcome of every entry (setup
+// entries included) in report. It only returns an error when the run was
+// canceled or the applications' required_by lists form a cycle; entry
+// failures are in the report.
+//
+//nolint:dupl // similar structure to backup, but semantically different operations
+func (m *Manager) restore(report *Report) error {
 	// Check context before starting
 	if err := m.checkContext(); err != nil {
 		return err
 	}
 
+	apps, err := orderByRequiredBy(m.applicationsByPriority())
+	if err != nil {
+		return err
+	}
+
 	m.logger.Info("starting restore",
 		slog.String("os", m.Platform.OS),
 		slog.Int("version", m.Config.Version),
 	)
 
-	apps := m.GetApplications()
+	user, deferred := restoreBatches(apps)
 
-	var errs []error
+	if m.Jobs > 1 {
+		err = m.restoreConcurrently(apps, user, report)
+	} else {
+		err = m.restoreItems(user, report)
+	}
 
-	for _, app := range apps {
-		// Check context before each application
+	if err != nil {
+		return err
+	}
+
+	return m.restoreItems(deferred, report)
+}
+
+// restoreItems restores items one after the other, in order, recording the
+// outcome of each in report. It only returns an error when the run was
+// canceled.
+func (m *Manager) restoreItems(items []restoreItem, report *Report) error {
+	// Once sudo refuses to authenticate, the rest of the sudo batch would
+	// only ask again, so it is skipped.
+	declined := false
+	app := ""
+
+	for _, item := range items {
+		// Check context before each entry
 		if err := m.checkContext(); err != nil {
 			return err
 		}
 
-		m.logger.Info("restoring application", slog.String("app", app.Name))
+		if item.app != app {
+			app = item.app
+			m.logger.Info("restoring application", slog.String("app", app))
+		}
+
+		if declined && item.entry.Sudo {
+			m.logger.Warn("skipped: elevation declined",
+				slog.String("app", item.app),
+				slog.String("entry", item.entry.Name))
+
+			report.add(EntryResult{App: item.app, Entry: item.entry.Name, Action: ActionSkipped, Detail: skipReasonElevation})
+
+			continue
+		}
+
+		result, ok := m.restorePlanned(item)
+		if !ok {
+			continue
+		}
+
+		declined = declined || result.Detail == skipReasonElevation
+
+		report.add(result)
+	}
+
+	return nil
+}
+
+// restoreItem is an entry of a restore plan, with its application.
+type restoreItem struct {
+	app   string
+	entry config.SubEntry
+}
+
+// restorePlan returns the entries of apps in the order restore runs them:
+// the order of apps and YAML order, except that sudo entries run last, as
+// one batch, so that everything needing no elevation is applied before sudo
+// asks for a password, and asks only once. A setup entry listed after a sudo
+// entry of its application still runs after that entry, once the batch is
+// done.
+func restorePlan(apps []config.Application) []restoreItem {
+	user, deferred := restoreBatches(apps)
+
+	return append(user, deferred...)
+}
+
+// restoreBatches splits the restore plan of apps in two: the entries needing
+// no elevation, and the sudo batch with the setup entries that follow it.
+func restoreBatches(apps []config.Application) (user, deferred []restoreItem) {
+	var sudo, after []restoreItem
+
+	for _, app := range apps {
+		deferred := false
 
 		for _, subEntry := range app.Entries {
-			// Check context before each entry
-			if err := m.checkContext(); err != nil {
-				return err
+			item := restoreItem{app: app.Name, entry: subEntry}
+
+			switch {
+			case subEntry.Sudo:
+				sudo = append(sudo, item)
+				deferred = true
+			case subEntry.IsSetup() && deferred:
+				after = append(after, item)
+			default:
+				user = append(user, item)
 			}
+		}
+	}
 
-			// Setup entries run their check, and their run command if it fails.
-			// They are dispatched in YAML order, so a setup entry listed after
-			// config entries runs after those entries are deployed.
-			if subEntry.IsSetup() {
-				if err := m.runSetupEntry(app.Name, subEntry); err != nil {
-					m.logger.Error("setup failed",
-						slog.String("app", app.Name),
-						slog.String("entry", subEntry.Name),
-						slog.String("error", err.Error()))
+	return user, append(sudo, after...)
+}
 
-					errs = append(errs, err)
-				}
+// restorePlanned restores one entry of the plan. It returns false for an entry
+// restore does not report: one that is neither a setup nor a config entry,
+// or has no target on this OS.
+func (m *Manager) restorePlanned(item restoreItem) (EntryResult, bool) {
+	subEntry := item.entry
+
+	// Setup entries run their check, and their run command if it fails.
+	// They are dispatched in plan order, so a setup entry listed after
+	// config entries runs after those entries are deployed.
+	if subEntry.IsSetup() {
+		result := EntryResult{App: item.app, Entry: subEntry.Name, Action: ActionSetUp}
+		if m.Offline {
+			result.Action, result.Detail = ActionSkipped, skipReasonOffline
+		}
 
-				continue
-			}
+		if m.SkipSetup {
+			result.Action, result.Detail = ActionSkipped, skipReasonSetup
+			return result, true
+		}
 
-			// Only process config entries
-			if !subEntry.IsConfig() {
-				m.logger.Debug("skipping entry",
-					slog.String("app", app.Name),
-					slog.String("entry", subEntry.Name),
-					slog.String("reason", "not a config entry"))
-				continue
-			}
+		em := m.forApp(item.app).withSteps()
+		err := em.runSetupEntry(item.app, subEntry)
+		result.Steps = em.recordedSteps()
 
-			target := subEntry.GetTarget(m.Platform.OS)
-			if target == "" {
-				m.logger.Debug("skipping entry",
-					slog.String("app", app.Name),
-					slog.String("entry", subEntry.Name),
-					slog.String("os", m.Platform.OS),
-					slog.String("reason", "no target for OS"))
-				continue
+		if err != nil {
+			if m.elevationDeclined(item.app, subEntry, err) {
+				result.Action, result.Detail = ActionSkipped, skipReasonElevation
+				return result, true
 			}
 
-			// Expand ~ and env vars in target path for file operations
-			expandedTarget := m.expandTarget(target)
+			m.logger.Error("setup failed",
+				slog.String("app", item.app),
+				slog.String("entry", subEntry.Name),
+				slog.String("error", err.Error()))
 
-			if err := m.restoreSubEntry(app.Name, subEntry, expandedTarget); err != nil {
-				m.logger.Error("restore failed",
-					slog.String("app", app.Name),
-					slog.String("entry", subEntry.Name),
-					slog.String("error", err.Error()))
-				errs = append(errs, err)
-			}
+			result.Action, result.Err = ActionFailed, err
+		}
+
+		return result, true
+	}
+
+	// Only process config entries
+	if !subEntry.IsConfig() {
+		m.logger.Debug("skipping entry",
+			slog.String("app", item.app),
+			slog.String("entry", subEntry.Name),
+			slog.String("reason", "not a config entry"))
+
+		return EntryResult{}, false
+	}
+
+	target := subEntry.GetTarget(m.Platform.OS)
+	if target == "" {
+		m.logger.Debug("skipping entry",
+			slog.String("app", item.app),
+			slog.String("entry", subEntry.Name),
+			slog.String("os", m.Platform.OS),
+			slog.String("reason", "no target for OS"))
+
+		return EntryResult{}, false
+	}
+
+	// Expand ~ and env vars in target path for file operations
+	return m.RestoreEntry(item.app, subEntry, m.expandTarget(target)), true
+}
+
+// elevationDeclined reports whether err, from restoring a sudo entry, is sudo
+// refusing to authenticate, in which case it warns that the entry is skipped.
+func (m *Manager) elevationDeclined(appName string, subEntry config.SubEntry, err error) bool {
+	if !errors.Is(err, cmdexec.ErrElevationDeclined) {
+		return false
+	}
+
+	m.logger.Warn("skipped: elevation declined",
+		slog.String("app", appName),
+		slog.String("entry", subEntry.Name),
+		slog.String("error", err.Error()))
+
+	return true
+}
+
+// RestoreEntry restores one config entry to target, an expanded target path,
+// and records it in the operation history. An entry that requires sudo is
+// skipped when NoSudo is set, or when sudo fails to authenticate, and in a
+// resumed run an entry the interrupted run restored is skipped while target
+// exists.
+func (m *Manager) RestoreEntry(appName string, subEntry config.SubEntry, target string) EntryResult {
+	result := EntryResult{App: appName, Entry: subEntry.Name}
+
+	if m.SkipsSudo(subEntry) {
+		m.logger.Warn("skipped: requires sudo",
+			slog.String("app", appName),
+			slog.String("entry", subEntry.Name))
+
+		result.Action, result.Detail = ActionSkipped, skipReasonSudo
+
+		return result
+	}
+
+	if m.resumed(appName, subEntry.Name, target) {
+		result.Action, result.Detail = ActionSkipped, skipReasonResumed
+
+		return result
+	}
+
+	em := m.forApp(appName).withSteps()
+	err := em.restoreSubEntry(appName, subEntry, target)
+
+	if err == nil {
+		if hookErr := em.runPostRestore(appName, subEntry); hookErr != nil {
+			result.Warning = hookErr.Error()
 		}
 	}
 
-	return errors.Join(errs...)
+	result.Steps = em.recordedSteps()
+
+	if err != nil && m.elevationDeclined(appName, subEntry, err) {
+		result.Action, result.Detail = ActionSkipped, skipReasonElevation
+
+		return result
+	}
+
+	if err != nil {
+		m.logger.Error("restore failed",
+			slog.String("app", appName),
+			slog.String("entry", subEntry.Name),
+			slog.String("error", err.Error()))
+
+		result.Action, result.Err = ActionFailed, err
+
+		return result
+	}
+
+	m.RecordOperation(state.OpRestore, appName, subEntry.Name)
+	m.journalEntry(appName, subEntry.Name)
+
+	result.Action = ActionRestored
+	result.Detail = fmt.Sprintf("%s -> %s", target, m.resolvePath(subEntry.Backup))
+
+	return result
 }
 
 // symlinkPointsTo checks if a symlink at 'path' points to 'expectedTarget'.
@@ -119,10 +334,50 @@ func (m *Manager) symlinkPointsTo(path, expectedTarget string) bool {
 }
 
 // createSymlink creates a symbolic link from source to target using the
-// Manager's filesystem and runner abstractions. When useSudo is true and
-// the OS supports it, the underlying ln command is executed with sudo.
+// Manager's filesystem and runner abstractions. The link is made at a temp
+// name next to target and renamed into place, so target is never left half
+// created. When useSudo is true and the OS supports it, the underlying ln and
+// mv commands are executed with sudo.
 func (m *Manager) createSymlink(source, target string, useSudo bool) error {
-	// Validate source exists
+	if err := m.checkLinkSource(source); err != nil {
+		return err
+	}
+
+	tmpPath := atomicTempPath(target)
+
+	if useSudo && runtime.GOOS != platform.OSWindows {
+		// -f replaces a temp link left behind by an interrupted run.
+		if _, err := m.runner.RunWithSudo(m.ctx, "ln", "-sfn", source, tmpPath); err != nil {
+			return err
+		}
+
+		if _, err := m.runner.RunWithSudo(m.ctx, "mv", "-f", tmpPath, target); err != nil {
+			_, _ = m.runner.RunWithSudo(m.ctx, "rm", "-f", tmpPath) //nolint:errcheck // best-effort cleanup
+			return err
+		}
+
+		return nil
+	}
+
+	// Clear a temp link left behind by an interrupted run.
+	_ = m.fs.Remove(tmpPath)
+
+	if err := m.fs.Symlink(source, tmpPath); err != nil {
+		return err
+	}
+
+	if err := m.fs.Rename(tmpPath, target); err != nil {
+		// Best-effort cleanup of the orphaned temp link.
+		_ = m.fs.Remove(tmpPath)
+		return err
+	}
+
+	return nil
+}
+
+// checkLinkSource returns a PathError when the link source does not exist or
+// cannot be accessed.
+func (m *Manager) checkLinkSource(source string) error {
 	if _, err := m.fs.Stat(source); err != nil {
 		if errors.Is(err, os.ErrNotExist) {
 			return NewPathError("restore", source, fmt.Errorf("symlink source does not exist"))
@@ -131,19 +386,66 @@ func (m *Manager) createSymlink(source, target string, useSudo bool) error {
 		return NewPathError("restore", source, fmt.Errorf("cannot access symlink source: %w", err))
 	}
 
-	if useSudo && runtime.GOOS != platform.OSWindows {
-		if _, err := m.runner.RunWithSudo(m.ctx, "ln", "-s", source, target); err != nil {
-			return err
+	return nil
+}
+
+// useJunctions reports whether folders are linked with directory junctions
+// rather than symlinks: on Windows, when symlink_compat is junction.
+// Junctions need neither Developer Mode nor admin rights.
+func (m *Manager) useJunctions() bool {
+	mode := m.SymlinkCompat
+	if mode == "" && m.Config != nil {
+		mode = m.Config.SymlinkCompat
+	}
+
+	return mode == config.SymlinkCompatJunction && runtime.GOOS == platform.OSWindows
+}
+
+// createFolderLink links the target folder to source, with a directory
+// junction when useJunctions says so and a symlink otherwise.
+func (m *Manager) createFolderLink(source, target string, useSudo bool) error {
+	if !m.useJunctions() {
+		return m.createSymlink(source, target, useSudo)
+	}
+
+	if err := m.checkLinkSource(source); err != nil {
+		return err
+	}
+
+	// mklink is a cmd builtin, not an executable.
+	res, err := m.runner.Run(m.ctx, "cmd", "/c", "mklink", "/J", target, source)
+	if err != nil {
+		if msg := strings.TrimSpace(string(res.Stdout) + string(res.Stderr)); msg != "" {
+			err = fmt.Errorf("%w: %s", err, msg)
 		}
-		return nil
+
+		return NewPathError("restore", target, fmt.Errorf("creating junction: %w", err))
 	}
 
-	return m.fs.Symlink(source, target)
+	return nil
 }
 
-func (m *Manager) restoreSubEntry(_ string, subEntry config.SubEntry, target string) error {
+func (m *Manager) restoreSubEntry(appName string, subEntry config.SubEntry, target string) error {
+	if err := m.checkPathTemplates(subEntry); err != nil {
+		return err
+	}
+
+	if err := m.checkTargetSafety(subEntry, target); err != nil {
+		return NewPathError("restore", target, err)
+	}
+
 	backupPath := m.resolvePath(subEntry.Backup)
 
+	if subEntry.Verify {
+		if err := m.checkEntryIntegrity(subEntry, backupPath); err != nil {
+			return err
+		}
+	}
+
+	if subEntry.LinksFiles() {
+		return m.RestoreLinkFarm(appName, subEntry, backupPath, target)
+	}
+
 	if subEntry.IsFolder() {
 		// Check if folder contains template files
 		if m.hasTemplateFiles(backupPath) {
@@ -162,12 +464,18 @@ func (m *Manager) RestoreFolder(subEntry config.SubEntry, source, target string)
 	// Check if already a symlink pointing to the correct source
 	if m.symlinkPointsTo(target, source) {
 		m.logger.Debug("already a symlink", slog.String("path", target))
+		m.step(StepLink, source, target, ConflictUnchanged)
+
 		return nil
 	}
 
+	conflict := ConflictNone
+
 	// If it's a symlink but points to wrong location, remove it
 	if m.isSymlink(target) {
 		m.logger.Info("removing incorrect symlink", slog.String("path", target))
+		conflict = ConflictRelink
+
 		if !m.DryRun {
 			if err := m.fs.Remove(target); err != nil {
 				return NewPathError("restore", target, fmt.Errorf("removing incorrect symlink: %w", err))
@@ -200,12 +508,15 @@ func (m *Manager) RestoreFolder(subEntry config.SubEntry, source, target string)
 					strings.Join(fileList, ", ")))
 			}
 			// ForceDelete is true, skip to removal logic below
+			conflict = ConflictReplace
 		} else {
 			// Merge target into backup
 			m.logger.Info("merging existing content into backup",
 				slog.String("target", target),
 				slog.String("backup", source))
 
+			conflict = ConflictMerge
+
 			if !m.DryRun {
 				summary := NewMergeSummary(subEntry.Name)
 				if err := m.MergeFolder(source, target, subEntry.Sudo, summary); err != nil {
@@ -247,6 +558,8 @@ func (m *Manager) RestoreFolder(subEntry config.SubEntry, source, target string)
 			slog.String("from", target),
 			slog.String("to", source))
 
+		conflict = ConflictAdopt
+
 		if !m.DryRun {
 			backupParent := filepath.Dir(source)
 			if !m.pathExists(backupParent) {
@@ -313,8 +626,10 @@ func (m *Manager) RestoreFolder(subEntry config.SubEntry, source, target string)
 		slog.String("target", target),
 		slog.String("source", source))
 
+	m.step(StepLink, source, target, conflict)
+
 	if !m.DryRun {
-		return m.createSymlink(source, target, subEntry.Sudo)
+		return m.createFolderLink(source, target, subEntry.Sudo)
 	}
 
 	return nil
@@ -348,8 +663,13 @@ func (m *Manager) RestoreFiles(subEntry config.SubEntry, source, target string)
 		}
 	}
 
-	for _, file := range subEntry.Files {
-		srcFile := filepath.Join(source, file)
+	files, err := m.entryFiles(subEntry, source)
+	if err != nil {
+		return NewPathError("restore", source, err)
+	}
+
+	for _, file := range files {
+		srcFile := filepath.Join(source, subEntry.BackupName(file))
 		dstFile := filepath.Join(target, file)
 
 		if subEntry.IsCopy() {
@@ -359,15 +679,27 @@ func (m *Manager) RestoreFiles(subEntry config.SubEntry, source, target string)
 			continue
 		}
 
+		// A link to a pointer would deploy the pointer, not the file.
+		if _, ok := m.readPointer(srcFile); ok {
+			return NewPathError("restore", srcFile, errors.New(
+				"backup is a dedupe object pointer, which only copy entries can deploy; set method: copy"))
+		}
+
 		// Check if already a symlink pointing to correct source
 		if m.symlinkPointsTo(dstFile, srcFile) {
 			m.logger.Debug("already a symlink", slog.String("path", dstFile))
+			m.step(StepLink, srcFile, dstFile, ConflictUnchanged)
+
 			continue
 		}
 
+		conflict := ConflictNone
+
 		// If it's a symlink but points to wrong location, remove it
 		if m.isSymlink(dstFile) {
 			m.logger.Info("removing incorrect symlink", slog.String("path", dstFile))
+			conflict = ConflictRelink
+
 			if !m.DryRun {
 				if err := m.fs.Remove(dstFile); err != nil {
 					return NewPathError("restore", dstFile, fmt.Errorf("removing incorrect symlink: %w", err))
@@ -383,15 +715,18 @@ func (m *Manager) RestoreFiles(subEntry config.SubEntry, source, target string)
 						"target file exists. Use merge mode or --force to proceed"))
 				}
 				// ForceDelete is true, skip to removal logic below
+				conflict = ConflictReplace
 			} else {
 				// Merge target file into backup
 				m.logger.Info("merging existing file into backup",
 					slog.String("target", dstFile),
 					slog.String("backup", srcFile))
 
+				conflict = ConflictMerge
+
 				if !m.DryRun {
 					summary := NewMergeSummary(subEntry.Name)
-					if err := m.mergeFile(dstFile, source, file, subEntry.Sudo, summary); err != nil {
+					if err := m.mergeFile(dstFile, source, subEntry.BackupName(file), subEntry.Sudo, summary); err != nil {
 						return NewPathError("restore", dstFile, fmt.Errorf("merging file: %w", err))
 					}
 
@@ -418,6 +753,8 @@ func (m *Manager) RestoreFiles(subEntry config.SubEntry, source, target string)
 				slog.String("from", dstFile),
 				slog.String("to", srcFile))
 
+			conflict = ConflictAdopt
+
 			if !m.DryRun {
 				if subEntry.Sudo {
 					if _, err := m.runner.RunWithSudo(m.ctx, "mv", dstFile, srcFile); err != nil {
@@ -466,7 +803,16 @@ func (m *Manager) RestoreFiles(subEntry config.SubEntry, source, target string)
 			slog.String("target", dstFile),
 			slog.String("source", srcFile))
 
+		m.step(StepLink, srcFile, dstFile, conflict)
+
 		if !m.DryRun {
+			// A pattern such as themes/* matches files in a subdirectory.
+			if dir := filepath.Dir(dstFile); dir != target && !subEntry.Sudo {
+				if err := m.fs.MkdirAll(dir, DirPerms); err != nil {
+					return NewPathError("restore", dir, fmt.Errorf("creating target directory: %w", err))
+				}
+			}
+
 			if err := m.createSymlink(srcFile, dstFile, subEntry.Sudo); err != nil {
 				return NewPathError("restore", dstFile, fmt.Errorf("creating symlink: %w", err))
 			}
@@ -0,0 +1,98 @@
+package manager
+
+import (
+	"log/slog"
+	"sync"
+
+	"github.com/AntoineGS/tidydots/internal/config"
+)
+
+// restoreConcurrently restores items, the entries of apps needing no
+// elevation, working on up to Jobs applications at a time. The entries of an
+// application are restored in order, and an application waits until the
+// applications of a higher priority and those its RequiredBy names are done.
+// Setup entries and post_restore commands still run one at a time: their
+// commands commonly call a package manager, and pacman, apt and dnf lock
+// their database. Results are added to report in plan order.
+//
+// apps must be in the order orderByRequiredBy returns, so an application only
+// ever waits for one started before it.
+func (m *Manager) restoreConcurrently(apps []config.Application, items []restoreItem, report *Report) error {
+	m2 := *m
+	m2.setupMu = &sync.Mutex{}
+	m = &m2
+
+	index := make(map[string]int, len(apps))
+	for i, app := range apps {
+		index[app.Name] = i
+	}
+
+	groups := make([][]restoreItem, len(apps))
+	for _, item := range items {
+		i := index[item.app]
+		groups[i] = append(groups[i], item)
+	}
+
+	results := make([][]EntryResult, len(apps))
+	done := make([]chan struct{}, len(apps))
+	sem := make(chan struct{}, m.Jobs)
+
+	var wg sync.WaitGroup
+
+	for i, app := range apps {
+		done[i] = make(chan struct{})
+
+		sem <- struct{}{}
+
+		wg.Go(func() {
+			defer func() { <-sem }()
+			defer close(done[i])
+
+			for j := range i {
+				if apps[j].Priority > app.Priority {
+					<-done[j]
+				}
+			}
+
+			for _, name := range app.RequiredBy {
+				if j, ok := index[name]; ok && j < i {
+					<-done[j]
+				}
+			}
+
+			if len(groups[i]) > 0 {
+				m.logger.Info("restoring application", slog.String("app", app.Name))
+			}
+
+			for _, item := range groups[i] {
+				if m.checkContext() != nil {
+					return
+				}
+
+				if item.entry.IsSetup() {
+					m.setupMu.Lock()
+				}
+
+				result, ok := m.restorePlanned(item)
+
+				if item.entry.IsSetup() {
+					m.setupMu.Unlock()
+				}
+
+				if ok {
+					results[i] = append(results[i], result)
+				}
+			}
+		})
+	}
+
+	wg.Wait()
+
+	for _, appResults := range results {
+		for _, result := range appResults {
+			report.add(result)
+		}
+	}
+
+	return m.checkContext()
+}
@@ -0,0 +1,73 @@
+package manager
+
+import (
+	"context"
+	"slices"
+	"testing"
+
+	"github.com/AntoineGS/tidydots/internal/config"
+)
+
+func TestRestore_Jobs(t *testing.T) {
+	t.Parallel()
+
+	run := func(jobs int) []string {
+		mgr, mem := newMemManager(t)
+		mgr.Jobs = jobs
+		mgr.SkipSetup = true
+
+		entry := func(name string) config.SubEntry {
+			return config.SubEntry{
+				Name:    name,
+				Backup:  "./" + name,
+				Files:   []string{"f"},
+				Targets: map[string]string{"linux": "/target/" + name},
+			}
+		}
+
+		setup := config.SubEntry{Name: "setup", Run: map[string]string{"linux": "true"}, Check: map[string]string{"linux": "true"}}
+
+		mgr.Config.Version = 3
+		mgr.Config.Applications = []config.Application{
+			{Name: "kitty", RequiredBy: []string{"fonts"}, Entries: []config.SubEntry{entry("kitty"), setup}},
+			{Name: "zsh", Entries: []config.SubEntry{entry("zsh"), entry("zsh-env")}},
+			{Name: "fonts", Entries: []config.SubEntry{entry("fonts")}},
+			{Name: "git", Entries: []config.SubEntry{entry("git")}},
+		}
+
+		for _, name := range []string{"kitty", "zsh", "zsh-env", "fonts", "git"} {
+			_ = mem.MkdirAll("/backup/"+name, 0o755)
+			_ = mem.WriteFile("/backup/"+name+"/f", []byte(name), 0o644)
+			_ = mem.MkdirAll("/target/"+name, 0o755)
+		}
+
+		report, err := mgr.RestoreReport(context.Background())
+		if err != nil {
+			t.Fatalf("RestoreReport() with %d jobs error = %v", jobs, err)
+		}
+
+		for _, name := range []string{"kitty", "zsh", "zsh-env", "fonts", "git"} {
+			if target, err := mem.Readlink("/target/" + name + "/f"); err != nil || target != "/backup/"+name+"/f" {
+				t.Errorf("%d jobs: %s link = (%q, %v), want /backup/%s/f", jobs, name, target, err, name)
+			}
+		}
+
+		var got []string
+		for _, res := range report.Entries {
+			got = append(got, res.Name()+" "+string(res.Action))
+		}
+
+		return got
+	}
+
+	// Restoring applications concurrently reports them in the order a
+	// sequential restore does.
+	sequential, concurrent := run(1), run(4)
+	if !slices.Equal(sequential, concurrent) {
+		t.Errorf("report with 4 jobs =\n%v\nwant the sequential one\n%v", concurrent, sequential)
+	}
+
+	if want := "zsh/zsh restored"; len(sequential) != 6 || sequential[0] != want {
+		t.Errorf("sequential report = %v, want 6 entries starting with %s", sequential, want)
+	}
+}
@@ -0,0 +1,156 @@
+package manager
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/AntoineGS/tidydots/internal/cmdexec"
+	"github.com/AntoineGS/tidydots/internal/config"
+)
+
+func TestRestorePlan_SudoEntriesLast(t *testing.T) {
+	t.Parallel()
+
+	setup := func(name string) config.SubEntry {
+		return config.SubEntry{Name: name, Run: map[string]string{"linux": "true"}}
+	}
+
+	apps := []config.Application{
+		{Name: "a", Entries: []config.SubEntry{
+			{Name: "cfg", Backup: "./a"},
+			setup("before"),
+			{Name: "sys", Backup: "./a-sys", Sudo: true},
+			setup("after"),
+		}},
+		{Name: "b", Entries: []config.SubEntry{
+			{Name: "sys", Backup: "./b-sys", Sudo: true},
+			{Name: "cfg", Backup: "./b"},
+		}},
+		{Name: "c", Entries: []config.SubEntry{setup("setup")}},
+	}
+
+	var got []string
+	for _, item := range restorePlan(apps) {
+		got = append(got, item.app+"/"+item.entry.Name)
+	}
+
+	// User entries keep priority and YAML order, the sudo entries follow as
+	// one batch, and a setup entry listed after a sudo entry comes last.
+	want := "a/cfg a/before b/cfg c/setup a/sys b/sys a/after"
+	if strings.Join(got, " ") != want {
+		t.Errorf("plan = %s, want %s", strings.Join(got, " "), want)
+	}
+}
+
+func TestRestore_ElevationDeclinedSkipsSudoBatch(t *testing.T) {
+	t.Parallel()
+	skipIfNoSudo(t)
+
+	mgr, mem, stub := newSudoManager(t)
+
+	entry := func(name string, sudo bool) config.SubEntry {
+		return config.SubEntry{
+			Name:    name,
+			Backup:  "./" + name,
+			Files:   []string{"f"},
+			Sudo:    sudo,
+			Targets: map[string]string{"linux": "/target/" + name},
+		}
+	}
+
+	mgr.Config.Version = 3
+	mgr.Config.Applications = []config.Application{
+		{Name: "sys", Entries: []config.SubEntry{entry("first", true), entry("second", true)}},
+		{Name: "user", Entries: []config.SubEntry{entry("shell", false)}},
+	}
+
+	for _, name := range []string{"first", "second", "shell"} {
+		_ = mem.MkdirAll("/backup/"+name, 0o755)
+		_ = mem.WriteFile("/backup/"+name+"/f", []byte(name), 0o644)
+		_ = mem.MkdirAll("/target/"+name, 0o755)
+	}
+
+	stub.AddError("ln", fmt.Errorf("%w: exit status 1", cmdexec.ErrElevationDeclined))
+
+	report, err := mgr.RestoreReport(context.Background())
+	if err != nil {
+		t.Fatalf("RestoreReport() error = %v, want declined elevation reported as skips", err)
+	}
+
+	want := []string{
+		"user/shell " + string(ActionRestored),
+		"sys/first " + string(ActionSkipped) + ": " + skipReasonElevation,
+		"sys/second " + string(ActionSkipped) + ": " + skipReasonElevation,
+	}
+
+	var got []string
+	for _, res := range report.Entries {
+		line := res.App + "/" + res.Entry + " " + string(res.Action)
+		if res.Action == ActionSkipped {
+			line += ": " + res.Detail
+		}
+
+		got = append(got, line)
+	}
+
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("report =\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+
+	// sudo is asked once; the second sudo entry is not attempted.
+	if len(stub.Calls) != 1 {
+		t.Errorf("expected only the declined ln call, got %+v", stub.Calls)
+	}
+
+	if target, err := mem.Readlink("/target/shell/f"); err != nil || target != "/backup/shell/f" {
+		t.Errorf("user entry link = (%q, %v), want /backup/shell/f", target, err)
+	}
+}
+
+func TestCreateSymlink_ReplacesAtomically(t *testing.T) {
+	t.Parallel()
+
+	mgr, mem := newMemManager(t)
+	_ = mem.MkdirAll("/backup", 0o755)
+	_ = mem.MkdirAll("/home", 0o755)
+	_ = mem.WriteFile("/backup/f", []byte("x"), 0o644)
+	_ = mem.WriteFile("/home/f", []byte("old"), 0o644)
+	_ = mem.Symlink("/elsewhere", "/home/.f.tidydots-tmp") // left by a crash
+
+	if err := mgr.createSymlink("/backup/f", "/home/f", false); err != nil {
+		t.Fatalf("createSymlink: %v", err)
+	}
+
+	if target, err := mem.Readlink("/home/f"); err != nil || target != "/backup/f" {
+		t.Errorf("link = (%q, %v), want /backup/f", target, err)
+	}
+
+	if _, err := mem.Lstat("/home/.f.tidydots-tmp"); err == nil {
+		t.Error("temp link left behind after createSymlink")
+	}
+}
+
+func TestCreateSymlink_Sudo_LinksAtTempThenMoves(t *testing.T) {
+	t.Parallel()
+	skipIfNoSudo(t)
+
+	mgr, mem, stub := newSudoManager(t)
+	_ = mem.MkdirAll("/backup", 0o755)
+	_ = mem.WriteFile("/backup/f", []byte("x"), 0o644)
+
+	if err := mgr.createSymlink("/backup/f", "/etc/f", true); err != nil {
+		t.Fatalf("createSymlink sudo: %v", err)
+	}
+
+	var got []string
+	for _, call := range stub.Calls {
+		got = append(got, call.Name+" "+strings.Join(call.Args, " "))
+	}
+
+	want := "ln -sfn /backup/f /etc/.f.tidydots-tmp; mv -f /etc/.f.tidydots-tmp /etc/f"
+	if strings.Join(got, "; ") != want {
+		t.Errorf("calls = %s, want %s", strings.Join(got, "; "), want)
+	}
+}
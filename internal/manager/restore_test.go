@@ -1,6 +1,7 @@
 package manager
 
 import (
+	"cmp"
 	"errors"
 	"os"
 	"path/filepath"
@@ -8,6 +9,7 @@ import (
 	"strings"
 	"testing"
 
+	"github.com/AntoineGS/tidydots/internal/cmdexec"
 	"github.com/AntoineGS/tidydots/internal/config"
 	"github.com/AntoineGS/tidydots/internal/platform"
 )
@@ -1270,6 +1272,49 @@ func TestRestoreV3_SkipsWrongOS(t *testing.T) {
 	}
 }
 
+func TestRestoreV3_NoSudoSkipsSudoEntries(t *testing.T) {
+	t.Parallel()
+	skipIfNoSymlink(t)
+	tmpDir := t.TempDir()
+
+	backupRoot := filepath.Join(tmpDir, "backup")
+	for _, dir := range []string{"user", "root"} {
+		if err := os.MkdirAll(filepath.Join(backupRoot, dir), 0750); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	cfg := &config.Config{
+		Version:    3,
+		BackupRoot: backupRoot,
+		Applications: []config.Application{
+			{
+				Name: "test-app",
+				Entries: []config.SubEntry{
+					{Name: "user", Backup: "./user", Targets: map[string]string{"linux": filepath.Join(tmpDir, "user")}},
+					{Name: "root", Sudo: true, Backup: "./root", Targets: map[string]string{"linux": filepath.Join(tmpDir, "root")}},
+				},
+			},
+		},
+	}
+
+	stub := cmdexec.NewStubRunner()
+	mgr := New(cfg, &platform.Platform{OS: platform.OSLinux}).WithRunner(stub)
+	mgr.NoSudo = true
+
+	if err := mgr.Restore(); err != nil {
+		t.Fatalf("Restore() error = %v", err)
+	}
+
+	if !testPathExists(filepath.Join(tmpDir, "user")) {
+		t.Error("entry without sudo should be restored")
+	}
+
+	if testPathExists(filepath.Join(tmpDir, "root")) || len(stub.Calls) != 0 {
+		t.Errorf("sudo entry should be skipped, calls = %+v", stub.Calls)
+	}
+}
+
 func TestRestoreFiles_SourceMissing(t *testing.T) {
 	t.Parallel()
 	skipIfNoSymlink(t)
@@ -1852,3 +1897,98 @@ func TestRestoreFiles_OnlyMergesListedFiles(t *testing.T) {
 		t.Error("conflict file (config_target_*.txt) should be created for the merged file")
 	}
 }
+
+func TestRestoreEntry_PostRestore(t *testing.T) {
+	t.Parallel()
+	skipIfNoSymlink(t)
+
+	tests := []struct {
+		name        string
+		os          string
+		dryRun      bool
+		offline     bool
+		skipSetup   bool
+		exitCode    int
+		wantCalls   int
+		wantCommand string
+		wantSkipped string
+		wantWarning string
+	}{
+		{name: "succeeds", wantCalls: 1, wantCommand: "fc-cache -f"},
+		{name: "fails", exitCode: 1, wantCalls: 1, wantCommand: "fc-cache -f", wantWarning: "post_restore failed (exit 1)"},
+		{name: "dry run", dryRun: true, wantCommand: "fc-cache -f"},
+		{name: "offline", offline: true, wantCommand: "fc-cache -f", wantSkipped: skipReasonOffline},
+		{name: "target os", os: platform.OSWindows, skipSetup: true, wantCommand: "refresh-fonts", wantSkipped: skipReasonSetup},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			tmpDir := t.TempDir()
+
+			backupRoot := filepath.Join(tmpDir, "repo")
+			if err := os.MkdirAll(filepath.Join(backupRoot, "fonts"), 0750); err != nil {
+				t.Fatal(err)
+			}
+
+			stub := cmdexec.NewStubRunner()
+			stub.AddResult("sh", cmdexec.Result{ExitCode: tt.exitCode})
+
+			osType := cmp.Or(tt.os, platform.OSLinux)
+			mgr := New(&config.Config{BackupRoot: backupRoot}, &platform.Platform{OS: osType}).WithRunner(stub)
+			mgr.DryRun = tt.dryRun
+			mgr.Offline = tt.offline
+			mgr.SkipSetup = tt.skipSetup
+
+			subEntry := config.SubEntry{
+				Name:        "local",
+				Backup:      "./fonts",
+				PostRestore: map[string]string{"linux": "fc-cache -f", "windows": "refresh-fonts"},
+			}
+
+			result := mgr.RestoreEntry("fonts", subEntry, filepath.Join(tmpDir, "target"))
+			if result.Action != ActionRestored {
+				t.Fatalf("RestoreEntry() action = %q (%v), want restored even when the hook fails", result.Action, result.Err)
+			}
+
+			if result.Warning != tt.wantWarning {
+				t.Errorf("RestoreEntry() warning = %q, want %q", result.Warning, tt.wantWarning)
+			}
+
+			if len(stub.Calls) != tt.wantCalls {
+				t.Fatalf("RestoreEntry() ran %d commands %+v, want %d", len(stub.Calls), stub.Calls, tt.wantCalls)
+			}
+
+			if tt.wantCalls > 0 && stub.Calls[0].Args[1] != "fc-cache -f" {
+				t.Errorf("RestoreEntry() ran %q, want the linux post_restore", stub.Calls[0].Args[1])
+			}
+
+			last := result.Steps[len(result.Steps)-1]
+			if last.Op != StepRun || last.Source != tt.wantCommand || last.Skipped != tt.wantSkipped {
+				t.Errorf("RestoreEntry() last step = %+v, want the post_restore run %q skipped for %q", last, tt.wantCommand, tt.wantSkipped)
+			}
+		})
+	}
+}
+
+func TestRestoreEntry_PostRestoreNotRunOnFailure(t *testing.T) {
+	t.Parallel()
+	tmpDir := t.TempDir()
+
+	stub := cmdexec.NewStubRunner()
+	mgr := New(&config.Config{BackupRoot: filepath.Join(tmpDir, "repo")}, &platform.Platform{OS: platform.OSLinux}).WithRunner(stub)
+
+	subEntry := config.SubEntry{
+		Name:        "local",
+		Backup:      "./missing",
+		PostRestore: map[string]string{"linux": "fc-cache -f"},
+	}
+
+	if result := mgr.RestoreEntry("fonts", subEntry, filepath.Join(tmpDir, "target")); result.Action != ActionFailed {
+		t.Fatalf("RestoreEntry() action = %q, want failed for a missing backup", result.Action)
+	}
+
+	if calls := shellCalls(stub); len(calls) != 0 {
+		t.Errorf("post_restore ran after a failed restore: %+v", calls)
+	}
+}
@@ -0,0 +1,144 @@
+package manager
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/AntoineGS/tidydots/internal/config"
+)
+
+// maxLinkHops is how many symlinks followLinks follows before giving up,
+// as Linux does for a path.
+const maxLinkHops = 40
+
+// UnsafeTarget is a config entry that restore refuses because of where its
+// target resolves; see checkTargetSafety.
+type UnsafeTarget struct {
+	Err   error
+	App   string
+	Entry string
+}
+
+// String describes the target for a report.
+func (u UnsafeTarget) String() string {
+	return fmt.Sprintf("%s/%s: %v", u.App, u.Entry, u.Err)
+}
+
+// UnsafeTargets lists the config entries of the current platform whose
+// target restore refuses.
+func (m *Manager) UnsafeTargets() []UnsafeTarget {
+	var unsafe []UnsafeTarget
+
+	for _, app := range m.applicationsByPriority() {
+		for _, subEntry := range app.Entries {
+			target := subEntry.GetTarget(m.Platform.OS)
+			if !subEntry.IsConfig() || target == "" {
+				continue
+			}
+
+			if err := m.checkTargetSafety(subEntry, m.expandTarget(target)); err != nil {
+				unsafe = append(unsafe, UnsafeTarget{App: app.Name, Entry: subEntry.Name, Err: err})
+			}
+		}
+	}
+
+	return unsafe
+}
+
+// checkTargetSafety returns an error when restoring subEntry to target, an
+// expanded target path, could damage the system or the repository. The
+// target's parents are resolved through their symlinks first. A folder entry
+// may not target a system directory or the home directory without
+// allow_dangerous, and no entry may target a path in the repository, which
+// linking to its backup would make a cycle of; nor may a folder entry target
+// a directory holding the repository, which restore would replace.
+func (m *Manager) checkTargetSafety(subEntry config.SubEntry, target string) error {
+	target = filepath.Clean(target)
+	resolved := filepath.Join(m.followLinks(filepath.Dir(target)), filepath.Base(target))
+	home := config.ExpandPath("~", m.Platform.EnvVars)
+
+	if reason := subEntry.DangerousTarget(target, home); reason != "" {
+		return fmt.Errorf("target %s is %s; set allow_dangerous: true to restore there", target, reason)
+	}
+
+	if reason := subEntry.DangerousTarget(resolved, m.followLinks(home)); reason != "" {
+		return fmt.Errorf("target %s resolves to %s, %s; set allow_dangerous: true to restore there", target, resolved, reason)
+	}
+
+	if m.Config.BackupRoot == "" {
+		return nil
+	}
+
+	repo := m.followLinks(config.ExpandPath(m.Config.BackupRoot, m.Platform.EnvVars))
+
+	if resolved == repo || config.IsWithin(resolved, repo) {
+		return fmt.Errorf("target %s resolves to %s, inside the repository %s", target, resolved, repo)
+	}
+
+	if subEntry.IsFolder() && !subEntry.LinksFiles() && config.IsWithin(repo, resolved) {
+		return fmt.Errorf("target %s resolves to %s, which holds the repository %s", target, resolved, repo)
+	}
+
+	return nil
+}
+
+// followLinks returns path, cleaned, with the symlinks among it and its
+// parents followed as far as they exist. It gives up after maxLinkHops
+// links, which only a loop of links takes.
+func (m *Manager) followLinks(path string) string {
+	path = filepath.Clean(path)
+
+	for range maxLinkHops {
+		next, ok := m.followFirstLink(path)
+		if !ok {
+			break
+		}
+
+		path = next
+	}
+
+	return path
+}
+
+// followFirstLink returns path with the first symlink from its root replaced
+// by where the link points, or false when none of its existing parts is a
+// symlink.
+func (m *Manager) followFirstLink(path string) (string, bool) {
+	vol := filepath.VolumeName(path)
+	parts := strings.Split(path[len(vol):], string(filepath.Separator))
+
+	dir := vol
+	if filepath.IsAbs(path) {
+		dir += string(filepath.Separator)
+	}
+
+	for i, part := range parts {
+		if part == "" {
+			continue
+		}
+
+		next := filepath.Join(dir, part)
+
+		if _, err := m.fs.Lstat(next); err != nil {
+			return "", false
+		}
+
+		if m.isSymlink(next) {
+			link, err := m.fs.Readlink(next)
+			if err != nil {
+				return "", false
+			}
+
+			if !filepath.IsAbs(link) {
+				link = filepath.Join(dir, link)
+			}
+
+			return filepath.Join(append([]string{link}, parts[i+1:]...)...), true
+		}
+
+		dir = next
+	}
+
+	return "", false
+}
@@ -0,0 +1,129 @@
+package manager
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/AntoineGS/tidydots/internal/config"
+	"github.com/AntoineGS/tidydots/internal/fsys"
+)
+
+// newSafetyManager returns a MemFS manager whose home is /home/user, with
+// /home/user/sysconf a symlink to / and /home/user/dots one to the
+// repository, /backup.
+func newSafetyManager(t *testing.T) (*Manager, *fsys.MemFS) {
+	t.Helper()
+
+	mgr, mem := newMemManager(t)
+	mgr.Platform.EnvVars[config.HomeVar] = "/home/user"
+
+	for _, dir := range []string{"/home/user", "/backup/nvim", "/etc"} {
+		if err := mem.MkdirAll(dir, 0o755); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := mem.Symlink("/", "/home/user/sysconf"); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := mem.Symlink("../../backup", "/home/user/dots"); err != nil {
+		t.Fatal(err)
+	}
+
+	return mgr, mem
+}
+
+func TestCheckTargetSafety(t *testing.T) {
+	t.Parallel()
+
+	folder := config.SubEntry{Name: "nvim", Backup: "./nvim"}
+	files := config.SubEntry{Name: "hosts", Backup: "./hosts", Files: []string{"hosts"}}
+	allowed := folder
+	allowed.AllowDangerous = true
+
+	tests := []struct {
+		name    string
+		entry   config.SubEntry
+		target  string
+		wantErr string
+	}{
+		{"config dir", folder, "/home/user/.config/nvim", ""},
+		{"system dir", folder, "/etc", "a system directory"},
+		{"system dir allowed", allowed, "/etc", ""},
+		{"files into system dir", files, "/etc", ""},
+		{"home", folder, "/home/user", "the home directory"},
+		{"dot-dot to system dir", folder, "/home/user/../../usr", "a system directory"},
+		{"symlinked parent to system dir", folder, "/home/user/sysconf/etc", "resolves to /etc, a system directory"},
+		{"symlinked parent into repo", folder, "/home/user/dots/nvim", "inside the repository /backup"},
+		{"dot-dot into repo", files, "/home/user/../../backup/hosts", "inside the repository /backup"},
+		{"repo itself", files, "/backup", "inside the repository"},
+		{"folder holding repo", allowed, "/", "holds the repository"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			mgr, _ := newSafetyManager(t)
+
+			err := mgr.checkTargetSafety(tt.entry, tt.target)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("checkTargetSafety(%q) = %v, want nil", tt.target, err)
+				}
+
+				return
+			}
+
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("checkTargetSafety(%q) = %v, want error containing %q", tt.target, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestCheckTargetSafety_RestoredLinkIsSafe(t *testing.T) {
+	t.Parallel()
+
+	mgr, mem := newSafetyManager(t)
+
+	// The target itself is the link restore made; only its parents count.
+	if err := mem.MkdirAll("/home/user/.config", 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := mem.Symlink("/backup/nvim", "/home/user/.config/nvim"); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := mgr.checkTargetSafety(config.SubEntry{Name: "nvim", Backup: "./nvim"}, "/home/user/.config/nvim"); err != nil {
+		t.Errorf("checkTargetSafety(restored link) = %v, want nil", err)
+	}
+}
+
+func TestRestore_RefusesUnsafeTarget(t *testing.T) {
+	t.Parallel()
+
+	mgr, mem := newSafetyManager(t)
+	mgr.Config.Applications = []config.Application{{Name: "sys", Entries: []config.SubEntry{
+		{Name: "etc", Backup: "./nvim", Targets: map[string]string{"linux": "~/sysconf/etc"}},
+	}}}
+
+	if err := mgr.Restore(); err == nil || !strings.Contains(err.Error(), "a system directory") {
+		t.Errorf("Restore() error = %v, want the target refused", err)
+	}
+
+	if mgr.isSymlink("/etc") {
+		t.Error("Restore() replaced /etc with a symlink")
+	}
+
+	if _, err := mem.Stat("/etc"); err != nil {
+		t.Errorf("/etc is gone: %v", err)
+	}
+
+	unsafe := mgr.UnsafeTargets()
+	if len(unsafe) != 1 || unsafe[0].App != "sys" || unsafe[0].Entry != "etc" {
+		t.Errorf("UnsafeTargets() = %v, want sys/etc", unsafe)
+	}
+}
@@ -0,0 +1,52 @@
+package manager
+
+import (
+	"slices"
+	"strings"
+
+	"github.com/AntoineGS/tidydots/internal/config"
+)
+
+// SetApplicationFilter keeps only the applications of m.Config named in
+// names, dropping the others from Config.Applications in place. Names match
+// case-insensitively, as a part of the application name, or as the whole
+// name when ExactSelect is set. It returns the names that match no
+// application. An empty names keeps every application.
+func (m *Manager) SetApplicationFilter(names []string) (missing []string) {
+	if len(names) == 0 {
+		return nil
+	}
+
+	matched := make([]bool, len(names))
+
+	m.Config.Applications = slices.DeleteFunc(m.Config.Applications, func(app config.Application) bool {
+		drop := true
+
+		for i, name := range names {
+			if m.selectsApplication(name, app.Name) {
+				matched[i] = true
+				drop = false
+			}
+		}
+
+		return drop
+	})
+
+	for i, name := range names {
+		if !matched[i] {
+			missing = append(missing, name)
+		}
+	}
+
+	return missing
+}
+
+// selectsApplication reports whether the --select name picks the application
+// named appName.
+func (m *Manager) selectsApplication(name, appName string) bool {
+	if m.ExactSelect {
+		return strings.EqualFold(name, appName)
+	}
+
+	return strings.Contains(strings.ToLower(appName), strings.ToLower(name))
+}
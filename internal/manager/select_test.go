@@ -0,0 +1,50 @@
+package manager
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/AntoineGS/tidydots/internal/config"
+	"github.com/AntoineGS/tidydots/internal/platform"
+)
+
+func TestSetApplicationFilter(t *testing.T) {
+	tests := []struct {
+		name        string
+		names       []string
+		exact       bool
+		wantApps    []string
+		wantMissing []string
+	}{
+		{"no names keeps all", nil, false, []string{"neovim", "nvim-lsp", "zsh"}, nil},
+		{"partial", []string{"VIM"}, false, []string{"neovim", "nvim-lsp"}, nil},
+		{"several", []string{"zsh", "lsp"}, false, []string{"nvim-lsp", "zsh"}, nil},
+		{"exact", []string{"NVIM", "Neovim"}, true, []string{"neovim"}, []string{"NVIM"}},
+		{"missing", []string{"zsh", "fish"}, false, []string{"zsh"}, []string{"fish"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &config.Config{Applications: []config.Application{
+				{Name: "neovim"}, {Name: "nvim-lsp"}, {Name: "zsh"},
+			}}
+			mgr := New(cfg, &platform.Platform{OS: platform.OSLinux})
+			mgr.ExactSelect = tt.exact
+
+			missing := mgr.SetApplicationFilter(tt.names)
+
+			var got []string
+			for _, app := range cfg.Applications {
+				got = append(got, app.Name)
+			}
+
+			if !slices.Equal(got, tt.wantApps) {
+				t.Errorf("applications = %v, want %v", got, tt.wantApps)
+			}
+
+			if !slices.Equal(missing, tt.wantMissing) {
+				t.Errorf("missing = %v, want %v", missing, tt.wantMissing)
+			}
+		})
+	}
+}
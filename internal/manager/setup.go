@@ -96,24 +96,25 @@ func (m *Manager) setupWorkDir() string {
 func (m *Manager) runCheck(command string) bool {
 	name, args := shellCommand(m.Platform.OS, command)
 
-	res, err := m.runner.RunIn(m.ctx, cmdexec.RunOptions{Dir: m.setupWorkDir()}, name, args...) //nolint:gosec // command from trusted config
+	res, err := m.runner.RunIn(m.ctx, cmdexec.RunOptions{Dir: m.setupWorkDir(), Env: m.appEnv}, name, args...) //nolint:gosec // command from trusted config
 
 	return commandSucceeded(res, err)
 }
 
-// IsSetupApplied runs the entry's check command for the current OS and reports
-// whether it passes. An entry that declares no check for this OS does not apply
-// here, and is reported as applied (nothing outstanding).
+// IsSetupApplied runs the check command for the current OS of entry e of
+// application appName, with the application's env_vars, and reports whether it
+// passes. An entry that declares no check for this OS does not apply here, and
+// is reported as applied (nothing outstanding).
 //
 // The check command is executed every time this is called. Checks must therefore
 // be side-effect free and fast; see docs/configuration/setup.md.
-func (m *Manager) IsSetupApplied(e config.SubEntry) bool {
+func (m *Manager) IsSetupApplied(appName string, e config.SubEntry) bool {
 	check := e.GetCheck(m.Platform.OS)
 	if check == "" {
 		return true
 	}
 
-	return m.runCheck(check)
+	return m.forApp(appName).runCheck(check)
 }
 
 // RunSetup executes a single setup sub-entry: it runs the entry's check and,
@@ -129,12 +130,15 @@ func (m *Manager) IsSetupApplied(e config.SubEntry) bool {
 // bubbletea TUI) must therefore release it for the duration of this call; see
 // internal/tui/setup_run.go.
 func (m *Manager) RunSetup(appName string, e config.SubEntry) error {
-	return m.runSetupEntry(appName, e)
+	return m.forApp(appName).runSetupEntry(appName, e)
 }
 
-// runSetupEntry executes a single setup sub-entry:
+// runSetupEntry executes a single setup sub-entry, on a Manager forApp scoped
+// to appName:
 //
 //  1. no run command for this OS  -> skip
+//     sudo: true under NoSudo     -> skip
+//     Offline                     -> skip
 //  2. check passes                -> skip (already set up)
 //  3. dry run                     -> report, never execute the run command
 //  4. execute the run command     -> non-zero exit is an error
@@ -151,6 +155,23 @@ func (m *Manager) runSetupEntry(appName string, e config.SubEntry) error {
 		return nil
 	}
 
+	if m.SkipsSudo(e) {
+		m.logger.Warn("skipped: requires sudo",
+			slog.String("app", appName),
+			slog.String("entry", e.Name))
+
+		return nil
+	}
+
+	// A run command may download anything, so none runs offline.
+	if m.Offline {
+		m.logger.Info("skipping (offline mode)",
+			slog.String("app", appName),
+			slog.String("entry", e.Name))
+
+		return nil
+	}
+
 	// Validation guarantees this, but a hand-built config could bypass it. An
 	// empty check would run as `sh -c ""`, exit 0, and silently suppress the
 	// setup forever — so fail loudly instead.
@@ -164,10 +185,13 @@ func (m *Manager) runSetupEntry(appName string, e config.SubEntry) error {
 		m.logger.Info("setup already applied",
 			slog.String("app", appName),
 			slog.String("entry", e.Name))
+		m.step(StepRun, command, "", ConflictUnchanged)
 
 		return nil
 	}
 
+	m.step(StepRun, command, "", ConflictNone)
+
 	if m.DryRun {
 		m.logger.Info("would run setup",
 			slog.String("app", appName),
@@ -180,7 +204,7 @@ func (m *Manager) runSetupEntry(appName string, e config.SubEntry) error {
 	name, args := shellCommand(m.Platform.OS, command)
 
 	res, err := m.runner.RunIn(m.ctx, //nolint:gosec // command from trusted config
-		cmdexec.RunOptions{Dir: m.setupWorkDir(), Sudo: e.Sudo}, name, args...)
+		cmdexec.RunOptions{Dir: m.setupWorkDir(), Sudo: e.Sudo, Env: m.appEnv}, name, args...)
 
 	if !commandSucceeded(res, err) {
 		return newSetupRunError(appName, e.Name, res, err)
@@ -199,3 +223,87 @@ func (m *Manager) runSetupEntry(appName string, e config.SubEntry) error {
 
 	return nil
 }
+
+// runPostRestore runs the post_restore command of config entry e for this OS,
+// once e has been restored, and returns an error if it fails. A failure is a
+// warning only: the entry stays restored and the run goes on. In a dry run
+// the command is recorded as a step but not run. Like a setup command, it is
+// skipped under SkipSetup and Offline, with the reason recorded on its step.
+func (m *Manager) runPostRestore(appName string, e config.SubEntry) error {
+	command := e.GetPostRestore(m.Platform.OS)
+	if command == "" {
+		return nil
+	}
+
+	if reason := m.setupSkipReason(); reason != "" {
+		m.logger.Info("skipping post_restore",
+			slog.String("app", appName),
+			slog.String("entry", e.Name),
+			slog.String("reason", reason))
+		m.skipStep(StepRun, command, reason)
+
+		return nil
+	}
+
+	m.step(StepRun, command, "", ConflictNone)
+
+	if m.DryRun {
+		m.logger.Info("would run post_restore",
+			slog.String("app", appName),
+			slog.String("entry", e.Name),
+			slog.String("command", command))
+
+		return nil
+	}
+
+	name, args := shellCommand(m.Platform.OS, command)
+
+	if m.setupMu != nil {
+		m.setupMu.Lock()
+	}
+
+	res, err := m.runner.RunIn(m.ctx, //nolint:gosec // command from trusted config
+		cmdexec.RunOptions{Dir: m.setupWorkDir(), Sudo: e.Sudo, Env: m.appEnv}, name, args...)
+
+	if m.setupMu != nil {
+		m.setupMu.Unlock()
+	}
+
+	if !commandSucceeded(res, err) {
+		// The entry is already named wherever the warning is shown, so only the
+		// exit code and reason of setupRunError are kept.
+		runErr := newSetupRunError(appName, e.Name, res, err)
+		hookErr := fmt.Errorf("post_restore failed (exit %d)", runErr.exitCode)
+
+		if runErr.reason != "" {
+			hookErr = fmt.Errorf("post_restore failed (exit %d): %s", runErr.exitCode, runErr.reason)
+		}
+
+		m.logger.Warn("post_restore failed",
+			slog.String("app", appName),
+			slog.String("entry", e.Name),
+			slog.String("error", hookErr.Error()))
+
+		return hookErr
+	}
+
+	m.logger.Info("post_restore ran",
+		slog.String("app", appName),
+		slog.String("entry", e.Name))
+
+	return nil
+}
+
+// setupSkipReason returns why setup and post_restore commands do not run in
+// this run, or "" when they do: a restore for another OS has commands meant
+// for it, and offline any command may need the network.
+func (m *Manager) setupSkipReason() string {
+	switch {
+	case m.SkipSetup:
+		return skipReasonSetup
+	case m.Offline:
+		return skipReasonOffline
+	}
+
+	return ""
+}
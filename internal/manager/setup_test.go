@@ -195,6 +195,95 @@ func TestRunSetupEntry_Sudo_UsesSudo(t *testing.T) {
 	}
 }
 
+func TestRunSetupEntry_NoSudo_Skips(t *testing.T) {
+	stub := cmdexec.NewStubRunner()
+
+	e := setupEntry()
+	e.Sudo = true
+
+	m := newSetupManager(stub, false)
+	m.NoSudo = true
+
+	if err := m.runSetupEntry("vicinae", e); err != nil {
+		t.Fatalf("runSetupEntry returned error: %v", err)
+	}
+
+	if len(shellCalls(stub)) != 0 {
+		t.Errorf("expected no shell calls for a sudo entry under NoSudo, got %d", len(shellCalls(stub)))
+	}
+}
+
+func TestRestore_SkipsSetupEntries(t *testing.T) {
+	tests := []struct {
+		name       string
+		offline    bool
+		skipSetup  bool
+		wantDetail string
+	}{
+		{name: "offline", offline: true, wantDetail: skipReasonOffline},
+		{name: "skip setup", skipSetup: true, wantDetail: skipReasonSetup},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stub := cmdexec.NewStubRunner()
+
+			cfg := &config.Config{
+				Version:      3,
+				BackupRoot:   "/repo",
+				Applications: []config.Application{{Name: "vicinae", Entries: []config.SubEntry{setupEntry()}}},
+			}
+			plat := &platform.Platform{OS: platform.OSLinux, EnvVars: map[string]string{}}
+
+			m := New(cfg, plat).WithRunner(stub)
+			m.Offline = tt.offline
+			m.SkipSetup = tt.skipSetup
+
+			report, err := m.RestoreReport(context.Background())
+			if err != nil {
+				t.Fatalf("RestoreReport() error = %v", err)
+			}
+
+			if len(report.Entries) != 1 || report.Entries[0].Action != ActionSkipped || report.Entries[0].Detail != tt.wantDetail {
+				t.Errorf("report entries = %+v, want the setup entry skipped: %s", report.Entries, tt.wantDetail)
+			}
+
+			if len(shellCalls(stub)) != 0 {
+				t.Errorf("expected no shell calls, not even the check, got %d", len(shellCalls(stub)))
+			}
+		})
+	}
+}
+
+func TestRunSetup_PassesApplicationEnvVars(t *testing.T) {
+	stub := cmdexec.NewStubRunner()
+	stub.AddResult("sh", cmdexec.Result{ExitCode: 1}) // check fails
+	stub.AddResult("sh", cmdexec.Result{ExitCode: 0}) // run succeeds
+	stub.AddResult("sh", cmdexec.Result{ExitCode: 0}) // re-check passes
+
+	m := newSetupManager(stub, false)
+	m.Config.Applications = []config.Application{{
+		Name:    "vicinae",
+		EnvVars: map[string]string{"VICINAE_THEME": "dark", "A_FIRST": "1"},
+	}}
+
+	if err := m.RunSetup("vicinae", setupEntry()); err != nil {
+		t.Fatalf("RunSetup returned error: %v", err)
+	}
+
+	for _, call := range shellCalls(stub) {
+		if strings.Join(call.Env, " ") != "A_FIRST=1 VICINAE_THEME=dark" {
+			t.Errorf("call %q Env = %v, want the application's env_vars", call.Args[1], call.Env)
+		}
+	}
+
+	stub.Calls = nil
+
+	if m.IsSetupApplied("other-app", setupEntry()); len(stub.Calls) != 1 || stub.Calls[0].Env != nil {
+		t.Errorf("check of another application ran with %+v, want no env_vars", stub.Calls)
+	}
+}
+
 func TestRunSetupEntry_MissingCheckForOS_ReturnsError(t *testing.T) {
 	stub := cmdexec.NewStubRunner()
 
@@ -0,0 +1,100 @@
+package manager
+
+import "io/fs"
+
+// Step is one change a restore or backup run decided on for an entry. Steps
+// are recorded as the run makes its decisions, so a dry run records the
+// steps a real run would take.
+type Step struct {
+	Op       string `json:"op"`
+	Source   string `json:"source"`
+	Target   string `json:"target"`
+	Conflict string `json:"conflict"`
+	// Bytes is the size of what a copy or backup step writes: Source, or
+	// the files under it. It is 0 for other steps and unchanged targets.
+	Bytes int64 `json:"bytes,omitempty"`
+	// Skipped is why the step is not taken, as for a post_restore command
+	// offline. It is empty for a step that is.
+	Skipped string `json:"skipped,omitempty"`
+}
+
+// Operations of a Step.
+const (
+	StepLink   = "link"   // symlink Target to Source
+	StepCopy   = "copy"   // write a copy of Source at Target (method: copy)
+	StepRender = "render" // render the template Source into Target
+	StepBackup = "backup" // copy the deployed Source into the backup at Target
+	StepRun    = "run"    // run the setup command Source; Target is empty
+)
+
+// Conflict decisions of a Step: what happens to what is already at Target.
+const (
+	ConflictNone      = "none"      // nothing is there
+	ConflictUnchanged = "unchanged" // already up to date, nothing is written
+	ConflictRelink    = "relink"    // a symlink pointing elsewhere is replaced
+	ConflictMerge     = "merge"     // the existing content is merged in first
+	ConflictAdopt     = "adopt"     // the target is moved into the empty backup
+	ConflictReplace   = "replace"   // the existing content is overwritten
+)
+
+// withSteps returns a copy of m that records the steps of one entry, and the
+// files its backup skips.
+func (m *Manager) withSteps() *Manager {
+	m2 := *m
+	m2.steps = &[]Step{}
+	m2.skipped = &[]SkippedFile{}
+
+	return &m2
+}
+
+// step records s when m records steps; see withSteps.
+func (m *Manager) step(op, source, target, conflict string) {
+	if m.steps == nil {
+		return
+	}
+
+	s := Step{Op: op, Source: source, Target: target, Conflict: conflict}
+	if (op == StepCopy || op == StepBackup) && conflict != ConflictUnchanged {
+		s.Bytes = m.pathSize(source)
+	}
+
+	*m.steps = append(*m.steps, s)
+}
+
+// skipStep records a step that is not taken, for reason.
+func (m *Manager) skipStep(op, source, reason string) {
+	if m.steps == nil {
+		return
+	}
+
+	*m.steps = append(*m.steps, Step{Op: op, Source: source, Conflict: ConflictNone, Skipped: reason})
+}
+
+// pathSize returns the size of the regular file at path, or of the regular
+// files under it, skipping what cannot be read.
+func (m *Manager) pathSize(path string) int64 {
+	var size int64
+
+	_ = m.fs.WalkDir(path, func(_ string, d fs.DirEntry, err error) error {
+		if err != nil || !d.Type().IsRegular() {
+			return nil
+		}
+
+		if info, err := d.Info(); err == nil {
+			size += info.Size()
+		}
+
+		return nil
+	})
+
+	return size
+}
+
+// recordedSteps returns the steps recorded so far.
+func (m *Manager) recordedSteps() []Step {
+	if m.steps == nil {
+		return nil
+	}
+
+	return *m.steps
+}
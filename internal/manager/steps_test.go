@@ -0,0 +1,194 @@
+package manager
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/AntoineGS/tidydots/internal/config"
+)
+
+func TestRestoreReport_DryRunRecordsSteps(t *testing.T) {
+	mgr, root, home := newLinkManager(t)
+	mgr.DryRun = true
+
+	if err := os.WriteFile(filepath.Join(home, ".zshrc"), []byte("edited"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := os.Symlink("/elsewhere", filepath.Join(home, ".zshenv")); err != nil {
+		t.Fatal(err)
+	}
+
+	report, err := mgr.RestoreReport(context.Background())
+	if err != nil {
+		t.Fatalf("RestoreReport() error = %v", err)
+	}
+
+	got := make(map[string]Step)
+
+	for _, e := range report.Entries {
+		for _, s := range e.Steps {
+			got[s.Target] = s
+		}
+	}
+
+	want := map[string]Step{
+		filepath.Join(home, "nvim"):           {Op: StepLink, Source: filepath.Join(root, "nvim"), Target: filepath.Join(home, "nvim"), Conflict: ConflictNone},
+		filepath.Join(home, ".zshrc"):         {Op: StepLink, Source: filepath.Join(root, "zsh", ".zshrc"), Target: filepath.Join(home, ".zshrc"), Conflict: ConflictMerge},
+		filepath.Join(home, ".zshenv"):        {Op: StepLink, Source: filepath.Join(root, "zsh", ".zshenv"), Target: filepath.Join(home, ".zshenv"), Conflict: ConflictRelink},
+		filepath.Join(home, "copy", ".zshrc"): {Op: StepCopy, Source: filepath.Join(root, "zsh", ".zshrc"), Target: filepath.Join(home, "copy", ".zshrc"), Conflict: ConflictNone, Bytes: int64(len("zsh/.zshrc"))},
+	}
+
+	if len(got) != len(want) {
+		t.Fatalf("steps = %v, want %v", got, want)
+	}
+
+	for target, w := range want {
+		if got[target] != w {
+			t.Errorf("step for %s = %+v, want %+v", target, got[target], w)
+		}
+	}
+
+	// Nothing was changed by the dry run.
+	if data, err := os.ReadFile(filepath.Join(home, ".zshrc")); err != nil || string(data) != "edited" {
+		t.Errorf(".zshrc = %q, %v; want it left alone", data, err)
+	}
+}
+
+func TestBackupReport_DryRunRecordsSteps(t *testing.T) {
+	mgr, root, home := newLinkManager(t)
+	mgr.DryRun = true
+
+	if err := os.MkdirAll(filepath.Join(home, "nvim"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := os.WriteFile(filepath.Join(home, ".zshrc"), []byte("rc"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	report, err := mgr.BackupReport(context.Background())
+	if err != nil {
+		t.Fatalf("BackupReport() error = %v", err)
+	}
+
+	var steps []Step
+	for _, e := range report.Entries {
+		steps = append(steps, e.Steps...)
+	}
+
+	want := []Step{
+		{Op: StepBackup, Source: filepath.Join(home, "nvim"), Target: filepath.Join(root, "nvim"), Conflict: ConflictMerge},
+		{Op: StepBackup, Source: filepath.Join(home, ".zshrc"), Target: filepath.Join(root, "zsh", ".zshrc"), Conflict: ConflictReplace, Bytes: int64(len("rc"))},
+	}
+
+	for _, w := range want {
+		found := false
+
+		for _, s := range steps {
+			if s == w {
+				found = true
+			}
+		}
+
+		if !found {
+			t.Errorf("steps = %+v, missing %+v", steps, w)
+		}
+	}
+}
+
+// planFixture sets up the tree of newLinkManager with a target to merge, a
+// link to replace, a deployed folder to back up and a setup entry to run.
+func planFixture(t *testing.T) (mgr *Manager, home string) {
+	t.Helper()
+
+	mgr, _, home = newLinkManager(t)
+
+	if err := os.WriteFile(filepath.Join(home, ".zshrc"), []byte("edited"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := os.Symlink("/elsewhere", filepath.Join(home, ".zshenv")); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := os.MkdirAll(filepath.Join(home, "nvim"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := os.WriteFile(filepath.Join(home, "nvim", "init.lua"), []byte("-- edited"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	marker := filepath.Join(home, "setup-done")
+	mgr.Config.Applications = append(mgr.Config.Applications, config.Application{
+		Name: "service",
+		Entries: []config.SubEntry{{
+			Name:  "enable",
+			Check: map[string]string{"linux": "test -f " + marker},
+			Run:   map[string]string{"linux": "touch " + marker},
+		}},
+	})
+
+	return mgr, home
+}
+
+// runSteps returns the steps of every entry of report, by entry.
+func runSteps(report *Report) map[string][]Step {
+	steps := make(map[string][]Step, len(report.Entries))
+	for _, e := range report.Entries {
+		steps[e.Name()] = e.Steps
+	}
+
+	return steps
+}
+
+func TestReport_DryRunPlanMatchesRealRun(t *testing.T) {
+	runs := map[string]func(*Manager) (*Report, error){
+		"restore": func(m *Manager) (*Report, error) { return m.RestoreReport(context.Background()) },
+		"backup":  func(m *Manager) (*Report, error) { return m.BackupReport(context.Background()) },
+	}
+
+	for name, run := range runs {
+		t.Run(name, func(t *testing.T) {
+			mgr, home := planFixture(t)
+			mgr.DryRun = true
+
+			planned, err := run(mgr)
+			if err != nil {
+				t.Fatalf("dry run error = %v", err)
+			}
+
+			// The dry run changed nothing, so the real run starts from the
+			// same tree.
+			mgr.DryRun = false
+
+			done, err := run(mgr)
+			if err != nil {
+				t.Fatalf("real run error = %v", err)
+			}
+
+			if got, want := runSteps(done), runSteps(planned); !reflect.DeepEqual(got, want) {
+				t.Errorf("real run steps = %+v\nwant the dry run's %+v", got, want)
+			}
+
+			if name != "restore" {
+				return
+			}
+
+			marker := filepath.Join(home, "setup-done")
+			want := []Step{{Op: StepRun, Source: "touch " + marker, Conflict: ConflictNone}}
+
+			if got := runSteps(planned)["service/enable"]; !reflect.DeepEqual(got, want) {
+				t.Errorf("setup steps = %+v, want %+v", got, want)
+			}
+
+			if _, err := os.Stat(marker); err != nil {
+				t.Errorf("the real run did not run the setup command: %v", err)
+			}
+		})
+	}
+}
@@ -6,6 +6,9 @@ import (
 	"os"
 	"path/filepath"
 	"testing"
+
+	"github.com/AntoineGS/tidydots/internal/config"
+	"github.com/AntoineGS/tidydots/internal/fsys"
 )
 
 func TestSymlink_Linux_FileSymlink(t *testing.T) {
@@ -195,3 +198,32 @@ func TestSymlink_Linux_NoPrivilegeRequired(t *testing.T) {
 		t.Errorf("Symlink() should not require privileges on Linux, got error: %v", err)
 	}
 }
+
+func TestRestoreFolder_Linux_JunctionCompatIgnored(t *testing.T) {
+	t.Parallel()
+
+	mgr, _, stub := newSudoManager(t)
+	mgr = mgr.WithFS(fsys.OsFS{})
+	mgr.SymlinkCompat = config.SymlinkCompatJunction
+
+	dir := t.TempDir()
+	source := filepath.Join(dir, "backup")
+	target := filepath.Join(dir, "target")
+
+	if err := os.MkdirAll(source, 0o755); err != nil {
+		t.Fatalf("MkdirAll() error: %v", err)
+	}
+
+	if err := mgr.RestoreFolder(config.SubEntry{Name: "nvim"}, source, target); err != nil {
+		t.Fatalf("RestoreFolder() error: %v", err)
+	}
+
+	info, err := os.Lstat(target)
+	if err != nil || info.Mode()&os.ModeSymlink == 0 {
+		t.Errorf("target should be a symlink, junctions are Windows-only (Lstat: %v, %v)", info, err)
+	}
+
+	if len(stub.Calls) != 0 {
+		t.Errorf("no command should run, got %v", stub.Calls)
+	}
+}
@@ -7,6 +7,9 @@ import (
 	"path/filepath"
 	"strings"
 	"testing"
+
+	"github.com/AntoineGS/tidydots/internal/config"
+	"github.com/AntoineGS/tidydots/internal/platform"
 )
 
 func TestSymlink_Windows_FileSymlink(t *testing.T) {
@@ -167,3 +170,30 @@ func TestSymlink_Windows_GracefulFailure(t *testing.T) {
 		t.Errorf("Readlink() = %q, want %q", got, source)
 	}
 }
+
+func TestRestoreFolder_Windows_Junction(t *testing.T) {
+	t.Parallel()
+	dir := t.TempDir()
+
+	source := filepath.Join(dir, "backup")
+	if err := os.MkdirAll(source, 0o755); err != nil {
+		t.Fatalf("failed to create source dir: %v", err)
+	}
+
+	target := filepath.Join(dir, "target")
+	mgr := New(&config.Config{SymlinkCompat: config.SymlinkCompatJunction}, &platform.Platform{OS: platform.OSWindows})
+
+	if err := mgr.RestoreFolder(config.SubEntry{Name: "nvim"}, source, target); err != nil {
+		t.Fatalf("RestoreFolder() error: %v", err)
+	}
+
+	got, ok := platform.JunctionTarget(target)
+	if !ok || !strings.EqualFold(got, source) {
+		t.Fatalf("JunctionTarget(target) = %q, %v; want a junction to %q", got, ok, source)
+	}
+
+	// A second restore finds the junction in place and leaves it alone.
+	if err := mgr.RestoreFolder(config.SubEntry{Name: "nvim"}, source, target); err != nil {
+		t.Errorf("second RestoreFolder() error: %v", err)
+	}
+}
@@ -19,12 +19,16 @@ func normalizeStateKey(relPath string) string {
 	return filepath.ToSlash(relPath)
 }
 
+// atomicTempPath returns the sibling path a file or link is built at before
+// being renamed over path.
+func atomicTempPath(path string) string {
+	return filepath.Join(filepath.Dir(path), "."+filepath.Base(path)+".tidydots-tmp")
+}
+
 // writeFileAtomic writes data to path via a sibling temp file and a rename,
 // so a crash mid-write cannot truncate the existing content.
 func (m *Manager) writeFileAtomic(path string, data []byte, perm fs.FileMode) error {
-	dir := filepath.Dir(path)
-	base := filepath.Base(path)
-	tmpPath := filepath.Join(dir, "."+base+".tidydots-tmp")
+	tmpPath := atomicTempPath(path)
 
 	if err := m.fs.WriteFile(tmpPath, data, perm); err != nil {
 		return fmt.Errorf("writing temp file: %w", err)
@@ -54,7 +58,31 @@ func (m *Manager) RestoreFolderWithTemplates(subEntry config.SubEntry, source, t
 		return nil
 	}
 
-	return m.renderTemplatesInBackup(source)
+	m2, err := m.withTemplateEncoding(subEntry.Encoding)
+	if err != nil {
+		return NewPathError("restore", source, err)
+	}
+
+	return m2.renderTemplatesInBackup(source)
+}
+
+// withTemplateEncoding returns a Manager whose templates are read and written
+// in the encoding called name; see config.SubEntry.Encoding. It returns m for
+// the default UTF-8.
+func (m *Manager) withTemplateEncoding(name string) (*Manager, error) {
+	if name == "" || name == config.EncodingUTF8 {
+		return m, nil
+	}
+
+	engine, err := m.templateEngine.WithEncoding(name)
+	if err != nil {
+		return nil, err
+	}
+
+	m2 := *m
+	m2.templateEngine = engine
+
+	return &m2, nil
 }
 
 // renderTemplatesInBackup walks the backup directory for .tmpl files and
@@ -113,12 +141,14 @@ func (m *Manager) renderTemplateAndLink(tmplAbsPath, relPath string) error {
 			// Template unchanged and rendered file exists - just ensure relative symlink
 			m.logger.Debug("template unchanged, skipping re-render",
 				slog.String("template", relPath))
+			m.step(StepRender, tmplAbsPath, renderedAbsPath, ConflictUnchanged)
+
 			return m.ensureRelativeSymlinkForTemplate(tmplAbsPath)
 		}
 	}
 
 	// Render the template
-	rendered, renderErr := m.templateEngine.RenderBytes(relPath, tmplContent)
+	rendered, renderErr := m.templateEngine.RenderFile(relPath, tmplContent)
 	if renderErr != nil {
 		return NewPathError("restore", tmplAbsPath, fmt.Errorf("rendering template: %w", renderErr))
 	}
@@ -127,6 +157,8 @@ func (m *Manager) renderTemplateAndLink(tmplAbsPath, relPath string) error {
 		slog.String("template", relPath),
 		slog.String("rendered", renderedAbsPath))
 
+	m.step(StepRender, tmplAbsPath, renderedAbsPath, m.renderConflict(relPath, renderedAbsPath))
+
 	if m.DryRun {
 		return nil
 	}
@@ -207,17 +239,36 @@ func (m *Manager) renderTemplateAndLink(tmplAbsPath, relPath string) error {
 
 	// Store pure render in DB (always store the unmerged template output)
 	if m.stateStore != nil {
-		if saveErr := m.stateStore.SaveRender(m.ctx, normalizeStateKey(relPath), rendered, hash, m.Platform.OS, m.Platform.Hostname); saveErr != nil {
-			m.logger.Warn("failed to save render record",
-				slog.String("template", relPath),
-				slog.String("error", saveErr.Error()))
-		}
+		m.saveRender(normalizeStateKey(relPath), rendered, hash)
 	}
 
 	// Create relative symlink in backup dir: name → name.tmpl.rendered
 	return m.ensureRelativeSymlinkForTemplate(tmplAbsPath)
 }
 
+// saveRender records a pure render of the template stored under key, then
+// prunes the template's history down to MaxHistory renders. Failures are
+// logged: the rendered file has already been written.
+func (m *Manager) saveRender(key string, rendered []byte, hash string) {
+	if err := m.stateStore.SaveRender(m.ctx, key, rendered, hash, m.Platform.OS, m.Platform.Hostname); err != nil {
+		m.logger.Warn("failed to save render record",
+			slog.String("template", key),
+			slog.String("error", err.Error()))
+
+		return
+	}
+
+	if m.MaxHistory <= 0 {
+		return
+	}
+
+	if err := m.stateStore.PruneHistory(m.ctx, key, m.MaxHistory); err != nil {
+		m.logger.Warn("failed to prune render history",
+			slog.String("template", key),
+			slog.String("error", err.Error()))
+	}
+}
+
 // ensureRelativeSymlinkForTemplate creates a relative symlink in the backup directory
 // for a template file: e.g., "config" → "config.tmpl.rendered".
 func (m *Manager) ensureRelativeSymlinkForTemplate(tmplAbsPath string) error {
@@ -262,3 +313,22 @@ func (m *Manager) ensureRelativeSymlink(symlinkPath, target string) error {
 
 	return nil
 }
+
+// renderConflict returns how rendering the template stored under relPath
+// treats its existing output at renderedAbsPath: merged with the previous
+// render when there is one in the state store, overwritten otherwise. The
+// state store is only queried when m records steps.
+func (m *Manager) renderConflict(relPath, renderedAbsPath string) string {
+	if m.steps == nil || !m.pathExists(renderedAbsPath) {
+		return ConflictNone
+	}
+
+	if m.stateStore != nil && !m.ForceRender {
+		record, err := m.stateStore.GetLatestRender(m.ctx, normalizeStateKey(relPath), m.Platform.OS, m.Platform.Hostname)
+		if err == nil && record != nil {
+			return ConflictMerge
+		}
+	}
+
+	return ConflictReplace
+}
@@ -201,6 +201,91 @@ func TestRestoreFolderWithTemplates_RendersContext(t *testing.T) {
 	verifyRelativeSymlink(t, filepath.Join(backupDir, "info"), "info.tmpl.rendered")
 }
 
+func TestRestoreFolderWithTemplates_Encoding(t *testing.T) {
+	skipIfNoSymlink(t)
+	backupRoot, targetDir, mgr, _ := setupTemplateTest(t)
+
+	backupDir := filepath.Join(backupRoot, "config")
+	if err := os.MkdirAll(backupDir, 0750); err != nil {
+		t.Fatal(err)
+	}
+
+	// Latin-1 bytes: "é" is 0xE9. The plain file is never transcoded.
+	if err := os.WriteFile(filepath.Join(backupDir, "motd.tmpl"), []byte("caf\xe9 {{ .Hostname }}"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := os.WriteFile(filepath.Join(backupDir, "plain.txt"), []byte("caf\xe9"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	subEntry := config.SubEntry{
+		Name:     "config",
+		Backup:   "./config",
+		Encoding: config.EncodingLatin1,
+		Targets:  map[string]string{"linux": targetDir},
+	}
+
+	if err := mgr.RestoreFolderWithTemplates(subEntry, backupDir, targetDir); err != nil {
+		t.Fatal(err)
+	}
+
+	content, _ := os.ReadFile(filepath.Join(backupDir, "motd.tmpl.rendered")) //nolint:gosec
+	if want := "caf\xe9 testhost"; string(content) != want {
+		t.Errorf("rendered content = %q, want %q", content, want)
+	}
+
+	plain, _ := os.ReadFile(filepath.Join(backupDir, "plain.txt")) //nolint:gosec
+	if string(plain) != "caf\xe9" {
+		t.Errorf("plain file = %q, want it untouched", plain)
+	}
+
+	subEntry.Encoding = "ebcdic"
+
+	err := mgr.RestoreFolderWithTemplates(subEntry, backupDir, targetDir)
+	if !errors.Is(err, tmpl.ErrUnsupportedEncoding) {
+		t.Errorf("RestoreFolderWithTemplates(ebcdic) error = %v, want ErrUnsupportedEncoding", err)
+	}
+}
+
+func TestRestoreEntry_RendersApplicationEnvVars(t *testing.T) {
+	skipIfNoSymlink(t)
+	t.Setenv("TIDYDOTS_TEST_BASE", "/opt/base")
+	t.Setenv("MY_VAR", "from-process")
+
+	backupRoot, targetDir, mgr, _ := setupTemplateTest(t)
+
+	mgr.Config.Applications = []config.Application{{
+		Name:    "app",
+		EnvVars: map[string]string{"MY_VAR": "from-app", "TOOL_DIR": "$TIDYDOTS_TEST_BASE/tool"},
+	}}
+
+	backupDir := filepath.Join(backupRoot, "config")
+	if err := os.MkdirAll(backupDir, 0750); err != nil {
+		t.Fatal(err)
+	}
+
+	tmplContent := "{{ .Env.MY_VAR }} {{ .Env.TOOL_DIR }} {{ .Env.TIDYDOTS_TEST_BASE }}"
+	if err := os.WriteFile(filepath.Join(backupDir, "env.tmpl"), []byte(tmplContent), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	subEntry := config.SubEntry{
+		Name:    "config",
+		Backup:  "./config",
+		Targets: map[string]string{"linux": targetDir},
+	}
+
+	if result := mgr.RestoreEntry("app", subEntry, targetDir); result.Err != nil {
+		t.Fatalf("RestoreEntry() error = %v", result.Err)
+	}
+
+	content, _ := os.ReadFile(filepath.Join(backupDir, "env.tmpl.rendered")) //nolint:gosec
+	if want := "from-app /opt/base/tool /opt/base"; string(content) != want {
+		t.Errorf("rendered content = %q, want %q", string(content), want)
+	}
+}
+
 func TestRestoreFolderWithTemplates_ReRenderWithUserEdits(t *testing.T) {
 	skipIfNoSymlink(t)
 	backupRoot, targetDir, mgr, store := setupTemplateTest(t)
@@ -495,6 +580,47 @@ func TestRestoreFolderWithTemplates_DryRun(t *testing.T) {
 	}
 }
 
+func TestRestoreFolderWithTemplates_PrunesRenderHistory(t *testing.T) {
+	skipIfNoSymlink(t)
+	backupRoot, targetDir, mgr, store := setupTemplateTest(t)
+	mgr.MaxHistory = 2
+
+	backupDir := filepath.Join(backupRoot, "config")
+	if err := os.MkdirAll(backupDir, 0750); err != nil {
+		t.Fatal(err)
+	}
+
+	subEntry := config.SubEntry{
+		Name:    "config",
+		Backup:  "./config",
+		Targets: map[string]string{"linux": targetDir},
+	}
+
+	// One render more than the limit.
+	for _, content := range []string{"v1\n", "v2\n", "v3\n"} {
+		if err := os.WriteFile(filepath.Join(backupDir, "file.tmpl"), []byte(content), 0600); err != nil {
+			t.Fatal(err)
+		}
+		if err := mgr.RestoreFolderWithTemplates(subEntry, backupDir, targetDir); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	records, err := store.GetRenderHistory(context.Background(), "file.tmpl", 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var got []string
+	for _, r := range records {
+		got = append(got, strings.TrimSpace(string(r.PureRender)))
+	}
+
+	if strings.Join(got, ",") != "v3,v2" {
+		t.Errorf("render history = %v, want the 2 most recent renders [v3 v2]", got)
+	}
+}
+
 func TestRestoreFolderWithTemplates_ForceRender(t *testing.T) {
 	skipIfNoSymlink(t)
 	backupRoot, targetDir, mgr, _ := setupTemplateTest(t)
@@ -1349,3 +1475,79 @@ func TestStateStoreKey_IsForwardSlashNormalized(t *testing.T) {
 		t.Errorf("normalizeStateKey(%q) = %q, want %q", rel, got, want)
 	}
 }
+
+func TestRestore_TemplatedTargetDiffersByHostname(t *testing.T) {
+	skipIfNoSymlink(t)
+
+	backupRoot := t.TempDir()
+	targetRoot := t.TempDir()
+
+	if err := os.MkdirAll(filepath.Join(backupRoot, "app"), 0750); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := &config.Config{
+		BackupRoot: backupRoot,
+		Version:    3,
+		Vars:       map[string]string{"app": "tool"},
+		Applications: []config.Application{{
+			Name: "app",
+			Entries: []config.SubEntry{{
+				Name:    "config",
+				Backup:  "./app",
+				Targets: map[string]string{"linux": targetRoot + "/{{ .Hostname }}/{{ .Vars.app }}"},
+			}},
+		}},
+	}
+
+	for _, host := range []string{"desktop", "laptop"} {
+		mgr := New(cfg, &platform.Platform{OS: "linux", Hostname: host, EnvVars: map[string]string{}})
+
+		report, err := mgr.RestoreReport(context.Background())
+		if err != nil {
+			t.Fatalf("RestoreReport() on %s error = %v", host, err)
+		}
+
+		if got := report.Count(ActionFailed); got != 0 {
+			t.Fatalf("RestoreReport() on %s failed %d entries: %+v", host, got, report.Entries)
+		}
+
+		verifyFolderSymlink(t, filepath.Join(targetRoot, host, "tool"), filepath.Join(backupRoot, "app"))
+	}
+}
+
+func TestRestoreEntry_PathRenderErrorFailsEntry(t *testing.T) {
+	tests := []struct {
+		name   string
+		target string
+		want   string
+	}{
+		{name: "undefined var", target: "/tmp/{{ .Vars.missing }}", want: "not defined"},
+		{name: "bad syntax", target: "/tmp/{{ .Hostname", want: "parsing template"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			backupRoot, _, mgr, _ := setupTemplateTest(t)
+
+			if err := os.MkdirAll(filepath.Join(backupRoot, "app"), 0750); err != nil {
+				t.Fatal(err)
+			}
+
+			subEntry := config.SubEntry{
+				Name:    "config",
+				Backup:  "./app",
+				Targets: map[string]string{"linux": tt.target},
+			}
+
+			result := mgr.RestoreEntry("app", subEntry, mgr.expandTarget(tt.target))
+			if result.Action != ActionFailed || result.Err == nil {
+				t.Fatalf("RestoreEntry() = %+v, want a failure", result)
+			}
+
+			if msg := result.Err.Error(); !strings.Contains(msg, "rendering target path") || !strings.Contains(msg, tt.want) {
+				t.Errorf("RestoreEntry() error = %q, want rendering target path ... %s", msg, tt.want)
+			}
+		})
+	}
+}
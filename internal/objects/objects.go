@@ -0,0 +1,82 @@
+// Package objects stores large backup files by content in the dotfiles
+// repository, under .tidydots/objects. An entry's backup then holds a small
+// pointer file naming the object instead of the file itself, so a blob that
+// several entries or machines share is stored once, and backing it up again
+// unchanged leaves the repository as it was.
+package objects
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// Dir is the object store, relative to the backup root.
+const Dir = ".tidydots/objects"
+
+// MaxPointerSize bounds the size of a pointer file. Larger files are never
+// read as pointers.
+const MaxPointerSize = 128
+
+// pointerPrefix starts every pointer file.
+const pointerPrefix = "tidydots-object sha256:"
+
+// Pointer names the object holding a file's content.
+type Pointer struct {
+	Hash string // hex-encoded SHA-256 of the content
+	Size int64
+}
+
+// NewPointer returns the pointer to data.
+func NewPointer(data []byte) Pointer {
+	sum := sha256.Sum256(data)
+	return Pointer{Hash: hex.EncodeToString(sum[:]), Size: int64(len(data))}
+}
+
+// String returns the content of the pointer file, e.g.
+// "tidydots-object sha256:9f86d0… 1048576".
+func (p Pointer) String() string {
+	return fmt.Sprintf("%s%s %d\n", pointerPrefix, p.Hash, p.Size)
+}
+
+// Matches reports whether data is the content p points to.
+func (p Pointer) Matches(data []byte) bool {
+	return NewPointer(data) == p
+}
+
+// Parse reads a pointer file's content. It reports false for anything that
+// is not a well-formed pointer.
+func Parse(data []byte) (Pointer, bool) {
+	if len(data) > MaxPointerSize {
+		return Pointer{}, false
+	}
+
+	rest, ok := strings.CutPrefix(strings.TrimSpace(string(data)), pointerPrefix)
+	if !ok {
+		return Pointer{}, false
+	}
+
+	hash, sizeStr, ok := strings.Cut(rest, " ")
+	if !ok || len(hash) != sha256.Size*2 {
+		return Pointer{}, false
+	}
+
+	if _, err := hex.DecodeString(hash); err != nil {
+		return Pointer{}, false
+	}
+
+	size, err := strconv.ParseInt(sizeStr, 10, 64)
+	if err != nil || size < 0 {
+		return Pointer{}, false
+	}
+
+	return Pointer{Hash: strings.ToLower(hash), Size: size}, true
+}
+
+// Path returns where the object with hash is stored under root.
+func Path(root, hash string) string {
+	return filepath.Join(root, filepath.FromSlash(Dir), hash)
+}
@@ -0,0 +1,54 @@
+package objects
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestPointerRoundTrip(t *testing.T) {
+	data := []byte("firmware")
+	p := NewPointer(data)
+
+	got, ok := Parse([]byte(p.String()))
+	if !ok {
+		t.Fatalf("Parse(%q) = false, want a pointer", p.String())
+	}
+
+	if got != p {
+		t.Errorf("Parse() = %+v, want %+v", got, p)
+	}
+
+	if !got.Matches(data) {
+		t.Error("Matches(data) = false, want true")
+	}
+
+	if got.Matches([]byte("firmware v2")) {
+		t.Error("Matches(other) = true, want false")
+	}
+}
+
+func TestParse_Rejects(t *testing.T) {
+	valid := NewPointer([]byte("x")).String()
+
+	for name, data := range map[string]string{
+		"empty":      "",
+		"plain text": "hello\n",
+		"short hash": "tidydots-object sha256:abcd 4\n",
+		"not hex":    "tidydots-object sha256:" + strings.Repeat("z", 64) + " 4\n",
+		"no size":    strings.TrimSuffix(strings.Fields(valid)[0]+" "+strings.Fields(valid)[1], "\n"),
+		"bad size":   strings.Replace(valid, " 1\n", " -1\n", 1),
+		"too large":  valid + strings.Repeat(" ", MaxPointerSize),
+	} {
+		if _, ok := Parse([]byte(data)); ok {
+			t.Errorf("%s: Parse(%q) = true, want false", name, data)
+		}
+	}
+}
+
+func TestPath(t *testing.T) {
+	want := filepath.Join("/repo", ".tidydots", "objects", "abc")
+	if got := Path("/repo", "abc"); got != want {
+		t.Errorf("Path() = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,164 @@
+package packages
+
+import (
+	"fmt"
+	"strings"
+)
+
+// aptSources are the files and directories apt reads its repositories from.
+var aptSources = []string{"/etc/apt/sources.list", "/etc/apt/sources.list.d"}
+
+// aptRepoMarker returns a string that appears in the apt sources once repo has
+// been added: the Launchpad path of a PPA ("ppa:owner/name" is served from
+// .../owner/name/ubuntu), or the URI of a deb line. It returns "" when repo
+// has neither, and whether it is configured cannot be told.
+func aptRepoMarker(repo string) string {
+	if ppa, ok := strings.CutPrefix(repo, "ppa:"); ok {
+		owner, name, found := strings.Cut(ppa, "/")
+		if !found {
+			name = "ppa"
+		}
+
+		return "/" + owner + "/" + name + "/ubuntu"
+	}
+
+	for _, field := range strings.Fields(repo) {
+		if strings.HasPrefix(field, "http://") || strings.HasPrefix(field, "https://") {
+			return strings.TrimRight(field, "/")
+		}
+	}
+
+	return ""
+}
+
+// aptRepoCheckArgs returns the command that exits 0 when marker is found in
+// the apt sources.
+func aptRepoCheckArgs(marker string) []string {
+	return append([]string{"grep", "-rqsF", "--", marker}, aptSources...)
+}
+
+// aptRepoSetupArgs returns the commands that add repo and refresh the package
+// lists, without their sudo prefix when noSudo is set.
+func aptRepoSetupArgs(repo string, noSudo bool) [][]string {
+	cmds := [][]string{
+		{cmdSudo, "add-apt-repository", "-y", repo},
+		{cmdSudo, cmdAptGet, "update"},
+	}
+
+	if noSudo {
+		for i := range cmds {
+			cmds[i] = cmds[i][1:]
+		}
+	}
+
+	return cmds
+}
+
+// aptRepoPresent reports whether repo is already configured. A repository
+// whose presence cannot be checked counts as absent: add-apt-repository does
+// nothing when it is already there.
+func (m *Manager) aptRepoPresent(repo string) bool {
+	marker := aptRepoMarker(repo)
+	if marker == "" {
+		return false
+	}
+
+	args := aptRepoCheckArgs(marker)
+
+	result, err := m.runner.Run(m.ctx, args[0], args[1:]...)
+
+	return err == nil && result.ExitCode == 0
+}
+
+// installNative installs val through the native package manager mgr. An apt
+// package with a repo has the repository added first, unless it is already
+// configured, and so does a scoop package with a bucket or a winget package
+// with a source (see ensureSource).
+func (m *Manager) installNative(mgr PackageManager, val ManagerValue) (bool, string) {
+	if s, ok := sourceOf(mgr, val); ok {
+		return m.installFromSource(mgr, val, s)
+	}
+
+	if mgr != Apt || val.Apt == nil || val.Apt.Repo == "" || m.aptRepoPresent(val.Apt.Repo) {
+		return m.installWithManager(mgr, val)
+	}
+
+	setup := aptRepoSetupArgs(val.Apt.Repo, !m.RequiresSudo(Apt))
+
+	if m.DryRun {
+		_, msg := m.installWithManager(mgr, val)
+		return true, fmt.Sprintf("Would run: %s && %s", joinCommands(setup), strings.TrimPrefix(msg, "Would run: "))
+	}
+
+	if ok, msg := m.addAptRepo(val.Apt.Repo, setup); !ok {
+		return false, msg
+	}
+
+	return m.installWithManager(mgr, val)
+}
+
+// addAptRepo runs the setup commands that add repo, holding the native
+// package manager lock so a concurrent apt install does not race the update.
+func (m *Manager) addAptRepo(repo string, setup [][]string) (bool, string) {
+	if m.nativeMu != nil {
+		m.nativeMu.Lock()
+		defer m.nativeMu.Unlock()
+	}
+
+	for _, args := range setup {
+		if _, err := m.runner.Run(m.ctx, args[0], args[1:]...); err != nil { //nolint:gosec // args from trusted lookup table and a validated repo
+			return false, fmt.Sprintf("Adding apt repository %s failed: %v", repo, err)
+		}
+	}
+
+	return true, ""
+}
+
+// aptRepoScript returns a shell script that adds repo when it is not already
+// configured and then runs install. Every argument is single-quoted, so the
+// repository and package name reach the commands unchanged.
+func aptRepoScript(repo string, install []string, noSudo bool) string {
+	setup := quoteCommands(aptRepoSetupArgs(repo, noSudo))
+
+	var b strings.Builder
+
+	if marker := aptRepoMarker(repo); marker != "" {
+		fmt.Fprintf(&b, "%s || { %s; } && ", quoteArgs(aptRepoCheckArgs(marker)), setup)
+	} else {
+		fmt.Fprintf(&b, "%s && ", setup)
+	}
+
+	b.WriteString(quoteArgs(install))
+
+	return b.String()
+}
+
+// joinCommands renders cmds as a single "a && b" command line for messages.
+func joinCommands(cmds [][]string) string {
+	parts := make([]string, len(cmds))
+	for i, args := range cmds {
+		parts[i] = strings.Join(args, " ")
+	}
+
+	return strings.Join(parts, " && ")
+}
+
+// quoteCommands renders cmds as a shell "a && b" list with quoted arguments.
+func quoteCommands(cmds [][]string) string {
+	parts := make([]string, len(cmds))
+	for i, args := range cmds {
+		parts[i] = quoteArgs(args)
+	}
+
+	return strings.Join(parts, " && ")
+}
+
+// quoteArgs single-quotes each argument for a POSIX shell.
+func quoteArgs(args []string) string {
+	quoted := make([]string, len(args))
+	for i, arg := range args {
+		quoted[i] = "'" + escapeShellSingleQuote(arg) + "'"
+	}
+
+	return strings.Join(quoted, " ")
+}
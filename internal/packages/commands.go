@@ -4,11 +4,13 @@ import (
 	"context"
 	"fmt"
 	"log/slog"
+	"os"
 	"os/exec"
 	"strings"
 
 	"github.com/AntoineGS/tidydots/internal/cmdexec"
 	"github.com/AntoineGS/tidydots/internal/config"
+	"github.com/AntoineGS/tidydots/internal/gitutil"
 	"github.com/AntoineGS/tidydots/internal/platform"
 )
 
@@ -26,8 +28,10 @@ const (
 	cmdAptGet = "apt-get"
 	// argInstall is the install subcommand accepted by most package managers.
 	argInstall = "install"
-	// argClone is the git clone subcommand.
-	argClone = "clone"
+	// argUninstall is the uninstall subcommand accepted by brew.
+	argUninstall = "uninstall"
+	// flagCask switches brew from formulae to casks (GUI apps).
+	flagCask = "--cask"
 	// flagNoConfirm skips interactive prompts for the pacman family of managers.
 	flagNoConfirm = "--noconfirm"
 )
@@ -37,12 +41,13 @@ const (
 // slow or unreliable under concurrency (e.g. winget).
 type bulkListFunc func(ctx context.Context) map[string]bool
 
-// managerCmd defines the install and check commands for a package manager.
-// The placeholder "{pkg}" in args is replaced with the actual package name.
+// managerCmd defines the install, check and uninstall commands for a package
+// manager. The placeholder "{pkg}" in args is replaced with the actual package name.
 type managerCmd struct {
-	install  []string     // command args for install, e.g. {"sudo", "pacman", "-S", "--noconfirm", "{pkg}"}
-	check    []string     // command args for checking install status, e.g. {"pacman", "-Q", "{pkg}"}
-	bulkList bulkListFunc // if set, IsInstalled uses a single bulk query instead of per-package checks
+	install   []string     // command args for install, e.g. {"sudo", "pacman", "-S", "--noconfirm", "{pkg}"}
+	check     []string     // command args for checking install status, e.g. {"pacman", "-Q", "{pkg}"}
+	uninstall []string     // command args for uninstall, e.g. {"brew", "uninstall", "{pkg}"}; nil if unsupported
+	bulkList  bulkListFunc // if set, IsInstalled uses a single bulk query instead of per-package checks
 }
 
 var managerCmds = map[PackageManager]managerCmd{
@@ -51,7 +56,14 @@ var managerCmds = map[PackageManager]managerCmd{
 	Paru:   {install: []string{string(Paru), "-S", flagNoConfirm, pkgPlaceholder}, check: []string{string(Pacman), "-Q", pkgPlaceholder}},
 	Apt:    {install: []string{cmdSudo, cmdAptGet, argInstall, "-y", pkgPlaceholder}, check: []string{"dpkg", "-s", pkgPlaceholder}},
 	Dnf:    {install: []string{cmdSudo, string(Dnf), argInstall, "-y", pkgPlaceholder}, check: []string{"rpm", "-q", pkgPlaceholder}},
-	Brew:   {install: []string{string(Brew), argInstall, pkgPlaceholder}, check: []string{string(Brew), "list", pkgPlaceholder}},
+	Eopkg:  {install: []string{cmdSudo, string(Eopkg), argInstall, "-y", pkgPlaceholder}, bulkList: eopkgBulkList},
+	// No --ask: installs run without a terminal, so emerge would read EOF as "No".
+	Emerge: {install: []string{cmdSudo, string(Emerge), "-v", pkgPlaceholder}, check: []string{"portageq", "has_version", "/", pkgPlaceholder}},
+	Brew:   {install: []string{string(Brew), argInstall, pkgPlaceholder}, check: []string{string(Brew), "list", pkgPlaceholder}, uninstall: []string{string(Brew), argUninstall, pkgPlaceholder}},
+	// Casks run the brew executable; BrewCask is only an identifier.
+	BrewCask: {install: []string{string(Brew), argInstall, flagCask, pkgPlaceholder}, check: []string{string(Brew), "list", flagCask, pkgPlaceholder}, uninstall: []string{string(Brew), argUninstall, flagCask, pkgPlaceholder}},
+	// mas has no per-app query that fails for a missing app.
+	Mas:    {install: []string{string(Mas), argInstall, pkgPlaceholder}, bulkList: masBulkList},
 	Winget: {install: []string{string(Winget), argInstall, "--accept-package-agreements", "--accept-source-agreements", pkgPlaceholder}, bulkList: wingetBulkList},
 	Scoop:  {install: []string{string(Scoop), argInstall, pkgPlaceholder}, check: []string{string(Scoop), "info", pkgPlaceholder}},
 	Choco:  {install: []string{string(Choco), argInstall, "-y", pkgPlaceholder}, check: []string{string(Choco), "list", "--local-only", pkgPlaceholder}},
@@ -88,15 +100,7 @@ func parseWingetListOutput(output string) map[string]bool {
 	lines := cleanWingetOutput(output)
 
 	// Find the header separator line (all dashes) to locate column positions
-	headerIdx := -1
-	for i, line := range lines {
-		trimmed := strings.TrimSpace(line)
-		if len(trimmed) > 0 && strings.Count(trimmed, "-") == len(trimmed) {
-			headerIdx = i
-			break
-		}
-	}
-
+	headerIdx := wingetHeaderSeparator(lines)
 	if headerIdx < 1 {
 		slog.Debug("winget bulk list: could not find header separator")
 		return ids
@@ -140,6 +144,19 @@ func parseWingetListOutput(output string) map[string]bool {
 	return ids
 }
 
+// wingetHeaderSeparator returns the index of the line of dashes under the
+// column names of winget output, or -1.
+func wingetHeaderSeparator(lines []string) int {
+	for i, line := range lines {
+		trimmed := strings.TrimSpace(line)
+		if len(trimmed) > 0 && strings.Count(trimmed, "-") == len(trimmed) {
+			return i
+		}
+	}
+
+	return -1
+}
+
 // cleanWingetOutput handles winget's progress spinner and encoding quirks.
 // When stdout is piped (not a terminal), winget writes \r-based spinner
 // characters that accumulate in the buffer. Windows line endings (\r\n) are
@@ -163,6 +180,68 @@ func cleanWingetOutput(output string) []string {
 	return lines
 }
 
+// eopkgBulkList runs "eopkg list-installed" once and returns the installed
+// package names. eopkg has no per-package query that fails for a missing package.
+func eopkgBulkList(ctx context.Context) map[string]bool {
+	return eopkgBulkListWithRunner(ctx, cmdexec.OsRunner{})
+}
+
+// eopkgBulkListWithRunner runs eopkg list-installed using the given runner.
+func eopkgBulkListWithRunner(ctx context.Context, r cmdexec.Runner) map[string]bool {
+	result, err := r.Run(ctx, string(Eopkg), "list-installed", "--no-color")
+	if err != nil {
+		slog.Debug("eopkg bulk list failed",
+			slog.String("error", err.Error()),
+			slog.String("stderr", strings.TrimSpace(string(result.Stderr))))
+		return make(map[string]bool)
+	}
+
+	return parseEopkgListOutput(string(result.Stdout))
+}
+
+// parseEopkgListOutput extracts package names from eopkg list-installed output,
+// where each line is "name - summary".
+func parseEopkgListOutput(output string) map[string]bool {
+	names := make(map[string]bool)
+
+	for _, line := range strings.Split(output, "\n") {
+		if fields := strings.Fields(line); len(fields) > 0 {
+			names[strings.ToLower(fields[0])] = true
+		}
+	}
+
+	return names
+}
+
+// installArgs returns the install command for val's package through mc,
+// without the sudo prefix when noSudo is set. A winget source is passed with
+// --source. When emerge options set a USE override, the command runs through
+// env so the variable survives sudo, which drops variables it does not know.
+func installArgs(mc managerCmd, val ManagerValue, noSudo bool) []string {
+	args := expandArgs(mc.install, val.PackageName)
+	if noSudo && args[0] == cmdSudo {
+		args = args[1:]
+	}
+
+	if val.Winget != nil && val.Winget.Source != "" && args[0] == string(Winget) {
+		last := len(args) - 1
+		args = append(append(args[:last:last], "--source", val.Winget.Source), args[last])
+	}
+
+	emerge := val.Emerge
+
+	if emerge == nil || emerge.UseFlagsOverride == "" {
+		return args
+	}
+
+	envArgs := []string{"env", "USE=" + emerge.UseFlagsOverride}
+	if args[0] == cmdSudo {
+		return append(append([]string{cmdSudo}, envArgs...), args[1:]...)
+	}
+
+	return append(envArgs, args...)
+}
+
 // expandArgs replaces "{pkg}" placeholders in args with the actual package name.
 func expandArgs(args []string, pkgName string) []string {
 	result := make([]string, len(args))
@@ -177,21 +256,109 @@ func expandArgs(args []string, pkgName string) []string {
 	return result
 }
 
+// UninstallArgs returns the command that uninstalls pkgName through mgr, or
+// nil when tidydots knows no uninstall command for mgr.
+func UninstallArgs(mgr PackageManager, pkgName string) []string {
+	mc, ok := managerCmds[canonicalManager(mgr)]
+	if !ok || mc.uninstall == nil {
+		return nil
+	}
+
+	return expandArgs(mc.uninstall, pkgName)
+}
+
+// GitRequiresSudo reports whether installing pkg with method cannot be done
+// without sudo. That is the case for git packages marked sudo: true; native
+// package managers only lose their sudo prefix under --no-sudo.
+func GitRequiresSudo(pkg Package, method string) bool {
+	if method != string(Git) {
+		return false
+	}
+
+	gitVal, ok := pkg.Managers[Git]
+
+	return ok && gitVal.IsGit() && gitVal.Git.Sudo
+}
+
+// RequiresSudo reports whether the install commands of mgr run through sudo
+// on this machine. Native package managers that change the system, such as
+// pacman and apt, do unless NoSudo is set, the user is root already, or sudo
+// is not installed, as in minimal containers; their commands then run as the
+// current user.
+func (m *Manager) RequiresSudo(mgr PackageManager) bool {
+	mc, ok := managerCmds[mgr]
+	if !ok || len(mc.install) == 0 || mc.install[0] != cmdSudo || m.NoSudo {
+		return false
+	}
+
+	return m.canElevate()
+}
+
+// canElevate reports whether running a command through sudo makes a
+// difference: the user is not root, and sudo is installed.
+func (m *Manager) canElevate() bool {
+	getUID := m.getUID
+	if getUID == nil {
+		getUID = os.Getuid
+	}
+
+	if getUID() == 0 {
+		return false
+	}
+
+	_, err := m.runner.LookPath(cmdSudo)
+
+	return err == nil
+}
+
 // BuildCommand creates an *exec.Cmd for installing a package using the given method.
 // It is a pure command builder — the caller controls execution, stdio wiring, and dry-run logic.
+// Native package managers get their sudo prefix only when RequiresSudo, and
+// under NoSudo packages that GitRequiresSudo get no command.
+// URL downloads are verified unless SkipVerify.
+// The package name is resolved against renderer first, so an override
+// whose when is true replaces it.
 // Returns nil if no command can be built for the given method.
-func BuildCommand(ctx context.Context, pkg Package, method, osType string) *exec.Cmd { //nolint:gocyclo // switch over package manager types is inherently branchy
+func (m *Manager) BuildCommand(ctx context.Context, pkg Package, method string, renderer config.PathRenderer) *exec.Cmd { //nolint:gocyclo // switch over package manager types is inherently branchy
 	pm := PackageManager(method)
+	osType := m.OS
 
 	// Package managers (pacman, yay, apt, etc.)
 	if mc, ok := managerCmds[pm]; ok {
 		if val, exists := pkg.Managers[pm]; exists {
+			val = val.Resolve(renderer)
+
 			// Validate package name before constructing command to prevent flag injection
 			if err := ValidatePackageName(val.PackageName); err != nil {
 				return nil
 			}
 
-			args := expandArgs(mc.install, val.PackageName)
+			if pm == Mas && ValidateMasID(val.PackageName) != nil {
+				return nil
+			}
+
+			args := installArgs(mc, val, !m.RequiresSudo(pm))
+
+			// A scoop bucket or winget source is added first when missing,
+			// in the same PowerShell session as the install.
+			if s, ok := sourceOf(pm, val); ok {
+				if err := s.validate(); err != nil {
+					return nil
+				}
+
+				return exec.CommandContext(ctx, "powershell", "-Command", sourceScript(s, args)) //nolint:gosec // arguments are quoted and validated
+			}
+
+			// An apt repo is added first, in the same shell so a single sudo
+			// prompt covers the setup and the install.
+			if pm == Apt && val.Apt != nil && val.Apt.Repo != "" {
+				if err := ValidateAptRepo(val.Apt.Repo); err != nil {
+					return nil
+				}
+
+				return exec.CommandContext(ctx, "sh", "-c", aptRepoScript(val.Apt.Repo, args, !m.RequiresSudo(Apt))) //nolint:gosec // arguments are quoted and validated
+			}
+
 			return exec.CommandContext(ctx, args[0], args[1:]...) //nolint:gosec // args from trusted lookup table
 		}
 	}
@@ -202,6 +369,11 @@ func BuildCommand(ctx context.Context, pkg Package, method, osType string) *exec
 		if !ok || !gitVal.IsGit() {
 			return nil
 		}
+		if m.NoSudo && gitVal.Git.Sudo {
+			return nil
+		}
+		// Root clones without sudo, and so does a machine without it.
+		sudo := gitVal.Git.Sudo && m.canElevate()
 		if err := validateURLScheme(gitVal.Git.URL); err != nil {
 			slog.Warn("git URL rejected", slog.String("error", err.Error()))
 			return nil
@@ -210,14 +382,22 @@ func BuildCommand(ctx context.Context, pkg Package, method, osType string) *exec
 		if target == "" {
 			return nil
 		}
+		if _, ok := validateGitOptions(*gitVal.Git); !ok {
+			return nil
+		}
 		// Expand ~ since git clone doesn't do shell tilde expansion
 		target = config.ExpandPath(target, nil)
-		args := []string{argClone}
-		if gitVal.Git.Branch != "" {
-			args = append(args, "-b", gitVal.Git.Branch)
+		opts := gitCloneOptions(*gitVal.Git)
+		args := gitutil.CloneArgs(gitVal.Git.URL, target, opts)
+		// A sparse clone is followed by sparse-checkout set, in one shell.
+		if len(opts.Sparse) > 0 {
+			cmds := [][]string{args, gitutil.SparseCheckoutArgs(target, opts.Sparse)}
+			if osType == platform.OSWindows {
+				return exec.CommandContext(ctx, "powershell", "-Command", gitPowerShellScript(cmds)) //nolint:gosec // arguments are quoted and validated
+			}
+			return exec.CommandContext(ctx, "sh", "-c", gitShellScript(cmds, sudo)) //nolint:gosec // arguments are quoted and validated
 		}
-		args = append(args, gitVal.Git.URL, target)
-		if gitVal.Git.Sudo {
+		if sudo {
 			args = append([]string{cmdGit}, args...)
 			return exec.CommandContext(ctx, cmdSudo, args...) //nolint:gosec // intentional command from user config
 		}
@@ -232,6 +412,7 @@ func BuildCommand(ctx context.Context, pkg Package, method, osType string) *exec
 		if !hasCmd {
 			return nil
 		}
+		command = withVerify(command, installerVal.Installer.Verify[osType], osType)
 		if osType == platform.OSWindows {
 			return exec.CommandContext(ctx, "powershell", "-Command", command) //nolint:gosec // intentional install command from user config
 		}
@@ -242,6 +423,7 @@ func BuildCommand(ctx context.Context, pkg Package, method, osType string) *exec
 		if !ok {
 			return nil
 		}
+		command = withVerify(command, pkg.Verify[osType], osType)
 		if osType == platform.OSWindows {
 			return exec.CommandContext(ctx, "powershell", "-Command", command) //nolint:gosec // intentional command from user config
 		}
@@ -256,28 +438,95 @@ func BuildCommand(ctx context.Context, pkg Package, method, osType string) *exec
 			slog.Warn("URL rejected", slog.String("error", err.Error()))
 			return nil
 		}
+		// Like installFromURL, the download goes into a private temp
+		// directory, and is verified unless SkipVerify.
+		verify := hasVerification(urlInstall) && !m.SkipVerify
 		if osType == platform.OSWindows {
 			escapedURL := escapePowerShellSingleQuote(urlInstall.URL)
 			escapedCmd := escapePowerShellSingleQuote(urlInstall.Command)
+			verifyScript := ""
+			if verify {
+				verifyScript = powerShellVerifyScript(urlInstall)
+			}
 			script := fmt.Sprintf(`
-				$tmpFile = [System.IO.Path]::GetTempFileName()
-				Invoke-WebRequest -Uri '%s' -OutFile $tmpFile
-				$command = '%s' -replace '\{file\}', $tmpFile
-				Invoke-Expression $command
-				Remove-Item $tmpFile -ErrorAction SilentlyContinue
-			`, escapedURL, escapedCmd)
+				$tmpDir = Join-Path ([System.IO.Path]::GetTempPath()) ('tidydots-' + [System.IO.Path]::GetRandomFileName())
+				New-Item -ItemType Directory -Path $tmpDir | Out-Null
+				$tmpFile = Join-Path $tmpDir 'installer'
+				try {
+					Invoke-WebRequest -Uri '%s' -OutFile $tmpFile
+					%s$command = '%s' -replace '\{file\}', $tmpFile
+					Invoke-Expression $command
+				} finally {
+					Remove-Item $tmpDir -Recurse -Force -ErrorAction SilentlyContinue
+				}
+			`, escapedURL, verifyScript, escapedCmd)
 			return exec.CommandContext(ctx, "powershell", "-Command", script) //nolint:gosec // intentional command from user config
 		}
 		escapedURL := escapeShellSingleQuote(urlInstall.URL)
+		verifyScript := ""
+		if verify {
+			verifyScript = shellVerifyScript(urlInstall)
+		}
 		script := fmt.Sprintf(`
-			tmpfile=$(mktemp)
-			trap "rm -f $tmpfile" EXIT
-			curl -fsSL -o "$tmpfile" '%s' && \
-			chmod +x "$tmpfile" && \
+			tmpdir=$(mktemp -d) && chmod 700 "$tmpdir" || exit 1
+			trap 'rm -rf "$tmpdir"' EXIT
+			tmpfile="$tmpdir/installer"
+			curl -fsSL -o "$tmpfile" '%s' || exit 1
+			%schmod +x "$tmpfile" && \
 			%s
-		`, escapedURL, strings.ReplaceAll(urlInstall.Command, "{file}", "$tmpfile"))
+		`, escapedURL, verifyScript, strings.ReplaceAll(urlInstall.Command, "{file}", "$tmpfile"))
 		return exec.CommandContext(ctx, "sh", "-c", script) //nolint:gosec // intentional command from user config
 	}
 
 	return nil
 }
+
+// withVerify returns a script that runs command and then verify, failing
+// with MsgVerifyFailed when command succeeds but verify does not, the way
+// Manager.Install reports it. command runs in its own scope so an exit in it
+// does not skip the verification. Returns command unchanged when verify is
+// empty.
+func withVerify(command, verify, osType string) string {
+	if verify == "" {
+		return command
+	}
+
+	if osType == platform.OSWindows {
+		return fmt.Sprintf("& {\n%s\n}\nif (-not $?) { exit 1 }\n& {\n%s\n}\nif (-not $?) { Write-Error '%s'; exit 1 }",
+			command, verify, MsgVerifyFailed)
+	}
+
+	return fmt.Sprintf("(\n%s\n) || exit $?\n(\n%s\n) || { echo '%s' >&2; exit 1; }", command, verify, MsgVerifyFailed)
+}
+
+// gitShellScript renders git commands as a POSIX shell "a && b" list, each
+// run through sudo when sudo is set.
+func gitShellScript(cmds [][]string, sudo bool) string {
+	prefixed := make([][]string, len(cmds))
+	for i, args := range cmds {
+		prefixed[i] = append([]string{cmdGit}, args...)
+		if sudo {
+			prefixed[i] = append([]string{cmdSudo}, prefixed[i]...)
+		}
+	}
+
+	return quoteCommands(prefixed)
+}
+
+// gitPowerShellScript renders git commands as a PowerShell script that stops
+// at the first one that fails.
+func gitPowerShellScript(cmds [][]string) string {
+	var b strings.Builder
+
+	for i, args := range cmds {
+		if i > 0 {
+			b.WriteString("; if ($LASTEXITCODE -ne 0) { exit $LASTEXITCODE }; ")
+		}
+
+		b.WriteString(quotePowerShellArgs(append([]string{cmdGit}, args...)))
+	}
+
+	b.WriteString("; exit $LASTEXITCODE")
+
+	return b.String()
+}
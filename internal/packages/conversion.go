@@ -6,18 +6,33 @@ import (
 
 // FilterPackages returns packages that match the given when expressions.
 // It evaluates each package's When expression and returns only those that match.
+// Applications are filtered by their own when before their packages are
+// converted, so this only applies the package's when.
 func FilterPackages(packages []Package, renderer config.PathRenderer) []Package {
 	result := make([]Package, 0, len(packages))
 
 	for _, pkg := range packages {
 		if config.EvaluateWhen(pkg.When, renderer) {
-			result = append(result, pkg)
+			result = append(result, pkg.ResolveNames(renderer))
 		}
 	}
 
 	return result
 }
 
+// ResolveNames returns pkg with the package name of each manager replaced
+// by its override for this machine, if any; see config.ManagerValue.Resolve.
+func (p Package) ResolveNames(renderer config.PathRenderer) Package {
+	managers := make(map[PackageManager]ManagerValue, len(p.Managers))
+	for mgr, val := range p.Managers {
+		managers[mgr] = val.Resolve(renderer)
+	}
+
+	p.Managers = managers
+
+	return p
+}
+
 // convertPackage converts a config.EntryPackage into Package fields (managers, custom, url).
 // This is the shared conversion logic used by FromApplication and FromPackageSpec.
 // Since GitConfig, InstallerConfig, ManagerValue, and URLInstall are now type aliases
@@ -52,8 +67,12 @@ func FromApplication(app config.Application) *Package {
 		Description: app.Description,
 		Managers:    managers,
 		Custom:      custom,
+		Verify:      app.Package.Verify,
 		URL:         urlInstalls,
-		When:        app.When,
+		When:        app.Package.When,
+		Phase:       app.Package.Phase,
+		After:       app.Package.After,
+		Prefer:      PackageManager(app.Package.Prefer),
 	}
 }
 
@@ -74,7 +93,7 @@ func FromApplications(apps []config.Application) []Package {
 
 // FromPackageSpec creates a Package from a name and EntryPackage.
 // This is used by the TUI's buildInstallCommand which only has a name and
-// package spec (no description/when, but those aren't needed by BuildCommand).
+// package spec (no description, but that isn't needed by BuildCommand).
 // Returns nil if pkg is nil.
 func FromPackageSpec(name string, pkg *config.EntryPackage) *Package {
 	if pkg == nil {
@@ -87,6 +106,11 @@ func FromPackageSpec(name string, pkg *config.EntryPackage) *Package {
 		Name:     name,
 		Managers: managers,
 		Custom:   custom,
+		Verify:   pkg.Verify,
 		URL:      urlInstalls,
+		Phase:    pkg.Phase,
+		After:    pkg.After,
+		Prefer:   PackageManager(pkg.Prefer),
+		When:     pkg.When,
 	}
 }
@@ -0,0 +1,333 @@
+package packages
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/AntoineGS/tidydots/internal/cmdexec"
+	"github.com/AntoineGS/tidydots/internal/config"
+)
+
+// wingetDefaultSource is the source winget installs from unless told
+// otherwise; packages from it need no source in the config.
+const wingetDefaultSource = "winget"
+
+// scoopDefaultBucket is the bucket scoop knows without scoop bucket add.
+const scoopDefaultBucket = "main"
+
+// ExplicitPackage is a package the user installed with a manager, as opposed
+// to one pulled in as a dependency. Value is what the manager's key of an
+// application's package.managers holds to install it again, including the
+// scoop bucket or winget source it came from.
+type ExplicitPackage struct {
+	Name  string
+	Value ManagerValue
+}
+
+// explicitQuery lists the packages installed explicitly with a manager.
+type explicitQuery func(ctx context.Context, r cmdexec.Runner) ([]ExplicitPackage, error)
+
+// explicitQueries holds the managers that can list explicitly installed
+// packages.
+var explicitQueries = map[PackageManager]explicitQuery{
+	Pacman: pacmanExplicit,
+	Apt:    aptExplicit,
+	Brew:   brewExplicit,
+	Winget: wingetExplicit,
+	Scoop:  scoopExplicit,
+}
+
+// ExplicitManagers returns the managers ExplicitPackages supports, sorted.
+func ExplicitManagers() []PackageManager {
+	return slices.Sorted(maps.Keys(explicitQueries))
+}
+
+// ExplicitPackages asks mgr which packages were installed explicitly on this
+// machine, sorted by name. It fails for a manager ExplicitManagers does not
+// list, or when the manager's command fails.
+func (m *Manager) ExplicitPackages(mgr PackageManager) ([]ExplicitPackage, error) {
+	query, ok := explicitQueries[mgr]
+	if !ok {
+		return nil, fmt.Errorf("cannot list installed packages of %s; supported managers: %v", mgr, ExplicitManagers())
+	}
+
+	pkgs, err := query(m.ctx, m.runner)
+	if err != nil {
+		return nil, fmt.Errorf("listing packages installed with %s: %w", mgr, err)
+	}
+
+	slices.SortFunc(pkgs, func(a, b ExplicitPackage) int { return strings.Compare(a.Name, b.Name) })
+
+	return slices.CompactFunc(pkgs, func(a, b ExplicitPackage) bool { return a.Name == b.Name }), nil
+}
+
+// pacmanExplicit runs "pacman -Qe", which lists the packages not installed
+// as dependencies, AUR ones included.
+func pacmanExplicit(ctx context.Context, r cmdexec.Runner) ([]ExplicitPackage, error) {
+	res, err := r.Run(ctx, string(Pacman), "-Qe")
+	if err != nil {
+		return nil, err
+	}
+
+	return parsePacmanExplicit(string(res.Stdout)), nil
+}
+
+// parsePacmanExplicit returns the packages of "pacman -Qe" output, a
+// "name version" line each.
+func parsePacmanExplicit(output string) []ExplicitPackage {
+	var pkgs []ExplicitPackage
+
+	for line := range strings.Lines(output) {
+		if fields := strings.Fields(line); len(fields) > 0 {
+			pkgs = append(pkgs, ExplicitPackage{Name: fields[0], Value: ManagerValue{PackageName: fields[0]}})
+		}
+	}
+
+	return pkgs
+}
+
+// aptExplicit runs "apt-mark showmanual".
+func aptExplicit(ctx context.Context, r cmdexec.Runner) ([]ExplicitPackage, error) {
+	res, err := r.Run(ctx, "apt-mark", "showmanual")
+	if err != nil {
+		return nil, err
+	}
+
+	return parseNameLines(string(res.Stdout)), nil
+}
+
+// brewExplicit runs "brew leaves --installed-on-request", the formulae
+// installed by name that no other formula depends on.
+func brewExplicit(ctx context.Context, r cmdexec.Runner) ([]ExplicitPackage, error) {
+	res, err := r.Run(ctx, string(Brew), "leaves", "--installed-on-request")
+	if err != nil {
+		return nil, err
+	}
+
+	return parseNameLines(string(res.Stdout)), nil
+}
+
+// parseNameLines returns the packages of output naming one per line, as
+// "apt-mark showmanual" and "brew leaves" print them.
+func parseNameLines(output string) []ExplicitPackage {
+	var pkgs []ExplicitPackage
+
+	for line := range strings.Lines(output) {
+		if name := strings.TrimSpace(line); name != "" {
+			pkgs = append(pkgs, ExplicitPackage{Name: name, Value: ManagerValue{PackageName: name}})
+		}
+	}
+
+	return pkgs
+}
+
+// wingetExplicit runs "winget export", which only writes to a file, into a
+// temporary directory and reads the file back.
+func wingetExplicit(ctx context.Context, r cmdexec.Runner) ([]ExplicitPackage, error) {
+	dir, err := os.MkdirTemp("", "tidydots-winget-")
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = os.RemoveAll(dir) }()
+
+	out := filepath.Join(dir, "export.json")
+
+	if _, err := r.Run(ctx, string(Winget), "export", "--output", out,
+		"--disable-interactivity", "--accept-source-agreements"); err != nil {
+		return nil, err
+	}
+
+	data, err := os.ReadFile(out) //nolint:gosec // path is built above in our own temp dir
+	if err != nil {
+		return nil, fmt.Errorf("reading winget export: %w", err)
+	}
+
+	return parseWingetExport(data)
+}
+
+// wingetExport is the part of a "winget export" file naming the packages.
+type wingetExport struct {
+	Sources []struct {
+		Packages []struct {
+			PackageIdentifier string
+		}
+		SourceDetails struct {
+			Name string
+		}
+	}
+}
+
+// parseWingetExport returns the packages of a "winget export" file, with
+// the source of those not from the default one.
+func parseWingetExport(data []byte) ([]ExplicitPackage, error) {
+	var export wingetExport
+	if err := json.Unmarshal(data, &export); err != nil {
+		return nil, fmt.Errorf("parsing winget export: %w", err)
+	}
+
+	var pkgs []ExplicitPackage
+
+	for _, source := range export.Sources {
+		for _, p := range source.Packages {
+			if p.PackageIdentifier == "" {
+				continue
+			}
+
+			value := ManagerValue{PackageName: p.PackageIdentifier}
+			if name := source.SourceDetails.Name; name != "" && name != wingetDefaultSource {
+				value.Winget = &WingetOptions{Source: name}
+			}
+
+			pkgs = append(pkgs, ExplicitPackage{Name: p.PackageIdentifier, Value: value})
+		}
+	}
+
+	return pkgs, nil
+}
+
+// scoopExplicit runs "scoop export". Scoop only installs what it is asked
+// to, so every app it lists is explicit.
+func scoopExplicit(ctx context.Context, r cmdexec.Runner) ([]ExplicitPackage, error) {
+	res, err := r.Run(ctx, string(Scoop), "export")
+	if err != nil {
+		return nil, err
+	}
+
+	return parseScoopExport(res.Stdout)
+}
+
+// scoopExport is the part of "scoop export" JSON output naming the apps.
+type scoopExport struct {
+	Apps []struct {
+		Name   string
+		Source string
+	} `json:"apps"`
+}
+
+// parseScoopExport returns the apps of "scoop export" output, with the
+// bucket of those not from the main one. Scoop before 0.4 printed a
+// "name (v:version) [bucket]" line per app instead of JSON; both are read.
+func parseScoopExport(data []byte) ([]ExplicitPackage, error) {
+	text := strings.TrimSpace(string(data))
+	if !strings.HasPrefix(text, "{") {
+		return parseScoopExportLines(text), nil
+	}
+
+	var export scoopExport
+	if err := json.Unmarshal(data, &export); err != nil {
+		return nil, fmt.Errorf("parsing scoop export: %w", err)
+	}
+
+	pkgs := make([]ExplicitPackage, 0, len(export.Apps))
+
+	for _, app := range export.Apps {
+		if app.Name != "" {
+			pkgs = append(pkgs, scoopPackage(app.Name, app.Source))
+		}
+	}
+
+	return pkgs, nil
+}
+
+// parseScoopExportLines returns the apps of the line-based output of older
+// scoop exports.
+func parseScoopExportLines(text string) []ExplicitPackage {
+	var pkgs []ExplicitPackage
+
+	for line := range strings.Lines(text) {
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+
+		bucket := ""
+		if last := fields[len(fields)-1]; len(fields) > 1 && strings.HasPrefix(last, "[") {
+			bucket = strings.Trim(last, "[]")
+		}
+
+		pkgs = append(pkgs, scoopPackage(fields[0], bucket))
+	}
+
+	return pkgs
+}
+
+// scoopPackage returns the package of a scoop app from bucket. Buckets
+// given as a URL or path are left out; scoop bucket add needs a name.
+func scoopPackage(name, bucket string) ExplicitPackage {
+	value := ManagerValue{PackageName: name}
+	if bucket != "" && bucket != scoopDefaultBucket && !strings.ContainsAny(bucket, `/\`) {
+		value.Scoop = &ScoopOptions{Bucket: bucket}
+	}
+
+	return ExplicitPackage{Name: name, Value: value}
+}
+
+// ImportApplications returns an application per package of pkgs, with only
+// a package installed with mgr, leaving out those cfg already has: when an
+// application has the package's name, or already installs it with mgr. The
+// names of the packages left out are returned too.
+//
+// Applications are named after their package, except winget ones: the last
+// part of the identifier, lowercased, e.g. git for Git.Git, unless another
+// package or application already has that name.
+func ImportApplications(cfg *config.Config, mgr PackageManager, pkgs []ExplicitPackage) (apps []config.Application, skipped []string) {
+	taken := make(map[string]bool, len(cfg.Applications))
+	installed := make(map[string]bool)
+
+	for _, app := range cfg.Applications {
+		taken[app.Name] = true
+
+		if app.Package == nil {
+			continue
+		}
+
+		if val, ok := app.Package.Managers[string(mgr)]; ok && val.PackageName != "" {
+			installed[val.PackageName] = true
+		}
+	}
+
+	for _, pkg := range pkgs {
+		if taken[pkg.Name] || installed[pkg.Name] {
+			skipped = append(skipped, pkg.Name)
+			continue
+		}
+
+		name := pkg.Name
+		if short := importedName(mgr, pkg.Name); !taken[short] && !slices.ContainsFunc(pkgs, func(p ExplicitPackage) bool {
+			return p.Name != pkg.Name && importedName(mgr, p.Name) == short
+		}) {
+			name = short
+		}
+
+		taken[name] = true
+
+		apps = append(apps, config.Application{
+			Name: name,
+			Package: &config.EntryPackage{
+				Managers: map[string]ManagerValue{string(mgr): pkg.Value},
+			},
+		})
+	}
+
+	return apps, skipped
+}
+
+// importedName returns the application name ImportApplications prefers for
+// the package name of mgr.
+func importedName(mgr PackageManager, name string) string {
+	if mgr != Winget {
+		return name
+	}
+
+	if i := strings.LastIndexByte(name, '.'); i >= 0 && i < len(name)-1 {
+		name = name[i+1:]
+	}
+
+	return strings.ToLower(name)
+}
@@ -0,0 +1,223 @@
+package packages
+
+import (
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/AntoineGS/tidydots/internal/cmdexec"
+	"github.com/AntoineGS/tidydots/internal/config"
+)
+
+// explicitNames returns the names of pkgs.
+func explicitNames(pkgs []ExplicitPackage) []string {
+	names := make([]string, len(pkgs))
+	for i, p := range pkgs {
+		names[i] = p.Name
+	}
+
+	return names
+}
+
+func TestParsePacmanExplicit(t *testing.T) {
+	t.Parallel()
+
+	got := parsePacmanExplicit("base 3-2\nneovim 0.10.2-1\nyay-bin 12.4.2-1\n\n")
+	if want := []string{"base", "neovim", "yay-bin"}; !slices.Equal(explicitNames(got), want) {
+		t.Errorf("parsePacmanExplicit() = %v, want %v", explicitNames(got), want)
+	}
+
+	if got[1].Value.PackageName != "neovim" {
+		t.Errorf("PackageName = %q, want neovim", got[1].Value.PackageName)
+	}
+}
+
+func TestParseNameLines(t *testing.T) {
+	t.Parallel()
+
+	// apt-mark showmanual and brew leaves print a name per line.
+	got := parseNameLines("git\nripgrep\n  \ntmux\n")
+	if want := []string{"git", "ripgrep", "tmux"}; !slices.Equal(explicitNames(got), want) {
+		t.Errorf("parseNameLines() = %v, want %v", explicitNames(got), want)
+	}
+}
+
+func TestParseWingetExport(t *testing.T) {
+	t.Parallel()
+
+	data := []byte(`{
+  "$schema" : "https://aka.ms/winget-packages.schema.2.0.json",
+  "CreationDate" : "2026-10-17T10:00:00.000-00:00",
+  "Sources" : [
+    {
+      "Packages" : [
+        { "PackageIdentifier" : "Git.Git" },
+        { "PackageIdentifier" : "Microsoft.PowerToys" }
+      ],
+      "SourceDetails" : { "Argument" : "https://cdn.winget.microsoft.com/cache", "Identifier" : "Microsoft.Winget.Source_8wekyb3d8bbwe", "Name" : "winget", "Type" : "Microsoft.PreIndexed.Package" }
+    },
+    {
+      "Packages" : [ { "PackageIdentifier" : "9NBLGGH4NNS1" } ],
+      "SourceDetails" : { "Argument" : "https://storeedgefd.dsx.mp.microsoft.com/v9.0", "Identifier" : "StoreEdgeFD", "Name" : "msstore", "Type" : "Microsoft.Rest" }
+    }
+  ],
+  "WinGetVersion" : "1.9.25200"
+}`)
+
+	got, err := parseWingetExport(data)
+	if err != nil {
+		t.Fatalf("parseWingetExport() error = %v", err)
+	}
+
+	if want := []string{"Git.Git", "Microsoft.PowerToys", "9NBLGGH4NNS1"}; !slices.Equal(explicitNames(got), want) {
+		t.Fatalf("parseWingetExport() = %v, want %v", explicitNames(got), want)
+	}
+
+	if got[0].Value.Winget != nil {
+		t.Errorf("winget source package has options %+v, want none", got[0].Value.Winget)
+	}
+
+	if got[2].Value.Winget == nil || got[2].Value.Winget.Source != "msstore" {
+		t.Errorf("msstore package options = %+v, want source msstore", got[2].Value.Winget)
+	}
+
+	if _, err := parseWingetExport([]byte("not json")); err == nil {
+		t.Error("parseWingetExport(invalid) error = nil, want an error")
+	}
+}
+
+func TestParseScoopExport(t *testing.T) {
+	t.Parallel()
+
+	data := []byte(`{
+  "buckets": [
+    { "Name": "main", "Source": "https://github.com/ScoopInstaller/Main", "Updated": "2026-10-16T09:12:00+02:00", "Manifests": 1400 },
+    { "Name": "extras", "Source": "https://github.com/ScoopInstaller/Extras", "Updated": "2026-10-16T09:12:00+02:00", "Manifests": 2100 }
+  ],
+  "apps": [
+    { "Name": "7zip", "Version": "24.08", "Source": "main", "Updated": "2026-09-01T10:00:00+02:00", "Info": "" },
+    { "Name": "vscode", "Version": "1.94.2", "Source": "extras", "Updated": "2026-10-10T10:00:00+02:00", "Info": "" }
+  ]
+}`)
+
+	got, err := parseScoopExport(data)
+	if err != nil {
+		t.Fatalf("parseScoopExport() error = %v", err)
+	}
+
+	if want := []string{"7zip", "vscode"}; !slices.Equal(explicitNames(got), want) {
+		t.Fatalf("parseScoopExport() = %v, want %v", explicitNames(got), want)
+	}
+
+	if got[0].Value.Scoop != nil {
+		t.Errorf("main bucket app has options %+v, want none", got[0].Value.Scoop)
+	}
+
+	if got[1].Value.Scoop == nil || got[1].Value.Scoop.Bucket != "extras" {
+		t.Errorf("extras app options = %+v, want bucket extras", got[1].Value.Scoop)
+	}
+}
+
+func TestParseScoopExport_Lines(t *testing.T) {
+	t.Parallel()
+
+	// Scoop before 0.4 printed a line per app.
+	got, err := parseScoopExport([]byte("7zip (v:24.08) [main]\nneovim (v:0.10.2) *global* [extras]\nlocal (v:1.0) [C:\\manifests]\n"))
+	if err != nil {
+		t.Fatalf("parseScoopExport() error = %v", err)
+	}
+
+	if want := []string{"7zip", "neovim", "local"}; !slices.Equal(explicitNames(got), want) {
+		t.Fatalf("parseScoopExport() = %v, want %v", explicitNames(got), want)
+	}
+
+	if got[1].Value.Scoop == nil || got[1].Value.Scoop.Bucket != "extras" {
+		t.Errorf("extras app options = %+v, want bucket extras", got[1].Value.Scoop)
+	}
+
+	if got[2].Value.Scoop != nil {
+		t.Errorf("app from a path has options %+v, want none", got[2].Value.Scoop)
+	}
+}
+
+func TestExplicitPackages(t *testing.T) {
+	t.Parallel()
+
+	mgr, stub := newStubManager(t, "linux")
+	stub.AddResult("pacman", cmdexec.Result{Stdout: []byte("neovim 0.10.2-1\ngit 2.47.0-1\n")})
+
+	got, err := mgr.ExplicitPackages(Pacman)
+	if err != nil {
+		t.Fatalf("ExplicitPackages() error = %v", err)
+	}
+
+	if want := []string{"git", "neovim"}; !slices.Equal(explicitNames(got), want) {
+		t.Errorf("ExplicitPackages() = %v, want sorted %v", explicitNames(got), want)
+	}
+
+	if call := stub.Calls[0]; call.Name != "pacman" || !slices.Equal(call.Args, []string{"-Qe"}) {
+		t.Errorf("ran %s %v, want pacman -Qe", call.Name, call.Args)
+	}
+}
+
+func TestExplicitPackages_Errors(t *testing.T) {
+	t.Parallel()
+
+	mgr, stub := newStubManager(t, "linux")
+
+	if _, err := mgr.ExplicitPackages(Dnf); err == nil {
+		t.Error("ExplicitPackages(dnf) error = nil, want unsupported manager")
+	}
+
+	stub.AddError("apt-mark", errors.New("exit status 1"))
+
+	if _, err := mgr.ExplicitPackages(Apt); err == nil {
+		t.Error("ExplicitPackages(apt) error = nil, want the command's error")
+	}
+}
+
+func TestImportApplications(t *testing.T) {
+	t.Parallel()
+
+	cfg := &config.Config{Applications: []config.Application{
+		{Name: "git"},
+		{Name: "editor", Package: &config.EntryPackage{Managers: map[string]ManagerValue{
+			"winget": {PackageName: "Neovim.Neovim"},
+		}}},
+	}}
+
+	pkgs := []ExplicitPackage{
+		{Name: "Git.Git", Value: ManagerValue{PackageName: "Git.Git"}},
+		{Name: "Neovim.Neovim", Value: ManagerValue{PackageName: "Neovim.Neovim"}},
+		{Name: "Microsoft.PowerToys", Value: ManagerValue{PackageName: "Microsoft.PowerToys"}},
+		{Name: "Mozilla.Firefox", Value: ManagerValue{PackageName: "Mozilla.Firefox"}},
+		{Name: "Other.Firefox", Value: ManagerValue{PackageName: "Other.Firefox"}},
+	}
+
+	apps, skipped := ImportApplications(cfg, Winget, pkgs)
+
+	names := make([]string, len(apps))
+	for i, app := range apps {
+		names[i] = app.Name
+	}
+
+	// git is taken, so Git.Git keeps its identifier; the two Firefoxes
+	// would share a name.
+	if want := []string{"Git.Git", "powertoys", "Mozilla.Firefox", "Other.Firefox"}; !slices.Equal(names, want) {
+		t.Errorf("application names = %v, want %v", names, want)
+	}
+
+	if want := []string{"Neovim.Neovim"}; !slices.Equal(skipped, want) {
+		t.Errorf("skipped = %v, want %v", skipped, want)
+	}
+
+	if got := apps[1].Package.Managers["winget"].PackageName; got != "Microsoft.PowerToys" {
+		t.Errorf("powertoys winget package = %q, want Microsoft.PowerToys", got)
+	}
+
+	// A package named like an application is already configured.
+	apps, skipped = ImportApplications(cfg, Pacman, []ExplicitPackage{{Name: "git"}, {Name: "tmux"}})
+	if len(apps) != 1 || apps[0].Name != "tmux" || !slices.Equal(skipped, []string{"git"}) {
+		t.Errorf("pacman import = %v skipped %v, want tmux added and git skipped", apps, skipped)
+	}
+}
@@ -1,21 +1,111 @@
 package packages
 
 import (
+	"cmp"
+	"context"
 	"fmt"
+	"log/slog"
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/AntoineGS/tidydots/internal/config"
+	"github.com/AntoineGS/tidydots/internal/gitutil"
 	"github.com/AntoineGS/tidydots/internal/platform"
 )
 
-// Install installs a single package using the best available method.
+// Install installs a single package using the best available method, under
+// the Manager's context (see WithContext). It is InstallWithContext with that
+// context.
+func (m *Manager) Install(pkg Package) InstallResult {
+	return m.InstallWithContext(m.ctx, pkg)
+}
+
+// InstallWithContext installs a single package using the best available method.
 // It tries git packages first, then package managers (in order of availability),
 // then custom commands, and finally URL-based installation. Returns an InstallResult
 // indicating success or failure with a descriptive message.
-func (m *Manager) Install(pkg Package) InstallResult {
-	result := InstallResult{Package: pkg.Name}
+//
+// A failed install is retried up to m.InstallRetries times, waiting
+// m.RetryDelay before the first retry and twice as long before each next one,
+// up to maxRetryDelay. The result's Attempts counts every try.
+//
+// Every command the install runs is bound to ctx. A package whose ctx is
+// canceled, before or during its install, fails with a message starting with
+// MsgCanceled, and is not retried.
+func (m *Manager) InstallWithContext(ctx context.Context, pkg Package) InstallResult {
+	if err := ctx.Err(); err != nil {
+		return InstallResult{
+			Package: pkg.Name,
+			Phase:   pkg.Phase,
+			Message: fmt.Sprintf("%s: %v", MsgCanceled, err),
+		}
+	}
+
+	mc := m.WithContext(ctx)
+
+	var result InstallResult
+
+	for attempt := 1; ; attempt++ {
+		result = mc.install(pkg)
+		result.Attempts = attempt
+
+		if result.Success || m.DryRun || attempt > m.InstallRetries || ctx.Err() != nil {
+			break
+		}
+
+		delay := retryDelay(m.RetryDelay, attempt)
+		slog.Debug("retrying package install",
+			slog.String("package", pkg.Name),
+			slog.Int("attempt", attempt+1),
+			slog.Duration("delay", delay),
+			slog.String("error", result.Message))
+
+		if !sleepContext(ctx, delay) {
+			break
+		}
+	}
+
+	if !result.Success && ctx.Err() != nil {
+		result.Message = fmt.Sprintf("%s: %s", MsgCanceled, result.Message)
+	}
+
+	return result
+}
+
+// maxRetryDelay caps the wait between two install attempts.
+const maxRetryDelay = 60 * time.Second
+
+// retryDelay returns how long to wait after the given failed attempt: base,
+// doubled for each attempt after the first, capped at maxRetryDelay.
+func retryDelay(base time.Duration, attempt int) time.Duration {
+	delay := base
+	for i := 1; i < attempt && delay < maxRetryDelay; i++ {
+		delay *= 2
+	}
+
+	return min(delay, maxRetryDelay)
+}
+
+// sleepContext waits for d, returning false early if ctx is canceled first.
+func sleepContext(ctx context.Context, d time.Duration) bool {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
+// install is InstallWithContext under m.ctx, without the cancellation checks.
+func (m *Manager) install(pkg Package) InstallResult {
+	result := InstallResult{Package: pkg.Name, Phase: pkg.Phase}
 
 	// Validate all package names before executing any commands to prevent flag injection
 	if method, msg, ok := validatePackageNames(pkg); !ok {
@@ -26,6 +116,15 @@ func (m *Manager) Install(pkg Package) InstallResult {
 		return result
 	}
 
+	if m.Offline {
+		slog.Info("skipping (offline mode)", slog.String("package", pkg.Name))
+
+		result.Method = m.GetInstallMethod(pkg)
+		result.Success, result.Skipped, result.Message = true, true, MsgOffline
+
+		return result
+	}
+
 	// Phase 1: Install dependencies across all managers
 	if method, msg, ok := m.installDeps(pkg); !ok {
 		result.Method = method
@@ -37,6 +136,12 @@ func (m *Manager) Install(pkg Package) InstallResult {
 	// Check if this is a git package
 	if gitValue, ok := pkg.Managers[Git]; ok && gitValue.IsGit() {
 		result.Method = string(Git)
+		if m.NoSudo && gitValue.Git.Sudo {
+			result.Success = true
+			result.Skipped = true
+			result.Message = MsgRequiresSudo
+			return result
+		}
 		success, msg := m.installGitPackage(*gitValue.Git)
 		result.Success = success
 		result.Message = msg
@@ -47,6 +152,9 @@ func (m *Manager) Install(pkg Package) InstallResult {
 	if installerValue, ok := pkg.Managers[Installer]; ok && installerValue.IsInstaller() {
 		result.Method = string(Installer)
 		success, msg := m.installInstallerPackage(*installerValue.Installer)
+		if success {
+			success, msg, result.Unverified = m.verifyInstall(installerValue.Installer.Verify[m.OS], msg)
+		}
 		result.Success = success
 		result.Message = msg
 		return result
@@ -54,7 +162,7 @@ func (m *Manager) Install(pkg Package) InstallResult {
 
 	// Try package managers
 	if len(pkg.Managers) > 0 {
-		for _, mgr := range m.Available {
+		for _, mgr := range m.managerOrder(pkg) {
 			// Skip git and installer managers (already handled above)
 			if mgr == Git || mgr == Installer {
 				continue
@@ -62,7 +170,7 @@ func (m *Manager) Install(pkg Package) InstallResult {
 
 			if val, ok := pkg.Managers[mgr]; ok {
 				result.Method = string(mgr)
-				success, msg := m.installWithManager(mgr, val.PackageName)
+				success, msg := m.installNative(mgr, val)
 				result.Success = success
 				result.Message = msg
 
@@ -75,6 +183,9 @@ func (m *Manager) Install(pkg Package) InstallResult {
 	if cmd, ok := pkg.Custom[m.OS]; ok {
 		result.Method = MethodCustom
 		success, msg := m.runCustomCommand(cmd)
+		if success {
+			success, msg, result.Unverified = m.verifyInstall(pkg.Verify[m.OS], msg)
+		}
 		result.Success = success
 		result.Message = msg
 
@@ -97,9 +208,10 @@ func (m *Manager) Install(pkg Package) InstallResult {
 	return result
 }
 
-// validatePackageNames checks that all package names and dependency names in the
-// package are safe for use as CLI arguments. It returns the manager method, an
-// error message, and false if any name is invalid.
+// validatePackageNames checks that all package names, dependency names, apt
+// repositories, App Store IDs, scoop buckets and winget sources in the package are safe for use as CLI
+// arguments. It returns the manager method, an error message, and false if
+// any name is invalid.
 func validatePackageNames(pkg Package) (string, string, bool) {
 	for mgr, val := range pkg.Managers {
 		if mgr == Git || mgr == Installer {
@@ -112,11 +224,35 @@ func validatePackageNames(pkg Package) (string, string, bool) {
 			}
 		}
 
+		if mgr == Mas {
+			if err := ValidateMasID(val.PackageName); err != nil {
+				return string(mgr), fmt.Sprintf("Invalid App Store ID: %v", err), false
+			}
+		}
+
 		for _, dep := range val.Deps {
 			if err := ValidatePackageName(dep); err != nil {
 				return string(mgr), fmt.Sprintf("Invalid dependency name: %v", err), false
 			}
 		}
+
+		for _, o := range val.Overrides {
+			if err := ValidatePackageName(o.PackageName); err != nil {
+				return string(mgr), fmt.Sprintf("Invalid override package name: %v", err), false
+			}
+		}
+
+		if val.Apt != nil && val.Apt.Repo != "" {
+			if err := ValidateAptRepo(val.Apt.Repo); err != nil {
+				return string(mgr), fmt.Sprintf("Invalid apt repo: %v", err), false
+			}
+		}
+
+		if s, ok := sourceOf(mgr, val); ok {
+			if err := s.validate(); err != nil {
+				return string(mgr), fmt.Sprintf("Invalid %s: %v", s.kind(), err), false
+			}
+		}
 	}
 
 	return "", "", true
@@ -127,6 +263,8 @@ func validatePackageNames(pkg Package) (string, string, bool) {
 // It returns the manager method, an error message, and false if any dependency fails.
 // Returns ("", "", true) if all dependencies installed successfully.
 func (m *Manager) installDeps(pkg Package) (string, string, bool) {
+	m.ensureManagers()
+
 	for mgr, val := range pkg.Managers {
 		if len(val.Deps) == 0 {
 			continue
@@ -140,7 +278,7 @@ func (m *Manager) installDeps(pkg Package) (string, string, bool) {
 			continue
 		}
 		for _, dep := range val.Deps {
-			success, msg := m.installWithManager(mgr, dep)
+			success, msg := m.installWithManager(mgr, ManagerValue{PackageName: dep})
 			if !success {
 				return string(mgr), fmt.Sprintf("Dependency %s failed: %s", dep, msg), false
 			}
@@ -150,19 +288,182 @@ func (m *Manager) installDeps(pkg Package) (string, string, bool) {
 	return "", "", true
 }
 
-// InstallAll installs all packages in the provided slice sequentially.
-// It returns a slice of InstallResult, one for each package, indicating
-// the success or failure of each installation.
+// InstallAll installs packages phase by phase, lowest phase first. Every
+// package of a phase is attempted before the next phase starts; a failure does
+// not stop later phases. Within a phase up to Jobs packages are installed
+// concurrently, each waiting for the packages its After names. Results are
+// returned in the order GroupByPhase gives the packages.
+//
+// Installs run under the Manager's context: once it is canceled, in-flight
+// installs fail and the remaining packages are reported as canceled without
+// running anything.
 func (m *Manager) InstallAll(packages []Package) []InstallResult {
+	// Detect before installPhase copies m for its workers.
+	m.ensureManagers()
+
 	results := make([]InstallResult, 0, len(packages))
-	for _, pkg := range packages {
-		results = append(results, m.Install(pkg))
+	for _, phase := range GroupByPhase(packages) {
+		results = append(results, m.installPhase(phase)...)
 	}
 
 	return results
 }
 
-func (m *Manager) installWithManager(mgr PackageManager, pkgName string) (bool, string) {
+// GroupByPhase splits packages into their install phases, in ascending phase
+// order. Within a phase, each package comes after the packages its After
+// names, and packages otherwise keep their relative order.
+func GroupByPhase(packages []Package) [][]Package {
+	sorted := slices.Clone(packages)
+	slices.SortStableFunc(sorted, func(a, b Package) int {
+		return cmp.Compare(a.Phase, b.Phase)
+	})
+
+	var phases [][]Package
+
+	for i, pkg := range sorted {
+		if i == 0 || pkg.Phase != sorted[i-1].Phase {
+			phases = append(phases, nil)
+		}
+
+		phases[len(phases)-1] = append(phases[len(phases)-1], pkg)
+	}
+
+	for i := range phases {
+		phases[i] = orderByAfter(phases[i])
+	}
+
+	return phases
+}
+
+// afterEdges returns, for each package, the indices of the packages in the
+// same slice that its After names. Names not in packages are ignored: After
+// only orders packages that are installed together.
+func afterEdges(packages []Package) [][]int {
+	index := make(map[string]int, len(packages))
+	for i, pkg := range packages {
+		index[pkg.Name] = i
+	}
+
+	edges := make([][]int, len(packages))
+
+	for i, pkg := range packages {
+		for _, name := range pkg.After {
+			if j, ok := index[name]; ok && j != i {
+				edges[i] = append(edges[i], j)
+			}
+		}
+	}
+
+	return edges
+}
+
+// orderByAfter returns packages reordered so each comes after the packages
+// its After names, keeping the given order wherever After does not decide.
+// Packages caught in an After cycle are installed in their given order.
+func orderByAfter(packages []Package) []Package {
+	edges := afterEdges(packages)
+
+	pending := make([]int, len(packages))
+	followers := make([][]int, len(packages))
+
+	for i, preds := range edges {
+		pending[i] = len(preds)
+		for _, j := range preds {
+			followers[j] = append(followers[j], i)
+		}
+	}
+
+	placed := make([]bool, len(packages))
+	ordered := make([]Package, 0, len(packages))
+
+	for len(ordered) < len(packages) {
+		next := -1
+
+		for i := range packages {
+			if !placed[i] && pending[i] == 0 {
+				next = i
+				break
+			}
+		}
+
+		if next == -1 {
+			for i := range packages {
+				if !placed[i] {
+					next = i
+					break
+				}
+			}
+
+			slog.Warn("after: ordering cycle, installing in config order",
+				slog.String("package", packages[next].Name))
+		}
+
+		placed[next] = true
+		ordered = append(ordered, packages[next])
+
+		for _, f := range followers[next] {
+			pending[f]--
+		}
+	}
+
+	return ordered
+}
+
+// installPhase installs the packages of one phase, up to Jobs at a time, in
+// the order GroupByPhase gives them. A package whose After names an earlier
+// package of the phase waits for that install to finish, successful or not.
+// Commands of native package managers still run one at a time: pacman, apt
+// and dnf lock their database, and yay and paru share pacman's. Git clones,
+// installer, custom and URL installs are what actually run in parallel.
+func (m *Manager) installPhase(packages []Package) []InstallResult {
+	results := make([]InstallResult, len(packages))
+
+	if m.Jobs < 2 || len(packages) < 2 {
+		for i, pkg := range packages {
+			results[i] = m.InstallWithContext(m.ctx, pkg)
+		}
+
+		return results
+	}
+
+	pm := *m
+	pm.nativeMu = &sync.Mutex{}
+
+	sem := make(chan struct{}, m.Jobs)
+	edges := afterEdges(packages)
+	done := make([]chan struct{}, len(packages))
+
+	var wg sync.WaitGroup
+
+	for i, pkg := range packages {
+		done[i] = make(chan struct{})
+
+		sem <- struct{}{}
+
+		wg.Go(func() {
+			defer func() { <-sem }()
+			defer close(done[i])
+
+			// Only earlier packages are waited for: a later one is either
+			// not ordered before this one or part of a cycle.
+			for _, j := range edges[i] {
+				if j < i {
+					<-done[j]
+				}
+			}
+
+			results[i] = pm.InstallWithContext(pm.ctx, pkg)
+		})
+	}
+
+	wg.Wait()
+
+	return results
+}
+
+// installWithManager installs val through a native package manager, with its
+// emerge USE override or winget source applied (see installArgs).
+func (m *Manager) installWithManager(mgr PackageManager, val ManagerValue) (bool, string) {
 	mc, ok := managerCmds[mgr]
 	if !ok {
 		if mgr == Git {
@@ -176,12 +477,17 @@ func (m *Manager) installWithManager(mgr PackageManager, pkgName string) (bool,
 		return false, fmt.Sprintf("Unknown package manager: %s", mgr)
 	}
 
-	args := expandArgs(mc.install, pkgName)
+	args := installArgs(mc, val, !m.RequiresSudo(mgr))
 
 	if m.DryRun {
 		return true, fmt.Sprintf("Would run: %s", strings.Join(args, " "))
 	}
 
+	if m.nativeMu != nil {
+		m.nativeMu.Lock()
+		defer m.nativeMu.Unlock()
+	}
+
 	_, err := m.runner.Run(m.ctx, args[0], args[1:]...) //nolint:gosec // args from trusted lookup table
 	if err != nil {
 		return false, fmt.Sprintf("Installation failed: %v", err)
@@ -205,68 +511,95 @@ func (m *Manager) installGitPackage(gitCfg GitConfig) (bool, string) {
 	// Expand path (handle ~ and env vars)
 	targetPath = config.ExpandPath(targetPath, nil)
 
-	// Check if already cloned
-	gitDir := filepath.Join(targetPath, ".git")
-	if _, err := os.Stat(gitDir); err == nil {
-		return m.gitPull(targetPath, gitCfg.Sudo)
+	if msg, ok := validateGitOptions(gitCfg); !ok {
+		return false, msg
+	}
+
+	opts := gitCloneOptions(gitCfg)
+	sudo := gitCfg.Sudo && m.canElevate()
+
+	if gitutil.IsCloned(targetPath) {
+		return m.gitPull(targetPath, opts, sudo)
 	}
 
-	return m.gitClone(gitCfg.URL, targetPath, gitCfg.Branch, gitCfg.Sudo)
+	return m.gitClone(gitCfg.URL, targetPath, opts, sudo)
 }
 
-func (m *Manager) gitClone(repoURL, targetPath, branch string, sudo bool) (bool, string) {
-	if err := ValidateGitBranch(branch); err != nil {
-		return false, fmt.Sprintf("Invalid git branch: %v", err)
+// gitCloneOptions returns the clone settings of a git package.
+func gitCloneOptions(gitCfg GitConfig) gitutil.CloneOptions {
+	return gitutil.CloneOptions{Branch: gitCfg.Branch, Depth: gitCfg.Depth, Sparse: gitCfg.Sparse}
+}
+
+// validateGitOptions checks the branch, depth and sparse patterns of a git
+// package. It returns an error message and false if any is invalid.
+func validateGitOptions(gitCfg GitConfig) (string, bool) {
+	if err := ValidateGitBranch(gitCfg.Branch); err != nil {
+		return fmt.Sprintf("Invalid git branch: %v", err), false
 	}
-	args := []string{argClone}
-	if branch != "" {
-		args = append(args, "-b", branch)
+
+	if gitCfg.Depth < 0 {
+		return fmt.Sprintf("Invalid git depth %d: must not be negative", gitCfg.Depth), false
 	}
-	args = append(args, repoURL, targetPath)
 
-	if m.DryRun {
-		if sudo {
-			return true, fmt.Sprintf("Would run: sudo git %s", strings.Join(args, " "))
+	for _, pattern := range gitCfg.Sparse {
+		if err := ValidateSparsePattern(pattern); err != nil {
+			return fmt.Sprintf("Invalid sparse pattern: %v", err), false
 		}
-		return true, fmt.Sprintf("Would run: git %s", strings.Join(args, " "))
 	}
 
-	var err error
-	if sudo {
-		_, err = m.runner.RunWithSudo(m.ctx, cmdGit, args...)
-	} else {
-		_, err = m.runner.Run(m.ctx, cmdGit, args...)
+	return "", true
+}
+
+func (m *Manager) gitClone(repoURL, targetPath string, opts gitutil.CloneOptions, sudo bool) (bool, string) {
+	if m.DryRun {
+		cmds := [][]string{gitutil.CloneArgs(repoURL, targetPath, opts)}
+		if len(opts.Sparse) > 0 {
+			cmds = append(cmds, gitutil.SparseCheckoutArgs(targetPath, opts.Sparse))
+		}
+
+		return true, fmt.Sprintf("Would run: %s", gitCommandLines(cmds, sudo))
 	}
 
-	if err != nil {
+	if err := gitutil.Clone(m.ctx, m.runner, repoURL, targetPath, opts, sudo); err != nil {
 		return false, fmt.Sprintf("Git clone failed: %v", err)
 	}
 
 	return true, "Repository cloned successfully"
 }
 
-func (m *Manager) gitPull(repoPath string, sudo bool) (bool, string) {
+func (m *Manager) gitPull(repoPath string, opts gitutil.CloneOptions, sudo bool) (bool, string) {
 	if m.DryRun {
-		if sudo {
-			return true, fmt.Sprintf("Would run: sudo git -C %s pull", repoPath)
-		}
-		return true, fmt.Sprintf("Would run: git -C %s pull", repoPath)
+		return true, fmt.Sprintf("Would run: %s", gitCommandLines(gitutil.PullArgs(repoPath, opts), sudo))
 	}
 
-	var err error
-	if sudo {
-		_, err = m.runner.RunWithSudo(m.ctx, cmdGit, "-C", repoPath, "pull")
-	} else {
-		_, err = m.runner.Run(m.ctx, cmdGit, "-C", repoPath, "pull")
-	}
-
-	if err != nil {
+	if err := gitutil.Pull(m.ctx, m.runner, repoPath, opts, sudo); err != nil {
 		return false, fmt.Sprintf("Git pull failed: %v", err)
 	}
 
 	return true, "Repository updated successfully"
 }
 
+// gitCommandLines renders git commands as one "a && b" line for dry-run
+// messages.
+func gitCommandLines(cmds [][]string, sudo bool) string {
+	lines := make([]string, len(cmds))
+	for i, args := range cmds {
+		lines[i] = gitCommandLine(args, sudo)
+	}
+
+	return strings.Join(lines, " && ")
+}
+
+// gitCommandLine renders a git command for dry-run messages.
+func gitCommandLine(args []string, sudo bool) string {
+	line := cmdGit + " " + strings.Join(args, " ")
+	if sudo {
+		return cmdSudo + " " + line
+	}
+
+	return line
+}
+
 // installInstallerPackage runs an OS-specific shell command to install a package.
 // SECURITY NOTE: This intentionally executes arbitrary shell commands from the
 // user's configuration file. Users should only use configurations they trust,
@@ -318,7 +651,40 @@ func (m *Manager) runCustomCommand(command string) (bool, string) {
 	return true, "Installed via custom command"
 }
 
+// verifyInstall runs verify, the command a custom or installer package
+// declares to confirm that its install command, which reported msg, really
+// succeeded. A failing verify command fails the install with a message
+// starting with MsgVerifyFailed and sets unverified. msg is kept when verify
+// is empty or passes.
+// SECURITY NOTE: like the install command, verify comes from the user's
+// configuration file and is run as is.
+func (m *Manager) verifyInstall(verify, msg string) (success bool, message string, unverified bool) {
+	if verify == "" {
+		return true, msg, false
+	}
+
+	if m.DryRun {
+		return true, fmt.Sprintf("%s, then verify: %s", msg, verify), false
+	}
+
+	var err error
+	if m.OS == platform.OSWindows {
+		_, err = m.runner.Run(m.ctx, "powershell", "-Command", verify) //nolint:gosec // intentional command from user config
+	} else {
+		_, err = m.runner.Run(m.ctx, "sh", "-c", verify) //nolint:gosec // intentional command from user config
+	}
+
+	if err != nil {
+		return false, fmt.Sprintf("%s: %v", MsgVerifyFailed, err), true
+	}
+
+	return true, msg + ", verified", false
+}
+
 // installFromURL downloads a file from a URL and runs an install command.
+// The download lives in a private (0700) temp directory that is removed on
+// every return path, including cancellation. When the spec declares a sha256
+// or size, the file is verified before the command runs, unless SkipVerify.
 // SECURITY NOTE: This intentionally downloads and executes content from URLs
 // specified in the user's configuration file. Users should only use configurations
 // they trust, as malicious configs could download and execute harmful code.
@@ -327,7 +693,12 @@ func (m *Manager) installFromURL(urlInstall URLInstall) (bool, string) {
 		return false, fmt.Sprintf("URL rejected: %v", err)
 	}
 
+	verify := hasVerification(urlInstall) && !m.SkipVerify
+
 	if m.DryRun {
+		if verify {
+			return true, fmt.Sprintf("Would download %s, verify its checksum, and run: %s", urlInstall.URL, urlInstall.Command)
+		}
 		return true, fmt.Sprintf("Would download %s and run: %s", urlInstall.URL, urlInstall.Command)
 	}
 
@@ -340,10 +711,16 @@ func (m *Manager) installFromURL(urlInstall URLInstall) (bool, string) {
 	defer func() {
 		if err := os.RemoveAll(tmpDir); err != nil {
 			// Log but don't fail on cleanup errors
-			fmt.Printf("[WARN] Failed to remove temp directory %s: %v\n", tmpDir, err)
+			m.logf("[WARN] Failed to remove temp directory %s: %v\n", tmpDir, err)
 		}
 	}()
 
+	// MkdirTemp already uses 0700 on Unix; make it explicit so nobody else
+	// can swap the file between verification and execution.
+	if err := os.Chmod(tmpDir, PrivateDirPerms); err != nil {
+		return false, fmt.Sprintf("Failed to restrict temp directory: %v", err)
+	}
+
 	tmpPath := filepath.Join(tmpDir, "installer")
 
 	// Download file
@@ -361,6 +738,12 @@ func (m *Manager) installFromURL(urlInstall URLInstall) (bool, string) {
 		return false, fmt.Sprintf("Download failed: %v", err)
 	}
 
+	if verify {
+		if err := verifyDownload(tmpPath, urlInstall); err != nil {
+			return false, fmt.Sprintf("Verification failed: %v", err)
+		}
+	}
+
 	// Make executable on Unix
 	if m.OS != platform.OSWindows {
 		if err := os.Chmod(tmpPath, ExecPerms); err != nil { //nolint:gosec // installer scripts need to be executable
@@ -2,6 +2,12 @@ package packages
 
 import (
 	"context"
+	"fmt"
+	"io"
+	"os"
+	"runtime"
+	"sync"
+	"time"
 
 	"github.com/AntoineGS/tidydots/internal/cmdexec"
 	"github.com/AntoineGS/tidydots/internal/platform"
@@ -12,38 +18,91 @@ import (
 // based on configuration and OS, and provides methods to install packages using
 // the appropriate installation method. It supports dry-run mode for previewing
 // operations and verbose mode for detailed output.
+//
+// Detection is lazy: Available and Preferred are filled in by DetectManagers,
+// which the first call needing them (CanInstall, GetInstallMethod, Install,
+// Check, ...) runs if nothing did before. A Manager shared between goroutines
+// must call DetectManagers first.
 type Manager struct {
-	ctx          context.Context
-	Config       *Config
-	OS           string
-	Preferred    PackageManager
-	Available    []PackageManager
+	ctx       context.Context
+	Config    *Config
+	OS        string
+	Preferred PackageManager
+	Available []PackageManager
+	// availableSet holds Available for lookups; nil until managers are
+	// detected.
 	availableSet map[PackageManager]bool
 	runner       cmdexec.Runner
 	DryRun       bool
 	Verbose      bool
+	// SkipVerify disables sha256/size verification of URL downloads. It is an
+	// escape hatch for emergencies, e.g. a vendor re-publishing a release.
+	SkipVerify bool
+	// NoSudo drops the sudo prefix from native package manager commands and
+	// skips git packages marked sudo: true, for systems where sudo is
+	// unavailable (e.g. containers already running as root).
+	NoSudo bool
+	// Offline skips every install, clone and update, for machines without
+	// network access. Custom and installer commands are skipped as well,
+	// since tidydots cannot tell whether they download anything.
+	Offline bool
+	// Jobs is how many packages of the same phase InstallAll installs at
+	// once. Values below 2 install one package at a time.
+	Jobs int
+	// InstallRetries is how many more times a failed install is tried, for
+	// network-dependent installs that fail transiently. Zero never retries.
+	InstallRetries int
+	// RetryDelay is the wait before the first retry; it doubles before each
+	// next one, up to a minute.
+	RetryDelay time.Duration
+	// getUID returns the ID of the user running tidydots, for RequiresSudo
+	// to tell root, who needs no sudo; nil uses os.Getuid. Tests replace it.
+	getUID func() int
+	// nativeMu serializes package manager commands during concurrent
+	// installs; nil when installing sequentially.
+	nativeMu *sync.Mutex
+	// sources remembers the scoop buckets and winget sources already
+	// ensured this run; nil disables the cache.
+	sources *sourceCache
+	// LogOutput receives the informational messages the Manager prints
+	// outside of its results, such as failed cleanups; nil writes to
+	// os.Stdout. Dry runs print nothing: what they would run is the Message
+	// of each result.
+	LogOutput io.Writer
 }
 
 // NewManager creates a new package Manager with the given configuration.
-// It detects available package managers on the system and selects a preferred
-// manager based on the configuration priority, default manager setting, or
-// OS-specific defaults. The osType parameter specifies the target OS (linux/windows),
-// and dryRun/verbose control the execution mode.
+// It does not probe the system: available package managers are detected, and
+// a preferred one selected based on the configuration priority, default
+// manager setting, or OS-specific defaults, on first use; see DetectManagers.
+// The osType parameter specifies the target OS (linux/windows), and
+// dryRun/verbose control the execution mode.
 func NewManager(cfg *Config, osType string, dryRun, verbose bool) *Manager {
 	m := &Manager{
-		ctx:     context.Background(),
-		Config:  cfg,
-		OS:      osType,
-		DryRun:  dryRun,
-		Verbose: verbose,
-		runner:  cmdexec.OsRunner{},
+		ctx:       context.Background(),
+		Config:    cfg,
+		OS:        osType,
+		DryRun:    dryRun,
+		Verbose:   verbose,
+		runner:    cmdexec.OsRunner{},
+		getUID:    os.Getuid,
+		sources:   newSourceCache(),
+		LogOutput: os.Stdout,
 	}
-	m.detectAvailableManagers()
-	m.selectPreferredManager()
 
 	return m
 }
 
+// logf prints an informational message to LogOutput.
+func (m *Manager) logf(format string, args ...any) {
+	w := m.LogOutput
+	if w == nil {
+		w = os.Stdout
+	}
+
+	fmt.Fprintf(w, format, args...)
+}
+
 // WithRunner returns a new Manager with the given command runner.
 // Used primarily for testing with a stub command runner.
 func (m *Manager) WithRunner(r cmdexec.Runner) *Manager {
@@ -59,9 +118,58 @@ func (m *Manager) WithContext(ctx context.Context) *Manager {
 	return &m2
 }
 
-func (m *Manager) detectAvailableManagers() {
-	m.availableSet = make(map[PackageManager]bool)
-	for _, mgr := range platform.DetectAvailableManagersWithRunner(m.runner) {
+// DetectManagers detects the package managers available on the system and
+// selects the preferred one, filling in Available and Preferred. Detection
+// looks up every known manager's binary in PATH, concurrently, and is cached
+// for the process, so calling it again is cheap. It returns ctx.Err() without
+// waiting for the lookups when ctx is canceled first.
+func (m *Manager) DetectManagers(ctx context.Context) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	found := make(chan []string, 1)
+
+	go func() {
+		found <- platform.DetectAvailableManagersWithRunner(m.runner)
+	}()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case available := <-found:
+		m.setAvailable(available)
+	}
+
+	m.selectPreferredManager()
+
+	return nil
+}
+
+// ensureManagers runs DetectManagers unless managers were already detected or
+// Available was set directly.
+func (m *Manager) ensureManagers() {
+	if m.availableSet != nil {
+		return
+	}
+
+	if m.Available != nil {
+		m.availableSet = make(map[PackageManager]bool, len(m.Available))
+		for _, mgr := range m.Available {
+			m.availableSet[mgr] = true
+		}
+
+		return
+	}
+
+	_ = m.DetectManagers(m.ctx) //nolint:errcheck // canceled: no manager counts as available
+}
+
+func (m *Manager) setAvailable(managers []string) {
+	m.Available = make([]PackageManager, 0, len(managers))
+	m.availableSet = make(map[PackageManager]bool, len(managers))
+
+	for _, mgr := range managers {
 		pm := PackageManager(mgr)
 		m.Available = append(m.Available, pm)
 		m.availableSet[pm] = true
@@ -73,7 +181,7 @@ func (m *Manager) selectPreferredManager() {
 	if len(m.Config.ManagerPriority) > 0 {
 		for _, mgr := range m.Config.ManagerPriority {
 			if m.HasManager(mgr) {
-				m.Preferred = mgr
+				m.Preferred = canonicalManager(mgr)
 				return
 			}
 		}
@@ -81,7 +189,7 @@ func (m *Manager) selectPreferredManager() {
 
 	// Use default if set and available
 	if m.Config.DefaultManager != "" && m.HasManager(m.Config.DefaultManager) {
-		m.Preferred = m.Config.DefaultManager
+		m.Preferred = canonicalManager(m.Config.DefaultManager)
 		return
 	}
 
@@ -94,8 +202,12 @@ func (m *Manager) selectPreferredManager() {
 			}
 		}
 	} else {
-		// Linux/macOS priority
-		for _, mgr := range []PackageManager{Yay, Paru, Pacman, Apt, Dnf, Brew} {
+		priority := linuxManagerPriority
+		if runtime.GOOS == "darwin" {
+			priority = darwinManagerPriority
+		}
+
+		for _, mgr := range priority {
 			if m.HasManager(mgr) {
 				m.Preferred = mgr
 				return
@@ -104,8 +216,28 @@ func (m *Manager) selectPreferredManager() {
 	}
 }
 
+// linuxManagerPriority is the auto-selection order on Linux. It includes brew
+// for Homebrew on Linux, but not brew-cask or mas, which are macOS only.
+var linuxManagerPriority = []PackageManager{Yay, Paru, Pacman, Apt, Dnf, Eopkg, Emerge, Brew}
+
+// darwinManagerPriority is the auto-selection order on macOS, which tidydots
+// otherwise reports as linux.
+var darwinManagerPriority = []PackageManager{Brew, BrewCask, Mas}
+
 // HasManager checks if a package manager is available on the system.
-// It returns true if the specified manager was detected during initialization.
+// It returns true if the specified manager, or the manager an alias such as
+// portage stands for, was detected; see DetectManagers.
 func (m *Manager) HasManager(mgr PackageManager) bool {
-	return m.availableSet[mgr]
+	m.ensureManagers()
+
+	return m.availableSet[canonicalManager(mgr)]
+}
+
+// canonicalManager maps a manager alias onto the manager it stands for.
+func canonicalManager(mgr PackageManager) PackageManager {
+	if mgr == Portage {
+		return Emerge
+	}
+
+	return mgr
 }
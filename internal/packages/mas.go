@@ -0,0 +1,102 @@
+package packages
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"github.com/AntoineGS/tidydots/internal/cmdexec"
+)
+
+// MasApp is a Mac App Store app as reported by mas.
+type MasApp struct {
+	ID   string
+	Name string
+}
+
+// ValidateMasID checks that id is a Mac App Store ID, which is numeric, e.g.
+// 497799835 for Xcode.
+func ValidateMasID(id string) error {
+	if id == "" {
+		return fmt.Errorf("app store ID must not be empty")
+	}
+
+	for _, r := range id {
+		if r < '0' || r > '9' {
+			return fmt.Errorf("app store ID %q must be numeric", id)
+		}
+	}
+
+	return nil
+}
+
+// LookupMasApp asks the App Store, through mas, for the app with the given
+// ID. It fails when the ID is invalid or no app has it. mas search matches app
+// names rather than IDs, so the lookup uses mas info, which is keyed by ID.
+func LookupMasApp(ctx context.Context, r cmdexec.Runner, id string) (MasApp, error) {
+	if err := ValidateMasID(id); err != nil {
+		return MasApp{}, err
+	}
+
+	result, err := r.Run(ctx, string(Mas), "info", id)
+	if err != nil {
+		return MasApp{}, fmt.Errorf("no App Store app has ID %s", id)
+	}
+
+	name := parseMasInfoName(string(result.Stdout))
+	if name == "" {
+		return MasApp{}, fmt.Errorf("no App Store app has ID %s", id)
+	}
+
+	return MasApp{ID: id, Name: name}, nil
+}
+
+// parseMasInfoName returns the app name from mas info output, whose first line
+// is "name version [price]", e.g. "Xcode 15.0 [Free]".
+func parseMasInfoName(output string) string {
+	line, _, _ := strings.Cut(strings.TrimSpace(output), "\n")
+
+	if i := strings.LastIndex(line, " ["); i >= 0 {
+		line = line[:i]
+	}
+
+	fields := strings.Fields(line)
+	if len(fields) < 2 {
+		return strings.Join(fields, " ")
+	}
+
+	return strings.Join(fields[:len(fields)-1], " ")
+}
+
+// masBulkList runs "mas list" once and returns the IDs of the installed apps.
+func masBulkList(ctx context.Context) map[string]bool {
+	return masBulkListWithRunner(ctx, cmdexec.OsRunner{})
+}
+
+// masBulkListWithRunner runs mas list using the given runner.
+func masBulkListWithRunner(ctx context.Context, r cmdexec.Runner) map[string]bool {
+	result, err := r.Run(ctx, string(Mas), "list")
+	if err != nil {
+		slog.Debug("mas bulk list failed",
+			slog.String("error", err.Error()),
+			slog.String("stderr", strings.TrimSpace(string(result.Stderr))))
+		return make(map[string]bool)
+	}
+
+	return parseMasListOutput(string(result.Stdout))
+}
+
+// parseMasListOutput extracts app IDs from mas list output, where each line is
+// "id  name  (version)".
+func parseMasListOutput(output string) map[string]bool {
+	ids := make(map[string]bool)
+
+	for _, line := range strings.Split(output, "\n") {
+		if fields := strings.Fields(line); len(fields) > 0 && ValidateMasID(fields[0]) == nil {
+			ids[fields[0]] = true
+		}
+	}
+
+	return ids
+}
@@ -0,0 +1,124 @@
+package packages
+
+import (
+	"context"
+	"slices"
+	"testing"
+
+	"github.com/AntoineGS/tidydots/internal/cmdexec"
+	"gopkg.in/yaml.v3"
+)
+
+func TestValidateMasID(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		id      string
+		wantErr bool
+	}{
+		{"497799835", false},
+		{"", true},
+		{"xcode", true},
+		{"-497799835", true},
+		{"4977 99835", true},
+	}
+
+	for _, tt := range tests {
+		if err := ValidateMasID(tt.id); (err != nil) != tt.wantErr {
+			t.Errorf("ValidateMasID(%q) error = %v, wantErr %v", tt.id, err, tt.wantErr)
+		}
+	}
+}
+
+func TestBuildCommand_Mas(t *testing.T) {
+	t.Parallel()
+
+	pkg := Package{
+		Name:     "xcode",
+		Managers: map[PackageManager]ManagerValue{Mas: {PackageName: "497799835", Mas: &MasOptions{AppName: "Xcode"}}},
+	}
+
+	cmd := buildCommand(t, pkg, string(Mas), "linux", false, nil)
+	if cmd == nil {
+		t.Fatal("BuildCommand(mas) returned nil")
+	}
+
+	if want := []string{"mas", "install", "497799835"}; !slices.Equal(cmd.Args, want) {
+		t.Errorf("mas args = %v, want %v", cmd.Args, want)
+	}
+
+	pkg.Managers[Mas] = ManagerValue{PackageName: "xcode"}
+	if cmd := buildCommand(t, pkg, string(Mas), "linux", false, nil); cmd != nil {
+		t.Errorf("BuildCommand(mas) with a non-numeric ID = %v, want nil", cmd.Args)
+	}
+}
+
+func TestLookupMasApp(t *testing.T) {
+	t.Parallel()
+
+	stub := cmdexec.NewStubRunner()
+	stub.AddResult("mas", cmdexec.Result{Stdout: []byte("Final Cut Pro 10.7 [USD 299.99]\nBy: Apple\n")})
+
+	app, err := LookupMasApp(context.Background(), stub, "424389933")
+	if err != nil {
+		t.Fatalf("LookupMasApp() error = %v", err)
+	}
+
+	if app.Name != "Final Cut Pro" {
+		t.Errorf("LookupMasApp() name = %q, want %q", app.Name, "Final Cut Pro")
+	}
+
+	if want := []string{"info", "424389933"}; !slices.Equal(stub.Calls[0].Args, want) {
+		t.Errorf("mas args = %v, want %v", stub.Calls[0].Args, want)
+	}
+
+	// mas info prints nothing for an unknown ID.
+	if _, err := LookupMasApp(context.Background(), stub, "1"); err == nil {
+		t.Error("LookupMasApp() with no output should fail")
+	}
+
+	if _, err := LookupMasApp(context.Background(), stub, "xcode"); err == nil || len(stub.Calls) != 2 {
+		t.Errorf("LookupMasApp() with a non-numeric ID should fail without running mas, err = %v", err)
+	}
+}
+
+func TestParseMasListOutput(t *testing.T) {
+	t.Parallel()
+
+	output := `497799835   Xcode          (15.0)
+ 1451685025  WireGuard      (1.0.16)
+No installed apps found
+`
+
+	ids := parseMasListOutput(output)
+
+	if !ids["497799835"] || !ids["1451685025"] || len(ids) != 2 {
+		t.Errorf("parseMasListOutput() = %v", ids)
+	}
+}
+
+func TestManagerPriority_MasDarwinOnly(t *testing.T) {
+	t.Parallel()
+
+	if slices.Contains(linuxManagerPriority, Mas) {
+		t.Error("mas must not be in the Linux priority list")
+	}
+
+	if i := slices.Index(darwinManagerPriority, Mas); i < 0 || i < slices.Index(darwinManagerPriority, BrewCask) {
+		t.Errorf("mas should follow brew-cask in the macOS priority list, got %v", darwinManagerPriority)
+	}
+}
+
+func TestPackage_UnmarshalYAML_Mas(t *testing.T) {
+	t.Parallel()
+
+	var pkg Package
+	if err := yaml.Unmarshal([]byte("name: xcode\nmanagers:\n  mas:\n    name: 497799835\n    app: Xcode\n"), &pkg); err != nil {
+		t.Fatalf("Unmarshal error: %v", err)
+	}
+
+	val := pkg.Managers[Mas]
+	if val.PackageName != "497799835" || val.Mas == nil || val.Mas.AppName != "Xcode" {
+		t.Errorf("mas = %+v (Mas %+v), want ID 497799835 and app Xcode", val, val.Mas)
+	}
+}
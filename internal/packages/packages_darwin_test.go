@@ -3,7 +3,6 @@
 package packages
 
 import (
-	"context"
 	"os/exec"
 	"testing"
 
@@ -21,7 +20,7 @@ func TestBuildCommand_DarwinBrew(t *testing.T) {
 		},
 	}
 
-	cmd := BuildCommand(context.Background(), pkg, string(Brew), "linux") // tidydots maps macOS to "linux"
+	cmd := buildCommand(t, pkg, string(Brew), "linux", false, nil) // tidydots maps macOS to "linux"
 	if cmd == nil {
 		t.Fatal("BuildCommand() returned nil")
 	}
@@ -38,7 +37,7 @@ func TestBuildCommand_DarwinCustomUsesShell(t *testing.T) {
 		Custom: map[string]string{"linux": "brew install --cask firefox"},
 	}
 
-	cmd := BuildCommand(context.Background(), pkg, MethodCustom, "linux") // tidydots maps macOS to "linux"
+	cmd := buildCommand(t, pkg, MethodCustom, "linux", false, nil) // tidydots maps macOS to "linux"
 	if cmd == nil {
 		t.Fatal("BuildCommand() returned nil")
 	}
@@ -59,7 +58,7 @@ func TestBuildCommand_DarwinURLUsesCurl(t *testing.T) {
 		},
 	}
 
-	cmd := BuildCommand(context.Background(), pkg, MethodURL, "linux")
+	cmd := buildCommand(t, pkg, MethodURL, "linux", false, nil)
 	if cmd == nil {
 		t.Fatal("BuildCommand() returned nil")
 	}
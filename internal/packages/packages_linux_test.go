@@ -3,10 +3,15 @@
 package packages
 
 import (
-	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
 	"os/exec"
+	"strings"
 	"testing"
 
+	"github.com/AntoineGS/tidydots/internal/cmdexec"
 	"github.com/AntoineGS/tidydots/internal/platform"
 	"github.com/AntoineGS/tidydots/internal/testutil"
 )
@@ -18,6 +23,7 @@ func TestBuildCommand_LinuxManagers(t *testing.T) {
 		name     string
 		manager  PackageManager
 		pkgName  string
+		emerge   *EmergeOptions
 		wantArgs []string
 	}{
 		{
@@ -50,6 +56,25 @@ func TestBuildCommand_LinuxManagers(t *testing.T) {
 			pkgName:  "neovim-git",
 			wantArgs: []string{"paru", "-S", "--noconfirm", "neovim-git"},
 		},
+		{
+			name:     "eopkg install",
+			manager:  Eopkg,
+			pkgName:  "neovim",
+			wantArgs: []string{"sudo", "eopkg", "install", "-y", "neovim"},
+		},
+		{
+			name:     "emerge install",
+			manager:  Emerge,
+			pkgName:  "app-editors/neovim",
+			wantArgs: []string{"sudo", "emerge", "-v", "app-editors/neovim"},
+		},
+		{
+			name:     "emerge install with USE override",
+			manager:  Emerge,
+			pkgName:  "app-editors/neovim",
+			emerge:   &EmergeOptions{UseFlagsOverride: "lua -X"},
+			wantArgs: []string{"sudo", "env", "USE=lua -X", "emerge", "-v", "app-editors/neovim"},
+		},
 	}
 
 	for _, tt := range tests {
@@ -59,11 +84,11 @@ func TestBuildCommand_LinuxManagers(t *testing.T) {
 			pkg := Package{
 				Name: "test-pkg",
 				Managers: map[PackageManager]ManagerValue{
-					tt.manager: {PackageName: tt.pkgName},
+					tt.manager: {PackageName: tt.pkgName, Emerge: tt.emerge},
 				},
 			}
 
-			cmd := BuildCommand(context.Background(), pkg, string(tt.manager), "linux")
+			cmd := buildCommand(t, pkg, string(tt.manager), "linux", false, nil)
 			if cmd == nil {
 				t.Fatal("BuildCommand() returned nil")
 			}
@@ -73,6 +98,97 @@ func TestBuildCommand_LinuxManagers(t *testing.T) {
 	}
 }
 
+func TestBuildCommand_LinuxManagersNoSudo(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		manager  PackageManager
+		pkgName  string
+		emerge   *EmergeOptions
+		wantArgs []string
+	}{
+		{
+			name:     "pacman install",
+			manager:  Pacman,
+			pkgName:  "neovim",
+			wantArgs: []string{"pacman", "-S", "--noconfirm", "neovim"},
+		},
+		{
+			name:     "apt install",
+			manager:  Apt,
+			pkgName:  "neovim",
+			wantArgs: []string{"apt-get", "install", "-y", "neovim"},
+		},
+		{
+			name:     "dnf install",
+			manager:  Dnf,
+			pkgName:  "neovim",
+			wantArgs: []string{"dnf", "install", "-y", "neovim"},
+		},
+		{
+			name:     "yay install is unchanged",
+			manager:  Yay,
+			pkgName:  "neovim-git",
+			wantArgs: []string{"yay", "-S", "--noconfirm", "neovim-git"},
+		},
+		{
+			name:     "emerge install with USE override",
+			manager:  Emerge,
+			pkgName:  "app-editors/neovim",
+			emerge:   &EmergeOptions{UseFlagsOverride: "lua"},
+			wantArgs: []string{"env", "USE=lua", "emerge", "-v", "app-editors/neovim"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			pkg := Package{
+				Name: "test-pkg",
+				Managers: map[PackageManager]ManagerValue{
+					tt.manager: {PackageName: tt.pkgName, Emerge: tt.emerge},
+				},
+			}
+
+			cmd := buildCommand(t, pkg, string(tt.manager), "linux", true, nil)
+			if cmd == nil {
+				t.Fatal("BuildCommand() returned nil")
+			}
+
+			assertArgs(t, cmd, tt.wantArgs)
+		})
+	}
+}
+
+func TestBuildCommand_NoSudoGit(t *testing.T) {
+	t.Parallel()
+
+	pkg := Package{
+		Name: "git-pkg",
+		Managers: map[PackageManager]ManagerValue{
+			Git: {Git: &GitConfig{
+				URL:     "https://github.com/example/repo.git",
+				Targets: map[string]string{"linux": "/opt/repo"},
+				Sudo:    true,
+			}},
+		},
+	}
+
+	if !GitRequiresSudo(pkg, string(Git)) {
+		t.Error("GitRequiresSudo() = false for a sudo git package")
+	}
+
+	if cmd := buildCommand(t, pkg, string(Git), "linux", true, nil); cmd != nil {
+		t.Errorf("BuildCommand() = %v, want nil for a sudo git package under noSudo", cmd.Args)
+	}
+
+	if cmd := buildCommand(t, pkg, string(Git), "linux", false, nil); cmd == nil {
+		t.Error("BuildCommand() = nil, want sudo git clone without noSudo")
+	}
+}
+
 func TestBuildCommand_LinuxCustomUsesShell(t *testing.T) {
 	t.Parallel()
 
@@ -81,7 +197,7 @@ func TestBuildCommand_LinuxCustomUsesShell(t *testing.T) {
 		Custom: map[string]string{"linux": "make install"},
 	}
 
-	cmd := BuildCommand(context.Background(), pkg, MethodCustom, "linux")
+	cmd := buildCommand(t, pkg, MethodCustom, "linux", false, nil)
 	if cmd == nil {
 		t.Fatal("BuildCommand() returned nil")
 	}
@@ -90,6 +206,72 @@ func TestBuildCommand_LinuxCustomUsesShell(t *testing.T) {
 	assertArgs(t, cmd, []string{"sh", "-c", "make install"})
 }
 
+func TestBuildCommand_LinuxCustomVerify(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name, command, verify string
+		wantErr               bool
+	}{
+		{"verify passes", "true", "true", false},
+		{"verify fails", "true", "false", true},
+		{"install fails", "false", "true", true},
+		{"install exits early", "exit 0", "false", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			pkg := Package{
+				Name:   "custom-tool",
+				Custom: map[string]string{"linux": tt.command},
+				Verify: map[string]string{"linux": tt.verify},
+			}
+
+			cmd := buildCommand(t, pkg, MethodCustom, "linux", false, nil)
+			if cmd == nil {
+				t.Fatal("BuildCommand() returned nil")
+			}
+
+			out, err := cmd.CombinedOutput()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("running %q: err = %v, want error %v", cmd.Args[2], err, tt.wantErr)
+			}
+
+			if wantMsg := tt.wantErr && tt.command != "false"; wantMsg != strings.Contains(string(out), MsgVerifyFailed) {
+				t.Errorf("output = %q, want %q reported only when the install succeeded", out, MsgVerifyFailed)
+			}
+		})
+	}
+}
+
+func TestBuildCommand_LinuxAptRepo(t *testing.T) {
+	t.Parallel()
+
+	pkg := Package{
+		Name: "neovim",
+		Managers: map[PackageManager]ManagerValue{
+			Apt: {PackageName: "neovim", Apt: &AptOptions{Repo: "ppa:neovim-ppa/stable"}},
+		},
+	}
+
+	cmd := buildCommand(t, pkg, string(Apt), "linux", false, nil)
+	if cmd == nil {
+		t.Fatal("BuildCommand() returned nil")
+	}
+
+	want := `'grep' '-rqsF' '--' '/neovim-ppa/stable/ubuntu' '/etc/apt/sources.list' '/etc/apt/sources.list.d'` +
+		` || { 'sudo' 'add-apt-repository' '-y' 'ppa:neovim-ppa/stable' && 'sudo' 'apt-get' 'update'; }` +
+		` && 'sudo' 'apt-get' 'install' '-y' 'neovim'`
+	assertArgs(t, cmd, []string{"sh", "-c", want})
+
+	pkg.Managers[Apt] = ManagerValue{PackageName: "neovim", Apt: &AptOptions{Repo: "-r ppa:x/y"}}
+	if cmd := buildCommand(t, pkg, string(Apt), "linux", false, nil); cmd != nil {
+		t.Errorf("BuildCommand() with an invalid repo = %v, want nil", cmd.Args)
+	}
+}
+
 func TestBuildCommand_LinuxURLUsesCurl(t *testing.T) {
 	t.Parallel()
 
@@ -103,7 +285,7 @@ func TestBuildCommand_LinuxURLUsesCurl(t *testing.T) {
 		},
 	}
 
-	cmd := BuildCommand(context.Background(), pkg, MethodURL, "linux")
+	cmd := buildCommand(t, pkg, MethodURL, "linux", false, nil)
 	if cmd == nil {
 		t.Fatal("BuildCommand() returned nil")
 	}
@@ -163,3 +345,128 @@ func assertArgs(t *testing.T, cmd *exec.Cmd, wantArgs []string) {
 		}
 	}
 }
+
+// newURLTestServer serves body over HTTP and returns the server and the
+// sha256 of body.
+func newURLTestServer(t *testing.T, body string) (*httptest.Server, string) {
+	t.Helper()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte(body))
+	}))
+	t.Cleanup(srv.Close)
+
+	sum := sha256.Sum256([]byte(body))
+
+	return srv, hex.EncodeToString(sum[:])
+}
+
+func TestInstallFromURL_Verification(t *testing.T) {
+	if _, err := exec.LookPath("curl"); err != nil {
+		t.Skip("curl not available")
+	}
+
+	const body = "#!/bin/sh\nexit 0\n"
+
+	srv, goodHash := newURLTestServer(t, body)
+	badHash := strings.Repeat("0", 64)
+
+	tests := []struct {
+		name        string
+		spec        URLInstall
+		skipVerify  bool
+		wantSuccess bool
+		wantMsg     string
+	}{
+		{name: "good hash", spec: URLInstall{SHA256: goodHash, Size: int64(len(body))}, wantSuccess: true},
+		{name: "uppercase hash", spec: URLInstall{SHA256: strings.ToUpper(goodHash)}, wantSuccess: true},
+		{name: "bad hash", spec: URLInstall{SHA256: badHash}, wantMsg: "expected " + badHash + ", got " + goodHash},
+		{name: "bad size", spec: URLInstall{Size: 1}, wantMsg: "expected 1 bytes"},
+		{name: "missing hash", spec: URLInstall{}, wantSuccess: true},
+		{name: "skip verify", spec: URLInstall{SHA256: badHash}, skipVerify: true, wantSuccess: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mgr, _ := newStubManager(t, platform.OSLinux)
+			mgr.runner = cmdexec.OsRunner{}
+			mgr.SkipVerify = tt.skipVerify
+
+			spec := tt.spec
+			spec.URL = srv.URL + "/install.sh"
+			spec.Command = "sh {file}"
+
+			ok, msg := mgr.installFromURL(spec)
+			if ok != tt.wantSuccess {
+				t.Fatalf("installFromURL() = %v (%s), want success %v", ok, msg, tt.wantSuccess)
+			}
+
+			if tt.wantMsg != "" && !strings.Contains(msg, tt.wantMsg) {
+				t.Errorf("message %q does not contain %q", msg, tt.wantMsg)
+			}
+		})
+	}
+}
+
+func TestBuildCommand_LinuxURLVerifiesChecksum(t *testing.T) {
+	if _, err := exec.LookPath("curl"); err != nil {
+		t.Skip("curl not available")
+	}
+
+	srv, goodHash := newURLTestServer(t, "payload")
+
+	for _, tc := range []struct {
+		name    string
+		hash    string
+		wantErr bool
+	}{
+		{name: "good hash", hash: goodHash},
+		{name: "bad hash", hash: strings.Repeat("a", 64), wantErr: true},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			pkg := Package{
+				Name: "url-tool",
+				URL:  map[string]URLInstall{"linux": {URL: srv.URL, Command: "true {file}", SHA256: tc.hash}},
+			}
+
+			out, err := buildCommand(t, pkg, MethodURL, "linux", false, nil).CombinedOutput()
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("command error = %v, wantErr %v (output: %s)", err, tc.wantErr, out)
+			}
+
+			if tc.wantErr && !strings.Contains(string(out), "sha256 mismatch") {
+				t.Errorf("output %q does not report sha256 mismatch", out)
+			}
+		})
+	}
+}
+
+func TestBuildCommand_LinuxURLPrivateDirAndSkipVerify(t *testing.T) {
+	if _, err := exec.LookPath("curl"); err != nil {
+		t.Skip("curl not available")
+	}
+
+	srv, _ := newURLTestServer(t, "payload")
+
+	// The command prints the mode of the directory the download is in.
+	pkg := Package{
+		Name: "url-tool",
+		URL: map[string]URLInstall{"linux": {
+			URL:     srv.URL,
+			Command: `stat -c %a "$(dirname {file})"`,
+			SHA256:  strings.Repeat("a", 64),
+		}},
+	}
+
+	mgr, _ := newStubManager(t, "linux")
+	mgr.SkipVerify = true
+
+	out, err := mgr.BuildCommand(t.Context(), pkg, MethodURL, nil).CombinedOutput()
+	if err != nil {
+		t.Fatalf("command with SkipVerify error = %v (output: %s), want the bad hash ignored", err, out)
+	}
+
+	if got := strings.TrimSpace(string(out)); got != "700" {
+		t.Errorf("download directory mode = %q, want 700", got)
+	}
+}
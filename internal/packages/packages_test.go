@@ -1,17 +1,22 @@
 package packages
 
 import (
+	"bytes"
 	"context"
+	"errors"
 	"fmt"
 	"os"
 	"os/exec"
 	"path/filepath"
 	"runtime"
+	"slices"
 	"strings"
 	"testing"
 
+	"github.com/AntoineGS/tidydots/internal/cmdexec"
 	"github.com/AntoineGS/tidydots/internal/config"
 	"github.com/AntoineGS/tidydots/internal/platform"
+	tmpl "github.com/AntoineGS/tidydots/internal/template"
 	"gopkg.in/yaml.v3"
 )
 
@@ -107,6 +112,71 @@ func TestNewManager(t *testing.T) {
 	}
 }
 
+func TestNewManager_DetectsLazily(t *testing.T) {
+	platform.ResetAvailableManagersCache()
+	platform.SetDetectionHints("linux", false)
+	t.Cleanup(platform.ResetAvailableManagersCache)
+
+	stub := cmdexec.NewStubRunner()
+	stub.AddPath("pacman", "/usr/bin/pacman")
+
+	m := NewManager(&Config{}, "linux", false, false).WithRunner(stub)
+	if m.Available != nil || m.Preferred != "" {
+		t.Fatalf("NewManager detected managers: Available = %v, Preferred = %q", m.Available, m.Preferred)
+	}
+
+	pkg := Package{Name: "neovim", Managers: map[PackageManager]ManagerValue{Pacman: {PackageName: "neovim"}}}
+	if !m.CanInstall(pkg) {
+		t.Error("CanInstall() = false, want pacman detected on first use")
+	}
+
+	if !slices.Equal(m.Available, []PackageManager{Pacman}) || m.Preferred != Pacman {
+		t.Errorf("after CanInstall: Available = %v, Preferred = %q, want [pacman] and pacman", m.Available, m.Preferred)
+	}
+}
+
+func TestManager_LogOutput(t *testing.T) {
+	m := NewManager(&Config{}, "linux", false, false)
+	if m.LogOutput != os.Stdout {
+		t.Errorf("NewManager() LogOutput = %v, want os.Stdout", m.LogOutput)
+	}
+
+	var out bytes.Buffer
+	m.LogOutput = &out
+	m.logf("[WARN] Failed to remove temp directory %s: %v\n", "/tmp/x", "busy")
+
+	if got, want := out.String(), "[WARN] Failed to remove temp directory /tmp/x: busy\n"; got != want {
+		t.Errorf("LogOutput got %q, want %q", got, want)
+	}
+}
+
+func TestDetectManagers(t *testing.T) {
+	platform.ResetAvailableManagersCache()
+	platform.SetDetectionHints("linux", false)
+	t.Cleanup(platform.ResetAvailableManagersCache)
+
+	stub := cmdexec.NewStubRunner()
+	stub.AddPath("apt", "/usr/bin/apt")
+	stub.AddPath("git", "/usr/bin/git")
+
+	m := NewManager(&Config{}, "linux", false, false).WithRunner(stub)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if err := m.DetectManagers(ctx); !errors.Is(err, context.Canceled) {
+		t.Errorf("DetectManagers(canceled) error = %v, want context.Canceled", err)
+	}
+
+	if err := m.DetectManagers(context.Background()); err != nil {
+		t.Fatalf("DetectManagers() error = %v", err)
+	}
+
+	if !slices.Equal(m.Available, []PackageManager{Apt, Git}) || m.Preferred != Apt {
+		t.Errorf("Available = %v, Preferred = %q, want [apt git] and apt", m.Available, m.Preferred)
+	}
+}
+
 func TestHasManager(t *testing.T) {
 	tests := []struct {
 		name      string
@@ -150,6 +220,12 @@ func TestHasManager(t *testing.T) {
 			check:     Scoop,
 			want:      true,
 		},
+		{
+			name:      "portage is an alias for emerge",
+			available: []PackageManager{Emerge},
+			check:     Portage,
+			want:      true,
+		},
 	}
 
 	for _, tt := range tests {
@@ -426,6 +502,68 @@ func TestCanInstall(t *testing.T) {
 	}
 }
 
+func TestGetInstallabilityReason(t *testing.T) {
+	tests := []struct {
+		name string
+		pkg  Package
+		want string
+	}{
+		{
+			name: "installable",
+			pkg:  Package{Name: "vim", Managers: map[PackageManager]ManagerValue{Pacman: {PackageName: "vim"}}},
+			want: "",
+		},
+		{
+			name: "manager not installed",
+			pkg:  Package{Name: "vim", Managers: map[PackageManager]ManagerValue{Apt: {PackageName: "vim"}, Winget: {PackageName: "vim.vim"}}},
+			want: "manager apt configured but not installed",
+		},
+		{
+			name: "managers not installed",
+			pkg:  Package{Name: "vim", Managers: map[PackageManager]ManagerValue{Dnf: {PackageName: "vim"}, Apt: {PackageName: "vim"}}},
+			want: "managers apt, dnf configured but not installed",
+		},
+		{
+			name: "custom command only for other OS",
+			pkg:  Package{Name: "tool", Managers: map[PackageManager]ManagerValue{Winget: {PackageName: "t"}}, Custom: map[string]string{"windows": "iwr x"}},
+			want: "custom command only for windows",
+		},
+		{
+			name: "url only for other OS",
+			pkg:  Package{Name: "tool", URL: map[string]URLInstall{"windows": {URL: "https://example.com/t.exe"}}},
+			want: "url install only for windows",
+		},
+		{
+			name: "installer only for other OS",
+			pkg: Package{Name: "tool", Managers: map[PackageManager]ManagerValue{
+				Installer: {Installer: &InstallerConfig{Command: map[string]string{"windows": "setup.exe"}}},
+			}},
+			want: "installer command only for windows",
+		},
+		{
+			name: "no manager for this OS",
+			pkg:  Package{Name: "powertoys", Managers: map[PackageManager]ManagerValue{Winget: {PackageName: "Microsoft.PowerToys"}}},
+			want: "no manager for linux",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := &Manager{
+				ctx:          context.Background(),
+				Config:       &Config{},
+				OS:           "linux",
+				Available:    []PackageManager{Pacman},
+				availableSet: toAvailableSet([]PackageManager{Pacman}),
+			}
+
+			if got := m.GetInstallabilityReason(tt.pkg); got != tt.want {
+				t.Errorf("GetInstallabilityReason() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
 func TestGetInstallMethod(t *testing.T) {
 	tests := []struct {
 		name      string
@@ -457,6 +595,34 @@ func TestGetInstallMethod(t *testing.T) {
 			},
 			want: "yay",
 		},
+		{
+			name:      "preferred manager over priority",
+			available: []PackageManager{Yay, Pacman},
+			osType:    "linux",
+			pkg: Package{
+				Name: "vim",
+				Managers: map[PackageManager]ManagerValue{
+					Pacman: {PackageName: "vim"},
+					Yay:    {PackageName: "vim"},
+				},
+				Prefer: Pacman,
+			},
+			want: "pacman",
+		},
+		{
+			name:      "unavailable preferred manager falls back to priority",
+			available: []PackageManager{Yay, Apt},
+			osType:    "linux",
+			pkg: Package{
+				Name: "vim",
+				Managers: map[PackageManager]ManagerValue{
+					Pacman: {PackageName: "vim"},
+					Yay:    {PackageName: "vim"},
+				},
+				Prefer: Pacman,
+			},
+			want: "yay",
+		},
 		{
 			name:      "returns custom",
 			available: []PackageManager{},
@@ -759,7 +925,9 @@ func TestFromApplication(t *testing.T) {
 		wantName   string
 		wantDesc   string
 		app        config.Application
+		wantWhen   string
 		wantMgrLen int
+		wantPhase  int
 		wantNil    bool
 	}{
 		{
@@ -819,6 +987,33 @@ func TestFromApplication(t *testing.T) {
 			wantNil:  false,
 			wantName: "filtered-pkg",
 		},
+		{
+			name: "package with its own when expression",
+			app: config.Application{
+				Name: "gui-pkg",
+				When: `{{ eq .OS "linux" }}`,
+				Package: &config.EntryPackage{
+					Managers: map[string]config.ManagerValue{"pacman": {PackageName: "gui-pkg"}},
+					When:     `{{ .HasDisplay }}`,
+				},
+			},
+			wantName: "gui-pkg",
+			wantWhen: `{{ .HasDisplay }}`,
+		},
+		{
+			name: "app with install phase",
+			app: config.Application{
+				Name: "phased-pkg",
+				Package: &config.EntryPackage{
+					Managers: map[string]config.ManagerValue{"pacman": {PackageName: "phased-pkg"}},
+					Phase:    2,
+				},
+			},
+			wantNil:    false,
+			wantName:   "phased-pkg",
+			wantMgrLen: 1,
+			wantPhase:  2,
+		},
 		{
 			name: "app with all package options",
 			app: config.Application{
@@ -866,6 +1061,14 @@ func TestFromApplication(t *testing.T) {
 			if tt.wantMgrLen > 0 && len(got.Managers) != tt.wantMgrLen {
 				t.Errorf("FromApplication().Managers has %d entries, want %d", len(got.Managers), tt.wantMgrLen)
 			}
+
+			if got.Phase != tt.wantPhase {
+				t.Errorf("FromApplication().Phase = %d, want %d", got.Phase, tt.wantPhase)
+			}
+
+			if got.When != tt.wantWhen {
+				t.Errorf("FromApplication().When = %q, want %q", got.When, tt.wantWhen)
+			}
 		})
 	}
 }
@@ -1528,6 +1731,119 @@ managers:
 	}
 }
 
+func TestPackage_UnmarshalYAML_Emerge(t *testing.T) {
+	t.Run("use override", func(t *testing.T) {
+		yamlData := `
+name: neovim
+managers:
+  emerge:
+    name: app-editors/neovim
+    use: "lua -X"
+`
+
+		var pkg Package
+		if err := yaml.Unmarshal([]byte(yamlData), &pkg); err != nil {
+			t.Fatalf("Failed to unmarshal: %v", err)
+		}
+
+		value := pkg.Managers[Emerge]
+		if value.PackageName != "app-editors/neovim" {
+			t.Errorf("PackageName = %q, want %q", value.PackageName, "app-editors/neovim")
+		}
+
+		if value.Emerge == nil || value.Emerge.UseFlagsOverride != "lua -X" {
+			t.Errorf("Emerge = %+v, want USE override %q", value.Emerge, "lua -X")
+		}
+	})
+
+	t.Run("portage alias", func(t *testing.T) {
+		var pkg Package
+		if err := yaml.Unmarshal([]byte("name: neovim\nmanagers:\n  portage: app-editors/neovim\n"), &pkg); err != nil {
+			t.Fatalf("Failed to unmarshal: %v", err)
+		}
+
+		if _, ok := pkg.Managers[Portage]; ok {
+			t.Error("portage should be stored as emerge")
+		}
+
+		if got := pkg.Managers[Emerge].PackageName; got != "app-editors/neovim" {
+			t.Errorf("emerge PackageName = %q, want %q", got, "app-editors/neovim")
+		}
+	})
+
+	t.Run("use on other manager", func(t *testing.T) {
+		var pkg Package
+		err := yaml.Unmarshal([]byte("name: neovim\nmanagers:\n  apt:\n    name: neovim\n    use: lua\n"), &pkg)
+		if err == nil {
+			t.Error("expected an error for use on apt")
+		}
+	})
+}
+
+func TestPackage_UnmarshalYAML_AptRepo(t *testing.T) {
+	t.Run("repo", func(t *testing.T) {
+		var pkg Package
+		if err := yaml.Unmarshal([]byte("name: neovim\nmanagers:\n  apt:\n    name: neovim\n    repo: ppa:neovim-ppa/stable\n"), &pkg); err != nil {
+			t.Fatalf("Failed to unmarshal: %v", err)
+		}
+
+		value := pkg.Managers[Apt]
+		if value.PackageName != "neovim" {
+			t.Errorf("PackageName = %q, want %q", value.PackageName, "neovim")
+		}
+
+		if value.Apt == nil || value.Apt.Repo != "ppa:neovim-ppa/stable" {
+			t.Errorf("Apt = %+v, want repo %q", value.Apt, "ppa:neovim-ppa/stable")
+		}
+	})
+
+	t.Run("repo on other manager", func(t *testing.T) {
+		var pkg Package
+		err := yaml.Unmarshal([]byte("name: neovim\nmanagers:\n  dnf:\n    name: neovim\n    repo: ppa:neovim-ppa/stable\n"), &pkg)
+		if err == nil {
+			t.Error("expected an error for repo on dnf")
+		}
+	})
+}
+
+func TestAptRepoMarker(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		repo string
+		want string
+	}{
+		{"ppa:neovim-ppa/stable", "/neovim-ppa/stable/ubuntu"},
+		{"ppa:fish-shell", "/fish-shell/ppa/ubuntu"},
+		{"deb [arch=amd64] https://apt.example.com/ stable main", "https://apt.example.com"},
+		{"universe", ""},
+	}
+
+	for _, tt := range tests {
+		if got := aptRepoMarker(tt.repo); got != tt.want {
+			t.Errorf("aptRepoMarker(%q) = %q, want %q", tt.repo, got, tt.want)
+		}
+	}
+}
+
+func TestParseEopkgListOutput(t *testing.T) {
+	output := `neovim                         - Vim-fork focused on extensibility and agility
+Git                            - Fast, scalable, distributed revision control system
+`
+
+	names := parseEopkgListOutput(output)
+
+	for _, name := range []string{"neovim", "git"} {
+		if !names[name] {
+			t.Errorf("expected %q in installed names", name)
+		}
+	}
+
+	if names["vim-fork"] {
+		t.Error("description words should not be parsed as names")
+	}
+}
+
 func TestPackage_UnmarshalYAML_InstallerWithoutBinary(t *testing.T) {
 	yamlData := `
 name: "no-binary-pkg"
@@ -1790,6 +2106,11 @@ func TestManager_InstallGitPackage_WithSudo(t *testing.T) {
 
 	cfg := &Config{Packages: []Package{}}
 	mgr := NewManager(cfg, platform.OSLinux, true, false) // dry-run to avoid actual sudo
+	// Run as a user with sudo, whoever runs the test.
+	mgr.getUID = func() int { return 1000 }
+	stub := cmdexec.NewStubRunner()
+	stub.AddPath("sudo", "/usr/bin/sudo")
+	mgr.runner = stub
 
 	pkg := Package{
 		Name: "test-repo",
@@ -2099,7 +2420,7 @@ func TestBuildCommand(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			cmd := BuildCommand(context.Background(), tt.pkg, tt.method, tt.osType)
+			cmd := buildCommand(t, tt.pkg, tt.method, tt.osType, false, nil)
 
 			if tt.wantNil {
 				if cmd != nil {
@@ -2142,6 +2463,73 @@ func TestBuildCommand(t *testing.T) {
 	}
 }
 
+func TestBuildCommand_BrewCask(t *testing.T) {
+	t.Parallel()
+
+	pkg := Package{
+		Name: "firefox",
+		Managers: map[PackageManager]ManagerValue{
+			Brew:     {PackageName: "wget"},
+			BrewCask: {PackageName: "firefox"},
+		},
+	}
+
+	cask := buildCommand(t, pkg, string(BrewCask), "linux", false, nil)
+	if cask == nil {
+		t.Fatal("BuildCommand(brew-cask) returned nil")
+	}
+
+	if want := []string{"brew", "install", "--cask", "firefox"}; !slices.Equal(cask.Args, want) {
+		t.Errorf("brew-cask args = %v, want %v", cask.Args, want)
+	}
+
+	formula := buildCommand(t, pkg, string(Brew), "linux", false, nil)
+	if formula == nil {
+		t.Fatal("BuildCommand(brew) returned nil")
+	}
+
+	if slices.Contains(formula.Args, "--cask") {
+		t.Errorf("brew args = %v, want no --cask", formula.Args)
+	}
+}
+
+func TestUninstallArgs(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		manager PackageManager
+		want    []string
+	}{
+		{"brew-cask", BrewCask, []string{"brew", "uninstall", "--cask", "firefox"}},
+		{"brew", Brew, []string{"brew", "uninstall", "firefox"}},
+		{"no uninstall command", Pacman, nil},
+		{"unknown manager", PackageManager("nix"), nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			if got := UninstallArgs(tt.manager, "firefox"); !slices.Equal(got, tt.want) {
+				t.Errorf("UninstallArgs(%q) = %v, want %v", tt.manager, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestManagerPriority_BrewCaskDarwinOnly(t *testing.T) {
+	t.Parallel()
+
+	if slices.Contains(linuxManagerPriority, BrewCask) {
+		t.Error("brew-cask must not be in the Linux priority list")
+	}
+
+	if !slices.Contains(darwinManagerPriority, BrewCask) {
+		t.Error("brew-cask should be in the macOS priority list")
+	}
+}
+
 func TestBuildCommand_GitExpandsTildeInTarget(t *testing.T) {
 	t.Parallel()
 
@@ -2186,7 +2574,7 @@ func TestBuildCommand_GitExpandsTildeInTarget(t *testing.T) {
 				},
 			}
 
-			cmd := BuildCommand(context.Background(), pkg, string(Git), "linux")
+			cmd := buildCommand(t, pkg, string(Git), "linux", false, nil)
 			if cmd == nil {
 				t.Fatal("BuildCommand() returned nil")
 			}
@@ -2457,3 +2845,113 @@ func TestIsGitInstalled(t *testing.T) {
 		})
 	}
 }
+
+func TestBuildCommand_GitShallowSparse(t *testing.T) {
+	t.Parallel()
+
+	git := &GitConfig{
+		URL:     "https://github.com/example/repo.git",
+		Branch:  "main",
+		Targets: map[string]string{"linux": "/opt/repo"},
+		Depth:   1,
+	}
+	pkg := Package{Name: "git-pkg", Managers: map[PackageManager]ManagerValue{Git: {Git: git}}}
+
+	cmd := buildCommand(t, pkg, string(Git), "linux", false, nil)
+	if cmd == nil {
+		t.Fatal("BuildCommand() = nil")
+	}
+
+	want := []string{"git", "clone", "-b", "main", "--depth", "1", "https://github.com/example/repo.git", "/opt/repo"}
+	if !slices.Equal(cmd.Args, want) {
+		t.Errorf("shallow Args = %v, want %v", cmd.Args, want)
+	}
+
+	git.Sparse = []string{"/lua/", "*.vim"}
+
+	cmd = buildCommand(t, pkg, string(Git), "linux", false, nil)
+	if cmd == nil {
+		t.Fatal("BuildCommand() = nil")
+	}
+
+	wantScript := "'git' 'clone' '-b' 'main' '--depth' '1' '--sparse' 'https://github.com/example/repo.git' '/opt/repo' && " +
+		"'git' '-C' '/opt/repo' 'sparse-checkout' 'set' '--no-cone' '/lua/' '*.vim'"
+	if cmd.Args[0] != "sh" || cmd.Args[len(cmd.Args)-1] != wantScript {
+		t.Errorf("sparse Args = %v, want sh -c %q", cmd.Args, wantScript)
+	}
+
+	git.Sparse = []string{"--exec=evil"}
+	if cmd := buildCommand(t, pkg, string(Git), "linux", false, nil); cmd != nil {
+		t.Errorf("BuildCommand() = %v, want nil for a sparse pattern starting with '-'", cmd.Args)
+	}
+}
+
+func TestInstallGitPackage_ShallowSparseDryRun(t *testing.T) {
+	t.Parallel()
+
+	target := filepath.Join(t.TempDir(), "repo")
+	mgr := NewManager(&Config{}, platform.OSLinux, true, false)
+
+	success, msg := mgr.installGitPackage(GitConfig{
+		URL:     "https://github.com/example/repo.git",
+		Targets: map[string]string{platform.OSLinux: target},
+		Depth:   1,
+		Sparse:  []string{"/lua/"},
+	})
+	if !success {
+		t.Fatalf("installGitPackage() failed: %s", msg)
+	}
+
+	want := "Would run: git clone --depth 1 --sparse https://github.com/example/repo.git " + target +
+		" && git -C " + target + " sparse-checkout set --no-cone /lua/"
+	if msg != want {
+		t.Errorf("message = %q, want %q", msg, want)
+	}
+
+	if success, msg := mgr.installGitPackage(GitConfig{URL: "https://github.com/example/repo.git", Targets: map[string]string{platform.OSLinux: target}, Depth: -1}); success {
+		t.Errorf("installGitPackage() with a negative depth succeeded: %s", msg)
+	}
+}
+
+func TestBuildCommand_PackageNameOverrides(t *testing.T) {
+	pkg := Package{
+		Name: "neovim",
+		Managers: map[PackageManager]ManagerValue{
+			Yay: {
+				PackageName: "neovim",
+				Overrides: []config.PackageNameOverride{
+					{When: `{{ eq .Hostname "desktop" }}`, PackageName: "neovim-git"},
+					{When: `{{ eq .Hostname "laptop" }}`, PackageName: "neovim-nightly-bin"},
+				},
+			},
+		},
+	}
+
+	tests := map[string]string{
+		"desktop": "neovim-git",
+		"laptop":  "neovim-nightly-bin",
+		"server":  "neovim",
+	}
+
+	for hostname, want := range tests {
+		renderer := tmpl.NewEngine(&tmpl.Context{OS: "linux", Hostname: hostname})
+
+		cmd := buildCommand(t, pkg, string(Yay), "linux", false, renderer)
+		if cmd == nil {
+			t.Fatalf("BuildCommand() on %s = nil", hostname)
+		}
+
+		if got := cmd.Args[len(cmd.Args)-1]; got != want {
+			t.Errorf("BuildCommand() on %s installs %q, want %q", hostname, got, want)
+		}
+
+		if got := FilterPackages([]Package{pkg}, renderer)[0].Managers[Yay].PackageName; got != want {
+			t.Errorf("FilterPackages() on %s resolves %q, want %q", hostname, got, want)
+		}
+	}
+
+	// Without a renderer no override applies.
+	if cmd := buildCommand(t, pkg, string(Yay), "linux", false, nil); cmd.Args[len(cmd.Args)-1] != "neovim" {
+		t.Errorf("BuildCommand() without a renderer = %v, want the plain package name", cmd.Args)
+	}
+}
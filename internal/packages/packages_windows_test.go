@@ -3,7 +3,6 @@
 package packages
 
 import (
-	"context"
 	"os/exec"
 	"testing"
 
@@ -51,7 +50,7 @@ func TestBuildCommand_WindowsManagers(t *testing.T) {
 				},
 			}
 
-			cmd := BuildCommand(context.Background(), pkg, string(tt.manager), "windows")
+			cmd := buildCommand(t, pkg, string(tt.manager), "windows", false, nil)
 			if cmd == nil {
 				t.Fatal("BuildCommand() returned nil")
 			}
@@ -69,7 +68,7 @@ func TestBuildCommand_WindowsCustomUsesPowerShell(t *testing.T) {
 		Custom: map[string]string{"windows": "msbuild /t:install"},
 	}
 
-	cmd := BuildCommand(context.Background(), pkg, MethodCustom, "windows")
+	cmd := buildCommand(t, pkg, MethodCustom, "windows", false, nil)
 	if cmd == nil {
 		t.Fatal("BuildCommand() returned nil")
 	}
@@ -91,7 +90,7 @@ func TestBuildCommand_WindowsURLUsesInvokeWebRequest(t *testing.T) {
 		},
 	}
 
-	cmd := BuildCommand(context.Background(), pkg, MethodURL, "windows")
+	cmd := buildCommand(t, pkg, MethodURL, "windows", false, nil)
 	if cmd == nil {
 		t.Fatal("BuildCommand() returned nil")
 	}
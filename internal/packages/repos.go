@@ -0,0 +1,134 @@
+package packages
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/AntoineGS/tidydots/internal/config"
+	"github.com/AntoineGS/tidydots/internal/gitutil"
+)
+
+// ErrGitNotFound is returned by CheckGit when git is not installed.
+var ErrGitNotFound = errors.New("git is not installed")
+
+// CheckGit returns ErrGitNotFound when git cannot be found in PATH.
+func (m *Manager) CheckGit() error {
+	if _, err := m.runner.LookPath(cmdGit); err != nil {
+		return fmt.Errorf("%w: %w", ErrGitNotFound, err)
+	}
+
+	return nil
+}
+
+// GitRepo is the clone of a git package on one OS.
+type GitRepo struct {
+	Name   string // package name
+	URL    string
+	Branch string // empty for the remote's default branch
+	Path   string // target path with ~ and environment variables expanded
+	Sparse []string
+	Depth  int
+	Sudo   bool
+}
+
+// config returns the git package settings repo was read from.
+func (r GitRepo) config() GitConfig {
+	return GitConfig{URL: r.URL, Branch: r.Branch, Depth: r.Depth, Sparse: r.Sparse, Sudo: r.Sudo}
+}
+
+// GitRepos returns the repositories of the git packages in pkgs that have a
+// target on osType, in package order.
+func GitRepos(pkgs []Package, osType string) []GitRepo {
+	var repos []GitRepo
+
+	for _, pkg := range pkgs {
+		val, ok := pkg.Managers[Git]
+		if !ok || !val.IsGit() {
+			continue
+		}
+
+		target := val.Git.Targets[osType]
+		if target == "" {
+			continue
+		}
+
+		repos = append(repos, GitRepo{
+			Name:   pkg.Name,
+			URL:    val.Git.URL,
+			Branch: val.Git.Branch,
+			Path:   config.ExpandPath(target, nil),
+			Sparse: val.Git.Sparse,
+			Depth:  val.Git.Depth,
+			Sudo:   val.Git.Sudo,
+		})
+	}
+
+	return repos
+}
+
+// RepoStatus returns the status of repo's clone; see gitutil.ReadStatus.
+func (m *Manager) RepoStatus(repo GitRepo) (gitutil.Status, error) {
+	return gitutil.ReadStatus(m.ctx, m.runner, repo.Path)
+}
+
+// CloneRepo clones repo if it is not cloned yet. Unlike installing its
+// package, it never pulls an existing clone.
+func (m *Manager) CloneRepo(repo GitRepo) InstallResult {
+	result := InstallResult{Package: repo.Name, Method: string(Git)}
+
+	switch {
+	case gitutil.IsCloned(repo.Path):
+		result.Success, result.Skipped = true, true
+		result.Message = "Skipped: already cloned"
+	case m.NoSudo && repo.Sudo:
+		result.Success, result.Skipped = true, true
+		result.Message = MsgRequiresSudo
+	case m.Offline:
+		result.Success, result.Skipped = true, true
+		result.Message = MsgOffline
+	default:
+		if err := validateURLScheme(repo.URL); err != nil {
+			result.Message = fmt.Sprintf("Git URL rejected: %v", err)
+			return result
+		}
+
+		if msg, ok := validateGitOptions(repo.config()); !ok {
+			result.Message = msg
+			return result
+		}
+
+		result.Success, result.Message = m.gitClone(repo.URL, repo.Path, gitCloneOptions(repo.config()), repo.Sudo && m.canElevate())
+	}
+
+	return result
+}
+
+// UpdateRepo fetches repo and fast-forwards its clone; see gitutil.Update.
+// A repo that is not cloned fails.
+func (m *Manager) UpdateRepo(repo GitRepo, stash bool) InstallResult {
+	result := InstallResult{Package: repo.Name, Method: string(Git)}
+
+	switch {
+	case !gitutil.IsCloned(repo.Path):
+		result.Message = fmt.Sprintf("Not cloned at %s", repo.Path)
+	case m.NoSudo && repo.Sudo:
+		result.Success, result.Skipped = true, true
+		result.Message = MsgRequiresSudo
+	case m.Offline:
+		result.Success, result.Skipped = true, true
+		result.Message = MsgOffline
+	case m.DryRun:
+		result.Success = true
+		result.Message = fmt.Sprintf("Would fetch and fast-forward %s", repo.Path)
+	default:
+		if err := gitutil.Update(m.ctx, m.runner, repo.Path, stash, repo.Sudo && m.canElevate()); err != nil {
+			result.Message = fmt.Sprintf("Update failed: %v", err)
+			return result
+		}
+
+		result.Success = true
+		result.Message = "Repository updated successfully"
+	}
+
+	return result
+}
@@ -0,0 +1,226 @@
+package packages
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// packageSource is the scoop bucket or winget source a package is installed
+// from. URL is only set for a winget source that can be added when missing.
+type packageSource struct {
+	mgr  PackageManager
+	name string
+	url  string
+}
+
+// sourceOf returns the bucket or source val installs from through mgr, if
+// any.
+func sourceOf(mgr PackageManager, val ManagerValue) (packageSource, bool) {
+	switch {
+	case mgr == Scoop && val.Scoop != nil && val.Scoop.Bucket != "":
+		return packageSource{mgr: Scoop, name: val.Scoop.Bucket}, true
+	case mgr == Winget && val.Winget != nil && val.Winget.Source != "":
+		return packageSource{mgr: Winget, name: val.Winget.Source, url: val.Winget.SourceURL}, true
+	}
+
+	return packageSource{}, false
+}
+
+// validate checks the source's name, and URL when set.
+func (s packageSource) validate() error {
+	if err := ValidateSourceName(s.name); err != nil {
+		return err
+	}
+
+	if s.url == "" {
+		return nil
+	}
+
+	if lower := strings.ToLower(s.url); !strings.HasPrefix(lower, "https://") && !strings.HasPrefix(lower, "http://") {
+		return fmt.Errorf("source URL %q must be http or https", s.url)
+	}
+
+	return nil
+}
+
+// kind names the source in messages.
+func (s packageSource) kind() string {
+	if s.mgr == Scoop {
+		return "scoop bucket"
+	}
+
+	return "winget source"
+}
+
+// listArgs returns the command whose result tells whether the source is
+// present: scoop lists every bucket, winget exits non-zero for an unknown
+// source name.
+func (s packageSource) listArgs() []string {
+	if s.mgr == Scoop {
+		return []string{string(Scoop), "bucket", "list"}
+	}
+
+	return []string{string(Winget), "source", "list", "--name", s.name}
+}
+
+// addArgs returns the command that adds the source, or nil for a winget
+// source without a URL, which cannot be added.
+func (s packageSource) addArgs() []string {
+	if s.mgr == Scoop {
+		return []string{string(Scoop), "bucket", "add", s.name}
+	}
+
+	if s.url == "" {
+		return nil
+	}
+
+	return []string{string(Winget), "source", "add", "--name", s.name, "--arg", s.url, "--accept-source-agreements"}
+}
+
+// sourceCache remembers which sources a run has already checked or added, so
+// each is ensured once however many packages use it.
+type sourceCache struct {
+	mu    sync.Mutex
+	ready map[string]bool
+}
+
+func newSourceCache() *sourceCache {
+	return &sourceCache{ready: make(map[string]bool)}
+}
+
+// sourcePresent reports whether s is already configured.
+func (m *Manager) sourcePresent(s packageSource) bool {
+	args := s.listArgs()
+
+	result, err := m.runner.Run(m.ctx, args[0], args[1:]...)
+	if err != nil || result.ExitCode != 0 {
+		return false
+	}
+
+	if s.mgr == Winget {
+		return true
+	}
+
+	return parseScoopBuckets(string(result.Stdout))[strings.ToLower(s.name)]
+}
+
+// parseScoopBuckets extracts the lowercase bucket names from scoop bucket
+// list output, a table whose first column is the name.
+func parseScoopBuckets(output string) map[string]bool {
+	buckets := make(map[string]bool)
+
+	for _, line := range strings.Split(output, "\n") {
+		if fields := strings.Fields(line); len(fields) > 0 {
+			buckets[strings.ToLower(fields[0])] = true
+		}
+	}
+
+	return buckets
+}
+
+// ensureSource adds s unless it is already configured, checking each source
+// once per Manager. It returns the add command it ran, or in a dry run would
+// have run, so the caller can report it; nil when nothing was needed.
+func (m *Manager) ensureSource(s packageSource) ([]string, error) {
+	key := string(s.mgr) + ":" + strings.ToLower(s.name)
+
+	if m.sources != nil {
+		m.sources.mu.Lock()
+		defer m.sources.mu.Unlock()
+
+		if m.sources.ready[key] {
+			return nil, nil
+		}
+	}
+
+	args, err := m.addSource(s)
+	if err != nil {
+		return nil, err
+	}
+
+	if m.sources != nil {
+		m.sources.ready[key] = true
+	}
+
+	return args, nil
+}
+
+// addSource runs the command that adds s when it is not configured, holding
+// the native package manager lock like addAptRepo.
+func (m *Manager) addSource(s packageSource) ([]string, error) {
+	if m.sourcePresent(s) {
+		return nil, nil
+	}
+
+	args := s.addArgs()
+	if args == nil {
+		return nil, fmt.Errorf("%s %s is not configured and has no source_url to add it from", s.kind(), s.name)
+	}
+
+	if m.DryRun {
+		return args, nil
+	}
+
+	if m.nativeMu != nil {
+		m.nativeMu.Lock()
+		defer m.nativeMu.Unlock()
+	}
+
+	if _, err := m.runner.Run(m.ctx, args[0], args[1:]...); err != nil { //nolint:gosec // args from a validated source name and URL
+		return nil, fmt.Errorf("adding %s %s failed: %w", s.kind(), s.name, err)
+	}
+
+	return args, nil
+}
+
+// installFromSource installs val through mgr after ensuring the bucket or
+// source it comes from is configured.
+func (m *Manager) installFromSource(mgr PackageManager, val ManagerValue, s packageSource) (bool, string) {
+	added, err := m.ensureSource(s)
+	if err != nil {
+		return false, fmt.Sprintf("Source setup failed: %v", err)
+	}
+
+	ok, msg := m.installWithManager(mgr, val)
+	if m.DryRun && added != nil {
+		return ok, fmt.Sprintf("Would run: %s && %s", strings.Join(added, " "), strings.TrimPrefix(msg, "Would run: "))
+	}
+
+	return ok, msg
+}
+
+// sourceScript returns a PowerShell script that adds s when it is missing and
+// then runs install. Names are validated and arguments single-quoted.
+func sourceScript(s packageSource, install []string) string {
+	var b strings.Builder
+
+	name := escapePowerShellSingleQuote(s.name)
+
+	if s.mgr == Scoop {
+		fmt.Fprintf(&b, "if (-not (@(scoop bucket list | ForEach-Object { if ($_.Name) { $_.Name } else { \"$_\" } }) -contains '%s')) { %s; if (-not $?) { exit 1 } }; ", name, quotePowerShellArgs(s.addArgs()))
+	} else if add := s.addArgs(); add != nil {
+		fmt.Fprintf(&b, "winget source list --name '%s' *> $null; if ($LASTEXITCODE -ne 0) { %s; if ($LASTEXITCODE -ne 0) { exit $LASTEXITCODE } }; ", name, quotePowerShellArgs(add))
+	}
+
+	b.WriteString(quotePowerShellArgs(install))
+	b.WriteString("; exit $LASTEXITCODE")
+
+	return b.String()
+}
+
+// quotePowerShellArgs renders args as a PowerShell command with every argument
+// after the executable single-quoted.
+func quotePowerShellArgs(args []string) string {
+	quoted := make([]string, len(args))
+	for i, arg := range args {
+		if i == 0 {
+			quoted[i] = arg
+			continue
+		}
+
+		quoted[i] = "'" + escapePowerShellSingleQuote(arg) + "'"
+	}
+
+	return strings.Join(quoted, " ")
+}
@@ -0,0 +1,234 @@
+package packages
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/AntoineGS/tidydots/internal/cmdexec"
+	"gopkg.in/yaml.v3"
+)
+
+func TestPackage_UnmarshalYAML_Sources(t *testing.T) {
+	t.Parallel()
+
+	var pkg Package
+	data := "name: tools\nmanagers:\n  scoop:\n    name: vlc\n    bucket: extras\n" +
+		"  winget:\n    name: Contoso.Tool\n    source: contoso\n    source_url: https://winget.contoso.com/api\n"
+	if err := yaml.Unmarshal([]byte(data), &pkg); err != nil {
+		t.Fatalf("Failed to unmarshal: %v", err)
+	}
+
+	if scoop := pkg.Managers[Scoop]; scoop.PackageName != "vlc" || scoop.Scoop == nil || scoop.Scoop.Bucket != "extras" {
+		t.Errorf("scoop = %+v, want vlc from the extras bucket", scoop)
+	}
+
+	winget := pkg.Managers[Winget]
+	if winget.Winget == nil || winget.Winget.Source != "contoso" || winget.Winget.SourceURL != "https://winget.contoso.com/api" {
+		t.Errorf("winget = %+v, want the contoso source", winget)
+	}
+
+	var plain Package
+	if err := yaml.Unmarshal([]byte("name: git\nmanagers:\n  scoop: git\n  winget: Git.Git\n"), &plain); err != nil {
+		t.Fatalf("Failed to unmarshal: %v", err)
+	}
+
+	if plain.Managers[Scoop].Scoop != nil || plain.Managers[Winget].Winget != nil {
+		t.Errorf("plain string form should carry no bucket or source, got %+v", plain.Managers)
+	}
+
+	for _, bad := range []string{
+		"name: x\nmanagers:\n  winget:\n    name: x\n    bucket: extras\n",
+		"name: x\nmanagers:\n  scoop:\n    name: x\n    source: msstore\n",
+		"name: x\nmanagers:\n  winget:\n    name: x\n    source_url: https://example.com\n",
+	} {
+		var p Package
+		if err := yaml.Unmarshal([]byte(bad), &p); err == nil {
+			t.Errorf("expected an error for %q", bad)
+		}
+	}
+}
+
+func TestBuildCommand_Sources(t *testing.T) {
+	t.Parallel()
+
+	t.Run("scoop bucket", func(t *testing.T) {
+		pkg := Package{
+			Name:     "vlc",
+			Managers: map[PackageManager]ManagerValue{Scoop: {PackageName: "vlc", Scoop: &ScoopOptions{Bucket: "extras"}}},
+		}
+
+		cmd := buildCommand(t, pkg, string(Scoop), "windows", false, nil)
+		if cmd == nil {
+			t.Fatal("BuildCommand() = nil")
+		}
+
+		if cmd.Args[0] != "powershell" {
+			t.Fatalf("Args = %v, want a powershell script", cmd.Args)
+		}
+
+		script := cmd.Args[len(cmd.Args)-1]
+		for _, want := range []string{"-contains 'extras'", "scoop 'bucket' 'add' 'extras'", "scoop 'install' 'vlc'"} {
+			if !strings.Contains(script, want) {
+				t.Errorf("script %q does not contain %q", script, want)
+			}
+		}
+	})
+
+	t.Run("winget source with url", func(t *testing.T) {
+		pkg := Package{
+			Name: "tool",
+			Managers: map[PackageManager]ManagerValue{Winget: {
+				PackageName: "Contoso.Tool",
+				Winget:      &WingetOptions{Source: "contoso", SourceURL: "https://winget.contoso.com/api"},
+			}},
+		}
+
+		cmd := buildCommand(t, pkg, string(Winget), "windows", false, nil)
+		if cmd == nil {
+			t.Fatal("BuildCommand() = nil")
+		}
+
+		script := cmd.Args[len(cmd.Args)-1]
+		for _, want := range []string{
+			"winget source list --name 'contoso'",
+			"winget 'source' 'add' '--name' 'contoso' '--arg' 'https://winget.contoso.com/api'",
+			"'--source' 'contoso' 'Contoso.Tool'",
+		} {
+			if !strings.Contains(script, want) {
+				t.Errorf("script %q does not contain %q", script, want)
+			}
+		}
+	})
+
+	t.Run("winget builtin source", func(t *testing.T) {
+		pkg := Package{
+			Name:     "app",
+			Managers: map[PackageManager]ManagerValue{Winget: {PackageName: "9NBLGGH4NNS1", Winget: &WingetOptions{Source: "msstore"}}},
+		}
+
+		cmd := buildCommand(t, pkg, string(Winget), "windows", false, nil)
+		if cmd == nil {
+			t.Fatal("BuildCommand() = nil")
+		}
+
+		script := cmd.Args[len(cmd.Args)-1]
+		if strings.Contains(script, "source add") || !strings.Contains(script, "'--source' 'msstore' '9NBLGGH4NNS1'") {
+			t.Errorf("script = %q, want only the install from msstore", script)
+		}
+	})
+
+	t.Run("invalid bucket", func(t *testing.T) {
+		pkg := Package{
+			Name:     "vlc",
+			Managers: map[PackageManager]ManagerValue{Scoop: {PackageName: "vlc", Scoop: &ScoopOptions{Bucket: "extras'; rm"}}},
+		}
+
+		if cmd := buildCommand(t, pkg, string(Scoop), "windows", false, nil); cmd != nil {
+			t.Errorf("BuildCommand() = %v, want nil for an invalid bucket", cmd.Args)
+		}
+	})
+}
+
+func TestInstall_ScoopBucket_AddedOncePerRun(t *testing.T) {
+	mgr, stub := newStubManager(t, "windows")
+	setAvailable(mgr, Scoop)
+	mgr.sources = newSourceCache()
+	stub.AddResult("scoop", cmdexec.Result{Stdout: []byte("Name Source Updated Manifests\n---- ------ ------- ---------\nmain https://github.com/ScoopInstaller/Main 2026-01-01 1000\n")})
+
+	for _, name := range []string{"vlc", "firefox"} {
+		pkg := Package{
+			Name:     name,
+			Managers: map[PackageManager]ManagerValue{Scoop: {PackageName: name, Scoop: &ScoopOptions{Bucket: "extras"}}},
+		}
+
+		if result := mgr.Install(pkg); !result.Success {
+			t.Fatalf("Install(%s) failed: %s", name, result.Message)
+		}
+	}
+
+	var got []string
+	for _, call := range stub.Calls {
+		got = append(got, strings.Join(append([]string{call.Name}, call.Args...), " "))
+	}
+
+	want := []string{
+		"scoop bucket list",
+		"scoop bucket add extras",
+		"scoop install vlc",
+		"scoop install firefox",
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("commands =\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+}
+
+func TestInstall_ScoopBucket_Present(t *testing.T) {
+	mgr, stub := newStubManager(t, "windows")
+	setAvailable(mgr, Scoop)
+	stub.AddResult("scoop", cmdexec.Result{Stdout: []byte("Name   Source\n----   ------\nextras https://github.com/ScoopInstaller/Extras\n")})
+
+	pkg := Package{
+		Name:     "vlc",
+		Managers: map[PackageManager]ManagerValue{Scoop: {PackageName: "vlc", Scoop: &ScoopOptions{Bucket: "Extras"}}},
+	}
+
+	if result := mgr.Install(pkg); !result.Success {
+		t.Fatalf("expected success, got: %s", result.Message)
+	}
+
+	if len(stub.Calls) != 2 || stub.Calls[1].Args[0] != "install" {
+		t.Errorf("expected the bucket list then the install, got %+v", stub.Calls)
+	}
+}
+
+func TestInstall_SourceDryRun(t *testing.T) {
+	mgr, stub := newStubManager(t, "windows")
+	setAvailable(mgr, Winget)
+	mgr.DryRun = true
+	mgr.sources = newSourceCache()
+	stub.AddResult("winget", cmdexec.Result{ExitCode: 1})
+
+	pkg := Package{
+		Name: "tool",
+		Managers: map[PackageManager]ManagerValue{Winget: {
+			PackageName: "Contoso.Tool",
+			Winget:      &WingetOptions{Source: "contoso", SourceURL: "https://winget.contoso.com/api"},
+		}},
+	}
+
+	result := mgr.Install(pkg)
+	if !result.Success {
+		t.Fatalf("expected success, got: %s", result.Message)
+	}
+
+	want := "Would run: winget source add --name contoso --arg https://winget.contoso.com/api --accept-source-agreements && " +
+		"winget install --accept-package-agreements --accept-source-agreements --source contoso Contoso.Tool"
+	if result.Message != want {
+		t.Errorf("message = %q, want %q", result.Message, want)
+	}
+
+	if len(stub.Calls) != 1 || stub.Calls[0].Args[0] != "source" || stub.Calls[0].Args[1] != "list" {
+		t.Errorf("dry run should only list sources, got %+v", stub.Calls)
+	}
+
+	// The source counts as added for the rest of the run.
+	if again := mgr.Install(pkg); strings.Contains(again.Message, "source add") {
+		t.Errorf("second message = %q, want the source add shown once", again.Message)
+	}
+}
+
+func TestInstall_WingetSourceMissingWithoutURL(t *testing.T) {
+	mgr, stub := newStubManager(t, "windows")
+	setAvailable(mgr, Winget)
+	stub.AddResult("winget", cmdexec.Result{ExitCode: 1})
+
+	pkg := Package{
+		Name:     "app",
+		Managers: map[PackageManager]ManagerValue{Winget: {PackageName: "9NBLGGH4NNS1", Winget: &WingetOptions{Source: "msstore"}}},
+	}
+
+	result := mgr.Install(pkg)
+	if result.Success || !strings.Contains(result.Message, "no source_url") {
+		t.Errorf("result = %+v, want a failure naming the missing source_url", result)
+	}
+}
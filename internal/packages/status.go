@@ -2,14 +2,17 @@ package packages
 
 import (
 	"context"
+	"fmt"
 	"log/slog"
-	"os"
-	"path/filepath"
+	"maps"
+	"slices"
 	"strings"
 	"sync"
 
 	"github.com/AntoineGS/tidydots/internal/cmdexec"
 	"github.com/AntoineGS/tidydots/internal/config"
+	"github.com/AntoineGS/tidydots/internal/gitutil"
+	"github.com/AntoineGS/tidydots/internal/platform"
 )
 
 // installedCache holds the lazily-populated set of installed package IDs for
@@ -119,7 +122,7 @@ func isInstallerInstalledWithRunner(binary string, r cmdexec.Runner) bool {
 
 // IsGitInstalled checks whether a git repository has been cloned at the
 // OS-specific target path. It returns true if the target directory contains
-// a .git subdirectory.
+// a .git entry.
 func IsGitInstalled(targets map[string]string, osType string) bool {
 	target, ok := targets[osType]
 	if !ok || target == "" {
@@ -129,14 +132,15 @@ func IsGitInstalled(targets map[string]string, osType string) bool {
 	// Expand ~ (git clone doesn't do shell tilde expansion)
 	target = config.ExpandPath(target, nil)
 
-	_, err := os.Stat(filepath.Join(target, ".git"))
-	return err == nil
+	return gitutil.IsCloned(target)
 }
 
 // CanInstall checks if a package can be installed on this system.
 // It returns true if any of the package's installation methods (manager,
 // custom command, or URL) are available for the current OS and package managers.
 func (m *Manager) CanInstall(pkg Package) bool {
+	m.ensureManagers()
+
 	// Check managers
 	for _, mgr := range m.Available {
 		if _, ok := pkg.Managers[mgr]; ok {
@@ -161,12 +165,78 @@ func (m *Manager) CanInstall(pkg Package) bool {
 	return false
 }
 
+// GetInstallabilityReason returns why pkg cannot be installed on this
+// system, for showing next to an unavailable package, or "" when CanInstall
+// reports it can. In order, it names the package's managers for this OS that
+// are not installed, then the other OSes its custom command, URL or installer
+// command is for, and otherwise says no manager is for this OS.
+func (m *Manager) GetInstallabilityReason(pkg Package) string {
+	if m.CanInstall(pkg) {
+		return ""
+	}
+
+	var missing []string
+
+	for mgr, val := range pkg.Managers {
+		if mgr != Installer && !val.IsInstaller() && platform.IsManagerValidForOS(string(mgr), m.OS) {
+			missing = append(missing, string(mgr))
+		}
+	}
+
+	slices.Sort(missing)
+
+	switch {
+	case len(missing) == 1:
+		return fmt.Sprintf("manager %s configured but not installed", missing[0])
+	case len(missing) > 1:
+		return fmt.Sprintf("managers %s configured but not installed", strings.Join(missing, ", "))
+	case len(pkg.Custom) > 0:
+		return "custom command only for " + joinKeys(pkg.Custom)
+	case len(pkg.URL) > 0:
+		return "url install only for " + joinKeys(pkg.URL)
+	}
+
+	if val, ok := pkg.Managers[Installer]; ok && val.IsInstaller() && len(val.Installer.Command) > 0 {
+		return "installer command only for " + joinKeys(val.Installer.Command)
+	}
+
+	return "no manager for " + m.OS
+}
+
+// joinKeys returns the sorted keys of m, comma-separated.
+func joinKeys[V any](m map[string]V) string {
+	return strings.Join(slices.Sorted(maps.Keys(m)), ", ")
+}
+
+// managerOrder returns the available managers in the order they are tried
+// for pkg: its preferred manager first when available, then the others in
+// priority order.
+func (m *Manager) managerOrder(pkg Package) []PackageManager {
+	m.ensureManagers()
+
+	if pkg.Prefer == "" || !m.availableSet[pkg.Prefer] {
+		return m.Available
+	}
+
+	order := make([]PackageManager, 0, len(m.Available))
+	order = append(order, pkg.Prefer)
+
+	for _, mgr := range m.Available {
+		if mgr != pkg.Prefer {
+			order = append(order, mgr)
+		}
+	}
+
+	return order
+}
+
 // GetInstallMethod returns the method that would be used to install a package.
-// It returns the name of the first available package manager, "installer" for
-// installer packages, "custom" if a custom command is available, "url" for
-// URL-based installation, or "none" if no installation method is available.
+// It returns the name of the first available package manager, the package's
+// preferred one first, "installer" for installer packages, "custom" if a
+// custom command is available, "url" for URL-based installation, or "none"
+// if no installation method is available.
 func (m *Manager) GetInstallMethod(pkg Package) string {
-	for _, mgr := range m.Available {
+	for _, mgr := range m.managerOrder(pkg) {
 		if _, ok := pkg.Managers[mgr]; ok {
 			return string(mgr)
 		}
@@ -204,3 +274,89 @@ func (m *Manager) GetInstallablePackages() []Package {
 
 	return result
 }
+
+// States reported by Check.
+const (
+	// CheckInstalled means the package is already on this system
+	CheckInstalled = "installed"
+	// CheckMissing means the package is not installed but could be
+	CheckMissing = "missing"
+	// CheckUnavailable means no install method of the package works here
+	CheckUnavailable = "unavailable"
+)
+
+// CheckResult is the installed state of one package, as reported by Check.
+type CheckResult struct {
+	Package string
+	State   string // CheckInstalled, CheckMissing or CheckUnavailable
+	Method  string // method an install would use; MethodNone when unavailable
+}
+
+// Check reports whether pkg is installed, without installing anything. A
+// package counts as installed when any of its methods that can be checked
+// finds it: a git clone at its target, an installer's binary in PATH, or any
+// available package manager listing it. Custom and URL installs leave no
+// record, so for them a command named after the package must be in PATH.
+func (m *Manager) Check(pkg Package) CheckResult {
+	result := CheckResult{Package: pkg.Name, Method: m.GetInstallMethod(pkg)}
+
+	switch {
+	case m.isPackageInstalled(pkg):
+		result.State = CheckInstalled
+	case result.Method == MethodNone:
+		result.State = CheckUnavailable
+	default:
+		result.State = CheckMissing
+	}
+
+	return result
+}
+
+// CheckAll runs Check on each package, in order.
+func (m *Manager) CheckAll(pkgs []Package) []CheckResult {
+	results := make([]CheckResult, 0, len(pkgs))
+
+	for _, pkg := range pkgs {
+		if m.ctx.Err() != nil {
+			break
+		}
+
+		results = append(results, m.Check(pkg))
+	}
+
+	return results
+}
+
+// isPackageInstalled reports whether any checkable method of pkg finds it
+// installed; see Check.
+func (m *Manager) isPackageInstalled(pkg Package) bool {
+	if val, ok := pkg.Managers[Git]; ok && val.IsGit() {
+		if target := val.Git.Targets[m.OS]; target != "" && gitutil.IsCloned(config.ExpandPath(target, nil)) {
+			return true
+		}
+	}
+
+	if val, ok := pkg.Managers[Installer]; ok && val.IsInstaller() && val.Installer.Binary != "" {
+		if isInstallerInstalledWithRunner(val.Installer.Binary, m.runner) {
+			return true
+		}
+	}
+
+	m.ensureManagers()
+
+	for _, mgr := range m.Available {
+		val, ok := pkg.Managers[mgr]
+		if !ok || val.IsGit() || val.IsInstaller() {
+			continue
+		}
+
+		if isInstalledWithRunner(m.ctx, val.PackageName, string(mgr), m.runner) {
+			return true
+		}
+	}
+
+	_, hasCustom := pkg.Custom[m.OS]
+	_, hasURL := pkg.URL[m.OS]
+
+	return (hasCustom || hasURL) && isInstallerInstalledWithRunner(pkg.Name, m.runner)
+}
@@ -2,9 +2,14 @@ package packages
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"os"
+	"os/exec"
+	"slices"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/AntoineGS/tidydots/internal/cmdexec"
 	"github.com/AntoineGS/tidydots/internal/config"
@@ -23,10 +28,21 @@ func newStubManager(t *testing.T, osType string) (*Manager, *cmdexec.StubRunner)
 		Available:    []PackageManager{},
 		availableSet: map[PackageManager]bool{},
 		runner:       stub,
+		getUID:       func() int { return 1000 },
 	}
+	stub.AddPath("sudo", "/usr/bin/sudo")
 	return mgr, stub
 }
 
+// buildCommand builds the install command of pkg for a user who is not root
+// on an osType machine with sudo installed.
+func buildCommand(t *testing.T, pkg Package, method, osType string, noSudo bool, renderer config.PathRenderer) *exec.Cmd {
+	t.Helper()
+	mgr, _ := newStubManager(t, osType)
+	mgr.NoSudo = noSudo
+	return mgr.BuildCommand(context.Background(), pkg, method, renderer)
+}
+
 // setAvailable is a helper to configure which managers are available on a stub manager.
 func setAvailable(m *Manager, managers ...PackageManager) {
 	m.Available = managers
@@ -75,6 +91,116 @@ func TestInstall_Pacman_CallsCorrectCommand(t *testing.T) {
 	}
 }
 
+func TestInstall_Emerge_PassesUseOverride(t *testing.T) {
+	mgr, stub := newStubManager(t, "linux")
+	setAvailable(mgr, Emerge)
+
+	pkg := Package{
+		Name: "neovim",
+		Managers: map[PackageManager]ManagerValue{
+			Emerge: {PackageName: "app-editors/neovim", Emerge: &EmergeOptions{UseFlagsOverride: "lua"}},
+		},
+	}
+
+	result := mgr.Install(pkg)
+	if !result.Success {
+		t.Fatalf("expected success, got: %s", result.Message)
+	}
+
+	if len(stub.Calls) == 0 {
+		t.Fatal("expected at least one stub call")
+	}
+
+	call := stub.Calls[len(stub.Calls)-1]
+	got := strings.Join(append([]string{call.Name}, call.Args...), " ")
+	if want := "sudo env USE=lua emerge -v app-editors/neovim"; got != want {
+		t.Errorf("command = %q, want %q", got, want)
+	}
+}
+
+func TestInstall_Apt_AddsMissingRepo(t *testing.T) {
+	mgr, stub := newStubManager(t, "linux")
+	setAvailable(mgr, Apt)
+	stub.AddResult("grep", cmdexec.Result{ExitCode: 1})
+
+	pkg := Package{
+		Name: "neovim",
+		Managers: map[PackageManager]ManagerValue{
+			Apt: {PackageName: "neovim", Apt: &AptOptions{Repo: "ppa:neovim-ppa/stable"}},
+		},
+	}
+
+	result := mgr.Install(pkg)
+	if !result.Success {
+		t.Fatalf("expected success, got: %s", result.Message)
+	}
+
+	var got []string
+	for _, call := range stub.Calls {
+		got = append(got, strings.Join(append([]string{call.Name}, call.Args...), " "))
+	}
+
+	want := []string{
+		"grep -rqsF -- /neovim-ppa/stable/ubuntu /etc/apt/sources.list /etc/apt/sources.list.d",
+		"sudo add-apt-repository -y ppa:neovim-ppa/stable",
+		"sudo apt-get update",
+		"sudo apt-get install -y neovim",
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("commands =\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+}
+
+func TestInstall_Apt_SkipsPresentRepo(t *testing.T) {
+	mgr, stub := newStubManager(t, "linux")
+	setAvailable(mgr, Apt)
+
+	pkg := Package{
+		Name: "neovim",
+		Managers: map[PackageManager]ManagerValue{
+			Apt: {PackageName: "neovim", Apt: &AptOptions{Repo: "ppa:neovim-ppa/stable"}},
+		},
+	}
+
+	if result := mgr.Install(pkg); !result.Success {
+		t.Fatalf("expected success, got: %s", result.Message)
+	}
+
+	if len(stub.Calls) != 2 || stub.Calls[1].Name != "sudo" || stub.Calls[1].Args[0] != "apt-get" {
+		t.Errorf("expected the repo check then the install, got %+v", stub.Calls)
+	}
+}
+
+func TestInstall_Apt_RepoDryRun(t *testing.T) {
+	mgr, stub := newStubManager(t, "linux")
+	setAvailable(mgr, Apt)
+	mgr.DryRun = true
+	stub.AddResult("grep", cmdexec.Result{ExitCode: 1})
+
+	pkg := Package{
+		Name: "neovim",
+		Managers: map[PackageManager]ManagerValue{
+			Apt: {PackageName: "neovim", Apt: &AptOptions{Repo: "ppa:neovim-ppa/stable"}},
+		},
+	}
+
+	result := mgr.Install(pkg)
+	if !result.Success {
+		t.Fatalf("expected success, got: %s", result.Message)
+	}
+
+	want := "Would run: sudo add-apt-repository -y ppa:neovim-ppa/stable && sudo apt-get update && sudo apt-get install -y neovim"
+	if result.Message != want {
+		t.Errorf("message = %q, want %q", result.Message, want)
+	}
+
+	for _, call := range stub.Calls {
+		if call.Name != "grep" {
+			t.Errorf("dry run ran %s %v", call.Name, call.Args)
+		}
+	}
+}
+
 func TestInstall_Yay_CallsCorrectCommand(t *testing.T) {
 	mgr, stub := newStubManager(t, "linux")
 	setAvailable(mgr, Yay)
@@ -133,6 +259,124 @@ func TestInstall_Apt_UsesNonSudoRunner(t *testing.T) {
 	}
 }
 
+func TestInstall_PreferredManager(t *testing.T) {
+	mgr, stub := newStubManager(t, "linux")
+	setAvailable(mgr, Yay, Pacman)
+
+	result := mgr.Install(Package{
+		Name: "firefox",
+		Managers: map[PackageManager]ManagerValue{
+			Yay:    {PackageName: "firefox-bin"},
+			Pacman: {PackageName: "firefox"},
+		},
+		Prefer: Pacman,
+	})
+	if !result.Success || result.Method != string(Pacman) {
+		t.Fatalf("result = %+v, want installed with pacman over the higher-priority yay", result)
+	}
+
+	if len(stub.Calls) != 1 || !slices.Contains(stub.Calls[0].Args, "firefox") {
+		t.Errorf("calls = %+v, want the repo package installed", stub.Calls)
+	}
+}
+
+func TestInstall_NoSudo(t *testing.T) {
+	mgr, stub := newStubManager(t, "linux")
+	setAvailable(mgr, Apt)
+	mgr.NoSudo = true
+
+	result := mgr.Install(Package{
+		Name:     "vim",
+		Managers: map[PackageManager]ManagerValue{Apt: {PackageName: "vim"}},
+	})
+	if !result.Success || result.Skipped {
+		t.Errorf("apt install: Success = %v, Skipped = %v, want installed", result.Success, result.Skipped)
+	}
+
+	if len(stub.Calls) != 1 || stub.Calls[0].Name != "apt-get" {
+		t.Fatalf("calls = %+v, want a single apt-get call without sudo", stub.Calls)
+	}
+
+	result = mgr.Install(Package{
+		Name: "repo",
+		Managers: map[PackageManager]ManagerValue{Git: {Git: &GitConfig{
+			URL:     "https://github.com/example/repo.git",
+			Targets: map[string]string{"linux": "/opt/repo"},
+			Sudo:    true,
+		}}},
+	})
+	if !result.Skipped || !result.Success || result.Message != MsgRequiresSudo {
+		t.Errorf("sudo git install = %+v, want skipped with %q", result, MsgRequiresSudo)
+	}
+
+	if len(stub.Calls) != 1 {
+		t.Errorf("sudo git package ran %d commands, want none", len(stub.Calls)-1)
+	}
+}
+
+func TestManager_RequiresSudo(t *testing.T) {
+	tests := []struct {
+		name   string
+		mgr    PackageManager
+		uid    int
+		sudo   bool
+		noSudo bool
+		want   bool
+	}{
+		{name: "user with sudo", mgr: Pacman, uid: 1000, sudo: true, want: true},
+		{name: "root", mgr: Pacman, uid: 0, sudo: true, want: false},
+		{name: "sudo not installed", mgr: Apt, uid: 1000, want: false},
+		{name: "no sudo", mgr: Dnf, uid: 1000, sudo: true, noSudo: true, want: false},
+		{name: "manager without sudo", mgr: Yay, uid: 1000, sudo: true, want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mgr, _ := newStubManager(t, "linux")
+			mgr.getUID = func() int { return tt.uid }
+			mgr.NoSudo = tt.noSudo
+
+			if !tt.sudo {
+				mgr.runner = cmdexec.NewStubRunner()
+			}
+
+			if got := mgr.RequiresSudo(tt.mgr); got != tt.want {
+				t.Errorf("RequiresSudo(%s) = %v, want %v", tt.mgr, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBuildCommand_Root(t *testing.T) {
+	mgr, _ := newStubManager(t, "linux")
+	mgr.getUID = func() int { return 0 }
+
+	cmd := mgr.BuildCommand(context.Background(), Package{
+		Name:     "vim",
+		Managers: map[PackageManager]ManagerValue{Pacman: {PackageName: "vim"}},
+	}, string(Pacman), nil)
+	if cmd == nil {
+		t.Fatal("BuildCommand() = nil")
+	}
+
+	if want := []string{"pacman", "-S", "--noconfirm", "vim"}; !slices.Equal(cmd.Args, want) {
+		t.Errorf("BuildCommand() as root = %v, want %v", cmd.Args, want)
+	}
+
+	// A sudo git package clones as root directly rather than being skipped.
+	cmd = mgr.BuildCommand(context.Background(), Package{
+		Name: "repo",
+		Managers: map[PackageManager]ManagerValue{Git: {Git: &GitConfig{
+			URL:     "https://github.com/example/repo.git",
+			Targets: map[string]string{"linux": "/opt/repo"},
+			Sudo:    true,
+		}}},
+	}, string(Git), nil)
+	if cmd == nil || cmd.Args[0] != "git" {
+		t.Errorf("BuildCommand(sudo git) as root = %v, want git without sudo", cmd)
+	}
+}
+
 func TestInstall_MultipleManagersAvailable_UsesFirst(t *testing.T) {
 	mgr, stub := newStubManager(t, "linux")
 	// Both yay and pacman available; pkg has both — yay comes first in Available
@@ -540,6 +784,54 @@ func TestInstall_CustomCommand_WrongOS_ReturnsFailure(t *testing.T) {
 	}
 }
 
+func TestInstall_VerifyCommand(t *testing.T) {
+	mgr, _ := newStubManager(t, "linux")
+	setAvailable(mgr)
+
+	stub := &failingRunner{StubRunner: *cmdexec.NewStubRunner(), fail: map[string]bool{"tool --version": true}}
+	mgr = mgr.WithRunner(stub)
+
+	custom := Package{
+		Name:   "tool",
+		Custom: map[string]string{"linux": "make install"},
+		Verify: map[string]string{"linux": "tool --version"},
+	}
+
+	result := mgr.Install(custom)
+	if result.Success || !result.Unverified || !strings.HasPrefix(result.Message, MsgVerifyFailed) {
+		t.Errorf("custom install with a failing verify = %+v, want an unverified failure", result)
+	}
+
+	installer := Package{
+		Name: "other",
+		Managers: map[PackageManager]ManagerValue{Installer: {Installer: &InstallerConfig{
+			Command: map[string]string{"linux": "curl -fsSL https://example.com/install.sh | sh"},
+			Verify:  map[string]string{"linux": "other --version"},
+		}}},
+	}
+
+	result = mgr.Install(installer)
+	if !result.Success || result.Unverified || result.Message != "Installed via installer, verified" {
+		t.Errorf("installer install with a passing verify = %+v, want a verified success", result)
+	}
+
+	if last := stub.Calls[len(stub.Calls)-1]; !slices.Equal(last.Args, []string{"-c", "other --version"}) {
+		t.Errorf("last call = %s %v, want the verify command", last.Name, last.Args)
+	}
+
+	// A failed install command is not verified.
+	stub.fail["make install"] = true
+	calls := len(stub.Calls)
+
+	if result = mgr.Install(custom); result.Success || result.Unverified {
+		t.Errorf("failed custom install = %+v, want a plain failure", result)
+	}
+
+	if len(stub.Calls) != calls+1 {
+		t.Errorf("made %d calls after a failed install command, want only the install", len(stub.Calls)-calls)
+	}
+}
+
 // --- InstallAll with stub ---
 
 func TestInstallAll_MultiplePackages(t *testing.T) {
@@ -568,6 +860,335 @@ func TestInstallAll_MultiplePackages(t *testing.T) {
 	}
 }
 
+func customPkg(name string, phase int) Package {
+	return Package{Name: name, Phase: phase, Custom: map[string]string{"linux": "install " + name}}
+}
+
+func TestInstallAll_InstallsLowerPhasesFirst(t *testing.T) {
+	mgr, stub := newStubManager(t, "linux")
+
+	results := mgr.InstallAll([]Package{
+		customPkg("gui", 2),
+		customPkg("runtime", 0),
+		customPkg("tool", 1),
+		customPkg("base", 0),
+	})
+
+	var gotCalls []string
+	for _, c := range stub.Calls {
+		gotCalls = append(gotCalls, c.Args[1])
+	}
+
+	wantCalls := []string{"install runtime", "install base", "install tool", "install gui"}
+	if strings.Join(gotCalls, ",") != strings.Join(wantCalls, ",") {
+		t.Errorf("install order = %v, want %v", gotCalls, wantCalls)
+	}
+
+	var gotResults []string
+	for _, r := range results {
+		gotResults = append(gotResults, fmt.Sprintf("%s@%d", r.Package, r.Phase))
+	}
+
+	wantResults := []string{"runtime@0", "base@0", "tool@1", "gui@2"}
+	if strings.Join(gotResults, ",") != strings.Join(wantResults, ",") {
+		t.Errorf("results = %v, want %v", gotResults, wantResults)
+	}
+}
+
+func TestInstallAll_ParallelWithinPhase(t *testing.T) {
+	mgr, stub := newStubManager(t, "linux")
+	setAvailable(mgr, Pacman)
+	mgr.Jobs = 4
+
+	packages := []Package{
+		customPkg("a", 0),
+		{Name: "b", Managers: map[PackageManager]ManagerValue{Pacman: {PackageName: "b", Deps: []string{"b-dep"}}}},
+		{Name: "c", Managers: map[PackageManager]ManagerValue{Pacman: {PackageName: "c"}}},
+		customPkg("d", 0),
+		customPkg("late", 1),
+	}
+
+	results := mgr.InstallAll(packages)
+
+	if len(results) != len(packages) {
+		t.Fatalf("expected %d results, got %d", len(packages), len(results))
+	}
+
+	for i, r := range results {
+		if r.Package != packages[i].Name {
+			t.Errorf("results[%d] = %q, want %q", i, r.Package, packages[i].Name)
+		}
+		if !r.Success {
+			t.Errorf("%s: expected success, got %s", r.Package, r.Message)
+		}
+	}
+
+	if len(stub.Calls) != 6 {
+		t.Fatalf("expected 6 calls, got %d: %v", len(stub.Calls), stub.Calls)
+	}
+
+	last := stub.Calls[len(stub.Calls)-1]
+	if last.Args[len(last.Args)-1] != "install late" {
+		t.Errorf("phase 1 package must install after phase 0, last call = %v", last)
+	}
+
+	// A package's deps are installed before the package itself.
+	depIdx, pkgIdx := -1, -1
+	for i, c := range stub.Calls {
+		switch c.Args[len(c.Args)-1] {
+		case "b-dep":
+			depIdx = i
+		case "b":
+			pkgIdx = i
+		}
+	}
+
+	if depIdx == -1 || pkgIdx == -1 || depIdx > pkgIdx {
+		t.Errorf("expected b-dep before b, got indexes %d and %d", depIdx, pkgIdx)
+	}
+}
+
+// blockingRunner is a cmdexec.Runner whose commands run until their context
+// is canceled. started receives one value per command once it is running.
+type blockingRunner struct {
+	cmdexec.StubRunner
+	started chan string
+}
+
+func (r *blockingRunner) Run(ctx context.Context, name string, _ ...string) (cmdexec.Result, error) {
+	r.started <- name
+	<-ctx.Done()
+
+	return cmdexec.Result{}, ctx.Err()
+}
+
+func TestInstallWithContext_CanceledInFlight(t *testing.T) {
+	mgr, _ := newStubManager(t, "linux")
+	runner := &blockingRunner{started: make(chan string, 1)}
+	mgr = mgr.WithRunner(runner)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	done := make(chan InstallResult)
+	go func() { done <- mgr.InstallWithContext(ctx, customPkg("slow", 0)) }()
+
+	<-runner.started
+	cancel()
+
+	result := <-done
+	if result.Success {
+		t.Fatal("expected a canceled install to fail")
+	}
+
+	if !strings.HasPrefix(result.Message, MsgCanceled+":") || !strings.Contains(result.Message, context.Canceled.Error()) {
+		t.Errorf("message = %q, want it to start with %q and name the cancellation", result.Message, MsgCanceled)
+	}
+}
+
+func TestInstallAll_CanceledContextSkipsRemaining(t *testing.T) {
+	mgr, _ := newStubManager(t, "linux")
+	runner := &blockingRunner{started: make(chan string, 1)}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	mgr = mgr.WithRunner(runner).WithContext(ctx)
+
+	done := make(chan []InstallResult)
+	go func() { done <- mgr.InstallAll([]Package{customPkg("first", 0), customPkg("second", 1)}) }()
+
+	<-runner.started
+	cancel()
+
+	results := <-done
+	if len(results) != 2 {
+		t.Fatalf("expected 2 results, got %d", len(results))
+	}
+
+	for _, r := range results {
+		if r.Success || !strings.HasPrefix(r.Message, MsgCanceled+":") {
+			t.Errorf("%s: result = %+v, want a canceled failure", r.Package, r)
+		}
+	}
+
+	select {
+	case name := <-runner.started:
+		t.Errorf("ran %q after the context was canceled", name)
+	default:
+	}
+}
+
+func TestGroupByPhase(t *testing.T) {
+	phases := GroupByPhase([]Package{
+		{Name: "c", Phase: 1},
+		{Name: "a"},
+		{Name: "z", Phase: -1},
+		{Name: "b"},
+	})
+
+	var got []string
+	for _, phase := range phases {
+		var names []string
+		for _, pkg := range phase {
+			names = append(names, pkg.Name)
+		}
+		got = append(got, strings.Join(names, " "))
+	}
+
+	want := []string{"z", "a b", "c"}
+	if strings.Join(got, "|") != strings.Join(want, "|") {
+		t.Errorf("GroupByPhase = %q, want %q", got, want)
+	}
+
+	if GroupByPhase(nil) != nil {
+		t.Error("expected no phases for no packages")
+	}
+}
+
+func TestGroupByPhase_After(t *testing.T) {
+	after := func(name string, phase int, names ...string) Package {
+		return Package{Name: name, Phase: phase, After: names}
+	}
+
+	tests := []struct {
+		name     string
+		packages []Package
+		want     string
+	}{
+		{
+			name:     "after moves a package behind the named one",
+			packages: []Package{after("cargo-tools", 0, "rust"), after("git", 0), after("rust", 0)},
+			want:     "git rust cargo-tools",
+		},
+		{
+			name:     "chains are followed",
+			packages: []Package{after("c", 0, "b"), after("b", 0, "a"), after("a", 0)},
+			want:     "a b c",
+		},
+		{
+			name:     "names not installed together are ignored",
+			packages: []Package{after("b", 0, "missing", "a"), after("a", 1), after("c", 0, "c")},
+			want:     "b c|a",
+		},
+		{
+			name:     "cycles keep the given order",
+			packages: []Package{after("a", 0, "b"), after("b", 0, "a"), after("c", 0)},
+			want:     "c a b",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			for _, phase := range GroupByPhase(tt.packages) {
+				var names []string
+				for _, pkg := range phase {
+					names = append(names, pkg.Name)
+				}
+				got = append(got, strings.Join(names, " "))
+			}
+
+			if strings.Join(got, "|") != tt.want {
+				t.Errorf("GroupByPhase = %q, want %q", strings.Join(got, "|"), tt.want)
+			}
+		})
+	}
+}
+
+// failingRunner is a cmdexec.Runner that fails the commands whose last
+// argument is in fail and runs the rest successfully.
+type failingRunner struct {
+	cmdexec.StubRunner
+	fail map[string]bool
+}
+
+func (r *failingRunner) Run(ctx context.Context, name string, args ...string) (cmdexec.Result, error) {
+	result, _ := r.StubRunner.Run(ctx, name, args...)
+	if len(args) > 0 && r.fail[args[len(args)-1]] {
+		return cmdexec.Result{ExitCode: 1}, fmt.Errorf("exit status 1")
+	}
+
+	return result, nil
+}
+
+// An after entry only orders installs: the later package is installed even
+// when the earlier one fails. A dep is a requirement: when it fails, the
+// package is not installed.
+func TestInstallAll_AfterIsNotADependency(t *testing.T) {
+	mgr, _ := newStubManager(t, "linux")
+	setAvailable(mgr, Pacman)
+
+	stub := &failingRunner{fail: map[string]bool{"install rust": true, "plugin-dep": true}}
+	mgr = mgr.WithRunner(stub)
+
+	tools := customPkg("cargo-tools", 0)
+	tools.After = []string{"rust"}
+
+	results := mgr.InstallAll([]Package{
+		tools,
+		customPkg("rust", 0),
+		{Name: "plugin", Managers: map[PackageManager]ManagerValue{Pacman: {PackageName: "plugin", Deps: []string{"plugin-dep"}}}},
+	})
+
+	got := make(map[string]InstallResult, len(results))
+	for _, r := range results {
+		got[r.Package] = r
+	}
+
+	if results[0].Package != "rust" || got["rust"].Success {
+		t.Errorf("expected rust to be installed first and fail, got %+v", results)
+	}
+
+	if !got["cargo-tools"].Success {
+		t.Errorf("cargo-tools: expected success after rust failed, got %s", got["cargo-tools"].Message)
+	}
+
+	if r := got["plugin"]; r.Success || !strings.Contains(r.Message, "Dependency plugin-dep failed") {
+		t.Errorf("plugin: expected its failed dep to stop it, got %+v", r)
+	}
+
+	for _, c := range stub.Calls {
+		if c.Args[len(c.Args)-1] == "plugin" {
+			t.Errorf("plugin was installed although its dep failed: %v", c)
+		}
+	}
+}
+
+func TestInstallAll_ParallelWaitsForAfter(t *testing.T) {
+	mgr, _ := newStubManager(t, "linux")
+	runner := &blockingRunner{started: make(chan string, 2)}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	mgr = mgr.WithRunner(runner).WithContext(ctx)
+	mgr.Jobs = 4
+
+	tools := customPkg("cargo-tools", 0)
+	tools.After = []string{"rust"}
+
+	done := make(chan []InstallResult)
+	go func() { done <- mgr.InstallAll([]Package{tools, customPkg("rust", 0), customPkg("git", 0)}) }()
+
+	// rust and git start right away; cargo-tools waits for rust.
+	<-runner.started
+	<-runner.started
+
+	select {
+	case name := <-runner.started:
+		t.Errorf("%s started while rust was still installing", name)
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	cancel()
+
+	results := <-done
+	if len(results) != 3 || results[0].Package != "rust" || results[1].Package != "cargo-tools" {
+		t.Errorf("results = %+v, want cargo-tools right after rust", results)
+	}
+}
+
 // --- Status checks with isInstalledWithRunner ---
 
 func TestIsInstalledWithRunner_PackagePresentReturnsTrue(t *testing.T) {
@@ -1022,3 +1643,322 @@ func TestWithRunner_ReturnsNewManagerWithStub(t *testing.T) {
 		t.Error("WithRunner changed Config pointer")
 	}
 }
+
+func TestInstallFromURL_DryRunMentionsVerification(t *testing.T) {
+	mgr, stub := newStubManager(t, "linux")
+	mgr.DryRun = true
+
+	spec := URLInstall{URL: "https://example.com/install.sh", Command: "sh {file}", SHA256: strings.Repeat("a", 64)}
+
+	ok, msg := mgr.installFromURL(spec)
+	if !ok || !strings.Contains(msg, "verify its checksum") {
+		t.Errorf("installFromURL() = %v, %q; want dry-run message mentioning verification", ok, msg)
+	}
+
+	mgr.SkipVerify = true
+	if _, msg := mgr.installFromURL(spec); strings.Contains(msg, "verify") {
+		t.Errorf("dry-run message %q mentions verification despite SkipVerify", msg)
+	}
+
+	if len(stub.Calls) != 0 {
+		t.Errorf("dry run executed %d commands, want 0", len(stub.Calls))
+	}
+}
+
+// flakyRunner is a cmdexec.Runner that fails its first `failures` commands
+// and runs the rest successfully.
+type flakyRunner struct {
+	cmdexec.StubRunner
+	failures int
+}
+
+func (r *flakyRunner) Run(ctx context.Context, name string, args ...string) (cmdexec.Result, error) {
+	result, _ := r.StubRunner.Run(ctx, name, args...)
+	if len(r.Calls) <= r.failures {
+		return cmdexec.Result{ExitCode: 1}, fmt.Errorf("exit status 1")
+	}
+
+	return result, nil
+}
+
+func TestInstall_RetriesUntilSuccess(t *testing.T) {
+	mgr, _ := newStubManager(t, "linux")
+	runner := &flakyRunner{failures: 2}
+	mgr = mgr.WithRunner(runner)
+	mgr.InstallRetries = 3
+	mgr.RetryDelay = time.Millisecond
+
+	result := mgr.Install(customPkg("flaky", 0))
+	if !result.Success {
+		t.Fatalf("expected success after retries, got: %s", result.Message)
+	}
+
+	if result.Attempts != 3 || len(runner.Calls) != 3 {
+		t.Errorf("Attempts = %d, commands run = %d, want 3 and 3", result.Attempts, len(runner.Calls))
+	}
+}
+
+func TestInstall_RetriesExhausted(t *testing.T) {
+	mgr, _ := newStubManager(t, "linux")
+	runner := &flakyRunner{failures: 5}
+	mgr = mgr.WithRunner(runner)
+	mgr.InstallRetries = 2
+	mgr.RetryDelay = time.Millisecond
+
+	result := mgr.Install(customPkg("broken", 0))
+	if result.Success {
+		t.Fatal("expected failure once retries are exhausted")
+	}
+
+	if result.Attempts != 3 || len(runner.Calls) != 3 {
+		t.Errorf("Attempts = %d, commands run = %d, want 3 and 3", result.Attempts, len(runner.Calls))
+	}
+}
+
+func TestInstall_NoRetriesByDefault(t *testing.T) {
+	mgr, _ := newStubManager(t, "linux")
+	runner := &flakyRunner{failures: 1}
+	mgr = mgr.WithRunner(runner)
+
+	result := mgr.Install(customPkg("once", 0))
+	if result.Success || result.Attempts != 1 {
+		t.Errorf("result = %+v, want one failed attempt", result)
+	}
+}
+
+func TestInstallWithContext_CancelStopsRetries(t *testing.T) {
+	mgr, _ := newStubManager(t, "linux")
+	runner := &flakyRunner{failures: 5}
+	mgr = mgr.WithRunner(runner)
+	mgr.InstallRetries = 5
+	mgr.RetryDelay = time.Hour
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	done := make(chan InstallResult)
+	go func() { done <- mgr.InstallWithContext(ctx, customPkg("slow", 0)) }()
+
+	// The first attempt fails at once; cancel while waiting for the retry.
+	time.Sleep(10 * time.Millisecond)
+	cancel()
+
+	select {
+	case result := <-done:
+		if result.Success || result.Attempts != 1 || !strings.HasPrefix(result.Message, MsgCanceled+":") {
+			t.Errorf("result = %+v, want one canceled attempt", result)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("cancellation did not interrupt the retry delay")
+	}
+}
+
+func TestRetryDelay(t *testing.T) {
+	tests := []struct {
+		base    time.Duration
+		attempt int
+		want    time.Duration
+	}{
+		{time.Second, 1, time.Second},
+		{time.Second, 2, 2 * time.Second},
+		{time.Second, 4, 8 * time.Second},
+		{time.Second, 10, maxRetryDelay},
+		{45 * time.Second, 2, maxRetryDelay},
+		{0, 3, 0},
+	}
+
+	for _, tt := range tests {
+		if got := retryDelay(tt.base, tt.attempt); got != tt.want {
+			t.Errorf("retryDelay(%v, %d) = %v, want %v", tt.base, tt.attempt, got, tt.want)
+		}
+	}
+}
+
+// --- Git repositories ---
+
+func gitPkg(name, target string, sudo bool) Package {
+	return Package{
+		Name: name,
+		Managers: map[PackageManager]ManagerValue{
+			Git: {Git: &config.GitPackage{
+				URL:     "https://github.com/user/" + name + ".git",
+				Targets: map[string]string{"linux": target},
+				Sudo:    sudo,
+			}},
+		},
+	}
+}
+
+func TestGitRepos(t *testing.T) {
+	repos := GitRepos([]Package{
+		gitPkg("nvim", "/home/u/.config/nvim", false),
+		customPkg("tool", 0),
+		gitPkg("windows-only", "", false),
+		gitPkg("system", "/opt/system", true),
+	}, "linux")
+
+	if len(repos) != 2 || repos[0].Name != "nvim" || repos[1].Name != "system" {
+		t.Fatalf("GitRepos() = %+v, want nvim and system", repos)
+	}
+
+	if repos[0].Path != "/home/u/.config/nvim" || repos[0].URL != "https://github.com/user/nvim.git" || !repos[1].Sudo {
+		t.Errorf("GitRepos() = %+v, want the packages' URL, target and sudo", repos)
+	}
+}
+
+func TestInstall_Offline(t *testing.T) {
+	mgr, stub := newStubManager(t, "linux")
+	setAvailable(mgr, Apt)
+	mgr.Offline = true
+
+	for _, pkg := range []Package{
+		{Name: "vim", Managers: map[PackageManager]ManagerValue{Apt: {PackageName: "vim"}}},
+		{Name: "tool", URL: map[string]URLInstall{"linux": {URL: "https://example.com/tool", Command: "sh {file}"}}},
+		{Name: "repo", Managers: map[PackageManager]ManagerValue{Git: {Git: &GitConfig{
+			URL:     "https://github.com/example/repo.git",
+			Targets: map[string]string{"linux": "/opt/repo"},
+		}}}},
+	} {
+		result := mgr.Install(pkg)
+		if !result.Success || !result.Skipped || result.Message != MsgOffline {
+			t.Errorf("offline install of %s = %+v, want skipped with %q", pkg.Name, result, MsgOffline)
+		}
+	}
+
+	if len(stub.Calls) != 0 {
+		t.Errorf("offline installs ran %v, want no commands", stub.Calls)
+	}
+
+	repo := GitRepos([]Package{gitPkg("new", t.TempDir()+"/missing", false)}, "linux")[0]
+
+	if r := mgr.CloneRepo(repo); !r.Skipped || r.Message != MsgOffline {
+		t.Errorf("offline clone = %+v, want skipped with %q", r, MsgOffline)
+	}
+
+	if len(stub.Calls) != 0 {
+		t.Errorf("offline clone ran %v, want no commands", stub.Calls)
+	}
+}
+
+func TestCloneRepo(t *testing.T) {
+	cloned := t.TempDir()
+	if err := os.MkdirAll(cloned+"/.git", 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	missing := t.TempDir() + "/missing"
+
+	mgr, stub := newStubManager(t, "linux")
+	mgr.NoSudo = true
+
+	repos := GitRepos([]Package{
+		gitPkg("existing", cloned, false),
+		gitPkg("system", missing, true),
+		gitPkg("new", missing, false),
+	}, "linux")
+
+	var got []string
+	for _, repo := range repos {
+		r := mgr.CloneRepo(repo)
+		got = append(got, fmt.Sprintf("%s:%t:%t", r.Package, r.Success, r.Skipped))
+	}
+
+	want := "existing:true:true system:true:true new:true:false"
+	if strings.Join(got, " ") != want {
+		t.Errorf("CloneRepo results = %v, want %s", got, want)
+	}
+
+	if len(stub.Calls) != 1 || stub.Calls[0].Args[0] != "clone" || stub.Calls[0].Args[len(stub.Calls[0].Args)-1] != missing {
+		t.Errorf("expected only the missing repo to be cloned, got %v", stub.Calls)
+	}
+}
+
+func TestCheckGit(t *testing.T) {
+	mgr, stub := newStubManager(t, "linux")
+
+	if err := mgr.CheckGit(); !errors.Is(err, ErrGitNotFound) {
+		t.Errorf("CheckGit() without git = %v, want ErrGitNotFound", err)
+	}
+
+	stub.AddPath("git", "/usr/bin/git")
+
+	if err := mgr.CheckGit(); err != nil {
+		t.Errorf("CheckGit() with git = %v, want nil", err)
+	}
+}
+
+func TestUpdateRepo(t *testing.T) {
+	cloned := t.TempDir()
+	if err := os.MkdirAll(cloned+"/.git", 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	mgr, stub := newStubManager(t, "linux")
+	mgr.DryRun = true
+
+	if r := mgr.UpdateRepo(GitRepos([]Package{gitPkg("missing", t.TempDir()+"/missing", false)}, "linux")[0], false); r.Success {
+		t.Errorf("updating a repo that is not cloned should fail, got %+v", r)
+	}
+
+	r := mgr.UpdateRepo(GitRepos([]Package{gitPkg("existing", cloned, false)}, "linux")[0], false)
+	if !r.Success || !strings.HasPrefix(r.Message, "Would fetch") {
+		t.Errorf("dry-run update = %+v, want a Would message", r)
+	}
+
+	if len(stub.Calls) != 0 {
+		t.Errorf("expected no commands, got %v", stub.Calls)
+	}
+}
+
+func TestCheckAll(t *testing.T) {
+	cloned := t.TempDir()
+	if err := os.MkdirAll(cloned+"/.git", 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	mgr, _ := newStubManager(t, "linux")
+	setAvailable(mgr, Pacman, Yay, Git)
+
+	stub := &failingRunner{StubRunner: *cmdexec.NewStubRunner(), fail: map[string]bool{"ripgrep": true, "fd": true}}
+	stub.AddPath("starship", "/usr/bin/starship")
+	stub.AddPath("mytool", "/usr/local/bin/mytool")
+	mgr = mgr.WithRunner(stub)
+
+	results := mgr.CheckAll([]Package{
+		{Name: "neovim", Managers: map[PackageManager]ManagerValue{Pacman: {PackageName: "neovim"}}},
+		{Name: "ripgrep", Managers: map[PackageManager]ManagerValue{Pacman: {PackageName: "ripgrep"}}},
+		{Name: "fd", Managers: map[PackageManager]ManagerValue{Pacman: {PackageName: "fd"}, Yay: {PackageName: "fd-git"}}},
+		gitPkg("dotfiles", cloned, false),
+		gitPkg("plugins", t.TempDir()+"/missing", false),
+		{Name: "starship", Managers: map[PackageManager]ManagerValue{Installer: {Installer: &InstallerConfig{
+			Command: map[string]string{"linux": "curl -sS https://starship.rs/install.sh | sh"},
+			Binary:  "starship",
+		}}}},
+		{Name: "mytool", URL: map[string]URLInstall{"linux": {URL: "https://example.com/mytool", Command: "install {file}"}}},
+		{Name: "winonly", Managers: map[PackageManager]ManagerValue{Winget: {PackageName: "Win.Only"}}},
+	})
+
+	got := make([]string, 0, len(results))
+	for _, r := range results {
+		got = append(got, fmt.Sprintf("%s=%s/%s", r.Package, r.State, r.Method))
+	}
+
+	want := []string{
+		"neovim=installed/pacman",
+		"ripgrep=missing/pacman",
+		"fd=installed/pacman",
+		"dotfiles=installed/git",
+		"plugins=missing/git",
+		"starship=installed/installer",
+		"mytool=installed/url",
+		"winonly=unavailable/none",
+	}
+	if strings.Join(got, " ") != strings.Join(want, " ") {
+		t.Errorf("CheckAll() = %v, want %v", got, want)
+	}
+
+	for _, call := range stub.Calls {
+		if slices.Contains(call.Args, "-S") || call.Name == cmdSudo {
+			t.Errorf("CheckAll() ran an install command: %v", call)
+		}
+	}
+}
@@ -14,12 +14,17 @@ const (
 	// ExecPerms are the permissions for executable files (rwxr-xr-x)
 	// Owner: read, write, execute; Group: read, execute; Other: read, execute
 	ExecPerms os.FileMode = 0755
+
+	// PrivateDirPerms are the permissions for temp directories holding
+	// downloads before they are verified and executed (rwx------)
+	PrivateDirPerms os.FileMode = 0700
 )
 
 // PackageManager represents a supported package manager identifier.
 // It is used to specify which package manager should be used for installing
 // a package, such as pacman, apt, brew, winget, etc. The supported values
-// are defined as constants (Pacman, Yay, Paru, Apt, Dnf, Brew, Winget, Scoop, Choco).
+// are defined as constants (Pacman, Yay, Paru, Apt, Dnf, Eopkg, Emerge, Brew,
+// BrewCask, Mas, Winget, Scoop, Choco).
 type PackageManager string
 
 // Supported package manager identifiers.
@@ -34,8 +39,18 @@ const (
 	Apt PackageManager = "apt"
 	// Dnf is the Fedora package manager
 	Dnf PackageManager = "dnf"
+	// Eopkg is the Solus package manager
+	Eopkg PackageManager = "eopkg"
+	// Emerge is the Gentoo package manager
+	Emerge PackageManager = "emerge"
+	// Portage is an alias for Emerge; portage entries are loaded as emerge
+	Portage PackageManager = "portage"
 	// Brew is the macOS package manager
 	Brew PackageManager = "brew"
+	// BrewCask is Homebrew's cask mode, for macOS GUI apps
+	BrewCask PackageManager = "brew-cask"
+	// Mas installs Mac App Store apps by their numeric App Store ID
+	Mas PackageManager = "mas"
 	// Winget is the Windows package manager
 	Winget PackageManager = "winget"
 	// Scoop is a Windows package manager
@@ -70,6 +85,21 @@ type (
 
 	// ManagerValue is an alias for config.ManagerValue.
 	ManagerValue = config.ManagerValue
+
+	// EmergeOptions is an alias for config.EmergeOptions.
+	EmergeOptions = config.EmergeOptions
+
+	// AptOptions is an alias for config.AptOptions.
+	AptOptions = config.AptOptions
+
+	// MasOptions is an alias for config.MasOptions.
+	MasOptions = config.MasOptions
+
+	// ScoopOptions is an alias for config.ScoopOptions.
+	ScoopOptions = config.ScoopOptions
+
+	// WingetOptions is an alias for config.WingetOptions.
+	WingetOptions = config.WingetOptions
 )
 
 // Package represents a package to install with multiple installation methods.
@@ -77,14 +107,23 @@ type (
 // command (Custom), or by downloading from a URL (URL). The installation method
 // is selected based on availability, with package managers tried first, then
 // custom commands, and finally URL-based installation. A `when` expression can
-// conditionally include the package based on template variables.
+// conditionally include the package based on template variables. Verify is
+// run after a successful custom command to confirm the install. Phase
+// controls the order InstallAll installs packages in (lower phases first), and
+// After orders packages within a phase without making them depend on each
+// other. Prefer is tried before the other available managers; see
+// Manager.GetInstallMethod.
 type Package struct {
 	Name        string                          `yaml:"name"`
 	Description string                          `yaml:"description,omitempty"`
 	Managers    map[PackageManager]ManagerValue `yaml:"managers,omitempty"`
 	Custom      map[string]string               `yaml:"custom,omitempty"` // OS -> command
+	Verify      map[string]string               `yaml:"verify,omitempty"` // OS -> command checking the custom install
 	URL         map[string]URLInstall           `yaml:"url,omitempty"`    // OS -> URL install
-	When        string                          `yaml:"when,omitempty"`
+	When        string                          `yaml:"when,omitempty"`   // the package's own when; see FilterPackages
+	Phase       int                             `yaml:"phase,omitempty"`
+	After       []string                        `yaml:"after,omitempty"` // package names installed first; see GroupByPhase
+	Prefer      PackageManager                  `yaml:"prefer,omitempty"`
 }
 
 // UnmarshalYAML implements custom YAML unmarshaling for Package.
@@ -97,8 +136,12 @@ func (p *Package) UnmarshalYAML(node *yaml.Node) error {
 		Description string                `yaml:"description,omitempty"`
 		Managers    map[string]yaml.Node  `yaml:"managers,omitempty"`
 		Custom      map[string]string     `yaml:"custom,omitempty"`
+		Verify      map[string]string     `yaml:"verify,omitempty"`
 		URL         map[string]URLInstall `yaml:"url,omitempty"`
 		When        string                `yaml:"when,omitempty"`
+		Phase       int                   `yaml:"phase,omitempty"`
+		After       []string              `yaml:"after,omitempty"`
+		Prefer      PackageManager        `yaml:"prefer,omitempty"`
 	}
 
 	var alias packageAlias
@@ -110,8 +153,25 @@ func (p *Package) UnmarshalYAML(node *yaml.Node) error {
 	p.Name = alias.Name
 	p.Description = alias.Description
 	p.Custom = alias.Custom
+	p.Verify = alias.Verify
 	p.URL = alias.URL
 	p.When = alias.When
+	p.Phase = alias.Phase
+	p.After = alias.After
+	p.Prefer = alias.Prefer
+
+	if p.Prefer == Portage {
+		p.Prefer = Emerge
+	}
+
+	if node, ok := alias.Managers[string(Portage)]; ok {
+		if _, ok := alias.Managers[string(Emerge)]; ok {
+			return fmt.Errorf("managers: %s is an alias for %s, set only one", Portage, Emerge)
+		}
+
+		alias.Managers[string(Emerge)] = node
+		delete(alias.Managers, string(Portage))
+	}
 
 	// Process managers map
 	p.Managers = make(map[PackageManager]ManagerValue)
@@ -141,10 +201,17 @@ func (p *Package) UnmarshalYAML(node *yaml.Node) error {
 				continue
 			}
 
-			// Try object with name/deps
+			// Try object with name/deps (and use, for emerge, repo, for apt,
+			// app, for mas, bucket, for scoop, or source, for winget)
 			type nativeManagerObj struct {
-				Name string   `yaml:"name"`
-				Deps []string `yaml:"deps"`
+				Name      string   `yaml:"name"`
+				Use       string   `yaml:"use"`
+				Repo      string   `yaml:"repo"`
+				App       string   `yaml:"app"`
+				Bucket    string   `yaml:"bucket"`
+				Source    string   `yaml:"source"`
+				SourceURL string   `yaml:"source_url"`
+				Deps      []string `yaml:"deps"`
 			}
 
 			var obj nativeManagerObj
@@ -152,7 +219,52 @@ func (p *Package) UnmarshalYAML(node *yaml.Node) error {
 				return fmt.Errorf("failed to decode manager %s: expected string or object with name/deps: %w", key, err)
 			}
 
-			p.Managers[pm] = ManagerValue{PackageName: obj.Name, Deps: obj.Deps}
+			mv := ManagerValue{PackageName: obj.Name, Deps: obj.Deps}
+			if obj.Use != "" {
+				if pm != Emerge {
+					return fmt.Errorf("manager %s: use is only supported on emerge", key)
+				}
+
+				mv.Emerge = &EmergeOptions{UseFlagsOverride: obj.Use}
+			}
+
+			if obj.Repo != "" {
+				if pm != Apt {
+					return fmt.Errorf("manager %s: repo is only supported on apt", key)
+				}
+
+				mv.Apt = &AptOptions{Repo: obj.Repo}
+			}
+
+			if obj.App != "" {
+				if pm != Mas {
+					return fmt.Errorf("manager %s: app is only supported on mas", key)
+				}
+
+				mv.Mas = &MasOptions{AppName: obj.App}
+			}
+
+			if obj.Bucket != "" {
+				if pm != Scoop {
+					return fmt.Errorf("manager %s: bucket is only supported on scoop", key)
+				}
+
+				mv.Scoop = &ScoopOptions{Bucket: obj.Bucket}
+			}
+
+			if obj.Source != "" || obj.SourceURL != "" {
+				if pm != Winget {
+					return fmt.Errorf("manager %s: source is only supported on winget", key)
+				}
+
+				if obj.Source == "" {
+					return fmt.Errorf("manager %s: source_url needs a source name", key)
+				}
+
+				mv.Winget = &WingetOptions{Source: obj.Source, SourceURL: obj.SourceURL}
+			}
+
+			p.Managers[pm] = mv
 		}
 	}
 
@@ -175,11 +287,37 @@ type Config struct {
 
 // InstallResult represents the result of a package installation attempt.
 // It contains the package name, whether the installation succeeded, a message
-// describing the outcome, and the method used (e.g., "pacman", "custom", "url").
+// describing the outcome, the method used (e.g., "pacman", "custom", "url"),
+// the package's install phase and how many times the install was tried (see
+// Manager.InstallRetries). Skipped is set, along with Success, when the
+// package was deliberately not installed (see Manager.NoSudo and
+// Manager.Offline). Unverified is
+// set, with Success false, when the install command succeeded but the verify
+// command declared for it failed.
 // This is returned by Install and InstallAll methods to report installation status.
 type InstallResult struct {
-	Package string
-	Message string
-	Method  string
-	Success bool
+	Package    string
+	Message    string
+	Method     string
+	Phase      int
+	Attempts   int
+	Success    bool
+	Skipped    bool
+	Unverified bool
 }
+
+// MsgRequiresSudo is the InstallResult message of a package skipped because
+// it needs sudo and Manager.NoSudo is set.
+const MsgRequiresSudo = "Skipped: requires sudo"
+
+// MsgOffline is the InstallResult message of a package or repository skipped
+// because Manager.Offline is set.
+const MsgOffline = "Skipped: offline mode"
+
+// MsgVerifyFailed starts the InstallResult message of a package whose install
+// command succeeded but whose verify command failed.
+const MsgVerifyFailed = "Installed but verification failed"
+
+// MsgCanceled starts the InstallResult message of a package whose install was
+// canceled through its context.
+const MsgCanceled = "Canceled"
@@ -0,0 +1,282 @@
+package packages
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync"
+
+	"github.com/AntoineGS/tidydots/internal/cmdexec"
+)
+
+// Update states reported by CheckUpdates.
+const (
+	// UpdateCurrent means the installed version is the available one
+	UpdateCurrent = "up to date"
+	// UpdateOutdated means the manager offers another version
+	UpdateOutdated = "outdated"
+	// UpdateUnknown means the installed or available version is unknown
+	UpdateUnknown = "unknown"
+)
+
+// UpdateResult is the installed version of one package, as reported by
+// Versions, compared with the version its manager offers.
+type UpdateResult struct {
+	VersionResult
+	Available string // empty when the manager cannot tell
+	Status    string // UpdateCurrent, UpdateOutdated or UpdateUnknown
+}
+
+// availableQueries holds the managers that can report the version they
+// offer of a package, in the format their versionQueries report.
+var availableQueries = map[PackageManager]versionQuery{
+	Pacman:   syncInfoVersion(Pacman),
+	Yay:      syncInfoVersion(Yay),
+	Paru:     syncInfoVersion(Paru),
+	Apt:      aptCandidate,
+	Dnf:      dnfAvailableVersion,
+	Brew:     brewStableVersion,
+	BrewCask: brewCaskStableVersion,
+	Winget:   wingetAvailableVersion,
+	Choco:    chocoAvailableVersion,
+}
+
+// availableCache holds the available versions queried in this process,
+// keyed by manager and package name, so that repeated checks query each
+// package once. Failed queries are not cached.
+var availableCache sync.Map // map[string]string
+
+// ResetAvailableCache clears the available versions cached by
+// GetAvailableVersion, causing the next call to query again.
+func ResetAvailableCache() {
+	availableCache = sync.Map{}
+}
+
+// GetAvailableVersion asks mgr which version of pkg it would install, within
+// versionQueryTimeout. It returns "" when the output names no version, and
+// fails with ErrNoVersionQuery when mgr cannot be asked about pkg. Versions
+// are cached for the process; see ResetAvailableCache.
+func (m *Manager) GetAvailableVersion(pkg Package, mgr PackageManager) (string, error) {
+	key := string(mgr) + "\x00" + pkg.Managers[mgr].PackageName
+	if version, ok := availableCache.Load(key); ok {
+		return version.(string), nil //nolint:errcheck // only strings are stored
+	}
+
+	version, err := m.queryVersion(pkg, mgr, availableQueries)
+	if err != nil {
+		return "", err
+	}
+
+	availableCache.Store(key, version)
+
+	return version, nil
+}
+
+// CheckUpdates runs Versions on pkgs and, for each installed package whose
+// version is known, asks the manager that reported it which version it
+// offers. A package is UpdateOutdated when the two differ. The queries run
+// concurrently, like those of Versions; one that fails leaves the package
+// UpdateUnknown. Only installed packages are returned, in the order of pkgs.
+func (m *Manager) CheckUpdates(pkgs []Package) []UpdateResult {
+	versions := m.Versions(pkgs)
+	results := make([]UpdateResult, len(versions))
+	jobs := make(chan struct{}, versionQueryJobs)
+
+	var wg sync.WaitGroup
+
+	for i, v := range versions {
+		results[i] = UpdateResult{VersionResult: v, Status: UpdateUnknown}
+
+		if v.State != CheckInstalled || v.Version == "" {
+			continue
+		}
+
+		wg.Go(func() {
+			jobs <- struct{}{}
+			defer func() { <-jobs }()
+
+			available, err := m.GetAvailableVersion(pkgs[i], v.Manager)
+			if err != nil {
+				slog.Debug("available version query failed",
+					slog.String("package", v.Package),
+					slog.String("manager", string(v.Manager)),
+					slog.String("error", err.Error()))
+
+				return
+			}
+
+			results[i].Available = available
+			results[i].Status = updateStatus(v.Version, available)
+		})
+	}
+
+	wg.Wait()
+
+	installed := results[:0]
+
+	for _, r := range results {
+		if r.State == CheckInstalled {
+			installed = append(installed, r)
+		}
+	}
+
+	return installed
+}
+
+// updateStatus compares an installed version with the available one.
+func updateStatus(installed, available string) string {
+	switch {
+	case installed == "" || available == "":
+		return UpdateUnknown
+	case installed == available:
+		return UpdateCurrent
+	default:
+		return UpdateOutdated
+	}
+}
+
+// syncInfoVersion returns the query running "bin -Si name", which pacman and
+// the AUR helpers print in the same format.
+func syncInfoVersion(bin PackageManager) versionQuery {
+	return func(ctx context.Context, r cmdexec.Runner, name string) (string, error) {
+		res, err := r.Run(ctx, string(bin), "-Si", name)
+		if err != nil {
+			return "", err
+		}
+
+		return parseSyncInfoVersion(string(res.Stdout)), nil
+	}
+}
+
+// parseSyncInfoVersion returns the Version field of "pacman -Si" output, or
+// of its first package when several repositories have it.
+func parseSyncInfoVersion(output string) string {
+	for line := range strings.Lines(output) {
+		key, value, ok := strings.Cut(line, ":")
+		if ok && strings.TrimSpace(key) == "Version" {
+			return strings.TrimSpace(value)
+		}
+	}
+
+	return ""
+}
+
+// aptCandidate runs "apt-cache policy name".
+func aptCandidate(ctx context.Context, r cmdexec.Runner, name string) (string, error) {
+	res, err := r.Run(ctx, "apt-cache", "policy", name)
+	if err != nil {
+		return "", err
+	}
+
+	return parseAptPolicyField(string(res.Stdout), "Candidate:"), nil
+}
+
+// dnfAvailableVersion runs "dnf repoquery" for the newest version-release
+// the repositories offer.
+func dnfAvailableVersion(ctx context.Context, r cmdexec.Runner, name string) (string, error) {
+	res, err := r.Run(ctx, string(Dnf), "repoquery", "--quiet", "--latest-limit=1",
+		"--queryformat", "%{VERSION}-%{RELEASE}\n", name)
+	if err != nil {
+		return "", err
+	}
+
+	// Like rpm, repoquery prints a line per architecture.
+	line, _, _ := strings.Cut(strings.TrimSpace(string(res.Stdout)), "\n")
+
+	return strings.TrimSpace(line), nil
+}
+
+// brewInfo is the part of "brew info --json=v2" output the queries read.
+type brewInfo struct {
+	Formulae []struct {
+		Versions struct {
+			Stable string `json:"stable"`
+		} `json:"versions"`
+		Revision int `json:"revision"`
+	} `json:"formulae"`
+	Casks []struct {
+		Version string `json:"version"`
+	} `json:"casks"`
+}
+
+// brewStableVersion runs "brew info --json=v2 name" for the stable version of
+// a formula, with its revision suffix as "brew list --versions" prints it.
+func brewStableVersion(ctx context.Context, r cmdexec.Runner, name string) (string, error) {
+	info, err := runBrewInfo(ctx, r, "--formula", name)
+	if err != nil || len(info.Formulae) == 0 {
+		return "", err
+	}
+
+	formula := info.Formulae[0]
+	if formula.Revision > 0 && formula.Versions.Stable != "" {
+		return fmt.Sprintf("%s_%d", formula.Versions.Stable, formula.Revision), nil
+	}
+
+	return formula.Versions.Stable, nil
+}
+
+// brewCaskStableVersion runs "brew info --json=v2 --cask name".
+func brewCaskStableVersion(ctx context.Context, r cmdexec.Runner, name string) (string, error) {
+	info, err := runBrewInfo(ctx, r, flagCask, name)
+	if err != nil || len(info.Casks) == 0 {
+		return "", err
+	}
+
+	return info.Casks[0].Version, nil
+}
+
+// runBrewInfo runs "brew info --json=v2" with kind, --formula or --cask.
+func runBrewInfo(ctx context.Context, r cmdexec.Runner, kind, name string) (brewInfo, error) {
+	var info brewInfo
+
+	res, err := r.Run(ctx, string(Brew), "info", "--json=v2", kind, name)
+	if err != nil {
+		return info, err
+	}
+
+	if err := json.Unmarshal(res.Stdout, &info); err != nil {
+		return info, fmt.Errorf("parsing brew info: %w", err)
+	}
+
+	return info, nil
+}
+
+// wingetAvailableVersion runs "winget list --id name --exact", whose
+// Available column names the upgrade winget offers.
+func wingetAvailableVersion(ctx context.Context, r cmdexec.Runner, name string) (string, error) {
+	wingetQueries.Lock()
+	defer wingetQueries.Unlock()
+
+	res, err := r.Run(ctx, string(Winget), "list", "--id", name, "--exact",
+		"--disable-interactivity", "--accept-source-agreements")
+	if err != nil {
+		return "", err
+	}
+
+	return parseWingetAvailable(string(res.Stdout), name), nil
+}
+
+// parseWingetAvailable returns the Available column of the row of "winget
+// list" output whose Id is id. winget leaves it empty, or leaves the column
+// out, when no upgrade is available, so the installed version is returned
+// then.
+func parseWingetAvailable(output, id string) string {
+	if available, _ := wingetListColumn(output, id, "Available"); available != "" {
+		return available
+	}
+
+	return parseWingetVersion(output, id)
+}
+
+// chocoAvailableVersion runs "choco search --exact --limit-output name",
+// which lists the version the configured sources offer.
+func chocoAvailableVersion(ctx context.Context, r cmdexec.Runner, name string) (string, error) {
+	res, err := r.Run(ctx, string(Choco), "search", "--exact", "--limit-output", name)
+	if err != nil {
+		return "", err
+	}
+
+	return parseChocoVersion(string(res.Stdout), name), nil
+}
@@ -0,0 +1,137 @@
+package packages
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/AntoineGS/tidydots/internal/cmdexec"
+)
+
+func TestParseSyncInfoVersion(t *testing.T) {
+	t.Parallel()
+
+	output := `Repository      : extra
+Name            : neovim
+Version         : 0.10.3-1
+Description     : Fork of Vim aiming to improve user experience, plugins, and GUIs
+`
+
+	if got := parseSyncInfoVersion(output); got != "0.10.3-1" {
+		t.Errorf("parseSyncInfoVersion() = %q, want 0.10.3-1", got)
+	}
+
+	if got := parseSyncInfoVersion(""); got != "" {
+		t.Errorf("parseSyncInfoVersion(empty) = %q, want empty", got)
+	}
+}
+
+func TestParseAptPolicyField(t *testing.T) {
+	t.Parallel()
+
+	output := `ripgrep:
+  Installed: 13.0.0-4
+  Candidate: 14.1.0-1
+  Version table:
+`
+
+	if got := parseAptPolicyField(output, "Candidate:"); got != "14.1.0-1" {
+		t.Errorf("parseAptPolicyField(Candidate) = %q, want 14.1.0-1", got)
+	}
+
+	if got := parseAptPolicyField("  Candidate: (none)\n", "Candidate:"); got != "" {
+		t.Errorf("parseAptPolicyField(none) = %q, want empty", got)
+	}
+}
+
+func TestParseWingetAvailable(t *testing.T) {
+	t.Parallel()
+
+	output := `Name                     Id                      Version     Available Source
+---------------------------------------------------------------------------
+Git                      Git.Git                 2.53.0      2.54.0    winget
+Microsoft Visual C++ …   Microsoft.VCRedist.x64  14.38.33135           winget
+`
+
+	tests := map[string]string{
+		"Git.Git":                "2.54.0",
+		"Microsoft.VCRedist.x64": "14.38.33135",
+		"Starship.Starship":      "",
+	}
+
+	for id, want := range tests {
+		if got := parseWingetAvailable(output, id); got != want {
+			t.Errorf("parseWingetAvailable(%q) = %q, want %q", id, got, want)
+		}
+	}
+
+	upToDate := `Name   Id        Version  Source
+--------------------------------
+Git    Git.Git   2.54.0   winget
+`
+
+	if got := parseWingetAvailable(upToDate, "Git.Git"); got != "2.54.0" {
+		t.Errorf("parseWingetAvailable(no Available column) = %q, want 2.54.0", got)
+	}
+}
+
+func TestBrewStableVersion(t *testing.T) {
+	t.Parallel()
+
+	stub := cmdexec.NewStubRunner()
+	stub.AddResult("brew", cmdexec.Result{Stdout: []byte(`{"formulae":[{"versions":{"stable":"14.1.1"},"revision":1}],"casks":[]}`)})
+	stub.AddResult("brew", cmdexec.Result{Stdout: []byte(`{"formulae":[],"casks":[{"version":"4.37.0"}]}`)})
+
+	if got, err := brewStableVersion(t.Context(), stub, "ripgrep"); err != nil || got != "14.1.1_1" {
+		t.Errorf("brewStableVersion() = %q, %v, want 14.1.1_1", got, err)
+	}
+
+	if got, err := brewCaskStableVersion(t.Context(), stub, "docker"); err != nil || got != "4.37.0" {
+		t.Errorf("brewCaskStableVersion() = %q, %v, want 4.37.0", got, err)
+	}
+}
+
+func TestCheckUpdates(t *testing.T) {
+	ResetAvailableCache()
+	t.Cleanup(ResetAvailableCache)
+
+	mgr, stub := newStubManager(t, "linux")
+	setAvailable(mgr, Pacman, Brew)
+
+	stub.AddResult("pacman", cmdexec.Result{})                                                      // the check of neovim
+	stub.AddResult("pacman", cmdexec.Result{Stdout: []byte("neovim 0.10.2-1\n")})                   // its version
+	stub.AddResult("pacman", cmdexec.Result{Stdout: []byte("Name : neovim\nVersion : 0.10.3-1\n")}) // its available version
+	stub.AddError("brew", errors.New("exit status 1"))                                              // ripgrep is missing
+
+	pkgs := []Package{
+		{Name: "neovim", Managers: map[PackageManager]ManagerValue{Pacman: {PackageName: "neovim"}}},
+		{Name: "ripgrep", Managers: map[PackageManager]ManagerValue{Brew: {PackageName: "ripgrep"}}},
+	}
+
+	results := mgr.CheckUpdates(pkgs)
+	if len(results) != 1 {
+		t.Fatalf("CheckUpdates() = %+v, want only the installed neovim", results)
+	}
+
+	if r := results[0]; r.Version != "0.10.2-1" || r.Available != "0.10.3-1" || r.Status != UpdateOutdated {
+		t.Errorf("CheckUpdates()[0] = %+v, want 0.10.2-1 outdated by 0.10.3-1", r)
+	}
+
+	// The available version is cached for the process.
+	stub.AddResult("pacman", cmdexec.Result{})
+	stub.AddResult("pacman", cmdexec.Result{Stdout: []byte("neovim 0.10.3-1\n")})
+
+	if r := mgr.CheckUpdates(pkgs[:1]); len(r) != 1 || r[0].Status != UpdateCurrent {
+		t.Errorf("CheckUpdates() after upgrading = %+v, want neovim up to date", r)
+	}
+}
+
+func TestGetAvailableVersion_NoQuery(t *testing.T) {
+	t.Parallel()
+
+	mgr, _ := newStubManager(t, "linux")
+	pkg := Package{Name: "tool", Managers: map[PackageManager]ManagerValue{Pacman: {PackageName: "tool"}}}
+
+	if _, err := mgr.GetAvailableVersion(pkg, Apt); !errors.Is(err, ErrNoVersionQuery) {
+		t.Errorf("GetAvailableVersion() for a manager the package lacks error = %v, want ErrNoVersionQuery", err)
+	}
+}
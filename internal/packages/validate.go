@@ -53,3 +53,54 @@ func ValidateGitBranch(branch string) error {
 	}
 	return nil
 }
+
+// ValidateSparsePattern rejects sparse-checkout patterns that git would read
+// as a flag, and empty or multi-line ones.
+func ValidateSparsePattern(pattern string) error {
+	if strings.TrimSpace(pattern) == "" {
+		return fmt.Errorf("sparse pattern must not be empty")
+	}
+	if strings.HasPrefix(pattern, "-") {
+		return fmt.Errorf("sparse pattern %q must not start with '-'", pattern)
+	}
+	for _, r := range pattern {
+		if r == 0 || r == '\n' || r == '\r' {
+			return fmt.Errorf("sparse pattern %q contains control characters", pattern)
+		}
+	}
+	return nil
+}
+
+// ValidateAptRepo rejects apt repositories that add-apt-repository would read
+// as a flag or that could smuggle extra lines into a sources file. Spaces are
+// allowed: a repository may be a full "deb [options] uri suite components" line.
+func ValidateAptRepo(repo string) error {
+	if strings.TrimSpace(repo) == "" {
+		return fmt.Errorf("apt repo must not be empty")
+	}
+	if strings.HasPrefix(repo, "-") {
+		return fmt.Errorf("apt repo %q must not start with '-' (possible flag injection)", repo)
+	}
+	for _, r := range repo {
+		if r == 0 || r == '\n' || r == '\r' {
+			return fmt.Errorf("apt repo %q contains control characters", repo)
+		}
+	}
+	return nil
+}
+
+// validSourceName matches scoop bucket and winget source names, e.g. "extras"
+// or "msstore".
+var validSourceName = regexp.MustCompile(`^[a-zA-Z0-9][a-zA-Z0-9._\-]*$`)
+
+// ValidateSourceName checks that a scoop bucket or winget source name is safe
+// for use as a CLI argument and inside a quoted PowerShell string.
+func ValidateSourceName(name string) error {
+	if name == "" {
+		return fmt.Errorf("source name must not be empty")
+	}
+	if !validSourceName.MatchString(name) {
+		return fmt.Errorf("source name %q contains invalid characters", name)
+	}
+	return nil
+}
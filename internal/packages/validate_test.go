@@ -175,3 +175,56 @@ func TestValidateGitBranch(t *testing.T) {
 		})
 	}
 }
+
+func TestValidateAptRepo(t *testing.T) {
+	cases := []struct {
+		name    string
+		repo    string
+		wantErr bool
+	}{
+		{"ppa", "ppa:neovim-ppa/stable", false},
+		{"deb line", "deb [arch=amd64] https://example.com/apt stable main", false},
+		{"empty rejected", "", true},
+		{"leading dash rejected", "--remove", true},
+		{"newline rejected", "ppa:a/b\ndeb http://evil stable main", true},
+		{"null byte rejected", "ppa:a/b\x00", true},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := ValidateAptRepo(tc.repo)
+			if tc.wantErr && err == nil {
+				t.Errorf("ValidateAptRepo(%q) = nil, want error", tc.repo)
+			}
+			if !tc.wantErr && err != nil {
+				t.Errorf("ValidateAptRepo(%q) = %v, want nil", tc.repo, err)
+			}
+		})
+	}
+}
+
+func TestValidateSourceName(t *testing.T) {
+	cases := []struct {
+		name    string
+		source  string
+		wantErr bool
+	}{
+		{"scoop bucket", "extras", false},
+		{"winget source", "msstore", false},
+		{"dots and dashes", "my-bucket.v2", false},
+		{"empty rejected", "", true},
+		{"leading dash rejected", "--force", true},
+		{"quote rejected", "extras'; rm", true},
+		{"space rejected", "my bucket", true},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := ValidateSourceName(tc.source)
+			if tc.wantErr && err == nil {
+				t.Errorf("ValidateSourceName(%q) = nil, want error", tc.source)
+			}
+			if !tc.wantErr && err != nil {
+				t.Errorf("ValidateSourceName(%q) = %v, want nil", tc.source, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,95 @@
+package packages
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// hasVerification reports whether a URL install declares a checksum or size
+// that the downloaded file must match.
+func hasVerification(spec URLInstall) bool {
+	return spec.SHA256 != "" || spec.Size > 0
+}
+
+// verifyDownload checks the file at path against the sha256 and size declared
+// on spec. Fields that are not set are not checked. A mismatch reports both the
+// expected and the actual value so a stale pin is easy to tell from tampering.
+func verifyDownload(path string, spec URLInstall) error {
+	if !hasVerification(spec) {
+		return nil
+	}
+
+	f, err := os.Open(path) //nolint:gosec // path is our own private temp file
+	if err != nil {
+		return fmt.Errorf("opening download for verification: %w", err)
+	}
+	defer func() { _ = f.Close() }()
+
+	h := sha256.New()
+
+	size, err := io.Copy(h, f)
+	if err != nil {
+		return fmt.Errorf("reading download for verification: %w", err)
+	}
+
+	if spec.Size > 0 && size != spec.Size {
+		return fmt.Errorf("size mismatch for %s: expected %d bytes, got %d", spec.URL, spec.Size, size)
+	}
+
+	if spec.SHA256 != "" {
+		actual := hex.EncodeToString(h.Sum(nil))
+		if !strings.EqualFold(actual, spec.SHA256) {
+			return fmt.Errorf("sha256 mismatch for %s: expected %s, got %s", spec.URL, strings.ToLower(spec.SHA256), actual)
+		}
+	}
+
+	return nil
+}
+
+// shellVerifyScript returns a POSIX shell snippet that verifies $tmpfile
+// against spec, exiting non-zero with expected vs actual on mismatch. It is
+// used by BuildCommand, where the download happens inside the shell script.
+// Returns "" when spec declares nothing to verify.
+func shellVerifyScript(spec URLInstall) string {
+	var b strings.Builder
+
+	if spec.Size > 0 {
+		fmt.Fprintf(&b, `actual_size=$(wc -c < "$tmpfile" | tr -d ' ')
+			[ "$actual_size" = '%d' ] || { echo "size mismatch: expected %d bytes, got $actual_size" >&2; exit 1; }
+			`, spec.Size, spec.Size)
+	}
+
+	if spec.SHA256 != "" {
+		expected := escapeShellSingleQuote(strings.ToLower(spec.SHA256))
+		fmt.Fprintf(&b, `if command -v sha256sum >/dev/null 2>&1; then actual_sha=$(sha256sum "$tmpfile" | cut -d' ' -f1); else actual_sha=$(shasum -a 256 "$tmpfile" | cut -d' ' -f1); fi
+			[ "$actual_sha" = '%s' ] || { echo "sha256 mismatch: expected %s, got $actual_sha" >&2; exit 1; }
+			`, expected, expected)
+	}
+
+	return b.String()
+}
+
+// powerShellVerifyScript is the PowerShell counterpart of shellVerifyScript,
+// verifying $tmpFile. Returns "" when spec declares nothing to verify.
+func powerShellVerifyScript(spec URLInstall) string {
+	var b strings.Builder
+
+	if spec.Size > 0 {
+		fmt.Fprintf(&b, `$actualSize = (Get-Item $tmpFile).Length
+				if ($actualSize -ne %d) { Remove-Item $tmpFile -ErrorAction SilentlyContinue; throw "size mismatch: expected %d bytes, got $actualSize" }
+				`, spec.Size, spec.Size)
+	}
+
+	if spec.SHA256 != "" {
+		expected := escapePowerShellSingleQuote(strings.ToLower(spec.SHA256))
+		fmt.Fprintf(&b, `$actualSha = (Get-FileHash -Algorithm SHA256 $tmpFile).Hash.ToLower()
+				if ($actualSha -ne '%s') { Remove-Item $tmpFile -ErrorAction SilentlyContinue; throw "sha256 mismatch: expected %s, got $actualSha" }
+				`, expected, expected)
+	}
+
+	return b.String()
+}
@@ -0,0 +1,345 @@
+package packages
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/AntoineGS/tidydots/internal/cmdexec"
+)
+
+// versionQueryTimeout bounds each version query of Versions.
+const versionQueryTimeout = 10 * time.Second
+
+// versionQueryJobs is how many version queries Versions runs at once.
+const versionQueryJobs = 8
+
+// VersionResult is the installed state of one package, as reported by Check,
+// with the version its manager reports.
+type VersionResult struct {
+	CheckResult
+	Version string         // empty when not installed, or the manager cannot tell
+	Manager PackageManager // the manager that reported Version
+}
+
+// ErrNoVersionQuery is returned when a package manager cannot be asked for
+// the version of a package: it has no version query, or the package is not
+// installed through it by name.
+var ErrNoVersionQuery = errors.New("no version query")
+
+// versionQuery asks a package manager which version of the package name is
+// installed. It returns "" when the output names no version.
+type versionQuery func(ctx context.Context, r cmdexec.Runner, name string) (string, error)
+
+// versionQueries holds the managers that can report installed versions.
+var versionQueries = map[PackageManager]versionQuery{
+	Pacman:   pacmanVersion,
+	Yay:      pacmanVersion,
+	Paru:     pacmanVersion,
+	Apt:      aptVersion,
+	Dnf:      rpmVersion,
+	Brew:     brewVersion,
+	BrewCask: brewCaskVersion,
+	Winget:   wingetVersion,
+	Choco:    chocoVersion,
+}
+
+// Versions runs Check on each package and, for the installed ones, asks the
+// first available manager that lists the package which version it has. The
+// packages are queried concurrently, each query within versionQueryTimeout;
+// one that fails or times out leaves the version empty. Results are in the
+// order of pkgs.
+func (m *Manager) Versions(pkgs []Package) []VersionResult {
+	m.ensureManagers()
+
+	results := make([]VersionResult, len(pkgs))
+	jobs := make(chan struct{}, versionQueryJobs)
+
+	var wg sync.WaitGroup
+
+	for i, pkg := range pkgs {
+		wg.Go(func() {
+			jobs <- struct{}{}
+			defer func() { <-jobs }()
+
+			results[i] = m.version(pkg)
+		})
+	}
+
+	wg.Wait()
+
+	return results
+}
+
+// version returns the VersionResult of pkg; see Versions.
+func (m *Manager) version(pkg Package) VersionResult {
+	result := VersionResult{CheckResult: m.Check(pkg)}
+	if result.State != CheckInstalled {
+		return result
+	}
+
+	for _, mgr := range m.managerOrder(pkg) {
+		if _, ok := versionQueries[mgr]; !ok {
+			continue
+		}
+
+		if _, ok := pkg.Managers[mgr]; !ok {
+			continue
+		}
+
+		version, err := m.GetInstalledVersion(pkg, mgr)
+		if err != nil {
+			slog.Debug("version query failed",
+				slog.String("package", pkg.Name),
+				slog.String("manager", string(mgr)),
+				slog.String("error", err.Error()))
+
+			continue
+		}
+
+		if version != "" {
+			result.Version, result.Manager = version, mgr
+			break
+		}
+	}
+
+	return result
+}
+
+// GetInstalledVersion asks mgr which version of pkg is installed, within
+// versionQueryTimeout. It returns "" when the output names no version, and
+// fails with ErrNoVersionQuery when mgr cannot be asked about pkg.
+func (m *Manager) GetInstalledVersion(pkg Package, mgr PackageManager) (string, error) {
+	return m.queryVersion(pkg, mgr, versionQueries)
+}
+
+// queryVersion runs the query of queries for mgr on pkg's name with mgr,
+// within versionQueryTimeout.
+func (m *Manager) queryVersion(pkg Package, mgr PackageManager, queries map[PackageManager]versionQuery) (string, error) {
+	val, ok := pkg.Managers[mgr]
+	query, canQuery := queries[mgr]
+
+	if !ok || !canQuery || val.IsGit() || val.IsInstaller() {
+		return "", fmt.Errorf("%w: %s for %s", ErrNoVersionQuery, mgr, pkg.Name)
+	}
+
+	ctx, cancel := context.WithTimeout(m.ctx, versionQueryTimeout)
+	defer cancel()
+
+	return query(ctx, m.runner, val.PackageName)
+}
+
+// pacmanVersion runs "pacman -Q name", which yay and paru packages share.
+func pacmanVersion(ctx context.Context, r cmdexec.Runner, name string) (string, error) {
+	res, err := r.Run(ctx, string(Pacman), "-Q", name)
+	if err != nil {
+		return "", err
+	}
+
+	return parsePacmanVersion(string(res.Stdout)), nil
+}
+
+// parsePacmanVersion returns the version of "pacman -Q" output, "name version".
+func parsePacmanVersion(output string) string {
+	fields := strings.Fields(output)
+	if len(fields) < 2 {
+		return ""
+	}
+
+	return fields[1]
+}
+
+// aptVersion runs "apt-cache policy name".
+func aptVersion(ctx context.Context, r cmdexec.Runner, name string) (string, error) {
+	res, err := r.Run(ctx, "apt-cache", "policy", name)
+	if err != nil {
+		return "", err
+	}
+
+	return parseAptPolicyVersion(string(res.Stdout)), nil
+}
+
+// parseAptPolicyVersion returns the Installed line of "apt-cache policy"
+// output, or "" when it is "(none)".
+func parseAptPolicyVersion(output string) string {
+	return parseAptPolicyField(output, "Installed:")
+}
+
+// parseAptPolicyField returns the version of the line of "apt-cache policy"
+// output starting with field, or "" when it is "(none)".
+func parseAptPolicyField(output, field string) string {
+	for line := range strings.Lines(output) {
+		version, ok := strings.CutPrefix(strings.TrimSpace(line), field)
+		if !ok {
+			continue
+		}
+
+		if version = strings.TrimSpace(version); version == "(none)" {
+			return ""
+		}
+
+		return version
+	}
+
+	return ""
+}
+
+// rpmVersion runs "rpm -q" with a format printing only version-release.
+func rpmVersion(ctx context.Context, r cmdexec.Runner, name string) (string, error) {
+	res, err := r.Run(ctx, "rpm", "-q", "--queryformat", "%{VERSION}-%{RELEASE}\n", name)
+	if err != nil {
+		return "", err
+	}
+
+	// rpm prints a line per installed architecture; they share a version.
+	line, _, _ := strings.Cut(strings.TrimSpace(string(res.Stdout)), "\n")
+
+	return strings.TrimSpace(line), nil
+}
+
+// brewVersion runs "brew list --versions name".
+func brewVersion(ctx context.Context, r cmdexec.Runner, name string) (string, error) {
+	res, err := r.Run(ctx, string(Brew), "list", "--versions", name)
+	if err != nil {
+		return "", err
+	}
+
+	return parseBrewVersion(string(res.Stdout)), nil
+}
+
+// brewCaskVersion runs "brew list --cask --versions name".
+func brewCaskVersion(ctx context.Context, r cmdexec.Runner, name string) (string, error) {
+	res, err := r.Run(ctx, string(Brew), "list", flagCask, "--versions", name)
+	if err != nil {
+		return "", err
+	}
+
+	return parseBrewVersion(string(res.Stdout)), nil
+}
+
+// parseBrewVersion returns the newest version of "brew list --versions"
+// output, "name version...", which lists every installed version, oldest
+// first.
+func parseBrewVersion(output string) string {
+	fields := strings.Fields(output)
+	if len(fields) < 2 {
+		return ""
+	}
+
+	return fields[len(fields)-1]
+}
+
+// wingetQueries serializes winget queries, which fail with 0x8a150001 when
+// run in parallel.
+var wingetQueries sync.Mutex
+
+// wingetVersion runs "winget list --id name --exact".
+func wingetVersion(ctx context.Context, r cmdexec.Runner, name string) (string, error) {
+	wingetQueries.Lock()
+	defer wingetQueries.Unlock()
+
+	res, err := r.Run(ctx, string(Winget), "list", "--id", name, "--exact",
+		"--disable-interactivity", "--accept-source-agreements")
+	if err != nil {
+		return "", err
+	}
+
+	return parseWingetVersion(string(res.Stdout), name), nil
+}
+
+// parseWingetVersion returns the Version column of the row of "winget list"
+// output whose Id is id, ignoring case.
+func parseWingetVersion(output, id string) string {
+	version, _ := wingetListColumn(output, id, "Version")
+	return version
+}
+
+// wingetListColumn returns column of the row of "winget list" output whose Id
+// is id, ignoring case, and whether the output has that column at all.
+func wingetListColumn(output, id, column string) (string, bool) {
+	lines := cleanWingetOutput(output)
+
+	headerIdx := wingetHeaderSeparator(lines)
+	if headerIdx < 1 {
+		return "", false
+	}
+
+	// Columns are aligned by character, and names truncated with "…".
+	header := []rune(lines[headerIdx-1])
+	idStart := runeIndex(header, "Id")
+	start := runeIndex(header, column)
+
+	if idStart < 0 || start <= idStart {
+		return "", false
+	}
+
+	idEnd, end := len(header), len(header)
+
+	for _, next := range []string{"Version", "Available", "Source"} {
+		i := runeIndex(header, next)
+		if i > idStart && i < idEnd {
+			idEnd = i
+		}
+
+		if i > start && i < end {
+			end = i
+		}
+	}
+
+	for _, line := range lines[headerIdx+1:] {
+		row := []rune(line)
+		if len(row) <= idEnd {
+			continue
+		}
+
+		if !strings.EqualFold(strings.TrimSpace(string(row[idStart:idEnd])), id) {
+			continue
+		}
+
+		if len(row) <= start {
+			return "", true
+		}
+
+		return strings.TrimSpace(string(row[start:min(end, len(row))])), true
+	}
+
+	return "", true
+}
+
+// runeIndex returns the index in runes of the first substr in s, or -1.
+func runeIndex(s []rune, substr string) int {
+	i := strings.Index(string(s), substr)
+	if i < 0 {
+		return -1
+	}
+
+	return utf8.RuneCountInString(string(s)[:i])
+}
+
+// chocoVersion runs "choco list --local-only --exact --limit-output name".
+func chocoVersion(ctx context.Context, r cmdexec.Runner, name string) (string, error) {
+	res, err := r.Run(ctx, string(Choco), "list", "--local-only", "--exact", "--limit-output", name)
+	if err != nil {
+		return "", err
+	}
+
+	return parseChocoVersion(string(res.Stdout), name), nil
+}
+
+// parseChocoVersion returns the version of the line of "choco list
+// --limit-output" output, "name|version", naming name, ignoring case.
+func parseChocoVersion(output, name string) string {
+	for line := range strings.Lines(output) {
+		pkg, version, ok := strings.Cut(strings.TrimSpace(line), "|")
+		if ok && strings.EqualFold(pkg, name) {
+			return version
+		}
+	}
+
+	return ""
+}
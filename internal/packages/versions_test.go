@@ -0,0 +1,136 @@
+package packages
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/AntoineGS/tidydots/internal/cmdexec"
+)
+
+func TestParsePacmanVersion(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]string{
+		"neovim 0.10.2-1\n": "0.10.2-1",
+		"":                  "",
+	}
+
+	for output, want := range tests {
+		if got := parsePacmanVersion(output); got != want {
+			t.Errorf("parsePacmanVersion(%q) = %q, want %q", output, got, want)
+		}
+	}
+}
+
+func TestParseAptPolicyVersion(t *testing.T) {
+	t.Parallel()
+
+	installed := `neovim:
+  Installed: 0.9.5-6ubuntu2
+  Candidate: 0.9.5-6ubuntu2
+  Version table:
+ *** 0.9.5-6ubuntu2 500
+        500 http://archive.ubuntu.com/ubuntu noble/universe amd64 Packages
+        100 /var/lib/dpkg/status
+`
+	if got := parseAptPolicyVersion(installed); got != "0.9.5-6ubuntu2" {
+		t.Errorf("installed = %q, want 0.9.5-6ubuntu2", got)
+	}
+
+	missing := `ripgrep:
+  Installed: (none)
+  Candidate: 14.1.0-1
+`
+	if got := parseAptPolicyVersion(missing); got != "" {
+		t.Errorf("not installed = %q, want empty", got)
+	}
+
+	if got := parseAptPolicyVersion(""); got != "" {
+		t.Errorf("unknown package = %q, want empty", got)
+	}
+}
+
+func TestParseBrewVersion(t *testing.T) {
+	t.Parallel()
+
+	if got := parseBrewVersion("node 20.11.0 21.6.1\n"); got != "21.6.1" {
+		t.Errorf("parseBrewVersion(two versions) = %q, want the newest", got)
+	}
+
+	if got := parseBrewVersion(""); got != "" {
+		t.Errorf("parseBrewVersion(empty) = %q, want empty", got)
+	}
+}
+
+func TestParseWingetVersion(t *testing.T) {
+	t.Parallel()
+
+	output := "\r   - \r   \\ \r" + `Name                     Id                      Version     Available Source
+---------------------------------------------------------------------------
+Git                      Git.Git                 2.53.0      2.54.0    winget
+Microsoft Visual C++ …   Microsoft.VCRedist.x64  14.38.33135           winget
+`
+
+	tests := map[string]string{
+		"git.git":                "2.53.0",
+		"Microsoft.VCRedist.x64": "14.38.33135",
+		"Starship.Starship":      "",
+	}
+
+	for id, want := range tests {
+		if got := parseWingetVersion(output, id); got != want {
+			t.Errorf("parseWingetVersion(%q) = %q, want %q", id, got, want)
+		}
+	}
+
+	if got := parseWingetVersion("No installed package found matching input criteria.\n", "Git.Git"); got != "" {
+		t.Errorf("parseWingetVersion(no match) = %q, want empty", got)
+	}
+}
+
+func TestParseChocoVersion(t *testing.T) {
+	t.Parallel()
+
+	output := "Chocolatey v1.4.0\ngit|2.43.0\ngit.install|2.43.0\n"
+
+	if got := parseChocoVersion(output, "Git"); got != "2.43.0" {
+		t.Errorf("parseChocoVersion(Git) = %q, want 2.43.0", got)
+	}
+
+	if got := parseChocoVersion(output, "nodejs"); got != "" {
+		t.Errorf("parseChocoVersion(nodejs) = %q, want empty", got)
+	}
+}
+
+func TestVersions(t *testing.T) {
+	t.Parallel()
+
+	mgr, stub := newStubManager(t, "linux")
+	setAvailable(mgr, Pacman, Brew)
+
+	stub.AddResult("pacman", cmdexec.Result{})                                    // the check of neovim
+	stub.AddResult("pacman", cmdexec.Result{Stdout: []byte("neovim 0.10.2-1\n")}) // its version
+	stub.AddError("brew", errors.New("exit status 1"))                            // ripgrep is missing
+
+	results := mgr.Versions([]Package{
+		{Name: "neovim", Managers: map[PackageManager]ManagerValue{Pacman: {PackageName: "neovim"}}},
+		{Name: "ripgrep", Managers: map[PackageManager]ManagerValue{Brew: {PackageName: "ripgrep"}}},
+		{Name: "powertoys", Managers: map[PackageManager]ManagerValue{Winget: {PackageName: "Microsoft.PowerToys"}}},
+	})
+
+	want := []VersionResult{
+		{CheckResult: CheckResult{Package: "neovim", State: CheckInstalled, Method: string(Pacman)}, Version: "0.10.2-1", Manager: Pacman},
+		{CheckResult: CheckResult{Package: "ripgrep", State: CheckMissing, Method: string(Brew)}},
+		{CheckResult: CheckResult{Package: "powertoys", State: CheckUnavailable, Method: MethodNone}},
+	}
+
+	if len(results) != len(want) {
+		t.Fatalf("Versions() returned %d results, want %d", len(results), len(want))
+	}
+
+	for i := range want {
+		if results[i] != want[i] {
+			t.Errorf("Versions()[%d] = %+v, want %+v", i, results[i], want[i])
+		}
+	}
+}
@@ -0,0 +1,25 @@
+package platform
+
+import "os"
+
+// JunctionTarget returns the directory the Windows directory junction
+// (mklink /J) at path points to. Since Go 1.23 os.Lstat no longer reports
+// junctions as ModeSymlink, but os.Readlink still resolves them. ok is false
+// for symbolic links, for paths that are not links and on other systems.
+func JunctionTarget(path string) (target string, ok bool) {
+	if hostGOOS != OSWindows {
+		return "", false
+	}
+
+	info, err := os.Lstat(LongPath(path))
+	if err != nil || info.Mode()&os.ModeSymlink != 0 {
+		return "", false
+	}
+
+	target, err = os.Readlink(LongPath(path))
+	if err != nil {
+		return "", false
+	}
+
+	return TrimLongPath(target), true
+}
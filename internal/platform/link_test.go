@@ -0,0 +1,23 @@
+package platform
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestJunctionTarget_NotAJunction(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	link := filepath.Join(dir, "link")
+	if err := os.Symlink(dir, link); err != nil {
+		t.Skipf("symlink creation failed: %v", err)
+	}
+
+	for _, path := range []string{dir, link, filepath.Join(dir, "missing")} {
+		if target, ok := JunctionTarget(path); ok {
+			t.Errorf("JunctionTarget(%q) = %q, true; want false", path, target)
+		}
+	}
+}
@@ -0,0 +1,46 @@
+//go:build windows
+
+package platform
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestJunctionTarget_Windows(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	target := filepath.Join(dir, "target")
+	if err := os.Mkdir(target, 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	junction := filepath.Join(dir, "junction")
+	out, err := exec.CommandContext(context.Background(), "cmd", "/c", "mklink", "/J", junction, target).CombinedOutput() //nolint:gosec // test command
+	if err != nil {
+		t.Fatalf("mklink /J: %v\n%s", err, out)
+	}
+
+	got, ok := JunctionTarget(junction)
+	if !ok || !strings.EqualFold(got, target) {
+		t.Errorf("JunctionTarget(junction) = %q, %v; want %q, true", got, ok, target)
+	}
+
+	if _, ok := JunctionTarget(target); ok {
+		t.Error("JunctionTarget() = true for a plain directory")
+	}
+
+	symlink := filepath.Join(dir, "symlink")
+	if err := os.Symlink(target, symlink); err != nil {
+		t.Skipf("symlink creation failed (dev mode may not be enabled): %v", err)
+	}
+
+	if _, ok := JunctionTarget(symlink); ok {
+		t.Error("JunctionTarget() = true for a symlink")
+	}
+}
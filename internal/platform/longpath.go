@@ -0,0 +1,184 @@
+package platform
+
+import (
+	"io/fs"
+	"os"
+	"strings"
+
+	"github.com/AntoineGS/tidydots/internal/fsys"
+)
+
+// Windows extended-length path prefixes. A path behind them may exceed
+// MAX_PATH (260), but Windows no longer normalizes it: it must be absolute,
+// use backslashes and contain no "." or ".." elements.
+const (
+	longPathPrefix    = `\\?\`
+	longPathUNCPrefix = `\\?\UNC\`
+)
+
+// longPathThreshold is the length from which paths are prefixed. It is below
+// MAX_PATH because CreateDirectory needs room for an 8.3 file name (12
+// characters) on top of the directory path.
+const longPathThreshold = 248
+
+// LongPath returns path in its Windows extended-length form (\\?\C:\... or
+// \\?\UNC\server\share\...) when it is long enough to hit MAX_PATH, so file
+// operations on deep trees do not fail. Short, relative and already prefixed
+// paths, and every path on other systems, are returned unchanged.
+func LongPath(path string) string {
+	if hostGOOS != OSWindows {
+		return path
+	}
+
+	return windowsLongPath(path)
+}
+
+// TrimLongPath removes the extended-length prefix LongPath adds, giving back
+// a path that compares equal to the unprefixed form.
+func TrimLongPath(path string) string {
+	if rest, ok := strings.CutPrefix(path, longPathUNCPrefix); ok {
+		return `\\` + rest
+	}
+
+	return strings.TrimPrefix(path, longPathPrefix)
+}
+
+// windowsLongPath is LongPath with Windows path semantics, whatever the host.
+func windowsLongPath(path string) string {
+	if len(path) < longPathThreshold || strings.HasPrefix(path, longPathPrefix) || strings.HasPrefix(path, `\\.\`) {
+		return path
+	}
+
+	p := strings.ReplaceAll(path, "/", `\`)
+
+	switch {
+	case strings.HasPrefix(p, `\\`):
+		// UNC path: \\server\share is the root.
+		elems := cleanWindowsElems(strings.Split(p[2:], `\`), 2)
+		if len(elems) < 2 {
+			return path
+		}
+
+		return longPathUNCPrefix + strings.Join(elems, `\`)
+	case len(p) >= 3 && isDriveLetter(p[0]) && p[1] == ':' && p[2] == '\\':
+		// Drive-absolute path: C: is the root.
+		return longPathPrefix + strings.Join(cleanWindowsElems(strings.Split(p, `\`), 1), `\`)
+	default:
+		// Relative and drive-relative (C:foo) paths cannot be prefixed.
+		return path
+	}
+}
+
+// cleanWindowsElems drops empty and "." elements and resolves ".." against
+// the elements before it, never past the first rootLen elements.
+func cleanWindowsElems(elems []string, rootLen int) []string {
+	cleaned := make([]string, 0, len(elems))
+
+	for i, e := range elems {
+		switch {
+		case i < rootLen:
+			cleaned = append(cleaned, e)
+		case e == "" || e == ".":
+		case e == "..":
+			if len(cleaned) > rootLen {
+				cleaned = cleaned[:len(cleaned)-1]
+			}
+		default:
+			cleaned = append(cleaned, e)
+		}
+	}
+
+	return cleaned
+}
+
+func isDriveLetter(c byte) bool {
+	return ('a' <= c && c <= 'z') || ('A' <= c && c <= 'Z')
+}
+
+// LongPathFS wraps a filesystem so every path it is given goes through
+// LongPath first. Paths it hands back (WalkDir callbacks, Readlink) have the
+// prefix trimmed, so callers keep comparing and joining plain paths.
+type LongPathFS struct {
+	fsys.FS
+}
+
+// WithLongPaths returns f wrapped in a LongPathFS on Windows, and f itself
+// elsewhere.
+func WithLongPaths(f fsys.FS) fsys.FS {
+	if hostGOOS != OSWindows {
+		return f
+	}
+
+	return LongPathFS{FS: f}
+}
+
+// Stat returns a FileInfo describing the named file.
+func (l LongPathFS) Stat(name string) (fs.FileInfo, error) {
+	return l.FS.Stat(LongPath(name))
+}
+
+// Lstat returns a FileInfo describing the named file, without following symlinks.
+func (l LongPathFS) Lstat(name string) (fs.FileInfo, error) {
+	return l.FS.Lstat(LongPath(name))
+}
+
+// ReadFile reads and returns the content of the named file.
+func (l LongPathFS) ReadFile(name string) ([]byte, error) {
+	return l.FS.ReadFile(LongPath(name))
+}
+
+// WriteFile writes data to the named file, creating it if needed.
+func (l LongPathFS) WriteFile(name string, data []byte, perm fs.FileMode) error {
+	return l.FS.WriteFile(LongPath(name), data, perm)
+}
+
+// MkdirAll creates path and all necessary parents.
+func (l LongPathFS) MkdirAll(path string, perm fs.FileMode) error {
+	return l.FS.MkdirAll(LongPath(path), perm)
+}
+
+// Remove removes the named file or empty directory.
+func (l LongPathFS) Remove(name string) error {
+	return l.FS.Remove(LongPath(name))
+}
+
+// RemoveAll removes path and any children it contains.
+func (l LongPathFS) RemoveAll(path string) error {
+	return l.FS.RemoveAll(LongPath(path))
+}
+
+// Symlink creates newname as a symbolic link to oldname. Only newname is
+// prefixed: oldname is stored in the link as given, so Readlink returns it
+// unchanged.
+func (l LongPathFS) Symlink(oldname, newname string) error {
+	return l.FS.Symlink(oldname, LongPath(newname))
+}
+
+// Readlink returns the destination of the named symbolic link.
+func (l LongPathFS) Readlink(name string) (string, error) {
+	dest, err := l.FS.Readlink(LongPath(name))
+	return TrimLongPath(dest), err
+}
+
+// Rename renames (moves) oldpath to newpath.
+func (l LongPathFS) Rename(oldpath, newpath string) error {
+	return l.FS.Rename(LongPath(oldpath), LongPath(newpath))
+}
+
+// ReadDir reads the named directory, returning its directory entries sorted by filename.
+func (l LongPathFS) ReadDir(name string) ([]os.DirEntry, error) {
+	return l.FS.ReadDir(LongPath(name))
+}
+
+// WalkDir walks the file tree rooted at root, calling fn for each file or
+// directory with the prefix trimmed from its path.
+func (l LongPathFS) WalkDir(root string, fn fs.WalkDirFunc) error {
+	prefixed := LongPath(root)
+	if prefixed == root {
+		return l.FS.WalkDir(root, fn)
+	}
+
+	return l.FS.WalkDir(prefixed, func(path string, d fs.DirEntry, err error) error {
+		return fn(TrimLongPath(path), d, err)
+	})
+}
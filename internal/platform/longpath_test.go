@@ -0,0 +1,76 @@
+package platform
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestWindowsLongPath(t *testing.T) {
+	t.Parallel()
+
+	// deep is long enough to cross the threshold on its own.
+	deep := strings.Repeat(`abcdefghij\`, 25) + "file.txt"
+
+	tests := []struct {
+		name string
+		path string
+		want string
+	}{
+		{"short drive path", `C:\Users\me\.zshrc`, `C:\Users\me\.zshrc`},
+		{"drive path", `C:\` + deep, `\\?\C:\` + deep},
+		{"forward slashes", `C:/` + strings.ReplaceAll(deep, `\`, "/"), `\\?\C:\` + deep},
+		{"dot elements", `C:\.\x\..\` + deep, `\\?\C:\` + deep},
+		{"duplicate separators", `C:\\` + deep, `\\?\C:\` + deep},
+		{"dotdot past root", `C:\..\` + deep, `\\?\C:\` + deep},
+		{"UNC path", `\\srv\share\` + deep, `\\?\UNC\srv\share\` + deep},
+		{"already prefixed", `\\?\C:\` + deep, `\\?\C:\` + deep},
+		{"device path", `\\.\C:\` + deep, `\\.\C:\` + deep},
+		{"relative path", deep, deep},
+		{"drive relative path", `C:` + deep, `C:` + deep},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			if got := windowsLongPath(tt.path); got != tt.want {
+				t.Errorf("windowsLongPath(%q) = %q, want %q", tt.path, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTrimLongPath(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		path string
+		want string
+	}{
+		{`\\?\C:\Users\me`, `C:\Users\me`},
+		{`\\?\UNC\srv\share\dir`, `\\srv\share\dir`},
+		{`C:\Users\me`, `C:\Users\me`},
+		{"/home/me", "/home/me"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			t.Parallel()
+
+			if got := TrimLongPath(tt.path); got != tt.want {
+				t.Errorf("TrimLongPath(%q) = %q, want %q", tt.path, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLongPath_NonWindowsUnchanged(t *testing.T) {
+	if hostGOOS == OSWindows {
+		t.Skip("host is Windows")
+	}
+
+	path := "/" + strings.Repeat("abcdefghij/", 30) + "file.txt"
+	if got := LongPath(path); got != path {
+		t.Errorf("LongPath(%q) = %q, want it unchanged", path, got)
+	}
+}
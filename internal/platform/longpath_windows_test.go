@@ -0,0 +1,81 @@
+//go:build windows
+
+package platform
+
+import (
+	"io/fs"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/AntoineGS/tidydots/internal/fsys"
+)
+
+func TestLongPath_Windows(t *testing.T) {
+	t.Parallel()
+
+	path := `C:\` + strings.Repeat(`abcdefghij\`, 25) + "file.txt"
+
+	got := LongPath(path)
+	if !strings.HasPrefix(got, `\\?\C:\`) {
+		t.Errorf("LongPath(%q) = %q, want a \\\\?\\ prefix", path, got)
+	}
+
+	if TrimLongPath(got) != path {
+		t.Errorf("TrimLongPath(LongPath(p)) = %q, want %q", TrimLongPath(got), path)
+	}
+}
+
+func TestLongPathFS_DeepTree(t *testing.T) {
+	t.Parallel()
+
+	root := t.TempDir()
+	dir := root
+	for len(dir) < 300 {
+		dir = filepath.Join(dir, "abcdefghijklmnopqrstuvwxyz")
+	}
+
+	lfs := WithLongPaths(fsys.OsFS{})
+	file := filepath.Join(dir, "config.toml")
+
+	if err := lfs.MkdirAll(dir, 0750); err != nil {
+		t.Fatalf("MkdirAll() error = %v", err)
+	}
+
+	if err := lfs.WriteFile(file, []byte("x"), 0600); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+
+	if _, err := lfs.Stat(file); err != nil {
+		t.Fatalf("Stat() error = %v", err)
+	}
+
+	var walked []string
+
+	err := lfs.WalkDir(root, func(path string, _ fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		walked = append(walked, path)
+
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("WalkDir() error = %v", err)
+	}
+
+	if len(walked) == 0 || walked[len(walked)-1] != file {
+		t.Errorf("WalkDir() last path = %v, want %q", walked, file)
+	}
+
+	for _, p := range walked {
+		if strings.HasPrefix(p, `\\?\`) {
+			t.Errorf("WalkDir() passed prefixed path %q to the callback", p)
+		}
+	}
+
+	if err := lfs.RemoveAll(filepath.Join(root, "abcdefghijklmnopqrstuvwxyz")); err != nil {
+		t.Fatalf("RemoveAll() error = %v", err)
+	}
+}
@@ -13,6 +13,7 @@ import (
 	"runtime"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/AntoineGS/tidydots/internal/cmdexec"
 	"github.com/AntoineGS/tidydots/internal/fsys"
@@ -26,6 +27,20 @@ const (
 	OSWindows = "windows"
 )
 
+// Environment variables that override detected platform values, for testing
+// and for reproducing reports that depend on a specific platform. The --os
+// flag still takes precedence over TIDYDOTS_OS.
+const (
+	EnvOS       = "TIDYDOTS_OS"
+	EnvDistro   = "TIDYDOTS_DISTRO"
+	EnvHostname = "TIDYDOTS_HOSTNAME"
+	EnvUser     = "TIDYDOTS_USER"
+	// EnvHome is the home directory used when the OS is overridden to one
+	// tidydots is not running on. It is also the EnvVars key that holds that
+	// home, which config.ExpandPath looks for (see config.HomeVar).
+	EnvHome = "TIDYDOTS_HOME"
+)
+
 // distroArch is the /etc/os-release ID of Arch Linux.
 const distroArch = "arch"
 
@@ -33,16 +48,20 @@ const distroArch = "arch"
 // packages package, which cannot be imported here because that package already
 // imports platform.
 const (
-	mgrYay    = "yay"
-	mgrParu   = "paru"
-	mgrPacman = "pacman"
-	mgrApt    = "apt"
-	mgrDnf    = "dnf"
-	mgrBrew   = "brew"
-	mgrWinget = "winget"
-	mgrScoop  = "scoop"
-	mgrChoco  = "choco"
-	mgrGit    = "git"
+	mgrYay      = "yay"
+	mgrParu     = "paru"
+	mgrPacman   = "pacman"
+	mgrApt      = "apt"
+	mgrDnf      = "dnf"
+	mgrEopkg    = "eopkg"
+	mgrEmerge   = "emerge"
+	mgrBrew     = "brew"
+	mgrBrewCask = "brew-cask"
+	mgrMas      = "mas"
+	mgrWinget   = "winget"
+	mgrScoop    = "scoop"
+	mgrChoco    = "choco"
+	mgrGit      = "git"
 )
 
 // Platform holds detected platform information including the operating system,
@@ -55,11 +74,58 @@ type Platform struct {
 	User       string
 	HasDisplay bool
 	IsWSL      bool
+
+	// hostOS and hostEnvVars are the detected OS and EnvVars, which an
+	// override back to the host's OS restores. crossHome is the home set with
+	// WithHome. All are empty on a Platform that was not detected.
+	hostEnvVars map[string]string
+	hostOS      string
+	crossHome   string
 }
 
+// probeTimeout bounds each probe of Detect that runs an external command, so
+// a hung command cannot block startup.
+const probeTimeout = 2 * time.Second
+
+// The host is probed once per process: hostOnce guards host, which holds the
+// result before any TIDYDOTS_* override. probeHost is the probe, which tests
+// replace to count its calls.
+var (
+	hostOnce  sync.Once
+	host      *Platform
+	probeHost = detectHost
+)
+
 // Detect detects the current platform characteristics including OS type,
 // Linux distribution (if applicable), hostname, current user, and root status.
+// Values set through TIDYDOTS_OS, TIDYDOTS_DISTRO, TIDYDOTS_HOSTNAME and
+// TIDYDOTS_USER replace the detected ones; like --os, they do not change how
+// the host itself is probed (display, WSL, package managers).
+//
+// The host is only probed by the first call; later calls return a copy of
+// that result, with the overrides read again.
 func Detect() *Platform {
+	return DetectContext(context.Background())
+}
+
+// DetectContext is Detect with ctx bounding the probes that run external
+// commands, each of which also gives up after probeTimeout. A probe cut short
+// leaves its values unset, for the rest of the process when it was the first
+// detection.
+func DetectContext(ctx context.Context) *Platform {
+	hostOnce.Do(func() { host = probeHost(ctx) })
+
+	p := *host
+	p.EnvVars = maps.Clone(host.EnvVars)
+	p.hostEnvVars = maps.Clone(host.hostEnvVars)
+
+	return p.withEnvOverrides(os.Getenv)
+}
+
+// detectHost probes this machine for the values Detect returns. Only the
+// PowerShell profile on Windows needs an external command; everything else
+// is read from the environment and from files.
+func detectHost(ctx context.Context) *Platform {
 	p := &Platform{
 		OS:       detectOS(),
 		Hostname: detectHostname(),
@@ -75,12 +141,48 @@ func Detect() *Platform {
 	}
 
 	if p.OS == OSWindows {
-		p.detectPowerShellProfile()
+		p.detectPowerShellProfile(ctx)
 	}
 
 	// Provide OS/WSL hints so DetectAvailableManagers can skip slow Windows drive mounts
 	SetDetectionHints(p.OS, p.IsWSL)
 
+	p.hostOS = p.OS
+	p.hostEnvVars = maps.Clone(p.EnvVars)
+
+	return p
+}
+
+// withEnvOverrides returns p with the values of the TIDYDOTS_* override
+// variables applied, as read through getenv. An unsupported TIDYDOTS_OS is
+// logged and ignored.
+func (p *Platform) withEnvOverrides(getenv func(string) string) *Platform {
+	if osType := getenv(EnvOS); osType != "" {
+		if osType == OSLinux || osType == OSWindows {
+			p = p.WithOS(osType)
+		} else {
+			slog.Warn("ignoring unsupported OS override",
+				slog.String("variable", EnvOS),
+				slog.String("value", osType))
+		}
+	}
+
+	if distro := getenv(EnvDistro); distro != "" {
+		p = p.WithDistro(distro)
+	}
+
+	if hostname := getenv(EnvHostname); hostname != "" {
+		p = p.WithHostname(hostname)
+	}
+
+	if username := getenv(EnvUser); username != "" {
+		p = p.WithUser(username)
+	}
+
+	if home := getenv(EnvHome); home != "" {
+		p = p.WithHome(home)
+	}
+
 	return p
 }
 
@@ -90,9 +192,15 @@ func detectDistro() string {
 	return detectDistroWithFS(fsys.OsFS{})
 }
 
-// detectDistroWithFS returns the Linux distribution ID using the given filesystem.
+// detectDistroWithFS returns the Linux distribution ID using the given
+// filesystem. Like os-release(5) says, /usr/lib/os-release is read when
+// /etc/os-release is missing.
 func detectDistroWithFS(f fsys.FS) string {
 	data, err := f.ReadFile("/etc/os-release")
+	if err != nil {
+		data, err = f.ReadFile("/usr/lib/os-release")
+	}
+
 	if err != nil {
 		slog.Debug("unable to detect linux distribution",
 			slog.String("file", "/etc/os-release"),
@@ -218,13 +326,18 @@ func hasX11Socket() bool {
 	return false
 }
 
-func (p *Platform) detectPowerShellProfile() {
-	p.detectPowerShellProfileWithRunner(cmdexec.OsRunner{})
+func (p *Platform) detectPowerShellProfile(ctx context.Context) {
+	p.detectPowerShellProfileWithRunner(ctx, cmdexec.OsRunner{})
 }
 
-func (p *Platform) detectPowerShellProfileWithRunner(r cmdexec.Runner) {
-	result, err := r.Run(context.Background(), "pwsh", "-NoProfile", "-Command", "echo $PROFILE")
+func (p *Platform) detectPowerShellProfileWithRunner(ctx context.Context, r cmdexec.Runner) {
+	ctx, cancel := context.WithTimeout(ctx, probeTimeout)
+	defer cancel()
+
+	result, err := r.Run(ctx, "pwsh", "-NoProfile", "-Command", "echo $PROFILE")
 	if err != nil {
+		slog.Debug("unable to detect PowerShell profile",
+			slog.String("error", err.Error()))
 		return
 	}
 
@@ -252,15 +365,104 @@ func (p *Platform) IsArchLinux() bool {
 	return p.Distro == distroArch
 }
 
-// WithOS returns a copy of the Platform with the OS field overridden.
+// WithOS returns a copy of the Platform with the OS field overridden. When
+// osType is not the OS tidydots runs on, EnvVars describe a typical install of
+// osType instead of this machine (see CrossOS), so that paths expand the way
+// they would there.
 func (p *Platform) WithOS(osType string) *Platform {
 	newP := *p
 	newP.OS = osType
-	newP.EnvVars = maps.Clone(p.EnvVars)
+	newP.EnvVars = newP.envVars()
 
 	return &newP
 }
 
+// WithHome returns a copy of the Platform whose home directory, once the OS
+// is overridden to another one, is home instead of the default for its user:
+// C:/Users/<user> on Windows and /home/<user> on Linux.
+func (p *Platform) WithHome(home string) *Platform {
+	newP := *p
+	newP.crossHome = home
+	newP.EnvVars = newP.envVars()
+
+	return &newP
+}
+
+// CrossOS reports whether the OS was overridden to one tidydots is not
+// running on. EnvVars then describe that OS, and nothing done on this machine
+// would land where the paths say.
+func (p *Platform) CrossOS() bool {
+	return p.hostOS != "" && p.OS != p.hostOS
+}
+
+// envVars returns the EnvVars p should have: those of the overridden OS when
+// it is not the host's, otherwise the detected ones.
+func (p *Platform) envVars() map[string]string {
+	if p.CrossOS() {
+		return crossOSEnv(p.OS, p.User, p.crossHome)
+	}
+
+	if p.hostEnvVars != nil {
+		return maps.Clone(p.hostEnvVars)
+	}
+
+	return maps.Clone(p.EnvVars)
+}
+
+// crossOSEnv returns the environment of a typical install of osType for
+// username, whose home is home, or the default for the user when home is
+// empty. Paths use forward slashes on both OSes.
+func crossOSEnv(osType, username, home string) map[string]string {
+	home = strings.TrimRight(strings.ReplaceAll(home, `\`, "/"), "/")
+	if home == "" {
+		home = defaultHome(osType, username)
+	}
+
+	env := map[string]string{EnvHome: home}
+
+	if osType == OSWindows {
+		profileDir := home + "/Documents/PowerShell"
+		profileFile := "Microsoft.PowerShell_profile.ps1"
+
+		env["USERPROFILE"] = home
+		env["USERNAME"] = username
+		env["APPDATA"] = home + "/AppData/Roaming"
+		env["LOCALAPPDATA"] = home + "/AppData/Local"
+		env["PWSH_PROFILE"] = profileDir + "/" + profileFile
+		env["PWSH_PROFILE_FILE"] = profileFile
+		env["PWSH_PROFILE_PATH"] = profileDir
+
+		return env
+	}
+
+	env["HOME"] = home
+	env["USER"] = username
+	env["XDG_CONFIG_HOME"] = home + "/.config"
+	env["XDG_DATA_HOME"] = home + "/.local/share"
+	env["XDG_STATE_HOME"] = home + "/.local/state"
+	env["XDG_CACHE_HOME"] = home + "/.cache"
+
+	return env
+}
+
+// defaultHome returns the usual home directory of username on osType. A
+// Windows DOMAIN\user name keeps only the user.
+func defaultHome(osType, username string) string {
+	username = username[strings.LastIndex(username, `\`)+1:]
+	if username == "" {
+		username = "user"
+	}
+
+	switch {
+	case osType == OSWindows:
+		return "C:/Users/" + username
+	case username == "root":
+		return "/root"
+	default:
+		return "/home/" + username
+	}
+}
+
 // WithHostname returns a copy of the Platform with the Hostname field overridden.
 func (p *Platform) WithHostname(hostname string) *Platform {
 	newP := *p
@@ -270,11 +472,12 @@ func (p *Platform) WithHostname(hostname string) *Platform {
 	return &newP
 }
 
-// WithUser returns a copy of the Platform with the User field overridden.
+// WithUser returns a copy of the Platform with the User field overridden. On
+// a cross-OS platform the default home directory follows the new user.
 func (p *Platform) WithUser(username string) *Platform {
 	newP := *p
 	newP.User = username
-	newP.EnvVars = maps.Clone(p.EnvVars)
+	newP.EnvVars = newP.envVars()
 
 	return &newP
 }
@@ -288,10 +491,65 @@ func (p *Platform) WithDistro(distro string) *Platform {
 	return &newP
 }
 
-// IsCommandAvailable checks if a command is available in PATH
+// CommandCache remembers whether commands are in PATH, so each one is looked
+// up once however many callers ask, concurrent ones included. The zero value
+// is not usable; see NewCommandCache.
+type CommandCache struct {
+	lookPath func(string) (string, error)
+	commands map[string]*cachedCommand
+	mu       sync.Mutex
+}
+
+// cachedCommand is the answer of a CommandCache for one command.
+type cachedCommand struct {
+	once  sync.Once
+	found bool
+}
+
+// NewCommandCache returns an empty CommandCache that looks commands up with
+// lookPath, usually exec.LookPath.
+func NewCommandCache(lookPath func(string) (string, error)) *CommandCache {
+	return &CommandCache{lookPath: lookPath, commands: make(map[string]*cachedCommand)}
+}
+
+// Available reports whether lookPath finds cmd, looking it up on the first
+// call only.
+func (c *CommandCache) Available(cmd string) bool {
+	c.mu.Lock()
+
+	entry, ok := c.commands[cmd]
+	if !ok {
+		entry = &cachedCommand{}
+		c.commands[cmd] = entry
+	}
+
+	c.mu.Unlock()
+
+	entry.once.Do(func() {
+		_, err := c.lookPath(cmd)
+		entry.found = err == nil
+	})
+
+	return entry.found
+}
+
+// Reset forgets every answer, so the next call for each command looks it up
+// again.
+func (c *CommandCache) Reset() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	clear(c.commands)
+}
+
+// commands is the process-wide cache behind IsCommandAvailable.
+var commands = NewCommandCache(exec.LookPath)
+
+// IsCommandAvailable checks if a command is available in PATH, with
+// exec.LookPath rather than by running it. The answer is cached for the
+// process; ResetAvailableManagersCache clears it.
 func IsCommandAvailable(cmd string) bool {
-	_, err := exec.LookPath(cmd)
-	return err == nil
+	return commands.Available(cmd)
 }
 
 // isCommandAvailableWithRunner checks if a command is available using the given runner.
@@ -301,11 +559,12 @@ func isCommandAvailableWithRunner(cmd string, r cmdexec.Runner) bool {
 }
 
 // KnownPackageManagers is the list of supported package managers across all platforms.
-// Includes Arch Linux (yay, paru, pacman), Debian/Fedora/macOS (apt, dnf, brew),
-// Windows (winget, scoop, choco) package managers, and git for repository cloning.
+// Includes Arch Linux (yay, paru, pacman), Debian/Fedora/Solus/Gentoo/macOS
+// (apt, dnf, eopkg, emerge, brew, brew-cask, mas), Windows (winget, scoop,
+// choco) package managers, and git for repository cloning.
 var KnownPackageManagers = []string{
 	mgrYay, mgrParu, mgrPacman, // Arch Linux
-	mgrApt, mgrDnf, mgrBrew, // Debian/Fedora/macOS
+	mgrApt, mgrDnf, mgrEopkg, mgrEmerge, mgrBrew, mgrBrewCask, mgrMas, // Debian/Fedora/Solus/Gentoo/macOS
 	mgrWinget, mgrScoop, mgrChoco, // Windows
 	mgrGit, // Git for repository cloning
 }
@@ -385,21 +644,50 @@ var (
 var managersForOS = map[string]map[string]bool{
 	OSLinux: {
 		mgrYay: true, mgrParu: true, mgrPacman: true,
-		mgrApt: true, mgrDnf: true, mgrBrew: true,
+		mgrApt: true, mgrDnf: true, mgrEopkg: true, mgrEmerge: true,
+		mgrBrew: true, mgrBrewCask: true, mgrMas: true,
 	},
 	OSWindows: {
 		mgrWinget: true, mgrScoop: true, mgrChoco: true,
 	},
 }
 
-// isManagerValidForOS returns true if the manager is valid for the given OS,
+// darwinOnlyManagers are only valid on macOS. tidydots reports macOS as
+// linux, so managersForOS alone cannot keep them off Linux.
+var darwinOnlyManagers = map[string]bool{
+	mgrBrewCask: true,
+	mgrMas:      true,
+}
+
+// hostGOOS is runtime.GOOS, a variable so tests can check darwin-only managers.
+var hostGOOS = runtime.GOOS
+
+// managerBinaries maps the managers whose executable is not their name.
+var managerBinaries = map[string]string{
+	mgrBrewCask: mgrBrew,
+}
+
+// managerBinary returns the executable looked up in PATH to detect manager.
+func managerBinary(manager string) string {
+	if bin, ok := managerBinaries[manager]; ok {
+		return bin
+	}
+
+	return manager
+}
+
+// IsManagerValidForOS returns true if the manager is valid for the given OS,
 // or if the manager is cross-platform (not listed in any OS-specific set).
-func isManagerValidForOS(manager, osType string) bool {
+func IsManagerValidForOS(manager, osType string) bool {
 	osManagers, osKnown := managersForOS[osType]
 	if !osKnown || osType == "" {
 		return true // unknown OS, allow everything
 	}
 
+	if darwinOnlyManagers[manager] && hostGOOS != "darwin" {
+		return false
+	}
+
 	if osManagers[manager] {
 		return true // explicitly listed for this OS
 	}
@@ -434,25 +722,53 @@ func DetectAvailableManagers() []string {
 	return DetectAvailableManagersWithRunner(cmdexec.OsRunner{})
 }
 
+// SupportedManagers returns the known package managers that can exist on
+// this OS, available or not, in KnownPackageManagers order.
+func SupportedManagers() []string {
+	supported := make([]string, 0, len(KnownPackageManagers))
+
+	for _, mgr := range KnownPackageManagers {
+		if IsManagerValidForOS(mgr, detectedOS) {
+			supported = append(supported, mgr)
+		}
+	}
+
+	return supported
+}
+
 // DetectAvailableManagersWithRunner returns a list of package managers available on the
-// system using the given runner for PATH lookups. Results are cached after the first call.
+// system using the given runner for PATH lookups. The lookups run concurrently,
+// since some (winget on Windows) are slow, and the result keeps the order of
+// KnownPackageManagers. Results are cached after the first call.
 func DetectAvailableManagersWithRunner(r cmdexec.Runner) []string {
 	availableManagersOnce.Do(func() {
-		available := make([]string, 0, len(KnownPackageManagers))
+		found := make([]bool, len(KnownPackageManagers))
+
+		var wg sync.WaitGroup
 
-		for _, mgr := range KnownPackageManagers {
-			if !isManagerValidForOS(mgr, detectedOS) {
+		for i, mgr := range KnownPackageManagers {
+			if !IsManagerValidForOS(mgr, detectedOS) {
 				continue
 			}
 
-			if detectedWSL && len(windowsDriveMounts) > 0 {
-				if lookPathSkipWindowsDrives(mgr, windowsDriveMounts) {
-					available = append(available, mgr)
-				}
-			} else {
-				if isCommandAvailableWithRunner(mgr, r) {
-					available = append(available, mgr)
+			bin := managerBinary(mgr)
+
+			wg.Go(func() {
+				if detectedWSL && len(windowsDriveMounts) > 0 {
+					found[i] = lookPathSkipWindowsDrives(bin, windowsDriveMounts)
+				} else {
+					found[i] = isCommandAvailableWithRunner(bin, r)
 				}
+			})
+		}
+
+		wg.Wait()
+
+		available := make([]string, 0, len(KnownPackageManagers))
+
+		for i, mgr := range KnownPackageManagers {
+			if found[i] {
+				available = append(available, mgr)
 			}
 		}
 
@@ -463,8 +779,11 @@ func DetectAvailableManagersWithRunner(r cmdexec.Runner) []string {
 }
 
 // ResetAvailableManagersCache clears the cached manager detection results,
-// causing the next call to DetectAvailableManagers to re-scan PATH.
+// causing the next call to DetectAvailableManagers to re-scan PATH, along with
+// the cached answers of IsCommandAvailable and CommandVersion.
 func ResetAvailableManagersCache() {
 	availableManagersOnce = sync.Once{}
 	availableManagersCached = nil
+	commands.Reset()
+	commandVersions.Clear()
 }
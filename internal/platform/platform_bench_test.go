@@ -0,0 +1,43 @@
+package platform
+
+import (
+	"context"
+	"os/exec"
+	"testing"
+)
+
+func BenchmarkDetect(b *testing.B) {
+	Detect()
+
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		_ = Detect()
+	}
+}
+
+func BenchmarkDetectHost(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		_ = detectHost(context.Background())
+	}
+}
+
+// BenchmarkLookPath is the PATH search IsCommandAvailable made for every
+// call before it was cached, for comparison with BenchmarkIsCommandAvailable.
+func BenchmarkLookPath(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		for _, cmd := range KnownPackageManagers {
+			_, _ = exec.LookPath(cmd)
+		}
+	}
+}
+
+func BenchmarkIsCommandAvailable(b *testing.B) {
+	b.Cleanup(ResetAvailableManagersCache)
+
+	for i := 0; i < b.N; i++ {
+		for _, cmd := range KnownPackageManagers {
+			_ = IsCommandAvailable(cmd)
+		}
+	}
+}
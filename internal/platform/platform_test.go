@@ -1,8 +1,10 @@
 package platform
 
 import (
+	"context"
 	"path/filepath"
 	"runtime"
+	"sync"
 	"testing"
 )
 
@@ -38,6 +40,108 @@ func TestDetect(t *testing.T) {
 	}
 }
 
+func TestWithEnvOverrides(t *testing.T) {
+	t.Parallel()
+
+	detected := &Platform{
+		OS:       OSLinux,
+		Distro:   "arch",
+		Hostname: "desktop",
+		User:     "alice",
+		EnvVars:  map[string]string{},
+	}
+
+	tests := []struct {
+		env  map[string]string
+		want Platform
+		name string
+	}{
+		{
+			name: "no overrides",
+			want: Platform{OS: OSLinux, Distro: "arch", Hostname: "desktop", User: "alice"},
+		},
+		{
+			name: "all overrides",
+			env: map[string]string{
+				EnvOS:       OSWindows,
+				EnvDistro:   "ubuntu",
+				EnvHostname: "work-laptop",
+				EnvUser:     "bob",
+			},
+			want: Platform{OS: OSWindows, Distro: "ubuntu", Hostname: "work-laptop", User: "bob"},
+		},
+		{
+			name: "unsupported OS is ignored",
+			env:  map[string]string{EnvOS: "plan9", EnvHostname: "server"},
+			want: Platform{OS: OSLinux, Distro: "arch", Hostname: "server", User: "alice"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			got := detected.withEnvOverrides(func(key string) string { return tt.env[key] })
+
+			if got.OS != tt.want.OS || got.Distro != tt.want.Distro ||
+				got.Hostname != tt.want.Hostname || got.User != tt.want.User {
+				t.Errorf("withEnvOverrides() = %+v, want %+v", *got, tt.want)
+			}
+
+			if detected.Hostname != "desktop" || detected.OS != OSLinux {
+				t.Errorf("withEnvOverrides() modified the detected platform: %+v", *detected)
+			}
+		})
+	}
+}
+
+func TestDetect_EnvOverrides(t *testing.T) {
+	t.Setenv(EnvHostname, "repro-host")
+	t.Setenv(EnvUser, "repro-user")
+
+	p := Detect()
+
+	if p.Hostname != "repro-host" || p.User != "repro-user" {
+		t.Errorf("Detect() Hostname, User = %q, %q, want repro-host, repro-user", p.Hostname, p.User)
+	}
+}
+
+// resetHost forgets the cached host detection, probing with probe until the
+// test ends.
+func resetHost(t *testing.T, probe func(context.Context) *Platform) {
+	t.Helper()
+
+	hostOnce, host, probeHost = sync.Once{}, nil, probe
+	t.Cleanup(func() { hostOnce, host, probeHost = sync.Once{}, nil, detectHost })
+}
+
+func TestDetect_ProbesHostOnce(t *testing.T) {
+	calls := 0
+	resetHost(t, func(ctx context.Context) *Platform {
+		calls++
+		return detectHost(ctx)
+	})
+
+	first := Detect()
+	first.EnvVars["MUTATED"] = "yes"
+
+	t.Setenv(EnvHostname, "second-host")
+
+	second := DetectContext(context.Background())
+
+	if calls != 1 {
+		t.Errorf("host probed %d times, want 1", calls)
+	}
+
+	if _, ok := second.EnvVars["MUTATED"]; ok {
+		t.Error("Detect() results share EnvVars")
+	}
+
+	if second.Hostname != "second-host" {
+		t.Errorf("second Detect() Hostname = %q, want the override read again", second.Hostname)
+	}
+}
+
 func TestWithOS(t *testing.T) {
 	t.Parallel()
 	p := &Platform{
@@ -64,6 +168,81 @@ func TestWithOS(t *testing.T) {
 	}
 }
 
+func TestWithOS_CrossOSEnv(t *testing.T) {
+	t.Parallel()
+
+	host := &Platform{
+		OS:          OSLinux,
+		User:        "alice",
+		EnvVars:     map[string]string{"PWSH_PROFILE": "/home/alice/profile.ps1"},
+		hostOS:      OSLinux,
+		hostEnvVars: map[string]string{"PWSH_PROFILE": "/home/alice/profile.ps1"},
+	}
+
+	tests := []struct {
+		platform *Platform
+		want     map[string]string
+		name     string
+		cross    bool
+	}{
+		{
+			name:     "windows defaults",
+			platform: host.WithOS(OSWindows),
+			cross:    true,
+			want: map[string]string{
+				EnvHome:        "C:/Users/alice",
+				"USERPROFILE":  "C:/Users/alice",
+				"APPDATA":      "C:/Users/alice/AppData/Roaming",
+				"LOCALAPPDATA": "C:/Users/alice/AppData/Local",
+				"PWSH_PROFILE": "C:/Users/alice/Documents/PowerShell/Microsoft.PowerShell_profile.ps1",
+			},
+		},
+		{
+			name:     "windows home",
+			platform: host.WithOS(OSWindows).WithHome(`D:\Profiles\alice\`),
+			cross:    true,
+			want:     map[string]string{EnvHome: "D:/Profiles/alice", "APPDATA": "D:/Profiles/alice/AppData/Roaming"},
+		},
+		{
+			name:     "home follows the user",
+			platform: host.WithOS(OSWindows).WithUser("bob"),
+			cross:    true,
+			want:     map[string]string{EnvHome: "C:/Users/bob"},
+		},
+		{
+			name:     "linux from windows",
+			platform: (&Platform{OS: OSWindows, User: `CORP\carol`, hostOS: OSWindows}).WithOS(OSLinux),
+			cross:    true,
+			want:     map[string]string{EnvHome: "/home/carol", "HOME": "/home/carol", "XDG_CONFIG_HOME": "/home/carol/.config"},
+		},
+		{
+			name:     "back to the host",
+			platform: host.WithOS(OSWindows).WithOS(OSLinux),
+			want:     map[string]string{"PWSH_PROFILE": "/home/alice/profile.ps1"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			if got := tt.platform.CrossOS(); got != tt.cross {
+				t.Errorf("CrossOS() = %v, want %v", got, tt.cross)
+			}
+
+			for key, want := range tt.want {
+				if got := tt.platform.EnvVars[key]; got != want {
+					t.Errorf("EnvVars[%s] = %q, want %q", key, got, want)
+				}
+			}
+
+			if _, ok := tt.platform.EnvVars[EnvHome]; ok != tt.cross {
+				t.Errorf("EnvVars has %s = %v, want %v", EnvHome, ok, tt.cross)
+			}
+		})
+	}
+}
+
 func TestGetBasename(t *testing.T) {
 	t.Parallel()
 	tests := []struct {
@@ -243,14 +422,18 @@ func TestIsManagerValidForOS(t *testing.T) {
 		{"git on windows", "git", OSWindows, true},
 		{"unknown manager on linux", "unknown", OSLinux, true},
 		{"pacman on empty os", "pacman", "", true},
+		{"brew-cask on windows", "brew-cask", OSWindows, false},
+		{"brew-cask on linux", "brew-cask", OSLinux, hostGOOS == "darwin"},
+		{"mas on windows", "mas", OSWindows, false},
+		{"mas on linux", "mas", OSLinux, hostGOOS == "darwin"},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			t.Parallel()
-			got := isManagerValidForOS(tt.manager, tt.os)
+			got := IsManagerValidForOS(tt.manager, tt.os)
 			if got != tt.want {
-				t.Errorf("isManagerValidForOS(%q, %q) = %v, want %v", tt.manager, tt.os, got, tt.want)
+				t.Errorf("IsManagerValidForOS(%q, %q) = %v, want %v", tt.manager, tt.os, got, tt.want)
 			}
 		})
 	}
@@ -1,8 +1,10 @@
 package platform
 
 import (
+	"context"
 	"os"
 	"runtime"
+	"slices"
 	"testing"
 
 	"github.com/AntoineGS/tidydots/internal/cmdexec"
@@ -30,6 +32,24 @@ func TestDetectDistroWithFS_Arch(t *testing.T) {
 	}
 }
 
+func TestDetectDistroWithFS_UsrLibFallback(t *testing.T) {
+	t.Parallel()
+
+	mem := fsys.NewMemFS()
+	if err := mem.MkdirAll("/usr/lib", 0o755); err != nil {
+		t.Fatalf("MkdirAll: %v", err)
+	}
+
+	if err := mem.WriteFile("/usr/lib/os-release", []byte("NAME=Fedora\nID=fedora\n"), 0o644); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+
+	distro := detectDistroWithFS(mem)
+	if distro != "fedora" {
+		t.Errorf("detectDistroWithFS() = %q, want %q", distro, "fedora")
+	}
+}
+
 func TestDetectDistroWithFS_Ubuntu(t *testing.T) {
 	t.Parallel()
 
@@ -304,6 +324,37 @@ func TestDetectAvailableManagersWithRunner_LinuxOSFiltering(t *testing.T) {
 	}
 }
 
+func TestDetectAvailableManagersWithRunner_BrewCask(t *testing.T) {
+	t.Cleanup(func() { hostGOOS = runtime.GOOS })
+
+	for _, tt := range []struct {
+		goos     string
+		wantCask bool
+	}{
+		{"darwin", true},
+		{"linux", false},
+	} {
+		ResetAvailableManagersCache()
+		detectedOS = OSLinux // tidydots reports macOS as linux
+		hostGOOS = tt.goos
+
+		stub := cmdexec.NewStubRunner()
+		stub.AddPath("brew", "/opt/homebrew/bin/brew")
+
+		managers := DetectAvailableManagersWithRunner(stub)
+
+		if !slices.Contains(managers, "brew") {
+			t.Errorf("%s: brew not detected in %v", tt.goos, managers)
+		}
+
+		if got := slices.Contains(managers, "brew-cask"); got != tt.wantCask {
+			t.Errorf("%s: brew-cask detected = %v, want %v", tt.goos, got, tt.wantCask)
+		}
+	}
+
+	ResetAvailableManagersCache()
+}
+
 // --- Platform struct and With* method tests ---
 
 func TestPlatform_WithOS_ImmutableCopy(t *testing.T) {
@@ -432,7 +483,7 @@ func TestDetectPowerShellProfileWithRunner_Success(t *testing.T) {
 	})
 
 	p := &Platform{EnvVars: make(map[string]string)}
-	p.detectPowerShellProfileWithRunner(stub)
+	p.detectPowerShellProfileWithRunner(context.Background(), stub)
 
 	if p.EnvVars["PWSH_PROFILE"] == "" {
 		t.Error("PWSH_PROFILE was not set")
@@ -456,7 +507,7 @@ func TestDetectPowerShellProfileWithRunner_EmptyOutput(t *testing.T) {
 	})
 
 	p := &Platform{EnvVars: make(map[string]string)}
-	p.detectPowerShellProfileWithRunner(stub)
+	p.detectPowerShellProfileWithRunner(context.Background(), stub)
 
 	if len(p.EnvVars) != 0 {
 		t.Errorf("expected no EnvVars set for empty output, got %v", p.EnvVars)
@@ -470,7 +521,7 @@ func TestDetectPowerShellProfileWithRunner_NoResult(t *testing.T) {
 	// No result queued — StubRunner returns zero Result without error
 
 	p := &Platform{EnvVars: make(map[string]string)}
-	p.detectPowerShellProfileWithRunner(stub)
+	p.detectPowerShellProfileWithRunner(context.Background(), stub)
 
 	if _, ok := p.EnvVars["PWSH_PROFILE"]; ok {
 		t.Error("PWSH_PROFILE should not be set when output is empty")
@@ -557,3 +608,27 @@ func TestSetDetectionHints_Windows(t *testing.T) {
 		t.Errorf("detectedOS = %q, want %q", detectedOS, OSWindows)
 	}
 }
+
+// blockingRunner is a StubRunner whose Run waits for its context to end.
+type blockingRunner struct {
+	*cmdexec.StubRunner
+}
+
+func (blockingRunner) Run(ctx context.Context, _ string, _ ...string) (cmdexec.Result, error) {
+	<-ctx.Done()
+	return cmdexec.Result{}, ctx.Err()
+}
+
+func TestDetectPowerShellProfileWithRunner_Canceled(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	p := &Platform{EnvVars: make(map[string]string)}
+	p.detectPowerShellProfileWithRunner(ctx, blockingRunner{cmdexec.NewStubRunner()})
+
+	if len(p.EnvVars) != 0 {
+		t.Errorf("expected no EnvVars set when the probe is canceled, got %v", p.EnvVars)
+	}
+}
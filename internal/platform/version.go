@@ -0,0 +1,125 @@
+package platform
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"sync"
+
+	"github.com/AntoineGS/tidydots/internal/cmdexec"
+)
+
+// ErrNoVersion is returned by CommandVersion when the output of
+// `<cmd> --version` holds no version number.
+var ErrNoVersion = errors.New("no version in --version output")
+
+// Version is the version of a command, as reported by `<cmd> --version`.
+// A component the command does not report is 0.
+type Version struct {
+	Major int
+	Minor int
+	Patch int
+}
+
+// String returns the version as major.minor.patch.
+func (v Version) String() string {
+	return fmt.Sprintf("%d.%d.%d", v.Major, v.Minor, v.Patch)
+}
+
+// AtLeast reports whether v is the version major.minor.patch or a later one,
+// for features that need a recent enough command.
+func (v Version) AtLeast(major, minor, patch int) bool {
+	if v.Major != major {
+		return v.Major > major
+	}
+
+	if v.Minor != minor {
+		return v.Minor > minor
+	}
+
+	return v.Patch >= patch
+}
+
+// versionPattern matches the first version number of a --version output,
+// with or without a patch component and a leading v (e.g. "v1.9.25200",
+// "git version 2.43.0", "Pacman v6.0.2").
+var versionPattern = regexp.MustCompile(`(\d+)\.(\d+)(?:\.(\d+))?`)
+
+// parseVersion returns the first version number in output.
+func parseVersion(output string) (Version, error) {
+	m := versionPattern.FindStringSubmatch(output)
+	if m == nil {
+		return Version{}, ErrNoVersion
+	}
+
+	var v Version
+
+	for i, part := range []*int{&v.Major, &v.Minor, &v.Patch} {
+		if m[i+1] == "" {
+			continue
+		}
+
+		// The pattern only matches digits, so this fails only on overflow.
+		n, err := strconv.Atoi(m[i+1])
+		if err != nil {
+			return Version{}, fmt.Errorf("%w: %w", ErrNoVersion, err)
+		}
+
+		*part = n
+	}
+
+	return v, nil
+}
+
+// commandVersion is a cached answer of CommandVersion.
+type commandVersion struct {
+	version Version
+	err     error
+}
+
+// commandVersions caches CommandVersion, by command name, so each binary is
+// run once per process.
+var commandVersions sync.Map
+
+// CommandVersion runs `<name> --version` and returns the first version number
+// it prints. The answer is cached; ResetAvailableManagersCache clears it.
+func CommandVersion(name string) (Version, error) {
+	return commandVersionWithRunner(context.Background(), name, cmdexec.OsRunner{})
+}
+
+// commandVersionWithRunner is CommandVersion through r.
+func commandVersionWithRunner(ctx context.Context, name string, r cmdexec.Runner) (Version, error) {
+	if cached, ok := commandVersions.Load(name); ok {
+		cv := cached.(commandVersion) //nolint:forcetypeassert // only commandVersions are stored
+		return cv.version, cv.err
+	}
+
+	v, err := probeVersion(ctx, name, r)
+	commandVersions.Store(name, commandVersion{version: v, err: err})
+
+	return v, err
+}
+
+// probeVersion runs `<name> --version` through r and parses its output.
+// Some commands print their version to stderr, so both are searched.
+func probeVersion(ctx context.Context, name string, r cmdexec.Runner) (Version, error) {
+	res, err := r.Run(ctx, name, "--version")
+	if err != nil {
+		return Version{}, fmt.Errorf("running %s --version: %w", name, err)
+	}
+
+	v, err := parseVersion(string(res.Stdout) + "\n" + string(res.Stderr))
+	if err != nil {
+		return Version{}, fmt.Errorf("%s: %w", name, err)
+	}
+
+	return v, nil
+}
+
+// ManagerVersion returns the version of the binary of the package manager
+// mgr, one of KnownPackageManagers.
+func ManagerVersion(mgr string) (Version, error) {
+	return CommandVersion(managerBinary(mgr))
+}
@@ -0,0 +1,154 @@
+package platform
+
+import (
+	"context"
+	"errors"
+	"os/exec"
+	"sync"
+	"testing"
+
+	"github.com/AntoineGS/tidydots/internal/cmdexec"
+)
+
+func TestParseVersion(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		output  string
+		want    Version
+		wantErr bool
+	}{
+		{output: "git version 2.43.0\n", want: Version{2, 43, 0}},
+		{output: "v1.9.25200\n", want: Version{1, 9, 25200}},
+		{output: "\n .--.                  Pacman v6.0.2 - libalpm v13.0.2\n", want: Version{6, 0, 2}},
+		{output: "Homebrew 4.2\n", want: Version{4, 2, 0}},
+		{output: "unknown option --version\n", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.output, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := parseVersion(tt.output)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseVersion() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if got != tt.want {
+				t.Errorf("parseVersion() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestVersion_AtLeast(t *testing.T) {
+	t.Parallel()
+
+	v := Version{1, 9, 0}
+
+	tests := []struct {
+		major, minor, patch int
+		want                bool
+	}{
+		{1, 9, 0, true},
+		{1, 8, 9, true},
+		{0, 99, 99, true},
+		{1, 9, 1, false},
+		{1, 10, 0, false},
+		{2, 0, 0, false},
+	}
+
+	for _, tt := range tests {
+		if got := v.AtLeast(tt.major, tt.minor, tt.patch); got != tt.want {
+			t.Errorf("%v.AtLeast(%d, %d, %d) = %v, want %v", v, tt.major, tt.minor, tt.patch, got, tt.want)
+		}
+	}
+}
+
+func TestCommandVersionWithRunner_Caches(t *testing.T) {
+	t.Cleanup(ResetAvailableManagersCache)
+	ResetAvailableManagersCache()
+
+	stub := cmdexec.NewStubRunner()
+	stub.AddResult("winget", cmdexec.Result{Stdout: []byte("v1.9.25200\n")})
+
+	for range 2 {
+		v, err := commandVersionWithRunner(context.Background(), "winget", stub)
+		if err != nil {
+			t.Fatalf("commandVersionWithRunner() error = %v", err)
+		}
+
+		if !v.AtLeast(1, 9, 0) {
+			t.Errorf("commandVersionWithRunner() = %v, want 1.9.25200", v)
+		}
+	}
+
+	if len(stub.Calls) != 1 {
+		t.Errorf("winget --version ran %d times, want once", len(stub.Calls))
+	}
+
+	stub.AddResult("scoop", cmdexec.Result{Stderr: []byte("no version here\n")})
+	if _, err := commandVersionWithRunner(context.Background(), "scoop", stub); !errors.Is(err, ErrNoVersion) {
+		t.Errorf("commandVersionWithRunner(scoop) error = %v, want ErrNoVersion", err)
+	}
+}
+
+func TestIsCommandAvailable_Caches(t *testing.T) {
+	t.Cleanup(ResetAvailableManagersCache)
+	ResetAvailableManagersCache()
+
+	const missing = "tidydots-no-such-command"
+
+	if IsCommandAvailable(missing) {
+		t.Fatalf("IsCommandAvailable(%q) = true", missing)
+	}
+
+	if _, ok := commands.commands[missing]; !ok {
+		t.Error("IsCommandAvailable() did not cache its answer")
+	}
+
+	ResetAvailableManagersCache()
+
+	if _, ok := commands.commands[missing]; ok {
+		t.Error("ResetAvailableManagersCache() kept the cached answer")
+	}
+}
+
+func TestCommandCache_LooksUpOnce(t *testing.T) {
+	var mu sync.Mutex
+
+	lookups := map[string]int{}
+	cache := NewCommandCache(func(cmd string) (string, error) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		lookups[cmd]++
+		if cmd == "git" {
+			return "/usr/bin/git", nil
+		}
+
+		return "", exec.ErrNotFound
+	})
+
+	var wg sync.WaitGroup
+	for range 8 {
+		wg.Go(func() {
+			if !cache.Available("git") || cache.Available("hg") {
+				t.Error("Available() = wrong answer")
+			}
+		})
+	}
+
+	wg.Wait()
+
+	if lookups["git"] != 1 || lookups["hg"] != 1 {
+		t.Errorf("lookups = %v, want one per command", lookups)
+	}
+
+	cache.Reset()
+	cache.Available("git")
+
+	if lookups["git"] != 2 {
+		t.Errorf("git looked up %d times after Reset, want 2", lookups["git"])
+	}
+}
@@ -0,0 +1,164 @@
+// Package presets is the catalog of suggested applications for common tools:
+// where their configs live on each OS and what their packages are called. A
+// preset is an application snippet, in the format `tidydots show` prints, that
+// is added to a config as a starting point. The catalog built into tidydots
+// can be extended, or a built-in preset replaced, by files in the presets
+// directory of a repo.
+package presets
+
+import (
+	"embed"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/AntoineGS/tidydots/internal/config"
+)
+
+// Dir is the directory, relative to the backup root, holding the presets of a
+// repo. Each .yaml file in it is one preset.
+const Dir = "presets"
+
+// SourceBuiltin is the Source of the presets built into tidydots.
+const SourceBuiltin = "built-in"
+
+//go:embed builtin/*.yaml
+var builtinFS embed.FS
+
+// Preset is a suggested application for a tool.
+type Preset struct {
+	// Name is the name of the preset file without its extension, which is
+	// what --preset and the preset picker match.
+	Name string
+	// Source is SourceBuiltin or the path of the repo's preset file.
+	Source      string
+	Application config.Application
+}
+
+// Builtin returns the presets built into tidydots, sorted by name.
+func Builtin() ([]Preset, error) {
+	files, err := fs.Glob(builtinFS, "builtin/*.yaml")
+	if err != nil {
+		return nil, err
+	}
+
+	presets := make([]Preset, 0, len(files))
+
+	for _, file := range files {
+		data, err := builtinFS.ReadFile(file)
+		if err != nil {
+			return nil, err
+		}
+
+		p, err := parse(strings.TrimSuffix(path.Base(file), ".yaml"), SourceBuiltin, data)
+		if err != nil {
+			return nil, err
+		}
+
+		presets = append(presets, p)
+	}
+
+	return presets, nil
+}
+
+// Load returns the built-in presets together with those in the presets
+// directory under backupRoot, sorted by name. A repo preset replaces the
+// built-in preset of the same name. A missing directory adds nothing.
+func Load(backupRoot string) ([]Preset, error) {
+	presets, err := Builtin()
+	if err != nil {
+		return nil, err
+	}
+
+	dir := filepath.Join(backupRoot, Dir)
+
+	files, err := filepath.Glob(filepath.Join(dir, "*.yaml"))
+	if err != nil {
+		return nil, err
+	}
+
+	for _, file := range files {
+		data, err := os.ReadFile(file) //nolint:gosec // preset file of the repo
+		if err != nil {
+			return nil, fmt.Errorf("reading preset: %w", err)
+		}
+
+		p, err := parse(strings.TrimSuffix(filepath.Base(file), ".yaml"), file, data)
+		if err != nil {
+			return nil, err
+		}
+
+		if i := slices.IndexFunc(presets, func(b Preset) bool { return b.Name == p.Name }); i >= 0 {
+			presets[i] = p
+		} else {
+			presets = append(presets, p)
+		}
+	}
+
+	slices.SortFunc(presets, func(a, b Preset) int { return strings.Compare(a.Name, b.Name) })
+
+	return presets, nil
+}
+
+// parse decodes the preset named name from data, read from source.
+func parse(name, source string, data []byte) (Preset, error) {
+	snippet, err := config.ParseSnippet(data)
+	if err != nil {
+		return Preset{}, fmt.Errorf("preset %s (%s): %w", name, source, err)
+	}
+
+	if len(snippet.Attachments) > 0 {
+		return Preset{}, fmt.Errorf("preset %s (%s): presets cannot carry attachments", name, source)
+	}
+
+	return Preset{Name: name, Source: source, Application: snippet.Application}, nil
+}
+
+// ErrNotFound is returned by Find when no preset has the requested name.
+var ErrNotFound = errors.New("preset not found")
+
+// Find returns the preset of presets named name, ignoring case.
+func Find(presets []Preset, name string) (Preset, error) {
+	for _, p := range presets {
+		if strings.EqualFold(p.Name, name) {
+			return p, nil
+		}
+	}
+
+	return Preset{}, fmt.Errorf("%w: %q", ErrNotFound, name)
+}
+
+// Search returns the presets whose name or description contains query,
+// ignoring case. An empty query returns every preset.
+func Search(presets []Preset, query string) []Preset {
+	query = strings.ToLower(strings.TrimSpace(query))
+	if query == "" {
+		return presets
+	}
+
+	var found []Preset
+
+	for _, p := range presets {
+		if strings.Contains(strings.ToLower(p.Name), query) ||
+			strings.Contains(strings.ToLower(p.Application.Description), query) {
+			found = append(found, p)
+		}
+	}
+
+	return found
+}
+
+// Names returns the names of presets, for error messages and completion.
+func Names(presets []Preset) []string {
+	names := make([]string, 0, len(presets))
+	for _, p := range presets {
+		names = append(names, p.Name)
+	}
+
+	return names
+}
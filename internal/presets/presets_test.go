@@ -0,0 +1,128 @@
+package presets
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/AntoineGS/tidydots/internal/config"
+)
+
+func TestBuiltin_Validate(t *testing.T) {
+	presets, err := Builtin()
+	if err != nil {
+		t.Fatalf("Builtin() error = %v", err)
+	}
+
+	if len(presets) == 0 {
+		t.Fatal("Builtin() returned no presets")
+	}
+
+	if !slices.IsSortedFunc(presets, func(a, b Preset) int { return strings.Compare(a.Name, b.Name) }) {
+		t.Errorf("Builtin() = %v, not sorted by name", Names(presets))
+	}
+
+	for _, p := range presets {
+		if p.Application.Name != p.Name {
+			t.Errorf("preset %s names its application %q", p.Name, p.Application.Name)
+		}
+
+		if p.Source != SourceBuiltin {
+			t.Errorf("preset %s Source = %q, want %q", p.Name, p.Source, SourceBuiltin)
+		}
+
+		cfg := &config.Config{Version: 3, BackupRoot: "/repo", Applications: []config.Application{p.Application}}
+		if errs := config.ValidateConfig(cfg); len(errs) > 0 {
+			t.Errorf("preset %s does not validate: %v", p.Name, errors.Join(errs...))
+		}
+	}
+}
+
+func TestLoad_RepoPresets(t *testing.T) {
+	root := t.TempDir()
+	dir := filepath.Join(root, Dir)
+
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	files := map[string]string{
+		"neovim.yaml": "name: neovim\ndescription: my neovim\nentries:\n  - name: config\n    backup: ./editors/nvim\n    targets:\n      linux: ~/.config/nvim\n",
+		"aerc.yaml":   "name: aerc\nentries:\n  - name: config\n    backup: ./aerc\n    targets:\n      linux: ~/.config/aerc\n",
+		"notes.txt":   "not a preset",
+	}
+
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	presets, err := Load(root)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+
+	nvim, err := Find(presets, "NeoVim")
+	if err != nil {
+		t.Fatalf("Find(NeoVim) error = %v", err)
+	}
+
+	if nvim.Application.Description != "my neovim" || nvim.Source != filepath.Join(dir, "neovim.yaml") {
+		t.Errorf("neovim = %+v, want the repo preset over the built-in one", nvim)
+	}
+
+	if _, err := Find(presets, "aerc"); err != nil {
+		t.Errorf("Find(aerc) error = %v, want the repo's extra preset", err)
+	}
+
+	if names := Names(presets); slices.Index(names, "aerc") != 0 {
+		t.Errorf("Names() = %v, want aerc first", names)
+	}
+
+	if _, err := Find(presets, "emacs"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Find(emacs) error = %v, want ErrNotFound", err)
+	}
+}
+
+func TestLoad_InvalidRepoPreset(t *testing.T) {
+	root := t.TempDir()
+	dir := filepath.Join(root, Dir)
+
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, "bad.yaml"), []byte("entries: []\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := Load(root); err == nil {
+		t.Error("Load() = nil error for a preset without a name")
+	}
+}
+
+func TestSearch(t *testing.T) {
+	presets, err := Builtin()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got := Search(presets, ""); len(got) != len(presets) {
+		t.Errorf("Search(\"\") = %d presets, want all %d", len(got), len(presets))
+	}
+
+	got := Names(Search(presets, "TERMINAL"))
+	for _, want := range []string{"alacritty", "kitty", "wezterm"} {
+		if !slices.Contains(got, want) {
+			t.Errorf("Search(TERMINAL) = %v, missing %s", got, want)
+		}
+	}
+
+	if got := Names(Search(presets, "vim")); !slices.Equal(got, []string{"neovim", "vim"}) {
+		t.Errorf("Search(vim) = %v, want [neovim vim]", got)
+	}
+}
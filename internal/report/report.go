@@ -0,0 +1,327 @@
+// Package report builds the Markdown summary printed by `tidydots report`:
+// the platform, the configuration and the state of every entry on this
+// machine, in a form that can be pasted into a bug report.
+package report
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"strconv"
+	"strings"
+
+	"github.com/AntoineGS/tidydots/internal/config"
+	"github.com/AntoineGS/tidydots/internal/fsys"
+	"github.com/AntoineGS/tidydots/internal/manager"
+	"github.com/AntoineGS/tidydots/internal/platform"
+	tmpl "github.com/AntoineGS/tidydots/internal/template"
+	"github.com/AntoineGS/tidydots/internal/tui/detection"
+	tuitable "github.com/AntoineGS/tidydots/internal/tui/table"
+)
+
+// Placeholders Redact puts in place of the hostname and user name.
+const (
+	RedactedHostname = "<hostname>"
+	RedactedUser     = "<user>"
+)
+
+// stateBroken labels a linked entry whose link no longer resolves.
+const stateBroken = "Broken"
+
+// entryRow is one line of the entries table.
+type entryRow struct {
+	app, name, kind, target, state string
+}
+
+// Generate returns the report for cfg on plat. mgr supplies the template
+// render state and the backup repo's git status. Hostname and user name are
+// included as is; see Redact.
+func Generate(cfg *config.Config, mgr *manager.Manager, plat *platform.Platform) string {
+	apps := mgr.GetApplications()
+	rows := entryRows(cfg, mgr, plat, apps)
+
+	var b strings.Builder
+
+	b.WriteString("# tidydots report\n\n")
+	writeSummary(&b, cfg, apps, rows)
+
+	b.WriteString("## Platform\n\n")
+	writeTable(&b, []string{"Field", "Value"}, [][]string{
+		{"tidydots version", orDash(mgr.Version)},
+		{"OS", plat.OS},
+		{"Distro", orDash(plat.Distro)},
+		{"Architecture", runtime.GOOS + "/" + runtime.GOARCH},
+		{"Hostname", orDash(plat.Hostname)},
+		{"User", orDash(plat.User)},
+		{"WSL", strconv.FormatBool(plat.IsWSL)},
+		{"Display", strconv.FormatBool(plat.HasDisplay)},
+	})
+
+	b.WriteString("## Configuration\n\n")
+	writeTable(&b, []string{"Field", "Value"}, [][]string{
+		{"Config file", filepath.Join(cfg.BackupRoot, "tidydots.yaml")},
+		{"Applications", fmt.Sprintf("%d (%d on this machine)", len(cfg.Applications), len(apps))},
+		{"Default manager", orDash(cfg.DefaultManager)},
+		{"Manager priority", orDash(strings.Join(cfg.ManagerPriority, ", "))},
+	})
+
+	b.WriteString("## Package managers\n\n")
+	writePackageManagers(&b)
+
+	b.WriteString("## Applications\n\n")
+
+	if len(apps) == 0 {
+		b.WriteString("No applications match this machine.\n\n")
+	} else {
+		appRows := make([][]string, 0, len(apps))
+		for _, app := range apps {
+			pkg := "no"
+			if app.HasPackage() {
+				pkg = "yes"
+			}
+
+			appRows = append(appRows, []string{app.Name, strconv.Itoa(len(app.Entries)), pkg})
+		}
+
+		writeTable(&b, []string{"Application", "Entries", "Package"}, appRows)
+	}
+
+	b.WriteString("## Entries\n\n")
+
+	if len(rows) == 0 {
+		b.WriteString("No entries have a target on this machine.\n")
+	} else {
+		cells := make([][]string, 0, len(rows))
+		for _, r := range rows {
+			cells = append(cells, []string{r.app, r.name, r.kind, r.target, r.state})
+		}
+
+		writeTable(&b, []string{"Application", "Entry", "Kind", "Target", "State"}, cells)
+	}
+
+	return strings.TrimRight(b.String(), "\n") + "\n"
+}
+
+// Redact replaces plat's hostname and user name in report with
+// RedactedHostname and RedactedUser. Only whole words are replaced, so a user
+// named "al" does not mangle "local"; paths under the home directory become
+// e.g. /home/<user>/.config.
+func Redact(report string, plat *platform.Platform) string {
+	report = replaceWord(report, plat.Hostname, RedactedHostname)
+	return replaceWord(report, plat.User, RedactedUser)
+}
+
+// replaceWord replaces the case-insensitive whole-word occurrences of word in
+// s with repl.
+func replaceWord(s, word, repl string) string {
+	if word == "" {
+		return s
+	}
+
+	re := regexp.MustCompile(`(?i)\b` + regexp.QuoteMeta(word) + `\b`)
+
+	return re.ReplaceAllLiteralString(s, repl)
+}
+
+// writeSummary writes the prose summary: how many entries there are and how
+// many need attention.
+func writeSummary(b *strings.Builder, cfg *config.Config, apps []config.Application, rows []entryRow) {
+	counts := make(map[string]int)
+	for _, r := range rows {
+		counts[r.state]++
+	}
+
+	fmt.Fprintf(b, "%d of %d applications in the configuration apply to this machine, with %d entries, %d of them linked.",
+		len(apps), len(cfg.Applications), len(rows), counts[tuitable.StateLinked.String()])
+
+	var attention []string
+
+	for _, state := range []string{
+		stateBroken,
+		tuitable.StateOutdated.String(),
+		tuitable.StateModified.String(),
+		tuitable.StateDirty.String(),
+		tuitable.StateSetupNeeded.String(),
+		tuitable.StatePartial.String(),
+	} {
+		if n := counts[state]; n > 0 {
+			attention = append(attention, fmt.Sprintf("%d %s", n, strings.ToLower(state)))
+		}
+	}
+
+	if len(attention) > 0 {
+		fmt.Fprintf(b, " Needing attention: %s.", strings.Join(attention, ", "))
+	}
+
+	b.WriteString("\n\n")
+}
+
+// writePackageManagers lists the package managers found in PATH.
+func writePackageManagers(b *strings.Builder) {
+	available := platform.DetectAvailableManagers()
+	if len(available) == 0 {
+		b.WriteString("No supported package manager was found in PATH.\n\n")
+		return
+	}
+
+	fmt.Fprintf(b, "Available: %s.\n\n", strings.Join(available, ", "))
+}
+
+// entryRows returns the entries of apps that apply to plat, with their state.
+func entryRows(cfg *config.Config, mgr *manager.Manager, plat *platform.Platform, apps []config.Application) []entryRow {
+	engine := tmpl.NewEngine(tmpl.NewContextFromPlatform(plat).WithConfig(cfg))
+
+	dirty, _ := mgr.DirtyBackupFiles() //nolint:errcheck // the report just leaves out dirty states
+
+	var rows []entryRow
+
+	for _, app := range apps {
+		for _, entry := range app.Entries {
+			row := entryRow{app: app.Name, name: entry.Name, kind: entryKind(entry)}
+
+			if entry.IsSetup() {
+				if entry.GetRun(plat.OS) == "" {
+					continue
+				}
+
+				row.target = "-"
+				row.state = setupState(mgr, app.Name, entry)
+				rows = append(rows, row)
+
+				continue
+			}
+
+			target := entry.GetTarget(plat.OS)
+			if target == "" {
+				continue
+			}
+
+			row.target = config.ExpandPathWithTemplate(target, plat.EnvVars, engine)
+
+			if !entry.IsEnabled() {
+				row.state = tuitable.StateDisabled.String()
+			} else {
+				backupPath := config.ResolveBackupPath(entry.Backup, cfg.BackupRoot, plat.EnvVars, engine)
+				row.state = configState(mgr, entry, backupPath, row.target, dirty)
+			}
+
+			rows = append(rows, row)
+		}
+	}
+
+	return rows
+}
+
+// entryKind describes how an entry is deployed.
+func entryKind(entry config.SubEntry) string {
+	switch {
+	case entry.IsSetup():
+		return "setup"
+	case entry.IsFolder():
+		return entry.EffectiveMethod() + ", folder"
+	default:
+		return fmt.Sprintf("%s, %d file(s)", entry.EffectiveMethod(), len(entry.Files))
+	}
+}
+
+// setupState reports whether the check command of a setup entry of
+// application appName passes.
+func setupState(mgr *manager.Manager, appName string, entry config.SubEntry) string {
+	if !entry.IsEnabled() {
+		return tuitable.StateDisabled.String()
+	}
+
+	if mgr.IsSetupApplied(appName, entry) {
+		return tuitable.StateSetupOk.String()
+	}
+
+	return tuitable.StateSetupNeeded.String()
+}
+
+// configState returns the state the TUI would show for a config entry, or
+// stateBroken when it is linked but a link no longer resolves.
+func configState(mgr *manager.Manager, entry config.SubEntry, backupPath, target string, dirty map[string]bool) string {
+	st := detection.DetectSubEntryState(entry, backupPath, target)
+	if st != tuitable.StateLinked {
+		return st.String()
+	}
+
+	if isBroken(entry, backupPath, target) {
+		return stateBroken
+	}
+
+	if entry.IsFolder() {
+		if mgr.HasOutdatedTemplates(backupPath) {
+			return tuitable.StateOutdated.String()
+		}
+
+		if mgr.HasModifiedRenderedFiles(backupPath) {
+			return tuitable.StateModified.String()
+		}
+	}
+
+	if manager.EntryIsDirty(entry, backupPath, dirty) {
+		return tuitable.StateDirty.String()
+	}
+
+	return st.String()
+}
+
+// isBroken reports whether the target of a linked entry, or one of its
+// files, cannot be resolved.
+func isBroken(entry config.SubEntry, backupPath, target string) bool {
+	files := entry.Files
+
+	switch {
+	case entry.LinksFiles():
+		files, _ = manager.LinkFarmFiles(fsys.OsFS{}, backupPath) //nolint:errcheck // an unreadable backup has no links to check
+	case entry.IsFolder():
+		_, err := os.Stat(platform.LongPath(target))
+		return err != nil
+	}
+
+	for _, file := range files {
+		if _, err := os.Stat(platform.LongPath(filepath.Join(target, file))); err != nil {
+			return true
+		}
+	}
+
+	return false
+}
+
+// writeTable writes a Markdown table with a blank line after it.
+func writeTable(b *strings.Builder, header []string, rows [][]string) {
+	writeTableRow(b, header)
+
+	sep := make([]string, len(header))
+	for i := range sep {
+		sep[i] = "---"
+	}
+
+	writeTableRow(b, sep)
+
+	for _, row := range rows {
+		writeTableRow(b, row)
+	}
+
+	b.WriteString("\n")
+}
+
+func writeTableRow(b *strings.Builder, cells []string) {
+	escaped := make([]string, len(cells))
+	for i, c := range cells {
+		escaped[i] = strings.ReplaceAll(c, "|", `\|`)
+	}
+
+	b.WriteString("| " + strings.Join(escaped, " | ") + " |\n")
+}
+
+func orDash(s string) string {
+	if s == "" {
+		return "-"
+	}
+
+	return s
+}
@@ -0,0 +1,109 @@
+package report
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/AntoineGS/tidydots/internal/config"
+	"github.com/AntoineGS/tidydots/internal/manager"
+	"github.com/AntoineGS/tidydots/internal/platform"
+)
+
+func TestGenerate(t *testing.T) {
+	root := t.TempDir()
+	home := t.TempDir()
+
+	for _, dir := range []string{"nvim", "zsh"} {
+		if err := os.MkdirAll(filepath.Join(root, dir), 0o755); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := os.WriteFile(filepath.Join(root, "zsh", ".zshrc"), []byte("x"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := os.Symlink(filepath.Join(root, "nvim"), filepath.Join(home, "nvim")); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := os.Symlink(filepath.Join(root, "gone"), filepath.Join(home, "gone")); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := &config.Config{
+		Version:    3,
+		BackupRoot: root,
+		Applications: []config.Application{
+			{Name: "neovim", Entries: []config.SubEntry{
+				{Name: "config", Backup: "./nvim", Targets: map[string]string{"linux": filepath.Join(home, "nvim")}},
+			}},
+			{Name: "zsh", Entries: []config.SubEntry{
+				{Name: "rc", Backup: "./zsh", Files: []string{".zshrc"}, Targets: map[string]string{"linux": home}},
+				{Name: "plugins", Run: map[string]string{"linux": "true"}},
+				{Name: "fonts", Run: map[string]string{"windows": "exit 1"}},
+			}},
+			{Name: "old", Entries: []config.SubEntry{
+				{Name: "config", Backup: "./gone", Targets: map[string]string{"linux": filepath.Join(home, "gone")}},
+			}},
+			{Name: "windows-only", Entries: []config.SubEntry{
+				{Name: "config", Backup: "./win", Targets: map[string]string{"windows": `C:\win`}},
+			}},
+		},
+	}
+
+	plat := &platform.Platform{OS: platform.OSLinux, Hostname: "box", User: "alice", EnvVars: map[string]string{}}
+	mgr := manager.New(cfg, plat)
+	mgr.Version = "1.2.3"
+
+	got := Generate(cfg, mgr, plat)
+
+	for _, want := range []string{
+		"4 of 4 applications in the configuration apply to this machine, with 4 entries, 1 of them linked. Needing attention: 1 broken.",
+		"| tidydots version | 1.2.3 |",
+		"| Hostname | box |",
+		"| Config file | " + filepath.Join(root, "tidydots.yaml") + " |",
+		"| zsh | 3 | no |",
+		"| neovim | config | symlink, folder | " + filepath.Join(home, "nvim") + " | Linked |",
+		"| zsh | rc | symlink, 1 file(s) | " + home + " | Ready |",
+		"| zsh | plugins | setup | - | Set up |",
+		"| old | config | symlink, folder | " + filepath.Join(home, "gone") + " | Broken |",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("report does not contain %q:\n%s", want, got)
+		}
+	}
+
+	for _, absent := range []string{`C:\win`, "fonts"} {
+		if strings.Contains(got, absent) {
+			t.Errorf("report mentions %q, which does not apply to linux:\n%s", absent, got)
+		}
+	}
+}
+
+func TestRedact(t *testing.T) {
+	plat := &platform.Platform{Hostname: "Box", User: "al"}
+
+	got := Redact("| Hostname | box |\n| User | al |\n/home/al/.config on local", plat)
+	want := "| Hostname | <hostname> |\n| User | <user> |\n/home/<user>/.config on local"
+
+	if got != want {
+		t.Errorf("Redact() = %q, want %q", got, want)
+	}
+
+	if got := Redact("unchanged", &platform.Platform{}); got != "unchanged" {
+		t.Errorf("Redact() with no hostname or user = %q", got)
+	}
+}
+
+func TestWriteTableRow_EscapesPipes(t *testing.T) {
+	var b strings.Builder
+
+	writeTableRow(&b, []string{"a|b", "c"})
+
+	if got := b.String(); got != "| a\\|b | c |\n" {
+		t.Errorf("writeTableRow() = %q", got)
+	}
+}
@@ -0,0 +1,321 @@
+// Package stash saves deployed target files that were edited in place, so a
+// restore can replace them and the edits can be put back later, the way `git
+// stash` sets changes aside. Each stash is a directory named after the time
+// it was pushed, holding copies of the files and a manifest.yaml that records
+// where each one came from. The manifest is plain YAML and can be edited by
+// hand, e.g. to leave a file out of a pop.
+package stash
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ManifestName is the name of the manifest in each stash directory.
+const ManifestName = "manifest.yaml"
+
+// idLayout formats the time a stash was pushed into its ID.
+const idLayout = "20060102-150405"
+
+// ErrNoStash is returned when there is no stash to pop, or none with the
+// requested ID.
+var ErrNoStash = errors.New("no stash")
+
+// File is one stashed target.
+type File struct {
+	App    string `yaml:"app"`
+	Entry  string `yaml:"entry"`
+	Path   string `yaml:"path"`   // where the target was, and where Pop puts it back
+	Stored string `yaml:"stored"` // the copy, slash-separated and relative to the stash directory
+}
+
+// Stash is a set of files pushed together.
+type Stash struct {
+	Created time.Time `yaml:"created"`
+	ID      string    `yaml:"-"` // name of the stash directory
+	Files   []File    `yaml:"files"`
+}
+
+// Store is a directory of stashes.
+type Store struct {
+	now func() time.Time
+	Dir string
+}
+
+// New returns the Store of stashes in dir. The directory is created by the
+// first Push.
+func New(dir string) *Store {
+	return &Store{Dir: dir, now: time.Now}
+}
+
+// DefaultDir returns ~/.local/share/tidydots/stash.
+func DefaultDir() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("getting home directory: %w", err)
+	}
+
+	return filepath.Join(home, ".local", "share", "tidydots", "stash"), nil
+}
+
+// Push copies the files, or directories, at the paths of files into a new
+// stash and returns it. The originals are left in place. Stored is filled in
+// on the returned files. Nothing is kept when a copy fails.
+func (s *Store) Push(files []File) (*Stash, error) {
+	if len(files) == 0 {
+		return nil, errors.New("nothing to stash")
+	}
+
+	created := s.now()
+
+	id, err := s.newID(created)
+	if err != nil {
+		return nil, err
+	}
+
+	dir := filepath.Join(s.Dir, id)
+	st := &Stash{ID: id, Created: created.Truncate(time.Second), Files: make([]File, 0, len(files))}
+
+	for i, f := range files {
+		f.Stored = "files/" + strconv.Itoa(i) + "/" + filepath.Base(f.Path)
+
+		if err := copyPath(f.Path, filepath.Join(dir, filepath.FromSlash(f.Stored))); err != nil {
+			_ = os.RemoveAll(dir) //nolint:errcheck // best-effort cleanup of the partial stash
+			return nil, fmt.Errorf("stashing %s: %w", f.Path, err)
+		}
+
+		st.Files = append(st.Files, f)
+	}
+
+	data, err := yaml.Marshal(st)
+	if err != nil {
+		_ = os.RemoveAll(dir) //nolint:errcheck // best-effort cleanup of the partial stash
+		return nil, err
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, ManifestName), data, 0o600); err != nil {
+		_ = os.RemoveAll(dir) //nolint:errcheck // best-effort cleanup of the partial stash
+		return nil, fmt.Errorf("writing manifest: %w", err)
+	}
+
+	return st, nil
+}
+
+// newID creates the directory of a stash pushed at t and returns its name.
+// A second stash in the same second gets a -2 suffix, and so on.
+func (s *Store) newID(t time.Time) (string, error) {
+	if err := os.MkdirAll(s.Dir, 0o700); err != nil {
+		return "", fmt.Errorf("creating stash directory: %w", err)
+	}
+
+	base := t.Format(idLayout)
+
+	for n := 1; ; n++ {
+		id := base
+		if n > 1 {
+			id += "-" + strconv.Itoa(n)
+		}
+
+		err := os.Mkdir(filepath.Join(s.Dir, id), 0o700)
+		if err == nil {
+			return id, nil
+		}
+
+		if !errors.Is(err, fs.ErrExist) {
+			return "", fmt.Errorf("creating stash: %w", err)
+		}
+	}
+}
+
+// List returns the stashes, newest first.
+func (s *Store) List() ([]Stash, error) {
+	dirs, err := os.ReadDir(s.Dir)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("reading stash directory: %w", err)
+	}
+
+	var stashes []Stash
+
+	for _, d := range dirs {
+		if !d.IsDir() {
+			continue
+		}
+
+		st, err := s.load(d.Name())
+		if err != nil {
+			return nil, err
+		}
+
+		stashes = append(stashes, *st)
+	}
+
+	slices.SortFunc(stashes, func(a, b Stash) int {
+		return cmp.Or(b.Created.Compare(a.Created), cmp.Compare(b.ID, a.ID))
+	})
+
+	return stashes, nil
+}
+
+// Get returns the stash with id, or the newest one when id is empty.
+func (s *Store) Get(id string) (*Stash, error) {
+	if id != "" {
+		if !filepath.IsLocal(id) {
+			return nil, fmt.Errorf("%w %q", ErrNoStash, id)
+		}
+
+		if _, err := os.Stat(filepath.Join(s.Dir, id, ManifestName)); errors.Is(err, fs.ErrNotExist) {
+			return nil, fmt.Errorf("%w %q", ErrNoStash, id)
+		}
+
+		return s.load(id)
+	}
+
+	stashes, err := s.List()
+	if err != nil {
+		return nil, err
+	}
+
+	if len(stashes) == 0 {
+		return nil, ErrNoStash
+	}
+
+	return &stashes[0], nil
+}
+
+// load reads the manifest of the stash with id.
+func (s *Store) load(id string) (*Stash, error) {
+	path := filepath.Join(s.Dir, id, ManifestName)
+
+	data, err := os.ReadFile(path) //nolint:gosec // manifest of a stash in the store
+	if err != nil {
+		return nil, fmt.Errorf("reading stash %s: %w", id, err)
+	}
+
+	var st Stash
+	if err := yaml.Unmarshal(data, &st); err != nil {
+		return nil, fmt.Errorf("parsing %s: %w", path, err)
+	}
+
+	st.ID = id
+
+	return &st, nil
+}
+
+// Pop puts the files of the stash with id, or of the newest stash when id is
+// empty, back where they were, then drops the stash. A symlink at a file's
+// path, such as the one a restore deployed, is replaced. Anything else there
+// fails the pop before any file is moved, so nothing is overwritten.
+func (s *Store) Pop(id string) (*Stash, error) {
+	st, err := s.Get(id)
+	if err != nil {
+		return nil, err
+	}
+
+	dir := filepath.Join(s.Dir, st.ID)
+
+	for _, f := range st.Files {
+		if !filepath.IsLocal(filepath.FromSlash(f.Stored)) {
+			return nil, fmt.Errorf("stash %s: stored path %q is not inside the stash", st.ID, f.Stored)
+		}
+
+		info, err := os.Lstat(f.Path)
+		if err == nil && info.Mode()&fs.ModeSymlink == 0 {
+			return nil, fmt.Errorf("%s exists; move it away before popping stash %s", f.Path, st.ID)
+		} else if err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return nil, err
+		}
+	}
+
+	for _, f := range st.Files {
+		if err := os.Remove(f.Path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return nil, fmt.Errorf("removing %s: %w", f.Path, err)
+		}
+
+		if err := copyPath(filepath.Join(dir, filepath.FromSlash(f.Stored)), f.Path); err != nil {
+			return nil, fmt.Errorf("restoring %s: %w", f.Path, err)
+		}
+	}
+
+	if _, err := s.Drop(st.ID); err != nil {
+		return nil, err
+	}
+
+	return st, nil
+}
+
+// Drop deletes the stash with id, or the newest stash when id is empty, and
+// returns it.
+func (s *Store) Drop(id string) (*Stash, error) {
+	st, err := s.Get(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := os.RemoveAll(filepath.Join(s.Dir, st.ID)); err != nil {
+		return nil, fmt.Errorf("dropping stash %s: %w", st.ID, err)
+	}
+
+	return st, nil
+}
+
+// copyPath copies the file, directory tree or symlink at src to dst, keeping
+// permissions. Symlinks are copied as symlinks.
+func copyPath(src, dst string) error {
+	return filepath.WalkDir(src, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		rel, err := filepath.Rel(src, path)
+		if err != nil {
+			return err
+		}
+
+		target := filepath.Join(dst, rel)
+
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+
+		switch {
+		case d.IsDir():
+			return os.MkdirAll(target, info.Mode().Perm()|0o700)
+		case d.Type()&fs.ModeSymlink != 0:
+			link, err := os.Readlink(path)
+			if err != nil {
+				return err
+			}
+
+			if err := os.MkdirAll(filepath.Dir(target), 0o700); err != nil {
+				return err
+			}
+
+			return os.Symlink(link, target)
+		default:
+			data, err := os.ReadFile(path) //nolint:gosec // stashed target or its copy
+			if err != nil {
+				return err
+			}
+
+			if err := os.MkdirAll(filepath.Dir(target), 0o700); err != nil {
+				return err
+			}
+
+			return os.WriteFile(target, data, info.Mode().Perm())
+		}
+	})
+}
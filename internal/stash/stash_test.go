@@ -0,0 +1,228 @@
+package stash
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// writeFile writes content to path, creating its directory.
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func readFile(t *testing.T, path string) string {
+	t.Helper()
+
+	data, err := os.ReadFile(path) //nolint:gosec // test file
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return string(data)
+}
+
+// newStore returns a store in a temporary directory whose clock starts at a
+// fixed time and moves one minute per Push.
+func newStore(t *testing.T) *Store {
+	t.Helper()
+
+	s := New(t.TempDir())
+	now := time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC)
+	s.now = func() time.Time {
+		now = now.Add(time.Minute)
+		return now
+	}
+
+	return s
+}
+
+func TestPushPop(t *testing.T) {
+	s := newStore(t)
+	home := t.TempDir()
+
+	rc := filepath.Join(home, ".zshrc")
+	nvim := filepath.Join(home, "nvim")
+
+	writeFile(t, rc, "edited rc")
+	writeFile(t, filepath.Join(nvim, "lua", "plugins.lua"), "edited plugins")
+
+	st, err := s.Push([]File{{App: "zsh", Entry: "rc", Path: rc}, {App: "neovim", Entry: "config", Path: nvim}})
+	if err != nil {
+		t.Fatalf("Push() error = %v", err)
+	}
+
+	if st.ID != "20260301-090100" || len(st.Files) != 2 || st.Files[1].Stored != "files/1/nvim" {
+		t.Errorf("Push() = %+v, want ID 20260301-090100 with nvim stored as files/1/nvim", st)
+	}
+
+	if !strings.Contains(readFile(t, filepath.Join(s.Dir, st.ID, ManifestName)), "path: "+rc) {
+		t.Errorf("manifest does not record %s", rc)
+	}
+
+	// A restore puts links in place of the edited targets.
+	for _, path := range []string{rc, nvim} {
+		if err := os.RemoveAll(path); err != nil {
+			t.Fatal(err)
+		}
+
+		if err := os.Symlink("/backup", path); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	popped, err := s.Pop("")
+	if err != nil {
+		t.Fatalf("Pop() error = %v", err)
+	}
+
+	if popped.ID != st.ID {
+		t.Errorf("Pop() popped %s, want %s", popped.ID, st.ID)
+	}
+
+	if got := readFile(t, rc); got != "edited rc" {
+		t.Errorf(".zshrc = %q after Pop(), want %q", got, "edited rc")
+	}
+
+	if got := readFile(t, filepath.Join(nvim, "lua", "plugins.lua")); got != "edited plugins" {
+		t.Errorf("plugins.lua = %q after Pop(), want %q", got, "edited plugins")
+	}
+
+	if stashes, err := s.List(); err != nil || len(stashes) != 0 {
+		t.Errorf("List() after Pop() = %v, %v; want no stashes", stashes, err)
+	}
+}
+
+func TestPop_RefusesToOverwrite(t *testing.T) {
+	s := newStore(t)
+	home := t.TempDir()
+	rc := filepath.Join(home, ".zshrc")
+	env := filepath.Join(home, ".zshenv")
+
+	writeFile(t, rc, "stashed rc")
+	writeFile(t, env, "stashed env")
+
+	if _, err := s.Push([]File{{Path: rc}, {Path: env}}); err != nil {
+		t.Fatalf("Push() error = %v", err)
+	}
+
+	// .zshenv was removed, but .zshrc was edited again.
+	if err := os.Remove(env); err != nil {
+		t.Fatal(err)
+	}
+
+	writeFile(t, rc, "newer rc")
+
+	if _, err := s.Pop(""); err == nil || !strings.Contains(err.Error(), rc) {
+		t.Fatalf("Pop() error = %v, want one naming %s", err, rc)
+	}
+
+	if got := readFile(t, rc); got != "newer rc" {
+		t.Errorf(".zshrc = %q after a failed Pop(), want it untouched", got)
+	}
+
+	if _, err := os.Lstat(env); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf(".zshenv was restored by a failed Pop(): %v", err)
+	}
+
+	if stashes, _ := s.List(); len(stashes) != 1 {
+		t.Errorf("List() after a failed Pop() = %d stashes, want 1", len(stashes))
+	}
+}
+
+func TestListGetDrop(t *testing.T) {
+	s := newStore(t)
+	rc := filepath.Join(t.TempDir(), ".zshrc")
+	writeFile(t, rc, "rc")
+
+	var ids []string
+
+	for range 3 {
+		st, err := s.Push([]File{{Path: rc}})
+		if err != nil {
+			t.Fatalf("Push() error = %v", err)
+		}
+
+		ids = append(ids, st.ID)
+	}
+
+	stashes, err := s.List()
+	if err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+
+	if len(stashes) != 3 || stashes[0].ID != ids[2] || stashes[2].ID != ids[0] {
+		t.Fatalf("List() = %+v, want %v newest first", stashes, ids)
+	}
+
+	if st, err := s.Get(ids[1]); err != nil || st.ID != ids[1] {
+		t.Errorf("Get(%q) = %+v, %v", ids[1], st, err)
+	}
+
+	if dropped, err := s.Drop(""); err != nil || dropped.ID != ids[2] {
+		t.Errorf("Drop(\"\") = %+v, %v; want the newest stash %s", dropped, err, ids[2])
+	}
+
+	if _, err := s.Drop(ids[0]); err != nil {
+		t.Errorf("Drop(%q) error = %v", ids[0], err)
+	}
+
+	for _, id := range []string{ids[0], "missing", "../escape"} {
+		if _, err := s.Get(id); !errors.Is(err, ErrNoStash) {
+			t.Errorf("Get(%q) error = %v, want ErrNoStash", id, err)
+		}
+	}
+
+	if _, err := New(t.TempDir()).Pop(""); !errors.Is(err, ErrNoStash) {
+		t.Errorf("Pop() on an empty store error = %v, want ErrNoStash", err)
+	}
+}
+
+func TestPush_SameSecond(t *testing.T) {
+	s := New(t.TempDir())
+	s.now = func() time.Time { return time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC) }
+
+	rc := filepath.Join(t.TempDir(), ".zshrc")
+	writeFile(t, rc, "rc")
+
+	first, err := s.Push([]File{{Path: rc}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	second, err := s.Push([]File{{Path: rc}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if first.ID != "20260301-090000" || second.ID != "20260301-090000-2" {
+		t.Errorf("IDs = %s, %s; want 20260301-090000 and 20260301-090000-2", first.ID, second.ID)
+	}
+
+	if stashes, _ := s.List(); len(stashes) != 2 || stashes[0].ID != second.ID {
+		t.Errorf("List() = %+v, want %s first", stashes, second.ID)
+	}
+}
+
+func TestPush_MissingPathKeepsNothing(t *testing.T) {
+	s := newStore(t)
+
+	if _, err := s.Push([]File{{Path: filepath.Join(t.TempDir(), "missing")}}); err == nil {
+		t.Fatal("Push() of a missing path succeeded")
+	}
+
+	if stashes, err := s.List(); err != nil || len(stashes) != 0 {
+		t.Errorf("List() after a failed Push() = %v, %v; want no stashes", stashes, err)
+	}
+}
@@ -1,4 +1,5 @@
-// Package state manages template render history in a SQLite database.
+// Package state manages template render and operation history in a SQLite
+// database.
 package state
 
 import (
@@ -24,6 +25,24 @@ type RenderRecord struct {
 	PlatformHost string
 }
 
+// Operation names recorded in the operation history.
+const (
+	OpBackup  = "backup"
+	OpRestore = "restore"
+)
+
+// OperationRecord is the last successful run of an operation on one entry on
+// one machine. Recording an operation again replaces the previous record.
+type OperationRecord struct {
+	RanAt        time.Time
+	AppName      string
+	EntryName    string
+	Operation    string
+	Version      string // tidydots version that ran the operation
+	PlatformOS   string
+	PlatformHost string
+}
+
 // Store manages the SQLite database for template render history.
 type Store struct {
 	db *sql.DB
@@ -186,12 +205,187 @@ func (s *Store) RemoveTemplate(ctx context.Context, templatePath string) error {
 	return nil
 }
 
+// RecordOperation stores r as the last run of its operation on its entry,
+// replacing any earlier record for the same entry, operation and machine.
+func (s *Store) RecordOperation(ctx context.Context, r OperationRecord) error {
+	_, err := s.db.ExecContext(ctx, `
+		INSERT INTO entry_operations (app_name, entry_name, operation, platform_os, platform_host, ran_at, version)
+		VALUES (?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT (app_name, entry_name, operation, platform_os, platform_host)
+		DO UPDATE SET ran_at = excluded.ran_at, version = excluded.version
+	`, r.AppName, r.EntryName, r.Operation, r.PlatformOS, r.PlatformHost, r.RanAt.UTC().Format(time.RFC3339), r.Version)
+	if err != nil {
+		return fmt.Errorf("recording operation: %w", err)
+	}
+
+	return nil
+}
+
+// GetOperations returns the operation records of every entry on the specified
+// platform (OS + hostname).
+func (s *Store) GetOperations(ctx context.Context, platformOS, platformHost string) ([]OperationRecord, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT app_name, entry_name, operation, ran_at, version, platform_os, platform_host
+		FROM entry_operations
+		WHERE platform_os = ? AND platform_host = ?
+	`, platformOS, platformHost)
+	if err != nil {
+		return nil, fmt.Errorf("querying operations: %w", err)
+	}
+	defer func() { _ = rows.Close() }() //nolint:errcheck,gosec // defer close is best-effort
+
+	var records []OperationRecord
+	for rows.Next() {
+		var r OperationRecord
+		var ranAt string
+
+		if err := rows.Scan(&r.AppName, &r.EntryName, &r.Operation, &ranAt, &r.Version, &r.PlatformOS, &r.PlatformHost); err != nil {
+			return nil, fmt.Errorf("scanning operation record: %w", err)
+		}
+
+		r.RanAt, err = parseTime(ranAt)
+		if err != nil {
+			return nil, fmt.Errorf("parsing ran_at: %w", err)
+		}
+
+		records = append(records, r)
+	}
+
+	return records, rows.Err()
+}
+
+// RecordJournal adds r, an entry the running operation completed, to the
+// journal of r.Operation on r's machine. A run that is interrupted leaves its
+// journal behind, so that the next run can resume it.
+func (s *Store) RecordJournal(ctx context.Context, r OperationRecord) error {
+	_, err := s.db.ExecContext(ctx, `
+		INSERT INTO run_journal (operation, platform_os, platform_host, app_name, entry_name, completed_at)
+		VALUES (?, ?, ?, ?, ?, ?)
+		ON CONFLICT (operation, platform_os, platform_host, app_name, entry_name)
+		DO UPDATE SET completed_at = excluded.completed_at
+	`, r.Operation, r.PlatformOS, r.PlatformHost, r.AppName, r.EntryName, r.RanAt.UTC().Format(time.RFC3339))
+	if err != nil {
+		return fmt.Errorf("recording journal entry: %w", err)
+	}
+
+	return nil
+}
+
+// GetJournal returns the journal of op on the specified platform (OS +
+// hostname): the entries its last run completed, unless that run cleared it.
+func (s *Store) GetJournal(ctx context.Context, op, platformOS, platformHost string) ([]OperationRecord, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT app_name, entry_name, completed_at
+		FROM run_journal
+		WHERE operation = ? AND platform_os = ? AND platform_host = ?
+		ORDER BY completed_at, app_name, entry_name
+	`, op, platformOS, platformHost)
+	if err != nil {
+		return nil, fmt.Errorf("querying journal: %w", err)
+	}
+	defer func() { _ = rows.Close() }() //nolint:errcheck,gosec // defer close is best-effort
+
+	var records []OperationRecord
+	for rows.Next() {
+		r := OperationRecord{Operation: op, PlatformOS: platformOS, PlatformHost: platformHost}
+		var completedAt string
+
+		if err := rows.Scan(&r.AppName, &r.EntryName, &completedAt); err != nil {
+			return nil, fmt.Errorf("scanning journal entry: %w", err)
+		}
+
+		r.RanAt, err = parseTime(completedAt)
+		if err != nil {
+			return nil, fmt.Errorf("parsing completed_at: %w", err)
+		}
+
+		records = append(records, r)
+	}
+
+	return records, rows.Err()
+}
+
+// ClearJournal deletes the journal of op on the specified platform.
+func (s *Store) ClearJournal(ctx context.Context, op, platformOS, platformHost string) error {
+	_, err := s.db.ExecContext(ctx, `
+		DELETE FROM run_journal WHERE operation = ? AND platform_os = ? AND platform_host = ?
+	`, op, platformOS, platformHost)
+	if err != nil {
+		return fmt.Errorf("clearing journal: %w", err)
+	}
+
+	return nil
+}
+
+// SetLinkedFiles replaces the files recorded as linked by the link_mode: files
+// entry entryName of appName on the specified platform with files, paths
+// relative to the entry's target. An empty files clears the record.
+func (s *Store) SetLinkedFiles(ctx context.Context, appName, entryName, platformOS, platformHost string, files []string) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("recording linked files: %w", err)
+	}
+
+	if _, err := tx.ExecContext(ctx, `
+		DELETE FROM linked_files
+		WHERE app_name = ? AND entry_name = ? AND platform_os = ? AND platform_host = ?
+	`, appName, entryName, platformOS, platformHost); err != nil {
+		_ = tx.Rollback() //nolint:errcheck,gosec // rollback best-effort
+		return fmt.Errorf("clearing linked files: %w", err)
+	}
+
+	for _, file := range files {
+		if _, err := tx.ExecContext(ctx, `
+			INSERT OR IGNORE INTO linked_files (app_name, entry_name, platform_os, platform_host, file)
+			VALUES (?, ?, ?, ?, ?)
+		`, appName, entryName, platformOS, platformHost, file); err != nil {
+			_ = tx.Rollback() //nolint:errcheck,gosec // rollback best-effort
+			return fmt.Errorf("recording linked file %s: %w", file, err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("recording linked files: %w", err)
+	}
+
+	return nil
+}
+
+// GetLinkedFiles returns the files SetLinkedFiles last recorded for the entry,
+// sorted, or nil when none are.
+func (s *Store) GetLinkedFiles(ctx context.Context, appName, entryName, platformOS, platformHost string) ([]string, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT file FROM linked_files
+		WHERE app_name = ? AND entry_name = ? AND platform_os = ? AND platform_host = ?
+		ORDER BY file
+	`, appName, entryName, platformOS, platformHost)
+	if err != nil {
+		return nil, fmt.Errorf("querying linked files: %w", err)
+	}
+	defer func() { _ = rows.Close() }() //nolint:errcheck,gosec // defer close is best-effort
+
+	var files []string
+	for rows.Next() {
+		var file string
+		if err := rows.Scan(&file); err != nil {
+			return nil, fmt.Errorf("scanning linked file: %w", err)
+		}
+
+		files = append(files, file)
+	}
+
+	return files, rows.Err()
+}
+
 // migrate runs schema migrations.
 func (s *Store) migrate(ctx context.Context) error {
 	currentVersion := s.getSchemaVersion(ctx)
 
 	migrations := []func(context.Context, *sql.Tx) error{
 		migrateV1,
+		migrateV2,
+		migrateV3,
+		migrateV4,
 	}
 
 	for i := currentVersion; i < len(migrations); i++ {
@@ -282,3 +476,58 @@ func migrateV1(ctx context.Context, tx *sql.Tx) error {
 
 	return nil
 }
+
+// migrateV2 adds the operation history. Stores created before it simply have
+// no history: every entry reads as never backed up or restored.
+func migrateV2(ctx context.Context, tx *sql.Tx) error {
+	_, err := tx.ExecContext(ctx, `CREATE TABLE IF NOT EXISTS entry_operations (
+		app_name      TEXT NOT NULL,
+		entry_name    TEXT NOT NULL,
+		operation     TEXT NOT NULL,
+		platform_os   TEXT NOT NULL,
+		platform_host TEXT NOT NULL,
+		ran_at        DATETIME NOT NULL,
+		version       TEXT NOT NULL DEFAULT '',
+		PRIMARY KEY (app_name, entry_name, operation, platform_os, platform_host)
+	)`)
+	if err != nil {
+		return fmt.Errorf("creating entry_operations: %w", err)
+	}
+
+	return nil
+}
+
+// migrateV3 adds the journal of interrupted runs.
+func migrateV3(ctx context.Context, tx *sql.Tx) error {
+	_, err := tx.ExecContext(ctx, `CREATE TABLE IF NOT EXISTS run_journal (
+		operation     TEXT NOT NULL,
+		platform_os   TEXT NOT NULL,
+		platform_host TEXT NOT NULL,
+		app_name      TEXT NOT NULL,
+		entry_name    TEXT NOT NULL,
+		completed_at  DATETIME NOT NULL,
+		PRIMARY KEY (operation, platform_os, platform_host, app_name, entry_name)
+	)`)
+	if err != nil {
+		return fmt.Errorf("creating run_journal: %w", err)
+	}
+
+	return nil
+}
+
+// migrateV4 adds the files linked by link_mode: files entries.
+func migrateV4(ctx context.Context, tx *sql.Tx) error {
+	_, err := tx.ExecContext(ctx, `CREATE TABLE IF NOT EXISTS linked_files (
+		app_name      TEXT NOT NULL,
+		entry_name    TEXT NOT NULL,
+		platform_os   TEXT NOT NULL,
+		platform_host TEXT NOT NULL,
+		file          TEXT NOT NULL,
+		PRIMARY KEY (app_name, entry_name, platform_os, platform_host, file)
+	)`)
+	if err != nil {
+		return fmt.Errorf("creating linked_files: %w", err)
+	}
+
+	return nil
+}
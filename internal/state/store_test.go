@@ -2,8 +2,11 @@ package state
 
 import (
 	"context"
+	"database/sql"
 	"path/filepath"
+	"strings"
 	"testing"
+	"time"
 )
 
 const testTemplate = "test.tmpl"
@@ -27,14 +30,14 @@ func TestOpen_CreatesDBAndSchema(t *testing.T) {
 	}
 	defer func() { _ = store.Close() }() //nolint:errcheck // cleanup is best-effort
 
-	// Should have schema_version table with version 1
+	// Should have schema_version table with version 4
 	var version int
 	ctx := context.Background()
 	if err := store.db.QueryRowContext(ctx, `SELECT version FROM schema_version`).Scan(&version); err != nil {
 		t.Fatalf("failed to read schema version: %v", err)
 	}
-	if version != 1 {
-		t.Errorf("schema version = %d, want 1", version)
+	if version != 4 {
+		t.Errorf("schema version = %d, want 4", version)
 	}
 }
 
@@ -59,8 +62,8 @@ func TestOpen_Idempotent(t *testing.T) {
 	if err := store2.db.QueryRowContext(ctx, `SELECT version FROM schema_version`).Scan(&version); err != nil {
 		t.Fatalf("failed to read schema version: %v", err)
 	}
-	if version != 1 {
-		t.Errorf("schema version = %d, want 1", version)
+	if version != 4 {
+		t.Errorf("schema version = %d, want 4", version)
 	}
 }
 
@@ -317,19 +320,19 @@ func TestGetLatestRender_FiltersByPlatform(t *testing.T) {
 	}
 }
 
-func TestSchemaMigration_Version0To1(t *testing.T) {
+func TestSchemaMigration_Version0To4(t *testing.T) {
 	dbPath := filepath.Join(t.TempDir(), ".tidydots.db")
 	ctx := context.Background()
 
-	// Open creates schema from scratch (version 0 -> 1)
+	// Open creates schema from scratch (version 0 -> 4)
 	store, err := Open(ctx, dbPath)
 	if err != nil {
 		t.Fatal(err)
 	}
 
 	version := store.getSchemaVersion(ctx)
-	if version != 1 {
-		t.Errorf("expected version 1, got %d", version)
+	if version != 4 {
+		t.Errorf("expected version 4, got %d", version)
 	}
 
 	_ = store.Close() //nolint:errcheck // cleanup is best-effort
@@ -342,7 +345,195 @@ func TestSchemaMigration_Version0To1(t *testing.T) {
 	defer func() { _ = store2.Close() }() //nolint:errcheck // cleanup is best-effort
 
 	version = store2.getSchemaVersion(ctx)
-	if version != 1 {
-		t.Errorf("expected version 1 after re-open, got %d", version)
+	if version != 4 {
+		t.Errorf("expected version 4 after re-open, got %d", version)
+	}
+}
+
+func TestSchemaMigration_Version1To4(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), ".tidydots.db")
+	ctx := context.Background()
+
+	// Build a version 1 store, as written before operation history existed.
+	db, err := sql.Open("sqlite", dbPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := migrateV1(ctx, tx); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := tx.ExecContext(ctx, `INSERT INTO schema_version (version) VALUES (1)`); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := tx.ExecContext(ctx, `INSERT INTO template_renders (template_path, pure_render, template_hash, platform_os, platform_host)
+		VALUES ('old.tmpl', 'content', 'hash', 'linux', 'host')`); err != nil {
+		t.Fatal(err)
+	}
+	if err := tx.Commit(); err != nil {
+		t.Fatal(err)
+	}
+	_ = db.Close() //nolint:errcheck // cleanup is best-effort
+
+	store, err := Open(ctx, dbPath)
+	if err != nil {
+		t.Fatalf("Open on a version 1 store: %v", err)
+	}
+	defer func() { _ = store.Close() }() //nolint:errcheck // cleanup is best-effort
+
+	if version := store.getSchemaVersion(ctx); version != 4 {
+		t.Errorf("expected version 4 after migration, got %d", version)
+	}
+
+	if rec, err := store.GetLatestRender(ctx, "old.tmpl", "linux", "host"); err != nil || rec == nil {
+		t.Errorf("render history lost in migration: rec = %v, err = %v", rec, err)
+	}
+
+	ops, err := store.GetOperations(ctx, "linux", "host")
+	if err != nil {
+		t.Fatalf("GetOperations: %v", err)
+	}
+	if len(ops) != 0 {
+		t.Errorf("migrated store has %d operation records, want none", len(ops))
+	}
+
+	journal, err := store.GetJournal(ctx, OpBackup, "linux", "host")
+	if err != nil {
+		t.Fatalf("GetJournal: %v", err)
+	}
+	if len(journal) != 0 {
+		t.Errorf("migrated store has %d journal entries, want none", len(journal))
+	}
+}
+
+func TestRecordOperation_ReplacesPerEntryAndMachine(t *testing.T) {
+	store := newTestStore(t)
+	ctx := context.Background()
+
+	first := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	second := first.Add(48 * time.Hour)
+
+	records := []OperationRecord{
+		{AppName: "nvim", EntryName: "config", Operation: OpBackup, RanAt: first, Version: "1.0.0", PlatformOS: "linux", PlatformHost: "host-a"},
+		{AppName: "nvim", EntryName: "config", Operation: OpBackup, RanAt: second, Version: "1.1.0", PlatformOS: "linux", PlatformHost: "host-a"},
+		{AppName: "nvim", EntryName: "config", Operation: OpRestore, RanAt: first, Version: "1.0.0", PlatformOS: "linux", PlatformHost: "host-a"},
+		{AppName: "nvim", EntryName: "config", Operation: OpBackup, RanAt: first, Version: "1.0.0", PlatformOS: "linux", PlatformHost: "host-b"},
+	}
+
+	for _, r := range records {
+		if err := store.RecordOperation(ctx, r); err != nil {
+			t.Fatalf("RecordOperation(%+v): %v", r, err)
+		}
+	}
+
+	ops, err := store.GetOperations(ctx, "linux", "host-a")
+	if err != nil {
+		t.Fatalf("GetOperations: %v", err)
+	}
+	if len(ops) != 2 {
+		t.Fatalf("got %d records for host-a, want 2 (backup and restore): %+v", len(ops), ops)
+	}
+
+	for _, op := range ops {
+		if op.Operation == OpBackup && (!op.RanAt.Equal(second) || op.Version != "1.1.0") {
+			t.Errorf("backup record = %+v, want the second backup", op)
+		}
+	}
+}
+
+func TestJournal_RecordGetClear(t *testing.T) {
+	store := newTestStore(t)
+	ctx := context.Background()
+
+	at := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	records := []OperationRecord{
+		{AppName: "nvim", EntryName: "config", Operation: OpBackup, RanAt: at, PlatformOS: "linux", PlatformHost: "host-a"},
+		{AppName: "nvim", EntryName: "config", Operation: OpBackup, RanAt: at.Add(time.Minute), PlatformOS: "linux", PlatformHost: "host-a"},
+		{AppName: "zsh", EntryName: "rc", Operation: OpBackup, RanAt: at.Add(time.Hour), PlatformOS: "linux", PlatformHost: "host-a"},
+		{AppName: "zsh", EntryName: "rc", Operation: OpRestore, RanAt: at, PlatformOS: "linux", PlatformHost: "host-a"},
+		{AppName: "zsh", EntryName: "rc", Operation: OpBackup, RanAt: at, PlatformOS: "linux", PlatformHost: "host-b"},
+	}
+
+	for _, r := range records {
+		if err := store.RecordJournal(ctx, r); err != nil {
+			t.Fatalf("RecordJournal(%+v): %v", r, err)
+		}
+	}
+
+	journal, err := store.GetJournal(ctx, OpBackup, "linux", "host-a")
+	if err != nil {
+		t.Fatalf("GetJournal: %v", err)
+	}
+
+	if len(journal) != 2 || journal[0].AppName != "nvim" || journal[1].AppName != "zsh" {
+		t.Fatalf("journal = %+v, want nvim/config then zsh/rc", journal)
+	}
+
+	if !journal[0].RanAt.Equal(at.Add(time.Minute)) {
+		t.Errorf("nvim/config completed at %v, want the later record", journal[0].RanAt)
+	}
+
+	if err := store.ClearJournal(ctx, OpBackup, "linux", "host-a"); err != nil {
+		t.Fatalf("ClearJournal: %v", err)
+	}
+
+	if journal, _ := store.GetJournal(ctx, OpBackup, "linux", "host-a"); len(journal) != 0 {
+		t.Errorf("journal after clear = %+v, want empty", journal)
+	}
+
+	// Other operations and machines keep their journal.
+	if journal, _ := store.GetJournal(ctx, OpRestore, "linux", "host-a"); len(journal) != 1 {
+		t.Errorf("restore journal = %+v, want one entry", journal)
+	}
+
+	if journal, _ := store.GetJournal(ctx, OpBackup, "linux", "host-b"); len(journal) != 1 {
+		t.Errorf("host-b journal = %+v, want one entry", journal)
+	}
+}
+
+func TestLinkedFiles_SetGet(t *testing.T) {
+	store := newTestStore(t)
+	ctx := context.Background()
+
+	if err := store.SetLinkedFiles(ctx, "desktop", "apps", "linux", "host-a", []string{"b.desktop", "a.desktop"}); err != nil {
+		t.Fatalf("SetLinkedFiles: %v", err)
+	}
+
+	if err := store.SetLinkedFiles(ctx, "desktop", "apps", "linux", "host-b", []string{"c.desktop"}); err != nil {
+		t.Fatalf("SetLinkedFiles: %v", err)
+	}
+
+	files, err := store.GetLinkedFiles(ctx, "desktop", "apps", "linux", "host-a")
+	if err != nil {
+		t.Fatalf("GetLinkedFiles: %v", err)
+	}
+
+	if strings.Join(files, ",") != "a.desktop,b.desktop" {
+		t.Errorf("linked files = %v, want a.desktop and b.desktop, sorted", files)
+	}
+
+	if err := store.SetLinkedFiles(ctx, "desktop", "apps", "linux", "host-a", []string{"b.desktop"}); err != nil {
+		t.Fatalf("SetLinkedFiles: %v", err)
+	}
+
+	if files, _ = store.GetLinkedFiles(ctx, "desktop", "apps", "linux", "host-a"); strings.Join(files, ",") != "b.desktop" {
+		t.Errorf("linked files after replacing = %v, want b.desktop", files)
+	}
+
+	if err := store.SetLinkedFiles(ctx, "desktop", "apps", "linux", "host-a", nil); err != nil {
+		t.Fatalf("SetLinkedFiles: %v", err)
+	}
+
+	if files, _ = store.GetLinkedFiles(ctx, "desktop", "apps", "linux", "host-a"); files != nil {
+		t.Errorf("linked files after clearing = %v, want none", files)
+	}
+
+	if files, _ = store.GetLinkedFiles(ctx, "desktop", "apps", "linux", "host-b"); strings.Join(files, ",") != "c.desktop" {
+		t.Errorf("other host's linked files = %v, want c.desktop", files)
 	}
 }
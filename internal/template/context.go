@@ -2,9 +2,12 @@
 package template
 
 import (
+	"fmt"
 	"os"
+	"strconv"
 	"strings"
 
+	"github.com/AntoineGS/tidydots/internal/config"
 	"github.com/AntoineGS/tidydots/internal/platform"
 )
 
@@ -17,6 +20,11 @@ type Context struct {
 	HasDisplay bool
 	IsWSL      bool
 	Env        map[string]string
+	Vars       map[string]string // the config's vars
+
+	ConfigDir     string // the configurations directory, holding tidydots.yaml
+	BackupRoot    string // the directory backup paths are relative to
+	AppConfigPath string // the app config file, which points at ConfigDir
 }
 
 // NewContextFromPlatform creates a Context from platform detection results,
@@ -46,3 +54,81 @@ func NewContextFromPlatform(p *platform.Platform) *Context {
 		Env:        env,
 	}
 }
+
+// WithVars sets the user values templates see as .Vars, usually the config's
+// vars, and returns c. The map is copied, so Set does not change vars.
+func (c *Context) WithVars(vars map[string]string) *Context {
+	c.Vars = make(map[string]string, len(vars))
+	for k, v := range vars {
+		c.Vars[k] = v
+	}
+
+	return c
+}
+
+// WithConfig sets what templates see of cfg, which must have its BackupRoot
+// set, and returns c: its vars as .Vars (see WithVars), its directory as
+// .ConfigDir and .BackupRoot, and the app config file as .AppConfigPath.
+func (c *Context) WithConfig(cfg *config.Config) *Context {
+	c.ConfigDir = cfg.BackupRoot
+	c.BackupRoot = cfg.BackupRoot
+	c.AppConfigPath = config.AppConfigPath()
+
+	return c.WithVars(cfg.Vars)
+}
+
+// Set overrides one context value by the name templates use for it: OS,
+// Distro, Hostname, User, HasDisplay, IsWSL, ConfigDir, BackupRoot,
+// AppConfigPath, Env.NAME for an environment variable or Vars.NAME for a user
+// value. Field names are matched case-insensitively.
+func (c *Context) Set(key, value string) error {
+	if name, ok := strings.CutPrefix(key, "Env."); ok && name != "" {
+		if c.Env == nil {
+			c.Env = make(map[string]string)
+		}
+
+		c.Env[name] = value
+		return nil
+	}
+
+	if name, ok := strings.CutPrefix(key, "Vars."); ok && name != "" {
+		if c.Vars == nil {
+			c.Vars = make(map[string]string)
+		}
+
+		c.Vars[name] = value
+		return nil
+	}
+
+	switch strings.ToLower(key) {
+	case "os":
+		c.OS = value
+	case "distro":
+		c.Distro = value
+	case "hostname":
+		c.Hostname = value
+	case "user":
+		c.User = value
+	case "configdir":
+		c.ConfigDir = value
+	case "backuproot":
+		c.BackupRoot = value
+	case "appconfigpath":
+		c.AppConfigPath = value
+	case "hasdisplay", "iswsl":
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return fmt.Errorf("%s: %q is not a boolean", key, value)
+		}
+
+		if strings.EqualFold(key, "hasdisplay") {
+			c.HasDisplay = b
+		} else {
+			c.IsWSL = b
+		}
+	default:
+		return fmt.Errorf("unknown context key %q (want OS, Distro, Hostname, User, HasDisplay, IsWSL, ConfigDir, BackupRoot, AppConfigPath, Env.NAME or Vars.NAME)", key)
+	}
+
+	return nil
+}
@@ -0,0 +1,78 @@
+package template
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/AntoineGS/tidydots/internal/config"
+)
+
+func TestContextSet(t *testing.T) {
+	ctx := &Context{OS: "linux", Hostname: "desktop"}
+
+	for _, kv := range [][2]string{
+		{"os", "windows"},
+		{"Hostname", "laptop"},
+		{"HasDisplay", "true"},
+		{"Env.EDITOR", "nvim"},
+		{"Vars.theme", "dark"},
+		{"configdir", "/dots"},
+		{"BackupRoot", "/backups"},
+		{"AppConfigPath", "/app.yaml"},
+	} {
+		if err := ctx.Set(kv[0], kv[1]); err != nil {
+			t.Fatalf("Set(%q, %q) error = %v", kv[0], kv[1], err)
+		}
+	}
+
+	if ctx.OS != "windows" || ctx.Hostname != "laptop" || !ctx.HasDisplay || ctx.Env["EDITOR"] != "nvim" || ctx.Vars["theme"] != "dark" ||
+		ctx.ConfigDir != "/dots" || ctx.BackupRoot != "/backups" || ctx.AppConfigPath != "/app.yaml" {
+		t.Errorf("Set() produced %+v", ctx)
+	}
+
+	for _, kv := range [][2]string{{"Shell", "zsh"}, {"IsWSL", "maybe"}, {"Env.", "x"}, {"Vars.", "x"}} {
+		if err := ctx.Set(kv[0], kv[1]); err == nil {
+			t.Errorf("Set(%q, %q) expected error, got nil", kv[0], kv[1])
+		}
+	}
+}
+
+func TestContextWithVars(t *testing.T) {
+	vars := map[string]string{"app": "nvim"}
+	ctx := (&Context{OS: "linux"}).WithVars(vars)
+
+	vars["app"] = "vim"
+
+	if ctx.OS != "linux" || ctx.Vars["app"] != "nvim" {
+		t.Errorf("WithVars() = %+v, want OS kept and a copy of the vars", ctx)
+	}
+}
+
+func TestContextWithConfig(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("USERPROFILE", home)
+
+	cfg := &config.Config{BackupRoot: "/home/alice/dotfiles", Vars: map[string]string{"theme": "dark"}}
+	ctx := (&Context{OS: "linux"}).WithConfig(cfg)
+
+	tests := []struct {
+		field, got, want string
+	}{
+		{"ConfigDir", ctx.ConfigDir, "/home/alice/dotfiles"},
+		{"BackupRoot", ctx.BackupRoot, "/home/alice/dotfiles"},
+		{"AppConfigPath", ctx.AppConfigPath, config.AppConfigPath()},
+		{"Vars.theme", ctx.Vars["theme"], "dark"},
+		{"OS", ctx.OS, "linux"},
+	}
+
+	for _, tt := range tests {
+		if tt.got != tt.want {
+			t.Errorf("WithConfig() %s = %q, want %q", tt.field, tt.got, tt.want)
+		}
+	}
+
+	if filepath.Dir(filepath.Dir(ctx.AppConfigPath)) != filepath.Join(home, ".config") {
+		t.Errorf("AppConfigPath = %q, want it under %s", ctx.AppConfigPath, home)
+	}
+}
@@ -0,0 +1,37 @@
+package template
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/AntoineGS/tidydots/internal/config"
+	"golang.org/x/text/encoding"
+	"golang.org/x/text/encoding/charmap"
+)
+
+// ErrUnsupportedEncoding is returned by WithEncoding for an encoding name it
+// does not know.
+var ErrUnsupportedEncoding = errors.New("unsupported encoding")
+
+// WithEncoding returns a copy of the engine whose RenderFile reads and writes
+// files in the encoding called name: config.EncodingUTF8 (or empty),
+// config.EncodingLatin1 or config.EncodingWindows1252. The engine itself is
+// unchanged.
+func (e *Engine) WithEncoding(name string) (*Engine, error) {
+	var enc encoding.Encoding
+
+	switch name {
+	case "", config.EncodingUTF8:
+	case config.EncodingLatin1:
+		enc = charmap.ISO8859_1
+	case config.EncodingWindows1252:
+		enc = charmap.Windows1252
+	default:
+		return nil, fmt.Errorf("%w %q", ErrUnsupportedEncoding, name)
+	}
+
+	e2 := *e
+	e2.enc = enc
+
+	return &e2, nil
+}
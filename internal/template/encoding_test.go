@@ -0,0 +1,84 @@
+package template
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestWithEncoding_RenderFile(t *testing.T) {
+	ctx := &Context{OS: "linux", Hostname: "hôte", Env: map[string]string{"EURO": "€"}}
+
+	tests := []struct {
+		name     string
+		encoding string
+		content  []byte
+		want     []byte
+		wantErr  bool
+	}{
+		{
+			name:     "utf-8 passes through",
+			encoding: "utf-8",
+			content:  []byte("café {{ .Hostname }}"),
+			want:     []byte("café hôte"),
+		},
+		{
+			name:     "latin1 is decoded and encoded back",
+			encoding: "latin1",
+			content:  []byte("caf\xe9 {{ .Hostname }} {{ toUpper \"\xe9\" }}"),
+			want:     []byte("caf\xe9 h\xf4te \xc9"),
+		},
+		{
+			name:     "windows-1252",
+			encoding: "windows-1252",
+			content:  []byte("\x80 {{ .OS }}"),
+			want:     []byte("\x80 linux"),
+		},
+		{
+			name:     "output latin1 cannot encode",
+			encoding: "latin1",
+			content:  []byte(`{{ .Env.EURO }}`),
+			wantErr:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			engine, err := NewEngine(ctx).WithEncoding(tt.encoding)
+			if err != nil {
+				t.Fatalf("WithEncoding(%q) error = %v", tt.encoding, err)
+			}
+
+			got, err := engine.RenderFile("test.tmpl", tt.content)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("RenderFile() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if !tt.wantErr && string(got) != string(tt.want) {
+				t.Errorf("RenderFile() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWithEncoding_Unsupported(t *testing.T) {
+	_, err := NewEngine(&Context{}).WithEncoding("shift-jis")
+	if !errors.Is(err, ErrUnsupportedEncoding) {
+		t.Errorf("WithEncoding(shift-jis) error = %v, want ErrUnsupportedEncoding", err)
+	}
+}
+
+func TestWithEncoding_KeepsEnv(t *testing.T) {
+	engine, err := NewEngine(&Context{Env: map[string]string{}}).WithEncoding("latin1")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := engine.WithEnv(map[string]string{"NAME": "é"}).RenderFile("test.tmpl", []byte(`{{ .Env.NAME }}`))
+	if err != nil {
+		t.Fatalf("RenderFile() error = %v", err)
+	}
+
+	if string(got) != "\xe9" {
+		t.Errorf("RenderFile() = %q, want Latin-1 é", got)
+	}
+}
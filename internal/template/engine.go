@@ -15,6 +15,7 @@ import (
 	"github.com/go-sprout/sprout/registry/slices"
 	"github.com/go-sprout/sprout/registry/std"
 	sproutstrings "github.com/go-sprout/sprout/registry/strings"
+	"golang.org/x/text/encoding"
 )
 
 const (
@@ -27,6 +28,7 @@ const (
 type Engine struct {
 	ctx     *Context
 	funcMap template.FuncMap
+	enc     encoding.Encoding // of the files RenderFile renders; nil is UTF-8
 }
 
 // NewEngine creates a template engine with sprout functions and the given context.
@@ -49,6 +51,27 @@ func NewEngine(ctx *Context) *Engine {
 	}
 }
 
+// WithEnv returns a copy of the engine whose context has env merged into its
+// Env, env winning over the variables already there. The engine itself is
+// unchanged.
+func (e *Engine) WithEnv(env map[string]string) *Engine {
+	ctx := *e.ctx
+	ctx.Env = make(map[string]string, len(e.ctx.Env)+len(env))
+
+	for k, v := range e.ctx.Env {
+		ctx.Env[k] = v
+	}
+
+	for k, v := range env {
+		ctx.Env[k] = v
+	}
+
+	e2 := *e
+	e2.ctx = &ctx
+
+	return &e2
+}
+
 // RenderString renders a template string. Returns input unchanged if no {{ delimiters are present.
 func (e *Engine) RenderString(name, tmplStr string) (string, error) {
 	if !strings.Contains(tmplStr, "{{") {
@@ -15,6 +15,9 @@ func TestRenderString(t *testing.T) {
 			"HOME":   "/home/testuser",
 			"EDITOR": "nvim",
 		},
+		ConfigDir:     "/home/testuser/dotfiles",
+		BackupRoot:    "/home/testuser/dotfiles",
+		AppConfigPath: "/home/testuser/.config/tidydots/config.yaml",
 	}
 	engine := NewEngine(ctx)
 
@@ -44,6 +47,16 @@ func TestRenderString(t *testing.T) {
 			template: `{{ if eq .OS "windows" }}is windows{{ else }}not windows{{ end }}`,
 			want:     "not windows",
 		},
+		{
+			name:     "config directory",
+			template: "{{ .ConfigDir }}/themes/current",
+			want:     "/home/testuser/dotfiles/themes/current",
+		},
+		{
+			name:     "backup root and app config",
+			template: "{{ .BackupRoot }} {{ .AppConfigPath }}",
+			want:     "/home/testuser/dotfiles /home/testuser/.config/tidydots/config.yaml",
+		},
 		{
 			name:     "no delimiters passthrough",
 			template: "just a plain string",
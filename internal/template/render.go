@@ -0,0 +1,110 @@
+package template
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+	"strings"
+	"text/template"
+)
+
+// RenderError is a template failure located in the template source. Line and
+// Column are 1-based; Column is 0 when Go only reports the line, as it does
+// for parse errors.
+type RenderError struct {
+	Name   string
+	Line   int
+	Column int
+	Msg    string
+	Err    error // the underlying text/template error
+}
+
+func (e *RenderError) Error() string {
+	switch {
+	case e.Line == 0:
+		return fmt.Sprintf("%s: %s", e.Name, e.Msg)
+	case e.Column == 0:
+		return fmt.Sprintf("%s:%d: %s", e.Name, e.Line, e.Msg)
+	default:
+		return fmt.Sprintf("%s:%d:%d: %s", e.Name, e.Line, e.Column, e.Msg)
+	}
+}
+
+func (e *RenderError) Unwrap() error {
+	return e.Err
+}
+
+// RenderFile renders a template file's content with the engine's context, the
+// same way RenderBytes does. Parse and execution failures are returned as a
+// *RenderError carrying the line (and column, when known) in the file. With
+// an encoding set by WithEncoding, content is decoded from it before parsing
+// and the output is encoded back to it.
+func (e *Engine) RenderFile(name string, content []byte) ([]byte, error) {
+	if e.enc != nil {
+		decoded, err := e.enc.NewDecoder().Bytes(content)
+		if err != nil {
+			return nil, fmt.Errorf("decoding %s: %w", name, err)
+		}
+
+		content = decoded
+	}
+
+	tmpl, err := template.New(name).Funcs(e.funcMap).Parse(string(content))
+	if err != nil {
+		return nil, newRenderError(name, err)
+	}
+
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, e.ctx); err != nil {
+		return nil, newRenderError(name, err)
+	}
+
+	if e.enc == nil {
+		return buf.Bytes(), nil
+	}
+
+	out, err := e.enc.NewEncoder().Bytes(buf.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("encoding the output of %s: %w", name, err)
+	}
+
+	return out, nil
+}
+
+// newRenderError extracts the position text/template embeds in its messages:
+// "template: NAME:LINE: msg" for parse errors and
+// "template: NAME:LINE:COL: msg" for execution errors, where COL is 0-based.
+func newRenderError(name string, err error) *RenderError {
+	rerr := &RenderError{Name: name, Msg: err.Error(), Err: err}
+
+	rest, ok := strings.CutPrefix(err.Error(), "template: "+name+":")
+	if !ok {
+		return rerr
+	}
+
+	var nums []int
+
+	for len(nums) < 2 {
+		field, tail, found := strings.Cut(rest, ":")
+		n, convErr := strconv.Atoi(field)
+		if !found || convErr != nil {
+			break
+		}
+
+		nums = append(nums, n)
+		rest = tail
+	}
+
+	if len(nums) == 0 {
+		return rerr
+	}
+
+	rerr.Line = nums[0]
+	if len(nums) == 2 {
+		rerr.Column = nums[1] + 1
+	}
+
+	rerr.Msg = strings.TrimSpace(rest)
+
+	return rerr
+}
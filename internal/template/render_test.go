@@ -0,0 +1,55 @@
+package template
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestRenderFile(t *testing.T) {
+	engine := NewEngine(&Context{OS: "linux", Env: map[string]string{}})
+
+	tests := []struct {
+		name     string
+		template string
+		want     string
+		wantLine int
+		wantCol  int
+		wantErr  bool
+	}{
+		{name: "renders like RenderBytes", template: "os={{ .OS }}\n", want: "os=linux\n"},
+		{name: "parse error reports the line", template: "a\nb\n{{ nope }}\n", wantErr: true, wantLine: 3},
+		{name: "unclosed action", template: "a\n{{ .OS", wantErr: true, wantLine: 2},
+		{name: "exec error reports line and column", template: "a\nb {{ .Missing }}\n", wantErr: true, wantLine: 2, wantCol: 6},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := engine.RenderFile("nvim/init.lua.tmpl", []byte(tt.template))
+			if !tt.wantErr {
+				if err != nil {
+					t.Fatalf("RenderFile() error = %v", err)
+				}
+
+				if string(got) != tt.want {
+					t.Errorf("RenderFile() = %q, want %q", got, tt.want)
+				}
+
+				return
+			}
+
+			var rerr *RenderError
+			if !errors.As(err, &rerr) {
+				t.Fatalf("RenderFile() error = %v, want *RenderError", err)
+			}
+
+			if rerr.Name != "nvim/init.lua.tmpl" || rerr.Line != tt.wantLine || rerr.Column != tt.wantCol {
+				t.Errorf("RenderError = %s:%d:%d, want nvim/init.lua.tmpl:%d:%d",
+					rerr.Name, rerr.Line, rerr.Column, tt.wantLine, tt.wantCol)
+			}
+
+			if rerr.Msg == "" || rerr.Msg == err.Error() {
+				t.Errorf("RenderError.Msg = %q, want the message without its position", rerr.Msg)
+			}
+		})
+	}
+}
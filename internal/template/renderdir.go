@@ -0,0 +1,241 @@
+package template
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// RenderDirOpts controls RenderDir.
+type RenderDirOpts struct {
+	// IgnorePatterns leaves out matching files and directories of the source,
+	// in gitignore syntax: "*.bak" matches at any depth, "/build" or
+	// "docs/*.md" only relative to the source, "cache/" only directories,
+	// "**" any number of directories, and "!keep.bak" re-includes what an
+	// earlier pattern left out. The last matching pattern wins.
+	IgnorePatterns []string
+	// Overwrite replaces files already in the destination. Without it,
+	// RenderDir fails on the first file that exists, leaving what it wrote
+	// before in place.
+	Overwrite bool
+}
+
+// RenderDir renders the tree at srcDir into dstDir: every .tmpl file is
+// rendered with the engine's context into the same path without the suffix,
+// and every other file is copied unchanged, keeping file modes and symlinks.
+// The .tmpl.rendered and .tmpl.conflict files tidydots keeps next to its
+// templates are left out. A template that fails to render stops the walk with
+// a *RenderError naming it by its path in srcDir.
+func (e *Engine) RenderDir(srcDir, dstDir string, opts RenderDirOpts) error {
+	ignore, err := compileIgnorePatterns(opts.IgnorePatterns)
+	if err != nil {
+		return err
+	}
+
+	return filepath.WalkDir(srcDir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		rel, err := filepath.Rel(srcDir, path)
+		if err != nil {
+			return err
+		}
+
+		if rel == "." {
+			return os.MkdirAll(dstDir, 0o750)
+		}
+
+		if ignore.matches(filepath.ToSlash(rel), d.IsDir()) {
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+
+			return nil
+		}
+
+		if IsRenderedFile(rel) || IsConflictFile(rel) {
+			return nil
+		}
+
+		return e.renderDirEntry(path, rel, filepath.Join(dstDir, rel), d, opts.Overwrite)
+	})
+}
+
+// renderDirEntry renders or copies the entry of RenderDir at path, rel in
+// its source, to dst.
+func (e *Engine) renderDirEntry(path, rel, dst string, d fs.DirEntry, overwrite bool) error {
+	info, err := d.Info()
+	if err != nil {
+		return err
+	}
+
+	switch {
+	case d.IsDir():
+		return os.MkdirAll(dst, info.Mode().Perm())
+
+	case d.Type()&fs.ModeSymlink != 0:
+		link, err := os.Readlink(path)
+		if err != nil {
+			return err
+		}
+
+		if overwrite {
+			if err := os.Remove(dst); err != nil && !errors.Is(err, fs.ErrNotExist) {
+				return err
+			}
+		}
+
+		return os.Symlink(link, dst)
+	}
+
+	content, err := os.ReadFile(path) //nolint:gosec // path is walked from the caller's source directory
+	if err != nil {
+		return err
+	}
+
+	if IsTemplateFile(rel) {
+		dst = strings.TrimSuffix(dst, tmplSuffix)
+
+		if content, err = e.RenderFile(filepath.ToSlash(rel), content); err != nil {
+			return err
+		}
+	}
+
+	return writeRendered(dst, content, info.Mode().Perm(), overwrite)
+}
+
+// writeRendered writes content to dst with perm, failing when dst exists
+// unless overwrite is set.
+func writeRendered(dst string, content []byte, perm fs.FileMode, overwrite bool) error {
+	flags := os.O_WRONLY | os.O_CREATE | os.O_TRUNC
+	if !overwrite {
+		flags = os.O_WRONLY | os.O_CREATE | os.O_EXCL
+	}
+
+	f, err := os.OpenFile(dst, flags, perm) //nolint:gosec // dst is under the caller's destination directory
+	if err != nil {
+		return err
+	}
+
+	if _, err := f.Write(content); err != nil {
+		_ = f.Close()
+		return err
+	}
+
+	return f.Close()
+}
+
+// ignoreRule is one compiled pattern of RenderDirOpts.IgnorePatterns.
+type ignoreRule struct {
+	re      *regexp.Regexp
+	negate  bool
+	dirOnly bool
+}
+
+// ignoreRules are the patterns of RenderDirOpts.IgnorePatterns, in order.
+type ignoreRules []ignoreRule
+
+// compileIgnorePatterns compiles gitignore-style patterns; blank ones and
+// # comments are skipped.
+func compileIgnorePatterns(patterns []string) (ignoreRules, error) {
+	var rules ignoreRules
+
+	for _, p := range patterns {
+		p = strings.TrimSpace(p)
+		if p == "" || strings.HasPrefix(p, "#") {
+			continue
+		}
+
+		var rule ignoreRule
+
+		if rest, ok := strings.CutPrefix(p, "!"); ok {
+			rule.negate, p = true, rest
+		}
+
+		if rest, ok := strings.CutSuffix(p, "/"); ok {
+			rule.dirOnly, p = true, rest
+		}
+
+		// A pattern with a slash before its end is relative to the source;
+		// one without matches a name at any depth.
+		anchored := strings.Contains(p, "/")
+		p = strings.TrimPrefix(p, "/")
+
+		expr := globRegexp(p)
+		if !anchored {
+			expr = "(.*/)?" + expr
+		}
+
+		re, err := regexp.Compile("^" + expr + "$")
+		if err != nil {
+			return nil, fmt.Errorf("invalid ignore pattern %q: %w", p, err)
+		}
+
+		rule.re = re
+		rules = append(rules, rule)
+	}
+
+	return rules, nil
+}
+
+// globRegexp translates a glob to a regular expression: "**" spans
+// directories, "*" and "?" do not, and [...] classes are kept.
+func globRegexp(glob string) string {
+	var b strings.Builder
+
+	for i := 0; i < len(glob); i++ {
+		switch c := glob[i]; {
+		case strings.HasPrefix(glob[i:], "**/"):
+			b.WriteString("(.*/)?")
+			i += 2
+		case strings.HasPrefix(glob[i:], "**"):
+			b.WriteString(".*")
+			i++
+		case c == '*':
+			b.WriteString("[^/]*")
+		case c == '?':
+			b.WriteString("[^/]")
+		case c == '[':
+			end := strings.IndexByte(glob[i:], ']')
+			if end < 0 {
+				b.WriteString(`\[`)
+				continue
+			}
+
+			class := glob[i+1 : i+end]
+			if rest, ok := strings.CutPrefix(class, "!"); ok {
+				class = "^" + rest
+			}
+
+			b.WriteString("[" + class + "]")
+			i += end
+		default:
+			b.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+
+	return b.String()
+}
+
+// matches reports whether the last rule matching rel, a slash-separated
+// path in the source, ignores it.
+func (r ignoreRules) matches(rel string, isDir bool) bool {
+	ignored := false
+
+	for _, rule := range r {
+		if rule.dirOnly && !isDir {
+			continue
+		}
+
+		if rule.re.MatchString(rel) {
+			ignored = !rule.negate
+		}
+	}
+
+	return ignored
+}
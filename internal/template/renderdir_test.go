@@ -0,0 +1,127 @@
+package template
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// writeTree writes files, by slash-separated path under dir, with content.
+func writeTree(t *testing.T, dir string, files map[string]string) {
+	t.Helper()
+
+	for name, content := range files {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0o750); err != nil {
+			t.Fatal(err)
+		}
+
+		if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestRenderDir(t *testing.T) {
+	engine := NewEngine(&Context{OS: "linux", Env: map[string]string{}})
+	src, dst := t.TempDir(), filepath.Join(t.TempDir(), "out")
+
+	writeTree(t, src, map[string]string{
+		"init.lua.tmpl":           "os={{ .OS }}\n",
+		"lua/plugins.lua":         "return {}\n",
+		"lua/keys.lua.tmpl":       "{{ if eq .OS \"linux\" }}leader{{ end }}\n",
+		"plain.txt":               "{{ not rendered }}\n",
+		"init.lua.tmpl.rendered":  "stale\n",
+		"init.lua.tmpl.conflict":  "stale\n",
+		"notes.bak":               "ignored\n",
+		"lua/old.bak":             "ignored at any depth\n",
+		"keep.bak":                "re-included\n",
+		"cache/data":              "ignored directory\n",
+		"docs/readme.md":          "anchored\n",
+		"lua/docs/readme.md":      "not anchored there\n",
+		"deep/a/b/node_modules/x": "ignored with **\n",
+	})
+
+	err := engine.RenderDir(src, dst, RenderDirOpts{
+		IgnorePatterns: []string{"# comment", "*.bak", "!keep.bak", "cache/", "/docs", "**/node_modules"},
+	})
+	if err != nil {
+		t.Fatalf("RenderDir() error = %v", err)
+	}
+
+	want := map[string]string{
+		"init.lua":           "os=linux\n",
+		"lua/plugins.lua":    "return {}\n",
+		"lua/keys.lua":       "leader\n",
+		"plain.txt":          "{{ not rendered }}\n",
+		"keep.bak":           "re-included\n",
+		"lua/docs/readme.md": "not anchored there\n",
+	}
+
+	got := make(map[string]string)
+
+	err = filepath.WalkDir(dst, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+
+		rel, _ := filepath.Rel(dst, path)
+		content, err := os.ReadFile(path) //nolint:gosec // test file
+		got[filepath.ToSlash(rel)] = string(content)
+
+		return err
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for name, content := range want {
+		if got[name] != content {
+			t.Errorf("%s = %q, want %q", name, got[name], content)
+		}
+	}
+
+	for name := range got {
+		if _, ok := want[name]; !ok {
+			t.Errorf("unexpected file %s in the output", name)
+		}
+	}
+}
+
+func TestRenderDir_Overwrite(t *testing.T) {
+	engine := NewEngine(&Context{OS: "linux", Env: map[string]string{}})
+	src, dst := t.TempDir(), t.TempDir()
+
+	writeTree(t, src, map[string]string{"a.tmpl": "{{ .OS }}"})
+	writeTree(t, dst, map[string]string{"a": "old"})
+
+	if err := engine.RenderDir(src, dst, RenderDirOpts{}); !errors.Is(err, fs.ErrExist) {
+		t.Fatalf("RenderDir() error = %v, want one matching fs.ErrExist", err)
+	}
+
+	if err := engine.RenderDir(src, dst, RenderDirOpts{Overwrite: true}); err != nil {
+		t.Fatalf("RenderDir(Overwrite) error = %v", err)
+	}
+
+	if content, _ := os.ReadFile(filepath.Join(dst, "a")); string(content) != "linux" { //nolint:gosec // test file
+		t.Errorf("a = %q, want %q", content, "linux")
+	}
+}
+
+func TestRenderDir_RenderError(t *testing.T) {
+	engine := NewEngine(&Context{OS: "linux", Env: map[string]string{}})
+	src := t.TempDir()
+
+	writeTree(t, src, map[string]string{"sub/bad.tmpl": "a\n{{ nope }}\n"})
+
+	var rerr *RenderError
+	if err := engine.RenderDir(src, t.TempDir(), RenderDirOpts{}); !errors.As(err, &rerr) {
+		t.Fatalf("RenderDir() error = %v, want a *RenderError", err)
+	}
+
+	if rerr.Name != "sub/bad.tmpl" || rerr.Line != 2 {
+		t.Errorf("RenderError = %s:%d, want sub/bad.tmpl:2", rerr.Name, rerr.Line)
+	}
+}
@@ -89,18 +89,6 @@ func (m *Model) deleteApplicationOrSubEntry(appIdx, subIdx int) error {
 	return nil
 }
 
-// Stub functions for other phases (to be implemented later)
-
-func (m Model) renderApplicationInlineDetail(_ *ApplicationItem, _ int) string {
-	// Placeholder - to be implemented in Phase 5
-	return ""
-}
-
-func (m Model) renderSubEntryInlineDetail(_ *SubEntryItem, _ int) string {
-	// Placeholder - to be implemented in Phase 5
-	return ""
-}
-
 // performRestoreSubEntry performs restore on a SubEntry.
 //
 // A sub-entry is either a config entry (it deploys files) or a setup entry (it
@@ -123,23 +111,41 @@ func (m Model) performRestoreSubEntry(item SubEntryItem) (bool, string) {
 		return false, "Not a config entry"
 	}
 
-	target := item.Target
-	backupPath := m.resolvePath(subEntry.Backup)
+	// The manager's restore run builds the same result, so the results screen
+	// shows what `tidydots restore` prints.
+	// Entries decided in the conflict popup are restored as decided; the
+	// others restore as configured.
+	res := m.resolutions[subEntryKey{app: item.AppName, sub: subEntry.Name}]
+	result := m.Manager.RestoreEntryResolved(item.AppName, subEntry, item.Target, res)
 
-	var err error
-	if subEntry.IsFolder() {
-		if m.Manager.HasTemplateFiles(backupPath) {
-			err = m.Manager.RestoreFolderWithTemplates(subEntry, backupPath, target)
-		} else {
-			err = m.Manager.RestoreFolder(subEntry, backupPath, target)
-		}
+	return result.OK(), result.Message()
+}
+
+// toggleEnabled flips the enabled flag of an Application (subIdx < 0) or one
+// of its SubEntries, by config index, and saves. A disabled item stays in the
+// config and in the list, greyed out, but is skipped by every operation.
+func (m *Model) toggleEnabled(appIdx, subIdx int) error {
+	app := &m.Config.Applications[appIdx]
+
+	var undo func()
+
+	if subIdx >= 0 {
+		sub := &app.Entries[subIdx]
+		orig := sub.Enabled
+		sub.SetEnabled(!sub.IsEnabled())
+		undo = func() { sub.Enabled = orig }
 	} else {
-		err = m.Manager.RestoreFiles(subEntry, backupPath, target)
+		orig := app.Enabled
+		app.SetEnabled(!app.IsEnabled())
+		undo = func() { app.Enabled = orig }
 	}
 
-	if err != nil {
-		return false, fmt.Sprintf("Failed: %v", err)
+	if err := config.Save(m.Config, m.ConfigPath); err != nil {
+		undo()
+		return fmt.Errorf("failed to save config: %w", err)
 	}
 
-	return true, fmt.Sprintf("Restored: %s -> %s", target, backupPath)
+	m.reinitPreservingState(app.Name)
+
+	return nil
 }
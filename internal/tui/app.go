@@ -5,15 +5,38 @@ import (
 	"os"
 
 	tea "charm.land/bubbletea/v2"
+	"charm.land/lipgloss/v2"
 	"github.com/AntoineGS/tidydots/internal/config"
 	"github.com/AntoineGS/tidydots/internal/manager"
 	"github.com/AntoineGS/tidydots/internal/platform"
+	"github.com/AntoineGS/tidydots/internal/tui/tuishared"
 )
 
+// Options configures a TUI session started by Run.
+type Options struct {
+	ConfigPath string
+	Version    string // recorded with each restore in the state store
+	DryRun     bool
+	NoSudo     bool // skip installs and restores that need sudo
+	Offline    bool // skip operations that need the network
+	SkipVerify bool // install URL packages without verifying their downloads
+	// Theme, as set with --theme, wins over the app config's; see
+	// tuishared.ResolveTheme.
+	Theme string
+	// Width and Height, when both positive, are the size the TUI starts at
+	// instead of none when the terminal's size cannot be read, as when
+	// output goes to a pipe. A terminal's own size still wins.
+	Width  int
+	Height int
+}
+
 // Run starts the interactive TUI with a new manager
-func Run(cfg *config.Config, plat *platform.Platform, dryRun bool, configPath string) error {
+func Run(cfg *config.Config, plat *platform.Platform, opts Options) error {
 	mgr := manager.New(cfg, plat)
-	mgr.DryRun = dryRun
+	mgr.DryRun = opts.DryRun
+	mgr.NoSudo = opts.NoSudo
+	mgr.Offline = opts.Offline
+	mgr.Version = opts.Version
 
 	if err := mgr.InitStateStore(); err != nil {
 		// Non-fatal: outdated detection won't work, but TUI is still usable
@@ -21,14 +44,47 @@ func Run(cfg *config.Config, plat *platform.Platform, dryRun bool, configPath st
 	}
 	defer func() { _ = mgr.Close() }()
 
-	return RunWithManager(cfg, plat, mgr, configPath)
+	return runWithManager(cfg, plat, mgr, opts)
 }
 
 // RunWithManager runs the TUI with an existing manager
 func RunWithManager(cfg *config.Config, plat *platform.Platform, mgr *manager.Manager, configPath string) error {
-	model := NewModelWithManager(cfg, plat, mgr, configPath)
+	return runWithManager(cfg, plat, mgr, Options{ConfigPath: configPath})
+}
+
+// runWithManager runs the TUI with an existing manager and the options of
+// Run that are not manager settings.
+func runWithManager(cfg *config.Config, plat *platform.Platform, mgr *manager.Manager, opts Options) error {
+	appCfg, appCfgErr := config.LoadAppConfig()
+	if appCfgErr == nil {
+		mgr.MaxHistory = appCfg.HistoryLimit()
 
-	p := tea.NewProgram(model)
+		if err := tuishared.ApplyKeyBindings(appCfg.KeyBindingOverrides()); err != nil {
+			return fmt.Errorf("keybindings in %s: %w", config.AppConfigPath(), err)
+		}
+	}
+
+	if err := applyThemeSetting(opts.Theme, appCfg); err != nil {
+		return err
+	}
+
+	model := NewModelWithManager(cfg, plat, mgr, opts.ConfigPath)
+	model.SkipVerify = opts.SkipVerify
+	model.uiStatePath = DefaultUIStatePath()
+	model.applyUIState(loadUIState(model.uiStatePath))
+
+	if appCfgErr == nil {
+		model.globallyUniqueSubEntryNames = appCfg.GloballyUniqueSubEntryNames
+	}
+
+	// With a size hint, keys are read from stdin even when it is not a
+	// terminal, so a snapshot can be driven by piped keys.
+	var progOpts []tea.ProgramOption
+	if opts.Width > 0 && opts.Height > 0 {
+		progOpts = append(progOpts, tea.WithWindowSize(opts.Width, opts.Height), tea.WithInput(os.Stdin))
+	}
+
+	p := tea.NewProgram(model, progOpts...)
 
 	finalModel, err := p.Run()
 	if err != nil {
@@ -41,6 +97,11 @@ func RunWithManager(cfg *config.Config, plat *platform.Platform, mgr *manager.Ma
 		return fmt.Errorf("unexpected model type")
 	}
 
+	// Flush any save still waiting on its debounce tick.
+	if m.uiStatePath != "" {
+		_ = writeUIState(m.uiStatePath, m.currentUIState())
+	}
+
 	if m.Screen == ScreenResults && m.Operation != OpList && len(m.results) > 0 {
 		printFinalSummary(m)
 	}
@@ -51,6 +112,8 @@ func RunWithManager(cfg *config.Config, plat *platform.Platform, mgr *manager.Ma
 // NewModelWithManager creates a model with a manager for real operations
 func NewModelWithManager(cfg *config.Config, plat *platform.Platform, mgr *manager.Manager, configPath string) Model {
 	m := NewModel(cfg, plat, mgr.DryRun)
+	m.NoSudo = mgr.NoSudo
+	m.Offline = mgr.Offline
 	m.Manager = mgr
 	m.ConfigPath = configPath
 
@@ -78,6 +141,34 @@ func printFinalSummary(m Model) {
 	fmt.Println()
 }
 
+// applyThemeSetting applies the theme named by flag, or else by appCfg,
+// which may be nil. Without either, the terminal's background picks it.
+func applyThemeSetting(flag string, appCfg *config.AppConfig) error {
+	name, source := flag, "--theme"
+	if name == "" && appCfg != nil {
+		name, source = appCfg.Theme, "theme in "+config.AppConfigPath()
+	}
+
+	t, err := tuishared.ResolveTheme(name, hasDarkBackground)
+	if err != nil {
+		return fmt.Errorf("%s: %w", source, err)
+	}
+
+	ApplyTheme(t)
+
+	return nil
+}
+
+// hasDarkBackground asks the terminal for its background color. Without a
+// terminal to ask, it assumes a dark one.
+func hasDarkBackground() bool {
+	if !IsTerminal() {
+		return true
+	}
+
+	return lipgloss.HasDarkBackground(os.Stdin, os.Stdout)
+}
+
 // IsTerminal checks if stdout is a terminal
 func IsTerminal() bool {
 	fileInfo, err := os.Stdout.Stat()
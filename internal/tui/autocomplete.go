@@ -3,8 +3,12 @@ package tui
 import (
 	"os"
 	"path/filepath"
+	"runtime"
+	"slices"
 	"sort"
 	"strings"
+
+	"github.com/AntoineGS/tidydots/internal/platform"
 )
 
 const maxSuggestions = 8
@@ -69,6 +73,74 @@ func getPathSuggestions(input string, configDir string) []string {
 	return suggestions
 }
 
+// getTargetSuggestions returns suggestions for the target field of targetOS:
+// the usual config roots that complete input (see configRootSuggestions),
+// then the directory suggestions of getPathSuggestions.
+func getTargetSuggestions(input, targetOS, configDir string) []string {
+	suggestions := configRootSuggestions(input, targetOS)
+
+	for _, s := range getPathSuggestions(input, configDir) {
+		if !slices.Contains(suggestions, s) {
+			suggestions = append(suggestions, s)
+		}
+	}
+
+	if len(suggestions) > maxSuggestions {
+		suggestions = suggestions[:maxSuggestions]
+	}
+
+	return suggestions
+}
+
+// configRootSuggestions returns the config roots of targetOS that complete
+// input, when input is empty or starts with ~: $XDG_CONFIG_HOME (~/.config by
+// default) for Linux, plus ~/Library/Application Support on macOS, and
+// %APPDATA% and %LOCALAPPDATA% for Windows. Roots are written with ~ where
+// possible, like the targets in tidydots.yaml, so they work on any machine.
+func configRootSuggestions(input, targetOS string) []string {
+	if input != "" && !strings.HasPrefix(input, "~") {
+		return nil
+	}
+
+	var roots []string
+
+	if targetOS == platform.OSWindows {
+		roots = []string{"~/AppData/Roaming/", "~/AppData/Local/"}
+	} else {
+		roots = []string{xdgConfigRoot()}
+		if runtime.GOOS == "darwin" {
+			roots = append(roots, "~/Library/Application Support/")
+		}
+	}
+
+	var matches []string
+
+	for _, root := range roots {
+		if len(root) > len(input) && strings.HasPrefix(strings.ToLower(root), strings.ToLower(input)) {
+			matches = append(matches, root)
+		}
+	}
+
+	return matches
+}
+
+// xdgConfigRoot returns $XDG_CONFIG_HOME as a directory suggestion, relative
+// to ~ when it is under the home directory, or ~/.config/ when it is unset.
+func xdgConfigRoot() string {
+	xdg := os.Getenv("XDG_CONFIG_HOME")
+	if xdg == "" || !filepath.IsAbs(xdg) {
+		return "~/.config/"
+	}
+
+	if home, err := os.UserHomeDir(); err == nil {
+		if rel, err := filepath.Rel(home, xdg); err == nil && rel != "." && !strings.HasPrefix(rel, "..") {
+			return "~/" + filepath.ToSlash(rel) + "/"
+		}
+	}
+
+	return filepath.ToSlash(xdg) + "/"
+}
+
 // buildSuggestionPath constructs the suggestion maintaining the original path style
 func buildSuggestionPath(originalInput, name string, isDir bool) string {
 	// Find the directory part of the original input
@@ -3,7 +3,10 @@ package tui
 import (
 	"os"
 	"path/filepath"
+	"slices"
 	"testing"
+
+	"github.com/AntoineGS/tidydots/internal/platform"
 )
 
 func TestGetPathSuggestions(t *testing.T) {
@@ -65,6 +68,56 @@ func TestGetPathSuggestions(t *testing.T) {
 	}
 }
 
+func TestConfigRootSuggestions(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("USERPROFILE", home)
+	t.Setenv("XDG_CONFIG_HOME", "")
+
+	tests := []struct {
+		name     string
+		input    string
+		targetOS string
+		xdg      string
+		want     string
+		wantNone bool
+	}{
+		{name: "empty linux field", targetOS: platform.OSLinux, want: "~/.config/"},
+		{name: "partial root", input: "~/.c", targetOS: platform.OSLinux, want: "~/.config/"},
+		{name: "xdg under home", targetOS: platform.OSLinux, xdg: filepath.Join(home, "cfg"), want: "~/cfg/"},
+		{name: "xdg outside home", targetOS: platform.OSLinux, xdg: filepath.Join(t.TempDir(), "cfg")},
+		{name: "absolute input", input: "/etc", targetOS: platform.OSLinux, wantNone: true},
+		{name: "complete root", input: "~/.config/", targetOS: platform.OSLinux, wantNone: true},
+		{name: "empty windows field", targetOS: platform.OSWindows, want: "~/AppData/Roaming/"},
+		{name: "windows local", input: "~/AppData/L", targetOS: platform.OSWindows, want: "~/AppData/Local/"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("XDG_CONFIG_HOME", tt.xdg)
+
+			want := tt.want
+			if want == "" && !tt.wantNone {
+				want = filepath.ToSlash(tt.xdg) + "/"
+			}
+
+			got := configRootSuggestions(tt.input, tt.targetOS)
+
+			if tt.wantNone {
+				if len(got) != 0 {
+					t.Errorf("configRootSuggestions(%q) = %v, want none", tt.input, got)
+				}
+
+				return
+			}
+
+			if !slices.Contains(got, want) {
+				t.Errorf("configRootSuggestions(%q) = %v, want it to contain %q", tt.input, got, want)
+			}
+		})
+	}
+}
+
 func TestExpandPath(t *testing.T) {
 	home, err := os.UserHomeDir()
 	if err != nil {
@@ -7,6 +7,8 @@ import (
 
 	"charm.land/bubbles/v2/progress"
 	tea "charm.land/bubbletea/v2"
+	"github.com/AntoineGS/tidydots/internal/config"
+	"github.com/AntoineGS/tidydots/internal/packages"
 	tuiops "github.com/AntoineGS/tidydots/internal/tui/operations"
 )
 
@@ -40,18 +42,21 @@ type batchRestoreConfigsDoneMsg struct {
 }
 
 // collectBatchRestoreItems returns every selected sub-entry, de-duplicated
-// against apps that are selected as a whole. Iterating m.Applications (rather
-// than the selection maps) makes the batch order deterministic: model order,
-// not map order.
+// against apps that are selected as a whole. Disabled sub-entries are left
+// out. Iterating m.Applications (rather than the selection maps) makes the
+// batch order deterministic: model order, not map order.
 func (m Model) collectBatchRestoreItems() []batchRestoreItem {
 	var items []batchRestoreItem
 
 	for appIdx, app := range m.Applications {
 		name := app.Application.Name
 
-		// Whole app selected: every sub-entry is included.
+		// Whole app selected: every enabled sub-entry is included.
 		if m.selectedApps[name] {
 			for subIdx := range app.SubItems {
+				if app.SubItems[subIdx].IsDisabled {
+					continue
+				}
 				items = append(items, batchRestoreItem{
 					appIdx: appIdx,
 					subIdx: subIdx,
@@ -64,7 +69,7 @@ func (m Model) collectBatchRestoreItems() []batchRestoreItem {
 
 		// Standalone selected sub-entries.
 		for subIdx, sub := range app.SubItems {
-			if m.selectedSubEntries[subEntryKey{app: name, sub: sub.SubEntry.Name}] {
+			if !sub.IsDisabled && m.selectedSubEntries[subEntryKey{app: name, sub: sub.SubEntry.Name}] {
 				items = append(items, batchRestoreItem{
 					appIdx: appIdx,
 					subIdx: subIdx,
@@ -106,12 +111,17 @@ func (m Model) executeBatchRestore() tea.Cmd {
 		configs = append(configs, item)
 	}
 
-	// Execute the config restores sequentially in the background.
+	// Execute the config restores sequentially in the background. The
+	// restored entries are journaled, so an interrupted batch can be resumed.
 	return func() tea.Msg {
 		results := make([]ResultItem, 0, len(configs))
 		successCount := 0
 		failCount := 0
 
+		if m.Manager != nil {
+			m.Manager = m.batchRestoreManager()
+		}
+
 		for _, item := range configs {
 			subItem := m.Applications[item.appIdx].SubItems[item.subIdx]
 
@@ -130,6 +140,10 @@ func (m Model) executeBatchRestore() tea.Cmd {
 			}
 		}
 
+		if m.Manager != nil {
+			m.Manager.EndJournal(failCount == 0)
+		}
+
 		return batchRestoreConfigsDoneMsg{
 			results:      results,
 			setups:       setups,
@@ -160,37 +174,107 @@ func (m Model) handleBatchRestoreConfigsDone(msg batchRestoreConfigsDoneMsg) (te
 	return m, m.startSetupRun(msg.setups, true)
 }
 
-// executeBatchInstall executes package installation for all selected apps.
-// Returns a command that processes packages sequentially.
-func (m Model) executeBatchInstall() tea.Cmd {
-	// Collect all selected apps with packages to install
-	var packages []PackageItem
+// methodUnavailable is the install method of a selected package that no
+// installation method on this system can install.
+const methodUnavailable = "unavailable"
+
+// packageManager returns the package manager used to decide which selected
+// packages can be installed, creating one for this platform if unset.
+func (m Model) packageManager() *packages.Manager {
+	if m.pkgManager != nil {
+		return m.pkgManager
+	}
+
+	return packages.NewManager(&packages.Config{}, m.Platform.OS, m.DryRun, false)
+}
+
+// collectBatchInstallItems returns the packages of the selected applications
+// that are not known to be installed, in install order, split by whether
+// this system can install them. Installable packages carry the method they
+// will be installed with; the others carry methodUnavailable.
+func (m Model) collectBatchInstallItems() (installable, unavailable []PackageItem) {
+	pm := m.packageManager()
 
 	for _, app := range m.Applications {
-		if !m.selectedApps[app.Application.Name] {
+		if app.IsDisabled || !m.selectedApps[app.Application.Name] || !app.Application.HasPackage() {
 			continue
 		}
 
-		// Only install if package exists and is not already installed
-		if app.PkgInstalled != nil && !*app.PkgInstalled && app.Application.HasPackage() {
-			// Convert Application to PackageItem
-			pkg := PackageItem{
-				Name:     app.Application.Name,
-				Package:  app.Application.Package,
-				Method:   app.PkgMethod,
-				Selected: true,
-			}
-			packages = append(packages, pkg)
+		if app.PkgInstalled != nil && *app.PkgInstalled {
+			continue
+		}
+
+		// The package's own when leaves it out on this machine.
+		if !config.EvaluateWhen(app.Application.Package.When, m.Renderer) {
+			continue
 		}
+
+		pkg := PackageItem{
+			Name:     app.Application.Name,
+			Package:  app.Application.Package,
+			Selected: true,
+		}
+
+		converted := packages.FromPackageSpec(pkg.Name, pkg.Package)
+		if !pm.CanInstall(*converted) {
+			pkg.Method = methodUnavailable
+			unavailable = append(unavailable, pkg)
+
+			continue
+		}
+
+		pkg.Method = pm.GetInstallMethod(*converted)
+		installable = append(installable, pkg)
+	}
+
+	return orderInstallItems(installable), unavailable
+}
+
+// orderInstallItems returns items in the order InstallAll would install them:
+// lowest phase first, and each package after those its after list names.
+func orderInstallItems(items []PackageItem) []PackageItem {
+	byName := make(map[string]PackageItem, len(items))
+	pkgs := make([]packages.Package, 0, len(items))
+
+	for _, item := range items {
+		byName[item.Name] = item
+		pkgs = append(pkgs, *packages.FromPackageSpec(item.Name, item.Package))
+	}
+
+	ordered := make([]PackageItem, 0, len(items))
+
+	for _, phase := range packages.GroupByPhase(pkgs) {
+		for _, pkg := range phase {
+			ordered = append(ordered, byName[pkg.Name])
+		}
+	}
+
+	return ordered
+}
+
+// executeBatchInstall executes package installation for all selected apps.
+// Returns a command that processes packages sequentially, lowest phase first.
+// Packages this system cannot install are not queued; they are reported as
+// skipped in the results instead.
+func (m Model) executeBatchInstall() tea.Cmd {
+	packages, unavailable := m.collectBatchInstallItems()
+
+	skipped := make([]ResultItem, 0, len(unavailable))
+	for _, pkg := range unavailable {
+		skipped = append(skipped, ResultItem{
+			Name:    pkg.Name,
+			Success: false,
+			Message: fmt.Sprintf("Skipped (method: %s)", pkg.Method),
+		})
 	}
 
 	// If no packages to install, return complete immediately
 	if len(packages) == 0 {
 		return func() tea.Msg {
 			return BatchCompleteMsg{
-				Results:      []ResultItem{},
+				Results:      skipped,
 				SuccessCount: 0,
-				FailCount:    0,
+				FailCount:    len(skipped),
 			}
 		}
 	}
@@ -201,13 +285,14 @@ func (m Model) executeBatchInstall() tea.Cmd {
 	return func() tea.Msg {
 		// Signal that we need to start package installation
 		// This will be handled in the Update method
-		return initBatchInstallMsg{packages: packages}
+		return initBatchInstallMsg{packages: packages, skipped: skipped}
 	}
 }
 
 // initBatchInstallMsg is an internal message to initialize batch package installation.
 type initBatchInstallMsg struct {
 	packages []PackageItem
+	skipped  []ResultItem // unavailable packages, reported ahead of the installs
 }
 
 // executeBatchDelete executes delete operations for all selected items.
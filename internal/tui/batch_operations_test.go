@@ -0,0 +1,137 @@
+package tui
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/AntoineGS/tidydots/internal/config"
+	"github.com/AntoineGS/tidydots/internal/packages"
+	"github.com/AntoineGS/tidydots/internal/platform"
+)
+
+func TestExecuteBatchInstall_OrdersByPhase(t *testing.T) {
+	notInstalled := false
+
+	cfg := &config.Config{}
+	plat := &platform.Platform{OS: "linux", EnvVars: map[string]string{"HOME": "/home/test"}}
+	m := NewModel(cfg, plat, false)
+	m.pkgManager = &packages.Manager{OS: "linux", Available: []packages.PackageManager{packages.Pacman}}
+
+	for _, app := range []struct {
+		name  string
+		phase int
+	}{{"gui", 2}, {"runtime", 0}, {"tool", 1}, {"base", 0}} {
+		m.Applications = append(m.Applications, ApplicationItem{
+			Application: config.Application{
+				Name: app.name,
+				Package: &config.EntryPackage{
+					Managers: map[string]config.ManagerValue{"pacman": {PackageName: app.name}},
+					Phase:    app.phase,
+				},
+			},
+			PkgInstalled: &notInstalled,
+		})
+		m.selectedApps[app.name] = true
+	}
+
+	msg, ok := m.executeBatchInstall()().(initBatchInstallMsg)
+	if !ok {
+		t.Fatal("expected initBatchInstallMsg")
+	}
+
+	var got []string
+	for _, pkg := range msg.packages {
+		got = append(got, pkg.Name)
+	}
+
+	if want := "runtime base tool gui"; strings.Join(got, " ") != want {
+		t.Errorf("install order = %q, want %q", strings.Join(got, " "), want)
+	}
+}
+
+func TestExecuteBatchInstall_SkipsUnavailable(t *testing.T) {
+	notInstalled := false
+
+	cfg := &config.Config{}
+	plat := &platform.Platform{OS: "linux", EnvVars: map[string]string{"HOME": "/home/test"}}
+	m := NewModel(cfg, plat, false)
+	m.pkgManager = &packages.Manager{OS: "linux", Available: []packages.PackageManager{packages.Pacman}}
+
+	for _, app := range []struct {
+		name    string
+		manager string
+	}{{"ripgrep", "pacman"}, {"winonly", "winget"}, {"brewonly", "brew"}} {
+		m.Applications = append(m.Applications, ApplicationItem{
+			Application: config.Application{
+				Name: app.name,
+				Package: &config.EntryPackage{
+					Managers: map[string]config.ManagerValue{app.manager: {PackageName: app.name}},
+				},
+			},
+			PkgInstalled: &notInstalled,
+		})
+		m.selectedApps[app.name] = true
+	}
+
+	// The summary tells the user up front what will be skipped.
+	summary := stripAnsiCodes(m.renderInstallSummary())
+	for _, want := range []string{"Will install packages for 1 application(s)", "ripgrep (pacman)", "2 package(s) unavailable", "winonly", "brewonly"} {
+		if !strings.Contains(summary, want) {
+			t.Errorf("install summary is missing %q:\n%s", want, summary)
+		}
+	}
+
+	msg, ok := m.executeBatchInstall()().(initBatchInstallMsg)
+	if !ok {
+		t.Fatal("expected initBatchInstallMsg")
+	}
+
+	if len(msg.packages) != 1 || msg.packages[0].Name != "ripgrep" || msg.packages[0].Method != "pacman" {
+		t.Errorf("queued packages = %+v, want only ripgrep via pacman", msg.packages)
+	}
+
+	updated, _ := m.Update(msg)
+
+	got, ok := updated.(Model)
+	if !ok {
+		t.Fatalf("Update returned %T, want Model", updated)
+	}
+
+	if len(got.results) != 2 {
+		t.Fatalf("results = %+v, want the two skipped packages", got.results)
+	}
+
+	for _, r := range got.results {
+		if r.Success || r.Message != "Skipped (method: unavailable)" {
+			t.Errorf("result for %s = %+v, want an unavailable skip", r.Name, r)
+		}
+	}
+}
+
+func TestExecuteBatchInstall_SkipsPackagesFilteredByWhen(t *testing.T) {
+	notInstalled := false
+
+	cfg := &config.Config{}
+	plat := &platform.Platform{OS: "linux", EnvVars: map[string]string{"HOME": "/home/test"}}
+	m := NewModel(cfg, plat, false)
+	m.pkgManager = &packages.Manager{OS: "linux", Available: []packages.PackageManager{packages.Pacman}}
+
+	for _, app := range []struct{ name, when string }{{"ripgrep", `{{ eq .OS "linux" }}`}, {"winonly", `{{ eq .OS "windows" }}`}} {
+		m.Applications = append(m.Applications, ApplicationItem{
+			Application: config.Application{
+				Name: app.name,
+				Package: &config.EntryPackage{
+					Managers: map[string]config.ManagerValue{"pacman": {PackageName: app.name}},
+					When:     app.when,
+				},
+			},
+			PkgInstalled: &notInstalled,
+		})
+		m.selectedApps[app.name] = true
+	}
+
+	installable, unavailable := m.collectBatchInstallItems()
+	if len(installable) != 1 || installable[0].Name != "ripgrep" || len(unavailable) != 0 {
+		t.Errorf("collectBatchInstallItems() = %+v, %+v, want only ripgrep", installable, unavailable)
+	}
+}
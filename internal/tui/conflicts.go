@@ -0,0 +1,381 @@
+package tui
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"charm.land/bubbles/v2/key"
+	tea "charm.land/bubbletea/v2"
+	"charm.land/lipgloss/v2"
+	"github.com/AntoineGS/tidydots/internal/manager"
+)
+
+// conflictItem is an entry whose restore would merge or replace existing
+// targets, asked about before the restore runs.
+type conflictItem struct {
+	key       subEntryKey
+	name      string
+	conflicts []manager.TargetConflict
+	choice    manager.Resolution
+}
+
+// conflictChoice is one option of the conflict popup. Choosing an option with
+// a resolution decides the entry; "view diff" only shows its differences.
+type conflictChoice struct {
+	label string
+	res   manager.Resolution
+	diff  bool
+}
+
+// conflictChoices are the options offered for each conflicting entry, the
+// way `git mergetool` offers them.
+var conflictChoices = []conflictChoice{
+	{label: "keep mine", res: manager.ResolveKeepTarget},
+	{label: "use backup", res: manager.ResolveUseBackup},
+	{label: "view diff", diff: true},
+	{label: "skip", res: manager.ResolveSkip},
+}
+
+// restoreConflicts returns the config entries of items whose targets conflict
+// with their backup. Setup and disabled entries have no files to conflict.
+func (m Model) restoreConflicts(items []SubEntryItem) []conflictItem {
+	var found []conflictItem
+
+	for _, item := range items {
+		if item.IsDisabled || !item.SubEntry.IsConfig() || item.Target == "" {
+			continue
+		}
+
+		conflicts := m.Manager.TargetConflicts(item.SubEntry, item.Target)
+		if len(conflicts) == 0 {
+			continue
+		}
+
+		found = append(found, conflictItem{
+			key:       subEntryKey{app: item.AppName, sub: item.SubEntry.Name},
+			name:      item.AppName + "/" + item.SubEntry.Name,
+			conflicts: conflicts,
+		})
+	}
+
+	return found
+}
+
+// startConflicts opens the conflict popup on items. batch tells whether the
+// restore that asked is the multi-select batch or the one at the cursor, so it
+// can be resumed once every entry is decided.
+func (m *Model) startConflicts(items []conflictItem, batch bool) {
+	m.resolvingConflicts = true
+	m.conflictItems = items
+	m.conflictIndex = 0
+	m.conflictCursor = 0
+	m.conflictDiff = ""
+	m.conflictScroll = 0
+	m.conflictBatch = batch
+}
+
+// stopConflicts closes the conflict popup.
+func (m *Model) stopConflicts() {
+	m.resolvingConflicts = false
+	m.conflictItems = nil
+	m.conflictIndex = 0
+	m.conflictCursor = 0
+	m.conflictDiff = ""
+	m.conflictScroll = 0
+}
+
+// updateConflicts handles key events while the conflict popup is showing.
+func (m Model) updateConflicts(msg tea.KeyPressMsg) (tea.Model, tea.Cmd) {
+	if key.Matches(msg, SharedKeys.ForceQuit) {
+		return m, tea.Quit
+	}
+
+	// The diff replaces the choices until it is closed.
+	if m.conflictDiff != "" {
+		switch {
+		case key.Matches(msg, ResultsPopupKeys.Up):
+			if m.conflictScroll > 0 {
+				m.conflictScroll--
+			}
+		case key.Matches(msg, ResultsPopupKeys.Down):
+			if m.conflictScroll < m.conflictMaxScroll() {
+				m.conflictScroll++
+			}
+		case key.Matches(msg, ResultsPopupKeys.Close):
+			m.conflictDiff = ""
+			m.conflictScroll = 0
+		}
+
+		return m, nil
+	}
+
+	switch {
+	case key.Matches(msg, ConflictKeys.Cancel):
+		// Nothing has been restored yet; cancel the whole restore.
+		m.stopConflicts()
+		return m, nil
+
+	case key.Matches(msg, ConflictKeys.Up):
+		if m.conflictCursor > 0 {
+			m.conflictCursor--
+		}
+
+	case key.Matches(msg, ConflictKeys.Down):
+		if m.conflictCursor < len(conflictChoices)-1 {
+			m.conflictCursor++
+		}
+
+	case key.Matches(msg, ConflictKeys.Select):
+		choice := conflictChoices[m.conflictCursor]
+		if choice.diff {
+			m.conflictDiff = conflictDiff(m.conflictItems[m.conflictIndex].conflicts)
+			return m, nil
+		}
+
+		m.conflictItems[m.conflictIndex].choice = choice.res
+		m.conflictIndex++
+		m.conflictCursor = 0
+
+		if m.conflictIndex == len(m.conflictItems) {
+			return m.finishConflicts()
+		}
+	}
+
+	return m, nil
+}
+
+// finishConflicts records the decisions and resumes the restore that asked.
+func (m Model) finishConflicts() (tea.Model, tea.Cmd) {
+	m.resolutions = make(map[subEntryKey]manager.Resolution, len(m.conflictItems))
+	for _, item := range m.conflictItems {
+		m.resolutions[item.key] = item.choice
+	}
+
+	batch := m.conflictBatch
+	m.stopConflicts()
+
+	if batch {
+		return m.executeConfirmedOperation()
+	}
+
+	return m.restoreAtCursor()
+}
+
+// conflictDiff describes how the targets of conflicts differ from the backup:
+// a line diff for a file, and the differing files for a folder.
+func conflictDiff(conflicts []manager.TargetConflict) string {
+	var b strings.Builder
+
+	for i, c := range conflicts {
+		if i > 0 {
+			b.WriteString("\n")
+		}
+
+		if c.IsDir {
+			b.WriteString(folderDiff(c.Backup, c.Target))
+			continue
+		}
+
+		backup, err := os.ReadFile(c.Backup) //nolint:gosec // backup file of a configured entry
+		if err != nil {
+			fmt.Fprintf(&b, "%s: %v\n", c.Backup, err)
+			continue
+		}
+
+		target, err := os.ReadFile(c.Target) //nolint:gosec // target file of a configured entry
+		if err != nil {
+			fmt.Fprintf(&b, "%s: %v\n", c.Target, err)
+			continue
+		}
+
+		b.WriteString(unifiedDiff("backup ("+c.Backup+")", "mine ("+c.Target+")", string(backup), string(target)))
+	}
+
+	return b.String()
+}
+
+// folderDiff lists the files that differ between the backup and target
+// folders, or are in only one of them.
+func folderDiff(backup, target string) string {
+	backupFiles := treeFiles(backup)
+	targetFiles := treeFiles(target)
+
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "--- backup (%s)\n+++ mine (%s)\n\n", backup, target)
+
+	names := make([]string, 0, len(backupFiles)+len(targetFiles))
+	for name := range backupFiles {
+		names = append(names, name)
+	}
+
+	for name := range targetFiles {
+		if _, ok := backupFiles[name]; !ok {
+			names = append(names, name)
+		}
+	}
+
+	slices.Sort(names)
+
+	differ := false
+
+	for _, name := range names {
+		backupData, inBackup := backupFiles[name]
+		targetData, inTarget := targetFiles[name]
+
+		switch {
+		case !inTarget:
+			fmt.Fprintf(&b, "- %s (only in backup)\n", name)
+		case !inBackup:
+			fmt.Fprintf(&b, "+ %s (only in mine)\n", name)
+		case backupData != targetData:
+			fmt.Fprintf(&b, "~ %s (differs)\n", name)
+		default:
+			continue
+		}
+
+		differ = true
+	}
+
+	if !differ {
+		b.WriteString("No differences found.\n")
+	}
+
+	return b.String()
+}
+
+// treeFiles returns the content of every regular file under root, by
+// slash-separated relative path. Unreadable files are left out.
+func treeFiles(root string) map[string]string {
+	files := make(map[string]string)
+
+	_ = filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error { //nolint:errcheck // unreadable parts are left out
+		if err != nil {
+			if errors.Is(err, fs.ErrPermission) && d != nil && d.IsDir() {
+				return filepath.SkipDir
+			}
+
+			return nil
+		}
+
+		if !d.Type().IsRegular() {
+			return nil
+		}
+
+		data, err := os.ReadFile(path) //nolint:gosec // file of a configured entry
+		if err != nil {
+			return nil
+		}
+
+		rel, err := filepath.Rel(root, path)
+		if err != nil {
+			return nil
+		}
+
+		files[filepath.ToSlash(rel)] = string(data)
+
+		return nil
+	})
+
+	return files
+}
+
+// conflictMaxScroll returns the maximum scroll offset of the diff shown in the
+// conflict popup.
+func (m Model) conflictMaxScroll() int {
+	lines := strings.Count(strings.TrimSuffix(m.conflictDiff, "\n"), "\n") + 1
+
+	maxOffset := lines - m.resultsPopupContentHeight()
+	if maxOffset < 0 {
+		return 0
+	}
+	return maxOffset
+}
+
+// renderConflictsPopup renders the conflict popup as a centered overlay: the
+// entry being decided, its conflicting targets and the choices, or its diff.
+func (m Model) renderConflictsPopup() string {
+	popupWidth := m.overlayWidth()
+
+	// Available content width: popup minus border (2) and padding (2*2)
+	contentWidth := popupWidth - 6
+
+	item := m.conflictItems[m.conflictIndex]
+
+	var b strings.Builder
+
+	if m.conflictDiff != "" {
+		lines := strings.Split(strings.TrimSuffix(m.conflictDiff, "\n"), "\n")
+		end := min(m.conflictScroll+m.resultsPopupContentHeight(), len(lines))
+
+		for _, line := range lines[m.conflictScroll:end] {
+			style := ListItemStyle
+			switch {
+			case strings.HasPrefix(line, "+ "):
+				style = SuccessStyle
+			case strings.HasPrefix(line, "- "):
+				style = ErrorStyle
+			}
+
+			b.WriteString(style.MaxWidth(contentWidth).Render(line))
+			b.WriteString("\n")
+		}
+
+		if len(lines) > end-m.conflictScroll {
+			b.WriteString(MutedTextStyle.Render(fmt.Sprintf("(%d-%d of %d)", m.conflictScroll+1, end, len(lines))))
+			b.WriteString("\n")
+		}
+
+		b.WriteString("\n")
+		b.WriteString(RenderHelpWithWidth(contentWidth,
+			PlainKeys(ResultsPopupKeys.Up), ResultsPopupKeys.Up.Help().Desc,
+			PlainKeys(ResultsPopupKeys.Down), ResultsPopupKeys.Down.Help().Desc,
+			PlainKeys(ResultsPopupKeys.Close), "back",
+		))
+	} else {
+		b.WriteString(SubtitleStyle.Render(item.name))
+		b.WriteString("\n")
+
+		for _, c := range item.conflicts {
+			b.WriteString(MutedTextStyle.MaxWidth(contentWidth).Render("  " + c.Target))
+			b.WriteString("\n")
+		}
+
+		b.WriteString("\n")
+
+		for i, choice := range conflictChoices {
+			cursor := "  "
+			style := ListItemStyle
+			if i == m.conflictCursor {
+				cursor = "> "
+				style = SelectedListItemStyle
+			}
+
+			fmt.Fprintf(&b, "%s%s\n", cursor, style.Render(choice.label))
+		}
+
+		b.WriteString("\n")
+		b.WriteString(RenderHelpWithWidth(contentWidth,
+			PlainKeys(ConflictKeys.Up, ConflictKeys.Down), "move",
+			PlainKeys(ConflictKeys.Select), ConflictKeys.Select.Help().Desc,
+			PlainKeys(ConflictKeys.Cancel), ConflictKeys.Cancel.Help().Desc,
+		))
+	}
+
+	titleStyle := lipgloss.NewStyle().Bold(true).Foreground(primaryColor)
+	title := titleStyle.Render(fmt.Sprintf("Conflict %d of %d", m.conflictIndex+1, len(m.conflictItems)))
+
+	popup := lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(primaryColor).
+		Padding(1, 2).
+		Width(popupWidth).
+		Render(title + "\n\n" + b.String())
+
+	return lipgloss.Place(m.width, m.height, lipgloss.Center, lipgloss.Center, popup)
+}
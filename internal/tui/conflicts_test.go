@@ -0,0 +1,262 @@
+package tui
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	tea "charm.land/bubbletea/v2"
+	"github.com/AntoineGS/tidydots/internal/config"
+	"github.com/AntoineGS/tidydots/internal/manager"
+	"github.com/AntoineGS/tidydots/internal/platform"
+)
+
+// newConflictModel returns a model over a zsh app whose two entries, rc and
+// env, each find a regular file where their link belongs.
+func newConflictModel(t *testing.T) (m Model, root, home string) {
+	t.Helper()
+
+	if runtime.GOOS == platform.OSWindows {
+		t.Skip("symlink tests are skipped on Windows")
+	}
+
+	root = t.TempDir()
+	home = t.TempDir()
+
+	for _, f := range []struct{ path, content string }{
+		{filepath.Join(root, "zsh", ".zshrc"), "backup rc\n"},
+		{filepath.Join(root, "zsh", ".zshenv"), "backup env\n"},
+		{filepath.Join(home, ".zshrc"), "my rc\n"},
+		{filepath.Join(home, ".zshenv"), "my env\n"},
+	} {
+		if err := os.MkdirAll(filepath.Dir(f.path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+
+		if err := os.WriteFile(f.path, []byte(f.content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	cfg := &config.Config{
+		Version:    3,
+		BackupRoot: root,
+		Applications: []config.Application{
+			{Name: "zsh", Entries: []config.SubEntry{
+				{Name: "rc", Backup: "./zsh", Files: []string{".zshrc"}, Targets: map[string]string{"linux": home}},
+				{Name: "env", Backup: "./zsh", Files: []string{".zshenv"}, Targets: map[string]string{"linux": home}},
+			}},
+		},
+	}
+
+	m = NewModelWithManager(cfg, linuxPlatform(), manager.New(cfg, linuxPlatform()), "")
+	m.width, m.height = 100, 40
+
+	return m, root, home
+}
+
+// pressConflictKeys sends keys to m one at a time and returns the updated
+// model and the command of the last key.
+func pressConflictKeys(t *testing.T, m Model, keys ...tea.KeyPressMsg) (Model, tea.Cmd) {
+	t.Helper()
+
+	var cmd tea.Cmd
+
+	for _, k := range keys {
+		var updated tea.Model
+
+		updated, cmd = m.handleKeyPress(k)
+
+		var ok bool
+		if m, ok = updated.(Model); !ok {
+			t.Fatalf("handleKeyPress returned %T, want Model", updated)
+		}
+	}
+
+	return m, cmd
+}
+
+var (
+	keyRestore = tea.KeyPressMsg{Code: 'r', Text: "r"}
+	keyDown    = tea.KeyPressMsg{Code: tea.KeyDown}
+	keyEnter   = tea.KeyPressMsg{Code: tea.KeyEnter}
+	keyEsc     = tea.KeyPressMsg{Code: tea.KeyEsc}
+)
+
+func readFileString(t *testing.T, path string) string {
+	t.Helper()
+
+	data, err := os.ReadFile(path) //nolint:gosec // test file
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return string(data)
+}
+
+func isSymlink(path string) bool {
+	info, err := os.Lstat(path)
+	return err == nil && info.Mode()&os.ModeSymlink != 0
+}
+
+func TestConflicts_RestoreAskedPerEntry(t *testing.T) {
+	m, root, home := newConflictModel(t)
+
+	m, _ = pressConflictKeys(t, m, keyRestore)
+
+	if !m.resolvingConflicts || len(m.conflictItems) != 2 {
+		t.Fatalf("resolvingConflicts = %v, items = %+v; want both entries asked about", m.resolvingConflicts, m.conflictItems)
+	}
+
+	if view := m.renderConflictsPopup(); !strings.Contains(view, "zsh/rc") || !strings.Contains(view, "Conflict 1 of 2") {
+		t.Errorf("popup does not name the first entry:\n%s", view)
+	}
+
+	// Nothing is restored until every entry is decided.
+	m, _ = pressConflictKeys(t, m, keyEnter)
+
+	if m.conflictIndex != 1 {
+		t.Fatalf("conflictIndex = %d after the first choice, want 1", m.conflictIndex)
+	}
+
+	if isSymlink(filepath.Join(home, ".zshrc")) {
+		t.Fatal(".zshrc restored before every entry was decided")
+	}
+
+	// keep mine for rc, skip for env
+	m, _ = pressConflictKeys(t, m, keyDown, keyDown, keyDown, keyEnter)
+
+	if m.resolvingConflicts || m.resolutions != nil {
+		t.Errorf("resolvingConflicts = %v, resolutions = %v after the restore; want both cleared", m.resolvingConflicts, m.resolutions)
+	}
+
+	if !m.showingResults || len(m.results) != 2 {
+		t.Fatalf("results = %+v, want one per entry", m.results)
+	}
+
+	if got := readFileString(t, filepath.Join(root, "zsh", ".zshrc")); got != "my rc\n" {
+		t.Errorf("backup .zshrc = %q, want the kept target", got)
+	}
+
+	if !isSymlink(filepath.Join(home, ".zshrc")) {
+		t.Error(".zshrc is not linked after keep mine")
+	}
+
+	if isSymlink(filepath.Join(home, ".zshenv")) || readFileString(t, filepath.Join(home, ".zshenv")) != "my env\n" {
+		t.Error(".zshenv was changed though it was skipped")
+	}
+
+	if r := m.results[1]; r.Name != "env" || !strings.Contains(r.Message, "kept the existing target") {
+		t.Errorf("env result = %+v, want it skipped", r)
+	}
+}
+
+func TestConflicts_CancelLeavesTargets(t *testing.T) {
+	m, _, home := newConflictModel(t)
+
+	m, _ = pressConflictKeys(t, m, keyRestore, keyEnter, keyEsc)
+
+	if m.resolvingConflicts || m.showingResults || m.resolutions != nil {
+		t.Errorf("resolvingConflicts = %v, showingResults = %v, resolutions = %v after cancel",
+			m.resolvingConflicts, m.showingResults, m.resolutions)
+	}
+
+	if isSymlink(filepath.Join(home, ".zshrc")) || isSymlink(filepath.Join(home, ".zshenv")) {
+		t.Error("a target was restored though the restore was cancelled")
+	}
+}
+
+func TestConflicts_ViewDiff(t *testing.T) {
+	m, _, _ := newConflictModel(t)
+
+	m, _ = pressConflictKeys(t, m, keyRestore, keyDown, keyDown, keyEnter)
+
+	if !strings.Contains(m.conflictDiff, "- backup rc") || !strings.Contains(m.conflictDiff, "+ my rc") {
+		t.Errorf("conflictDiff = %q, want the backup and target lines", m.conflictDiff)
+	}
+
+	if view := m.renderConflictsPopup(); !strings.Contains(view, "my rc") {
+		t.Errorf("popup does not show the diff:\n%s", view)
+	}
+
+	// Closing the diff returns to the same entry's choices.
+	m, _ = pressConflictKeys(t, m, keyEsc)
+
+	if !m.resolvingConflicts || m.conflictDiff != "" || m.conflictIndex != 0 {
+		t.Errorf("resolvingConflicts = %v, conflictDiff = %q, conflictIndex = %d; want the choices of the first entry",
+			m.resolvingConflicts, m.conflictDiff, m.conflictIndex)
+	}
+}
+
+func TestConflicts_BatchRestore(t *testing.T) {
+	m, root, home := newConflictModel(t)
+	m.multiSelectActive = true
+	m.selectedApps["zsh"] = true
+	m.summaryOperation = OpRestore
+	m.Screen = ScreenSummary
+
+	updated, cmd := m.executeConfirmedOperation()
+	m = updated.(Model)
+
+	if !m.resolvingConflicts || cmd != nil || m.Screen != ScreenSummary {
+		t.Fatalf("resolvingConflicts = %v, Screen = %v; want the popup over the summary", m.resolvingConflicts, m.Screen)
+	}
+
+	// use backup for both
+	m, cmd = pressConflictKeys(t, m, keyDown, keyEnter, keyDown, keyEnter)
+
+	if m.Screen != ScreenProgress || cmd == nil {
+		t.Fatalf("Screen = %v, cmd = %v; want the batch started", m.Screen, cmd)
+	}
+
+	msg, ok := cmd().(batchRestoreConfigsDoneMsg)
+	if !ok || msg.successCount != 2 {
+		t.Fatalf("batch result = %+v, want two restores", msg)
+	}
+
+	for _, name := range []string{".zshrc", ".zshenv"} {
+		if !isSymlink(filepath.Join(home, name)) {
+			t.Errorf("%s is not linked after use backup", name)
+		}
+	}
+
+	if got := readFileString(t, filepath.Join(root, "zsh", ".zshrc")); got != "backup rc\n" {
+		t.Errorf("backup .zshrc = %q, want it unchanged", got)
+	}
+}
+
+func TestFolderDiff(t *testing.T) {
+	backup := t.TempDir()
+	target := t.TempDir()
+
+	for _, f := range []struct{ path, content string }{
+		{filepath.Join(backup, "same"), "x"},
+		{filepath.Join(target, "same"), "x"},
+		{filepath.Join(backup, "lua", "init.lua"), "a"},
+		{filepath.Join(target, "lua", "init.lua"), "b"},
+		{filepath.Join(backup, "old"), "x"},
+		{filepath.Join(target, "new"), "x"},
+	} {
+		if err := os.MkdirAll(filepath.Dir(f.path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+
+		if err := os.WriteFile(f.path, []byte(f.content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	got := folderDiff(backup, target)
+
+	for _, want := range []string{"~ lua/init.lua (differs)", "+ new (only in mine)", "- old (only in backup)"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("folderDiff() missing %q:\n%s", want, got)
+		}
+	}
+
+	if strings.Contains(got, "same") {
+		t.Errorf("folderDiff() lists an identical file:\n%s", got)
+	}
+}
@@ -48,6 +48,7 @@ const (
 	StatusInstalled = tuishared.StatusInstalled
 	StatusMissing   = tuishared.StatusMissing
 	StatusFiltered  = tuishared.StatusFiltered
+	StatusDisabled  = tuishared.StatusDisabled
 	StatusOutdated  = tuishared.StatusOutdated
 	StatusModified  = tuishared.StatusModified
 	StatusUnknown   = tuishared.StatusUnknown
@@ -56,9 +57,10 @@ const (
 
 // Sort column constants — re-exported from tuishared.
 const (
-	SortColumnName   = tuishared.SortColumnName
-	SortColumnStatus = tuishared.SortColumnStatus
-	SortColumnPath   = tuishared.SortColumnPath
+	SortColumnName     = tuishared.SortColumnName
+	SortColumnStatus   = tuishared.SortColumnStatus
+	SortColumnPath     = tuishared.SortColumnPath
+	SortColumnPriority = tuishared.SortColumnPriority
 )
 
 // Scrolling behavior constants — re-exported from tuishared.
@@ -102,6 +104,8 @@ const (
 	CharLimitBinary  = tuishared.CharLimitBinary
 	CharLimitDep     = tuishared.CharLimitDep
 	CharLimitFile    = tuishared.CharLimitFile
+	CharLimitNotes   = tuishared.CharLimitNotes
 	InputWidthNarrow = tuishared.InputWidthNarrow
 	InputWidthWide   = tuishared.InputWidthWide
+	NotesInputHeight = tuishared.NotesInputHeight
 )
@@ -1,5 +1,24 @@
 package tui
 
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"charm.land/lipgloss/v2"
+
+	"github.com/AntoineGS/tidydots/internal/manager"
+	"github.com/AntoineGS/tidydots/internal/state"
+)
+
+// detailTimeLayout is how the detail panel shows operation timestamps, in
+// local time.
+const detailTimeLayout = "2006-01-02 15:04"
+
+// detailNotesMaxLines is how many wrapped lines of an application's notes
+// the detail panel shows before cutting them short.
+const detailNotesMaxLines = 10
+
 // detailTarget resolves the cursor row to the model items the inline detail
 // panel describes: (app, sub) for a sub-entry row, (app, nil) for an
 // application row, (nil, nil) when the panel is closed or the cursor resolves
@@ -39,3 +58,176 @@ func (m Model) detailContent() string {
 		return ""
 	}
 }
+
+// openDetail shows the detail panel for the cursor row, reloading the
+// operation history so it includes restores run since the TUI started.
+func (m *Model) openDetail() {
+	if m.Manager != nil {
+		if history, err := m.Manager.History(); err == nil {
+			m.history = history
+		}
+	}
+
+	m.notes = make(map[string]string)
+
+	for i := range m.Config.Applications {
+		app := &m.Config.Applications[i]
+
+		notes, err := app.ReadNotes(m.Config.BackupRoot)
+		if err != nil {
+			notes = "Notes could not be read: " + err.Error()
+		}
+
+		if notes = strings.TrimSpace(notes); notes != "" {
+			m.notes[app.Name] = notes
+		}
+	}
+
+	m.showingDetail = true
+}
+
+// renderSubEntryInlineDetail describes a sub-entry: its paths and when it
+// was last backed up and restored on this machine.
+func (m Model) renderSubEntryInlineDetail(sub *SubEntryItem, width int) string {
+	entry := sub.SubEntry
+
+	lines := []string{PathNameStyle.Render(sub.AppName + "/" + entry.Name)}
+
+	if entry.IsConfig() {
+		lines = append(lines,
+			detailLine("Target", sub.Target),
+			detailLine("Backup", m.resolvePath(entry.Backup)),
+		)
+	}
+
+	h := m.history[manager.HistoryKey{App: sub.AppName, Entry: entry.Name}]
+	lines = append(lines,
+		detailLine("Last backup", formatOperation(h.Backup)),
+		detailLine("Last restore", formatOperation(h.Restore)),
+	)
+
+	return renderDetailPanel(lines, width)
+}
+
+// renderApplicationInlineDetail describes an application: its description,
+// the applications its required_by names and those naming it, its when
+// expressions, those of its when_ref presets first, and the most recent
+// backup and restore across its entries.
+func (m Model) renderApplicationInlineDetail(app *ApplicationItem, width int) string {
+	lines := []string{PathNameStyle.Render(app.Application.Name)}
+
+	if app.Application.Description != "" {
+		lines = append(lines, MutedTextStyle.Render(app.Application.Description))
+	}
+
+	if required := app.Application.RequiredBy; len(required) > 0 {
+		lines = append(lines, detailLine("Requires", strings.Join(required, ", ")))
+	}
+
+	if dependents := m.dependentApplications(app.Application.Name); len(dependents) > 0 {
+		lines = append(lines, detailLine("Needed by", strings.Join(dependents, ", ")))
+	}
+
+	for _, preset := range m.Config.PresetWhens(&app.Application) {
+		lines = append(lines, detailLine("When", preset.When+MutedTextStyle.Render(" (preset "+preset.Name+")")))
+	}
+
+	if app.Application.When != "" {
+		lines = append(lines, detailLine("When", app.Application.When))
+	}
+
+	var lastBackup, lastRestore *state.OperationRecord
+
+	for _, sub := range app.SubItems {
+		h := m.history[manager.HistoryKey{App: app.Application.Name, Entry: sub.SubEntry.Name}]
+		lastBackup = laterOperation(lastBackup, h.Backup)
+		lastRestore = laterOperation(lastRestore, h.Restore)
+	}
+
+	lines = append(lines,
+		detailLine("Entries", fmt.Sprintf("%d", len(app.SubItems))),
+		detailLine("Last backup", formatOperation(lastBackup)),
+		detailLine("Last restore", formatOperation(lastRestore)),
+	)
+
+	if notes := m.notes[app.Application.Name]; notes != "" {
+		lines = append(lines, MutedTextStyle.Render("Notes:"))
+		lines = append(lines, wrapNotes(notes, width-4)...)
+	}
+
+	return renderDetailPanel(lines, width)
+}
+
+// wrapNotes word-wraps notes to width, keeping their line breaks, and cuts
+// them to detailNotesMaxLines.
+func wrapNotes(notes string, width int) []string {
+	wrapped := strings.Split(lipgloss.Wrap(notes, max(width, 1), ""), "\n")
+	if len(wrapped) <= detailNotesMaxLines {
+		return wrapped
+	}
+
+	more := len(wrapped) - detailNotesMaxLines + 1
+
+	return append(wrapped[:detailNotesMaxLines-1], MutedTextStyle.Render(fmt.Sprintf("(%d more lines)", more)))
+}
+
+// dependentApplications returns the applications whose required_by names
+// the application name, in config order.
+func (m Model) dependentApplications(name string) []string {
+	var dependents []string
+
+	for _, app := range m.Config.Applications {
+		if slices.Contains(app.RequiredBy, name) {
+			dependents = append(dependents, app.Name)
+		}
+	}
+
+	return dependents
+}
+
+func renderDetailPanel(lines []string, width int) string {
+	// Two border columns and two padding columns.
+	contentWidth := width - 4
+	if contentWidth < 1 {
+		contentWidth = 1
+	}
+
+	for i, line := range lines {
+		lines[i] = lipgloss.NewStyle().MaxWidth(contentWidth).Render(line)
+	}
+
+	// The inline detail panel below the table, framed like the popups.
+	panelStyle := lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(primaryColor).
+		Padding(0, 1)
+
+	return panelStyle.Render(strings.Join(lines, "\n"))
+}
+
+func detailLine(label, value string) string {
+	return MutedTextStyle.Render(fmt.Sprintf("%-14s", label+":")) + value
+}
+
+// formatOperation renders a recorded operation as its local time and the
+// tidydots version that ran it, or "never".
+func formatOperation(rec *state.OperationRecord) string {
+	if rec == nil {
+		return "never"
+	}
+
+	s := rec.RanAt.Local().Format(detailTimeLayout)
+	if rec.Version != "" {
+		s += " (" + rec.Version + ")"
+	}
+
+	return s
+}
+
+func laterOperation(a, b *state.OperationRecord) *state.OperationRecord {
+	if a == nil || (b != nil && b.RanAt.After(a.RanAt)) {
+		return b
+	}
+
+	return a
+}
@@ -1,6 +1,17 @@
 package tui
 
-import "testing"
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	tea "charm.land/bubbletea/v2"
+
+	"github.com/AntoineGS/tidydots/internal/manager"
+	"github.com/AntoineGS/tidydots/internal/state"
+)
 
 // TestDetailTarget_UnderFilter_ResolvesCursorApp guards the detail panel's
 // app resolution. The old inline code indexed filtered[appIdx] with a REAL
@@ -39,3 +50,131 @@ func TestDetailTarget_ClosedPanel_ReturnsNothing(t *testing.T) {
 		t.Errorf("detailTarget = (%v, %v), want (nil, nil) when the panel is closed", app, sub)
 	}
 }
+
+func TestExpand_SubEntryOpensDetail(t *testing.T) {
+	m := NewModel(orderProbeConfig(), linuxPlatform(), false)
+	m.width = 100
+	m.Applications[0].Expanded = true
+	m.rebuildTable()
+
+	// On an expanded application row, expand opens the detail panel too.
+	cursorToRow(t, &m, "conf-a")
+
+	updated, _ := m.updateResults(tea.KeyPressMsg{Code: tea.KeyEnter})
+
+	got, ok := updated.(Model)
+	if !ok {
+		t.Fatalf("updateResults returned %T, want Model", updated)
+	}
+
+	if !got.showingDetail {
+		t.Fatal("enter on a sub-entry row should open the detail panel")
+	}
+
+	if content := stripAnsiCodes(got.detailContent()); !strings.Contains(content, "alpha/conf-a") {
+		t.Errorf("detail panel does not describe alpha/conf-a:\n%s", content)
+	}
+}
+
+func TestDetailPanel_ShowsHistory(t *testing.T) {
+	m := NewModel(orderProbeConfig(), linuxPlatform(), false)
+	m.width = 100
+
+	backedUp := time.Date(2026, 3, 1, 12, 0, 0, 0, time.Local)
+	m.history = map[manager.HistoryKey]manager.EntryHistory{
+		{App: "alpha", Entry: "conf-a"}: {
+			Backup: &state.OperationRecord{RanAt: backedUp, Version: "1.2.3"},
+		},
+	}
+
+	sub := stripAnsiCodes(m.renderSubEntryInlineDetail(&m.Applications[0].SubItems[0], m.width))
+
+	for _, want := range []string{"Last backup:  2026-03-01 12:00 (1.2.3)", "Last restore: never"} {
+		if !strings.Contains(sub, want) {
+			t.Errorf("sub-entry detail is missing %q:\n%s", want, sub)
+		}
+	}
+
+	app := stripAnsiCodes(m.renderApplicationInlineDetail(&m.Applications[1], m.width))
+	if !strings.Contains(app, "Last backup:  never") {
+		t.Errorf("zebra was never backed up, detail says:\n%s", app)
+	}
+}
+
+func TestDetailPanel_ShowsRequiredBy(t *testing.T) {
+	cfg := orderProbeConfig()
+	cfg.Applications[1].RequiredBy = []string{"alpha"}
+
+	m := NewModel(cfg, linuxPlatform(), false)
+	m.width = 100
+
+	zebra := stripAnsiCodes(m.renderApplicationInlineDetail(&m.Applications[1], m.width))
+	if !strings.Contains(zebra, "Requires:     alpha") {
+		t.Errorf("zebra detail is missing its requirement:\n%s", zebra)
+	}
+
+	alpha := stripAnsiCodes(m.renderApplicationInlineDetail(&m.Applications[0], m.width))
+	if !strings.Contains(alpha, "Needed by:    zebra") {
+		t.Errorf("alpha detail is missing the application needing it:\n%s", alpha)
+	}
+}
+
+func TestDetailPanel_ShowsWhenPresets(t *testing.T) {
+	cfg := orderProbeConfig()
+	cfg.WhenPresets = map[string]string{"anywhere": "true"}
+	cfg.Applications[1].WhenRef = []string{"anywhere"}
+	cfg.Applications[1].When = `{{ eq .OS "linux" }}`
+
+	m := NewModel(cfg, linuxPlatform(), false)
+	m.width = 100
+
+	zebra := stripAnsiCodes(m.renderApplicationInlineDetail(&m.Applications[1], m.width))
+	if !strings.Contains(zebra, "true (preset anywhere)") || !strings.Contains(zebra, `{{ eq .OS "linux" }}`) {
+		t.Errorf("zebra detail is missing its preset or own when:\n%s", zebra)
+	}
+}
+
+func TestDetailPanel_ShowsNotes(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "zebra.md"), []byte("Key is in the vault.\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := orderProbeConfig()
+	cfg.BackupRoot = dir
+	cfg.Applications[0].Notes = "Run the installer by hand, then sign in with the work account before restoring.\nDisable telemetry."
+	cfg.Applications[1].NotesFile = "zebra.md"
+
+	m := NewModel(cfg, linuxPlatform(), false)
+	m.width = 40
+	m.openDetail()
+
+	alpha := stripAnsiCodes(m.renderApplicationInlineDetail(&m.Applications[0], m.width))
+	for _, want := range []string{"Notes:", "Run the installer by hand,", "Disable telemetry."} {
+		if !strings.Contains(alpha, want) {
+			t.Errorf("alpha detail is missing %q:\n%s", want, alpha)
+		}
+	}
+
+	if strings.Contains(alpha, "…") {
+		t.Errorf("alpha notes are cut instead of wrapped:\n%s", alpha)
+	}
+
+	zebra := stripAnsiCodes(m.renderApplicationInlineDetail(&m.Applications[1], m.width))
+	if !strings.Contains(zebra, "Key is in the vault.") {
+		t.Errorf("zebra detail is missing its notes file:\n%s", zebra)
+	}
+}
+
+func TestWrapNotes_CutsLongNotes(t *testing.T) {
+	notes := strings.Repeat("line\n", 20)
+
+	lines := wrapNotes(strings.TrimSpace(notes), 40)
+	if len(lines) != detailNotesMaxLines {
+		t.Fatalf("wrapNotes() = %d lines, want %d", len(lines), detailNotesMaxLines)
+	}
+
+	if last := stripAnsiCodes(lines[len(lines)-1]); last != "(11 more lines)" {
+		t.Errorf("last line = %q, want (11 more lines)", last)
+	}
+}
@@ -2,6 +2,7 @@ package detection
 
 import (
 	"context"
+	"slices"
 	"time"
 
 	"github.com/AntoineGS/tidydots/internal/config"
@@ -61,8 +62,12 @@ func GetPackageInstallMethod(pkg *config.EntryPackage, osType string) string {
 		return tuishared.TypeNone
 	}
 
-	// Check package managers
+	// Check package managers, the preferred one first
 	availableManagers := DetectAvailableManagers()
+	if _, ok := pkg.Managers[pkg.Prefer]; ok && slices.Contains(availableManagers, pkg.Prefer) {
+		return pkg.Prefer
+	}
+
 	for _, mgr := range availableManagers {
 		if _, ok := pkg.Managers[mgr]; ok {
 			return mgr
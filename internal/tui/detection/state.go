@@ -7,24 +7,40 @@ import (
 	"bytes"
 	"os"
 	"path/filepath"
+	"strings"
 
+	"github.com/AntoineGS/tidydots/internal/config"
+	"github.com/AntoineGS/tidydots/internal/fsys"
+	"github.com/AntoineGS/tidydots/internal/manager"
+	"github.com/AntoineGS/tidydots/internal/objects"
+	"github.com/AntoineGS/tidydots/internal/platform"
 	tuitable "github.com/AntoineGS/tidydots/internal/tui/table"
 )
 
 // pathExists reports whether the path exists on the filesystem, using os.Lstat
 // so that broken symlinks are still reported as existing.
 func pathExists(path string) bool {
-	_, err := os.Lstat(path)
+	_, err := os.Lstat(platform.LongPath(path))
 	return err == nil
 }
 
+// isJunctionTo reports whether path is a Windows directory junction pointing
+// at dir, as restore creates with symlink_compat: junction. Windows paths are
+// case-insensitive, so the comparison is too.
+func isJunctionTo(path, dir string) bool {
+	target, ok := platform.JunctionTarget(path)
+	return ok && strings.EqualFold(filepath.Clean(target), filepath.Clean(dir))
+}
+
 // DetectConfigState determines the state of a config entry given its paths and file list.
 // This is a pure function that takes paths and returns a PathState. It only uses
-// os.Lstat and filepath.Join. It does NOT reference Model.
+// os.Lstat, os.Readlink and filepath.Join, with long Windows paths prefixed
+// through platform.LongPath. A folder linked to the backup with a directory
+// junction counts as linked. It does NOT reference Model.
 func DetectConfigState(backupPath, targetPath string, isFolder bool, files []string, isCopy bool) tuitable.PathState {
 	if isFolder {
-		if info, err := os.Lstat(targetPath); err == nil {
-			if info.Mode()&os.ModeSymlink != 0 {
+		if info, err := os.Lstat(platform.LongPath(targetPath)); err == nil {
+			if info.Mode()&os.ModeSymlink != 0 || isJunctionTo(targetPath, backupPath) {
 				return tuitable.StateLinked
 			}
 		}
@@ -60,7 +76,7 @@ func DetectConfigState(backupPath, targetPath string, isFolder bool, files []str
 		checkedAnyFile = true
 		anyBackup = true
 
-		if info, err := os.Lstat(dstFile); err == nil {
+		if info, err := os.Lstat(platform.LongPath(dstFile)); err == nil {
 			anyTarget = true
 			if isCopy {
 				// os.ReadFile follows symlinks, so a stale symlink pointing back
@@ -92,16 +108,65 @@ func DetectConfigState(backupPath, targetPath string, isFolder bool, files []str
 	return tuitable.StateMissing
 }
 
-// filesContentEqual reports whether two files have identical contents. Any read
-// error (missing or unreadable file) counts as not equal.
+// DetectSubEntryState determines the state of the config entry sub from its
+// expanded backup and target paths, with DetectLinkFarmState for a
+// link_mode: files entry and DetectConfigState for any other.
+func DetectSubEntryState(sub config.SubEntry, backupPath, targetPath string) tuitable.PathState {
+	if sub.LinksFiles() {
+		return DetectLinkFarmState(backupPath, targetPath)
+	}
+
+	return DetectConfigState(backupPath, targetPath, sub.IsFolder(), sub.Files, sub.IsCopy())
+}
+
+// DetectLinkFarmState determines the state of a link_mode: files entry:
+// linked when each file of the backup (see manager.LinkFarmFiles) has a
+// symlink at its path in the target, and partial when only some do, such as
+// after files were added to the backup since the last restore. Other files
+// in the target do not count.
+func DetectLinkFarmState(backupPath, targetPath string) tuitable.PathState {
+	if !pathExists(backupPath) {
+		return tuitable.StateMissing
+	}
+
+	files, err := manager.LinkFarmFiles(fsys.OsFS{}, platform.LongPath(backupPath))
+	if err != nil {
+		return tuitable.StateReady
+	}
+
+	linked := 0
+
+	for _, file := range files {
+		info, err := os.Lstat(platform.LongPath(filepath.Join(targetPath, file)))
+		if err == nil && info.Mode()&os.ModeSymlink != 0 {
+			linked++
+		}
+	}
+
+	switch linked {
+	case len(files):
+		return tuitable.StateLinked
+	case 0:
+		return tuitable.StateReady
+	default:
+		return tuitable.StatePartial
+	}
+}
+
+// filesContentEqual reports whether two files have identical contents. When a
+// is a dedupe object pointer, b is compared against the content it points
+// to. Any read error (missing or unreadable file) counts as not equal.
 func filesContentEqual(a, b string) bool {
-	da, err := os.ReadFile(a)
+	da, err := os.ReadFile(platform.LongPath(a))
 	if err != nil {
 		return false
 	}
-	db, err := os.ReadFile(b)
+	db, err := os.ReadFile(platform.LongPath(b))
 	if err != nil {
 		return false
 	}
+	if ptr, ok := objects.Parse(da); ok {
+		return ptr.Matches(db)
+	}
 	return bytes.Equal(da, db)
 }
@@ -5,6 +5,8 @@ import (
 	"path/filepath"
 	"testing"
 
+	"github.com/AntoineGS/tidydots/internal/config"
+	"github.com/AntoineGS/tidydots/internal/objects"
 	tuitable "github.com/AntoineGS/tidydots/internal/tui/table"
 )
 
@@ -284,6 +286,27 @@ func TestDetectConfigState_Copy_Drift(t *testing.T) {
 	}
 }
 
+func TestDetectConfigState_Copy_ObjectPointer(t *testing.T) {
+	t.Parallel()
+	dir := t.TempDir()
+	backup := filepath.Join(dir, "backup")
+	target := filepath.Join(dir, "target")
+	_ = os.MkdirAll(backup, 0755)
+	_ = os.MkdirAll(target, 0755)
+	_ = os.WriteFile(filepath.Join(backup, "f"), []byte(objects.NewPointer([]byte("blob")).String()), 0644)
+	_ = os.WriteFile(filepath.Join(target, "f"), []byte("blob"), 0644)
+
+	if got := DetectConfigState(backup, target, false, []string{"f"}, true); got != tuitable.StateLinked {
+		t.Errorf("state = %v, want StateLinked (target matches the pointed-to object)", got)
+	}
+
+	_ = os.WriteFile(filepath.Join(target, "f"), []byte("edited"), 0644)
+
+	if got := DetectConfigState(backup, target, false, []string{"f"}, true); got != tuitable.StateReady {
+		t.Errorf("state = %v, want StateReady (target differs from the object)", got)
+	}
+}
+
 func TestDetectConfigState_Copy_TargetMissing(t *testing.T) {
 	t.Parallel()
 	dir := t.TempDir()
@@ -317,3 +340,46 @@ func TestDetectConfigState_Copy_TargetStillSymlink(t *testing.T) {
 		t.Errorf("state = %v, want StateReady (a symlinked target must not report in sync)", got)
 	}
 }
+
+// ── Link farm tests ─────────────────────────────────────────────────────────
+
+func TestDetectSubEntryState_LinkFarm(t *testing.T) {
+	tmp := t.TempDir()
+	backupPath := filepath.Join(tmp, "backup")
+	targetPath := filepath.Join(tmp, "target")
+	sub := config.SubEntry{Name: "apps", Backup: "./backup", LinkMode: config.LinkModeFiles}
+
+	if got := DetectSubEntryState(sub, backupPath, targetPath); got != tuitable.StateMissing {
+		t.Errorf("no backup → want StateMissing, got %v", got)
+	}
+
+	mkFile(t, filepath.Join(backupPath, "a.desktop"))
+	mkFile(t, filepath.Join(backupPath, "sub", "b.desktop"))
+	mkFile(t, filepath.Join(backupPath, "a.desktop.sha256"))
+	mkFile(t, filepath.Join(targetPath, "other.desktop"))
+
+	if got := DetectSubEntryState(sub, backupPath, targetPath); got != tuitable.StateReady {
+		t.Errorf("nothing linked → want StateReady, got %v", got)
+	}
+
+	mkSymlink(t, filepath.Join(backupPath, "a.desktop"), filepath.Join(targetPath, "a.desktop"))
+	mkDir(t, filepath.Join(targetPath, "sub"))
+	mkSymlink(t, filepath.Join(backupPath, "sub", "b.desktop"), filepath.Join(targetPath, "sub", "b.desktop"))
+
+	if got := DetectSubEntryState(sub, backupPath, targetPath); got != tuitable.StateLinked {
+		t.Errorf("every file linked → want StateLinked, got %v", got)
+	}
+
+	// A file added to the backup after the restore is not linked yet.
+	mkFile(t, filepath.Join(backupPath, "c.desktop"))
+
+	if got := DetectSubEntryState(sub, backupPath, targetPath); got != tuitable.StatePartial {
+		t.Errorf("file added after restore → want StatePartial, got %v", got)
+	}
+
+	// The same folder without link_mode: files is judged by its directory.
+	sub.LinkMode = ""
+	if got := DetectSubEntryState(sub, backupPath, targetPath); got != tuitable.StateReady {
+		t.Errorf("directory mode with a real target → want StateReady, got %v", got)
+	}
+}
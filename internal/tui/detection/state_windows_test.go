@@ -0,0 +1,57 @@
+//go:build windows
+
+package detection
+
+import (
+	"context"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	tuitable "github.com/AntoineGS/tidydots/internal/tui/table"
+)
+
+// mkJunction creates a directory junction at dst pointing to src.
+func mkJunction(t *testing.T, src, dst string) {
+	t.Helper()
+	out, err := exec.CommandContext(context.Background(), "cmd", "/c", "mklink", "/J", dst, src).CombinedOutput() //nolint:gosec // test command
+	if err != nil {
+		t.Fatalf("mklink /J %q %q: %v\n%s", dst, src, err, out)
+	}
+}
+
+func TestDetectConfigState_FolderJunction(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		toBackup bool
+		want     tuitable.PathState
+	}{
+		{"junction to the backup is linked", true, tuitable.StateLinked},
+		{"junction elsewhere is not", false, tuitable.StateReady},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			dir := t.TempDir()
+			backup := filepath.Join(dir, "backup")
+			other := filepath.Join(dir, "other")
+			target := filepath.Join(dir, "target")
+			mkDir(t, backup)
+			mkDir(t, other)
+
+			if tt.toBackup {
+				mkJunction(t, backup, target)
+			} else {
+				mkJunction(t, other, target)
+			}
+
+			if got := DetectConfigState(backup, target, true, nil, false); got != tt.want {
+				t.Errorf("DetectConfigState() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,66 @@
+package tui
+
+import (
+	tea "charm.land/bubbletea/v2"
+	"github.com/AntoineGS/tidydots/internal/manager"
+)
+
+// dirtyCheckResultMsg carries the backup files with uncommitted git changes,
+// or nil when the check does not apply (see manager.DirtyBackupFiles).
+type dirtyCheckResultMsg struct {
+	files map[string]bool
+}
+
+// checkDirtyCmd runs the backup repo's git status once in the background.
+// A failing git status only loses the Dirty hint, so the error is dropped and
+// entries stay Linked.
+func (m Model) checkDirtyCmd() tea.Cmd {
+	mgr := m.Manager
+	if mgr == nil {
+		return nil
+	}
+
+	return func() tea.Msg {
+		files, _ := mgr.DirtyBackupFiles() //nolint:errcheck // best-effort hint
+		return dirtyCheckResultMsg{files: files}
+	}
+}
+
+// handleDirtyCheckResult stores the dirty file set and re-evaluates every
+// linked entry against it. The result may land before or after the per-entry
+// state checks; handleStateCheckResult covers the other order.
+func (m Model) handleDirtyCheckResult(msg dirtyCheckResultMsg) (tea.Model, tea.Cmd) {
+	m.dirtyFiles = msg.files
+
+	for i := range m.Applications {
+		for j := range m.Applications[i].SubItems {
+			item := &m.Applications[i].SubItems[j]
+			item.State = m.withDirtyState(item, item.State)
+		}
+	}
+
+	if m.pendingStateChecks == 0 {
+		m.initTableModel()
+	}
+
+	return m, nil
+}
+
+// withDirtyState turns a Linked symlink entry into Dirty when its backup files
+// have uncommitted changes, and a Dirty one back into Linked once they are
+// committed. Other states are returned unchanged.
+func (m *Model) withDirtyState(item *SubEntryItem, st PathState) PathState {
+	if st != StateLinked && st != StateDirty {
+		return st
+	}
+
+	if !item.SubEntry.IsConfig() || item.SubEntry.IsCopy() {
+		return st
+	}
+
+	if manager.EntryIsDirty(item.SubEntry, m.resolvePath(item.SubEntry.Backup), m.dirtyFiles) {
+		return StateDirty
+	}
+
+	return StateLinked
+}
@@ -0,0 +1,71 @@
+package tui
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/AntoineGS/tidydots/internal/config"
+	"github.com/AntoineGS/tidydots/internal/platform"
+)
+
+// TestDirtyCheckResult_MarksLinkedEntries covers both arrival orders of the
+// git status result relative to the per-entry state checks.
+func TestDirtyCheckResult_MarksLinkedEntries(t *testing.T) {
+	repo := filepath.Join("/", "repo")
+	copyEntry := config.SubEntry{Name: "copied", Backup: "vicinae", Files: []string{"a.conf"}, Method: config.MethodCopy}
+	dirty := map[string]bool{
+		filepath.Join(repo, "vicinae", "settings.json"): true,
+		filepath.Join(repo, "vicinae", "a.conf"):        true,
+	}
+
+	newModel := func() Model {
+		return Model{
+			Config:   &config.Config{Version: 3, BackupRoot: repo},
+			Platform: &platform.Platform{OS: platform.OSLinux, EnvVars: map[string]string{}},
+			Applications: []ApplicationItem{{
+				Application: config.Application{Name: "vicinae"},
+				SubItems: []SubEntryItem{
+					{AppName: "vicinae", SubEntry: configSubEntry(), State: StateLinked},
+					{AppName: "vicinae", SubEntry: configSubEntry(), State: StateMissing},
+					{AppName: "vicinae", SubEntry: copyEntry, State: StateLinked},
+				},
+			}},
+		}
+	}
+
+	want := []PathState{StateDirty, StateMissing, StateLinked}
+
+	check := func(t *testing.T, m Model) {
+		t.Helper()
+
+		for i, item := range m.Applications[0].SubItems {
+			if item.State != want[i] {
+				t.Errorf("sub-entry %d state = %v, want %v", i, item.State, want[i])
+			}
+		}
+	}
+
+	t.Run("dirty result after state checks", func(t *testing.T) {
+		next, _ := newModel().handleDirtyCheckResult(dirtyCheckResultMsg{files: dirty})
+		check(t, next.(Model))
+	})
+
+	t.Run("state check after dirty result", func(t *testing.T) {
+		m := newModel()
+		m.dirtyFiles = dirty
+		m.Applications[0].SubItems[0].State = StateLoading
+
+		next, _ := m.handleStateCheckResult(stateCheckResultMsg{appIndex: 0, subIndex: 0, state: StateLinked})
+		check(t, next.(Model))
+	})
+
+	t.Run("committed changes clear the dirty state", func(t *testing.T) {
+		m := newModel()
+		m.Applications[0].SubItems[0].State = StateDirty
+
+		next, _ := m.handleDirtyCheckResult(dirtyCheckResultMsg{})
+		if got := next.(Model).Applications[0].SubItems[0].State; got != StateLinked {
+			t.Errorf("state = %v, want %v", got, StateLinked)
+		}
+	})
+}
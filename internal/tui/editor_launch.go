@@ -38,13 +38,19 @@ type editorLaunchCompleteMsg struct {
 // generateUnifiedDiff creates a unified diff string between the pure render (from DB)
 // and the current on-disk content, using sergi/go-diff.
 func generateUnifiedDiff(mt manager.ModifiedTemplate) string {
+	return unifiedDiff("pure render (from DB)", fmt.Sprintf("edited file (%s)", mt.RenderedPath),
+		string(mt.PureRender), string(mt.CurrentOnDisk))
+}
+
+// unifiedDiff creates a line diff from oldText, labeled oldLabel, to newText.
+func unifiedDiff(oldLabel, newLabel, oldText, newText string) string {
 	dmp := diffmatchpatch.New()
 
-	a, b, lineArray := dmp.DiffLinesToChars(string(mt.PureRender), string(mt.CurrentOnDisk))
+	a, b, lineArray := dmp.DiffLinesToChars(oldText, newText)
 	diffs := dmp.DiffMain(a, b, false)
 	diffs = dmp.DiffCharsToLines(diffs, lineArray)
 
-	patches := dmp.PatchMake(string(mt.PureRender), diffs)
+	patches := dmp.PatchMake(oldText, diffs)
 
 	if len(patches) == 0 {
 		return "No differences found.\n"
@@ -52,8 +58,8 @@ func generateUnifiedDiff(mt manager.ModifiedTemplate) string {
 
 	// Build a readable unified diff output
 	var sb strings.Builder
-	sb.WriteString("--- pure render (from DB)\n")
-	fmt.Fprintf(&sb, "+++ edited file (%s)\n", mt.RenderedPath)
+	sb.WriteString("--- " + oldLabel + "\n")
+	sb.WriteString("+++ " + newLabel + "\n")
 	sb.WriteString("\n")
 
 	for _, diff := range diffs {
@@ -0,0 +1,265 @@
+package tui
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	tea "charm.land/bubbletea/v2"
+
+	"github.com/AntoineGS/tidydots/internal/config"
+)
+
+// parkedConfig has an enabled application with one disabled entry, a
+// disabled application with a package, and two disabled applications whose
+// when expressions do and do not match, for the enabled/when interaction.
+func parkedConfig() *config.Config {
+	disabled := false
+
+	return &config.Config{
+		Version:    3,
+		BackupRoot: "/repo",
+		Applications: []config.Application{
+			{
+				Name: "shell",
+				Entries: []config.SubEntry{
+					{Name: "zshrc", Backup: "./zsh", Targets: map[string]string{"linux": "~/.zsh"}},
+					{Name: "old-bashrc", Backup: "./bash", Targets: map[string]string{"linux": "~/.bash"}, Enabled: &disabled},
+				},
+			},
+			{
+				Name:    "game",
+				Enabled: &disabled,
+				Package: &config.EntryPackage{Managers: map[string]config.ManagerValue{"pacman": {PackageName: "steam"}}},
+				Entries: []config.SubEntry{
+					{Name: "saves", Backup: "./game", Targets: map[string]string{"linux": "~/.game"}},
+				},
+			},
+			{
+				Name:    "linux-parked",
+				When:    `{{ eq .OS "linux" }}`,
+				Enabled: &disabled,
+				Entries: []config.SubEntry{
+					{Name: "lp", Backup: "./lp", Targets: map[string]string{"linux": "~/.lp"}},
+				},
+			},
+			{
+				Name:    "windows-parked",
+				When:    `{{ eq .OS "windows" }}`,
+				Enabled: &disabled,
+				Entries: []config.SubEntry{
+					{Name: "wp", Backup: "./wp", Targets: map[string]string{"linux": "~/.wp"}},
+				},
+			},
+		},
+	}
+}
+
+// appItem returns the named application item.
+func appItem(t *testing.T, m *Model, name string) ApplicationItem {
+	t.Helper()
+
+	for _, app := range m.Applications {
+		if app.Application.Name == name {
+			return app
+		}
+	}
+
+	t.Fatalf("no application named %q", name)
+
+	return ApplicationItem{}
+}
+
+// pressToggleEnabled sends the "x" keypress and returns the updated model.
+func pressToggleEnabled(t *testing.T, m *Model) *Model {
+	t.Helper()
+
+	updated, _ := m.updateResults(tea.KeyPressMsg{Code: 'x', Text: "x"})
+
+	got, ok := updated.(Model)
+	if !ok {
+		t.Fatalf("updateResults returned %T, want Model", updated)
+	}
+
+	if got.err != nil {
+		t.Fatalf("toggle failed: %v", got.err)
+	}
+
+	return &got
+}
+
+func TestDisabledItems_StartResolvedAndUnchecked(t *testing.T) {
+	m := NewModel(parkedConfig(), linuxPlatform(), false)
+
+	shell := appItem(t, &m, "shell")
+	if shell.IsDisabled {
+		t.Error("shell should be enabled")
+	}
+
+	old := shell.SubItems[1]
+	if old.SubEntry.Name != "old-bashrc" {
+		t.Fatalf("shell.SubItems[1] = %q, want old-bashrc", old.SubEntry.Name)
+	}
+	if !old.IsDisabled || old.State != StateDisabled {
+		t.Errorf("old-bashrc: IsDisabled = %v, State = %v, want disabled", old.IsDisabled, old.State)
+	}
+
+	game := appItem(t, &m, "game")
+	if !game.IsDisabled || !game.SubItems[0].IsDisabled {
+		t.Error("game and its entries should be disabled")
+	}
+
+	// Only shell/zshrc is checked: shell has no package, every other item is
+	// disabled.
+	if got := m.countInitialStateChecks(); got != 1 {
+		t.Errorf("countInitialStateChecks() = %d, want 1", got)
+	}
+
+	if _, n := m.checkPackageStatesCmd(); n != 0 {
+		t.Errorf("checkPackageStatesCmd dispatched %d checks, want 0", n)
+	}
+
+	if _, n := m.checkSubEntryStatesCmd(); n != 1 {
+		t.Errorf("checkSubEntryStatesCmd dispatched %d checks, want 1", n)
+	}
+}
+
+func TestDisabledItems_WhenInteraction(t *testing.T) {
+	m := NewModel(parkedConfig(), linuxPlatform(), false)
+
+	// A disabled app whose when matches is still listed, greyed out.
+	m.filterEnabled = true
+	m.rebuildTable()
+
+	rowFor := func(name string) *TableRow {
+		for i := range m.tableRows {
+			if m.tableRows[i].SubIndex < 0 && m.tableRows[i].AppName == name {
+				return &m.tableRows[i]
+			}
+		}
+		return nil
+	}
+
+	row := rowFor("linux-parked")
+	if row == nil {
+		t.Fatal("linux-parked should be listed with the filter on")
+	}
+	if !row.Disabled || row.Data[1] != StatusDisabled {
+		t.Errorf("linux-parked row: Disabled = %v, status = %q, want disabled", row.Disabled, row.Data[1])
+	}
+
+	// A disabled app whose when does not match is hidden like any filtered app.
+	if rowFor("windows-parked") != nil {
+		t.Error("windows-parked should be hidden with the filter on")
+	}
+
+	// With the filter off it is shown, and reads as disabled rather than filtered.
+	m.filterEnabled = false
+	m.rebuildTable()
+
+	row = rowFor("windows-parked")
+	if row == nil {
+		t.Fatal("windows-parked should be listed with the filter off")
+	}
+	if row.Data[1] != StatusDisabled {
+		t.Errorf("windows-parked status = %q, want %q", row.Data[1], StatusDisabled)
+	}
+
+	// Revealing filtered apps does not check disabled ones.
+	if _, n := m.checkFilteredStatesCmd(); n != 0 {
+		t.Errorf("checkFilteredStatesCmd dispatched %d checks, want 0", n)
+	}
+}
+
+func TestDisabledItems_NotSelectedOrRestored(t *testing.T) {
+	m := NewModel(parkedConfig(), linuxPlatform(), false)
+
+	for i, app := range m.Applications {
+		if app.Application.Name == "shell" || app.Application.Name == "game" {
+			m.toggleAppSelection(i)
+		}
+	}
+
+	if m.isAppSelected("game") {
+		t.Error("a disabled application should not be selectable")
+	}
+
+	items := m.collectBatchRestoreItems()
+	if len(items) != 1 || items[0].name != "shell/zshrc" {
+		t.Errorf("collectBatchRestoreItems() = %v, want only shell/zshrc", items)
+	}
+
+	if summary := m.renderHierarchicalSummary("restore"); strings.Contains(summary, "old-bashrc") {
+		t.Errorf("restore summary lists a disabled entry:\n%s", summary)
+	}
+}
+
+func TestToggleEnabled_SavesConfig(t *testing.T) {
+	m, path := modelOnDisk(t, parkedConfig())
+
+	shellIdx := -1
+	for i, app := range m.Applications {
+		if app.Application.Name == "shell" {
+			shellIdx = i
+		}
+	}
+
+	m.Applications[shellIdx].Expanded = true
+	m.rebuildTable()
+
+	cursorToRow(t, m, "zshrc")
+	m = pressToggleEnabled(t, m)
+
+	loaded, err := config.Load(path)
+	if err != nil {
+		t.Fatalf("reloading config: %v", err)
+	}
+	if loaded.Applications[0].Entries[0].IsEnabled() {
+		t.Error("zshrc should be saved as disabled")
+	}
+
+	zshrc := appItem(t, m, "shell").SubItems[0]
+	if !zshrc.IsDisabled || zshrc.State != StateDisabled {
+		t.Errorf("zshrc: IsDisabled = %v, State = %v, want disabled", zshrc.IsDisabled, zshrc.State)
+	}
+
+	// Toggling back clears the flag instead of writing enabled: true.
+	cursorToRow(t, m, "zshrc")
+	m = pressToggleEnabled(t, m)
+
+	if m.Config.Applications[0].Entries[0].Enabled != nil {
+		t.Error("re-enabling should clear the enabled flag")
+	}
+
+	cursorToRow(t, m, "game")
+	m = pressToggleEnabled(t, m)
+
+	if !m.Config.Applications[1].IsEnabled() || appItem(t, m, "game").IsDisabled {
+		t.Error("x on a disabled application should enable it")
+	}
+}
+
+func TestWriteTree_Disabled(t *testing.T) {
+	cfg := parkedConfig()
+	cfg.Applications = cfg.Applications[:2]
+
+	var buf bytes.Buffer
+	if err := WriteTree(&buf, cfg, linuxPlatform(), false); err != nil {
+		t.Fatalf("WriteTree() error = %v", err)
+	}
+
+	if out := buf.String(); strings.Contains(out, "game") || strings.Contains(out, "old-bashrc") {
+		t.Errorf("WriteTree() without showDisabled lists disabled items:\n%s", out)
+	}
+
+	buf.Reset()
+	if err := WriteTree(&buf, cfg, linuxPlatform(), true); err != nil {
+		t.Fatalf("WriteTree() error = %v", err)
+	}
+
+	for _, want := range []string{"game (disabled)", "old-bashrc (disabled)"} {
+		if !strings.Contains(buf.String(), want) {
+			t.Errorf("WriteTree() with showDisabled is missing %q:\n%s", want, buf.String())
+		}
+	}
+}
@@ -513,3 +513,111 @@ func TestFilePickerIntegration_ModeMenuNavigation(t *testing.T) {
 		t.Errorf("after 'k' (vim up): modeMenuCursor = %d, want 2 (Type)", m.subEntryForm.ModeMenuCursor)
 	}
 }
+
+// sendPickerKey passes msg to the sub-entry form and feeds the directory read
+// it triggers back to the model, as the bubbletea runtime would.
+func sendPickerKey(t *testing.T, m Model, msg tea.KeyPressMsg) Model {
+	t.Helper()
+
+	updated, cmd := m.updateSubEntryForm(msg)
+	m = updated.(Model)
+
+	if cmd != nil {
+		updated, _ = m.Update(cmd())
+		m = updated.(Model)
+	}
+
+	return m
+}
+
+func TestPathPicker_TargetAndBackup(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("USERPROFILE", home)
+
+	repo := filepath.Join(home, "dotfiles")
+	for _, dir := range []string{filepath.Join(home, ".config"), filepath.Join(repo, "nvim")} {
+		//nolint:gosec // Test file - directory permissions are safe for test
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	cfg := &config.Config{Applications: []config.Application{{
+		Name:    "test-app",
+		Entries: []config.SubEntry{{Name: "placeholder", Targets: map[string]string{"linux": home}}},
+	}}}
+	m := NewModel(cfg, &platform.Platform{OS: OSLinux}, false)
+	m.ConfigPath = filepath.Join(repo, "tidydots.yaml")
+	m.initSubEntryForm(0, -1)
+
+	ctrlO := tea.KeyPressMsg{Code: 'o', Mod: tea.ModCtrl}
+
+	// Linux target, while editing: the picker opens at home and space picks
+	// the highlighted directory.
+	m.subEntryForm.FocusIndex = 1
+	m.enterSubEntryFieldEditMode()
+
+	m = sendPickerKey(t, m, ctrlO)
+	if m.subEntryForm.AddFileMode != ModePathPicker {
+		t.Fatalf("AddFileMode = %v after ctrl+o, want ModePathPicker", m.subEntryForm.AddFileMode)
+	}
+
+	if m.subEntryForm.FilePicker.CurrentDirectory != home {
+		t.Errorf("picker starts at %q, want home %q", m.subEntryForm.FilePicker.CurrentDirectory, home)
+	}
+
+	m = sendPickerKey(t, m, tea.KeyPressMsg{Code: tea.KeySpace, Text: " "})
+
+	if got := m.subEntryForm.LinuxTargetInput.Value(); got != "~/.config" {
+		t.Errorf("linux target = %q, want ~/.config", got)
+	}
+
+	if m.subEntryForm.AddFileMode != ModeNone || !m.subEntryForm.EditingField {
+		t.Errorf("after picking: AddFileMode = %v, EditingField = %v; want the field edit to resume",
+			m.subEntryForm.AddFileMode, m.subEntryForm.EditingField)
+	}
+
+	// Backup, without editing: the picker opens at the repo root, enter opens
+	// a directory and "." picks it.
+	m.subEntryForm.EditingField = false
+	m.subEntryForm.FocusIndex = 3
+
+	m = sendPickerKey(t, m, ctrlO)
+	if m.subEntryForm.FilePicker.CurrentDirectory != repo {
+		t.Errorf("picker starts at %q, want repo %q", m.subEntryForm.FilePicker.CurrentDirectory, repo)
+	}
+
+	m = sendPickerKey(t, m, tea.KeyPressMsg{Code: tea.KeyEnter})
+	m = sendPickerKey(t, m, tea.KeyPressMsg{Code: '.', Text: "."})
+
+	if got := m.subEntryForm.BackupInput.Value(); got != "./nvim" {
+		t.Errorf("backup = %q, want ./nvim", got)
+	}
+}
+
+func TestPathPicker_CancelKeepsValue(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("USERPROFILE", home)
+
+	cfg := &config.Config{Applications: []config.Application{{
+		Name:    "test-app",
+		Entries: []config.SubEntry{{Name: "placeholder", Targets: map[string]string{"linux": home}}},
+	}}}
+	m := NewModel(cfg, &platform.Platform{OS: OSLinux}, false)
+	m.initSubEntryForm(0, -1)
+	m.subEntryForm.FocusIndex = 1
+	m.subEntryForm.LinuxTargetInput.SetValue("~/.config/nvim")
+
+	m = sendPickerKey(t, m, tea.KeyPressMsg{Code: 'o', Mod: tea.ModCtrl})
+	m = sendPickerKey(t, m, tea.KeyPressMsg{Code: tea.KeyEsc})
+
+	if m.subEntryForm.AddFileMode != ModeNone {
+		t.Errorf("AddFileMode = %v after esc, want ModeNone", m.subEntryForm.AddFileMode)
+	}
+
+	if got := m.subEntryForm.LinuxTargetInput.Value(); got != "~/.config/nvim" {
+		t.Errorf("linux target = %q after cancel, want it unchanged", got)
+	}
+}
@@ -3,12 +3,15 @@ package tui
 import (
 	"errors"
 	"fmt"
+	"maps"
+	"slices"
 	"strings"
 
 	"charm.land/bubbles/v2/key"
 	"charm.land/bubbles/v2/textinput"
 	tea "charm.land/bubbletea/v2"
 	"github.com/AntoineGS/tidydots/internal/config"
+	"github.com/AntoineGS/tidydots/internal/packages"
 	"github.com/AntoineGS/tidydots/internal/tui/forms"
 )
 
@@ -20,6 +23,7 @@ const (
 	appFieldDescription = forms.AppFieldDescription
 	appFieldPackages    = forms.AppFieldPackages
 	appFieldWhen        = forms.AppFieldWhen
+	appFieldNotes       = forms.AppFieldNotes
 )
 
 // initApplicationForm initializes the application form.
@@ -42,12 +46,21 @@ func (m *Model) initApplicationForm(appIdx int) {
 		app = &m.Config.Applications[configAppIdx]
 	}
 
+	m.openApplicationForm(app, configAppIdx)
+}
+
+// openApplicationForm opens the application form filled from app, which may
+// be nil for an empty form. configAppIdx is the config index of the
+// application being edited, or -1 when the form adds a new one.
+func (m *Model) openApplicationForm(app *config.Application, configAppIdx int) {
 	nameInput := newFormInput(PlaceholderNeovim, CharLimitName, InputWidthNarrow)
 	nameInput.Focus()
 
 	descriptionInput := newFormInput("e.g., Neovim text editor", CharLimitDesc, InputWidthNarrow)
 	packageNameInput := newFormInput(PlaceholderNeovim, CharLimitPkgName, InputWidthNarrow)
 	whenInput := newFormInput(PlaceholderWhen, CharLimitWhen, InputWidthWide)
+	notesInput := newNotesInput()
+	notesFile := ""
 
 	gitURLInput, gitBranchInput, gitLinuxInput, gitWindowsInput := newGitTextInputs()
 	installerLinuxInput, installerWindowsInput, installerBinaryInput := newInstallerTextInputs()
@@ -64,6 +77,8 @@ func (m *Model) initApplicationForm(appIdx int) {
 		nameInput.SetValue(app.Name)
 		descriptionInput.SetValue(app.Description)
 		whenInput.SetValue(app.When)
+		notesInput.SetValue(app.Notes)
+		notesFile = app.NotesFile
 
 		// Load package managers (only string-based managers, skip git and installer)
 		if app.Package != nil && len(app.Package.Managers) > 0 {
@@ -130,6 +145,8 @@ func (m *Model) initApplicationForm(appIdx int) {
 		PackageNameInput:      packageNameInput,
 		LastPackageName:       "",
 		WhenInput:             whenInput,
+		NotesInput:            notesInput,
+		NotesFile:             notesFile,
 		FocusIndex:            0,
 		EditingField:          false,
 		OriginalValue:         "",
@@ -207,6 +224,11 @@ func (m Model) updateApplicationForm(msg tea.KeyPressMsg) (tea.Model, tea.Cmd) {
 		return m.updateApplicationWhenInput(msg)
 	}
 
+	// Handle editing notes
+	if m.applicationForm.EditingNotes {
+		return m.updateApplicationNotesInput(msg)
+	}
+
 	// Handle packages list navigation
 	if m.getApplicationFieldType() == appFieldPackages {
 		if m.applicationForm.PackagesCursor == len(displayPackageManagers) && m.applicationForm.GitFieldCursor >= 0 {
@@ -232,7 +254,7 @@ func (m Model) updateApplicationForm(msg tea.KeyPressMsg) (tea.Model, tea.Cmd) {
 
 	case key.Matches(msg, FormNavKeys.Down):
 		m.applicationForm.FocusIndex++
-		if m.applicationForm.FocusIndex > 3 {
+		if m.applicationForm.FocusIndex > 4 {
 			m.applicationForm.FocusIndex = 0
 		}
 		m.updateApplicationFormFocus()
@@ -241,7 +263,7 @@ func (m Model) updateApplicationForm(msg tea.KeyPressMsg) (tea.Model, tea.Cmd) {
 	case key.Matches(msg, FormNavKeys.Up):
 		m.applicationForm.FocusIndex--
 		if m.applicationForm.FocusIndex < 0 {
-			m.applicationForm.FocusIndex = 3
+			m.applicationForm.FocusIndex = 4
 		}
 		if m.getApplicationFieldType() == appFieldPackages {
 			m.applicationForm.PackagesCursor = len(displayPackageManagers) + 1
@@ -253,7 +275,7 @@ func (m Model) updateApplicationForm(msg tea.KeyPressMsg) (tea.Model, tea.Cmd) {
 
 	case key.Matches(msg, FormNavKeys.TabNext):
 		m.applicationForm.FocusIndex++
-		if m.applicationForm.FocusIndex > 3 {
+		if m.applicationForm.FocusIndex > 4 {
 			m.applicationForm.FocusIndex = 0
 		}
 		m.updateApplicationFormFocus()
@@ -262,7 +284,7 @@ func (m Model) updateApplicationForm(msg tea.KeyPressMsg) (tea.Model, tea.Cmd) {
 	case key.Matches(msg, FormNavKeys.TabPrev):
 		m.applicationForm.FocusIndex--
 		if m.applicationForm.FocusIndex < 0 {
-			m.applicationForm.FocusIndex = 3
+			m.applicationForm.FocusIndex = 4
 		}
 		if m.getApplicationFieldType() == appFieldPackages {
 			m.applicationForm.PackagesCursor = len(displayPackageManagers) + 1
@@ -286,6 +308,16 @@ func (m Model) updateApplicationForm(msg tea.KeyPressMsg) (tea.Model, tea.Cmd) {
 			m.applicationForm.WhenInput.SetCursor(len(m.applicationForm.WhenInput.Value()))
 			return m, nil
 		}
+		if ft == appFieldNotes {
+			if m.applicationForm.NotesFile != "" {
+				m.applicationForm.Err = fmt.Sprintf("notes come from notes_file %s; edit that file instead", m.applicationForm.NotesFile)
+				return m, nil
+			}
+			m.applicationForm.EditingNotes = true
+			m.applicationForm.OriginalValue = m.applicationForm.NotesInput.Value()
+			m.applicationForm.NotesInput.MoveToEnd()
+			return m, m.applicationForm.NotesInput.Focus()
+		}
 
 	case key.Matches(msg, FormNavKeys.Save):
 		// Save the form
@@ -338,8 +370,8 @@ func (m Model) updateApplicationFieldInput(msg tea.KeyPressMsg) (tea.Model, tea.
 		m.applicationForm.NameInput, cmd = m.applicationForm.NameInput.Update(msg)
 	case appFieldDescription:
 		m.applicationForm.DescriptionInput, cmd = m.applicationForm.DescriptionInput.Update(msg)
-	case appFieldPackages, appFieldWhen:
-		// List/when fields don't need text input updates here
+	case appFieldPackages, appFieldWhen, appFieldNotes:
+		// List/when/notes fields don't need text input updates here
 	}
 
 	// Clear error when typing
@@ -399,7 +431,7 @@ func (m Model) updateApplicationPackagesList(msg tea.KeyPressMsg) (tea.Model, te
 		default:
 			// Move to next field
 			m.applicationForm.FocusIndex++
-			if m.applicationForm.FocusIndex > 3 {
+			if m.applicationForm.FocusIndex > 4 {
 				m.applicationForm.FocusIndex = 0
 			}
 			m.applicationForm.ResetCursors()
@@ -409,7 +441,7 @@ func (m Model) updateApplicationPackagesList(msg tea.KeyPressMsg) (tea.Model, te
 
 	case key.Matches(msg, FormNavKeys.TabNext):
 		m.applicationForm.FocusIndex++
-		if m.applicationForm.FocusIndex > 3 {
+		if m.applicationForm.FocusIndex > 4 {
 			m.applicationForm.FocusIndex = 0
 		}
 		m.applicationForm.ResetCursors()
@@ -429,7 +461,7 @@ func (m Model) updateApplicationPackagesList(msg tea.KeyPressMsg) (tea.Model, te
 	case key.Matches(msg, FormNavKeys.Delete):
 		return m.handlePackagesListDelete(gitItemIdx, installerItemIdx)
 
-	case key.Matches(msg, key.NewBinding(key.WithKeys("p"))):
+	case key.Matches(msg, FormNavKeys.Deps):
 		// Enter deps editing for the current native manager
 		if m.applicationForm.PackagesCursor >= 0 && m.applicationForm.PackagesCursor < len(displayPackageManagers) {
 			manager := displayPackageManagers[m.applicationForm.PackagesCursor]
@@ -670,6 +702,18 @@ func (m Model) updateApplicationPackageInput(msg tea.KeyPressMsg) (tea.Model, te
 		}
 		manager := displayPackageManagers[m.applicationForm.PackagesCursor]
 
+		// mas takes a numeric App Store ID rather than a package name, and
+		// mas itself can tell whether an app has it.
+		var lookup tea.Cmd
+		if manager == string(packages.Mas) && pkgName != "" {
+			if err := packages.ValidateMasID(pkgName); err != nil {
+				m.applicationForm.Err = err.Error()
+				return m, nil
+			}
+
+			lookup = lookupMasAppCmd(pkgName)
+		}
+
 		if pkgName != "" {
 			m.applicationForm.PackageManagers[manager] = pkgName
 			m.applicationForm.LastPackageName = pkgName // Remember for auto-populate
@@ -680,7 +724,7 @@ func (m Model) updateApplicationPackageInput(msg tea.KeyPressMsg) (tea.Model, te
 
 		m.applicationForm.EditingPackage = false
 		m.applicationForm.PackageNameInput.SetValue("")
-		return m, nil
+		return m, lookup
 	}
 
 	// Handle text input
@@ -724,6 +768,42 @@ func (m Model) updateApplicationWhenInput(msg tea.KeyPressMsg) (tea.Model, tea.C
 	return m, cmd
 }
 
+// updateApplicationNotesInput handles key events when editing the notes.
+// Enter starts a new line, so ctrl+s ends the edit.
+func (m Model) updateApplicationNotesInput(msg tea.KeyPressMsg) (tea.Model, tea.Cmd) {
+	if m.applicationForm == nil {
+		return m, nil
+	}
+
+	var cmd tea.Cmd
+
+	if m, cmd, handled := m.handleTextEditKeys(msg); handled {
+		return m, cmd
+	}
+
+	switch {
+	case key.Matches(msg, TextEditKeys.Cancel):
+		// Cancel editing and restore original value
+		m.applicationForm.NotesInput.SetValue(m.applicationForm.OriginalValue)
+		m.applicationForm.EditingNotes = false
+		m.applicationForm.NotesInput.Blur()
+		m.applicationForm.Err = ""
+		return m, nil
+
+	case key.Matches(msg, TextEditKeys.SaveForm):
+		// Save and exit edit mode
+		m.applicationForm.EditingNotes = false
+		m.applicationForm.NotesInput.Blur()
+		return m, nil
+	}
+
+	// Handle text input
+	m.applicationForm.NotesInput, cmd = m.applicationForm.NotesInput.Update(msg)
+	m.applicationForm.Err = ""
+
+	return m, cmd
+}
+
 // viewApplicationForm renders the application form
 func (m Model) viewApplicationForm() string {
 	if m.applicationForm == nil {
@@ -812,8 +892,27 @@ func (m Model) viewApplicationForm() string {
 		m.applicationForm.EditingWhen,
 		m.applicationForm.WhenInput,
 	))
+
+	if idx := m.applicationForm.EditAppIdx; idx >= 0 && idx < len(m.Config.Applications) {
+		b.WriteString(renderPresetWhens(m.Config.PresetWhens(&m.Config.Applications[idx])))
+	}
 	b.WriteString("\n")
 
+	// Notes section
+	notesLabel := "Notes:"
+	if ft == appFieldNotes {
+		notesLabel = HelpKeyStyle.Render("Notes:")
+	}
+	fmt.Fprintf(&b, "  %s\n", notesLabel)
+	b.WriteString(m.renderApplicationNotesField())
+	b.WriteString("\n")
+
+	// Entries of the preset the form was filled from
+	if len(m.applicationForm.PresetEntries) > 0 {
+		b.WriteString(renderPresetEntries(m.applicationForm.PresetName, m.applicationForm.PresetEntries))
+		b.WriteString("\n")
+	}
+
 	// Error message
 	if m.applicationForm.Err != "" {
 		b.WriteString(ErrorStyle.Render("  Error: " + m.applicationForm.Err))
@@ -826,6 +925,28 @@ func (m Model) viewApplicationForm() string {
 	return BaseStyle.Render(b.String())
 }
 
+// renderPresetEntries lists the entries a preset adds with the application:
+// each entry's backup and its target on every OS.
+func renderPresetEntries(presetName string, entries []config.SubEntry) string {
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "  Entries (from preset %s):\n", presetName)
+
+	for _, entry := range entries {
+		targets := make([]string, 0, len(entry.Targets))
+		for _, osType := range slices.Sorted(maps.Keys(entry.Targets)) {
+			targets = append(targets, osType+": "+entry.Targets[osType])
+		}
+
+		fmt.Fprintf(&b, "    %s: %s → %s\n", entry.Name, entry.Backup, strings.Join(targets, ", "))
+	}
+
+	b.WriteString(MutedTextStyle.Render("    Edit them from the list once the application is saved."))
+	b.WriteString("\n")
+
+	return b.String()
+}
+
 // renderApplicationFieldValue renders a field value with appropriate styling
 func (m Model) renderApplicationFieldValue(fieldType applicationFieldType, placeholder string) string {
 	if m.applicationForm == nil {
@@ -863,6 +984,37 @@ func (m Model) renderApplicationFieldValue(fieldType applicationFieldType, place
 	return value
 }
 
+// renderApplicationNotesField renders the notes: the textarea while editing,
+// otherwise their lines, or where they come from when notes_file holds them.
+func (m Model) renderApplicationNotesField() string {
+	form := m.applicationForm
+	focused := m.getApplicationFieldType() == appFieldNotes
+
+	var lines []string
+
+	switch {
+	case form.NotesFile != "":
+		lines = []string{MutedTextStyle.Render("(from " + form.NotesFile + ")")}
+	case form.EditingNotes:
+		lines = strings.Split(form.NotesInput.View(), "\n")
+	case form.Notes() == "":
+		lines = []string{MutedTextStyle.Render("(optional)")}
+	default:
+		lines = strings.Split(form.Notes(), "\n")
+	}
+
+	var b strings.Builder
+
+	for _, line := range lines {
+		if focused && !form.EditingNotes {
+			line = SelectedMenuItemStyle.Render(line)
+		}
+		fmt.Fprintf(&b, "  %s\n", line)
+	}
+
+	return b.String()
+}
+
 // renderApplicationFormHelp renders context-sensitive help for the application form
 func (m Model) renderApplicationFormHelp() string {
 	if m.applicationForm == nil {
@@ -878,9 +1030,8 @@ func (m Model) renderApplicationFormHelp() string {
 				TextEditKeys.Cancel,
 			)
 		}
-		depsBinding := key.NewBinding(key.WithKeys("enter", "e"), key.WithHelp("enter/e", "edit"))
 		return RenderHelpFromBindings(m.width,
-			depsBinding,
+			FilesListKeys.Edit,
 			FormNavKeys.Delete,
 			FormNavKeys.Cancel,
 		)
@@ -910,6 +1061,13 @@ func (m Model) renderApplicationFormHelp() string {
 		)
 	}
 
+	if m.applicationForm.EditingNotes {
+		return RenderHelpFromBindings(m.width,
+			TextEditKeys.SaveForm,
+			TextEditKeys.Cancel,
+		)
+	}
+
 	if m.applicationForm.EditingField {
 		return RenderHelpFromBindings(m.width,
 			TextEditKeys.Confirm,
@@ -922,8 +1080,7 @@ func (m Model) renderApplicationFormHelp() string {
 		// Git package states
 		if m.applicationForm.PackagesCursor == len(displayPackageManagers) {
 			if !m.applicationForm.HasGitPackage {
-				addBinding := key.NewBinding(key.WithKeys("enter"), key.WithHelp("enter", "add"))
-				return RenderHelpFromBindings(m.width, addBinding, FormNavKeys.Save)
+				return RenderHelpFromBindings(m.width, WithDesc(FilesListKeys.Edit, "add"), FormNavKeys.Save)
 			}
 			if m.applicationForm.GitFieldCursor == -1 {
 				return RenderHelpFromBindings(m.width, FormNavKeys.Delete, FormNavKeys.Save)
@@ -936,8 +1093,7 @@ func (m Model) renderApplicationFormHelp() string {
 		// Installer package states
 		if m.applicationForm.PackagesCursor == len(displayPackageManagers)+1 {
 			if !m.applicationForm.HasInstallerPackage {
-				addBinding := key.NewBinding(key.WithKeys("enter"), key.WithHelp("enter", "add"))
-				return RenderHelpFromBindings(m.width, addBinding, FormNavKeys.Save)
+				return RenderHelpFromBindings(m.width, WithDesc(FilesListKeys.Edit, "add"), FormNavKeys.Save)
 			}
 			if m.applicationForm.InstallerFieldCursor == -1 {
 				return RenderHelpFromBindings(m.width, FormNavKeys.Delete, FormNavKeys.Save)
@@ -945,13 +1101,12 @@ func (m Model) renderApplicationFormHelp() string {
 			return RenderHelpFromBindings(m.width, FormNavKeys.Edit, FormNavKeys.Save)
 		}
 		// Bounds check for packagesCursor
-		depsBinding := key.NewBinding(key.WithKeys("p"), key.WithHelp("p", "deps"))
 		if m.applicationForm.PackagesCursor >= 0 && m.applicationForm.PackagesCursor < len(displayPackageManagers) {
 			manager := displayPackageManagers[m.applicationForm.PackagesCursor]
 			if m.applicationForm.PackageManagers[manager] != "" {
 				return RenderHelpFromBindings(m.width,
 					FormNavKeys.Edit,
-					depsBinding,
+					FormNavKeys.Deps,
 					FormNavKeys.Delete,
 					FormNavKeys.Save,
 				)
@@ -959,12 +1114,12 @@ func (m Model) renderApplicationFormHelp() string {
 		}
 		return RenderHelpFromBindings(m.width,
 			FormNavKeys.Edit,
-			depsBinding,
+			FormNavKeys.Deps,
 			FormNavKeys.Save,
 		)
 	}
 
-	if ft == appFieldWhen {
+	if ft == appFieldWhen || ft == appFieldNotes {
 		return RenderHelpFromBindings(m.width,
 			FormNavKeys.Edit,
 			FormNavKeys.Save,
@@ -991,15 +1146,29 @@ func (m *Model) saveApplicationForm() error {
 
 	// Save based on edit mode
 	if m.applicationForm.EditAppIdx >= 0 {
-		return m.saveEditedApplication(m.applicationForm.EditAppIdx, name, description, when, pkg)
+		return m.saveEditedApplication(m.applicationForm.EditAppIdx, name, description, when, m.applicationForm.Notes(), pkg)
 	}
-	return m.saveNewApplication(config.Application{
+
+	app := config.Application{
 		Name:        name,
 		Description: description,
 		When:        when,
+		Notes:       m.applicationForm.Notes(),
 		Package:     pkg,
 		Entries:     []config.SubEntry{}, // Empty entries initially
-	})
+	}
+
+	// The entries of a preset come along, as long as they fit the config:
+	// AddSnippet rejects targets that collide with existing entries.
+	if entries := m.applicationForm.PresetEntries; len(entries) > 0 {
+		app.Entries = slices.Clone(entries)
+
+		if _, err := config.AddSnippet(m.Config, &config.AppSnippet{Application: app}, m.Platform.EnvVars, m.Renderer); err != nil {
+			return err
+		}
+	}
+
+	return m.saveNewApplication(app)
 }
 
 // updateApplicationFormFocus updates which input field is focused
@@ -11,6 +11,7 @@ import (
 var (
 	displayPackageManagers        = forms.DisplayPackageManagers
 	newFormInput                  = forms.NewFormInput
+	newNotesInput                 = forms.NewNotesInput
 	newGitTextInputs              = forms.NewGitTextInputs
 	newInstallerTextInputs        = forms.NewInstallerTextInputs
 	renderPackagesSection         = forms.RenderPackagesSection
@@ -18,6 +19,7 @@ var (
 	renderGitPackageSection       = forms.RenderGitPackageSection
 	renderInstallerPackageSection = forms.RenderInstallerPackageSection
 	renderWhenField               = forms.RenderWhenField
+	renderPresetWhens             = forms.RenderPresetWhens
 	buildPackageSpec              = forms.BuildPackageSpec
 	mergeGitPackage               = forms.MergeGitPackage
 	mergeInstallerPackage         = forms.MergeInstallerPackage
@@ -32,6 +34,12 @@ func (m *Model) saveNewApplication(app config.Application) error {
 		}
 	}
 
+	for _, entry := range app.Entries {
+		if err := m.checkGlobalSubEntryName(entry.Name, -1); err != nil {
+			return err
+		}
+	}
+
 	m.Config.Applications = append(m.Config.Applications, app)
 
 	if err := config.Save(m.Config, m.ConfigPath); err != nil {
@@ -45,8 +53,31 @@ func (m *Model) saveNewApplication(app config.Application) error {
 	return nil
 }
 
+// checkGlobalSubEntryName rejects a sub-entry name already used by another
+// application when globallyUniqueSubEntryNames is set. Application appIdx is
+// skipped: duplicates within it are reported by the caller.
+func (m *Model) checkGlobalSubEntryName(name string, appIdx int) error {
+	if !m.globallyUniqueSubEntryNames {
+		return nil
+	}
+
+	for i, app := range m.Config.Applications {
+		if i == appIdx {
+			continue
+		}
+
+		for _, existing := range app.Entries {
+			if existing.Name == name {
+				return fmt.Errorf("a sub-entry with name '%s' already exists in application '%s'", name, app.Name)
+			}
+		}
+	}
+
+	return nil
+}
+
 // saveEditedApplication updates Application metadata only (no SubEntry changes)
-func (m *Model) saveEditedApplication(appIdx int, name, description, when string, pkg *config.EntryPackage) error {
+func (m *Model) saveEditedApplication(appIdx int, name, description, when, notes string, pkg *config.EntryPackage) error {
 	app := &m.Config.Applications[appIdx]
 
 	// Check for duplicate names (skip the one being edited)
@@ -57,14 +88,72 @@ func (m *Model) saveEditedApplication(appIdx int, name, description, when string
 	}
 
 	// Update Application metadata
-	origName, origDesc, origWhen, origPkg := app.Name, app.Description, app.When, app.Package
+	origName, origDesc, origWhen, origNotes, origPkg := app.Name, app.Description, app.When, app.Notes, app.Package
+
+	// The form has no phase, after, USE flag, apt repo, App Store app name,
+	// scoop bucket, winget source, verify, prefer, package when, git depth or
+	// sparse fields; keep the ones from the config file.
+	if pkg != nil && origPkg != nil {
+		pkg.Phase = origPkg.Phase
+		pkg.After = origPkg.After
+		pkg.Prefer = origPkg.Prefer
+		pkg.When = origPkg.When
+
+		if mv, ok := pkg.Managers["emerge"]; ok && mv.Emerge == nil {
+			mv.Emerge = origPkg.Managers["emerge"].Emerge
+			pkg.Managers["emerge"] = mv
+		}
+
+		if mv, ok := pkg.Managers["apt"]; ok && mv.Apt == nil {
+			mv.Apt = origPkg.Managers["apt"].Apt
+			pkg.Managers["apt"] = mv
+		}
+
+		if mv, ok := pkg.Managers["scoop"]; ok && mv.Scoop == nil {
+			mv.Scoop = origPkg.Managers["scoop"].Scoop
+			pkg.Managers["scoop"] = mv
+		}
+
+		if mv, ok := pkg.Managers["winget"]; ok && mv.Winget == nil {
+			mv.Winget = origPkg.Managers["winget"].Winget
+			pkg.Managers["winget"] = mv
+		}
+
+		// The form does not edit package name overrides.
+		for key, mv := range pkg.Managers {
+			if orig, ok := origPkg.Managers[key]; ok && mv.Overrides == nil {
+				mv.Overrides = orig.Overrides
+				pkg.Managers[key] = mv
+			}
+		}
+
+		// The app name only stays right while the App Store ID does.
+		if mv, ok := pkg.Managers["mas"]; ok && mv.Mas == nil && mv.PackageName == origPkg.Managers["mas"].PackageName {
+			mv.Mas = origPkg.Managers["mas"].Mas
+			pkg.Managers["mas"] = mv
+		}
+
+		if mv, ok := pkg.Managers["installer"]; ok && mv.Installer != nil && mv.Installer.Verify == nil {
+			if orig := origPkg.Managers["installer"]; orig.Installer != nil {
+				mv.Installer.Verify = orig.Installer.Verify
+			}
+		}
+
+		if mv, ok := pkg.Managers["git"]; ok && mv.Git != nil {
+			if orig := origPkg.Managers["git"]; orig.Git != nil {
+				mv.Git.Depth, mv.Git.Sparse = orig.Git.Depth, orig.Git.Sparse
+			}
+		}
+	}
+
 	app.Name = name
 	app.Description = description
 	app.When = when
+	app.Notes = notes
 	app.Package = pkg
 
 	if err := config.Save(m.Config, m.ConfigPath); err != nil {
-		app.Name, app.Description, app.When, app.Package = origName, origDesc, origWhen, origPkg
+		app.Name, app.Description, app.When, app.Notes, app.Package = origName, origDesc, origWhen, origNotes, origPkg
 		return fmt.Errorf("failed to save config: %w", err)
 	}
 
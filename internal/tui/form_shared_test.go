@@ -0,0 +1,86 @@
+package tui
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/AntoineGS/tidydots/internal/config"
+)
+
+func TestGloballyUniqueSubEntryNames(t *testing.T) {
+	t.Parallel()
+
+	entry := func(name string) config.SubEntry {
+		return config.SubEntry{
+			Name:    name,
+			Backup:  "./" + name,
+			Targets: map[string]string{"linux": "~/." + name},
+		}
+	}
+
+	tests := []struct {
+		name    string
+		global  bool
+		save    func(m *Model) error
+		wantErr string
+	}{
+		{
+			name:   "add allowed when setting is off",
+			global: false,
+			save:   func(m *Model) error { return m.addSubEntryToApp(1, entry("config")) },
+		},
+		{
+			name:    "add rejected when name used by another app",
+			global:  true,
+			save:    func(m *Model) error { return m.addSubEntryToApp(1, entry("config")) },
+			wantErr: "already exists in application 'nvim'",
+		},
+		{
+			name:    "rename rejected when name used by another app",
+			global:  true,
+			save:    func(m *Model) error { return m.updateSubEntry(1, 0, entry("config")) },
+			wantErr: "already exists in application 'nvim'",
+		},
+		{
+			name:   "editing an entry keeps its own name",
+			global: true,
+			save:   func(m *Model) error { return m.updateSubEntry(0, 0, entry("config")) },
+		},
+		{
+			name:   "new application rejected when an entry name is taken",
+			global: true,
+			save: func(m *Model) error {
+				return m.saveNewApplication(config.Application{Name: "git", Entries: []config.SubEntry{entry("config")}})
+			},
+			wantErr: "already exists in application 'nvim'",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			m, _ := modelOnDisk(t, &config.Config{
+				Version: 3,
+				Applications: []config.Application{
+					{Name: "nvim", Entries: []config.SubEntry{entry("config")}},
+					{Name: "zsh", Entries: []config.SubEntry{entry("zshrc")}},
+				},
+			})
+			m.globallyUniqueSubEntryNames = tt.global
+
+			err := tt.save(m)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("save error = %v, want nil", err)
+				}
+
+				return
+			}
+
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("save error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
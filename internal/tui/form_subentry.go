@@ -29,10 +29,11 @@ const (
 
 // Mode constants from forms package.
 const (
-	ModeNone      = forms.ModeNone
-	ModeChoosing  = forms.ModeChoosing
-	ModePicker    = forms.ModePicker
-	ModeTextInput = forms.ModeTextInput
+	ModeNone       = forms.ModeNone
+	ModeChoosing   = forms.ModeChoosing
+	ModePicker     = forms.ModePicker
+	ModeTextInput  = forms.ModeTextInput
+	ModePathPicker = forms.ModePathPicker
 )
 
 // setupEntryNotEditable is shown when the user presses `e` on a setup entry: the
@@ -112,9 +113,16 @@ func (m *Model) initSubEntryForm(appIdx, subIdx int) {
 	backupInput := newFormInput("e.g., ./nvim", CharLimitPath, InputWidthNarrow)
 	newFileInput := newFormInput("e.g., .bashrc", CharLimitFile, InputWidthNarrow)
 
-	isSudo := false
+	defaults := m.Config.EntryDefaults(&m.Config.Applications[configAppIdx])
+
+	// A new entry starts out with every default inherited.
+	isSudo := defaults.Sudo != nil && *defaults.Sudo
 	isCopy := false
 	isFolder := true
+	sudoInherited := defaults.Sudo != nil
+	copyInherited := defaults.Method != ""
+	verify := defaults.Verify != nil && *defaults.Verify
+	verifyInherited := defaults.Verify != nil
 	var files []string
 
 	if hasSub {
@@ -127,10 +135,18 @@ func (m *Model) initSubEntryForm(appIdx, subIdx int) {
 			windowsTargetInput.SetValue(target)
 		}
 
-		backupInput.SetValue(sub.Backup)
+		// An inherited backup stays empty, showing the pattern's path instead.
+		if !sub.Inherits(config.FieldBackup) {
+			backupInput.SetValue(sub.Backup)
+		}
+
 		isSudo = sub.Sudo
 		isCopy = sub.IsCopy()
 		isFolder = sub.IsFolder()
+		sudoInherited = sub.Inherits(config.FieldSudo)
+		copyInherited = sub.Inherits(config.FieldMethod)
+		verify = sub.Verify
+		verifyInherited = sub.Inherits(config.FieldVerify)
 
 		if !isFolder && len(sub.Files) > 0 {
 			files = make([]string, len(sub.Files))
@@ -163,6 +179,19 @@ func (m *Model) initSubEntryForm(appIdx, subIdx int) {
 		// gets here — this keeps any other path from dropping them.
 		Check:            maps.Clone(sub.Check),
 		Run:              maps.Clone(sub.Run),
+		Verify:           verify,
+		Dedupe:           sub.Dedupe,
+		CaseRename:       maps.Clone(sub.CaseRename),
+		MaxFileSize:      sub.MaxFileSize,
+		Encoding:         sub.Encoding,
+		LinkMode:         sub.LinkMode,
+		PostRestore:      maps.Clone(sub.PostRestore),
+		Enabled:          sub.Enabled,
+		Defaults:         defaults,
+		AppName:          appName,
+		SudoInherited:    sudoInherited,
+		CopyInherited:    copyInherited,
+		VerifyInherited:  verifyInherited,
 		IsFolder:         isFolder,
 		Files:            files,
 		FilesCursor:      0,
@@ -221,6 +250,11 @@ func (m Model) updateSubEntryForm(msg tea.KeyPressMsg) (tea.Model, tea.Cmd) {
 		return m.updateSubEntryFilePicker(msg)
 	}
 
+	// Handle the picker for a target or backup field
+	if m.subEntryForm.AddFileMode == ModePathPicker {
+		return m.updateSubEntryPathPicker(msg)
+	}
+
 	// Handle manual text input mode (from "Type Path" menu option)
 	if m.subEntryForm.AddFileMode == ModeTextInput {
 		return m.updateSubEntryFileInput(msg)
@@ -320,10 +354,10 @@ func (m Model) updateSubEntryForm(msg tea.KeyPressMsg) (tea.Model, tea.Cmd) {
 			m.subEntryForm.ToggleFolderMode()
 			return m, nil
 		case subFieldIsSudo:
-			m.subEntryForm.IsSudo = !m.subEntryForm.IsSudo
+			m.subEntryForm.ToggleSudo()
 			return m, nil
 		case subFieldIsCopy:
-			m.subEntryForm.IsCopy = !m.subEntryForm.IsCopy
+			m.subEntryForm.ToggleCopy()
 			return m, nil
 		case subFieldName, subFieldLinux, subFieldWindows, subFieldBackup, subFieldFiles:
 			// Text and list fields don't toggle
@@ -343,15 +377,18 @@ func (m Model) updateSubEntryForm(msg tea.KeyPressMsg) (tea.Model, tea.Cmd) {
 			m.subEntryForm.ToggleFolderMode()
 			return m, nil
 		case subFieldIsSudo:
-			m.subEntryForm.IsSudo = !m.subEntryForm.IsSudo
+			m.subEntryForm.ToggleSudo()
 			return m, nil
 		case subFieldIsCopy:
-			m.subEntryForm.IsCopy = !m.subEntryForm.IsCopy
+			m.subEntryForm.ToggleCopy()
 			return m, nil
 		case subFieldName, subFieldLinux, subFieldWindows, subFieldBackup, subFieldFiles:
 			// Text and list fields don't toggle
 		}
 
+	case key.Matches(msg, TextEditKeys.Browse) && m.isSubEntryPathField():
+		return m.openSubEntryPathPicker()
+
 	case key.Matches(msg, FormNavKeys.Save):
 		// Save the form
 		if err := m.saveSubEntryForm(); err != nil {
@@ -564,6 +601,10 @@ func (m Model) viewSubEntryForm() string {
 		return m.viewFilePicker()
 	}
 
+	if m.subEntryForm.AddFileMode == ModePathPicker {
+		return m.viewPathPicker()
+	}
+
 	var b strings.Builder
 	ft := m.getSubEntryFieldType()
 
@@ -613,7 +654,12 @@ func (m Model) viewSubEntryForm() string {
 	}
 
 	fmt.Fprintf(&b, "  %s\n", backupLabel)
-	fmt.Fprintf(&b, "  %s\n", m.renderSubEntryFieldValue(subFieldBackup, "(empty)"))
+	backupPlaceholder := "(empty)"
+	if inherited := m.subEntryForm.InheritedBackup(); inherited != "" {
+		backupPlaceholder = inherited + " (inherited)"
+	}
+
+	fmt.Fprintf(&b, "  %s\n", m.renderSubEntryFieldValue(subFieldBackup, backupPlaceholder))
 
 	if m.subEntryForm.EditingField && ft == subFieldBackup && m.subEntryForm.ShowSuggestions {
 		b.WriteString(m.renderSubEntrySuggestions())
@@ -661,6 +707,10 @@ func (m Model) viewSubEntryForm() string {
 				default:
 					fmt.Fprintf(&b, "%s• %s\n", prefix, file)
 				}
+
+				if !m.subEntryForm.EditingFile || m.subEntryForm.EditingFileIndex != i {
+					b.WriteString(m.renderPatternMatches(file))
+				}
 			}
 		}
 
@@ -676,6 +726,11 @@ func (m Model) viewSubEntryForm() string {
 			}
 		}
 
+		if w := m.subEntryFileWarning(); w != "" {
+			b.WriteString(WarningStyle.Render("    Warning: " + w))
+			b.WriteString("\n")
+		}
+
 		b.WriteString("\n")
 	}
 
@@ -690,7 +745,7 @@ func (m Model) viewSubEntryForm() string {
 		rootCheck = CheckboxChecked
 	}
 
-	fmt.Fprintf(&b, "  %s  %s Yes\n\n", rootLabel, rootCheck)
+	fmt.Fprintf(&b, "  %s  %s\n\n", rootLabel, renderInheritedToggle(rootCheck+" Yes", m.subEntryForm.SudoInherited))
 
 	// Deployment method toggle. Copy mode is files-only, so it has no field in
 	// folder mode — see SubEntryForm.ToggleFolderMode.
@@ -705,10 +760,14 @@ func (m Model) viewSubEntryForm() string {
 			copyCheck = CheckboxChecked
 		}
 
-		fmt.Fprintf(&b, "  %s %s Yes %s\n\n", copyLabel, copyCheck,
+		fmt.Fprintf(&b, "  %s %s %s\n\n", copyLabel,
+			renderInheritedToggle(copyCheck+" Yes", m.subEntryForm.CopyInherited),
 			MutedTextStyle.Render("(deploy real files instead of symlinks)"))
 	}
 
+	// Target overlap warnings (non-blocking)
+	b.WriteString(m.renderSubEntryTargetWarnings())
+
 	// Error message
 	if m.subEntryForm.Err != "" {
 		b.WriteString(ErrorStyle.Render("  Error: " + m.subEntryForm.Err))
@@ -727,6 +786,16 @@ func (m Model) viewSubEntryForm() string {
 	return BaseStyle.Render(b.String())
 }
 
+// renderInheritedToggle renders a toggle, greyed out and marked when its
+// value is inherited from defaults.
+func renderInheritedToggle(toggle string, inherited bool) string {
+	if !inherited {
+		return toggle
+	}
+
+	return MutedTextStyle.Render(toggle + " (inherited)")
+}
+
 // renderSubEntryFieldValue renders a field value with appropriate styling
 //
 //nolint:unparam // placeholder parameter kept for consistency and future extensibility
@@ -816,6 +885,15 @@ func (m Model) renderSubEntryFormHelp() string {
 			)
 		}
 
+		if m.isSubEntryPathField() {
+			return RenderHelpFromBindings(m.width,
+				TextEditKeys.Confirm,
+				TextEditKeys.Browse,
+				TextEditKeys.SaveForm,
+				TextEditKeys.Cancel,
+			)
+		}
+
 		return RenderHelpFromBindings(m.width,
 			TextEditKeys.Confirm,
 			TextEditKeys.SaveForm,
@@ -839,6 +917,15 @@ func (m Model) renderSubEntryFormHelp() string {
 		)
 	}
 
+	if m.isSubEntryPathField() {
+		// Path field focused (not editing)
+		return RenderHelpFromBindings(m.width,
+			FormNavKeys.Edit,
+			TextEditKeys.Browse,
+			FormNavKeys.Save,
+		)
+	}
+
 	if m.isSubEntryTextInputField() {
 		// Text field focused (not editing)
 		return RenderHelpFromBindings(m.width,
@@ -919,6 +1006,20 @@ func (m *Model) enterSubEntryFieldEditMode() {
 		return
 	}
 	m.subEntryForm.EnterFieldEditMode()
+
+	// An empty target field opens with its config root suggestions.
+	switch m.getSubEntryFieldType() {
+	case subFieldLinux:
+		if m.subEntryForm.LinuxTargetInput.Value() == "" {
+			m.updateSuggestionsSubEntry()
+		}
+	case subFieldWindows:
+		if m.subEntryForm.WindowsTargetInput.Value() == "" {
+			m.updateSuggestionsSubEntry()
+		}
+	case subFieldName, subFieldBackup, subFieldIsFolder, subFieldFiles, subFieldIsSudo, subFieldIsCopy:
+		// Other fields only suggest once something is typed
+	}
 }
 
 // cancelSubEntryFieldEdit cancels editing and restores the original value
@@ -937,6 +1038,16 @@ func (m *Model) isSubEntryTextInputField() bool {
 	return m.subEntryForm.IsTextInputField()
 }
 
+// isSubEntryPathField returns true if the current field holds a path that can
+// be browsed for: a target or the backup
+func (m *Model) isSubEntryPathField() bool {
+	if m.subEntryForm == nil {
+		return false
+	}
+	ft := m.getSubEntryFieldType()
+	return ft == subFieldLinux || ft == subFieldWindows || ft == subFieldBackup
+}
+
 // isSubEntryToggleField returns true if the current field is a toggle
 func (m *Model) isSubEntryToggleField() bool {
 	if m.subEntryForm == nil {
@@ -960,6 +1071,10 @@ func (m *Model) addSubEntryToApp(appIdx int, subEntry config.SubEntry) error {
 		}
 	}
 
+	if err := m.checkGlobalSubEntryName(subEntry.Name, appIdx); err != nil {
+		return err
+	}
+
 	app.Entries = append(app.Entries, subEntry)
 
 	if err := config.Save(m.Config, m.ConfigPath); err != nil {
@@ -992,6 +1107,10 @@ func (m *Model) updateSubEntry(appIdx, subIdx int, subEntry config.SubEntry) err
 		}
 	}
 
+	if err := m.checkGlobalSubEntryName(subEntry.Name, appIdx); err != nil {
+		return err
+	}
+
 	// Update SubEntry
 	original := app.Entries[subIdx]
 	app.Entries[subIdx] = subEntry
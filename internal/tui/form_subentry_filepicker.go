@@ -8,9 +8,14 @@ import (
 
 	"charm.land/bubbles/v2/filepicker"
 	"charm.land/bubbles/v2/key"
+	"charm.land/bubbles/v2/textinput"
 	tea "charm.land/bubbletea/v2"
 )
 
+// minFilePickerHeight is the number of rows the file picker shows before the
+// window size is known.
+const minFilePickerHeight = 10
+
 // updateFileAddModeChoice handles key events for the Browse/Type mode selection menu
 func (m Model) updateFileAddModeChoice(msg tea.KeyPressMsg) (tea.Model, tea.Cmd) {
 	if m.subEntryForm == nil {
@@ -55,6 +60,7 @@ func (m Model) updateFileAddModeChoice(msg tea.KeyPressMsg) (tea.Model, tea.Cmd)
 				return m, nil
 			}
 			m.subEntryForm.AddFileMode = ModePicker
+			return m, m.subEntryForm.FilePicker.Init()
 		case 1:
 			// Browse source directory - transition to ModePicker starting at backup path
 			if err := m.initFilePickerForBackup(); err != nil {
@@ -63,6 +69,7 @@ func (m Model) updateFileAddModeChoice(msg tea.KeyPressMsg) (tea.Model, tea.Cmd)
 				return m, nil
 			}
 			m.subEntryForm.AddFileMode = ModePicker
+			return m, m.subEntryForm.FilePicker.Init()
 		case 2:
 			// Type Path - transition to ModeTextInput
 			m.subEntryForm.AddFileMode = ModeTextInput
@@ -131,22 +138,36 @@ func (m *Model) initFilePicker() error {
 		targetPath = m.subEntryForm.LinuxTargetInput.Value()
 	}
 
+	return m.initFilePickerForTarget(targetPath)
+}
+
+// initFilePickerForTarget initializes the file picker at targetPath, or at its
+// nearest existing parent, or at the home directory when targetPath is empty
+func (m *Model) initFilePickerForTarget(targetPath string) error {
 	// Resolve the start directory using phase 2 utility
 	startDir, err := resolvePickerStartDirectory(targetPath, m.Platform.OS)
 	if err != nil {
 		return fmt.Errorf("failed to resolve start directory: %w", err)
 	}
 
-	// Initialize the file picker
+	m.subEntryForm.FilePicker = m.newFilePicker(startDir)
+
+	return nil
+}
+
+// newFilePicker returns a file picker rooted at dir that shows hidden files
+// and allows picking both files and directories. Its Init command reads the
+// directory; the results reach it through Model.Update.
+func (m *Model) newFilePicker(dir string) filepicker.Model {
 	picker := filepicker.New()
-	picker.CurrentDirectory = startDir
+	picker.CurrentDirectory = dir
 	picker.DirAllowed = true
 	picker.FileAllowed = true
 	picker.ShowHidden = true
+	picker.AutoHeight = false
+	picker.SetHeight(max(m.viewHeight, minFilePickerHeight))
 
-	m.subEntryForm.FilePicker = picker
-
-	return nil
+	return picker
 }
 
 // initFilePickerForBackup initializes the file picker starting at the backup/source directory
@@ -185,14 +206,7 @@ func (m *Model) initFilePickerForBackup() error {
 		}
 	}
 
-	// Initialize the file picker
-	picker := filepicker.New()
-	picker.CurrentDirectory = startDir
-	picker.DirAllowed = true
-	picker.FileAllowed = true
-	picker.ShowHidden = true
-
-	m.subEntryForm.FilePicker = picker
+	m.subEntryForm.FilePicker = m.newFilePicker(startDir)
 
 	return nil
 }
@@ -437,3 +451,137 @@ func (m Model) viewFilePicker() string {
 
 	return BaseStyle.Render(b.String())
 }
+
+// openSubEntryPathPicker opens the file picker for the focused target or
+// backup field. It starts at the field's path, or at the home directory for
+// targets and the repo root for the backup when the field is empty.
+func (m Model) openSubEntryPathPicker() (tea.Model, tea.Cmd) {
+	var err error
+
+	switch m.getSubEntryFieldType() {
+	case subFieldLinux:
+		err = m.initFilePickerForTarget(m.subEntryForm.LinuxTargetInput.Value())
+	case subFieldWindows:
+		err = m.initFilePickerForTarget(m.subEntryForm.WindowsTargetInput.Value())
+	case subFieldBackup:
+		err = m.initFilePickerForBackup()
+	default:
+		return m, nil
+	}
+
+	if err != nil {
+		m.subEntryForm.Err = fmt.Sprintf("failed to initialize file picker: %v", err)
+		return m, nil
+	}
+
+	// Enter opens directories; a directory is picked with Pick instead.
+	m.subEntryForm.FilePicker.DirAllowed = false
+	m.subEntryForm.AddFileMode = ModePathPicker
+
+	return m, m.subEntryForm.FilePicker.Init()
+}
+
+// updateSubEntryPathPicker handles key events when the file picker is choosing
+// the path of a target or backup field
+func (m Model) updateSubEntryPathPicker(msg tea.KeyPressMsg) (tea.Model, tea.Cmd) {
+	if m.subEntryForm == nil {
+		return m, nil
+	}
+
+	if m, cmd, handled := m.handleCommonKeys(msg); handled {
+		return m, cmd
+	}
+
+	switch {
+	case key.Matches(msg, PathPickerKeys.Cancel):
+		m.subEntryForm.AddFileMode = ModeNone
+		return m, nil
+
+	case key.Matches(msg, PathPickerKeys.Pick):
+		if path := m.subEntryForm.FilePicker.HighlightedPath(); path != "" {
+			m.setPickedPath(path)
+		}
+		return m, nil
+
+	case key.Matches(msg, PathPickerKeys.PickDirectory):
+		m.setPickedPath(m.subEntryForm.FilePicker.CurrentDirectory)
+		return m, nil
+	}
+
+	var cmd tea.Cmd
+	m.subEntryForm.FilePicker, cmd = m.subEntryForm.FilePicker.Update(msg)
+
+	if ok, path := m.subEntryForm.FilePicker.DidSelectFile(msg); ok {
+		m.setPickedPath(path)
+		return m, nil
+	}
+
+	return m, cmd
+}
+
+// setPickedPath writes a path chosen in the path picker into the focused field
+// and closes the picker. Paths under the home directory are written as ~/...,
+// and backup paths under the repo as ./...
+func (m *Model) setPickedPath(path string) {
+	home, _ := os.UserHomeDir() //nolint:errcheck // without a home the path stays absolute
+
+	var input *textinput.Model
+
+	repoDir := ""
+
+	switch m.getSubEntryFieldType() {
+	case subFieldLinux:
+		input = &m.subEntryForm.LinuxTargetInput
+	case subFieldWindows:
+		input = &m.subEntryForm.WindowsTargetInput
+	case subFieldBackup:
+		input = &m.subEntryForm.BackupInput
+		if m.ConfigPath != "" {
+			repoDir = filepath.Dir(m.ConfigPath)
+		}
+	default:
+		m.subEntryForm.AddFileMode = ModeNone
+		return
+	}
+
+	value := pickedPathValue(path, home, repoDir)
+	input.SetValue(value)
+	input.SetCursor(len(value))
+
+	m.subEntryForm.AddFileMode = ModeNone
+	m.subEntryForm.Err = ""
+}
+
+// viewPathPicker renders the file picker choosing the path of a field
+func (m Model) viewPathPicker() string {
+	if m.subEntryForm == nil {
+		return ""
+	}
+
+	var b strings.Builder
+
+	title := "  Select Backup Path"
+	switch m.getSubEntryFieldType() {
+	case subFieldLinux:
+		title = "  Select Linux Target"
+	case subFieldWindows:
+		title = "  Select Windows Target"
+	}
+
+	b.WriteString(TitleStyle.Render(title))
+	b.WriteString("\n")
+	b.WriteString(SubtitleStyle.Render(m.subEntryForm.FilePicker.CurrentDirectory))
+	b.WriteString("\n\n")
+
+	b.WriteString(m.renderStyledFilePicker())
+	b.WriteString("\n\n")
+
+	b.WriteString(RenderHelpFromBindings(m.width,
+		PathPickerKeys.Open,
+		PathPickerKeys.Pick,
+		PathPickerKeys.PickDirectory,
+		PathPickerKeys.Cancel,
+	))
+
+	return BaseStyle.Render(b.String())
+}
@@ -6,6 +6,7 @@ import (
 
 	"charm.land/bubbles/v2/key"
 	tea "charm.land/bubbletea/v2"
+	"github.com/AntoineGS/tidydots/internal/platform"
 )
 
 // updateSubEntryFieldInput handles key events when editing a text field
@@ -38,6 +39,10 @@ func (m Model) updateSubEntryFieldInput(msg tea.KeyPressMsg) (tea.Model, tea.Cmd
 
 		return m, nil
 
+	case key.Matches(msg, TextEditKeys.Browse) && isPathField:
+		m.subEntryForm.ShowSuggestions = false
+		return m.openSubEntryPathPicker()
+
 	case key.Matches(msg, SearchKeys.Confirm) || key.Matches(msg, TextEditKeys.SaveForm):
 		// Accept suggestion only if user has explicitly selected one
 		if hasSelectedSuggestion {
@@ -123,7 +128,7 @@ func (m *Model) updateSuggestionsSubEntry() {
 		return
 	}
 
-	var input string
+	var input, targetOS string
 	var configDir string
 	ft := m.getSubEntryFieldType()
 
@@ -134,9 +139,9 @@ func (m *Model) updateSuggestionsSubEntry() {
 
 	switch ft {
 	case subFieldLinux:
-		input = m.subEntryForm.LinuxTargetInput.Value()
+		input, targetOS = m.subEntryForm.LinuxTargetInput.Value(), platform.OSLinux
 	case subFieldWindows:
-		input = m.subEntryForm.WindowsTargetInput.Value()
+		input, targetOS = m.subEntryForm.WindowsTargetInput.Value(), platform.OSWindows
 	case subFieldBackup:
 		input = m.subEntryForm.BackupInput.Value()
 	case subFieldName, subFieldIsFolder, subFieldFiles, subFieldIsSudo, subFieldIsCopy:
@@ -150,7 +155,15 @@ func (m *Model) updateSuggestionsSubEntry() {
 		return
 	}
 
-	suggestions := getPathSuggestions(input, configDir)
+	// Target fields also suggest the OS's config roots, so a new entry gets a
+	// sensible starting point before anything is typed.
+	var suggestions []string
+	if targetOS != "" {
+		suggestions = getTargetSuggestions(input, targetOS, configDir)
+	} else {
+		suggestions = getPathSuggestions(input, configDir)
+	}
+
 	m.subEntryForm.Suggestions = suggestions
 	m.subEntryForm.SuggestionCursor = -1 // No selection until user uses arrows
 	m.subEntryForm.ShowSuggestions = len(suggestions) > 0
@@ -0,0 +1,148 @@
+package tui
+
+import (
+	"fmt"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/AntoineGS/tidydots/internal/config"
+	"github.com/AntoineGS/tidydots/internal/manager"
+)
+
+// subEntryTargetWarnings returns a warning for each existing entry whose
+// target overlaps the one currently in the sub-entry form. The check runs on
+// every render so the clash shows up while the target is being typed; it never
+// blocks saving. Nothing is reported while the form is incomplete.
+func (m Model) subEntryTargetWarnings() []string {
+	if m.subEntryForm == nil || m.Config == nil || m.Platform == nil {
+		return nil
+	}
+
+	entry, err := m.subEntryForm.BuildSubEntry()
+	if err != nil {
+		return nil
+	}
+
+	appIdx := m.subEntryForm.TargetAppIdx
+	if m.subEntryForm.EditAppIdx >= 0 {
+		appIdx = m.subEntryForm.EditAppIdx
+	}
+
+	if appIdx < 0 || appIdx >= len(m.Config.Applications) {
+		return nil
+	}
+
+	// Check against the config as it would look after saving.
+	apps := slices.Clone(m.Config.Applications)
+	app := &apps[appIdx]
+	app.Entries = slices.Clone(app.Entries)
+
+	if subIdx := m.subEntryForm.EditSubIdx; subIdx >= 0 && subIdx < len(app.Entries) {
+		app.Entries[subIdx] = entry
+	} else {
+		app.Entries = append(app.Entries, entry)
+	}
+
+	var warnings []string
+
+	for _, c := range config.FindTargetCollisions(apps, m.Platform.OS, m.Platform.EnvVars, m.Renderer) {
+		if !c.Involves(app.Name, entry.Name) {
+			continue
+		}
+
+		other := c.OtherApp + "/" + c.OtherEntry
+		if c.OtherApp == app.Name && c.OtherEntry == entry.Name {
+			other = c.App + "/" + c.Entry
+		}
+
+		warnings = append(warnings, "Target overlaps "+other+" ("+c.Path+")")
+	}
+
+	return warnings
+}
+
+// renderSubEntryTargetWarnings renders the target overlap warnings, if any.
+func (m Model) renderSubEntryTargetWarnings() string {
+	warnings := m.subEntryTargetWarnings()
+	if len(warnings) == 0 {
+		return ""
+	}
+
+	var b strings.Builder
+
+	for _, w := range warnings {
+		b.WriteString(WarningStyle.Render("  Warning: " + w))
+		b.WriteString("\n")
+	}
+
+	b.WriteString("\n")
+
+	return b.String()
+}
+
+// subEntryFileWarning returns a warning while the file being added or edited
+// is a glob pattern, since a files list normally holds exact names, or ""
+// otherwise.
+func (m Model) subEntryFileWarning() string {
+	if m.subEntryForm == nil || (!m.subEntryForm.AddingFile && !m.subEntryForm.EditingFile) {
+		return ""
+	}
+
+	name := strings.TrimSpace(m.subEntryForm.NewFileInput.Value())
+	if !manager.IsGlobPattern(name) {
+		return ""
+	}
+
+	if _, err := filepath.Match(name, ""); err != nil {
+		return "Invalid pattern: restore and backup will fail this entry"
+	}
+
+	return "Pattern: deploys every matching file in the backup, not one exact name"
+}
+
+// patternPreviewLimit is the number of matches listed under a glob pattern in
+// the sub-entry form before the rest are counted.
+const patternPreviewLimit = 5
+
+// renderPatternMatches previews the files that pattern, a glob in the files
+// list, matches in the backup directory of the entry being edited, so a
+// pattern that matches nothing or too much shows up before it is saved.
+// Exact names and malformed patterns preview nothing.
+func (m Model) renderPatternMatches(pattern string) string {
+	if m.Manager == nil || m.subEntryForm == nil || !manager.IsGlobPattern(pattern) {
+		return ""
+	}
+
+	backup := strings.TrimSpace(m.subEntryForm.BackupInput.Value())
+	if backup == "" {
+		backup = m.subEntryForm.InheritedBackup()
+	}
+
+	if backup == "" {
+		return ""
+	}
+
+	matches, err := m.Manager.MatchFiles(backup, pattern)
+	if err != nil {
+		return ""
+	}
+
+	if len(matches) == 0 {
+		return WarningStyle.Render("        matches no file in the backup") + "\n"
+	}
+
+	var b strings.Builder
+
+	for _, match := range matches[:min(len(matches), patternPreviewLimit)] {
+		b.WriteString(MutedTextStyle.Render("        → " + filepath.ToSlash(match)))
+		b.WriteString("\n")
+	}
+
+	if rest := len(matches) - patternPreviewLimit; rest > 0 {
+		b.WriteString(MutedTextStyle.Render(fmt.Sprintf("        (+%d more)", rest)))
+		b.WriteString("\n")
+	}
+
+	return b.String()
+}
@@ -0,0 +1,138 @@
+package tui
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/AntoineGS/tidydots/internal/config"
+	"github.com/AntoineGS/tidydots/internal/manager"
+)
+
+func TestSubEntryTargetWarnings(t *testing.T) {
+	nvim := config.SubEntry{Name: "config", Backup: "./nvim", Targets: map[string]string{"linux": "~/.config/nvim"}}
+	cfg := &config.Config{
+		Version:    3,
+		BackupRoot: "/repo",
+		Applications: []config.Application{
+			{Name: "nvim", Entries: []config.SubEntry{nvim}},
+			{Name: "neovim", Entries: []config.SubEntry{}},
+		},
+	}
+
+	tests := []struct {
+		name      string
+		entry     config.SubEntry
+		appIdx    int
+		editIdx   int
+		wantCount int
+	}{
+		{
+			name:      "new entry with the same target warns",
+			entry:     config.SubEntry{Name: "cfg", Backup: "./neovim", Targets: map[string]string{"linux": "~/.config/nvim/"}},
+			appIdx:    1,
+			editIdx:   -1,
+			wantCount: 1,
+		},
+		{
+			name:      "new entry with a distinct target is quiet",
+			entry:     config.SubEntry{Name: "cfg", Backup: "./neovim", Targets: map[string]string{"linux": "~/.config/neovim"}},
+			appIdx:    1,
+			editIdx:   -1,
+			wantCount: 0,
+		},
+		{
+			name:      "editing an entry does not collide with itself",
+			entry:     nvim,
+			appIdx:    0,
+			editIdx:   0,
+			wantCount: 0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := NewModel(cfg, linuxPlatform(), false)
+			m.subEntryForm = NewSubEntryForm(tt.entry)
+			m.subEntryForm.TargetAppIdx = tt.appIdx
+			m.subEntryForm.EditAppIdx = -1
+			m.subEntryForm.EditSubIdx = -1
+
+			if tt.editIdx >= 0 {
+				m.subEntryForm.TargetAppIdx = -1
+				m.subEntryForm.EditAppIdx = tt.appIdx
+				m.subEntryForm.EditSubIdx = tt.editIdx
+			}
+
+			warnings := m.subEntryTargetWarnings()
+			if len(warnings) != tt.wantCount {
+				t.Fatalf("subEntryTargetWarnings() = %v, want %d warning(s)", warnings, tt.wantCount)
+			}
+
+			if tt.wantCount > 0 && !strings.Contains(warnings[0], "nvim/config") {
+				t.Errorf("warning %q does not name the existing entry", warnings[0])
+			}
+		})
+	}
+}
+
+func TestSubEntryFileWarning(t *testing.T) {
+	tests := []struct {
+		name   string
+		input  string
+		adding bool
+		want   string
+	}{
+		{name: "exact name is quiet", input: ".bashrc", adding: true, want: ""},
+		{name: "pattern warns", input: "*.conf", adding: true, want: "Pattern:"},
+		{name: "malformed pattern warns", input: "[*.conf", adding: true, want: "Invalid pattern"},
+		{name: "not adding is quiet", input: "*.conf", adding: false, want: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := NewModel(&config.Config{Version: 3}, linuxPlatform(), false)
+			m.subEntryForm = NewSubEntryForm(config.SubEntry{Name: "rc"})
+			m.subEntryForm.AddingFile = tt.adding
+			m.subEntryForm.NewFileInput.SetValue(tt.input)
+
+			got := m.subEntryFileWarning()
+			if tt.want == "" && got != "" || !strings.HasPrefix(got, tt.want) {
+				t.Errorf("subEntryFileWarning() = %q, want prefix %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRenderPatternMatches(t *testing.T) {
+	root := t.TempDir()
+	for i := range 7 {
+		name := filepath.Join(root, "kitty", fmt.Sprintf("theme%d.conf", i))
+		if err := os.MkdirAll(filepath.Dir(name), 0o755); err != nil {
+			t.Fatal(err)
+		}
+
+		if err := os.WriteFile(name, nil, 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	cfg := &config.Config{Version: 3, BackupRoot: root}
+	m := NewModelWithManager(cfg, linuxPlatform(), manager.New(cfg, linuxPlatform()), "")
+	m.subEntryForm = NewSubEntryForm(config.SubEntry{Name: "config", Backup: "./kitty"})
+
+	got := m.renderPatternMatches("*.conf")
+	if !strings.Contains(got, "theme0.conf") || strings.Contains(got, "theme5.conf") || !strings.Contains(got, "(+2 more)") {
+		t.Errorf("renderPatternMatches(*.conf) = %q, want five matches and a count of the rest", got)
+	}
+
+	if got := m.renderPatternMatches("*.lua"); !strings.Contains(got, "matches no file") {
+		t.Errorf("renderPatternMatches(*.lua) = %q, want a no-match warning", got)
+	}
+
+	if got := m.renderPatternMatches("kitty.conf"); got != "" {
+		t.Errorf("renderPatternMatches(kitty.conf) = %q, want nothing for an exact name", got)
+	}
+}
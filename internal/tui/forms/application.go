@@ -4,6 +4,7 @@ import (
 	"errors"
 	"strings"
 
+	"charm.land/bubbles/v2/textarea"
 	"charm.land/bubbles/v2/textinput"
 	"github.com/AntoineGS/tidydots/internal/config"
 	"github.com/AntoineGS/tidydots/internal/tui/tuishared"
@@ -18,6 +19,7 @@ const (
 	AppFieldDescription
 	AppFieldPackages
 	AppFieldWhen
+	AppFieldNotes
 )
 
 // ApplicationForm holds state for editing Application metadata
@@ -37,6 +39,12 @@ type ApplicationForm struct {
 	EditingPackage   bool
 	EditingWhen      bool
 
+	// Notes fields. NotesFile is the application's notes_file: its notes
+	// live in the repository and are not edited on the form.
+	NotesInput   textarea.Model
+	NotesFile    string
+	EditingNotes bool
+
 	// Git package fields
 	GitURLInput     textinput.Model
 	GitBranchInput  textinput.Model
@@ -62,6 +70,12 @@ type ApplicationForm struct {
 	EditingDepItem bool                // true when editing a dep text input
 	DepsManagerKey string              // which manager's deps we're editing
 	DepInput       textinput.Model     // text input for adding/editing deps
+
+	// Preset the form was filled from, if any. Its entries are added with
+	// the new application; they are listed on the form and can be edited
+	// like any other entry once the application is saved.
+	PresetName    string
+	PresetEntries []config.SubEntry
 }
 
 // ResetCursors resets all cursor and sub-field state on the ApplicationForm.
@@ -91,6 +105,8 @@ func (f *ApplicationForm) GetFieldType() ApplicationFieldType {
 		return AppFieldPackages
 	case 3:
 		return AppFieldWhen
+	case 4:
+		return AppFieldNotes
 	default:
 		return AppFieldName
 	}
@@ -159,8 +175,8 @@ func (f *ApplicationForm) UpdateFocus() {
 		f.DescriptionInput.Focus()
 	case AppFieldPackages:
 		// List fields don't use textinput focus
-	case AppFieldWhen:
-		// When field focus is handled separately
+	case AppFieldWhen, AppFieldNotes:
+		// When and notes field focus is handled separately
 	}
 }
 
@@ -184,8 +200,8 @@ func (f *ApplicationForm) EnterFieldEditMode() {
 		f.DescriptionInput.SetCursor(len(f.DescriptionInput.Value()))
 	case AppFieldPackages:
 		// List fields don't use text input editing
-	case AppFieldWhen:
-		// When field has its own edit mode
+	case AppFieldWhen, AppFieldNotes:
+		// When and notes fields have their own edit mode
 	}
 }
 
@@ -203,8 +219,8 @@ func (f *ApplicationForm) CancelFieldEdit() {
 		f.DescriptionInput.SetValue(f.OriginalValue)
 	case AppFieldPackages:
 		// List fields don't use text input restoration
-	case AppFieldWhen:
-		// When field has its own cancel handling
+	case AppFieldWhen, AppFieldNotes:
+		// When and notes fields have their own cancel handling
 	}
 
 	f.EditingField = false
@@ -298,6 +314,16 @@ func (f *ApplicationForm) BuildApplication() (name, description, when string, pk
 	return name, description, when, pkg, nil
 }
 
+// Notes returns the notes entered on the form, without surrounding blank
+// lines or spaces.
+func (f *ApplicationForm) Notes() string {
+	if f == nil {
+		return ""
+	}
+
+	return strings.TrimSpace(f.NotesInput.Value())
+}
+
 // NewApplicationForm creates a new ApplicationForm for testing purposes
 func NewApplicationForm(app config.Application, isEdit bool) *ApplicationForm {
 	nameInput := NewFormInput(tuishared.PlaceholderNeovim, tuishared.CharLimitName, tuishared.InputWidthNarrow)
@@ -309,6 +335,9 @@ func NewApplicationForm(app config.Application, isEdit bool) *ApplicationForm {
 	whenInput := NewFormInput(tuishared.PlaceholderWhen, tuishared.CharLimitWhen, tuishared.InputWidthWide)
 	whenInput.SetValue(app.When)
 
+	notesInput := NewNotesInput()
+	notesInput.SetValue(app.Notes)
+
 	editAppIdx := -1
 	if isEdit {
 		editAppIdx = 0
@@ -385,6 +414,8 @@ func NewApplicationForm(app config.Application, isEdit bool) *ApplicationForm {
 		NameInput:             nameInput,
 		DescriptionInput:      descriptionInput,
 		WhenInput:             whenInput,
+		NotesInput:            notesInput,
+		NotesFile:             app.NotesFile,
 		PackageManagers:       packageManagers,
 		EditAppIdx:            editAppIdx,
 		GitURLInput:           gitURLInput,
@@ -381,6 +381,11 @@ func TestApplicationForm_GetFieldType(t *testing.T) {
 			focusIndex: 3,
 			wantType:   forms.AppFieldWhen,
 		},
+		{
+			name:       "index_4_is_notes",
+			focusIndex: 4,
+			wantType:   forms.AppFieldNotes,
+		},
 		{
 			name:       "out_of_range_defaults_to_name",
 			focusIndex: 99,
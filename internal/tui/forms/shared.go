@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"strings"
 
+	"charm.land/bubbles/v2/textarea"
 	"charm.land/bubbles/v2/textinput"
 	"github.com/AntoineGS/tidydots/internal/config"
 	"github.com/AntoineGS/tidydots/internal/platform"
@@ -31,6 +32,18 @@ func NewFormInput(placeholder string, charLimit int, width int) textinput.Model
 	return ti
 }
 
+// NewNotesInput creates the multi-line textarea of application notes.
+func NewNotesInput() textarea.Model {
+	ta := textarea.New()
+	ta.Placeholder = "e.g., Sign in to sync settings"
+	ta.CharLimit = tuishared.CharLimitNotes
+	ta.ShowLineNumbers = false
+	ta.Prompt = ""
+	ta.SetWidth(tuishared.InputWidthWide)
+	ta.SetHeight(tuishared.NotesInputHeight)
+	return ta
+}
+
 // NewGitTextInputs creates the four git text inputs with standard placeholders and char limits
 func NewGitTextInputs() (gitURLInput, gitBranchInput, gitLinuxInput, gitWindowsInput textinput.Model) {
 	gitURLInput = NewFormInput(tuishared.PlaceholderGitURL, tuishared.CharLimitPath, tuishared.InputWidthNarrow)
@@ -289,6 +302,20 @@ func RenderWhenField(
 	return fmt.Sprintf("%s%s\n", prefix, value)
 }
 
+// RenderPresetWhens renders the when_presets an application references,
+// one per line below its when field. They are edited in tidydots.yaml, not in
+// the form, so they are shown read-only.
+func RenderPresetWhens(presets []config.NamedWhen) string {
+	var b strings.Builder
+
+	for _, preset := range presets {
+		fmt.Fprintf(&b, "%s%s\n", tuishared.IndentSpaces,
+			tuishared.MutedTextStyle.Render(fmt.Sprintf("%s (preset %s, read-only)", preset.When, preset.Name)))
+	}
+
+	return b.String()
+}
+
 // BuildPackageSpec creates a config.EntryPackage from a managers map
 func BuildPackageSpec(managers map[string]string) *config.EntryPackage {
 	if len(managers) == 0 {
@@ -38,6 +38,9 @@ const (
 	ModePicker
 	// ModeTextInput indicates manual text input mode is active
 	ModeTextInput
+	// ModePathPicker indicates the file picker is choosing the path of the
+	// focused target or backup field
+	ModePathPicker
 )
 
 // SubEntryForm holds state for editing SubEntry data
@@ -53,6 +56,28 @@ type SubEntryForm struct {
 	// form built from an entry cannot silently delete them on the way back out.
 	Check map[string]string
 	Run   map[string]string
+	// Verify is likewise carried through unedited; the flag is set in tidydots.yaml.
+	Verify bool
+	// Dedupe, CaseRename, MaxFileSize, Encoding, LinkMode and PostRestore are
+	// carried through unedited too.
+	Dedupe      bool
+	CaseRename  map[string]string
+	MaxFileSize int64
+	Encoding    string
+	LinkMode    string
+	PostRestore map[string]string
+	// Defaults are the defaults the entry inherits from its application and the
+	// config, and AppName the application's name, which the backup pattern uses.
+	// SudoInherited, CopyInherited and VerifyInherited mark values still taken
+	// from them; an empty backup inherits the pattern. Inherited values are not
+	// saved, so the entry keeps following the defaults.
+	Defaults        config.Defaults
+	AppName         string
+	SudoInherited   bool
+	CopyInherited   bool
+	VerifyInherited bool
+	// Enabled is carried through too; it is toggled from the list view.
+	Enabled *bool
 	// Method is the entry's deployment method as it was read in. IsCopy is what
 	// the toggle edits; Method is kept so that turning the toggle off restores the
 	// original spelling ("" or an explicit "symlink") rather than normalizing it.
@@ -162,9 +187,33 @@ func (f *SubEntryForm) ToggleFolderMode() {
 	f.IsFolder = !f.IsFolder
 	if f.IsFolder {
 		f.IsCopy = false
+	} else if f.CopyInherited {
+		f.IsCopy = f.Defaults.Method == config.MethodCopy
 	}
 }
 
+// ToggleSudo flips the root toggle, overriding any inherited value.
+func (f *SubEntryForm) ToggleSudo() {
+	f.IsSudo = !f.IsSudo
+	f.SudoInherited = false
+}
+
+// ToggleCopy flips the copy toggle, overriding any inherited value.
+func (f *SubEntryForm) ToggleCopy() {
+	f.IsCopy = !f.IsCopy
+	f.CopyInherited = false
+}
+
+// InheritedBackup returns the backup path the entry inherits from the backup
+// pattern while the backup field is empty, or "" when it inherits none.
+func (f *SubEntryForm) InheritedBackup() string {
+	if f == nil || strings.TrimSpace(f.BackupInput.Value()) != "" {
+		return ""
+	}
+
+	return f.Defaults.BackupFor(f.AppName, strings.TrimSpace(f.NameInput.Value()))
+}
+
 // IsTextInputField returns true if the current field is a text input
 func (f *SubEntryForm) IsTextInputField() bool {
 	if f == nil {
@@ -283,7 +332,7 @@ func (f *SubEntryForm) Validate() error {
 		return errors.New("entry name is required")
 	}
 
-	if strings.TrimSpace(f.BackupInput.Value()) == "" {
+	if strings.TrimSpace(f.BackupInput.Value()) == "" && f.InheritedBackup() == "" {
 		return errors.New("backup path is required")
 	}
 
@@ -333,6 +382,12 @@ func (f *SubEntryForm) BuildSubEntry() (config.SubEntry, error) {
 	}
 
 	backup := strings.TrimSpace(f.BackupInput.Value())
+	inheritsBackup := backup == "" && f.Defaults.BackupPattern != ""
+
+	if inheritsBackup {
+		backup = f.Defaults.BackupFor(f.AppName, name)
+	}
+
 	if backup == "" {
 		return config.SubEntry{}, errors.New("backup path is required")
 	}
@@ -341,13 +396,24 @@ func (f *SubEntryForm) BuildSubEntry() (config.SubEntry, error) {
 	// are written back exactly as they came in: whatever the form does not carry
 	// through is deleted from the config file when the caller saves.
 	subEntry := config.SubEntry{
-		Name:    name,
-		Targets: targets,
-		Sudo:    f.IsSudo,
-		Method:  f.buildMethod(),
-		Backup:  backup,
-		Check:   maps.Clone(f.Check),
-		Run:     maps.Clone(f.Run),
+		Name:        name,
+		Targets:     targets,
+		Sudo:        f.IsSudo,
+		Method:      f.buildMethod(),
+		Backup:      backup,
+		Check:       maps.Clone(f.Check),
+		Run:         maps.Clone(f.Run),
+		Verify:      f.Verify,
+		Dedupe:      f.Dedupe,
+		Enabled:     f.Enabled,
+		MaxFileSize: f.MaxFileSize,
+		Encoding:    f.Encoding,
+		PostRestore: maps.Clone(f.PostRestore),
+	}
+
+	// A link mode only applies to folders.
+	if f.IsFolder {
+		subEntry.LinkMode = f.LinkMode
 	}
 
 	// Add files if in files mode
@@ -357,6 +423,7 @@ func (f *SubEntryForm) BuildSubEntry() (config.SubEntry, error) {
 		}
 		subEntry.Files = make([]string, len(f.Files))
 		copy(subEntry.Files, f.Files)
+		subEntry.CaseRename = maps.Clone(f.CaseRename)
 	}
 
 	// Copy mode is files-only: ValidateConfig rejects a copy entry with no files
@@ -368,9 +435,41 @@ func (f *SubEntryForm) BuildSubEntry() (config.SubEntry, error) {
 		return config.SubEntry{}, errors.New("copy mode requires a files list")
 	}
 
+	f.markInherited(&subEntry, inheritsBackup)
+
 	return subEntry, nil
 }
 
+// markInherited records which fields of s hold inherited defaults, so that
+// only overrides are saved. A field overriding a default is marked explicit,
+// which saves it even when it holds the zero value.
+func (f *SubEntryForm) markInherited(s *config.SubEntry, inheritsBackup bool) {
+	copyDefault := f.Defaults.Method != "" && !f.IsFolder
+
+	// An absent method would inherit the default again on the next load.
+	if copyDefault && !f.CopyInherited && s.Method == "" {
+		s.Method = config.MethodSymlink
+	}
+
+	for _, field := range []struct {
+		name       string
+		inherited  bool
+		hasDefault bool
+	}{
+		{config.FieldSudo, f.SudoInherited, f.Defaults.Sudo != nil},
+		{config.FieldVerify, f.VerifyInherited, f.Defaults.Verify != nil},
+		{config.FieldMethod, f.CopyInherited && copyDefault, copyDefault},
+		{config.FieldBackup, inheritsBackup, f.Defaults.BackupPattern != ""},
+	} {
+		switch {
+		case field.inherited:
+			s.SetInherited(field.name, true)
+		case field.hasDefault:
+			s.SetInherited(field.name, false)
+		}
+	}
+}
+
 // NewSubEntryForm creates a new SubEntryForm for testing purposes
 func NewSubEntryForm(entry config.SubEntry) *SubEntryForm {
 	nameInput := NewFormInput("e.g., nvim-config", tuishared.CharLimitName, tuishared.InputWidthNarrow)
@@ -401,5 +500,16 @@ func NewSubEntryForm(entry config.SubEntry) *SubEntryForm {
 		Files:              entry.Files,
 		Check:              maps.Clone(entry.Check),
 		Run:                maps.Clone(entry.Run),
+		Verify:             entry.Verify,
+		Dedupe:             entry.Dedupe,
+		CaseRename:         maps.Clone(entry.CaseRename),
+		MaxFileSize:        entry.MaxFileSize,
+		Encoding:           entry.Encoding,
+		LinkMode:           entry.LinkMode,
+		PostRestore:        maps.Clone(entry.PostRestore),
+		Enabled:            entry.Enabled,
+		SudoInherited:      entry.Inherits(config.FieldSudo),
+		CopyInherited:      entry.Inherits(config.FieldMethod),
+		VerifyInherited:    entry.Inherits(config.FieldVerify),
 	}
 }
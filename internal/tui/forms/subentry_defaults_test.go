@@ -0,0 +1,110 @@
+package forms_test
+
+import (
+	"testing"
+
+	"github.com/AntoineGS/tidydots/internal/config"
+	"github.com/AntoineGS/tidydots/internal/tui/forms"
+)
+
+// inheritingForm is a files entry form whose sudo, method and backup all still
+// follow the defaults.
+func inheritingForm() *forms.SubEntryForm {
+	sudo := true
+	form := forms.NewSubEntryForm(config.SubEntry{
+		Name:    "rc",
+		Targets: map[string]string{"linux": "~/.rc"},
+		Files:   []string{".rc"},
+	})
+	form.AppName = "shell"
+	form.Defaults = config.Defaults{Sudo: &sudo, Method: config.MethodCopy, BackupPattern: "./{{ .App }}/{{ .Entry }}"}
+	form.IsSudo = true
+	form.SudoInherited = true
+	form.IsCopy = true
+	form.CopyInherited = true
+
+	return form
+}
+
+func TestSubEntryForm_InheritedFieldsAreNotOverrides(t *testing.T) {
+	form := inheritingForm()
+
+	if got := form.InheritedBackup(); got != "./shell/rc" {
+		t.Errorf("InheritedBackup() = %q, want ./shell/rc", got)
+	}
+
+	got, err := form.BuildSubEntry()
+	if err != nil {
+		t.Fatalf("BuildSubEntry() error = %v", err)
+	}
+
+	if got.Backup != "./shell/rc" || !got.Sudo || got.Method != config.MethodCopy {
+		t.Errorf("entry = %+v, want the defaults' values", got)
+	}
+
+	for _, field := range []string{config.FieldSudo, config.FieldMethod, config.FieldBackup} {
+		if !got.Inherits(field) {
+			t.Errorf("Inherits(%q) = false, want true: the default would be saved as an override", field)
+		}
+	}
+}
+
+func TestSubEntryForm_TogglingOverridesDefaults(t *testing.T) {
+	tests := []struct {
+		name   string
+		edit   func(*forms.SubEntryForm)
+		field  string
+		verify func(*testing.T, config.SubEntry)
+	}{
+		{
+			name:  "sudo",
+			edit:  (*forms.SubEntryForm).ToggleSudo,
+			field: config.FieldSudo,
+			verify: func(t *testing.T, e config.SubEntry) {
+				if e.Sudo {
+					t.Error("Sudo = true, want the override to false")
+				}
+			},
+		},
+		{
+			name:  "copy",
+			edit:  (*forms.SubEntryForm).ToggleCopy,
+			field: config.FieldMethod,
+			verify: func(t *testing.T, e config.SubEntry) {
+				// An absent method would inherit copy again on the next load.
+				if e.Method != config.MethodSymlink {
+					t.Errorf("Method = %q, want %q", e.Method, config.MethodSymlink)
+				}
+			},
+		},
+		{
+			name:  "backup",
+			edit:  func(f *forms.SubEntryForm) { f.BackupInput.SetValue("./mine") },
+			field: config.FieldBackup,
+			verify: func(t *testing.T, e config.SubEntry) {
+				if e.Backup != "./mine" {
+					t.Errorf("Backup = %q, want ./mine", e.Backup)
+				}
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			form := inheritingForm()
+			tt.edit(form)
+
+			got, err := form.BuildSubEntry()
+			if err != nil {
+				t.Fatalf("BuildSubEntry() error = %v", err)
+			}
+
+			tt.verify(t, got)
+
+			if got.Inherits(tt.field) || !got.Explicit(tt.field) {
+				t.Errorf("Inherits(%q) = %v, Explicit = %v, want an explicit override",
+					tt.field, got.Inherits(tt.field), got.Explicit(tt.field))
+			}
+		})
+	}
+}
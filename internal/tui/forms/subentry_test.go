@@ -567,6 +567,7 @@ func TestSubEntryForm_RoundTripsCheckAndRun(t *testing.T) {
 		Backup:  "./vicinae",
 		Check:   map[string]string{"linux": "systemctl --user is-enabled --quiet vicinae.service"},
 		Run:     map[string]string{"linux": "systemctl --user enable --now vicinae.service"},
+		Verify:  true,
 	}
 
 	form := forms.NewSubEntryForm(entry)
@@ -585,4 +586,8 @@ func TestSubEntryForm_RoundTripsCheckAndRun(t *testing.T) {
 		t.Errorf("run survived as %v, want %v — the form dropped it, which deletes the setup step on save",
 			got.Run, entry.Run)
 	}
+
+	if !got.Verify {
+		t.Error("verify was dropped by the form, which stops checksums being written on backup")
+	}
 }
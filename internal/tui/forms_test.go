@@ -1,8 +1,10 @@
 package tui
 
 import (
+	"strings"
 	"testing"
 
+	tea "charm.land/bubbletea/v2"
 	"github.com/AntoineGS/tidydots/internal/config"
 )
 
@@ -627,3 +629,74 @@ func TestBuildPackageSpec(t *testing.T) {
 		}
 	})
 }
+
+func TestApplicationForm_EditNotes(t *testing.T) {
+	cfg := &config.Config{Version: 3, BackupRoot: t.TempDir(), Applications: []config.Application{{Name: "zsh"}}}
+	mp, path := modelOnDisk(t, cfg)
+	m := *mp
+
+	m.openApplicationForm(&m.Config.Applications[0], 0)
+	m.applicationForm.FocusIndex = 4
+
+	ctrlS := tea.KeyPressMsg{Code: 's', Mod: tea.ModCtrl}
+
+	m = pressPresetKeys(t, m, tea.KeyPressMsg{Code: tea.KeyEnter})
+	if !m.applicationForm.EditingNotes {
+		t.Fatal("enter on the notes field did not start editing them")
+	}
+
+	m = pressPresetKeys(t, m, typed("Run chsh")...)
+	m = pressPresetKeys(t, m, tea.KeyPressMsg{Code: tea.KeyEnter})
+	m = pressPresetKeys(t, m, typed("Log out")...)
+	m = pressPresetKeys(t, m, ctrlS)
+
+	if m.applicationForm.EditingNotes {
+		t.Fatal("ctrl+s did not end editing the notes")
+	}
+
+	if view := stripAnsiCodes(m.viewApplicationForm()); !strings.Contains(view, "  Log out") {
+		t.Errorf("form does not list the notes:\n%s", view)
+	}
+
+	if err := m.saveApplicationForm(); err != nil {
+		t.Fatalf("saveApplicationForm() error = %v", err)
+	}
+
+	saved, err := config.Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got, want := saved.Applications[0].Notes, "Run chsh\nLog out"; got != want {
+		t.Errorf("saved notes = %q, want %q", got, want)
+	}
+}
+
+func TestApplicationForm_NotesFileNotEdited(t *testing.T) {
+	cfg := &config.Config{Version: 3, BackupRoot: t.TempDir(), Applications: []config.Application{
+		{Name: "zsh", NotesFile: "zsh/NOTES.md"},
+	}}
+	mp, path := modelOnDisk(t, cfg)
+	m := *mp
+
+	m.openApplicationForm(&m.Config.Applications[0], 0)
+	m.applicationForm.FocusIndex = 4
+
+	m = pressPresetKeys(t, m, tea.KeyPressMsg{Code: tea.KeyEnter})
+	if m.applicationForm.EditingNotes || !strings.Contains(m.applicationForm.Err, "zsh/NOTES.md") {
+		t.Fatalf("EditingNotes = %v, Err = %q; want the notes_file named instead", m.applicationForm.EditingNotes, m.applicationForm.Err)
+	}
+
+	if err := m.saveApplicationForm(); err != nil {
+		t.Fatalf("saveApplicationForm() error = %v", err)
+	}
+
+	saved, err := config.Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got := saved.Applications[0].NotesFile; got != "zsh/NOTES.md" {
+		t.Errorf("saved notes_file = %q, want it kept", got)
+	}
+}
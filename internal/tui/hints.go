@@ -4,3 +4,9 @@ import "github.com/AntoineGS/tidydots/internal/tui/tuishared"
 
 // RenderHelpFromBindings is re-exported from tuishared.
 var RenderHelpFromBindings = tuishared.RenderHelpFromBindings
+
+// WithDesc is re-exported from tuishared.
+var WithDesc = tuishared.WithDesc
+
+// PlainKeys is re-exported from tuishared.
+var PlainKeys = tuishared.PlainKeys
@@ -0,0 +1,64 @@
+package tui
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"charm.land/bubbles/v2/key"
+	tea "charm.land/bubbletea/v2"
+)
+
+// updateJump handles the key pressed after the jump prefix: a letter or
+// digit moves the cursor to the next row whose name starts with it, and any
+// other key cancels the jump.
+func (m Model) updateJump(msg tea.KeyPressMsg) (tea.Model, tea.Cmd) {
+	m.jumping = false
+
+	if key.Matches(msg, SharedKeys.ForceQuit) {
+		return m, tea.Quit
+	}
+
+	if r, size := utf8.DecodeRuneInString(msg.Text); size > 0 && size == len(msg.Text) &&
+		(unicode.IsLetter(r) || unicode.IsDigit(r)) {
+		m.jumpToLetter(r)
+	}
+
+	return m, nil
+}
+
+// jumpToLetter moves the cursor to the first row after it, wrapping around,
+// whose application or entry name starts with r, ignoring case. The cursor
+// stays put when no row matches.
+func (m *Model) jumpToLetter(r rune) {
+	prefix := strings.ToLower(string(r))
+
+	for i := 1; i <= len(m.tableRows); i++ {
+		row := (m.tableCursor + i) % len(m.tableRows)
+		if strings.HasPrefix(strings.ToLower(tableRowName(m.tableRows[row])), prefix) {
+			m.jumpToRow(row)
+			return
+		}
+	}
+}
+
+// jumpToRow moves the cursor to row, if the table has it, and scrolls it
+// into view.
+func (m *Model) jumpToRow(row int) {
+	if row < 0 || row >= len(m.tableRows) {
+		return
+	}
+
+	m.tableCursor = row
+	m.updateScrollOffset()
+}
+
+// tableRowName returns the name a row is jumped to by: its entry's name, or
+// its application's on an application row.
+func tableRowName(row TableRow) string {
+	if row.SubIndex >= 0 {
+		return row.SubName
+	}
+
+	return row.AppName
+}
@@ -0,0 +1,107 @@
+package tui
+
+import (
+	"testing"
+
+	tea "charm.land/bubbletea/v2"
+	"github.com/AntoineGS/tidydots/internal/config"
+	"github.com/AntoineGS/tidydots/internal/platform"
+)
+
+func newJumpTestModel(names ...string) Model {
+	apps := make([]config.Application, len(names))
+	for i, name := range names {
+		apps[i] = config.Application{Name: name, Entries: []config.SubEntry{{
+			Name:    "config",
+			Backup:  "./" + name,
+			Targets: map[string]string{"linux": "/tmp/tidydots-test-nonexistent/" + name},
+		}}}
+	}
+
+	m := NewModel(&config.Config{Version: 3, Applications: apps}, &platform.Platform{OS: platform.OSLinux}, false)
+	m.width, m.height = 100, 30
+	m.Screen = ScreenResults
+	m.Operation = OpList
+
+	return m
+}
+
+// cursorName returns the name of the row under the cursor.
+func cursorName(m Model) string {
+	return tableRowName(m.tableRows[m.tableCursor])
+}
+
+func TestJumpToLetter(t *testing.T) {
+	m := newJumpTestModel("alacritty", "bash", "btop", "git", "nvim")
+
+	jump := func(letter rune) {
+		t.Helper()
+		m = pressPresetKeys(t, m, tea.KeyPressMsg{Code: '\'', Text: "'"}, tea.KeyPressMsg{Code: letter, Text: string(letter)})
+	}
+
+	jump('b')
+	if got := cursorName(m); got != "bash" {
+		t.Fatalf("'b moved to %q, want bash", got)
+	}
+
+	jump('b')
+	if got := cursorName(m); got != "btop" {
+		t.Fatalf("second 'b moved to %q, want btop", got)
+	}
+
+	// Wraps around to the first match.
+	jump('b')
+	if got := cursorName(m); got != "bash" {
+		t.Fatalf("third 'b moved to %q, want bash", got)
+	}
+
+	// Case is ignored; no match leaves the cursor alone.
+	jump('N')
+	if got := cursorName(m); got != "nvim" {
+		t.Fatalf("'N moved to %q, want nvim", got)
+	}
+
+	jump('z')
+	if got := cursorName(m); got != "nvim" {
+		t.Fatalf("'z moved to %q, want the cursor to stay on nvim", got)
+	}
+
+	if m.jumping {
+		t.Error("jump mode still active after a letter")
+	}
+}
+
+func TestJumpPrefix_LettersDoNotAct(t *testing.T) {
+	m := newJumpTestModel("alacritty", "nvim")
+
+	// n sorts by name on its own; after the prefix it only jumps.
+	m = pressPresetKeys(t, m, tea.KeyPressMsg{Code: '\'', Text: "'"}, tea.KeyPressMsg{Code: 'n', Text: "n"})
+
+	if got := cursorName(m); got != "nvim" {
+		t.Errorf("'n moved to %q, want nvim", got)
+	}
+
+	if !m.sortAscending || m.sortColumn != SortColumnName {
+		t.Error("'n changed the sort")
+	}
+
+	// Esc cancels the prefix.
+	m = pressPresetKeys(t, m, tea.KeyPressMsg{Code: '\'', Text: "'"}, tea.KeyPressMsg{Code: tea.KeyEscape})
+	if m.jumping {
+		t.Error("esc did not cancel jump mode")
+	}
+}
+
+func TestJumpTopBottom(t *testing.T) {
+	m := newJumpTestModel("a", "b", "c", "d")
+
+	m = pressPresetKeys(t, m, tea.KeyPressMsg{Code: 'G', Text: "G"})
+	if m.tableCursor != len(m.tableRows)-1 {
+		t.Errorf("G moved the cursor to %d, want %d", m.tableCursor, len(m.tableRows)-1)
+	}
+
+	m = pressPresetKeys(t, m, tea.KeyPressMsg{Code: 'g', Text: "g"})
+	if m.tableCursor != 0 {
+		t.Errorf("g moved the cursor to %d, want 0", m.tableCursor)
+	}
+}
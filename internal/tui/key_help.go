@@ -0,0 +1,124 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+
+	"charm.land/bubbles/v2/key"
+	tea "charm.land/bubbletea/v2"
+	"charm.land/lipgloss/v2"
+	"github.com/AntoineGS/tidydots/internal/tui/tuishared"
+)
+
+// acceptsHelpKey reports whether ? opens the key help overlay rather than
+// being typed or handled by a popup: on the summary, and on the list screen
+// outside of its search, popups and confirmations.
+func (m Model) acceptsHelpKey() bool {
+	switch m.Screen {
+	case ScreenSummary:
+		return true
+	case ScreenResults:
+		return !m.searching && !m.jumping && !m.showingPresetPicker && !m.showingResults && !m.showingDetail &&
+			!m.showingDiffPicker && !m.confirmingDeleteApp && !m.confirmingDeleteSubEntry &&
+			!m.confirmingFilterToggle
+	default:
+		return false
+	}
+}
+
+// updateKeyHelp handles key events while the key help overlay is showing.
+func (m Model) updateKeyHelp(msg tea.KeyPressMsg) (tea.Model, tea.Cmd) {
+	switch {
+	case key.Matches(msg, SharedKeys.ForceQuit):
+		return m, tea.Quit
+
+	case key.Matches(msg, SharedKeys.Help), key.Matches(msg, ResultsPopupKeys.Close), key.Matches(msg, SharedKeys.Quit):
+		m.showingKeyHelp = false
+		m.keyHelpScroll = 0
+
+	case key.Matches(msg, ResultsPopupKeys.Up):
+		if m.keyHelpScroll > 0 {
+			m.keyHelpScroll--
+		}
+
+	case key.Matches(msg, ResultsPopupKeys.Down):
+		if m.keyHelpScroll < m.keyHelpMaxScroll() {
+			m.keyHelpScroll++
+		}
+	}
+
+	return m, nil
+}
+
+// keyHelpLines returns the lines of the key help overlay: one section per
+// key context, each action with its keys, description and the name the
+// keybindings section of the app config remaps it by.
+func keyHelpLines() []string {
+	actions := tuishared.KeyActions()
+
+	width := 0
+	for _, a := range actions {
+		width = max(width, lipgloss.Width(a.KeyLabel()))
+	}
+
+	var lines []string
+
+	for _, ctx := range tuishared.KeyContexts {
+		if len(lines) > 0 {
+			lines = append(lines, "")
+		}
+
+		lines = append(lines, SubtitleStyle.Render(ctx.Title))
+
+		for _, a := range actions {
+			if a.Context != ctx.Name {
+				continue
+			}
+
+			label := a.KeyLabel()
+			pad := strings.Repeat(" ", width-lipgloss.Width(label))
+
+			lines = append(lines, fmt.Sprintf("  %s%s  %s  %s",
+				HelpKeyStyle.Render(label), pad, a.Desc(), MutedTextStyle.Render(a.Name)))
+		}
+	}
+
+	return lines
+}
+
+// keyHelpContentHeight returns the number of lines of the overlay visible at
+// once: the screen minus its title and footer.
+func (m Model) keyHelpContentHeight() int {
+	return max(1, m.height-4)
+}
+
+// keyHelpMaxScroll returns the maximum scroll offset of the key help overlay.
+func (m Model) keyHelpMaxScroll() int {
+	return max(0, len(keyHelpLines())-m.keyHelpContentHeight())
+}
+
+// renderKeyHelp renders the key help overlay over the whole screen.
+func (m Model) renderKeyHelp() string {
+	lines := keyHelpLines()
+	start := min(m.keyHelpScroll, len(lines))
+	end := min(start+m.keyHelpContentHeight(), len(lines))
+
+	var b strings.Builder
+
+	b.WriteString(TitleStyle.Render("Keybindings"))
+	b.WriteString("\n")
+
+	for _, line := range lines[start:end] {
+		b.WriteString(line)
+		b.WriteString("\n")
+	}
+
+	if len(lines) > end-start {
+		b.WriteString(MutedTextStyle.Render(fmt.Sprintf("(%d-%d of %d)", start+1, end, len(lines))))
+		b.WriteString("  ")
+	}
+
+	b.WriteString(RenderHelpFromBindings(m.width, ResultsPopupKeys.Up, ResultsPopupKeys.Down, SharedKeys.Help))
+
+	return b.String()
+}
@@ -0,0 +1,139 @@
+package tui
+
+import (
+	"strings"
+	"testing"
+
+	tea "charm.land/bubbletea/v2"
+	"github.com/AntoineGS/tidydots/internal/config"
+	"github.com/AntoineGS/tidydots/internal/tui/tuishared"
+)
+
+// resetKeyBindings restores the default keys after a test remaps them.
+func resetKeyBindings(t *testing.T) {
+	t.Helper()
+
+	t.Cleanup(func() {
+		if err := tuishared.ApplyKeyBindings(nil); err != nil {
+			t.Errorf("ApplyKeyBindings(nil) error = %v", err)
+		}
+	})
+}
+
+func TestKeyHelp_Toggle(t *testing.T) {
+	m := NewModel(&config.Config{Version: 3}, linuxPlatform(), false)
+	m.width, m.height = 100, 200
+
+	m = pressPresetKeys(t, m, tea.KeyPressMsg{Code: '?', Text: "?"})
+	if !m.showingKeyHelp {
+		t.Fatal("? did not open the key help overlay")
+	}
+
+	view := m.View().Content
+	for _, want := range []string{"Keybindings", "List", "Forms", "Summary", "list.sort_by_name"} {
+		if !strings.Contains(view, want) {
+			t.Errorf("overlay does not contain %q:\n%s", want, view)
+		}
+	}
+
+	// q closes the overlay rather than quitting.
+	m = pressPresetKeys(t, m, tea.KeyPressMsg{Code: 'q', Text: "q"})
+	if m.showingKeyHelp {
+		t.Error("q did not close the key help overlay")
+	}
+}
+
+func TestKeyHelp_NotWhileSearching(t *testing.T) {
+	m := NewModel(&config.Config{Version: 3}, linuxPlatform(), false)
+	m.searching = true
+
+	m = pressPresetKeys(t, m, tea.KeyPressMsg{Code: '?', Text: "?"})
+	if m.showingKeyHelp {
+		t.Error("? opened the key help overlay while searching")
+	}
+}
+
+func TestKeyActions_Names(t *testing.T) {
+	names := make(map[string]bool)
+	for _, a := range tuishared.KeyActions() {
+		if names[a.Name] {
+			t.Errorf("duplicate action name %q", a.Name)
+		}
+
+		names[a.Name] = true
+	}
+
+	for _, want := range []string{"global.help", "list.sort_by_name", "form.deps", "file_picker.toggle"} {
+		if !names[want] {
+			t.Errorf("KeyActions() has no %q", want)
+		}
+	}
+}
+
+func TestApplyKeyBindings(t *testing.T) {
+	resetKeyBindings(t)
+
+	tests := []struct {
+		name      string
+		overrides map[string][]string
+		wantErr   string
+	}{
+		{name: "unknown action", overrides: map[string][]string{"list.fly": {"F"}}, wantErr: "unknown action"},
+		{name: "empty keys", overrides: map[string][]string{"list.search": {}}, wantErr: "must not be empty"},
+		{name: "same context", overrides: map[string][]string{"list.search": {"e"}}, wantErr: "list.edit"},
+		{name: "global", overrides: map[string][]string{"list.search": {"q"}}, wantErr: "global.quit"},
+		{name: "swap", overrides: map[string][]string{"list.edit": {"d"}, "list.delete": {"e"}}},
+		{name: "other context", overrides: map[string][]string{"list.search": {"ctrl+f"}, "form.deps": {"ctrl+f"}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tuishared.ApplyKeyBindings(tt.overrides)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("ApplyKeyBindings() error = %v", err)
+				}
+
+				return
+			}
+
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("ApplyKeyBindings() error = %v, want one containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestApplyKeyBindings_Effective(t *testing.T) {
+	resetKeyBindings(t)
+
+	if err := tuishared.ApplyKeyBindings(map[string][]string{"list.search": {"ctrl+f"}}); err != nil {
+		t.Fatalf("ApplyKeyBindings() error = %v", err)
+	}
+
+	m := NewModel(&config.Config{Version: 3}, linuxPlatform(), false)
+	m.width, m.height = 200, 40
+
+	if footer := m.renderHelpForCurrentState(); !strings.Contains(footer, "ctrl+f") {
+		t.Errorf("footer does not show the remapped key:\n%s", footer)
+	}
+
+	m = pressPresetKeys(t, m, tea.KeyPressMsg{Code: '/', Text: "/"})
+	if m.searching {
+		t.Error("/ still starts a search after remapping it")
+	}
+
+	m = pressPresetKeys(t, m, tea.KeyPressMsg{Code: 'f', Mod: tea.ModCtrl})
+	if !m.searching {
+		t.Error("ctrl+f did not start a search")
+	}
+
+	// Applying no overrides restores the defaults.
+	if err := tuishared.ApplyKeyBindings(nil); err != nil {
+		t.Fatalf("ApplyKeyBindings(nil) error = %v", err)
+	}
+
+	if got := ListKeys.Search.Keys(); len(got) != 1 || got[0] != "/" {
+		t.Errorf("ListKeys.Search.Keys() = %v, want [/]", got)
+	}
+}
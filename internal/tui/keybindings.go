@@ -41,9 +41,21 @@ type DiffPickerKeyMap = tuishared.DiffPickerKeyMap
 // ResultsPopupKeyMap is an alias for tuishared.ResultsPopupKeyMap.
 type ResultsPopupKeyMap = tuishared.ResultsPopupKeyMap
 
+// ConflictKeyMap is an alias for tuishared.ConflictKeyMap.
+type ConflictKeyMap = tuishared.ConflictKeyMap
+
+// PresetPickerKeyMap is an alias for tuishared.PresetPickerKeyMap.
+type PresetPickerKeyMap = tuishared.PresetPickerKeyMap
+
+// PackagePickerKeyMap is an alias for tuishared.PackagePickerKeyMap.
+type PackagePickerKeyMap = tuishared.PackagePickerKeyMap
+
 // FilePickerKeyMap is an alias for tuishared.FilePickerKeyMap.
 type FilePickerKeyMap = tuishared.FilePickerKeyMap
 
+// PathPickerKeyMap is an alias for tuishared.PathPickerKeyMap.
+type PathPickerKeyMap = tuishared.PathPickerKeyMap
+
 // ModeChooserKeyMap is an alias for tuishared.ModeChooserKeyMap.
 type ModeChooserKeyMap = tuishared.ModeChooserKeyMap
 
@@ -52,19 +64,23 @@ type FilesListKeyMap = tuishared.FilesListKeyMap
 
 // Keybinding instances — re-exported from tuishared.
 var (
-	SharedKeys       = tuishared.SharedKeys
-	ListKeys         = tuishared.ListKeys
-	MultiSelectKeys  = tuishared.MultiSelectKeys
-	SearchKeys       = tuishared.SearchKeys
-	ConfirmKeys      = tuishared.ConfirmKeys
-	DetailKeys       = tuishared.DetailKeys
-	FormNavKeys      = tuishared.FormNavKeys
-	TextEditKeys     = tuishared.TextEditKeys
-	SuggestionKeys   = tuishared.SuggestionKeys
-	SummaryKeys      = tuishared.SummaryKeys
-	DiffPickerKeys   = tuishared.DiffPickerKeys
-	ResultsPopupKeys = tuishared.ResultsPopupKeys
-	FilePickerKeys   = tuishared.FilePickerKeys
-	ModeChooserKeys  = tuishared.ModeChooserKeys
-	FilesListKeys    = tuishared.FilesListKeys
+	SharedKeys        = &tuishared.SharedKeys
+	ListKeys          = &tuishared.ListKeys
+	MultiSelectKeys   = &tuishared.MultiSelectKeys
+	SearchKeys        = &tuishared.SearchKeys
+	ConfirmKeys       = &tuishared.ConfirmKeys
+	DetailKeys        = &tuishared.DetailKeys
+	FormNavKeys       = &tuishared.FormNavKeys
+	TextEditKeys      = &tuishared.TextEditKeys
+	SuggestionKeys    = &tuishared.SuggestionKeys
+	SummaryKeys       = &tuishared.SummaryKeys
+	DiffPickerKeys    = &tuishared.DiffPickerKeys
+	ResultsPopupKeys  = &tuishared.ResultsPopupKeys
+	ConflictKeys      = &tuishared.ConflictKeys
+	PresetPickerKeys  = &tuishared.PresetPickerKeys
+	PackagePickerKeys = &tuishared.PackagePickerKeys
+	FilePickerKeys    = &tuishared.FilePickerKeys
+	PathPickerKeys    = &tuishared.PathPickerKeys
+	ModeChooserKeys   = &tuishared.ModeChooserKeys
+	FilesListKeys     = &tuishared.FilesListKeys
 )
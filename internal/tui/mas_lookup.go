@@ -0,0 +1,43 @@
+package tui
+
+import (
+	"context"
+
+	tea "charm.land/bubbletea/v2"
+	"github.com/AntoineGS/tidydots/internal/cmdexec"
+	"github.com/AntoineGS/tidydots/internal/packages"
+	"github.com/AntoineGS/tidydots/internal/platform"
+)
+
+// masLookupMsg reports whether the App Store ID entered for mas names an app.
+type masLookupMsg struct {
+	err error
+	id  string
+}
+
+// lookupMasAppCmd checks an App Store ID with mas in the background. Without
+// mas there is nothing to ask, so only the numeric check of the form applies.
+func lookupMasAppCmd(id string) tea.Cmd {
+	if !platform.IsCommandAvailable(string(packages.Mas)) {
+		return nil
+	}
+
+	return func() tea.Msg {
+		_, err := packages.LookupMasApp(context.Background(), cmdexec.OsRunner{}, id)
+		return masLookupMsg{id: id, err: err}
+	}
+}
+
+// handleMasLookupResult shows a failed lookup as the application form's error,
+// unless the form was closed or the mas ID changed since.
+func (m Model) handleMasLookupResult(msg masLookupMsg) (tea.Model, tea.Cmd) {
+	if msg.err == nil || m.applicationForm == nil {
+		return m, nil
+	}
+
+	if m.applicationForm.PackageManagers[string(packages.Mas)] == msg.id {
+		m.applicationForm.Err = msg.err.Error()
+	}
+
+	return m, nil
+}
@@ -1,8 +1,6 @@
 package tui
 
 import (
-	"path/filepath"
-
 	"charm.land/bubbles/v2/key"
 	"charm.land/bubbles/v2/progress"
 	"charm.land/bubbles/v2/spinner"
@@ -10,7 +8,9 @@ import (
 	tea "charm.land/bubbletea/v2"
 	"github.com/AntoineGS/tidydots/internal/config"
 	"github.com/AntoineGS/tidydots/internal/manager"
+	"github.com/AntoineGS/tidydots/internal/packages"
 	"github.com/AntoineGS/tidydots/internal/platform"
+	"github.com/AntoineGS/tidydots/internal/presets"
 	tmpl "github.com/AntoineGS/tidydots/internal/template"
 	"github.com/AntoineGS/tidydots/internal/tui/detection"
 	"github.com/AntoineGS/tidydots/internal/tui/forms"
@@ -73,6 +73,12 @@ const (
 	StateSetupOk = tuitable.StateSetupOk
 	// StateSetupNeeded indicates a setup entry whose check command fails.
 	StateSetupNeeded = tuitable.StateSetupNeeded
+	// StateDirty indicates linked but the backup files have uncommitted git changes.
+	StateDirty = tuitable.StateDirty
+	// StateDisabled indicates an entry switched off with enabled: false; it is not checked.
+	StateDisabled = tuitable.StateDisabled
+	// StatePartial indicates a link_mode: files entry with only some file links in place.
+	StatePartial = tuitable.StatePartial
 )
 
 // TableRow is an alias for tuitable.Row so that all existing code in
@@ -109,6 +115,7 @@ type Model struct {
 	Platform                 *platform.Platform
 	Renderer                 config.PathRenderer
 	Manager                  *manager.Manager
+	pkgManager               *packages.Manager // decides which packages a batch install can install; see packageManager
 	subEntryForm             *SubEntryForm
 	applicationForm          *ApplicationForm
 	searchText               string
@@ -130,8 +137,12 @@ type Model struct {
 	Screen                   Screen
 	activeForm               FormType
 	DryRun                   bool
+	NoSudo                   bool // skip installs and restores that need sudo
+	Offline                  bool // skip installs and setup entries, which may need the network
+	SkipVerify               bool // install URL packages without verifying their downloads
 	processing               bool
 	searching                bool
+	jumping                  bool // the jump prefix was pressed; the next letter jumps
 	confirmingDeleteSubEntry bool
 	confirmingDeleteApp      bool
 	confirmingFilterToggle   bool // true when showing filter toggle confirmation
@@ -145,6 +156,34 @@ type Model struct {
 	diffPickerCursor  int
 	diffPickerFiles   []manager.ModifiedTemplate
 
+	// Restore conflict state. A restore whose targets conflict with their
+	// backup asks what to do with each entry first (see conflicts.go).
+	resolvingConflicts bool
+	conflictItems      []conflictItem
+	conflictIndex      int    // entry being decided
+	conflictCursor     int    // highlighted choice
+	conflictDiff       string // diff of the entry being decided, while shown
+	conflictScroll     int
+	conflictBatch      bool // the conflicts belong to a multi-select batch restore
+	// resolutions holds the decisions for the restore being run; nil until
+	// its conflicts have been asked about.
+	resolutions map[subEntryKey]manager.Resolution
+	// interruptedRestore holds the selected entries the last batch or CLI
+	// restore completed without finishing; resumeRestore skips them.
+	interruptedRestore map[subEntryKey]bool
+	resumeRestore      bool
+
+	// Key help overlay state, toggled with ?
+	showingKeyHelp bool
+	keyHelpScroll  int
+
+	// Preset picker state, shown before the form that adds an application
+	showingPresetPicker bool
+	presetSearch        textinput.Model
+	presetCatalog       []presets.Preset
+	presetCursor        int    // 0 is the empty application
+	presetErr           string // why the catalog could not be loaded
+
 	// Filter state
 	filterEnabled bool // true to hide filtered apps, false to show all
 
@@ -173,6 +212,31 @@ type Model struct {
 	batchSuccessCount int            // Count of successful operations
 	batchFailCount    int            // Count of failed operations
 
+	// Persisted list view state (see ui_state.go). savedExpanded seeds
+	// ApplicationItem.Expanded when items are rebuilt from config.
+	savedExpanded map[string]bool
+	uiStatePath   string  // empty disables persistence
+	lastUIState   uiState // state as of the last scheduled save
+	uiStateGen    int     // bumped on every change; debounces saves
+
+	// globallyUniqueSubEntryNames mirrors the app config setting of the same
+	// name: sub-entry names must be unique across all applications.
+	globallyUniqueSubEntryNames bool
+
+	// dirtyFiles are the backup files with uncommitted git changes, from a
+	// single git status per run (see dirty_state.go). nil disables the check.
+	dirtyFiles map[string]bool
+
+	// history holds the last backup and restore of each entry on this
+	// machine. It is reloaded from the state store when the detail panel
+	// opens, so it reflects operations run since the TUI started.
+	history map[manager.HistoryKey]manager.EntryHistory
+
+	// notes holds each application's notes, or why they could not be read,
+	// by application name. Like history it is reloaded when the detail
+	// panel opens, so edits to a notes_file show up.
+	notes map[string]string
+
 	// Pending async state check counter — avoids rebuilding the table on
 	// every single pkgCheckResultMsg / stateCheckResultMsg.  The table is
 	// rebuilt only once when the counter reaches 0.
@@ -196,6 +260,7 @@ type ApplicationItem struct {
 	SubItems     []SubEntryItem
 	Expanded     bool
 	IsFiltered   bool // True if this app doesn't match the current filter context
+	IsDisabled   bool // True if the app has enabled: false; it is shown but never checked or acted on
 }
 
 // SubEntryItem represents a sub-entry within an application (config or git)
@@ -204,6 +269,8 @@ type SubEntryItem struct {
 	Target   string
 	SubEntry config.SubEntry
 	State    PathState
+	// IsDisabled is true if the entry, or its application, has enabled: false.
+	IsDisabled bool
 	// Index is this entry's position in its application's SubItems. It is carried
 	// on the item because the search filter (getSearchedApplications) hands the
 	// table a compacted copy of SubItems holding only the matching entries: a
@@ -221,7 +288,7 @@ type ResultItem = tuiops.ResultItem
 // loading entries, detecting path states, and initializing the UI.
 func NewModel(cfg *config.Config, plat *platform.Platform, dryRun bool) Model {
 	// Create template engine for when expression evaluation
-	tmplCtx := tmpl.NewContextFromPlatform(plat)
+	tmplCtx := tmpl.NewContextFromPlatform(plat).WithConfig(cfg)
 	renderer := tmpl.NewEngine(tmplCtx)
 
 	// Initialize search input
@@ -278,6 +345,7 @@ func (m Model) Init() tea.Cmd {
 		m.spinner.Tick,
 		pkgCmd,
 		subCmd,
+		m.checkDirtyCmd(),
 	)
 }
 
@@ -291,12 +359,18 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	case stateCheckResultMsg:
 		return m.handleStateCheckResult(msg)
 
+	case dirtyCheckResultMsg:
+		return m.handleDirtyCheckResult(msg)
+
 	case batchRestoreConfigsDoneMsg:
 		return m.handleBatchRestoreConfigsDone(msg)
 
 	case setupRunMsg:
 		return m.handleSetupRunResult(msg)
 
+	case masLookupMsg:
+		return m.handleMasLookupResult(msg)
+
 	case spinner.TickMsg:
 		if m.hasLoadingItems() {
 			var cmd tea.Cmd
@@ -306,12 +380,15 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		return m, nil
 
 	case tea.KeyPressMsg:
-		return m.handleKeyPress(msg)
+		return trackUIState(m.handleKeyPress(msg))
 
 	case tea.MouseClickMsg:
-		return m.handleMouseClickEvent(msg)
+		return trackUIState(m.handleMouseClickEvent(msg))
 	case tea.MouseWheelMsg:
-		return m.handleMouseWheelEvent(msg)
+		return trackUIState(m.handleMouseWheelEvent(msg))
+
+	case uiStateSaveMsg:
+		return m.handleUIStateSave(msg)
 
 	case editorLaunchCompleteMsg:
 		// Editor exited - refresh application states since template may have changed.
@@ -326,7 +403,7 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				Message: msg.err.Error(),
 			}}
 		}
-		return m, m.dispatchLoadingSubEntryStates()
+		return m, tea.Batch(m.dispatchLoadingSubEntryStates(), m.checkDirtyCmd())
 
 	case tea.WindowSizeMsg:
 		m.width = msg.Width
@@ -437,6 +514,8 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		// Initialize batch package installation
 		m.pendingPackages = msg.packages
 		m.currentPackageIndex = 0
+		// Skipped packages head the results, so nothing selected goes unreported.
+		m.results = msg.skipped
 
 		// Start installing first package
 		if len(m.pendingPackages) > 0 {
@@ -453,6 +532,13 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		}
 	}
 
+	// The file picker reads directories asynchronously; hand it the result.
+	if m.subEntryForm != nil && (m.subEntryForm.AddFileMode == ModePicker || m.subEntryForm.AddFileMode == ModePathPicker) {
+		var cmd tea.Cmd
+		m.subEntryForm.FilePicker, cmd = m.subEntryForm.FilePicker.Update(msg)
+		return m, cmd
+	}
+
 	return m, nil
 }
 
@@ -498,6 +584,22 @@ func (m Model) handleKeyPress(msg tea.KeyPressMsg) (tea.Model, tea.Cmd) {
 		}
 	}
 
+	// The conflict popup takes every key until the restore goes ahead
+	if m.resolvingConflicts {
+		return m.updateConflicts(msg)
+	}
+
+	if m.showingKeyHelp {
+		return m.updateKeyHelp(msg)
+	}
+
+	if key.Matches(msg, SharedKeys.Help) && m.acceptsHelpKey() {
+		m.showingKeyHelp = true
+		m.keyHelpScroll = 0
+
+		return m, nil
+	}
+
 	switch {
 	case key.Matches(msg, SharedKeys.ForceQuit):
 		return m, tea.Quit
@@ -566,6 +668,9 @@ func (m Model) View() tea.View {
 		if m.showingResults && m.Operation == OpList && len(m.results) > 0 {
 			content = m.renderResultsPopup()
 		}
+		if m.showingPresetPicker && m.Operation == OpList {
+			content = m.renderPresetPicker()
+		}
 	case ScreenAddForm:
 		// Route to appropriate form view based on activeForm
 		switch m.activeForm {
@@ -582,6 +687,14 @@ func (m Model) View() tea.View {
 		content = m.viewSummary()
 	}
 
+	if m.resolvingConflicts && len(m.conflictItems) > 0 {
+		content = m.renderConflictsPopup()
+	}
+
+	if m.showingKeyHelp {
+		content = m.renderKeyHelp()
+	}
+
 	v := tea.NewView(content)
 	v.AltScreen = true
 	v.MouseMode = tea.MouseModeCellMotion
@@ -600,11 +713,11 @@ type PackageInstallMsg struct {
 	Success bool
 }
 
-// detectConfigState determines the state of a config entry given its paths and file list.
+// detectConfigState determines the state of a config entry given its paths.
 // This is the shared logic used by both detectPathState and detectSubEntryState.
-// It delegates to detection.DetectConfigState which is the canonical implementation.
-func detectConfigState(backupPath, targetPath string, isFolder bool, files []string, isCopy bool) PathState {
-	return detection.DetectConfigState(backupPath, targetPath, isFolder, files, isCopy)
+// It delegates to detection.DetectSubEntryState which is the canonical implementation.
+func detectConfigState(sub config.SubEntry, backupPath, targetPath string) PathState {
+	return detection.DetectSubEntryState(sub, backupPath, targetPath)
 }
 
 // handlePkgCheckResult processes the result of a single async package install check.
@@ -623,7 +736,10 @@ func (m Model) handlePkgCheckResult(msg pkgCheckResultMsg) (tea.Model, tea.Cmd)
 // handleStateCheckResult processes the result of a single async sub-entry state check.
 func (m Model) handleStateCheckResult(msg stateCheckResultMsg) (tea.Model, tea.Cmd) {
 	if msg.appIndex < len(m.Applications) && msg.subIndex < len(m.Applications[msg.appIndex].SubItems) {
-		m.Applications[msg.appIndex].SubItems[msg.subIndex].State = msg.state
+		// An entry disabled while its check was in flight keeps StateDisabled.
+		if item := &m.Applications[msg.appIndex].SubItems[msg.subIndex]; !item.IsDisabled {
+			item.State = m.withDirtyState(item, msg.state)
+		}
 	}
 	m.decrementPendingAndRebuild()
 	return m, nil
@@ -678,7 +794,8 @@ func (m Model) handleMouseClickEvent(msg tea.MouseClickMsg) (tea.Model, tea.Cmd)
 
 	// Don't handle mouse during modal states
 	if m.searching || m.confirmingDeleteApp || m.confirmingDeleteSubEntry ||
-		m.confirmingFilterToggle || m.showingDetail || m.showingResults {
+		m.confirmingFilterToggle || m.showingDetail || m.showingResults || m.resolvingConflicts ||
+		m.showingPresetPicker || m.showingKeyHelp {
 		return m, nil
 	}
 
@@ -704,7 +821,8 @@ func (m Model) handleMouseWheelEvent(msg tea.MouseWheelMsg) (tea.Model, tea.Cmd)
 
 	// Don't handle mouse during modal states
 	if m.searching || m.confirmingDeleteApp || m.confirmingDeleteSubEntry ||
-		m.confirmingFilterToggle || m.showingDetail || m.showingResults {
+		m.confirmingFilterToggle || m.showingDetail || m.showingResults || m.resolvingConflicts ||
+		m.showingPresetPicker || m.showingKeyHelp {
 		return m, nil
 	}
 
@@ -792,12 +910,5 @@ func (m Model) handleMouseClick(mouseY int, toggleSelect bool) (tea.Model, tea.C
 
 // resolvePath resolves relative paths against BackupRoot and expands ~ in paths
 func (m Model) resolvePath(path string) string {
-	expandedPath := config.ExpandPath(path, m.Platform.EnvVars)
-
-	if filepath.IsAbs(expandedPath) {
-		return expandedPath
-	}
-
-	expandedBackupRoot := config.ExpandPath(m.Config.BackupRoot, m.Platform.EnvVars)
-	return filepath.Join(expandedBackupRoot, expandedPath)
+	return config.ResolveBackupPath(path, m.Config.BackupRoot, m.Platform.EnvVars, nil)
 }
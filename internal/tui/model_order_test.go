@@ -4,6 +4,7 @@ import (
 	"slices"
 	"testing"
 
+	tea "charm.land/bubbletea/v2"
 	"github.com/AntoineGS/tidydots/internal/config"
 )
 
@@ -89,3 +90,39 @@ func TestSelection_SurvivesResort(t *testing.T) {
 			items[0].name, "alpha/conf-a")
 	}
 }
+
+// tableAppNames returns the application rows of the table, in display order.
+func tableAppNames(m *Model) []string {
+	var names []string
+	for _, row := range m.tableRows {
+		if row.SubIndex == -1 {
+			names = append(names, row.AppName)
+		}
+	}
+
+	return names
+}
+
+func TestSortByPriority(t *testing.T) {
+	cfg := orderProbeConfig()
+	cfg.Applications[1].Priority = 10
+
+	m := NewModel(cfg, linuxPlatform(), false)
+
+	for _, want := range [][]string{{"zebra", "alpha"}, {"alpha", "zebra"}} {
+		updated, _ := m.updateResults(tea.KeyPressMsg{Code: 'o', Text: "o"})
+
+		var ok bool
+		if m, ok = updated.(Model); !ok {
+			t.Fatalf("updateResults returned %T, want Model", updated)
+		}
+
+		if m.sortColumn != SortColumnPriority {
+			t.Fatalf("sortColumn = %q, want %q", m.sortColumn, SortColumnPriority)
+		}
+
+		if got := tableAppNames(&m); !slices.Equal(got, want) {
+			t.Errorf("table order with ascending=%v = %v, want %v", m.sortAscending, got, want)
+		}
+	}
+}
@@ -0,0 +1,189 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+
+	"charm.land/bubbles/v2/key"
+	tea "charm.land/bubbletea/v2"
+	"charm.land/lipgloss/v2"
+)
+
+// packagePickerMinVisible is the fewest packages the picker lists at once,
+// however small the terminal.
+const packagePickerMinVisible = 5
+
+// packagePicker is the program of SelectPackages: a list of package names
+// to check the ones to import.
+type packagePicker struct {
+	title     string
+	names     []string
+	selected  []bool
+	cursor    int
+	height    int
+	confirmed bool
+}
+
+// SelectPackages lets the user check, in a full-screen list headed by
+// title, which of names to import. Every name starts checked. It returns
+// the checked names in the order of names, or nil when the user cancels.
+func SelectPackages(title string, names []string) ([]string, error) {
+	picker := newPackagePicker(title, names)
+
+	final, err := tea.NewProgram(picker).Run()
+	if err != nil {
+		return nil, fmt.Errorf("TUI error: %w", err)
+	}
+
+	p, ok := final.(packagePicker)
+	if !ok {
+		return nil, fmt.Errorf("unexpected model type")
+	}
+
+	return p.result(), nil
+}
+
+// newPackagePicker returns a picker of names with every name checked.
+func newPackagePicker(title string, names []string) packagePicker {
+	selected := make([]bool, len(names))
+	for i := range selected {
+		selected[i] = true
+	}
+
+	return packagePicker{title: title, names: names, selected: selected}
+}
+
+// result returns the checked names once confirmed, and nil otherwise.
+func (p packagePicker) result() []string {
+	if !p.confirmed {
+		return nil
+	}
+
+	names := []string{}
+
+	for i, name := range p.names {
+		if p.selected[i] {
+			names = append(names, name)
+		}
+	}
+
+	return names
+}
+
+// Init implements tea.Model.
+func (p packagePicker) Init() tea.Cmd {
+	return nil
+}
+
+// Update implements tea.Model.
+func (p packagePicker) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		p.height = msg.Height
+		return p, nil
+
+	case tea.KeyPressMsg:
+		return p.updateKeys(msg)
+	}
+
+	return p, nil
+}
+
+// updateKeys handles key events of the picker.
+func (p packagePicker) updateKeys(msg tea.KeyPressMsg) (tea.Model, tea.Cmd) {
+	switch {
+	case key.Matches(msg, SharedKeys.ForceQuit), key.Matches(msg, PackagePickerKeys.Cancel):
+		return p, tea.Quit
+
+	case key.Matches(msg, PackagePickerKeys.Confirm):
+		p.confirmed = true
+		return p, tea.Quit
+
+	case key.Matches(msg, PackagePickerKeys.Up):
+		if p.cursor > 0 {
+			p.cursor--
+		}
+
+	case key.Matches(msg, PackagePickerKeys.Down):
+		if p.cursor < len(p.names)-1 {
+			p.cursor++
+		}
+
+	case key.Matches(msg, PackagePickerKeys.Toggle):
+		if p.cursor < len(p.names) {
+			p.selected[p.cursor] = !p.selected[p.cursor]
+		}
+
+	case key.Matches(msg, PackagePickerKeys.ToggleAll):
+		// Check everything unless everything is checked already.
+		all := p.count() < len(p.names)
+		for i := range p.selected {
+			p.selected[i] = all
+		}
+	}
+
+	return p, nil
+}
+
+// count returns how many names are checked.
+func (p packagePicker) count() int {
+	n := 0
+
+	for _, s := range p.selected {
+		if s {
+			n++
+		}
+	}
+
+	return n
+}
+
+// visible returns how many names fit on screen under the title and above
+// the count and help lines.
+func (p packagePicker) visible() int {
+	return max(packagePickerMinVisible, p.height-6)
+}
+
+// View implements tea.Model.
+func (p packagePicker) View() tea.View {
+	var b strings.Builder
+
+	titleStyle := lipgloss.NewStyle().Bold(true).Foreground(primaryColor)
+	b.WriteString(titleStyle.Render(p.title))
+	b.WriteString("\n\n")
+
+	// Keep the cursor in view.
+	visible := p.visible()
+	start := max(0, p.cursor-visible+1)
+	end := min(len(p.names), start+visible)
+
+	for i := start; i < end; i++ {
+		cursor, style := "  ", ListItemStyle
+		if i == p.cursor {
+			cursor, style = "> ", SelectedListItemStyle
+		}
+
+		check := "[ ]"
+		if p.selected[i] {
+			check = "[x]"
+		}
+
+		fmt.Fprintf(&b, "%s%s %s\n", cursor, check, style.Render(p.names[i]))
+	}
+
+	b.WriteString("\n")
+	b.WriteString(MutedTextStyle.Render(fmt.Sprintf("%d of %d selected", p.count(), len(p.names))))
+	b.WriteString("\n")
+	b.WriteString(RenderHelp(
+		PlainKeys(PackagePickerKeys.Up, PackagePickerKeys.Down), "move",
+		PlainKeys(PackagePickerKeys.Toggle), PackagePickerKeys.Toggle.Help().Desc,
+		PlainKeys(PackagePickerKeys.ToggleAll), PackagePickerKeys.ToggleAll.Help().Desc,
+		PlainKeys(PackagePickerKeys.Confirm), PackagePickerKeys.Confirm.Help().Desc,
+		PlainKeys(PackagePickerKeys.Cancel), PackagePickerKeys.Cancel.Help().Desc,
+	))
+
+	v := tea.NewView(b.String())
+	v.AltScreen = true
+
+	return v
+}
@@ -0,0 +1,71 @@
+package tui
+
+import (
+	"slices"
+	"strings"
+	"testing"
+
+	tea "charm.land/bubbletea/v2"
+)
+
+// pressPicker sends keys to p and returns the resulting picker.
+func pressPicker(t *testing.T, p packagePicker, keys ...tea.KeyPressMsg) packagePicker {
+	t.Helper()
+
+	for _, k := range keys {
+		updated, _ := p.Update(k)
+
+		var ok bool
+		if p, ok = updated.(packagePicker); !ok {
+			t.Fatalf("Update returned %T, want packagePicker", updated)
+		}
+	}
+
+	return p
+}
+
+func TestPackagePicker_Select(t *testing.T) {
+	keySpace := tea.KeyPressMsg{Code: tea.KeySpace, Text: " "}
+	keyAll := tea.KeyPressMsg{Code: 'a', Text: "a"}
+
+	names := []string{"git", "neovim", "tmux"}
+
+	// Uncheck neovim.
+	got := pressPicker(t, newPackagePicker("Import pacman packages", names), keyDown, keySpace, keyEnter).result()
+	if want := []string{"git", "tmux"}; !slices.Equal(got, want) {
+		t.Errorf("result = %v, want %v", got, want)
+	}
+
+	// Toggle all unchecks everything when everything is checked, and checks
+	// everything otherwise.
+	got = pressPicker(t, newPackagePicker("Import pacman packages", names), keyAll, keyEnter).result()
+	if len(got) != 0 || got == nil {
+		t.Errorf("result after unchecking all = %#v, want an empty list", got)
+	}
+
+	got = pressPicker(t, newPackagePicker("Import pacman packages", names), keyAll, keySpace, keyAll, keyEnter).result()
+	if want := []string{"git", "neovim", "tmux"}; !slices.Equal(got, want) {
+		t.Errorf("result after checking all = %v, want %v", got, want)
+	}
+}
+
+func TestPackagePicker_Cancel(t *testing.T) {
+	p := newPackagePicker("Import pacman packages", []string{"git"})
+
+	if got := pressPicker(t, p, keyEsc).result(); got != nil {
+		t.Errorf("result after cancel = %v, want nil", got)
+	}
+}
+
+func TestPackagePicker_View(t *testing.T) {
+	p := newPackagePicker("Import pacman packages", []string{"git", "neovim"})
+	p = pressPicker(t, p, tea.KeyPressMsg{Code: tea.KeySpace, Text: " "})
+
+	view := stripAnsiCodes(p.View().Content)
+
+	for _, want := range []string{"Import pacman packages", "> [ ] git", "[x] neovim", "1 of 2 selected"} {
+		if !strings.Contains(view, want) {
+			t.Errorf("view does not contain %q:\n%s", want, view)
+		}
+	}
+}
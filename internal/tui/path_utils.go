@@ -176,3 +176,40 @@ func convertToRelativePaths(absPaths []string, targetDir string) ([]string, []er
 
 	return relativePaths, errors
 }
+
+// pickedPathValue returns the form value for a path picked in the file picker:
+// "./..." when it is under repoDir (empty for target fields), "~/..." when it
+// is under home, and path itself otherwise. Relative parts use forward slashes
+// like the rest of the config.
+func pickedPathValue(path, home, repoDir string) string {
+	if rel, ok := relativeUnder(repoDir, path); ok {
+		if rel == "." {
+			return "."
+		}
+		return "./" + rel
+	}
+
+	if rel, ok := relativeUnder(home, path); ok {
+		if rel == "." {
+			return "~"
+		}
+		return "~/" + rel
+	}
+
+	return path
+}
+
+// relativeUnder returns path relative to base, with forward slashes, when
+// path is base or lies under it.
+func relativeUnder(base, path string) (string, bool) {
+	if base == "" {
+		return "", false
+	}
+
+	rel, err := filepath.Rel(filepath.Clean(base), filepath.Clean(path))
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", false
+	}
+
+	return filepath.ToSlash(rel), true
+}
@@ -390,3 +390,32 @@ func TestConvertToRelativePaths(t *testing.T) {
 		})
 	}
 }
+
+func TestPickedPathValue(t *testing.T) {
+	home := filepath.Join(string(filepath.Separator), "home", "user")
+	repo := filepath.Join(home, "dotfiles")
+	other := filepath.Join(string(filepath.Separator), "etc", "nginx")
+
+	tests := []struct {
+		name    string
+		path    string
+		repoDir string
+		want    string
+	}{
+		{"under home", filepath.Join(home, ".config", "nvim"), "", "~/.config/nvim"},
+		{"home itself", home, "", "~"},
+		{"repo for a target stays home-relative", filepath.Join(repo, "nvim"), "", "~/dotfiles/nvim"},
+		{"under repo", filepath.Join(repo, "nvim", "init.lua"), repo, "./nvim/init.lua"},
+		{"repo itself", repo, repo, "."},
+		{"sibling with repo prefix", filepath.Join(home, "dotfiles-old"), repo, "~/dotfiles-old"},
+		{"outside home", other, repo, other},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := pickedPathValue(tt.path, home, tt.repoDir); got != tt.want {
+				t.Errorf("pickedPathValue(%q) = %q, want %q", tt.path, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,172 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+
+	"charm.land/bubbles/v2/key"
+	tea "charm.land/bubbletea/v2"
+	"charm.land/lipgloss/v2"
+	"github.com/AntoineGS/tidydots/internal/presets"
+)
+
+// presetPickerVisible is the number of presets the picker lists at once.
+const presetPickerVisible = 10
+
+// openPresetPicker shows the preset picker that starts adding an
+// application. A catalog that fails to load is reported in the picker, which
+// still offers the empty form.
+func (m *Model) openPresetPicker() {
+	m.presetCatalog, m.presetErr = nil, ""
+
+	catalog, err := presets.Load(m.Config.BackupRoot)
+	if err != nil {
+		m.presetErr = err.Error()
+	} else {
+		m.presetCatalog = catalog
+	}
+
+	m.presetSearch = newFormInput("type to search presets...", CharLimitName, InputWidthNarrow)
+	m.presetSearch.Focus()
+	m.presetCursor = 0
+	m.showingPresetPicker = true
+}
+
+// presetMatches returns the presets matching the picker's search.
+func (m Model) presetMatches() []presets.Preset {
+	return presets.Search(m.presetCatalog, m.presetSearch.Value())
+}
+
+// updatePresetPicker handles key events while the preset picker is showing.
+// Row 0 is the empty application; row i > 0 is the (i-1)th matching preset.
+func (m Model) updatePresetPicker(msg tea.KeyPressMsg) (tea.Model, tea.Cmd) {
+	if m, cmd, handled := m.handleTextEditKeys(msg); handled {
+		return m, cmd
+	}
+
+	matches := m.presetMatches()
+
+	switch {
+	case key.Matches(msg, PresetPickerKeys.Cancel):
+		m.showingPresetPicker = false
+		m.presetCatalog = nil
+		return m, nil
+
+	case key.Matches(msg, PresetPickerKeys.Up):
+		if m.presetCursor > 0 {
+			m.presetCursor--
+		}
+		return m, nil
+
+	case key.Matches(msg, PresetPickerKeys.Down):
+		if m.presetCursor < len(matches) {
+			m.presetCursor++
+		}
+		return m, nil
+
+	case key.Matches(msg, PresetPickerKeys.Select):
+		m.showingPresetPicker = false
+		m.presetCatalog = nil
+
+		if m.presetCursor == 0 || m.presetCursor > len(matches) {
+			m.initApplicationForm(-1)
+			return m, nil
+		}
+
+		m.initApplicationFormFromPreset(matches[m.presetCursor-1])
+		return m, nil
+	}
+
+	// Anything else edits the search, which starts the list over.
+	var cmd tea.Cmd
+	m.presetSearch, cmd = m.presetSearch.Update(msg)
+	m.presetCursor = min(m.presetCursor, len(m.presetMatches()))
+
+	return m, cmd
+}
+
+// initApplicationFormFromPreset opens the new-application form filled from
+// preset. Every field stays editable; nothing is saved until the form is.
+func (m *Model) initApplicationFormFromPreset(preset presets.Preset) {
+	app := preset.Application
+	m.openApplicationForm(&app, -1)
+
+	m.applicationForm.PresetName = preset.Name
+	m.applicationForm.PresetEntries = app.Entries
+}
+
+// renderPresetPicker renders the preset picker as a centered overlay: the
+// search input, the empty application and the matching presets.
+func (m Model) renderPresetPicker() string {
+	popupWidth := m.overlayWidth()
+
+	// Available content width: popup minus border (2) and padding (2*2)
+	contentWidth := popupWidth - 6
+
+	matches := m.presetMatches()
+
+	var b strings.Builder
+
+	b.WriteString(m.presetSearch.View())
+	b.WriteString("\n\n")
+
+	if m.presetErr != "" {
+		b.WriteString(ErrorStyle.MaxWidth(contentWidth).Render("Presets could not be loaded: " + m.presetErr))
+		b.WriteString("\n\n")
+	}
+
+	rows := make([]string, 0, len(matches)+1)
+	rows = append(rows, "(empty application)")
+
+	for _, p := range matches {
+		row := p.Name
+		if p.Application.Description != "" {
+			row += "  " + MutedTextStyle.Render(p.Application.Description)
+		}
+
+		if p.Source != presets.SourceBuiltin {
+			row += "  " + MutedTextStyle.Render("(repo)")
+		}
+
+		rows = append(rows, row)
+	}
+
+	// Keep the cursor in view.
+	start := max(0, m.presetCursor-presetPickerVisible+1)
+	end := min(len(rows), start+presetPickerVisible)
+
+	for i := start; i < end; i++ {
+		cursor := "  "
+		style := ListItemStyle
+		if i == m.presetCursor {
+			cursor = "> "
+			style = SelectedListItemStyle
+		}
+
+		fmt.Fprintf(&b, "%s%s\n", cursor, style.MaxWidth(contentWidth-2).Render(rows[i]))
+	}
+
+	if len(rows) > presetPickerVisible {
+		b.WriteString(MutedTextStyle.Render(fmt.Sprintf("(%d-%d of %d)", start+1, end, len(rows))))
+		b.WriteString("\n")
+	}
+
+	b.WriteString("\n")
+	b.WriteString(RenderHelpWithWidth(contentWidth,
+		PlainKeys(PresetPickerKeys.Up, PresetPickerKeys.Down), "move",
+		PlainKeys(PresetPickerKeys.Select), PresetPickerKeys.Select.Help().Desc,
+		PlainKeys(PresetPickerKeys.Cancel), PresetPickerKeys.Cancel.Help().Desc,
+	))
+
+	titleStyle := lipgloss.NewStyle().Bold(true).Foreground(primaryColor)
+	title := titleStyle.Render("New application")
+
+	popup := lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(primaryColor).
+		Padding(1, 2).
+		Width(popupWidth).
+		Render(title + "\n\n" + b.String())
+
+	return lipgloss.Place(m.width, m.height, lipgloss.Center, lipgloss.Center, popup)
+}
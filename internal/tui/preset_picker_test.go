@@ -0,0 +1,138 @@
+package tui
+
+import (
+	"strings"
+	"testing"
+
+	tea "charm.land/bubbletea/v2"
+	"github.com/AntoineGS/tidydots/internal/config"
+)
+
+// pressPresetKeys sends keys to the list screen one at a time.
+func pressPresetKeys(t *testing.T, m Model, keys ...tea.KeyPressMsg) Model {
+	t.Helper()
+
+	for _, k := range keys {
+		updated, _ := m.handleKeyPress(k)
+
+		var ok bool
+		if m, ok = updated.(Model); !ok {
+			t.Fatalf("handleKeyPress returned %T, want Model", updated)
+		}
+	}
+
+	return m
+}
+
+// typed returns the key presses of typing s.
+func typed(s string) []tea.KeyPressMsg {
+	keys := make([]tea.KeyPressMsg, 0, len(s))
+	for _, r := range s {
+		keys = append(keys, tea.KeyPressMsg{Code: r, Text: string(r)})
+	}
+
+	return keys
+}
+
+func TestPresetPicker_FillsForm(t *testing.T) {
+	cfg := &config.Config{Version: 3, BackupRoot: t.TempDir()}
+	mp, path := modelOnDisk(t, cfg)
+	m := *mp
+	m.width, m.height = 100, 40
+
+	m = pressPresetKeys(t, m, tea.KeyPressMsg{Code: 'A', Text: "A"})
+
+	if !m.showingPresetPicker {
+		t.Fatal("A did not open the preset picker")
+	}
+
+	// Letters search rather than act on the list: q, a and k would otherwise
+	// quit, add an entry and move the cursor.
+	m = pressPresetKeys(t, m, typed("kitty")...)
+
+	if got := m.presetMatches(); len(got) != 1 || got[0].Name != "kitty" {
+		t.Fatalf("presetMatches() = %v, want only kitty", got)
+	}
+
+	if view := m.renderPresetPicker(); !strings.Contains(view, "kitty") || !strings.Contains(view, "(empty application)") {
+		t.Errorf("picker view:\n%s", view)
+	}
+
+	m = pressPresetKeys(t, m, tea.KeyPressMsg{Code: tea.KeyDown}, tea.KeyPressMsg{Code: tea.KeyEnter})
+
+	if m.showingPresetPicker || m.Screen != ScreenAddForm || m.activeForm != FormApplication {
+		t.Fatalf("showingPresetPicker = %v, Screen = %v; want the application form", m.showingPresetPicker, m.Screen)
+	}
+
+	form := m.applicationForm
+	if form.NameInput.Value() != "kitty" || form.PackageManagers["pacman"] != "kitty" || len(form.PresetEntries) != 1 {
+		t.Fatalf("form = name %q, managers %v, entries %v; want the kitty preset", form.NameInput.Value(), form.PackageManagers, form.PresetEntries)
+	}
+
+	if view := m.viewApplicationForm(); !strings.Contains(view, "~/.config/kitty") {
+		t.Errorf("form does not list the preset's entries:\n%s", view)
+	}
+
+	// The preset only fills the form; what is saved is what the form holds.
+	form.NameInput.SetValue("term")
+
+	if err := m.saveApplicationForm(); err != nil {
+		t.Fatalf("saveApplicationForm() error = %v", err)
+	}
+
+	saved, err := config.Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(saved.Applications) != 1 || saved.Applications[0].Name != "term" || len(saved.Applications[0].Entries) != 1 {
+		t.Fatalf("saved applications = %+v, want term with the preset's entry", saved.Applications)
+	}
+}
+
+func TestPresetPicker_EmptyApplication(t *testing.T) {
+	m := NewModel(&config.Config{Version: 3, BackupRoot: t.TempDir()}, linuxPlatform(), false)
+
+	m = pressPresetKeys(t, m, tea.KeyPressMsg{Code: 'A', Text: "A"}, tea.KeyPressMsg{Code: tea.KeyEnter})
+
+	if m.Screen != ScreenAddForm || m.applicationForm.NameInput.Value() != "" || m.applicationForm.PresetEntries != nil {
+		t.Errorf("Screen = %v, form = %+v; want an empty application form", m.Screen, m.applicationForm)
+	}
+}
+
+func TestPresetPicker_Cancel(t *testing.T) {
+	m := NewModel(&config.Config{Version: 3, BackupRoot: t.TempDir()}, linuxPlatform(), false)
+
+	m = pressPresetKeys(t, m, tea.KeyPressMsg{Code: 'A', Text: "A"}, tea.KeyPressMsg{Code: tea.KeyEsc})
+
+	if m.showingPresetPicker || m.Screen != ScreenResults {
+		t.Errorf("showingPresetPicker = %v, Screen = %v after esc; want the list", m.showingPresetPicker, m.Screen)
+	}
+}
+
+func TestSaveApplicationForm_PresetCollision(t *testing.T) {
+	cfg := &config.Config{Version: 3, BackupRoot: t.TempDir(), Applications: []config.Application{
+		{Name: "mykitty", Entries: []config.SubEntry{
+			{Name: "config", Backup: "./kitty", Targets: map[string]string{"linux": "~/.config/kitty"}},
+		}},
+	}}
+	mp, path := modelOnDisk(t, cfg)
+	m := *mp
+
+	m = pressPresetKeys(t, m, tea.KeyPressMsg{Code: 'A', Text: "A"})
+	m = pressPresetKeys(t, m, typed("kitty")...)
+	m = pressPresetKeys(t, m, tea.KeyPressMsg{Code: tea.KeyDown}, tea.KeyPressMsg{Code: tea.KeyEnter})
+
+	if err := m.saveApplicationForm(); err == nil || !strings.Contains(err.Error(), "collision") {
+		t.Fatalf("saveApplicationForm() error = %v, want a target collision", err)
+	}
+
+	saved, err := config.Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(saved.Applications) != 1 {
+		t.Errorf("saved applications = %d, want the config unchanged", len(saved.Applications))
+	}
+}
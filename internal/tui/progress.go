@@ -54,7 +54,8 @@ func (m *Model) initApplicationItems() {
 
 	for _, app := range apps {
 		// Check if this app matches the when expression
-		isFiltered := !config.EvaluateWhen(app.When, m.Renderer)
+		isFiltered := !m.Config.MatchesWhen(&app, m.Renderer, nil)
+		isDisabled := !app.IsEnabled()
 
 		subItems := make([]SubEntryItem, 0, len(app.Entries))
 
@@ -71,10 +72,16 @@ func (m *Model) initApplicationItems() {
 			}
 
 			subItem := SubEntryItem{
-				SubEntry: subEntry,
-				Target:   expandedTarget,
-				AppName:  app.Name,
-				Index:    len(subItems),
+				SubEntry:   subEntry,
+				Target:     expandedTarget,
+				AppName:    app.Name,
+				Index:      len(subItems),
+				IsDisabled: isDisabled || !subEntry.IsEnabled(),
+			}
+
+			// Disabled entries are never checked, so they start out resolved.
+			if subItem.IsDisabled {
+				subItem.State = StateDisabled
 			}
 
 			subItems = append(subItems, subItem)
@@ -87,9 +94,10 @@ func (m *Model) initApplicationItems() {
 
 		appItem := ApplicationItem{
 			Application: app,
-			Expanded:    false,
+			Expanded:    m.savedExpanded[app.Name],
 			SubItems:    subItems,
 			IsFiltered:  isFiltered,
+			IsDisabled:  isDisabled,
 		}
 
 		// Package method and install check deferred to async
@@ -209,6 +217,16 @@ func (m *Model) getApplicationAtCursorFromTable() (int, int) {
 	return realAppIdx, tableRow.SubIndex
 }
 
+// cursorRowDisabled reports whether the row under the cursor is a disabled
+// application or sub-entry.
+func (m Model) cursorRowDisabled() bool {
+	if m.tableCursor < 0 || m.tableCursor >= len(m.tableRows) {
+		return false
+	}
+
+	return m.tableRows[m.tableCursor].Disabled
+}
+
 func (m Model) viewProgress() string {
 	var b strings.Builder
 
@@ -282,6 +300,11 @@ func (m Model) updateResults(msg tea.KeyPressMsg) (tea.Model, tea.Cmd) {
 		}
 	}
 
+	// Handle preset picker, whose search takes letters as input
+	if m.Operation == OpList && m.showingPresetPicker {
+		return m.updatePresetPicker(msg)
+	}
+
 	// Handle results popup
 	if m.Operation == OpList && m.showingResults {
 		return m.updateResultsPopup(msg)
@@ -377,6 +400,11 @@ func (m Model) updateResults(msg tea.KeyPressMsg) (tea.Model, tea.Cmd) {
 		return m, nil
 	}
 
+	// Handle the letter typed after the jump prefix
+	if m.Operation == OpList && m.jumping {
+		return m.updateJump(msg)
+	}
+
 	// Handle ESC to clear active search or selections (when not in search mode but search text or selections are present)
 	if m.Operation == OpList && key.Matches(msg, FormNavKeys.Cancel) && !m.searching {
 		// Priority 1: Clear search first if active
@@ -441,6 +469,18 @@ func (m Model) updateResults(msg tea.KeyPressMsg) (tea.Model, tea.Cmd) {
 			m.rebuildTable()
 			return m, nil
 		}
+	case key.Matches(msg, ListKeys.SortByPriority):
+		// Sort by priority, highest first: the order restore and backup use
+		if listClean {
+			if m.sortColumn == SortColumnPriority {
+				m.sortAscending = !m.sortAscending
+			} else {
+				m.sortColumn = SortColumnPriority
+				m.sortAscending = true
+			}
+			m.rebuildTable()
+			return m, nil
+		}
 	case key.Matches(msg, ListKeys.Filter):
 		// Toggle filter
 		if listClean {
@@ -492,6 +532,21 @@ func (m Model) updateResults(msg tea.KeyPressMsg) (tea.Model, tea.Cmd) {
 		}
 
 		return m, nil
+	case key.Matches(msg, ListKeys.Top):
+		if listClean {
+			m.jumpToRow(0)
+			return m, nil
+		}
+	case key.Matches(msg, ListKeys.Bottom):
+		if listClean {
+			m.jumpToRow(len(m.tableRows) - 1)
+			return m, nil
+		}
+	case key.Matches(msg, ListKeys.Jump):
+		if listClean {
+			m.jumping = true
+			return m, nil
+		}
 	case key.Matches(msg, ListKeys.Collapse):
 		if m.Operation == OpList {
 			// Collapse node if expanded
@@ -509,16 +564,21 @@ func (m Model) updateResults(msg tea.KeyPressMsg) (tea.Model, tea.Cmd) {
 		return m, tea.Quit
 	case key.Matches(msg, ListKeys.Expand):
 		if m.Operation == OpList {
-			// If showing detail, close it; otherwise expand (not toggle)
+			// If showing detail, close it. Otherwise expand a collapsed
+			// application, or open the detail panel for an expanded one or
+			// a sub-entry.
 			if m.showingDetail {
 				m.showingDetail = false
 			} else {
 				appIdx, subIdx := m.getApplicationAtCursorFromTable()
-				if appIdx >= 0 && subIdx < 0 {
-					// Only expand application rows, not sub-entries
+				switch {
+				case appIdx < 0:
+				case subIdx < 0 && !m.Applications[appIdx].Expanded:
 					m.Applications[appIdx].Expanded = true
 					// Rebuild table to show expanded children
 					m.rebuildTable()
+				default:
+					m.openDetail()
 				}
 			}
 
@@ -526,6 +586,18 @@ func (m Model) updateResults(msg tea.KeyPressMsg) (tea.Model, tea.Cmd) {
 		}
 
 		return m, tea.Quit
+	case key.Matches(msg, ListKeys.ExpandAll):
+		if m.Operation == OpList {
+			m.setAllExpanded(true)
+		}
+
+		return m, nil
+	case key.Matches(msg, ListKeys.CollapseAll):
+		if m.Operation == OpList {
+			m.setAllExpanded(false)
+		}
+
+		return m, nil
 	case key.Matches(msg, ListKeys.Edit):
 		// Edit selected Application or SubEntry (only in List view)
 		if m.Operation == OpList {
@@ -543,9 +615,9 @@ func (m Model) updateResults(msg tea.KeyPressMsg) (tea.Model, tea.Cmd) {
 			}
 		}
 	case key.Matches(msg, ListKeys.AddApp):
-		// Add new Application (only in List view)
+		// Add new Application (only in List view), starting from a preset
 		if m.Operation == OpList {
-			m.initApplicationForm(-1)
+			m.openPresetPicker()
 			return m, nil
 		}
 	case key.Matches(msg, ListKeys.AddEntry):
@@ -585,7 +657,13 @@ func (m Model) updateResults(msg tea.KeyPressMsg) (tea.Model, tea.Cmd) {
 		if m.Operation == OpList {
 			// Check if multi-select mode is active
 			if m.multiSelectActive {
-				// Show summary screen for batch install
+				// Show summary screen for batch install. The package
+				// manager is created once here rather than on every
+				// render of the summary.
+				if m.pkgManager == nil {
+					m.pkgManager = m.packageManager()
+				}
+
 				m.summaryOperation = OpInstallPackages
 				m.Screen = ScreenSummary
 				return m, nil
@@ -619,7 +697,7 @@ func (m Model) updateResults(msg tea.KeyPressMsg) (tea.Model, tea.Cmd) {
 			// On an app row: install package (original behavior)
 			if appIdx >= 0 && subIdx < 0 {
 				app := m.Applications[appIdx]
-				if app.PkgInstalled != nil && !*app.PkgInstalled {
+				if !app.IsDisabled && app.PkgInstalled != nil && !*app.PkgInstalled {
 					m.Operation = OpInstallPackages
 					m.currentPackageIndex = 0
 					m.results = nil
@@ -636,6 +714,12 @@ func (m Model) updateResults(msg tea.KeyPressMsg) (tea.Model, tea.Cmd) {
 		}
 
 		return m, nil
+	case key.Matches(msg, ListKeys.Verify):
+		// Verify and repair the links of the selected rows, or the row under the cursor
+		if m.Operation == OpList {
+			return m.verifyLinks(), nil
+		}
+
 	case key.Matches(msg, ListKeys.Restore):
 		// Restore selected SubEntry (only in List view for SubEntry rows)
 		if m.Operation == OpList {
@@ -644,68 +728,11 @@ func (m Model) updateResults(msg tea.KeyPressMsg) (tea.Model, tea.Cmd) {
 				// Show summary screen for batch restore
 				m.summaryOperation = OpRestore
 				m.Screen = ScreenSummary
+				m.loadInterruptedRestore()
 				return m, nil
 			}
 
-			// Single-item restore (original behavior)
-			appIdx, subIdx := m.getApplicationAtCursorFromTable()
-			if appIdx >= 0 && subIdx >= 0 {
-				// Restore single sub-entry
-				subItem := &m.Applications[appIdx].SubItems[subIdx]
-
-				// A setup entry runs a command that may prompt for a sudo
-				// password, so it is dispatched through tea.Exec (which hands
-				// the terminal over) instead of running inline here.
-				if subItem.SubEntry.IsSetup() {
-					m.results = nil
-					return m, m.startSetupRun([]setupRunItem{{
-						appIdx: appIdx,
-						subIdx: subIdx,
-						name:   subItem.SubEntry.Name,
-						sub:    *subItem,
-					}}, false)
-				}
-
-				success, message := m.performRestoreSubEntry(*subItem)
-				if success {
-					m.Applications[appIdx].SubItems[subIdx].State = m.detectSubEntryState(subItem)
-					m.rebuildTable()
-				}
-				m.results = []ResultItem{{
-					Name:    subItem.SubEntry.Name,
-					Success: success,
-					Message: message,
-				}}
-				m.showingResults = true
-				m.resultsScrollOffset = 0
-			} else if appIdx >= 0 && subIdx < 0 {
-				// Restore all sub-entries for this application: config entries
-				// inline, setup entries queued for the tea.Exec runner.
-				m.results = nil
-				for i := range m.Applications[appIdx].SubItems {
-					subItem := &m.Applications[appIdx].SubItems[i]
-					if subItem.SubEntry.IsSetup() || !subItem.SubEntry.IsConfig() {
-						continue
-					}
-					success, message := m.performRestoreSubEntry(*subItem)
-					if success {
-						m.Applications[appIdx].SubItems[i].State = m.detectSubEntryState(subItem)
-					}
-					m.results = append(m.results, ResultItem{
-						Name:    subItem.SubEntry.Name,
-						Success: success,
-						Message: message,
-					})
-				}
-				m.rebuildTable()
-
-				if setups := m.collectAppSetupItems(appIdx); len(setups) > 0 {
-					return m, m.startSetupRun(setups, false)
-				}
-
-				m.showingResults = true
-				m.resultsScrollOffset = 0
-			}
+			return m.restoreAtCursor()
 		}
 
 		return m, nil
@@ -724,6 +751,36 @@ func (m Model) updateResults(msg tea.KeyPressMsg) (tea.Model, tea.Cmd) {
 				// Move to next row
 				m.moveToNextExpandedNode()
 			}
+			return m, nil
+		}
+	case key.Matches(msg, ListKeys.ToggleEnabled):
+		// Enable or disable the Application or SubEntry under the cursor
+		if listClean {
+			appIdx, subIdx := m.getApplicationAtCursorFromTable()
+			if appIdx < 0 {
+				return m, nil
+			}
+
+			app := m.Applications[appIdx]
+			configAppIdx := m.findConfigApplicationIndex(app.Application.Name)
+			configSubIdx := -1
+			if configAppIdx >= 0 && subIdx >= 0 {
+				configSubIdx = m.findConfigSubEntryIndex(configAppIdx, app.SubItems[subIdx].SubEntry.Name)
+				if configSubIdx < 0 {
+					return m, nil
+				}
+			}
+
+			if configAppIdx >= 0 {
+				if err := m.toggleEnabled(configAppIdx, configSubIdx); err != nil {
+					m.err = err
+					return m, nil
+				}
+
+				// A re-enabled item has not been checked yet
+				return m, tea.Batch(m.dispatchUncheckedPackageStates(), m.dispatchLoadingSubEntryStates())
+			}
+
 			return m, nil
 		}
 	case key.Matches(msg, ListKeys.ShowResults):
@@ -847,6 +904,10 @@ func (m Model) renderHelpForCurrentState() string {
 	appIdx, subIdx := m.getApplicationAtCursorFromTable()
 
 	switch {
+	case m.jumping:
+		return MutedTextStyle.Render("Jump to the next entry starting with: type a letter") + "  " +
+			RenderHelpFromBindings(m.width, FormNavKeys.Cancel)
+
 	case m.confirmingDeleteApp || m.confirmingDeleteSubEntry:
 		// Delete confirmation prompt
 		var name string
@@ -905,6 +966,7 @@ func (m Model) renderHelpForCurrentState() string {
 				MultiSelectKeys.Toggle,
 				MultiSelectKeys.Clear,
 				MultiSelectKeys.Restore,
+				ListKeys.Verify,
 				MultiSelectKeys.Install,
 				MultiSelectKeys.Delete,
 				SharedKeys.Quit,
@@ -919,6 +981,14 @@ func (m Model) renderHelpForCurrentState() string {
 			ListKeys.Edit,
 			ListKeys.Delete,
 			ListKeys.Restore,
+			ListKeys.Verify,
+		}
+
+		// The toggle enables a disabled row and disables any other
+		if m.cursorRowDisabled() {
+			bindings = append(bindings, WithDesc(ListKeys.ToggleEnabled, "enable"))
+		} else {
+			bindings = append(bindings, ListKeys.ToggleEnabled)
 		}
 
 		// Show context-sensitive "i" help
@@ -929,8 +999,7 @@ func (m Model) renderHelpForCurrentState() string {
 			subIdx < len(m.Applications[appIdx].SubItems) &&
 			m.Applications[appIdx].SubItems[subIdx].State == StateModified {
 			// Modified sub-entry: diff
-			diffBinding := key.NewBinding(key.WithKeys("i"), key.WithHelp("i", "diff"))
-			bindings = append(bindings, diffBinding)
+			bindings = append(bindings, WithDesc(ListKeys.Install, "diff"))
 		}
 
 		// Show "m" for messages if results exist
@@ -938,7 +1007,7 @@ func (m Model) renderHelpForCurrentState() string {
 			bindings = append(bindings, ListKeys.ShowResults)
 		}
 
-		bindings = append(bindings, SharedKeys.Quit)
+		bindings = append(bindings, SharedKeys.Help, SharedKeys.Quit)
 		return RenderHelpFromBindings(m.width, bindings...)
 	}
 }
@@ -990,8 +1059,8 @@ func (m Model) viewListTable() string {
 
 	// Calculate available height using shared method (keeps Update and View in sync).
 	// No minimum override — trust computeMaxVisibleRows() which clamps to 3 data rows.
-	// TODO: computeMaxVisibleRows() also renders help/detail/diff to measure heights,
-	// causing double computation. Acceptable while detail stubs return "".
+	// computeMaxVisibleRows() also renders help/detail/diff to measure heights,
+	// causing double computation. The panels are a few lines, so this is cheap.
 	maxVisibleRows := m.computeMaxVisibleRows()
 	availableForTable := maxVisibleRows + 4 // Add back table border lines
 
@@ -1109,6 +1178,26 @@ func (m Model) installNextPackage() tea.Cmd {
 
 	pkg := m.pendingPackages[m.currentPackageIndex]
 
+	if m.NoSudo && m.installRequiresSudo(pkg) {
+		return func() tea.Msg {
+			return PackageInstallMsg{
+				Package: pkg,
+				Success: true,
+				Message: packages.MsgRequiresSudo,
+			}
+		}
+	}
+
+	if m.Offline {
+		return func() tea.Msg {
+			return PackageInstallMsg{
+				Package: pkg,
+				Success: true,
+				Message: packages.MsgOffline,
+			}
+		}
+	}
+
 	// Handle dry run
 	if m.DryRun {
 		return func() tea.Msg {
@@ -1159,5 +1248,106 @@ func (m Model) buildInstallCommand(pkg PackageItem) *exec.Cmd {
 		return nil
 	}
 
-	return packages.BuildCommand(context.Background(), *converted, pkg.Method, m.Platform.OS)
+	pm := *m.packageManager()
+	pm.NoSudo = m.NoSudo
+	pm.SkipVerify = m.SkipVerify
+
+	return pm.BuildCommand(context.Background(), *converted, pkg.Method, m.Renderer)
+}
+
+// installRequiresSudo reports whether pkg cannot be installed without sudo.
+func (m Model) installRequiresSudo(pkg PackageItem) bool {
+	converted := packages.FromPackageSpec(pkg.Name, pkg.Package)
+
+	return converted != nil && packages.GitRequiresSudo(*converted, pkg.Method)
+}
+
+// restoreAtCursor restores the sub-entry under the cursor, or every enabled
+// sub-entry of the application under it. Entries whose targets conflict with
+// their backup are asked about first; the restore runs again once they are
+// decided.
+func (m Model) restoreAtCursor() (tea.Model, tea.Cmd) {
+	appIdx, subIdx := m.getApplicationAtCursorFromTable()
+	if appIdx >= 0 && subIdx >= 0 {
+		// Restore single sub-entry
+		subItem := &m.Applications[appIdx].SubItems[subIdx]
+		if subItem.IsDisabled {
+			return m, nil
+		}
+
+		// A setup entry runs a command that may prompt for a sudo
+		// password, so it is dispatched through tea.Exec (which hands
+		// the terminal over) instead of running inline here.
+		if subItem.SubEntry.IsSetup() {
+			m.results = nil
+			return m, m.startSetupRun([]setupRunItem{{
+				appIdx: appIdx,
+				subIdx: subIdx,
+				name:   subItem.SubEntry.Name,
+				sub:    *subItem,
+			}}, false)
+		}
+
+		if m.resolutions == nil {
+			if conflicts := m.restoreConflicts([]SubEntryItem{*subItem}); len(conflicts) > 0 {
+				m.startConflicts(conflicts, false)
+				return m, nil
+			}
+		}
+
+		success, message := m.performRestoreSubEntry(*subItem)
+		m.resolutions = nil
+		if success {
+			m.Applications[appIdx].SubItems[subIdx].State = m.detectSubEntryState(subItem)
+			m.rebuildTable()
+		}
+		m.results = []ResultItem{{
+			Name:    subItem.SubEntry.Name,
+			Success: success,
+			Message: message,
+		}}
+		m.showingResults = true
+		m.resultsScrollOffset = 0
+	} else if appIdx >= 0 && subIdx < 0 {
+		if m.Applications[appIdx].IsDisabled {
+			return m, nil
+		}
+
+		if m.resolutions == nil {
+			if conflicts := m.restoreConflicts(m.Applications[appIdx].SubItems); len(conflicts) > 0 {
+				m.startConflicts(conflicts, false)
+				return m, nil
+			}
+		}
+
+		// Restore all enabled sub-entries for this application: config
+		// entries inline, setup entries queued for the tea.Exec runner.
+		m.results = nil
+		for i := range m.Applications[appIdx].SubItems {
+			subItem := &m.Applications[appIdx].SubItems[i]
+			if subItem.IsDisabled || subItem.SubEntry.IsSetup() || !subItem.SubEntry.IsConfig() {
+				continue
+			}
+			success, message := m.performRestoreSubEntry(*subItem)
+			if success {
+				m.Applications[appIdx].SubItems[i].State = m.detectSubEntryState(subItem)
+			}
+			m.results = append(m.results, ResultItem{
+				Name:    subItem.SubEntry.Name,
+				Success: success,
+				Message: message,
+			})
+		}
+		m.resolutions = nil
+		m.rebuildTable()
+
+		if setups := m.collectAppSetupItems(appIdx); len(setups) > 0 {
+			return m, m.startSetupRun(setups, false)
+		}
+
+		m.showingResults = true
+		m.resultsScrollOffset = 0
+	}
+
+	return m, nil
 }
@@ -54,9 +54,9 @@ func (m Model) resultsMaxScrollOffset() int {
 	return maxOffset
 }
 
-// renderResultsPopup renders the results popup as a centered overlay.
-func (m Model) renderResultsPopup() string {
-	// Popup width: 65% of terminal width, min 40, max width-4
+// overlayWidth returns the width of a centered popup such as the results
+// popup: 65% of the terminal width, min 40, max width-4.
+func (m Model) overlayWidth() int {
 	popupWidth := int(float64(m.width) * 0.65)
 	if popupWidth < 40 {
 		popupWidth = 40
@@ -64,6 +64,12 @@ func (m Model) renderResultsPopup() string {
 	if popupWidth > m.width-4 {
 		popupWidth = m.width - 4
 	}
+	return popupWidth
+}
+
+// renderResultsPopup renders the results popup as a centered overlay.
+func (m Model) renderResultsPopup() string {
+	popupWidth := m.overlayWidth()
 
 	contentHeight := m.resultsPopupContentHeight()
 	offset := m.resultsScrollOffset
@@ -101,15 +107,15 @@ func (m Model) renderResultsPopup() string {
 	}
 
 	// Help line at bottom.
-	// Use ASCII-only keys ("k"/"j"/"enter/esc") and pass the inner content width
+	// Use PlainKeys ("k"/"j"/"enter/esc") and pass the inner content width
 	// so the help text wraps within the popup's padding. Avoid ambiguous-width
 	// glyphs (↑, ↓) that some terminals render as 2 cells, which would push the
 	// right border out of alignment on the help row.
 	b.WriteString("\n")
 	b.WriteString(RenderHelpWithWidth(contentWidth,
-		"k", "scroll up",
-		"j", "scroll down",
-		"enter/esc", "close",
+		PlainKeys(ResultsPopupKeys.Up), ResultsPopupKeys.Up.Help().Desc,
+		PlainKeys(ResultsPopupKeys.Down), ResultsPopupKeys.Down.Help().Desc,
+		PlainKeys(ResultsPopupKeys.Close), ResultsPopupKeys.Close.Help().Desc,
 	))
 
 	// Box with rounded border, primaryColor border, padding 1,2
@@ -0,0 +1,70 @@
+package tui
+
+import (
+	"fmt"
+
+	"github.com/AntoineGS/tidydots/internal/manager"
+	"github.com/AntoineGS/tidydots/internal/state"
+)
+
+// loadInterruptedRestore finds the selected config entries that the last
+// restore, from the TUI or the CLI, completed before it was interrupted or
+// failed, so the summary can offer to skip them. Skipping starts off.
+func (m *Model) loadInterruptedRestore() {
+	m.interruptedRestore, m.resumeRestore = nil, false
+
+	if m.Manager == nil {
+		return
+	}
+
+	keys, err := m.Manager.InterruptedEntries(state.OpRestore)
+	if err != nil || len(keys) == 0 {
+		return
+	}
+
+	done := make(map[manager.HistoryKey]bool, len(keys))
+	for _, k := range keys {
+		done[k] = true
+	}
+
+	for _, item := range m.collectBatchRestoreItems() {
+		app := m.Applications[item.appIdx]
+		sub := app.SubItems[item.subIdx].SubEntry
+
+		if !sub.IsConfig() || !done[manager.HistoryKey{App: app.Application.Name, Entry: sub.Name}] {
+			continue
+		}
+
+		if m.interruptedRestore == nil {
+			m.interruptedRestore = make(map[subEntryKey]bool)
+		}
+
+		m.interruptedRestore[subEntryKey{app: app.Application.Name, sub: sub.Name}] = true
+	}
+}
+
+// renderResumeChoice renders the offer to skip the entries of
+// interruptedRestore, or "" when there are none.
+func (m Model) renderResumeChoice() string {
+	if len(m.interruptedRestore) == 0 {
+		return ""
+	}
+
+	check := "[ ]"
+	if m.resumeRestore {
+		check = "[x]"
+	}
+
+	return WarningStyle.Render(fmt.Sprintf("The last restore did not finish; it restored %d of these entries.", len(m.interruptedRestore))) +
+		"\n" + fmt.Sprintf("%s Skip them where their target is still in place (%s)", check, PlainKeys(SummaryKeys.Resume))
+}
+
+// batchRestoreManager returns the manager a batch restore runs with: one
+// journaling the entries it restores, and with resumeRestore skipping those
+// the interrupted restore did.
+func (m Model) batchRestoreManager() *manager.Manager {
+	mgr := *m.Manager
+	mgr.Resume = m.resumeRestore
+
+	return mgr.BeginJournal(state.OpRestore)
+}
@@ -0,0 +1,111 @@
+package tui
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	tea "charm.land/bubbletea/v2"
+	"github.com/AntoineGS/tidydots/internal/config"
+	"github.com/AntoineGS/tidydots/internal/manager"
+	"github.com/AntoineGS/tidydots/internal/platform"
+	"github.com/AntoineGS/tidydots/internal/state"
+)
+
+// runBatchRestore runs the config half of a batch restore of m and returns
+// its results by entry name.
+func runBatchRestore(t *testing.T, m Model) map[string]string {
+	t.Helper()
+
+	msg, ok := m.executeBatchRestore()().(batchRestoreConfigsDoneMsg)
+	if !ok {
+		t.Fatal("executeBatchRestore did not return batchRestoreConfigsDoneMsg")
+	}
+
+	results := make(map[string]string, len(msg.results))
+	for _, r := range msg.results {
+		results[r.Name] = r.Message
+	}
+
+	return results
+}
+
+func TestBatchRestore_ResumesInterruptedRestore(t *testing.T) {
+	if runtime.GOOS == platform.OSWindows {
+		t.Skip("symlink tests are skipped on Windows")
+	}
+
+	root, home := t.TempDir(), t.TempDir()
+
+	// b has no backup yet, so the first restore fails on it.
+	if err := os.MkdirAll(filepath.Join(root, "a"), 0o750); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := os.WriteFile(filepath.Join(root, "a", "rc"), []byte("a"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	entry := func(name string) config.SubEntry {
+		return config.SubEntry{Name: name, Backup: "./" + name, Files: []string{"rc"}, Targets: map[string]string{"linux": filepath.Join(home, name)}}
+	}
+
+	cfg := &config.Config{
+		Version:      3,
+		BackupRoot:   root,
+		Applications: []config.Application{{Name: "shell", Entries: []config.SubEntry{entry("a"), entry("b")}}},
+	}
+
+	mgr := manager.New(cfg, linuxPlatform())
+	if err := mgr.InitStateStore(); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = mgr.Close() })
+
+	m := NewModelWithManager(cfg, linuxPlatform(), mgr, "")
+	m.width, m.height = 100, 40
+
+	m.selectedApps = map[string]bool{"shell": true}
+	m.multiSelectActive = true
+
+	if results := runBatchRestore(t, m); !strings.HasPrefix(results["shell/b"], "Failed") {
+		t.Fatalf("first restore = %v, want b to fail", results)
+	}
+
+	if err := os.MkdirAll(filepath.Join(root, "b"), 0o750); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := os.WriteFile(filepath.Join(root, "b", "rc"), []byte("b"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	m.summaryOperation = OpRestore
+	m.loadInterruptedRestore()
+
+	if !m.interruptedRestore[subEntryKey{app: "shell", sub: "a"}] || len(m.interruptedRestore) != 1 {
+		t.Fatalf("interruptedRestore = %v, want shell/a", m.interruptedRestore)
+	}
+
+	if view := m.viewSummary(); !strings.Contains(view, "restored 1 of these entries") {
+		t.Errorf("summary does not offer to resume:\n%s", view)
+	}
+
+	updated, _ := m.updateSummary(tea.KeyPressMsg{Code: 's', Text: "s"})
+	m = updated.(Model)
+
+	if !m.resumeRestore {
+		t.Fatal("s did not choose to skip the restored entries")
+	}
+
+	results := runBatchRestore(t, m)
+	if !strings.HasPrefix(results["shell/a"], "Skipped") || !strings.HasPrefix(results["shell/b"], "Restored") {
+		t.Errorf("resumed restore = %v, want a skipped and b restored", results)
+	}
+
+	if keys, _ := m.Manager.InterruptedEntries(state.OpRestore); len(keys) != 0 {
+		t.Errorf("journal after a full restore = %v, want it cleared", keys)
+	}
+}
@@ -16,20 +16,27 @@ type subEntryKey struct {
 }
 
 // toggleAppSelection toggles the selection state of an entire application and all its sub-entries.
-// When selecting an app, all its sub-entries are selected. When deselecting, all are deselected.
+// When selecting an app, all its enabled sub-entries are selected. When deselecting, all are deselected.
+// A disabled application cannot be selected.
 func (m *Model) toggleAppSelection(appIdx int) {
 	if appIdx < 0 || appIdx >= len(m.Applications) {
 		return
 	}
 
 	app := m.Applications[appIdx]
+	if app.IsDisabled {
+		return
+	}
+
 	name := app.Application.Name
 
 	newState := !m.selectedApps[name]
 	m.selectedApps[name] = newState
 
 	for _, sub := range app.SubItems {
-		m.selectedSubEntries[subEntryKey{app: name, sub: sub.SubEntry.Name}] = newState
+		if !sub.IsDisabled {
+			m.selectedSubEntries[subEntryKey{app: name, sub: sub.SubEntry.Name}] = newState
+		}
 	}
 
 	// Clean up maps if deselecting
@@ -44,6 +51,7 @@ func (m *Model) toggleAppSelection(appIdx int) {
 }
 
 // toggleSubEntrySelection toggles the selection state of a single sub-entry within an application.
+// A disabled sub-entry cannot be selected.
 func (m *Model) toggleSubEntrySelection(appIdx, subIdx int) {
 	if appIdx < 0 || appIdx >= len(m.Applications) {
 		return
@@ -51,6 +59,9 @@ func (m *Model) toggleSubEntrySelection(appIdx, subIdx int) {
 	if subIdx < 0 || subIdx >= len(m.Applications[appIdx].SubItems) {
 		return
 	}
+	if m.Applications[appIdx].SubItems[subIdx].IsDisabled {
+		return
+	}
 
 	key := subEntryKey{
 		app: m.Applications[appIdx].Application.Name,
@@ -155,19 +166,25 @@ func (m *Model) clearHiddenSelections() {
 }
 
 // pruneStaleSelections drops selection keys that no longer name a live
-// application or sub-entry (the item was renamed or deleted since it was
-// selected). Stale name keys cannot retarget another item the way stale
+// application or sub-entry (the item was renamed, deleted or disabled since it
+// was selected). Stale name keys cannot retarget another item the way stale
 // indices could, but they would inflate the multi-select banner counts.
 func (m *Model) pruneStaleSelections() {
 	liveApps := make(map[string]bool, len(m.Applications))
 	liveSubs := make(map[subEntryKey]bool)
 
 	for _, app := range m.Applications {
+		if app.IsDisabled {
+			continue
+		}
+
 		name := app.Application.Name
 		liveApps[name] = true
 
 		for _, sub := range app.SubItems {
-			liveSubs[subEntryKey{app: name, sub: sub.SubEntry.Name}] = true
+			if !sub.IsDisabled {
+				liveSubs[subEntryKey{app: name, sub: sub.SubEntry.Name}] = true
+			}
 		}
 	}
 
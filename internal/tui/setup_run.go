@@ -13,6 +13,8 @@ const (
 	setupResultOK       = "Setup complete: check passes"
 	setupResultDryRun   = "[DRY RUN] check ran; setup command not executed"
 	setupResultNoRunner = "Failed: no manager available to run the setup command"
+	setupResultNoSudo   = "Skipped: requires sudo"
+	setupResultOffline  = "Skipped: offline mode"
 )
 
 // setupRunItem is a setup sub-entry queued to run, together with where its row
@@ -88,8 +90,8 @@ func (s *setupExec) Run() error {
 }
 
 // runSetupForItem executes one setup sub-entry through the manager, which owns
-// the check → run → re-check state machine (including the dry-run and sudo
-// rules).
+// the check → run → re-check state machine (including the dry-run, sudo and
+// offline rules).
 //
 // This shells out. Callers must be on a goroutine that does not hold the
 // terminal: go through startSetupRun/runNextSetup, which wrap this in tea.Exec.
@@ -98,6 +100,14 @@ func (m Model) runSetupForItem(item SubEntryItem) (bool, string) {
 		return false, setupResultNoRunner
 	}
 
+	if m.Manager.SkipsSudo(item.SubEntry) {
+		return true, setupResultNoSudo
+	}
+
+	if m.Manager.Offline {
+		return true, setupResultOffline
+	}
+
 	if err := m.Manager.RunSetup(item.AppName, item.SubEntry); err != nil {
 		return false, fmt.Sprintf("Failed: %v", err)
 	}
@@ -232,7 +242,7 @@ func (m Model) collectAppSetupItems(appIdx int) []setupRunItem {
 	var items []setupRunItem
 
 	for subIdx, sub := range m.Applications[appIdx].SubItems {
-		if !sub.SubEntry.IsSetup() {
+		if !sub.SubEntry.IsSetup() || sub.IsDisabled {
 			continue
 		}
 
@@ -1,8 +1,6 @@
 package tui
 
 import (
-	"path/filepath"
-
 	tea "charm.land/bubbletea/v2"
 	"github.com/AntoineGS/tidydots/internal/config"
 	"github.com/AntoineGS/tidydots/internal/manager"
@@ -12,8 +10,8 @@ import (
 // detectSetupPathState reports the state of a setup sub-entry by running its
 // check command. A nil manager means the check cannot be run, so the entry is
 // reported as satisfied rather than falsely flagged.
-func detectSetupPathState(sub config.SubEntry, mgr *manager.Manager) PathState {
-	if mgr == nil || mgr.IsSetupApplied(sub) {
+func detectSetupPathState(appName string, sub config.SubEntry, mgr *manager.Manager) PathState {
+	if mgr == nil || mgr.IsSetupApplied(appName, sub) {
 		return StateSetupOk
 	}
 	return StateSetupNeeded
@@ -31,6 +29,10 @@ func detectSetupPathState(sub config.SubEntry, mgr *manager.Manager) PathState {
 // by detectSubEntryStateStatic (the goroutine-safe variant used by the async
 // detection pipeline).
 func (m *Model) detectSubEntryState(item *SubEntryItem) PathState {
+	if item.IsDisabled {
+		return StateDisabled
+	}
+
 	if item.SubEntry.IsSetup() {
 		return StateLoading
 	}
@@ -38,7 +40,7 @@ func (m *Model) detectSubEntryState(item *SubEntryItem) PathState {
 	targetPath := config.ExpandPath(item.Target, m.Platform.EnvVars)
 	backupPath := m.resolvePath(item.SubEntry.Backup)
 
-	st := detectConfigState(backupPath, targetPath, item.SubEntry.IsFolder(), item.SubEntry.Files, item.SubEntry.IsCopy())
+	st := detectConfigState(item.SubEntry, backupPath, targetPath)
 
 	if st == StateLinked && item.SubEntry.IsConfig() && item.SubEntry.IsFolder() && m.Manager != nil {
 		if m.Manager.HasOutdatedTemplates(backupPath) {
@@ -49,7 +51,7 @@ func (m *Model) detectSubEntryState(item *SubEntryItem) PathState {
 		}
 	}
 
-	return st
+	return m.withDirtyState(item, st)
 }
 
 // countInitialStateChecks counts how many async state checks Init() will dispatch.
@@ -58,13 +60,17 @@ func (m *Model) detectSubEntryState(item *SubEntryItem) PathState {
 func (m Model) countInitialStateChecks() int {
 	count := 0
 	for _, app := range m.Applications {
-		if app.IsFiltered {
+		if app.IsFiltered || app.IsDisabled {
 			continue
 		}
 		if app.Application.HasPackage() {
 			count++
 		}
-		count += len(app.SubItems)
+		for _, sub := range app.SubItems {
+			if !sub.IsDisabled {
+				count++
+			}
+		}
 	}
 	return count
 }
@@ -77,7 +83,7 @@ func (m Model) checkPackageStatesCmd() (tea.Cmd, int) {
 	osType := m.Platform.OS
 
 	for i, app := range m.Applications {
-		if app.IsFiltered || !app.Application.HasPackage() {
+		if app.IsFiltered || app.IsDisabled || !app.Application.HasPackage() {
 			continue
 		}
 		appIndex := i
@@ -107,10 +113,13 @@ func (m Model) checkSubEntryStatesCmd() (tea.Cmd, int) {
 	mgr := m.Manager
 
 	for i, app := range m.Applications {
-		if app.IsFiltered {
+		if app.IsFiltered || app.IsDisabled {
 			continue
 		}
 		for j, sub := range app.SubItems {
+			if sub.IsDisabled {
+				continue
+			}
 			appIndex := i
 			subIndex := j
 			subItem := sub
@@ -132,7 +141,7 @@ func (m Model) checkUncheckedPackageStatesCmd() (tea.Cmd, int) {
 	osType := m.Platform.OS
 
 	for i, app := range m.Applications {
-		if !app.Application.HasPackage() || app.PkgInstalled != nil {
+		if app.IsDisabled || !app.Application.HasPackage() || app.PkgInstalled != nil {
 			continue
 		}
 		appIndex := i
@@ -162,7 +171,7 @@ func (m Model) checkFilteredStatesCmd() (tea.Cmd, int) {
 	mgr := m.Manager
 
 	for i, app := range m.Applications {
-		if !app.IsFiltered {
+		if !app.IsFiltered || app.IsDisabled {
 			continue
 		}
 
@@ -245,14 +254,18 @@ func (m Model) checkLoadingSubEntryStatesCmd() (tea.Cmd, int) {
 // detectSubEntryStateStatic determines the state of a sub-entry item without using Model receiver.
 // This is safe to call from goroutines since it takes explicit dependencies.
 func detectSubEntryStateStatic(item SubEntryItem, plat *platform.Platform, cfg *config.Config, mgr *manager.Manager) PathState {
+	if item.IsDisabled {
+		return StateDisabled
+	}
+
 	if item.SubEntry.IsSetup() {
-		return detectSetupPathState(item.SubEntry, mgr)
+		return detectSetupPathState(item.AppName, item.SubEntry, mgr)
 	}
 
 	targetPath := config.ExpandPath(item.Target, plat.EnvVars)
 	backupPath := resolvePathStatic(item.SubEntry.Backup, cfg, plat.EnvVars)
 
-	st := detectConfigState(backupPath, targetPath, item.SubEntry.IsFolder(), item.SubEntry.Files, item.SubEntry.IsCopy())
+	st := detectConfigState(item.SubEntry, backupPath, targetPath)
 
 	if st == StateLinked && item.SubEntry.IsConfig() && item.SubEntry.IsFolder() && mgr != nil {
 		if mgr.HasOutdatedTemplates(backupPath) {
@@ -268,12 +281,5 @@ func detectSubEntryStateStatic(item SubEntryItem, plat *platform.Platform, cfg *
 
 // resolvePathStatic resolves relative paths against BackupRoot and expands ~ without using Model receiver.
 func resolvePathStatic(path string, cfg *config.Config, envVars map[string]string) string {
-	expandedPath := config.ExpandPath(path, envVars)
-
-	if filepath.IsAbs(expandedPath) {
-		return expandedPath
-	}
-
-	expandedBackupRoot := config.ExpandPath(cfg.BackupRoot, envVars)
-	return filepath.Join(expandedBackupRoot, expandedPath)
+	return config.ResolveBackupPath(path, cfg.BackupRoot, envVars, nil)
 }
@@ -54,7 +54,7 @@ func newStubManager(stub *cmdexec.StubRunner) *manager.Manager {
 }
 
 func TestDetectSetupPathState_NilManager_ReturnsSetupOk(t *testing.T) {
-	got := detectSetupPathState(setupSubEntry(), nil)
+	got := detectSetupPathState("app", setupSubEntry(), nil)
 	if got != StateSetupOk {
 		t.Errorf("detectSetupPathState with nil manager = %v, want StateSetupOk (a nil manager cannot run the check, so it must not falsely flag the entry)", got)
 	}
@@ -64,7 +64,7 @@ func TestDetectSetupPathState_CheckPasses_ReturnsSetupOk(t *testing.T) {
 	stub := cmdexec.NewStubRunner()
 	stub.AddResult("sh", cmdexec.Result{ExitCode: 0})
 
-	got := detectSetupPathState(setupSubEntry(), newStubManager(stub))
+	got := detectSetupPathState("app", setupSubEntry(), newStubManager(stub))
 	if got != StateSetupOk {
 		t.Errorf("detectSetupPathState with passing check = %v, want StateSetupOk", got)
 	}
@@ -74,7 +74,7 @@ func TestDetectSetupPathState_CheckFails_ReturnsSetupNeeded(t *testing.T) {
 	stub := cmdexec.NewStubRunner()
 	stub.AddResult("sh", cmdexec.Result{ExitCode: 1})
 
-	got := detectSetupPathState(setupSubEntry(), newStubManager(stub))
+	got := detectSetupPathState("app", setupSubEntry(), newStubManager(stub))
 	if got != StateSetupNeeded {
 		t.Errorf("detectSetupPathState with failing check = %v, want StateSetupNeeded", got)
 	}
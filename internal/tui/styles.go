@@ -1,6 +1,8 @@
 package tui
 
 import (
+	"image/color"
+
 	"charm.land/lipgloss/v2"
 	"github.com/AntoineGS/tidydots/internal/tui/tuishared"
 )
@@ -9,59 +11,114 @@ import (
 // continues to compile unchanged.  New sub-packages (forms/, table/, etc.)
 // should import tuishared directly.
 
-// Catppuccin Mocha palette — re-exported from tuishared for files that use raw colors.
+// The active theme's palette, re-exported from tuishared for files that use
+// raw colors; see ApplyTheme.
 var (
-	primaryColor  = tuishared.PrimaryColor
-	accentColor   = tuishared.AccentColor
-	errorColor    = tuishared.ErrorColor
-	mutedColor    = tuishared.MutedColor
-	surface2Color = tuishared.Surface2Color
-	blueColor     = tuishared.BlueColor
+	primaryColor color.Color
+	accentColor  color.Color
+	errorColor   color.Color
+	mutedColor   color.Color
+	blueColor    color.Color
 )
 
-// Ensure lipgloss import is used.
-var _ lipgloss.Style
-
-// Style variables re-exported from tuishared.
+// Style variables re-exported from tuishared; see ApplyTheme.
 var (
-	BaseStyle                = tuishared.BaseStyle
-	TitleStyle               = tuishared.TitleStyle
-	SubtitleStyle            = tuishared.SubtitleStyle
-	MutedTextStyle           = tuishared.MutedTextStyle
-	MenuItemStyle            = tuishared.MenuItemStyle
-	SelectedMenuItemStyle    = tuishared.SelectedMenuItemStyle
-	ListItemStyle            = tuishared.ListItemStyle
-	SelectedListItemStyle    = tuishared.SelectedListItemStyle
-	CheckedStyle             = tuishared.CheckedStyle
-	UncheckedStyle           = tuishared.UncheckedStyle
-	PathNameStyle            = tuishared.PathNameStyle
-	PathTargetStyle          = tuishared.PathTargetStyle
-	PathBackupStyle          = tuishared.PathBackupStyle
-	FolderBadgeStyle         = tuishared.FolderBadgeStyle
-	StateBadgeReadyStyle     = tuishared.StateBadgeReadyStyle
-	StateBadgeAdoptStyle     = tuishared.StateBadgeAdoptStyle
-	StateBadgeMissingStyle   = tuishared.StateBadgeMissingStyle
-	StateBadgeLinkedStyle    = tuishared.StateBadgeLinkedStyle
-	StateBadgeOutdatedStyle  = tuishared.StateBadgeOutdatedStyle
-	StateBadgeModifiedStyle  = tuishared.StateBadgeModifiedStyle
-	StateBadgeFilteredStyle  = tuishared.StateBadgeFilteredStyle
-	StateBadgeInstalledStyle = tuishared.StateBadgeInstalledStyle
-	ProgressStyle            = tuishared.ProgressStyle
-	SuccessStyle             = tuishared.SuccessStyle
-	ErrorStyle               = tuishared.ErrorStyle
-	WarningStyle             = tuishared.WarningStyle
-	BoxStyle                 = tuishared.BoxStyle
-	ResultBoxStyle           = tuishared.ResultBoxStyle
-	HelpStyle                = tuishared.HelpStyle
-	HelpKeyStyle             = tuishared.HelpKeyStyle
-	StatusBarStyle           = tuishared.StatusBarStyle
-	SpinnerStyle             = tuishared.SpinnerStyle
-	FilterInputStyle         = tuishared.FilterInputStyle
-	FilterHighlightStyle     = tuishared.FilterHighlightStyle
-	MultiSelectBannerStyle   = tuishared.MultiSelectBannerStyle
-	SelectedRowStyle         = tuishared.SelectedRowStyle
+	BaseStyle                lipgloss.Style
+	TitleStyle               lipgloss.Style
+	SubtitleStyle            lipgloss.Style
+	MutedTextStyle           lipgloss.Style
+	MenuItemStyle            lipgloss.Style
+	SelectedMenuItemStyle    lipgloss.Style
+	ListItemStyle            lipgloss.Style
+	SelectedListItemStyle    lipgloss.Style
+	CheckedStyle             lipgloss.Style
+	UncheckedStyle           lipgloss.Style
+	PathNameStyle            lipgloss.Style
+	PathTargetStyle          lipgloss.Style
+	PathBackupStyle          lipgloss.Style
+	FolderBadgeStyle         lipgloss.Style
+	StateBadgeReadyStyle     lipgloss.Style
+	StateBadgeAdoptStyle     lipgloss.Style
+	StateBadgeMissingStyle   lipgloss.Style
+	StateBadgeLinkedStyle    lipgloss.Style
+	StateBadgeOutdatedStyle  lipgloss.Style
+	StateBadgeModifiedStyle  lipgloss.Style
+	StateBadgeFilteredStyle  lipgloss.Style
+	StateBadgeInstalledStyle lipgloss.Style
+	ProgressStyle            lipgloss.Style
+	SuccessStyle             lipgloss.Style
+	ErrorStyle               lipgloss.Style
+	WarningStyle             lipgloss.Style
+	BoxStyle                 lipgloss.Style
+	ResultBoxStyle           lipgloss.Style
+	HelpStyle                lipgloss.Style
+	HelpKeyStyle             lipgloss.Style
+	StatusBarStyle           lipgloss.Style
+	SpinnerStyle             lipgloss.Style
+	FilterInputStyle         lipgloss.Style
+	FilterHighlightStyle     lipgloss.Style
+	MultiSelectBannerStyle   lipgloss.Style
+	SelectedRowStyle         lipgloss.Style
 )
 
+// ApplyTheme builds every style of the TUI from t. Run applies the theme
+// chosen with --theme or in the app config before the TUI starts.
+func ApplyTheme(t tuishared.Theme) {
+	tuishared.ApplyTheme(t)
+	syncStyles()
+}
+
+// syncStyles copies the colors and styles tuishared built into the
+// variables of this package.
+func syncStyles() {
+	primaryColor = tuishared.PrimaryColor
+	accentColor = tuishared.AccentColor
+	errorColor = tuishared.ErrorColor
+	mutedColor = tuishared.MutedColor
+	blueColor = tuishared.BlueColor
+
+	BaseStyle = tuishared.BaseStyle
+	TitleStyle = tuishared.TitleStyle
+	SubtitleStyle = tuishared.SubtitleStyle
+	MutedTextStyle = tuishared.MutedTextStyle
+	MenuItemStyle = tuishared.MenuItemStyle
+	SelectedMenuItemStyle = tuishared.SelectedMenuItemStyle
+	ListItemStyle = tuishared.ListItemStyle
+	SelectedListItemStyle = tuishared.SelectedListItemStyle
+	CheckedStyle = tuishared.CheckedStyle
+	UncheckedStyle = tuishared.UncheckedStyle
+	PathNameStyle = tuishared.PathNameStyle
+	PathTargetStyle = tuishared.PathTargetStyle
+	PathBackupStyle = tuishared.PathBackupStyle
+	FolderBadgeStyle = tuishared.FolderBadgeStyle
+	StateBadgeReadyStyle = tuishared.StateBadgeReadyStyle
+	StateBadgeAdoptStyle = tuishared.StateBadgeAdoptStyle
+	StateBadgeMissingStyle = tuishared.StateBadgeMissingStyle
+	StateBadgeLinkedStyle = tuishared.StateBadgeLinkedStyle
+	StateBadgeOutdatedStyle = tuishared.StateBadgeOutdatedStyle
+	StateBadgeModifiedStyle = tuishared.StateBadgeModifiedStyle
+	StateBadgeFilteredStyle = tuishared.StateBadgeFilteredStyle
+	StateBadgeInstalledStyle = tuishared.StateBadgeInstalledStyle
+	ProgressStyle = tuishared.ProgressStyle
+	SuccessStyle = tuishared.SuccessStyle
+	ErrorStyle = tuishared.ErrorStyle
+	WarningStyle = tuishared.WarningStyle
+	BoxStyle = tuishared.BoxStyle
+	ResultBoxStyle = tuishared.ResultBoxStyle
+	HelpStyle = tuishared.HelpStyle
+	HelpKeyStyle = tuishared.HelpKeyStyle
+	StatusBarStyle = tuishared.StatusBarStyle
+	SpinnerStyle = tuishared.SpinnerStyle
+	FilterInputStyle = tuishared.FilterInputStyle
+	FilterHighlightStyle = tuishared.FilterHighlightStyle
+	MultiSelectBannerStyle = tuishared.MultiSelectBannerStyle
+	SelectedRowStyle = tuishared.SelectedRowStyle
+}
+
+func init() {
+	syncStyles()
+}
+
 // Style function wrappers — delegate to tuishared.
 
 // Rendering helper functions re-exported from tuishared.
@@ -33,59 +33,77 @@ func (m Model) viewSummary() string {
 		b.WriteString(m.renderInstallSummary())
 	case OpRestore:
 		b.WriteString(m.renderHierarchicalSummary("restore"))
+
+		if choice := m.renderResumeChoice(); choice != "" {
+			b.WriteString("\n")
+			b.WriteString(choice)
+		}
 	case OpDelete, OpList:
 		b.WriteString(m.renderHierarchicalSummary("delete"))
 	}
 
+	bindings := []key.Binding{SummaryKeys.Confirm, SummaryKeys.Cancel}
+	if m.summaryOperation == OpRestore && len(m.interruptedRestore) > 0 {
+		bindings = append(bindings, SummaryKeys.Resume)
+	}
+
 	// Help
 	b.WriteString("\n\n")
-	b.WriteString(RenderHelpFromBindings(m.width,
-		SummaryKeys.Confirm,
-		SummaryKeys.Cancel,
-		SharedKeys.Quit,
-	))
+	b.WriteString(RenderHelpFromBindings(m.width, append(bindings, SharedKeys.Help, SharedKeys.Quit)...))
 
 	return BaseStyle.Render(b.String())
 }
 
 // renderInstallSummary renders the install packages summary.
-// Shows selected applications (app-level packages only).
+// Shows selected applications (app-level packages only), and the selected
+// packages that will be skipped because this system cannot install them.
 func (m Model) renderInstallSummary() string {
 	var b strings.Builder
 
-	// Count selected apps with packages. Iterating m.Applications keeps the
-	// output in model order without collecting and sorting map keys.
-	selectedAppsWithPkg := 0
-	for _, app := range m.Applications {
-		if m.selectedApps[app.Application.Name] && app.PkgInstalled != nil && !*app.PkgInstalled {
-			selectedAppsWithPkg++
-		}
-	}
+	installable, unavailable := m.collectBatchInstallItems()
 
-	b.WriteString(SubtitleStyle.Render(fmt.Sprintf("Will install packages for %d application(s):", selectedAppsWithPkg)))
+	b.WriteString(SubtitleStyle.Render(fmt.Sprintf("Will install packages for %d application(s):", len(installable))))
 	b.WriteString("\n\n")
 
-	for _, app := range m.Applications {
-		if !m.selectedApps[app.Application.Name] || app.PkgInstalled == nil || *app.PkgInstalled {
-			continue
-		}
-
+	for _, pkg := range installable {
 		b.WriteString(CheckedStyle.Render("  • "))
-		b.WriteString(PathNameStyle.Render(app.Application.Name))
-		if app.PkgMethod != "" && app.PkgMethod != TypeNone {
-			b.WriteString(MutedTextStyle.Render(fmt.Sprintf(" (%s)", app.PkgMethod)))
-		}
+		b.WriteString(PathNameStyle.Render(pkg.Name))
+		b.WriteString(MutedTextStyle.Render(fmt.Sprintf(" (%s)", pkg.Method)))
 		b.WriteString("\n")
 	}
 
-	if selectedAppsWithPkg == 0 {
-		b.WriteString(MutedTextStyle.Render("  No packages to install (all already installed)"))
+	if len(installable) == 0 {
+		b.WriteString(MutedTextStyle.Render("  No packages to install"))
 		b.WriteString("\n")
 	}
 
+	if len(unavailable) > 0 {
+		b.WriteString("\n")
+		b.WriteString(WarningStyle.Render(fmt.Sprintf("%d package(s) unavailable on this system will be skipped:", len(unavailable))))
+		b.WriteString("\n")
+
+		for _, pkg := range unavailable {
+			b.WriteString(MutedTextStyle.Render("  • " + pkg.Name))
+			b.WriteString("\n")
+		}
+	}
+
 	return b.String()
 }
 
+// enabledSubItems returns the sub-entries that are not disabled, in order.
+func enabledSubItems(subs []SubEntryItem) []SubEntryItem {
+	enabled := make([]SubEntryItem, 0, len(subs))
+
+	for _, sub := range subs {
+		if !sub.IsDisabled {
+			enabled = append(enabled, sub)
+		}
+	}
+
+	return enabled
+}
+
 // summarySubEntryDetail describes what will happen to a sub-entry, so the
 // pre-flight summary does not present a setup entry as a file restore to an
 // empty target path: a config entry deploys files to its target, a setup entry
@@ -120,14 +138,21 @@ func (m Model) renderHierarchicalSummary(operation string) string {
 			continue
 		}
 
+		// A restore skips disabled sub-entries; a delete removes the whole
+		// application, disabled sub-entries included.
+		subs := app.SubItems
+		if operation == "restore" {
+			subs = enabledSubItems(subs)
+		}
+
 		// App header
 		b.WriteString(CheckedStyle.Render("▼ "))
 		b.WriteString(PathNameStyle.Render(app.Application.Name))
-		b.WriteString(MutedTextStyle.Render(fmt.Sprintf(" (%d entries)", len(app.SubItems))))
+		b.WriteString(MutedTextStyle.Render(fmt.Sprintf(" (%d entries)", len(subs))))
 		b.WriteString("\n")
 
 		// Sub-entries
-		for _, sub := range app.SubItems {
+		for _, sub := range subs {
 			b.WriteString("  ")
 			b.WriteString(CheckedStyle.Render("  • "))
 			b.WriteString(sub.SubEntry.Name)
@@ -189,6 +214,10 @@ func (m Model) updateSummary(msg tea.KeyPressMsg) (tea.Model, tea.Cmd) {
 		// Confirm - execute the batch operation
 		return m.executeConfirmedOperation()
 
+	case key.Matches(msg, SummaryKeys.Resume) && m.summaryOperation == OpRestore && len(m.interruptedRestore) > 0:
+		m.resumeRestore = !m.resumeRestore
+		return m, nil
+
 	case key.Matches(msg, SummaryKeys.Cancel):
 		// Cancel - return to manage view
 		m.Screen = ScreenResults
@@ -230,6 +259,21 @@ func (m Model) updateSummary(msg tea.KeyPressMsg) (tea.Model, tea.Cmd) {
 // executeConfirmedOperation executes the confirmed batch operation.
 // Initializes progress state and switches to progress screen.
 func (m Model) executeConfirmedOperation() (tea.Model, tea.Cmd) {
+	// Entries whose targets conflict with their backup are asked about before
+	// the batch starts; confirming the last one comes back here.
+	if m.summaryOperation == OpRestore && m.resolutions == nil {
+		items := m.collectBatchRestoreItems()
+		subItems := make([]SubEntryItem, 0, len(items))
+		for _, item := range items {
+			subItems = append(subItems, m.Applications[item.appIdx].SubItems[item.subIdx])
+		}
+
+		if conflicts := m.restoreConflicts(subItems); len(conflicts) > 0 {
+			m.startConflicts(conflicts, true)
+			return m, nil
+		}
+	}
+
 	// Initialize progress bar
 	m.batchProgress = initBatchProgress()
 
@@ -250,6 +294,7 @@ func (m Model) executeConfirmedOperation() (tea.Model, tea.Cmd) {
 	switch m.summaryOperation {
 	case OpRestore:
 		cmd = m.executeBatchRestore()
+		m.resolutions = nil
 	case OpInstallPackages:
 		cmd = m.executeBatchInstall()
 	case OpDelete:
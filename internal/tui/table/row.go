@@ -29,6 +29,12 @@ const (
 	StateSetupOk
 	// StateSetupNeeded indicates a setup entry whose check command fails.
 	StateSetupNeeded
+	// StateDirty indicates linked but the backup files have uncommitted git changes.
+	StateDirty
+	// StateDisabled indicates an entry switched off with enabled: false; it is not checked.
+	StateDisabled
+	// StatePartial indicates a link_mode: files entry with only some of its file links in place.
+	StatePartial
 )
 
 // stateLinkedLabel is the display label for StateLinked.
@@ -55,6 +61,12 @@ func (s PathState) String() string {
 		return "Set up"
 	case StateSetupNeeded:
 		return "Needs setup"
+	case StateDirty:
+		return "Dirty"
+	case StateDisabled:
+		return "Disabled"
+	case StatePartial:
+		return "Partial"
 	}
 
 	return "Unknown"
@@ -75,4 +87,5 @@ type Row struct {
 	InfoAttention   bool      // Info column needs attention
 	InfoState       PathState // Highest-severity sub-entry state (app rows only)
 	BackupPath      string    // Backup/source path for sub-entries (empty for app rows)
+	Disabled        bool      // Disabled application or entry; rendered muted
 }
@@ -19,6 +19,7 @@ func TestPathStateString(t *testing.T) {
 		{"StateLinked", StateLinked, "Linked"},
 		{"StateOutdated", StateOutdated, "Outdated"},
 		{"StateModified", StateModified, "Modified"},
+		{"StateDirty", StateDirty, "Dirty"},
 		{"unknown value", PathState(99), "Unknown"},
 	}
 
@@ -134,6 +135,10 @@ func TestPathState_ExistingValuesUnchanged(t *testing.T) {
 	if StateModified != 6 {
 		t.Errorf("StateModified = %d, want 6; new states must be appended, not inserted", StateModified)
 	}
+
+	if StateDirty != 9 {
+		t.Errorf("StateDirty = %d, want 9; new states must be appended, not inserted", StateDirty)
+	}
 }
 
 func TestRowAppLevel(t *testing.T) {
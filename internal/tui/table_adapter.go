@@ -52,6 +52,7 @@ func flattenApplications(apps []ApplicationItem, osType string, filterEnabled bo
 			StatusAttention: needsAttention(statusText),
 			InfoAttention:   infoState != StateLinked,
 			InfoState:       infoState,
+			Disabled:        app.IsDisabled,
 		})
 
 		// Level 1: Sub-entry rows (if expanded)
@@ -87,6 +88,7 @@ func flattenApplications(apps []ApplicationItem, osType string, filterEnabled bo
 					StatusAttention: needsAttention(subItem.State.String()),
 					InfoAttention:   false, // Sub-entries don't have info attention
 					BackupPath:      subItem.SubEntry.Backup,
+					Disabled:        subItem.IsDisabled,
 				})
 			}
 		}
@@ -99,6 +101,10 @@ func flattenApplications(apps []ApplicationItem, osType string, filterEnabled bo
 // package install state only. Config sub-entry states are reflected in the
 // info column via appInfoNeedsAttention.
 func getApplicationStatus(app ApplicationItem) string {
+	if app.IsDisabled {
+		return StatusDisabled
+	}
+
 	if app.IsFiltered {
 		return StatusFiltered
 	}
@@ -146,6 +152,7 @@ func needsAttention(status string) bool {
 	return status != StatusInstalled &&
 		status != StatusUnknown &&
 		status != StatusLoading &&
+		status != StatusDisabled &&
 		status != StateLinked.String() &&
 		status != StateSetupOk.String()
 }
@@ -154,13 +161,13 @@ func needsAttention(status string) bool {
 // Higher values indicate more urgent states that should take priority in the info column.
 func stateSeverity(s PathState) int {
 	switch s {
-	case StateMissing, StateReady, StateAdopt, StateSetupNeeded:
+	case StateMissing, StateReady, StateAdopt, StateSetupNeeded, StatePartial:
 		return 3 // Red — action required
 	case StateOutdated:
 		return 2 // Amber — template source changed
-	case StateModified:
+	case StateModified, StateDirty:
 		return 1 // Blue — user edits detected
-	case StateLoading, StateLinked, StateSetupOk:
+	case StateLoading, StateLinked, StateSetupOk, StateDisabled:
 		return 0 // No attention
 	}
 
@@ -168,9 +175,10 @@ func stateSeverity(s PathState) int {
 }
 
 // appInfoMaxState returns the highest-severity sub-entry state for an application.
-// Returns StateLinked when no sub-entry needs attention or the app is filtered.
+// Returns StateLinked when no sub-entry needs attention or the app is filtered
+// or disabled.
 func appInfoMaxState(app ApplicationItem) PathState {
-	if app.IsFiltered {
+	if app.IsFiltered || app.IsDisabled {
 		return StateLinked
 	}
 
@@ -128,15 +128,21 @@ func (m *Model) initTableModel() {
 	filtered := m.getSearchedApplications()
 
 	// Sort applications before flattening (only if sort column applies to apps)
-	if m.sortColumn == SortColumnName || m.sortColumn == SortColumnStatus {
+	if m.sortColumn == SortColumnName || m.sortColumn == SortColumnStatus || m.sortColumn == SortColumnPriority {
 		slices.SortStableFunc(filtered, func(a, b ApplicationItem) int {
 			var cmp int
-			if m.sortColumn == SortColumnName {
+			switch m.sortColumn {
+			case SortColumnName:
 				cmp = strings.Compare(strings.ToLower(a.Application.Name), strings.ToLower(b.Application.Name))
-			} else { // SortColumnStatus
+			case SortColumnStatus:
 				statusA := getApplicationStatus(a)
 				statusB := getApplicationStatus(b)
 				cmp = strings.Compare(strings.ToLower(statusA), strings.ToLower(statusB))
+			default: // SortColumnPriority: ascending is restore order, highest priority first
+				cmp = b.Application.Priority - a.Application.Priority
+				if cmp == 0 {
+					cmp = strings.Compare(strings.ToLower(a.Application.Name), strings.ToLower(b.Application.Name))
+				}
 			}
 
 			if !m.sortAscending {
@@ -314,11 +320,17 @@ func (m Model) renderTable(availableHeight int) string {
 	// Determine if we have enough width to show backup column
 	showBackupColumn := m.width >= 140
 
-	// Build headers with highlighted shortcuts and sort indicators
+	// Build headers with highlighted shortcuts and sort indicators. Priority
+	// has no column of its own, so its indicator goes with the name.
+	nameHeader := m.formatHeaderWithShortcut("name", 'n', SortColumnName)
+	if m.sortColumn == SortColumnPriority {
+		nameHeader += " " + m.formatHeaderWithShortcut("by priority", 'o', SortColumnPriority)
+	}
+
 	var headers []string
 	if showBackupColumn {
 		headers = []string{
-			m.formatHeaderWithShortcut("name", 'n', SortColumnName),
+			nameHeader,
 			m.formatHeaderWithShortcut("status", 't', SortColumnStatus),
 			"info",
 			"backup",
@@ -326,7 +338,7 @@ func (m Model) renderTable(availableHeight int) string {
 		}
 	} else {
 		headers = []string{
-			m.formatHeaderWithShortcut("name", 'n', SortColumnName),
+			nameHeader,
 			m.formatHeaderWithShortcut("status", 't', SortColumnStatus),
 			"info",
 			m.formatHeaderWithShortcut("path", 'p', SortColumnPath),
@@ -396,7 +408,7 @@ func (m Model) renderTable(availableHeight int) string {
 			// Indicator rows get muted styling
 			if isIndicatorRow {
 				return lipgloss.NewStyle().
-					Foreground(lipgloss.Color("240")). // Dim gray
+					Foreground(mutedColor).
 					Italic(true).
 					Padding(0, 1)
 			}
@@ -413,11 +425,7 @@ func (m Model) renderTable(availableHeight int) string {
 
 			// Cursor row styling (takes priority)
 			if actualRow == m.tableCursor {
-				return lipgloss.NewStyle().
-					Foreground(primaryColor).
-					Background(surface2Color).
-					Bold(true).
-					Padding(0, 1)
+				return SelectedListItemStyle.Padding(0, 1)
 			}
 
 			// Multi-select styling
@@ -445,11 +453,16 @@ func (m Model) renderTable(availableHeight int) string {
 func cellAttentionStyle(tr TableRow, col int) lipgloss.Style {
 	baseStyle := lipgloss.NewStyle().Padding(0, 1)
 
+	// Disabled rows are greyed out whole: nothing on them needs attention.
+	if tr.Disabled {
+		return baseStyle.Foreground(mutedColor)
+	}
+
 	if col == 1 && tr.StatusAttention {
 		if tr.State == StateOutdated || tr.Data[1] == StatusOutdated {
 			return baseStyle.Foreground(accentColor)
 		}
-		if tr.State == StateModified || tr.Data[1] == StatusModified {
+		if tr.State == StateModified || tr.State == StateDirty || tr.Data[1] == StatusModified {
 			return baseStyle.Foreground(blueColor)
 		}
 		return baseStyle.Foreground(errorColor)
@@ -460,7 +473,7 @@ func cellAttentionStyle(tr TableRow, col int) lipgloss.Style {
 			return baseStyle.Foreground(errorColor)
 		case tr.InfoState == StateOutdated:
 			return baseStyle.Foreground(accentColor)
-		case tr.InfoState == StateModified:
+		case tr.InfoState == StateModified, tr.InfoState == StateDirty:
 			return baseStyle.Foreground(blueColor)
 		default:
 			return baseStyle.Foreground(errorColor)
@@ -514,7 +527,7 @@ func (m Model) buildVisibleRowsWithIndicators(
 
 		// Style indicator without margin (SubtitleStyle has MarginBottom(1) which creates empty row)
 		styledIndicator := lipgloss.NewStyle().
-			Foreground(lipgloss.Color("240")).
+			Foreground(mutedColor).
 			Italic(true).
 			Render(indicator)
 
@@ -573,7 +586,7 @@ func (m Model) buildVisibleRowsWithIndicators(
 
 		// Style indicator without margin (SubtitleStyle has MarginBottom(1) which creates empty row)
 		styledIndicator := lipgloss.NewStyle().
-			Foreground(lipgloss.Color("240")).
+			Foreground(mutedColor).
 			Italic(true).
 			Render(indicator)
 
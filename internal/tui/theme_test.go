@@ -0,0 +1,123 @@
+package tui
+
+import (
+	"strings"
+	"testing"
+
+	"charm.land/lipgloss/v2"
+	"github.com/AntoineGS/tidydots/internal/config"
+	"github.com/AntoineGS/tidydots/internal/tui/tuishared"
+)
+
+// useTheme applies t for the rest of the test.
+func useTheme(t *testing.T, theme tuishared.Theme) {
+	t.Helper()
+
+	orig := tuishared.ActiveTheme()
+	t.Cleanup(func() { ApplyTheme(orig) })
+
+	ApplyTheme(theme)
+}
+
+func TestResolveTheme(t *testing.T) {
+	dark := func() bool { return true }
+	light := func() bool { return false }
+
+	tests := []struct {
+		name     string
+		theme    string
+		noColor  string
+		clicolor string
+		bg       func() bool
+		want     string
+		wantErr  bool
+	}{
+		{name: "unset follows a dark background", bg: dark, want: tuishared.ThemeDark},
+		{name: "unset follows a light background", bg: light, want: tuishared.ThemeLight},
+		{name: "auto follows the background", theme: "auto", bg: light, want: tuishared.ThemeLight},
+		{name: "unset without detection", want: tuishared.ThemeDark},
+		{name: "named theme wins over the background", theme: "Dark", bg: light, want: tuishared.ThemeDark},
+		{name: "monochrome", theme: "monochrome", bg: dark, want: tuishared.ThemeMonochrome},
+		{name: "NO_COLOR", theme: "light", noColor: "1", bg: light, want: tuishared.ThemeMonochrome},
+		{name: "CLICOLOR=0", clicolor: "0", bg: dark, want: tuishared.ThemeMonochrome},
+		{name: "CLICOLOR=1", clicolor: "1", bg: dark, want: tuishared.ThemeDark},
+		{name: "unknown", theme: "solarized", wantErr: true},
+		{name: "unknown without colors", theme: "solarized", noColor: "1", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("NO_COLOR", tt.noColor)
+			t.Setenv("CLICOLOR", tt.clicolor)
+
+			got, err := tuishared.ResolveTheme(tt.theme, tt.bg)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ResolveTheme(%q) error = %v, wantErr %v", tt.theme, err, tt.wantErr)
+			}
+
+			if !tt.wantErr && got.Name != tt.want {
+				t.Errorf("ResolveTheme(%q) = %s, want %s", tt.theme, got.Name, tt.want)
+			}
+		})
+	}
+}
+
+func TestApplyTheme_RebuildsStyles(t *testing.T) {
+	useTheme(t, tuishared.LightTheme)
+
+	if TitleStyle.GetForeground() != tuishared.LightTheme.Primary || primaryColor != tuishared.LightTheme.Primary {
+		t.Errorf("TitleStyle foreground = %v, primaryColor = %v; want the light theme's %v",
+			TitleStyle.GetForeground(), primaryColor, tuishared.LightTheme.Primary)
+	}
+
+	if tuishared.ErrorStyle.GetForeground() != tuishared.LightTheme.Error {
+		t.Errorf("tuishared.ErrorStyle foreground = %v, want %v", tuishared.ErrorStyle.GetForeground(), tuishared.LightTheme.Error)
+	}
+
+	ApplyTheme(tuishared.MonochromeTheme)
+
+	if _, ok := SelectedListItemStyle.GetForeground().(lipgloss.NoColor); !ok {
+		t.Errorf("SelectedListItemStyle foreground = %v, want no color", SelectedListItemStyle.GetForeground())
+	}
+
+	if !SelectedListItemStyle.GetReverse() || !StateBadgeMissingStyle.GetReverse() {
+		t.Error("monochrome does not show the cursor and badges in reverse video")
+	}
+
+	ApplyTheme(tuishared.DarkTheme)
+
+	if SelectedListItemStyle.GetReverse() {
+		t.Error("the dark theme kept the monochrome reverse video")
+	}
+}
+
+func TestApplyThemeSetting(t *testing.T) {
+	t.Setenv("NO_COLOR", "")
+	t.Setenv("CLICOLOR", "")
+	useTheme(t, tuishared.DarkTheme)
+
+	if err := applyThemeSetting("", &config.AppConfig{Theme: "light"}); err != nil {
+		t.Fatalf("applyThemeSetting() error = %v", err)
+	}
+
+	if got := tuishared.ActiveTheme().Name; got != tuishared.ThemeLight {
+		t.Errorf("theme from the app config = %s, want light", got)
+	}
+
+	if err := applyThemeSetting("monochrome", &config.AppConfig{Theme: "light"}); err != nil {
+		t.Fatalf("applyThemeSetting() error = %v", err)
+	}
+
+	if got := tuishared.ActiveTheme().Name; got != tuishared.ThemeMonochrome {
+		t.Errorf("theme from --theme = %s, want monochrome", got)
+	}
+
+	err := applyThemeSetting("", &config.AppConfig{Theme: "neon"})
+	if err == nil || !strings.Contains(err.Error(), "theme in") {
+		t.Errorf("applyThemeSetting(neon) error = %v, want one naming the app config", err)
+	}
+
+	if err := applyThemeSetting("neon", nil); err == nil || !strings.HasPrefix(err.Error(), "--theme") {
+		t.Errorf("applyThemeSetting(--theme neon) error = %v, want one naming --theme", err)
+	}
+}
@@ -0,0 +1,79 @@
+package tui
+
+import (
+	"io"
+	"strings"
+
+	"charm.land/lipgloss/v2"
+
+	"github.com/AntoineGS/tidydots/internal/config"
+	"github.com/AntoineGS/tidydots/internal/platform"
+)
+
+// WriteTree writes the applications that apply to plat as a plain-text tree:
+// one line per application with its sub-entries indented beneath, each showing
+// its type and target. The rows are the ones the list view renders, with every
+// application expanded and those filtered out by `when` left out, so the text
+// matches the TUI without needing a terminal. Disabled applications and
+// entries are left out too unless showDisabled is set, in which case they are
+// marked "(disabled)".
+func WriteTree(w io.Writer, cfg *config.Config, plat *platform.Platform, showDisabled bool) error {
+	m := NewModel(cfg, plat, false)
+
+	apps := make([]ApplicationItem, 0, len(m.Applications))
+
+	for _, app := range m.Applications {
+		if !showDisabled {
+			if app.IsDisabled {
+				continue
+			}
+
+			app.SubItems = enabledSubItems(app.SubItems)
+		}
+
+		app.Expanded = true
+		apps = append(apps, app)
+	}
+
+	var lines [][]string
+
+	for _, row := range flattenApplications(apps, plat.OS, true) {
+		suffix := ""
+		if row.Disabled {
+			suffix = " (disabled)"
+		}
+
+		if row.Level == 0 {
+			lines = append(lines, []string{row.AppName + suffix, row.Data[2], ""})
+			continue
+		}
+
+		lines = append(lines, []string{"  " + row.TreeChar + " " + row.SubName + suffix, row.Data[2], row.Data[3]})
+	}
+
+	// Columns are aligned on display width rather than rune count, so names
+	// with wide characters (CJK, emoji) do not push the columns out of line.
+	var widths [2]int
+
+	for _, cells := range lines {
+		for i := range widths {
+			widths[i] = max(widths[i], lipgloss.Width(cells[i]))
+		}
+	}
+
+	var b strings.Builder
+
+	for _, cells := range lines {
+		for i := range widths {
+			b.WriteString(cells[i])
+			b.WriteString(strings.Repeat(" ", widths[i]-lipgloss.Width(cells[i])+2))
+		}
+
+		b.WriteString(cells[2])
+		b.WriteString("\n")
+	}
+
+	_, err := io.WriteString(w, b.String())
+
+	return err
+}
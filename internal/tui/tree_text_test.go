@@ -0,0 +1,142 @@
+package tui
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"charm.land/lipgloss/v2"
+
+	"github.com/AntoineGS/tidydots/internal/config"
+)
+
+func TestWriteTree(t *testing.T) {
+	cfg := &config.Config{
+		Version: 3,
+		Applications: []config.Application{
+			{
+				Name: "zsh",
+				Entries: []config.SubEntry{
+					{Name: "rc", Backup: "./zsh", Files: []string{".zshrc", ".zshenv"}, Targets: map[string]string{"linux": "~"}},
+				},
+			},
+			{
+				Name: "nvim",
+				Entries: []config.SubEntry{
+					{Name: "config", Backup: "./nvim", Targets: map[string]string{"linux": "~/.config/nvim"}},
+					{Name: "win-only", Backup: "./nvim-win", Targets: map[string]string{"windows": "~/AppData/Local/nvim"}},
+				},
+			},
+			{
+				Name: "mac-only",
+				When: `{{ eq .OS "darwin" }}`,
+				Entries: []config.SubEntry{
+					{Name: "config", Backup: "./mac", Targets: map[string]string{"linux": "~/.mac"}},
+				},
+			},
+		},
+	}
+
+	var buf bytes.Buffer
+	if err := WriteTree(&buf, cfg, linuxPlatform(), false); err != nil {
+		t.Fatalf("WriteTree() error = %v", err)
+	}
+
+	want := []string{
+		"nvim         1 entry",
+		"  └─ config  folder   ~/.config/nvim",
+		"zsh          1 entry",
+		"  └─ rc      2 files  ~",
+	}
+
+	got := strings.Split(strings.TrimRight(buf.String(), "\n"), "\n")
+	for i := range got {
+		got[i] = strings.TrimRight(got[i], " ")
+	}
+
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("WriteTree() =\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+}
+
+func TestWriteTree_WideNames(t *testing.T) {
+	cfg := &config.Config{
+		Version: 3,
+		Applications: []config.Application{
+			{
+				Name: "日本語",
+				Entries: []config.SubEntry{
+					{Name: "設定", Backup: "./jp", Targets: map[string]string{"linux": "~/.jp"}},
+				},
+			},
+			{
+				Name: "zsh",
+				Entries: []config.SubEntry{
+					{Name: "rc 🐚", Backup: "./zsh", Files: []string{".zshrc"}, Targets: map[string]string{"linux": "~"}},
+				},
+			},
+		},
+	}
+
+	var buf bytes.Buffer
+	if err := WriteTree(&buf, cfg, linuxPlatform(), false); err != nil {
+		t.Fatalf("WriteTree() error = %v", err)
+	}
+
+	// Every line starts its type column at the same display column, whatever
+	// the width of the characters before it.
+	lines := strings.Split(strings.TrimRight(buf.String(), "\n"), "\n")
+	if len(lines) != 4 {
+		t.Fatalf("WriteTree() wrote %d lines, want 4:\n%s", len(lines), buf.String())
+	}
+
+	typeCol := -1
+
+	for _, line := range lines {
+		name, _, ok := strings.Cut(line, "  1 ")
+		if !ok {
+			name, _, ok = strings.Cut(line, "  folder")
+		}
+
+		if !ok {
+			t.Fatalf("line %q has no type column", line)
+		}
+
+		col := lipgloss.Width(name)
+		if typeCol == -1 {
+			typeCol = col
+		}
+
+		if col != typeCol {
+			t.Errorf("type column of %q starts at %d, want %d:\n%s", line, col, typeCol, buf.String())
+		}
+	}
+}
+
+func TestRenderHelpWithWidth_WideDescriptions(t *testing.T) {
+	// Each item is 2 + 1 + 6*2 = 15 cells (21 bytes) wide, so both fit on one
+	// 32-cell line within 40 - 4, where counting bytes would wrap them.
+	help := RenderHelpWithWidth(40,
+		"ab", "設定設定設定",
+		"cd", "設定設定設定",
+	)
+
+	// HelpStyle adds a blank top margin line.
+	var lines []string
+
+	for _, line := range strings.Split(help, "\n") {
+		if strings.TrimSpace(line) != "" {
+			lines = append(lines, line)
+		}
+	}
+
+	if len(lines) != 1 {
+		t.Fatalf("RenderHelpWithWidth() rendered %d lines, want 1:\n%s", len(lines), help)
+	}
+
+	for _, line := range lines {
+		if w := lipgloss.Width(line); w > 40 {
+			t.Errorf("line %q is %d cells wide, want at most 40", line, w)
+		}
+	}
+}
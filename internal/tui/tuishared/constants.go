@@ -48,6 +48,7 @@ const (
 	StatusInstalled = "Installed"
 	StatusMissing   = "Missing"
 	StatusFiltered  = "Filtered"
+	StatusDisabled  = "Disabled"
 	StatusOutdated  = "Outdated"
 	StatusModified  = "Modified"
 	StatusUnknown   = "Unknown"
@@ -56,9 +57,10 @@ const (
 
 // Sort column constants
 const (
-	SortColumnName   = "name"
-	SortColumnStatus = "status"
-	SortColumnPath   = "path"
+	SortColumnName     = "name"
+	SortColumnStatus   = "status"
+	SortColumnPath     = "path"
+	SortColumnPriority = "priority"
 )
 
 // Scrolling behavior constants
@@ -104,6 +106,8 @@ const (
 	CharLimitBinary  = 128
 	CharLimitDep     = 128
 	CharLimitFile    = 256
+	CharLimitNotes   = 4096
 	InputWidthNarrow = 40
 	InputWidthWide   = 60
+	NotesInputHeight = 6
 )
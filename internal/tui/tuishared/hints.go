@@ -1,6 +1,10 @@
 package tuishared
 
-import "charm.land/bubbles/v2/key"
+import (
+	"strings"
+
+	"charm.land/bubbles/v2/key"
+)
 
 // RenderHelpFromBindings generates help text from key.Binding values.
 // It extracts help pairs from each enabled binding and feeds them into
@@ -16,3 +20,36 @@ func RenderHelpFromBindings(width int, bindings ...key.Binding) string {
 	}
 	return RenderHelpWithWidth(width, pairs...)
 }
+
+// WithDesc returns b described as desc in help, for a binding whose action
+// reads differently in some state (e.g. "enable" for the disable toggle on a
+// disabled row). Its keys, and so their remapping, are b's.
+func WithDesc(b key.Binding, desc string) key.Binding {
+	b.SetHelp(b.Help().Key, desc)
+	return b
+}
+
+// PlainKeys returns the keys of bindings for popup help rows, joined by "/",
+// leaving out the arrow keys when a binding has other keys: some terminals
+// render the arrow glyphs two cells wide, which breaks the popup border.
+func PlainKeys(bindings ...key.Binding) string {
+	var labels []string
+	for _, b := range bindings {
+		keys := b.Keys()
+
+		plain := make([]string, 0, len(keys))
+		for _, k := range keys {
+			if _, arrow := keyLabels[k]; !arrow {
+				plain = append(plain, k)
+			}
+		}
+
+		if len(plain) == 0 {
+			plain = keys
+		}
+
+		labels = append(labels, plain...)
+	}
+
+	return strings.Join(labels, "/")
+}
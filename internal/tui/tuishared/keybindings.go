@@ -6,6 +6,7 @@ import "charm.land/bubbles/v2/key"
 type SharedKeyMap struct {
 	ForceQuit key.Binding
 	Quit      key.Binding
+	Help      key.Binding
 }
 
 // SharedKeys are available on all screens.
@@ -18,30 +19,42 @@ var SharedKeys = SharedKeyMap{
 		key.WithKeys("q"),
 		key.WithHelp("q", "quit"),
 	),
+	Help: key.NewBinding(
+		key.WithKeys("?"),
+		key.WithHelp("?", "help"),
+	),
 }
 
 // ListKeyMap defines keybindings for the main list/results screen.
 type ListKeyMap struct {
-	Up           key.Binding
-	Down         key.Binding
-	Expand       key.Binding
-	Collapse     key.Binding
-	Search       key.Binding
-	SortByName   key.Binding
-	SortByStatus key.Binding
-	SortByPath   key.Binding
-	Filter       key.Binding
-	Edit         key.Binding
-	AddApp       key.Binding
-	AddEntry     key.Binding
-	Delete       key.Binding
-	Restore      key.Binding
-	Install      key.Binding
-	Toggle       key.Binding
-	ShowDetail   key.Binding
-	ShowResults  key.Binding
-	NewOperation key.Binding
-	QuitOrEnter  key.Binding
+	Up             key.Binding
+	Down           key.Binding
+	Top            key.Binding
+	Bottom         key.Binding
+	Jump           key.Binding
+	Expand         key.Binding
+	Collapse       key.Binding
+	ExpandAll      key.Binding
+	CollapseAll    key.Binding
+	Search         key.Binding
+	SortByName     key.Binding
+	SortByStatus   key.Binding
+	SortByPath     key.Binding
+	SortByPriority key.Binding
+	Filter         key.Binding
+	Edit           key.Binding
+	AddApp         key.Binding
+	AddEntry       key.Binding
+	Delete         key.Binding
+	Restore        key.Binding
+	Verify         key.Binding
+	Install        key.Binding
+	Toggle         key.Binding
+	ToggleEnabled  key.Binding
+	ShowDetail     key.Binding
+	ShowResults    key.Binding
+	NewOperation   key.Binding
+	QuitOrEnter    key.Binding
 }
 
 // ListKeys are the keybindings for the list screen.
@@ -54,6 +67,20 @@ var ListKeys = ListKeyMap{
 		key.WithKeys("down", "j"),
 		key.WithHelp("↓/j", "down"),
 	),
+	Top: key.NewBinding(
+		key.WithKeys("g", "home"),
+		key.WithHelp("g", "top"),
+	),
+	Bottom: key.NewBinding(
+		key.WithKeys("G", "end"),
+		key.WithHelp("G", "bottom"),
+	),
+	// Jump is a prefix: the letter typed after it moves the cursor to the
+	// next row whose name starts with that letter.
+	Jump: key.NewBinding(
+		key.WithKeys("'"),
+		key.WithHelp("'", "jump to letter"),
+	),
 	Expand: key.NewBinding(
 		key.WithKeys("enter", "l", "right"),
 		key.WithHelp("l/→", "expand"),
@@ -62,6 +89,14 @@ var ListKeys = ListKeyMap{
 		key.WithKeys("h", "left"),
 		key.WithHelp("h/←", "collapse"),
 	),
+	ExpandAll: key.NewBinding(
+		key.WithKeys("E"),
+		key.WithHelp("E", "expand all"),
+	),
+	CollapseAll: key.NewBinding(
+		key.WithKeys("C"),
+		key.WithHelp("C", "collapse all"),
+	),
 	Search: key.NewBinding(
 		key.WithKeys("/"),
 		key.WithHelp("/", "search"),
@@ -78,6 +113,10 @@ var ListKeys = ListKeyMap{
 		key.WithKeys("p"),
 		key.WithHelp("p", "path"),
 	),
+	SortByPriority: key.NewBinding(
+		key.WithKeys("o"),
+		key.WithHelp("o", "priority"),
+	),
 	Filter: key.NewBinding(
 		key.WithKeys("f"),
 		key.WithHelp("f", "filter"),
@@ -102,6 +141,10 @@ var ListKeys = ListKeyMap{
 		key.WithKeys("r"),
 		key.WithHelp("r", "restore"),
 	),
+	Verify: key.NewBinding(
+		key.WithKeys("V"),
+		key.WithHelp("V", "verify links"),
+	),
 	Install: key.NewBinding(
 		key.WithKeys("i"),
 		key.WithHelp("i", "install"),
@@ -110,6 +153,10 @@ var ListKeys = ListKeyMap{
 		key.WithKeys("tab", "space"),
 		key.WithHelp("tab", "toggle"),
 	),
+	ToggleEnabled: key.NewBinding(
+		key.WithKeys("x"),
+		key.WithHelp("x", "disable"),
+	),
 	ShowDetail: key.NewBinding(
 		key.WithKeys("enter", "l", "right"),
 		key.WithHelp("enter", "detail"),
@@ -203,7 +250,7 @@ type DetailKeyMap struct {
 // DetailKeys are the keybindings for the detail popup.
 var DetailKeys = DetailKeyMap{
 	Close: key.NewBinding(
-		key.WithKeys("esc", "enter"),
+		key.WithKeys("esc", "enter", "h", "left"),
 		key.WithHelp("h/←/esc", "close"),
 	),
 }
@@ -219,6 +266,7 @@ type FormNavKeyMap struct {
 	Cancel  key.Binding
 	Toggle  key.Binding
 	Delete  key.Binding
+	Deps    key.Binding
 }
 
 // FormNavKeys are the keybindings for form navigation.
@@ -259,6 +307,10 @@ var FormNavKeys = FormNavKeyMap{
 		key.WithKeys("d", "backspace", "delete"),
 		key.WithHelp("d", "delete"),
 	),
+	Deps: key.NewBinding(
+		key.WithKeys("p"),
+		key.WithHelp("p", "deps"),
+	),
 }
 
 // TextEditKeyMap defines keybindings when editing a text field.
@@ -266,6 +318,7 @@ type TextEditKeyMap struct {
 	Confirm  key.Binding
 	Cancel   key.Binding
 	SaveForm key.Binding
+	Browse   key.Binding // path fields only
 }
 
 // TextEditKeys are the keybindings for text editing mode.
@@ -281,6 +334,10 @@ var TextEditKeys = TextEditKeyMap{
 		key.WithKeys("ctrl+s"),
 		key.WithHelp("ctrl+s", "save field"),
 	),
+	Browse: key.NewBinding(
+		key.WithKeys("ctrl+o"),
+		key.WithHelp("ctrl+o", "browse"),
+	),
 }
 
 // SuggestionKeyMap defines keybindings for autocomplete suggestions.
@@ -315,6 +372,7 @@ var SuggestionKeys = SuggestionKeyMap{
 type SummaryKeyMap struct {
 	Confirm key.Binding
 	Cancel  key.Binding
+	Resume  key.Binding
 }
 
 // SummaryKeys are the keybindings for the summary screen.
@@ -327,6 +385,10 @@ var SummaryKeys = SummaryKeyMap{
 		key.WithKeys("n", "N", "esc"),
 		key.WithHelp("n/esc", "cancel"),
 	),
+	Resume: key.NewBinding(
+		key.WithKeys("s"),
+		key.WithHelp("s", "skip entries the interrupted restore did"),
+	),
 }
 
 // DiffPickerKeyMap defines keybindings for the diff file picker.
@@ -380,6 +442,102 @@ var ResultsPopupKeys = ResultsPopupKeyMap{
 	),
 }
 
+// ConflictKeyMap defines keybindings for the restore conflict popup.
+type ConflictKeyMap struct {
+	Up     key.Binding
+	Down   key.Binding
+	Select key.Binding
+	Cancel key.Binding
+}
+
+// ConflictKeys are the keybindings for the restore conflict popup.
+var ConflictKeys = ConflictKeyMap{
+	Up: key.NewBinding(
+		key.WithKeys("up", "k"),
+		key.WithHelp("↑/k", "up"),
+	),
+	Down: key.NewBinding(
+		key.WithKeys("down", "j"),
+		key.WithHelp("↓/j", "down"),
+	),
+	Select: key.NewBinding(
+		key.WithKeys("enter"),
+		key.WithHelp("enter", "choose"),
+	),
+	Cancel: key.NewBinding(
+		key.WithKeys("esc"),
+		key.WithHelp("esc", "cancel restore"),
+	),
+}
+
+// PresetPickerKeyMap defines keybindings for the preset picker. Letters go
+// to its search input, so it moves with the arrow keys only.
+type PresetPickerKeyMap struct {
+	Up     key.Binding
+	Down   key.Binding
+	Select key.Binding
+	Cancel key.Binding
+}
+
+// PresetPickerKeys are the keybindings for the preset picker.
+var PresetPickerKeys = PresetPickerKeyMap{
+	Up: key.NewBinding(
+		key.WithKeys("up"),
+		key.WithHelp("↑", "up"),
+	),
+	Down: key.NewBinding(
+		key.WithKeys("down"),
+		key.WithHelp("↓", "down"),
+	),
+	Select: key.NewBinding(
+		key.WithKeys("enter"),
+		key.WithHelp("enter", "fill the form"),
+	),
+	Cancel: key.NewBinding(
+		key.WithKeys("esc"),
+		key.WithHelp("esc", "cancel"),
+	),
+}
+
+// PackagePickerKeyMap defines keybindings for the package picker of
+// import-packages.
+type PackagePickerKeyMap struct {
+	Up        key.Binding
+	Down      key.Binding
+	Toggle    key.Binding
+	ToggleAll key.Binding
+	Confirm   key.Binding
+	Cancel    key.Binding
+}
+
+// PackagePickerKeys are the keybindings for the package picker.
+var PackagePickerKeys = PackagePickerKeyMap{
+	Up: key.NewBinding(
+		key.WithKeys("up", "k"),
+		key.WithHelp("↑/k", "up"),
+	),
+	Down: key.NewBinding(
+		key.WithKeys("down", "j"),
+		key.WithHelp("↓/j", "down"),
+	),
+	Toggle: key.NewBinding(
+		key.WithKeys("space", "tab"),
+		key.WithHelp("space/tab", "toggle"),
+	),
+	ToggleAll: key.NewBinding(
+		key.WithKeys("a"),
+		key.WithHelp("a", "toggle all"),
+	),
+	Confirm: key.NewBinding(
+		key.WithKeys("enter"),
+		key.WithHelp("enter", "import"),
+	),
+	Cancel: key.NewBinding(
+		key.WithKeys("esc", "q"),
+		key.WithHelp("esc", "cancel"),
+	),
+}
+
 // FilePickerKeyMap defines keybindings for the file picker.
 type FilePickerKeyMap struct {
 	Toggle  key.Binding
@@ -403,6 +561,35 @@ var FilePickerKeys = FilePickerKeyMap{
 	),
 }
 
+// PathPickerKeyMap defines keybindings for the file picker when it picks the
+// path of a target or backup field. Enter opens directories and picks files.
+type PathPickerKeyMap struct {
+	Open          key.Binding
+	Pick          key.Binding
+	PickDirectory key.Binding
+	Cancel        key.Binding
+}
+
+// PathPickerKeys are the keybindings for the path picker.
+var PathPickerKeys = PathPickerKeyMap{
+	Open: key.NewBinding(
+		key.WithKeys("enter"),
+		key.WithHelp("enter", "open/pick file"),
+	),
+	Pick: key.NewBinding(
+		key.WithKeys("space", "tab"),
+		key.WithHelp("space/tab", "pick"),
+	),
+	PickDirectory: key.NewBinding(
+		key.WithKeys("."),
+		key.WithHelp(".", "pick this directory"),
+	),
+	Cancel: key.NewBinding(
+		key.WithKeys("esc"),
+		key.WithHelp("esc", "cancel"),
+	),
+}
+
 // ModeChooserKeyMap defines keybindings for the file add mode chooser.
 type ModeChooserKeyMap struct {
 	Up     key.Binding
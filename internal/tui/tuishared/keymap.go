@@ -0,0 +1,256 @@
+package tuishared
+
+import (
+	"fmt"
+	"maps"
+	"reflect"
+	"slices"
+	"strings"
+	"unicode"
+
+	"charm.land/bubbles/v2/key"
+)
+
+// KeyContext is a group of key maps that are active together, such as the
+// list screen or the entry forms. The help overlay lists one section per
+// context, and a key may be bound to one action per context only.
+type KeyContext struct {
+	// Name prefixes the names of the context's actions, e.g. "list".
+	Name string
+	// Title heads the context's section of the help overlay.
+	Title string
+	// Maps are pointers to the key map structs of the context, whose
+	// key.Binding fields are its actions.
+	Maps []any
+}
+
+// KeyContexts is the central definition of every keybinding: the contexts
+// and the key maps each one uses. Action names and the help overlay are
+// derived from it, so a binding added to one of these maps is listed and
+// remappable without further changes.
+var KeyContexts = []KeyContext{
+	{Name: "global", Title: "Everywhere", Maps: []any{&SharedKeys}},
+	{Name: "list", Title: "List", Maps: []any{&ListKeys}},
+	{Name: "select", Title: "Multi-select", Maps: []any{&MultiSelectKeys}},
+	{Name: "search", Title: "Search", Maps: []any{&SearchKeys}},
+	{Name: "confirm", Title: "Confirmations", Maps: []any{&ConfirmKeys}},
+	{Name: "detail", Title: "Detail popup", Maps: []any{&DetailKeys}},
+	{Name: "results", Title: "Results popup", Maps: []any{&ResultsPopupKeys}},
+	{Name: "summary", Title: "Summary", Maps: []any{&SummaryKeys}},
+	{Name: "form", Title: "Forms", Maps: []any{&FormNavKeys}},
+	{Name: "text", Title: "Text fields", Maps: []any{&TextEditKeys}},
+	{Name: "suggestion", Title: "Path suggestions", Maps: []any{&SuggestionKeys}},
+	{Name: "files", Title: "Files list", Maps: []any{&FilesListKeys}},
+	{Name: "mode", Title: "Mode chooser", Maps: []any{&ModeChooserKeys}},
+	{Name: "file_picker", Title: "File picker", Maps: []any{&FilePickerKeys}},
+	{Name: "path_picker", Title: "Path picker", Maps: []any{&PathPickerKeys}},
+	{Name: "diff", Title: "Diff picker", Maps: []any{&DiffPickerKeys}},
+	{Name: "conflict", Title: "Restore conflicts", Maps: []any{&ConflictKeys}},
+	{Name: "preset", Title: "Preset picker", Maps: []any{&PresetPickerKeys}},
+	{Name: "package_picker", Title: "Package import", Maps: []any{&PackagePickerKeys}},
+}
+
+// KeyAction is one binding of a key map under its action name.
+type KeyAction struct {
+	Binding *key.Binding
+	// Name is the context name and the snake_case field name, e.g.
+	// "list.sort_by_name", which the keybindings section of the app config
+	// uses.
+	Name    string
+	Context string
+}
+
+// Desc returns the description of the action: the help of its binding, or
+// its field name in words.
+func (a KeyAction) Desc() string {
+	if desc := a.Binding.Help().Desc; desc != "" {
+		return desc
+	}
+
+	_, field, _ := strings.Cut(a.Name, ".")
+
+	return strings.ReplaceAll(field, "_", " ")
+}
+
+// KeyLabel returns the keys of the action as its help shows them, e.g. "↑/k".
+func (a KeyAction) KeyLabel() string {
+	if label := a.Binding.Help().Key; label != "" {
+		return label
+	}
+
+	return keysHelp(a.Binding.Keys())
+}
+
+// KeyActions returns every action of KeyContexts, in the order of the
+// contexts and of the fields of their key maps.
+func KeyActions() []KeyAction {
+	var actions []KeyAction
+
+	for _, ctx := range KeyContexts {
+		for _, m := range ctx.Maps {
+			v := reflect.ValueOf(m).Elem()
+
+			for i := range v.NumField() {
+				b, ok := v.Field(i).Addr().Interface().(*key.Binding)
+				if !ok {
+					continue
+				}
+
+				actions = append(actions, KeyAction{
+					Binding: b,
+					Name:    ctx.Name + "." + snakeCase(v.Type().Field(i).Name),
+					Context: ctx.Name,
+				})
+			}
+		}
+	}
+
+	return actions
+}
+
+// snakeCase converts a Go field name such as SortByName to sort_by_name.
+func snakeCase(name string) string {
+	var b strings.Builder
+
+	for i, r := range name {
+		if unicode.IsUpper(r) {
+			if i > 0 {
+				b.WriteByte('_')
+			}
+
+			r = unicode.ToLower(r)
+		}
+
+		b.WriteRune(r)
+	}
+
+	return b.String()
+}
+
+// defaultKeys are the keys of every action as defined, by action name, which
+// ApplyKeyBindings starts from and conflicts are judged against.
+var defaultKeys = actionKeys()
+
+// defaultHelp is the help of every action as defined, by action name.
+var defaultHelp = actionHelp()
+
+func actionKeys() map[string][]string {
+	keys := make(map[string][]string)
+	for _, a := range KeyActions() {
+		keys[a.Name] = a.Binding.Keys()
+	}
+
+	return keys
+}
+
+func actionHelp() map[string]key.Help {
+	help := make(map[string]key.Help)
+	for _, a := range KeyActions() {
+		help[a.Name] = a.Binding.Help()
+	}
+
+	return help
+}
+
+// keyLabels are the help labels of keys that are names rather than
+// characters.
+var keyLabels = map[string]string{
+	"up":    "↑",
+	"down":  "↓",
+	"left":  "←",
+	"right": "→",
+}
+
+// keysHelp returns the help label of keys, e.g. "↑/k".
+func keysHelp(keys []string) string {
+	labels := make([]string, len(keys))
+	for i, k := range keys {
+		labels[i] = k
+		if label, ok := keyLabels[k]; ok {
+			labels[i] = label
+		}
+	}
+
+	return strings.Join(labels, "/")
+}
+
+// ApplyKeyBindings rebinds the actions named in overrides, by action name, to
+// their keys, and restores every other action to its default keys. The help
+// of a rebound action shows its new keys, in the overlay and in the footers
+// that list it.
+// An unknown action, an empty key list, or a key bound to two actions of one
+// context (or to an action and a global one) fails without changing any
+// binding, unless both actions have that key by default.
+func ApplyKeyBindings(overrides map[string][]string) error {
+	actions := KeyActions()
+
+	keys := maps.Clone(defaultKeys)
+
+	names := make([]string, 0, len(overrides))
+	for name := range overrides {
+		names = append(names, name)
+	}
+
+	slices.Sort(names)
+
+	for _, name := range names {
+		if _, ok := keys[name]; !ok {
+			return fmt.Errorf("unknown action %q", name)
+		}
+
+		if len(overrides[name]) == 0 || slices.Contains(overrides[name], "") {
+			return fmt.Errorf("action %q: keys must not be empty", name)
+		}
+
+		keys[name] = overrides[name]
+	}
+
+	if err := checkKeyConflicts(actions, keys, overrides); err != nil {
+		return err
+	}
+
+	for _, a := range actions {
+		a.Binding.SetKeys(keys[a.Name]...)
+
+		help := defaultHelp[a.Name]
+		if _, ok := overrides[a.Name]; ok && help.Key != "" {
+			help.Key = keysHelp(keys[a.Name])
+		}
+
+		a.Binding.SetHelp(help.Key, help.Desc)
+	}
+
+	return nil
+}
+
+// checkKeyConflicts returns an error for the first key of a rebound action
+// that another action of the same context, or a global action, also has,
+// unless both had it by default.
+func checkKeyConflicts(actions []KeyAction, keys map[string][]string, overrides map[string][]string) error {
+	for _, a := range actions {
+		if _, ok := overrides[a.Name]; !ok {
+			continue
+		}
+
+		for _, other := range actions {
+			if other.Name == a.Name ||
+				(other.Context != a.Context && other.Context != "global" && a.Context != "global") {
+				continue
+			}
+
+			for _, k := range keys[a.Name] {
+				if !slices.Contains(keys[other.Name], k) {
+					continue
+				}
+
+				if slices.Contains(defaultKeys[a.Name], k) && slices.Contains(defaultKeys[other.Name], k) {
+					continue
+				}
+
+				return fmt.Errorf("key %q of %s is also bound to %s", k, a.Name, other.Name)
+			}
+		}
+	}
+
+	return nil
+}
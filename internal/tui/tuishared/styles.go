@@ -1,227 +1,340 @@
 package tuishared
 
 import (
+	"image/color"
+
 	"charm.land/lipgloss/v2"
 )
 
-// PrimaryColor and the rest of the Catppuccin Mocha palette are exported
-// so that sub-packages can build ad-hoc styles.
+// PrimaryColor and the rest of the active theme's palette are exported so
+// that sub-packages can build ad-hoc styles. ApplyTheme sets them.
 var (
-	PrimaryColor   = lipgloss.Color("#CBA6F7") // Mauve
-	SecondaryColor = lipgloss.Color("#A6E3A1") // Green
-	AccentColor    = lipgloss.Color("#F9E2AF") // Yellow
-	ErrorColor     = lipgloss.Color("#F38BA8") // Red
-	MutedColor     = lipgloss.Color("#6C7086") // Overlay0
-	TextColor      = lipgloss.Color("#CDD6F4") // Text
-	SurfaceColor   = lipgloss.Color("#313244") // Surface0
-	Surface2Color  = lipgloss.Color("#585B70") // Surface2
-	CrustColor     = lipgloss.Color("#11111B") // Crust
-	BlueColor      = lipgloss.Color("#89B4FA") // Blue
-	LavenderColor  = lipgloss.Color("#B4BEFE") // Lavender
+	PrimaryColor   color.Color
+	SecondaryColor color.Color
+	AccentColor    color.Color
+	ErrorColor     color.Color
+	MutedColor     color.Color
+	TextColor      color.Color
+	SurfaceColor   color.Color
+	Surface2Color  color.Color
+	CrustColor     color.Color
+	BlueColor      color.Color
+	LavenderColor  color.Color
+)
 
+// The styles below are built from the active theme by ApplyTheme.
+var (
 	// BaseStyle is the base style with padding for content.
-	BaseStyle = lipgloss.NewStyle().
-			Padding(1, 2)
+	BaseStyle lipgloss.Style
 
 	// TitleStyle is the main title style with border and bold text.
-	TitleStyle = lipgloss.NewStyle().
-			Bold(true).
-			Foreground(PrimaryColor).
-			MarginBottom(1).
-			Padding(0, 1).
-			Border(lipgloss.RoundedBorder()).
-			BorderForeground(PrimaryColor)
+	TitleStyle lipgloss.Style
 
 	// SubtitleStyle is the subtitle style with muted color and italic text.
-	SubtitleStyle = lipgloss.NewStyle().
-			Foreground(MutedColor).
-			Italic(true).
-			MarginBottom(1)
+	SubtitleStyle lipgloss.Style
 
 	// MutedTextStyle is inline muted text (no margins, for use within lines).
-	MutedTextStyle = lipgloss.NewStyle().
-			Foreground(MutedColor)
+	MutedTextStyle lipgloss.Style
 
 	// MenuItemStyle is the default menu item style.
-	MenuItemStyle = lipgloss.NewStyle().
-			Padding(0, 2)
+	MenuItemStyle lipgloss.Style
 
 	// SelectedMenuItemStyle is the style for selected menu items with highlighted background.
-	SelectedMenuItemStyle = lipgloss.NewStyle().
-				Foreground(PrimaryColor).
-				Background(Surface2Color).
-				Bold(true)
+	SelectedMenuItemStyle lipgloss.Style
 
 	// ListItemStyle is the default list item style.
-	ListItemStyle = lipgloss.NewStyle()
+	ListItemStyle lipgloss.Style
 
 	// SelectedListItemStyle is the style for selected list items with highlighted background.
-	SelectedListItemStyle = lipgloss.NewStyle().
-				Foreground(PrimaryColor).
-				Background(Surface2Color).
-				Bold(true)
+	SelectedListItemStyle lipgloss.Style
 
 	// CheckedStyle is the style for checked checkboxes.
-	CheckedStyle = lipgloss.NewStyle().
-			Foreground(SecondaryColor).
-			Bold(true)
+	CheckedStyle lipgloss.Style
 
 	// UncheckedStyle is the style for unchecked checkboxes.
-	UncheckedStyle = lipgloss.NewStyle().
-			Foreground(MutedColor)
+	UncheckedStyle lipgloss.Style
 
 	// PathNameStyle is the style for path names with bold text.
-	PathNameStyle = lipgloss.NewStyle().
-			Foreground(TextColor).
-			Bold(true)
+	PathNameStyle lipgloss.Style
 
 	// PathTargetStyle is the style for path target locations with muted italic text.
-	PathTargetStyle = lipgloss.NewStyle().
-			Foreground(MutedColor).
-			Italic(true)
+	PathTargetStyle lipgloss.Style
 
 	// PathBackupStyle is the style for path backup locations.
-	PathBackupStyle = lipgloss.NewStyle().
-			Foreground(AccentColor)
+	PathBackupStyle lipgloss.Style
 
 	// FolderBadgeStyle is the badge style for folder indicators.
-	FolderBadgeStyle = lipgloss.NewStyle().
-				Foreground(CrustColor).
-				Background(AccentColor).
-				Padding(0, 1).
-				MarginLeft(1)
+	FolderBadgeStyle lipgloss.Style
 
 	// StateBadgeReadyStyle is the badge style for ready state (green background).
-	StateBadgeReadyStyle = lipgloss.NewStyle().
-				Foreground(CrustColor).
-				Background(SecondaryColor).
-				Padding(0, 1).
-				MarginLeft(1)
+	StateBadgeReadyStyle lipgloss.Style
 
 	// StateBadgeAdoptStyle is the badge style for adopt state (yellow background).
-	StateBadgeAdoptStyle = lipgloss.NewStyle().
-				Foreground(CrustColor).
-				Background(AccentColor).
-				Padding(0, 1).
-				MarginLeft(1)
+	StateBadgeAdoptStyle lipgloss.Style
 
 	// StateBadgeMissingStyle is the badge style for missing state (red background).
-	StateBadgeMissingStyle = lipgloss.NewStyle().
-				Foreground(CrustColor).
-				Background(ErrorColor).
-				Padding(0, 1).
-				MarginLeft(1)
+	StateBadgeMissingStyle lipgloss.Style
 
 	// StateBadgeLinkedStyle is the badge style for linked state (muted text).
-	StateBadgeLinkedStyle = lipgloss.NewStyle().
-				Foreground(MutedColor).
-				Padding(0, 1).
-				MarginLeft(1)
+	StateBadgeLinkedStyle lipgloss.Style
 
 	// StateBadgeOutdatedStyle is the badge style for outdated state (yellow background).
-	StateBadgeOutdatedStyle = lipgloss.NewStyle().
-				Foreground(CrustColor).
-				Background(AccentColor).
-				Padding(0, 1).
-				MarginLeft(1)
+	StateBadgeOutdatedStyle lipgloss.Style
 
 	// StateBadgeModifiedStyle is the badge style for modified state (blue background).
-	StateBadgeModifiedStyle = lipgloss.NewStyle().
-				Foreground(CrustColor).
-				Background(BlueColor).
-				Padding(0, 1).
-				MarginLeft(1)
+	StateBadgeModifiedStyle lipgloss.Style
 
 	// StateBadgeFilteredStyle is the badge style for filtered state (same as linked - muted).
-	StateBadgeFilteredStyle = lipgloss.NewStyle().
-				Foreground(MutedColor).
-				Padding(0, 1).
-				MarginLeft(1)
+	StateBadgeFilteredStyle lipgloss.Style
 
 	// StateBadgeInstalledStyle is the badge style for installed state (muted, like linked).
-	StateBadgeInstalledStyle = lipgloss.NewStyle().
-					Foreground(MutedColor).
-					Padding(0, 1).
-					MarginLeft(1)
+	StateBadgeInstalledStyle lipgloss.Style
 
 	// ProgressStyle is the style for progress indicators.
-	ProgressStyle = lipgloss.NewStyle().
-			Foreground(SecondaryColor)
+	ProgressStyle lipgloss.Style
 
 	// SuccessStyle is the style for success messages with bold green text.
-	SuccessStyle = lipgloss.NewStyle().
-			Foreground(SecondaryColor).
-			Bold(true)
+	SuccessStyle lipgloss.Style
 
 	// ErrorStyle is the style for error messages with bold red text.
-	ErrorStyle = lipgloss.NewStyle().
-			Foreground(ErrorColor).
-			Bold(true)
+	ErrorStyle lipgloss.Style
 
 	// WarningStyle is the style for warning messages with amber text.
-	WarningStyle = lipgloss.NewStyle().
-			Foreground(AccentColor)
+	WarningStyle lipgloss.Style
 
 	// BoxStyle is the default box style with rounded border.
-	BoxStyle = lipgloss.NewStyle().
-			Border(lipgloss.RoundedBorder()).
-			BorderForeground(PrimaryColor).
-			Padding(1, 2).
-			MarginTop(1)
+	BoxStyle lipgloss.Style
 
 	// ResultBoxStyle is the box style for result displays with green border.
-	ResultBoxStyle = lipgloss.NewStyle().
-			Border(lipgloss.RoundedBorder()).
-			BorderForeground(SecondaryColor).
-			Padding(1, 2).
-			MarginTop(1)
+	ResultBoxStyle lipgloss.Style
 
 	// HelpStyle is the style for help text.
-	HelpStyle = lipgloss.NewStyle().
-			Foreground(MutedColor).
-			MarginTop(1)
+	HelpStyle lipgloss.Style
 
 	// HelpKeyStyle is the style for help key bindings with bold amber text.
-	HelpKeyStyle = lipgloss.NewStyle().
-			Foreground(AccentColor).
-			Bold(true)
+	HelpKeyStyle lipgloss.Style
 
 	// StatusBarStyle is the style for the status bar.
-	StatusBarStyle = lipgloss.NewStyle().
-			Foreground(TextColor).
-			Background(SurfaceColor).
-			Padding(0, 1).
-			MarginTop(1)
+	StatusBarStyle lipgloss.Style
 
 	// SpinnerStyle is the style for loading spinners.
-	SpinnerStyle = lipgloss.NewStyle().
-			Foreground(PrimaryColor)
+	SpinnerStyle lipgloss.Style
 
 	// FilterInputStyle is the style for filter input fields.
-	FilterInputStyle = lipgloss.NewStyle().
-				Foreground(TextColor).
-				Background(SurfaceColor).
-				Padding(0, 1)
+	FilterInputStyle lipgloss.Style
 
 	// FilterHighlightStyle is the style for highlighted filter matches with amber background.
-	FilterHighlightStyle = lipgloss.NewStyle().
-				Foreground(MutedColor).
-				Bold(true)
+	FilterHighlightStyle lipgloss.Style
 
 	// MultiSelectBannerStyle is the style for the multi-select banner showing selection counts.
-	MultiSelectBannerStyle = lipgloss.NewStyle().
-				Foreground(MutedColor).
-				Bold(true).
-				Padding(0, 2)
+	MultiSelectBannerStyle lipgloss.Style
 
 	// SelectedRowStyle is the style for rows that are selected in multi-select mode.
 	// Uses surface background with lavender text to differentiate from cursor highlight.
-	SelectedRowStyle = lipgloss.NewStyle().
-				Foreground(LavenderColor).
-				Background(SurfaceColor).
-				Padding(0, 1)
+	SelectedRowStyle lipgloss.Style
 )
 
+// buildStyles sets the palette and styles of the package from t.
+func buildStyles(t Theme) {
+	PrimaryColor = t.Primary
+	SecondaryColor = t.Secondary
+	AccentColor = t.Accent
+	ErrorColor = t.Error
+	MutedColor = t.Muted
+	TextColor = t.Text
+	SurfaceColor = t.Surface
+	Surface2Color = t.Surface2
+	CrustColor = t.Crust
+	BlueColor = t.Blue
+	LavenderColor = t.Lavender
+
+	BaseStyle = lipgloss.NewStyle().
+		Padding(1, 2)
+
+	TitleStyle = lipgloss.NewStyle().
+		Bold(true).
+		Foreground(PrimaryColor).
+		MarginBottom(1).
+		Padding(0, 1).
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(PrimaryColor)
+
+	SubtitleStyle = lipgloss.NewStyle().
+		Foreground(MutedColor).
+		Italic(true).
+		MarginBottom(1)
+
+	MutedTextStyle = lipgloss.NewStyle().
+		Foreground(MutedColor)
+
+	MenuItemStyle = lipgloss.NewStyle().
+		Padding(0, 2)
+
+	SelectedMenuItemStyle = lipgloss.NewStyle().
+		Foreground(PrimaryColor).
+		Background(Surface2Color).
+		Bold(true)
+
+	ListItemStyle = lipgloss.NewStyle()
+
+	SelectedListItemStyle = lipgloss.NewStyle().
+		Foreground(PrimaryColor).
+		Background(Surface2Color).
+		Bold(true)
+
+	CheckedStyle = lipgloss.NewStyle().
+		Foreground(SecondaryColor).
+		Bold(true)
+
+	UncheckedStyle = lipgloss.NewStyle().
+		Foreground(MutedColor)
+
+	PathNameStyle = lipgloss.NewStyle().
+		Foreground(TextColor).
+		Bold(true)
+
+	PathTargetStyle = lipgloss.NewStyle().
+		Foreground(MutedColor).
+		Italic(true)
+
+	PathBackupStyle = lipgloss.NewStyle().
+		Foreground(AccentColor)
+
+	FolderBadgeStyle = lipgloss.NewStyle().
+		Foreground(CrustColor).
+		Background(AccentColor).
+		Padding(0, 1).
+		MarginLeft(1)
+
+	StateBadgeReadyStyle = lipgloss.NewStyle().
+		Foreground(CrustColor).
+		Background(SecondaryColor).
+		Padding(0, 1).
+		MarginLeft(1)
+
+	StateBadgeAdoptStyle = lipgloss.NewStyle().
+		Foreground(CrustColor).
+		Background(AccentColor).
+		Padding(0, 1).
+		MarginLeft(1)
+
+	StateBadgeMissingStyle = lipgloss.NewStyle().
+		Foreground(CrustColor).
+		Background(ErrorColor).
+		Padding(0, 1).
+		MarginLeft(1)
+
+	StateBadgeLinkedStyle = lipgloss.NewStyle().
+		Foreground(MutedColor).
+		Padding(0, 1).
+		MarginLeft(1)
+
+	StateBadgeOutdatedStyle = lipgloss.NewStyle().
+		Foreground(CrustColor).
+		Background(AccentColor).
+		Padding(0, 1).
+		MarginLeft(1)
+
+	StateBadgeModifiedStyle = lipgloss.NewStyle().
+		Foreground(CrustColor).
+		Background(BlueColor).
+		Padding(0, 1).
+		MarginLeft(1)
+
+	StateBadgeFilteredStyle = lipgloss.NewStyle().
+		Foreground(MutedColor).
+		Padding(0, 1).
+		MarginLeft(1)
+
+	StateBadgeInstalledStyle = lipgloss.NewStyle().
+		Foreground(MutedColor).
+		Padding(0, 1).
+		MarginLeft(1)
+
+	ProgressStyle = lipgloss.NewStyle().
+		Foreground(SecondaryColor)
+
+	SuccessStyle = lipgloss.NewStyle().
+		Foreground(SecondaryColor).
+		Bold(true)
+
+	ErrorStyle = lipgloss.NewStyle().
+		Foreground(ErrorColor).
+		Bold(true)
+
+	WarningStyle = lipgloss.NewStyle().
+		Foreground(AccentColor)
+
+	BoxStyle = lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(PrimaryColor).
+		Padding(1, 2).
+		MarginTop(1)
+
+	ResultBoxStyle = lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(SecondaryColor).
+		Padding(1, 2).
+		MarginTop(1)
+
+	HelpStyle = lipgloss.NewStyle().
+		Foreground(MutedColor).
+		MarginTop(1)
+
+	HelpKeyStyle = lipgloss.NewStyle().
+		Foreground(AccentColor).
+		Bold(true)
+
+	StatusBarStyle = lipgloss.NewStyle().
+		Foreground(TextColor).
+		Background(SurfaceColor).
+		Padding(0, 1).
+		MarginTop(1)
+
+	SpinnerStyle = lipgloss.NewStyle().
+		Foreground(PrimaryColor)
+
+	FilterInputStyle = lipgloss.NewStyle().
+		Foreground(TextColor).
+		Background(SurfaceColor).
+		Padding(0, 1)
+
+	FilterHighlightStyle = lipgloss.NewStyle().
+		Foreground(MutedColor).
+		Bold(true)
+
+	MultiSelectBannerStyle = lipgloss.NewStyle().
+		Foreground(MutedColor).
+		Bold(true).
+		Padding(0, 2)
+
+	SelectedRowStyle = lipgloss.NewStyle().
+		Foreground(LavenderColor).
+		Background(SurfaceColor).
+		Padding(0, 1)
+
+	if t.Mono {
+		monoStyles()
+	}
+}
+
+// monoStyles marks with reverse video and underlines what the colored themes
+// mark with a background color, which a theme without colors cannot show.
+func monoStyles() {
+	SelectedMenuItemStyle = SelectedMenuItemStyle.Reverse(true)
+	SelectedListItemStyle = SelectedListItemStyle.Reverse(true)
+	SelectedRowStyle = SelectedRowStyle.Underline(true)
+	StatusBarStyle = StatusBarStyle.Reverse(true)
+	FilterInputStyle = FilterInputStyle.Reverse(true)
+
+	for _, badge := range []*lipgloss.Style{
+		&FolderBadgeStyle, &StateBadgeReadyStyle, &StateBadgeAdoptStyle,
+		&StateBadgeMissingStyle, &StateBadgeOutdatedStyle, &StateBadgeModifiedStyle,
+	} {
+		*badge = badge.Reverse(true)
+	}
+}
+
 // RenderHelp renders help text with key bindings, wrapping to 80 characters.
 // It takes alternating key and description strings and formats them with styling.
 func RenderHelp(keys ...string) string {
@@ -241,7 +354,7 @@ func RenderHelpWithWidth(width int, keys ...string) string {
 	var lineTexts []string    // Styled text for current line
 	var currentVisibleLen int // Track visible length separately
 	separator := "  "
-	separatorLen := len(separator)
+	separatorLen := lipgloss.Width(separator)
 
 	for i := 0; i < len(keys); i += 2 {
 		key := keys[i]
@@ -269,7 +382,7 @@ func RenderHelpWithWidth(width int, keys ...string) string {
 					highlighted := HelpKeyStyle.Render(key) // Use key's case, not matched character
 					after := string(descRunes[j+1:])
 					itemText = before + highlighted + after
-					itemLen = len(desc) // Visual length is just the description length
+					itemLen = lipgloss.Width(desc) // Visual length is just the description length
 					found = true
 					break
 				}
@@ -278,12 +391,12 @@ func RenderHelpWithWidth(width int, keys ...string) string {
 			if !found {
 				// Fallback: render as separate key and description
 				itemText = HelpKeyStyle.Render(key) + " " + desc
-				itemLen = len(key) + 1 + len(desc)
+				itemLen = lipgloss.Width(key) + 1 + lipgloss.Width(desc)
 			}
 		} else {
 			// For "q" and multi-character keys, render as separate key and description
 			itemText = HelpKeyStyle.Render(key) + " " + desc
-			itemLen = len(key) + 1 + len(desc)
+			itemLen = lipgloss.Width(key) + 1 + lipgloss.Width(desc)
 		}
 
 		// Check if adding this item would exceed width
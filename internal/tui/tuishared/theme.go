@@ -0,0 +1,151 @@
+package tuishared
+
+import (
+	"fmt"
+	"image/color"
+	"os"
+	"strings"
+
+	"charm.land/lipgloss/v2"
+)
+
+// Theme is the palette every TUI style is built from; see ApplyTheme.
+type Theme struct {
+	Name      string
+	Primary   color.Color // titles, borders and the cursor
+	Secondary color.Color // success and checked items
+	Accent    color.Color // warnings, help keys and highlights
+	Error     color.Color
+	Muted     color.Color
+	Text      color.Color
+	Surface   color.Color // status bar and selected rows
+	Surface2  color.Color // the cursor's background
+	Crust     color.Color // text on badges
+	Blue      color.Color
+	Lavender  color.Color
+
+	// Mono marks a theme without colors, which shows the cursor, selection
+	// and badges in reverse video or underlined instead.
+	Mono bool
+}
+
+// Theme names, as accepted by ThemeByName.
+const (
+	ThemeDark       = "dark"
+	ThemeLight      = "light"
+	ThemeMonochrome = "monochrome"
+	ThemeAuto       = "auto"
+)
+
+// DarkTheme is Catppuccin Mocha, the default.
+var DarkTheme = Theme{
+	Name:      ThemeDark,
+	Primary:   lipgloss.Color("#CBA6F7"), // Mauve
+	Secondary: lipgloss.Color("#A6E3A1"), // Green
+	Accent:    lipgloss.Color("#F9E2AF"), // Yellow
+	Error:     lipgloss.Color("#F38BA8"), // Red
+	Muted:     lipgloss.Color("#6C7086"), // Overlay0
+	Text:      lipgloss.Color("#CDD6F4"), // Text
+	Surface:   lipgloss.Color("#313244"), // Surface0
+	Surface2:  lipgloss.Color("#585B70"), // Surface2
+	Crust:     lipgloss.Color("#11111B"), // Crust
+	Blue:      lipgloss.Color("#89B4FA"), // Blue
+	Lavender:  lipgloss.Color("#B4BEFE"), // Lavender
+}
+
+// LightTheme is Catppuccin Latte, for terminals with a light background.
+var LightTheme = Theme{
+	Name:      ThemeLight,
+	Primary:   lipgloss.Color("#8839EF"), // Mauve
+	Secondary: lipgloss.Color("#40A02B"), // Green
+	Accent:    lipgloss.Color("#DF8E1D"), // Yellow
+	Error:     lipgloss.Color("#D20F39"), // Red
+	Muted:     lipgloss.Color("#8C8FA1"), // Overlay1
+	Text:      lipgloss.Color("#4C4F69"), // Text
+	Surface:   lipgloss.Color("#CCD0DA"), // Surface0
+	Surface2:  lipgloss.Color("#ACB0BE"), // Surface2
+	Crust:     lipgloss.Color("#EFF1F5"), // Base
+	Blue:      lipgloss.Color("#1E66F5"), // Blue
+	Lavender:  lipgloss.Color("#7287FD"), // Lavender
+}
+
+// MonochromeTheme uses no color at all: the terminal's own foreground and
+// background, bold, italics and reverse video.
+var MonochromeTheme = Theme{
+	Name:      ThemeMonochrome,
+	Primary:   lipgloss.NoColor{},
+	Secondary: lipgloss.NoColor{},
+	Accent:    lipgloss.NoColor{},
+	Error:     lipgloss.NoColor{},
+	Muted:     lipgloss.NoColor{},
+	Text:      lipgloss.NoColor{},
+	Surface:   lipgloss.NoColor{},
+	Surface2:  lipgloss.NoColor{},
+	Crust:     lipgloss.NoColor{},
+	Blue:      lipgloss.NoColor{},
+	Lavender:  lipgloss.NoColor{},
+	Mono:      true,
+}
+
+// activeTheme is the theme the styles were last built from.
+var activeTheme Theme
+
+// ThemeByName returns the theme called name, matched case-insensitively.
+func ThemeByName(name string) (Theme, error) {
+	for _, t := range []Theme{DarkTheme, LightTheme, MonochromeTheme} {
+		if strings.EqualFold(name, t.Name) {
+			return t, nil
+		}
+	}
+
+	return Theme{}, fmt.Errorf("unknown theme %q (want %s, %s, %s or %s)", name, ThemeDark, ThemeLight, ThemeMonochrome, ThemeAuto)
+}
+
+// ResolveTheme returns the theme to use for name, as set with --theme or in
+// the app config. NO_COLOR, or CLICOLOR=0, always selects MonochromeTheme.
+// An empty name or "auto" selects DarkTheme or LightTheme, whichever
+// hasDarkBackground says suits the terminal; a nil hasDarkBackground means
+// dark. An unknown name is an error even when colors are off.
+func ResolveTheme(name string, hasDarkBackground func() bool) (Theme, error) {
+	auto := name == "" || strings.EqualFold(name, ThemeAuto)
+
+	t := DarkTheme
+	if !auto {
+		var err error
+		if t, err = ThemeByName(name); err != nil {
+			return Theme{}, err
+		}
+	}
+
+	switch {
+	case NoColor():
+		return MonochromeTheme, nil
+	case auto && hasDarkBackground != nil && !hasDarkBackground():
+		return LightTheme, nil
+	default:
+		return t, nil
+	}
+}
+
+// NoColor reports whether the environment asks for output without colors:
+// NO_COLOR is set to anything but an empty string (https://no-color.org), or
+// CLICOLOR is 0.
+func NoColor() bool {
+	return os.Getenv("NO_COLOR") != "" || os.Getenv("CLICOLOR") == "0"
+}
+
+// ActiveTheme returns the theme the styles are currently built from.
+func ActiveTheme() Theme {
+	return activeTheme
+}
+
+// ApplyTheme rebuilds every color and style of the package from t. Styles
+// are plain values, so a style copied before the call keeps the old theme.
+func ApplyTheme(t Theme) {
+	activeTheme = t
+	buildStyles(t)
+}
+
+func init() {
+	ApplyTheme(DarkTheme)
+}
@@ -0,0 +1,230 @@
+package tui
+
+import (
+	"encoding/json"
+	"maps"
+	"os"
+	"path/filepath"
+	"time"
+
+	tea "charm.land/bubbletea/v2"
+)
+
+// uiStateSaveDelay is how long the list view must stay unchanged before the UI
+// state is written, so holding down j/k does not write on every keypress.
+const uiStateSaveDelay = 500 * time.Millisecond
+
+// uiState is the list view state remembered across sessions: which
+// applications are expanded, the row under the cursor, and the sort order.
+type uiState struct {
+	Expanded      map[string]bool `json:"expanded,omitempty"`
+	CursorApp     string          `json:"cursor_app,omitempty"`
+	CursorEntry   string          `json:"cursor_entry,omitempty"`
+	SortColumn    string          `json:"sort_column,omitempty"`
+	SortAscending bool            `json:"sort_ascending"`
+}
+
+func (s uiState) equal(o uiState) bool {
+	return maps.Equal(s.Expanded, o.Expanded) &&
+		s.CursorApp == o.CursorApp &&
+		s.CursorEntry == o.CursorEntry &&
+		s.SortColumn == o.SortColumn &&
+		s.SortAscending == o.SortAscending
+}
+
+// uiStateSaveMsg fires once the debounce delay has passed. Only the message
+// carrying the latest generation triggers a write.
+type uiStateSaveMsg struct {
+	gen int
+}
+
+// DefaultUIStatePath returns the UI state file location:
+// $XDG_STATE_HOME/tidydots/ui-state.json, falling back to ~/.local/state.
+// Returns an empty string if no location can be determined.
+func DefaultUIStatePath() string {
+	dir := os.Getenv("XDG_STATE_HOME")
+	if dir == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return ""
+		}
+
+		dir = filepath.Join(home, ".local", "state")
+	}
+
+	return filepath.Join(dir, "tidydots", "ui-state.json")
+}
+
+// loadUIState reads the UI state file. A missing or corrupt file yields the
+// zero state: remembering the view is a convenience, never a reason to fail.
+func loadUIState(path string) uiState {
+	var s uiState
+
+	if path == "" {
+		return s
+	}
+
+	data, err := os.ReadFile(path) //nolint:gosec // path is derived from the user's state directory
+	if err != nil {
+		return s
+	}
+
+	if err := json.Unmarshal(data, &s); err != nil {
+		return uiState{}
+	}
+
+	return s
+}
+
+// writeUIState writes the UI state file, creating its directory as needed.
+func writeUIState(path string, s uiState) error {
+	data, err := json.Marshal(s)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0750); err != nil {
+		return err
+	}
+
+	return os.WriteFile(path, data, 0600)
+}
+
+// currentUIState captures the persisted parts of the list view.
+func (m *Model) currentUIState() uiState {
+	s := uiState{
+		Expanded:      make(map[string]bool),
+		SortColumn:    m.sortColumn,
+		SortAscending: m.sortAscending,
+	}
+
+	for _, app := range m.Applications {
+		if app.Expanded {
+			s.Expanded[app.Application.Name] = true
+		}
+	}
+
+	appIdx, subIdx := m.getApplicationAtCursorFromTable()
+	if appIdx >= 0 {
+		s.CursorApp = m.Applications[appIdx].Application.Name
+		if subIdx >= 0 && subIdx < len(m.Applications[appIdx].SubItems) {
+			s.CursorEntry = m.Applications[appIdx].SubItems[subIdx].SubEntry.Name
+		}
+	}
+
+	return s
+}
+
+// applyUIState restores a saved list view. Applications and entries that no
+// longer exist are ignored, as is an unknown sort column.
+func (m *Model) applyUIState(s uiState) {
+	switch s.SortColumn {
+	case SortColumnName, SortColumnStatus, SortColumnPath, SortColumnPriority:
+		m.sortColumn = s.SortColumn
+		m.sortAscending = s.SortAscending
+	}
+
+	m.savedExpanded = s.Expanded
+	m.initApplicationItems()
+
+	for i, row := range m.tableRows {
+		if row.AppName != s.CursorApp {
+			continue
+		}
+
+		if row.SubIndex < 0 && s.CursorEntry == "" {
+			m.tableCursor = i
+			break
+		}
+
+		if row.SubIndex >= 0 && m.subEntryNameAt(row.AppName, row.SubIndex) == s.CursorEntry {
+			m.tableCursor = i
+			break
+		}
+	}
+
+	m.updateScrollOffset()
+	m.lastUIState = m.currentUIState()
+}
+
+// subEntryNameAt returns the name of the sub-entry at subIdx of the named
+// application, or "" if there is none.
+func (m *Model) subEntryNameAt(appName string, subIdx int) string {
+	for _, app := range m.Applications {
+		if app.Application.Name == appName && subIdx < len(app.SubItems) {
+			return app.SubItems[subIdx].SubEntry.Name
+		}
+	}
+
+	return ""
+}
+
+// trackUIState follows an Update step and, if it changed the persisted UI
+// state, schedules a debounced save. Every change bumps the generation, so
+// only the tick from the last change in a burst writes the file.
+func trackUIState(next tea.Model, cmd tea.Cmd) (tea.Model, tea.Cmd) {
+	m, ok := next.(Model)
+	if !ok || m.uiStatePath == "" {
+		return next, cmd
+	}
+
+	current := m.currentUIState()
+	if current.equal(m.lastUIState) {
+		return m, cmd
+	}
+
+	m.lastUIState = current
+	m.uiStateGen++
+	gen := m.uiStateGen
+
+	tick := tea.Tick(uiStateSaveDelay, func(time.Time) tea.Msg {
+		return uiStateSaveMsg{gen: gen}
+	})
+
+	return m, tea.Batch(cmd, tick)
+}
+
+// handleUIStateSave writes the UI state in a background command once the
+// debounce delay has elapsed without further changes. Write errors are
+// dropped: a stale state file only costs a few keypresses next launch.
+func (m Model) handleUIStateSave(msg uiStateSaveMsg) (tea.Model, tea.Cmd) {
+	if msg.gen != m.uiStateGen || m.uiStatePath == "" {
+		return m, nil
+	}
+
+	path, s := m.uiStatePath, m.lastUIState
+
+	return m, func() tea.Msg {
+		_ = writeUIState(path, s)
+		return nil
+	}
+}
+
+// setAllExpanded expands or collapses every application, keeping the cursor
+// on the same application (a collapsed sub-entry row moves to its parent).
+func (m *Model) setAllExpanded(expanded bool) {
+	var cursorApp string
+	if m.tableCursor >= 0 && m.tableCursor < len(m.tableRows) {
+		cursorApp = m.tableRows[m.tableCursor].AppName
+	}
+
+	cursorSub := -1
+	if expanded && m.tableCursor >= 0 && m.tableCursor < len(m.tableRows) {
+		cursorSub = m.tableRows[m.tableCursor].SubIndex
+	}
+
+	for i := range m.Applications {
+		m.Applications[i].Expanded = expanded
+	}
+
+	m.initTableModel()
+
+	for i, row := range m.tableRows {
+		if row.AppName == cursorApp && row.SubIndex == cursorSub {
+			m.tableCursor = i
+			break
+		}
+	}
+
+	m.updateScrollOffset()
+}
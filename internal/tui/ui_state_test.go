@@ -0,0 +1,145 @@
+package tui
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	tea "charm.land/bubbletea/v2"
+)
+
+func TestLoadUIState_MissingOrCorruptIsIgnored(t *testing.T) {
+	dir := t.TempDir()
+	corrupt := filepath.Join(dir, "corrupt.json")
+
+	if err := os.WriteFile(corrupt, []byte("{not json"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, path := range []string{"", filepath.Join(dir, "missing.json"), corrupt} {
+		if s := loadUIState(path); !s.equal(uiState{}) {
+			t.Errorf("loadUIState(%q) = %+v, want zero state", path, s)
+		}
+	}
+}
+
+func TestUIState_RoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "tidydots", "ui-state.json")
+
+	m := NewModel(deleteProbeConfig(), linuxPlatform(), false)
+	m.uiStatePath = path
+
+	// Expand alpha, move onto its entry and sort by path descending.
+	m.Applications[0].Expanded = true
+	m.sortColumn = SortColumnPath
+	m.sortAscending = false
+	m.rebuildTable()
+	m.tableCursor = 1
+
+	if err := writeUIState(path, m.currentUIState()); err != nil {
+		t.Fatalf("writeUIState() error = %v", err)
+	}
+
+	restored := NewModel(deleteProbeConfig(), linuxPlatform(), false)
+	restored.applyUIState(loadUIState(path))
+
+	if !restored.Applications[0].Expanded || restored.Applications[1].Expanded {
+		t.Errorf("expanded = [%v %v], want [true false]",
+			restored.Applications[0].Expanded, restored.Applications[1].Expanded)
+	}
+
+	if restored.sortColumn != SortColumnPath || restored.sortAscending {
+		t.Errorf("sort = %s ascending=%v, want path descending", restored.sortColumn, restored.sortAscending)
+	}
+
+	appIdx, subIdx := restored.getApplicationAtCursorFromTable()
+	if appIdx != 0 || subIdx != 0 {
+		t.Errorf("cursor at (%d, %d), want alpha's first entry (0, 0)", appIdx, subIdx)
+	}
+}
+
+func TestApplyUIState_IgnoresUnknownApplications(t *testing.T) {
+	m := NewModel(deleteProbeConfig(), linuxPlatform(), false)
+
+	m.applyUIState(uiState{
+		Expanded:   map[string]bool{"gone": true, "zebra": true},
+		CursorApp:  "gone",
+		SortColumn: "bogus",
+	})
+
+	if m.Applications[0].Expanded || !m.Applications[1].Expanded {
+		t.Errorf("expanded = [%v %v], want only zebra", m.Applications[0].Expanded, m.Applications[1].Expanded)
+	}
+
+	if m.tableCursor != 0 {
+		t.Errorf("tableCursor = %d, want 0 for an unknown application", m.tableCursor)
+	}
+
+	if m.sortColumn != SortColumnName {
+		t.Errorf("sortColumn = %q, want the default for an unknown column", m.sortColumn)
+	}
+}
+
+func TestExpandAllCollapseAll(t *testing.T) {
+	m := NewModel(deleteProbeConfig(), linuxPlatform(), false)
+
+	updated, _ := m.Update(tea.KeyPressMsg{Code: 'E', Text: "E"})
+	m = updated.(Model)
+
+	for _, app := range m.Applications {
+		if !app.Expanded {
+			t.Errorf("%s not expanded after E", app.Application.Name)
+		}
+	}
+
+	if len(m.tableRows) != 4 {
+		t.Errorf("rows = %d after E, want 4", len(m.tableRows))
+	}
+
+	// Park on a sub-entry; collapsing must move the cursor to its parent.
+	m.tableCursor = 3
+
+	updated, _ = m.Update(tea.KeyPressMsg{Code: 'C', Text: "C"})
+	m = updated.(Model)
+
+	if len(m.tableRows) != 2 {
+		t.Fatalf("rows = %d after C, want 2", len(m.tableRows))
+	}
+
+	if m.tableRows[m.tableCursor].AppName != "zebra" {
+		t.Errorf("cursor on %q after C, want zebra", m.tableRows[m.tableCursor].AppName)
+	}
+}
+
+func TestTrackUIState_DebouncesSaves(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "ui-state.json")
+
+	m := NewModel(deleteProbeConfig(), linuxPlatform(), false)
+	m.uiStatePath = path
+	m.applyUIState(uiState{})
+
+	for _, k := range []rune{'j', 'E'} {
+		updated, _ := m.Update(tea.KeyPressMsg{Code: k, Text: string(k)})
+		m = updated.(Model)
+	}
+
+	if m.uiStateGen != 2 {
+		t.Fatalf("uiStateGen = %d, want one bump per change", m.uiStateGen)
+	}
+
+	// The tick from the first change is stale and must not write.
+	if _, cmd := m.Update(uiStateSaveMsg{gen: 1}); cmd != nil {
+		t.Error("stale save tick produced a write command")
+	}
+
+	_, cmd := m.Update(uiStateSaveMsg{gen: 2})
+	if cmd == nil {
+		t.Fatal("latest save tick produced no write command")
+	}
+
+	cmd()
+
+	if s := loadUIState(path); s.CursorApp != "zebra" {
+		t.Errorf("saved cursor app = %q, want zebra", s.CursorApp)
+	}
+}
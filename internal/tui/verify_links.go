@@ -0,0 +1,67 @@
+package tui
+
+// linkVerifyItems returns the entries V verifies: the selection in
+// multi-select mode, otherwise the sub-entry under the cursor or every
+// sub-entry of the application under it. Disabled entries are left out.
+func (m Model) linkVerifyItems() []batchRestoreItem {
+	if m.multiSelectActive {
+		return m.collectBatchRestoreItems()
+	}
+
+	appIdx, subIdx := m.getApplicationAtCursorFromTable()
+	if appIdx < 0 || appIdx >= len(m.Applications) {
+		return nil
+	}
+
+	app := m.Applications[appIdx]
+
+	var items []batchRestoreItem
+
+	for i, sub := range app.SubItems {
+		if sub.IsDisabled || (subIdx >= 0 && i != subIdx) {
+			continue
+		}
+
+		items = append(items, batchRestoreItem{
+			appIdx: appIdx,
+			subIdx: i,
+			name:   app.Application.Name + "/" + sub.SubEntry.Name,
+		})
+	}
+
+	return items
+}
+
+// verifyLinks checks the links of linkVerifyItems, repairs the drifted ones
+// and shows one result per entry. Setup and copy entries have no links and
+// are skipped.
+func (m Model) verifyLinks() Model {
+	var results []ResultItem
+
+	for _, item := range m.linkVerifyItems() {
+		sub := &m.Applications[item.appIdx].SubItems[item.subIdx]
+		if !sub.SubEntry.IsConfig() || sub.SubEntry.IsCopy() || sub.Target == "" || m.Manager.SkipsSudo(sub.SubEntry) {
+			continue
+		}
+
+		r := m.Manager.VerifyEntryLinks(sub.AppName, sub.SubEntry, sub.Target, true)
+		results = append(results, ResultItem{
+			Name:    item.name,
+			Success: r.OK(),
+			Message: r.Message(),
+		})
+
+		sub.State = m.detectSubEntryState(sub)
+	}
+
+	if len(results) == 0 {
+		return m
+	}
+
+	m.rebuildTable()
+	m.results = results
+	m.showingResults = true
+	m.resultsScrollOffset = 0
+
+	return m
+}
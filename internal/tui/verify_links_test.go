@@ -0,0 +1,66 @@
+package tui
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/AntoineGS/tidydots/internal/config"
+	"github.com/AntoineGS/tidydots/internal/manager"
+	"github.com/AntoineGS/tidydots/internal/platform"
+)
+
+func TestVerifyLinks_RepairsSelection(t *testing.T) {
+	if runtime.GOOS == platform.OSWindows {
+		t.Skip("symlink tests are skipped on Windows")
+	}
+
+	root := t.TempDir()
+	home := t.TempDir()
+	target := filepath.Join(home, "nvim")
+
+	if err := os.MkdirAll(filepath.Join(root, "nvim"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	// A plain directory where the link should be.
+	if err := os.MkdirAll(target, 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := &config.Config{
+		Version:    3,
+		BackupRoot: root,
+		Applications: []config.Application{
+			{Name: "neovim", Entries: []config.SubEntry{
+				{Name: "config", Backup: "./nvim", Targets: map[string]string{"linux": target}},
+				{Name: "plugins", Run: map[string]string{"linux": "true"}},
+			}},
+		},
+	}
+
+	m := NewModelWithManager(cfg, linuxPlatform(), manager.New(cfg, linuxPlatform()), "")
+	m.multiSelectActive = true
+	m.selectedApps["neovim"] = true
+
+	m = m.verifyLinks()
+
+	if !m.showingResults || len(m.results) != 1 {
+		t.Fatalf("results = %+v, want one result for the config entry", m.results)
+	}
+
+	r := m.results[0]
+	if r.Name != "neovim/config" || !r.Success || !strings.HasPrefix(r.Message, "Repaired:") {
+		t.Errorf("result = %+v, want a successful repair of neovim/config", r)
+	}
+
+	if dest, err := os.Readlink(target); err != nil || dest != filepath.Join(root, "nvim") {
+		t.Errorf("Readlink(%s) = %q, %v; want a link to the backup", target, dest, err)
+	}
+
+	if got := m.Applications[0].SubItems[0].State; got != StateLinked {
+		t.Errorf("State = %v, want StateLinked after the repair", got)
+	}
+}